@@ -68,6 +68,11 @@ func WalletPassword() string {
 	return os.Getenv(siaWalletPassword)
 }
 
+// BackupPassword returns the SiaBackupPassword environment variable.
+func BackupPassword() string {
+	return os.Getenv(siaBackupPassword)
+}
+
 // ExchangeRate returns the siaExchangeRate environment variable.
 func ExchangeRate() string {
 	return os.Getenv(siaExchangeRate)
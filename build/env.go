@@ -20,6 +20,10 @@ var (
 	// auto unlocking the wallet
 	siaWalletPassword = "SIA_WALLET_PASSWORD"
 
+	// siaBackupPassword is the environment variable that can be set to
+	// provide the password of node backups
+	siaBackupPassword = "SIA_BACKUP_PASSWORD"
+
 	// siaExchangeRate is the environment variable that can be set to
 	// show amounts (additionally) in a different currency
 	siaExchangeRate = "SIA_EXCHANGE_RATE"
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/node"
+	"go.sia.tech/siad/node/api/client"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/siatest"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// stateFile is the name of the file in the devnet's directory that
+	// contains the nodes of the devnet.
+	stateFile = "devnet.json"
+
+	// roleMiner, roleHost and roleRenter are the roles of the devnet's nodes.
+	roleMiner  = "miner"
+	roleHost   = "host"
+	roleRenter = "renter"
+)
+
+var (
+	// stateMetadata is the metadata of the devnet's state file.
+	stateMetadata = persist.Metadata{
+		Header:  "Sia Devnet",
+		Version: "1.0",
+	}
+
+	// errNoMiners is returned if a spec doesn't contain any miners.
+	errNoMiners = errors.New("the devnet needs at least one miner")
+)
+
+type (
+	// devnetSpec describes the nodes of a devnet.
+	devnetSpec struct {
+		// Miners is the number of miners. At least one miner is required to
+		// fund the other nodes.
+		Miners int `json:"miners"`
+
+		// BlockInterval is the interval at which the first miner mines a
+		// block, e.g. "10s". Blocks are not mined automatically if it is
+		// empty.
+		BlockInterval string `json:"blockinterval"`
+
+		Hosts   []hostSpec   `json:"hosts"`
+		Renters []renterSpec `json:"renters"`
+	}
+
+	// hostSpec describes a set of hosts with the same configuration.
+	hostSpec struct {
+		Count int `json:"count"`
+
+		// Storage is the size of the host's storage folder in bytes.
+		Storage uint64 `json:"storage"`
+
+		// Settings are host settings as accepted by the /host [POST]
+		// endpoint, e.g. "mincontractprice".
+		Settings map[string]string `json:"settings"`
+	}
+
+	// renterSpec describes a set of renters with the same allowance.
+	renterSpec struct {
+		Count int `json:"count"`
+
+		// Allowance is the allowance of the renters. Fields that are not set
+		// default to the allowance of siatest.
+		Allowance modules.Allowance `json:"allowance"`
+	}
+
+	// devnetNode is a node of a devnet as persisted in the state file.
+	devnetNode struct {
+		Name          string `json:"name"`
+		Role          string `json:"role"`
+		Dir           string `json:"dir"`
+		PrimarySeed   string `json:"primaryseed"`
+		APIAddress    string `json:"apiaddress"`
+		RPCAddress    string `json:"rpcaddress"`
+		HostAddress   string `json:"hostaddress,omitempty"`
+		SiaMuxAddress string `json:"siamuxaddress,omitempty"`
+	}
+
+	// devnetState is the persisted state of a devnet.
+	devnetState struct {
+		GenesisTimestamp types.Timestamp `json:"genesistimestamp"`
+		Spec             devnetSpec      `json:"spec"`
+		Nodes            []devnetNode    `json:"nodes"`
+	}
+
+	// devnet is a running devnet.
+	devnet struct {
+		dir   string
+		state devnetState
+		nodes []*siatest.TestNode
+	}
+)
+
+// loadSpec loads and validates a devnet spec.
+func loadSpec(path string) (devnetSpec, error) {
+	var spec devnetSpec
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return spec, errors.AddContext(err, "failed to read spec")
+	}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&spec); err != nil {
+		return spec, errors.AddContext(err, "failed to parse spec")
+	}
+	return spec, spec.validate()
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The allowance defaults to the
+// allowance of siatest.
+func (rs *renterSpec) UnmarshalJSON(b []byte) error {
+	type spec renterSpec
+	s := spec{Allowance: siatest.DefaultAllowance}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&s); err != nil {
+		return err
+	}
+	*rs = renterSpec(s)
+	return nil
+}
+
+// validate checks that the spec describes a valid devnet.
+func (spec devnetSpec) validate() error {
+	if spec.Miners < 1 {
+		return errNoMiners
+	}
+	if _, err := spec.blockInterval(); err != nil {
+		return err
+	}
+	for _, h := range spec.Hosts {
+		if h.Count < 1 {
+			return errors.New("the count of hosts needs to be positive")
+		}
+	}
+	for _, r := range spec.Renters {
+		if r.Count < 1 {
+			return errors.New("the count of renters needs to be positive")
+		}
+		if r.Allowance.Hosts > uint64(spec.numHosts()) {
+			return fmt.Errorf("the allowance requires %v hosts but the devnet only has %v", r.Allowance.Hosts, spec.numHosts())
+		}
+	}
+	if len(spec.Renters) > 0 && spec.numHosts() == 0 {
+		return errors.New("renters require hosts")
+	}
+	return nil
+}
+
+// blockInterval returns the parsed block interval of the spec.
+func (spec devnetSpec) blockInterval() (time.Duration, error) {
+	if spec.BlockInterval == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(spec.BlockInterval)
+	if err != nil {
+		return 0, errors.AddContext(err, "invalid block interval")
+	}
+	if d <= 0 {
+		return 0, errors.New("the block interval needs to be positive")
+	}
+	return d, nil
+}
+
+// numHosts returns the number of hosts of the spec.
+func (spec devnetSpec) numHosts() (n int) {
+	for _, h := range spec.Hosts {
+		n += h.Count
+	}
+	return n
+}
+
+// templateForRole returns the node template of a role.
+func templateForRole(role string) (node.NodeParams, error) {
+	switch role {
+	case roleMiner:
+		return node.MinerTemplate, nil
+	case roleHost:
+		return node.HostTemplate, nil
+	case roleRenter:
+		return node.RenterTemplate, nil
+	}
+	return node.NodeParams{}, fmt.Errorf("unknown role '%v'", role)
+}
+
+// localAddress returns the loopback address with the port of addr.
+func localAddress(addr string) (string, error) {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", err
+	}
+	return net.JoinHostPort("localhost", port), nil
+}
+
+// setGenesisTimestamp changes the timestamp of the genesis block. The testing
+// constants derive the genesis timestamp from the time the process started, so
+// it needs to be restored to load the blockchain of a previous run.
+func setGenesisTimestamp(timestamp types.Timestamp) {
+	types.GenesisTimestamp = timestamp
+	types.GenesisBlock.Timestamp = timestamp
+	types.GenesisID = types.GenesisBlock.ID()
+}
+
+// createDevnet creates a new devnet in dir from the spec.
+func createDevnet(dir string, spec devnetSpec) (_ *devnet, err error) {
+	dn := &devnet{
+		dir: dir,
+		state: devnetState{
+			GenesisTimestamp: types.GenesisTimestamp,
+			Spec:             spec,
+		},
+	}
+
+	// Create the miners first, they fund the other nodes.
+	miners := make([]node.NodeParams, spec.Miners)
+	for i := range miners {
+		miners[i] = node.MinerTemplate
+	}
+	tg, err := siatest.NewGroup(dir, miners...)
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to create miners")
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, tg.Close())
+		}
+	}()
+	for _, tn := range tg.Miners() {
+		dn.nodes = append(dn.nodes, tn)
+		dn.state.Nodes = append(dn.state.Nodes, devnetNode{Role: roleMiner})
+	}
+
+	// Add the hosts and apply their settings before the renters form
+	// contracts with them.
+	for _, h := range spec.Hosts {
+		params := node.HostTemplate
+		params.HostStorage = h.Storage
+		hosts, err := tg.AddNodeN(params, h.Count)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to create hosts")
+		}
+		for _, tn := range hosts {
+			for key, value := range h.Settings {
+				if err := tn.HostModifySettingPost(client.HostParam(key), value); err != nil {
+					return nil, errors.AddContext(err, fmt.Sprintf("failed to set host setting '%v'", key))
+				}
+			}
+			dn.nodes = append(dn.nodes, tn)
+			dn.state.Nodes = append(dn.state.Nodes, devnetNode{Role: roleHost})
+		}
+	}
+
+	// Add the renters.
+	for _, r := range spec.Renters {
+		params := node.RenterTemplate
+		params.Allowance = r.Allowance
+		renters, err := tg.AddNodeN(params, r.Count)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to create renters")
+		}
+		for _, tn := range renters {
+			dn.nodes = append(dn.nodes, tn)
+			dn.state.Nodes = append(dn.state.Nodes, devnetNode{Role: roleRenter})
+		}
+	}
+
+	// Record the nodes.
+	counts := make(map[string]int)
+	for i, tn := range dn.nodes {
+		n := &dn.state.Nodes[i]
+		counts[n.Role]++
+		n.Name = fmt.Sprintf("%v-%v", n.Role, counts[n.Role])
+		n.Dir, err = filepath.Rel(dir, tn.Dir)
+		if err != nil {
+			return nil, err
+		}
+		n.PrimarySeed = tn.PrimarySeed()
+		n.APIAddress, err = localAddress(tn.APIAddress())
+		if err != nil {
+			return nil, err
+		}
+		n.RPCAddress = string(tn.GatewayAddress())
+		if n.Role != roleHost {
+			continue
+		}
+		hg, err := tn.HostGet()
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to get host addresses")
+		}
+		n.HostAddress, err = localAddress(string(hg.ExternalSettings.NetAddress))
+		if err != nil {
+			return nil, err
+		}
+		n.SiaMuxAddress = net.JoinHostPort("localhost", hg.ExternalSettings.SiaMuxPort)
+	}
+	if err := dn.confirmTransactions(); err != nil {
+		return nil, errors.AddContext(err, "failed to confirm transactions")
+	}
+	return dn, persist.SaveJSON(stateMetadata, dn.state, filepath.Join(dir, stateFile))
+}
+
+// loadDevnet starts the nodes of an existing devnet in dir.
+func loadDevnet(dir string) (_ *devnet, err error) {
+	dn := &devnet{dir: dir}
+	if err := persist.LoadJSON(stateMetadata, &dn.state, filepath.Join(dir, stateFile)); err != nil {
+		return nil, errors.AddContext(err, "failed to load devnet state")
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, dn.close())
+		}
+	}()
+	setGenesisTimestamp(dn.state.GenesisTimestamp)
+	for _, n := range dn.state.Nodes {
+		params, err := templateForRole(n.Role)
+		if err != nil {
+			return nil, err
+		}
+		params.Dir = filepath.Join(dir, n.Dir)
+		params.RPCAddress = n.RPCAddress
+		params.HostAddress = n.HostAddress
+		params.SiaMuxTCPAddress = n.SiaMuxAddress
+		if params.SiaMuxTCPAddress == "" {
+			params.SiaMuxTCPAddress = "localhost:0"
+		}
+		params.SiaMuxWSAddress = "localhost:0"
+		tn, err := siatest.LoadNode(n.APIAddress, params, n.PrimarySeed)
+		if err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("failed to start %v", n.Name))
+		}
+		dn.nodes = append(dn.nodes, tn)
+	}
+	// Reconnect the nodes to the first miner.
+	for _, tn := range dn.nodes[1:] {
+		err := tn.GatewayConnectPost(dn.nodes[0].GatewayAddress())
+		if err != nil && !errors.Contains(err, client.ErrPeerExists) {
+			return nil, errors.AddContext(err, "failed to connect nodes")
+		}
+	}
+	return dn, nil
+}
+
+// miner returns the node that mines blocks.
+func (dn *devnet) miner() *siatest.TestNode {
+	return dn.nodes[0]
+}
+
+// confirmTransactions mines blocks until the transaction pools of all nodes
+// are empty. Unconfirmed transactions are not persisted by the transaction
+// pool, so confirming them before stopping the devnet prevents the wallets
+// from rebroadcasting transactions with missing parents after a restart.
+func (dn *devnet) confirmTransactions() error {
+	return build.Retry(10, 500*time.Millisecond, func() error {
+		for _, tn := range dn.nodes {
+			tptg, err := tn.TransactionPoolTransactionsGet()
+			if err != nil {
+				return err
+			}
+			if len(tptg.Transactions) > 0 {
+				return errors.Compose(errors.New("transaction pool isn't empty"), dn.miner().MineBlock())
+			}
+		}
+		return nil
+	})
+}
+
+// stop confirms the pending transactions and stops the nodes of the devnet.
+func (dn *devnet) stop() error {
+	err := errors.AddContext(dn.confirmTransactions(), "failed to confirm transactions")
+	return errors.Compose(err, dn.close())
+}
+
+// close stops the nodes of the devnet.
+func (dn *devnet) close() error {
+	var errs []error
+	for _, tn := range dn.nodes {
+		errs = append(errs, tn.Close())
+	}
+	return errors.Compose(errs...)
+}
+
+// threadedMine mines a block every interval until stop is closed.
+func (dn *devnet) threadedMine(interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		if err := dn.miner().MineBlock(); err != nil {
+			fmt.Fprintln(os.Stderr, "Failed to mine block:", err)
+		}
+	}
+}
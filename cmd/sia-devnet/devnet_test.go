@@ -0,0 +1,145 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/siatest"
+)
+
+// TestLoadSpec tests loading and validating devnet specs.
+func TestLoadSpec(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("sia-devnet", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		spec  string
+		valid bool
+	}{
+		{`{"miners": 1}`, true},
+		{`{"miners": 1, "blockinterval": "5s", "hosts": [{"count": 2, "storage": 1024, "settings": {"mincontractprice": "1"}}], "renters": [{"count": 1, "allowance": {"hosts": 2}}]}`, true},
+		{`{"hosts": [{"count": 1}]}`, false},
+		{`{"miners": 1, "blockinterval": "soon"}`, false},
+		{`{"miners": 1, "blockinterval": "-1s"}`, false},
+		{`{"miners": 1, "hosts": [{"count": 0}]}`, false},
+		{`{"miners": 1, "renters": [{"count": 1}]}`, false},
+		{`{"miners": 1, "hosts": [{"count": 1}], "renters": [{"count": 1, "allowance": {"hosts": 2}}]}`, false},
+		{`{"miners": 1, "unknown": true}`, false},
+		{`{"miners": 1, "hosts": [{"count": 2}], "renters": [{"count": 1, "allowance": {"hosts": 2, "unknown": 1}}]}`, false},
+	}
+	for i, test := range tests {
+		path := filepath.Join(dir, "spec.json")
+		if err := os.WriteFile(path, []byte(test.spec), 0600); err != nil {
+			t.Fatal(err)
+		}
+		_, err := loadSpec(path)
+		if test.valid && err != nil {
+			t.Fatal(i, err)
+		} else if !test.valid && err == nil {
+			t.Fatal(i, "invalid spec was accepted")
+		}
+	}
+
+	// Unset allowance fields default to the siatest allowance.
+	path := filepath.Join(dir, "spec.json")
+	if err := os.WriteFile(path, []byte(`{"miners": 1, "hosts": [{"count": 2}], "renters": [{"count": 1, "allowance": {"hosts": 2}}]}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	spec, err := loadSpec(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a := spec.Renters[0].Allowance; a.Hosts != 2 || a.Period != siatest.DefaultAllowance.Period || !a.Funds.Equals(siatest.DefaultAllowance.Funds) {
+		t.Fatal("unexpected allowance", a)
+	}
+	if interval, _ := spec.blockInterval(); interval != 0 {
+		t.Fatal("unexpected interval", interval)
+	}
+	if _, err := loadSpec(filepath.Join(dir, "missing.json")); err == nil {
+		t.Fatal("missing spec was loaded")
+	}
+	if err := (devnetSpec{}).validate(); !errors.Contains(err, errNoMiners) {
+		t.Fatal("expected errNoMiners, got", err)
+	}
+}
+
+// TestDevnet tests creating a devnet and restarting it.
+func TestDevnet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("sia-devnet", t.Name())
+	spec := devnetSpec{
+		Miners:        1,
+		BlockInterval: "100ms",
+		Hosts:         []hostSpec{{Count: 2, Settings: map[string]string{"mincontractprice": "1000"}}},
+	}
+	dn, err := createDevnet(dir, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dn.state.Nodes) != 3 || dn.state.Nodes[0].Name != "miner-1" || dn.state.Nodes[2].Name != "host-2" {
+		t.Fatal("unexpected nodes", dn.state.Nodes)
+	}
+	hg, err := dn.nodes[1].HostGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hg.InternalSettings.MinContractPrice.String() != "1000" {
+		t.Fatal("host settings weren't applied", hg.InternalSettings.MinContractPrice)
+	}
+	cg, err := dn.miner().ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dn.stop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart the devnet and mine some blocks.
+	dn, err = loadDevnet(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	defer func() {
+		close(stop)
+		<-done
+		if err := dn.stop(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	for i, n := range dn.state.Nodes {
+		if dn.nodes[i].APIAddress() != n.APIAddress && dn.nodes[i].APIAddress() != "127.0.0.1"+n.APIAddress[len("localhost"):] {
+			t.Fatal("API address changed", dn.nodes[i].APIAddress(), n.APIAddress)
+		}
+	}
+	go func() {
+		dn.threadedMine(100*time.Millisecond, stop)
+		close(done)
+	}()
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		for _, tn := range dn.nodes {
+			cg2, err := tn.ConsensusGet()
+			if err != nil {
+				return err
+			}
+			if cg2.Height <= cg.Height+2 {
+				return errors.New("blocks weren't mined")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
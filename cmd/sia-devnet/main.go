@@ -0,0 +1,112 @@
+// sia-devnet starts a local Sia network for development. The network consists
+// of miners, hosts and renters that are connected to each other and funded,
+// and is described by a JSON spec:
+//
+//	{
+//	  "miners": 1,
+//	  "blockinterval": "10s",
+//	  "hosts": [{"count": 5, "storage": 1073741824, "settings": {"mincontractprice": "1000000000000000000000000"}}],
+//	  "renters": [{"count": 1, "allowance": {"funds": "1000000000000000000000000000", "hosts": 5}}]
+//	}
+//
+// The nodes are persisted in the devnet's directory and restarted with the
+// same addresses on the next run. sia-devnet needs to be built with the
+// 'testing' build tag, see 'make devnet'.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"text/tabwriter"
+
+	"go.sia.tech/siad/build"
+)
+
+// die prints its arguments to stderr and exits.
+func die(args ...interface{}) {
+	fmt.Fprintln(os.Stderr, args...)
+	os.Exit(1)
+}
+
+func main() {
+	dir := flag.String("d", "devnet", "directory of the devnet")
+	specPath := flag.String("spec", "", "path of the devnet spec, required when creating a devnet")
+	flag.Parse()
+
+	if build.Release != "testing" {
+		die("sia-devnet needs to be built with the 'testing' build tag, e.g. with 'make devnet'")
+	}
+	absDir, err := filepath.Abs(*dir)
+	if err != nil {
+		die("Invalid directory:", err)
+	}
+
+	// Load the devnet if it exists, otherwise create it from the spec.
+	var dn *devnet
+	if _, err := os.Stat(filepath.Join(absDir, stateFile)); err == nil {
+		if *specPath != "" {
+			die("The devnet already exists, remove", absDir, "to create it from a new spec")
+		}
+		fmt.Println("Starting devnet in", absDir)
+		dn, err = loadDevnet(absDir)
+		if err != nil {
+			die("Could not start devnet:", err)
+		}
+	} else {
+		if *specPath == "" {
+			die("No devnet in", absDir, "- use -spec to create one")
+		}
+		spec, err := loadSpec(*specPath)
+		if err != nil {
+			die("Invalid spec:", err)
+		}
+		fmt.Println("Creating devnet in", absDir, "- this might take a few minutes")
+		dn, err = createDevnet(absDir, spec)
+		if err != nil {
+			die("Could not create devnet:", err)
+		}
+	}
+
+	// Print the nodes.
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tAPI Address\tGateway Address\tHost Address")
+	for _, n := range dn.state.Nodes {
+		hostAddr := n.HostAddress
+		if hostAddr == "" {
+			hostAddr = "-"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\n", n.Name, n.APIAddress, n.RPCAddress, hostAddr)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+	fmt.Println("API password: password")
+	fmt.Println("User agent:   Sia-Agent")
+
+	// Mine blocks until the devnet is stopped.
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	interval, _ := dn.state.Spec.blockInterval()
+	if interval > 0 {
+		fmt.Println("Mining a block every", interval)
+		go func() {
+			dn.threadedMine(interval, stop)
+			close(done)
+		}()
+	} else {
+		close(done)
+	}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	<-sigChan
+	close(stop)
+	<-done
+	fmt.Println("Stopping devnet...")
+	if err := dn.stop(); err != nil {
+		die("Could not stop devnet:", err)
+	}
+}
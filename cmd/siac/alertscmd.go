@@ -0,0 +1,163 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/node/api"
+)
+
+var (
+	alertsHistoryCmd = &cobra.Command{
+		Use:   "history",
+		Short: "View the alert history",
+		Long: `View the alert history. The history contains all alerts that were
+registered, including the ones that were unregistered in the meantime, the most
+recent alerts first.`,
+		Run: wrap(alertshistorycmd),
+	}
+
+	alertsHooksCmd = &cobra.Command{
+		Use:   "hooks",
+		Short: "List the alert hooks",
+		Long: `List the alert hooks. Alert hooks are webhooks or local commands that are
+notified when alerts are registered or unregistered. Command hooks can only be
+set in the 'daemon.alerthooks' section of siad's config file.`,
+		Run: wrap(alertshookscmd),
+	}
+
+	alertsHooksAddCmd = &cobra.Command{
+		Use:   "add [name]",
+		Short: "Add an alert hook",
+		Long: `Add a webhook. Webhooks receive the alert event as JSON in a POST request.
+The event is either 'registered' or 'unregistered'. Command hooks can only be
+set in the 'daemon.alerthooks' section of siad's config file.
+
+By default a hook is notified about all alerts. Use --modules and --severities
+to filter them.`,
+		Run: wrap(alertshooksaddcmd),
+	}
+
+	alertsHooksRemoveCmd = &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove an alert hook",
+		Long:  "Remove an alert hook.",
+		Run:   wrap(alertshooksremovecmd),
+	}
+)
+
+// alertshistorycmd is the handler for the command `siac alerts history`.
+// Prints the alert history.
+func alertshistorycmd() {
+	var severity modules.AlertSeverity
+	if alertsSeverity != "" {
+		if err := severity.UnmarshalJSON([]byte(fmt.Sprintf("%q", alertsSeverity))); err != nil {
+			die("Could not parse severity:", err)
+		}
+	}
+	dahg, err := httpClient.DaemonAlertsHistoryGet(alertsModule, severity)
+	if err != nil {
+		die("Could not get alert history:", err)
+	}
+	if len(dahg.History) == 0 {
+		fmt.Println("The alert history is empty.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "First Seen\tCleared\tModule\tSeverity\tMessage\tCause")
+	for _, entry := range dahg.History {
+		cleared := "active"
+		if !entry.Cleared.IsZero() {
+			cleared = entry.Cleared.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", entry.FirstSeen.Format(time.RFC3339), cleared, entry.Module, entry.Severity, entry.Msg, entry.Cause)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// alertshookscmd is the handler for the command `siac alerts hooks`.
+// Lists the alert hooks.
+func alertshookscmd() {
+	dahg, err := httpClient.DaemonAlertsHooksGet()
+	if err != nil {
+		die("Could not get alert hooks:", err)
+	}
+	if len(dahg.Hooks) == 0 {
+		fmt.Println("No alert hooks.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tTarget\tModules\tSeverities\tLast Delivery\tLast Error")
+	for _, h := range dahg.Hooks {
+		target := h.URL
+		if target == "" {
+			target = h.Command
+		}
+		mods := "all"
+		if len(h.Modules) > 0 {
+			mods = strings.Join(h.Modules, ",")
+		}
+		severities := "all"
+		if len(h.Severities) > 0 {
+			var strs []string
+			for _, s := range h.Severities {
+				strs = append(strs, s.String())
+			}
+			severities = strings.Join(strs, ",")
+		}
+		lastDelivery := "never"
+		if !h.LastDelivery.IsZero() {
+			lastDelivery = h.LastDelivery.Format(time.RFC3339)
+		}
+		lastError := h.LastError
+		if lastError == "" {
+			lastError = "-"
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", h.Name, target, mods, severities, lastDelivery, lastError)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// alertshooksaddcmd is the handler for the command `siac alerts hooks add
+// [name]`. Adds an alert hook.
+func alertshooksaddcmd(name string) {
+	hook := api.AlertHook{
+		Name: name,
+		URL:  alertHookURL,
+	}
+	if alertHookModules != "" {
+		hook.Modules = strings.Split(alertHookModules, ",")
+	}
+	if alertHookSeverities != "" {
+		for _, str := range strings.Split(alertHookSeverities, ",") {
+			var severity modules.AlertSeverity
+			if err := severity.UnmarshalJSON([]byte(fmt.Sprintf("%q", strings.TrimSpace(str)))); err != nil {
+				die("Could not parse severities:", err)
+			}
+			hook.Severities = append(hook.Severities, severity)
+		}
+	}
+	if err := httpClient.DaemonAlertsHooksAddPost(hook); err != nil {
+		die("Could not add alert hook:", err)
+	}
+	fmt.Printf("Added alert hook '%v'\n", name)
+}
+
+// alertshooksremovecmd is the handler for the command `siac alerts hooks remove
+// [name]`. Removes an alert hook.
+func alertshooksremovecmd(name string) {
+	if err := httpClient.DaemonAlertsHooksRemovePost(name); err != nil {
+		die("Could not remove alert hook:", err)
+	}
+	fmt.Printf("Removed alert hook '%v'\n", name)
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"text/tabwriter"
 
 	"github.com/spf13/cobra"
 
@@ -44,6 +46,70 @@ Set them to 0 for no limit.`,
 		Run: wrap(globalratelimitcmd),
 	}
 
+	metricsCmd = &cobra.Command{
+		Use:   "metrics",
+		Short: "Print the daemon's metrics",
+		Long:  "Print the metrics of all loaded modules in the Prometheus text format.",
+		Run:   wrap(metricscmd),
+	}
+
+	metricsEnableCmd = &cobra.Command{
+		Use:   "enable",
+		Short: "Enable the /metrics endpoint",
+		Long:  "Enable the /metrics endpoint of the API for scraping by Prometheus.",
+		Run:   wrap(metricsenablecmd),
+	}
+
+	metricsDisableCmd = &cobra.Command{
+		Use:   "disable",
+		Short: "Disable the /metrics endpoint",
+		Long:  "Disable the /metrics endpoint of the API.",
+		Run:   wrap(metricsdisablecmd),
+	}
+
+	daemonReloadCmd = &cobra.Command{
+		Use:   "reload",
+		Short: "Reload the daemon's config file",
+		Long: `Reload the config file siad was started with and apply it to the loaded
+modules. Prints the changed settings. Changes of the startup flags in the
+'siad' section only take effect after a restart.`,
+		Run: wrap(daemonreloadcmd),
+	}
+
+	daemonLoggingCmd = &cobra.Command{
+		Use:   "logging",
+		Short: "Show the daemon's log settings",
+		Long: `Show the log format, whether the logs are written to stdout and the log
+levels of the modules. Modules without a level use the default level.`,
+		Run: wrap(daemonloggingcmd),
+	}
+
+	daemonLoggingFormatCmd = &cobra.Command{
+		Use:   "format [text|json]",
+		Short: "Set the log format",
+		Long: `Set the format of the log entries of all modules. JSON entries contain the
+time, level, module, caller and message of the entry as well as the fields of
+the entry, e.g. the siapath of a file or the key of a host.`,
+		Run: wrap(daemonloggingformatcmd),
+	}
+
+	daemonLoggingLevelCmd = &cobra.Command{
+		Use:   "level [module] [level]",
+		Short: "Set the log level of a module",
+		Long: `Set the log level of a module to debug, info, warn, error or critical.
+Entries below the level are discarded. Use 'default' as the module to set the
+level of all modules without a level and 'unset' as the level to make a module
+use the default level again.`,
+		Run: wrap(daemonlogginglevelcmd),
+	}
+
+	daemonLoggingStdoutCmd = &cobra.Command{
+		Use:   "stdout [true|false]",
+		Short: "Write the logs of all modules to stdout",
+		Long:  "Enable or disable writing the logs of all modules to the stdout of siad.",
+		Run:   wrap(daemonloggingstdoutcmd),
+	}
+
 	profileCmd = &cobra.Command{
 		Use:   "profile",
 		Short: "Start and stop profiles for the daemon",
@@ -290,6 +356,127 @@ func globalratelimitcmd(downloadSpeedStr, uploadSpeedStr string) {
 	fmt.Println("Set global maxdownloadspeed to ", downloadSpeedInt, " and maxuploadspeed to ", uploadSpeedInt)
 }
 
+// metricscmd is the handler for the command `siac metrics`.
+// Prints the metrics of all loaded modules.
+func metricscmd() {
+	metrics, err := httpClient.MetricsGet()
+	if err != nil {
+		die("Could not get metrics:", err)
+	}
+	fmt.Print(string(metrics))
+}
+
+// metricsenablecmd is the handler for the command `siac metrics enable`.
+// Enables the /metrics endpoint.
+func metricsenablecmd() {
+	err := httpClient.DaemonEnableMetricsPost(true)
+	if err != nil {
+		die("Could not enable the metrics endpoint:", err)
+	}
+	fmt.Println("Enabled the metrics endpoint")
+}
+
+// metricsdisablecmd is the handler for the command `siac metrics disable`.
+// Disables the /metrics endpoint.
+func metricsdisablecmd() {
+	err := httpClient.DaemonEnableMetricsPost(false)
+	if err != nil {
+		die("Could not disable the metrics endpoint:", err)
+	}
+	fmt.Println("Disabled the metrics endpoint")
+}
+
+// daemonreloadcmd is the handler for the command `siac daemon reload`.
+// Reloads the daemon's config file.
+func daemonreloadcmd() {
+	drp, err := httpClient.DaemonReloadPost()
+	if err != nil {
+		die("Could not reload the config file:", err)
+	}
+	if len(drp.Changes) == 0 {
+		fmt.Println("Reloaded the config file, no settings changed")
+		return
+	}
+	fmt.Println("Reloaded the config file, changed settings:")
+	for _, c := range drp.Changes {
+		fmt.Printf("  %v: %v -> %v\n", c.Setting, c.Old, c.New)
+	}
+}
+
+// daemonloggingcmd is the handler for the command `siac daemon logging`.
+// Prints the log settings of the daemon.
+func daemonloggingcmd() {
+	dlg, err := httpClient.DaemonLoggingGet()
+	if err != nil {
+		die("Could not get the log settings:", err)
+	}
+	fmt.Println("Format:       ", dlg.Format)
+	fmt.Println("Stdout:       ", yesNo(dlg.Stdout))
+	fmt.Println("Default Level:", dlg.DefaultLevel)
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Module\tLevel")
+	for _, m := range dlg.Modules {
+		level, exists := dlg.Levels[m]
+		if !exists {
+			fmt.Fprintf(w, "%v\t%v (default)\n", m, dlg.DefaultLevel)
+			continue
+		}
+		fmt.Fprintf(w, "%v\t%v\n", m, level)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// daemonloggingformatcmd is the handler for the command `siac daemon logging
+// format`. Sets the log format.
+func daemonloggingformatcmd(format string) {
+	if err := httpClient.DaemonLoggingFormatPost(format); err != nil {
+		die("Could not set the log format:", err)
+	}
+	fmt.Println("Set the log format to", format)
+}
+
+// daemonlogginglevelcmd is the handler for the command `siac daemon logging
+// level`. Sets the log level of a module.
+func daemonlogginglevelcmd(module, level string) {
+	if module == "default" {
+		module = ""
+	}
+	if level == "unset" {
+		level = ""
+	}
+	if err := httpClient.DaemonLoggingLevelPost(module, level); err != nil {
+		die("Could not set the log level:", err)
+	}
+	switch {
+	case module == "":
+		fmt.Println("Set the default log level to", level)
+	case level == "":
+		fmt.Printf("The %v module uses the default log level\n", module)
+	default:
+		fmt.Printf("Set the log level of the %v module to %v\n", module, level)
+	}
+}
+
+// daemonloggingstdoutcmd is the handler for the command `siac daemon logging
+// stdout`. Enables or disables writing the logs to stdout.
+func daemonloggingstdoutcmd(stdout string) {
+	enable, err := strconv.ParseBool(stdout)
+	if err != nil {
+		die("Could not parse argument, use true or false:", err)
+	}
+	if err := httpClient.DaemonLoggingStdoutPost(enable); err != nil {
+		die("Could not change writing the logs to stdout:", err)
+	}
+	if enable {
+		fmt.Println("The logs are written to stdout")
+	} else {
+		fmt.Println("The logs aren't written to stdout anymore")
+	}
+}
+
 // printAlerts is a helper function to print details of a slice of alerts
 // with given severity description to command line
 func printAlerts(alerts []modules.Alert, as modules.AlertSeverity) {
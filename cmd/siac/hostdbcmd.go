@@ -1,7 +1,9 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"math/big"
 	"os"
 	"strings"
@@ -47,6 +49,36 @@ var (
 		Run: hostdbsetfiltermodecmd,
 	}
 
+	hostdbScoringCmd = &cobra.Command{
+		Use:   "scoring",
+		Short: "View the host scoring rules.",
+		Long:  "View the user defined rules that adjust the scores of the hosts in the hostdb.",
+		Run:   wrap(hostdbscoringcmd),
+	}
+
+	hostdbSetScoringCmd = &cobra.Command{
+		Use:   "setscoring [rules.json]",
+		Short: "Set the host scoring rules.",
+		Long: `Replace the host scoring rules with the rules from the given JSON file. The file
+contains a list of rules, each of which compares a field of the host to a value
+and either multiplies the host's score or rejects the host, e.g.
+
+{"rules": [
+  {"field": "storageprice", "comparator": ">", "value": "1KS", "reject": true},
+  {"field": "latency", "comparator": "<", "value": "100ms", "multiplier": 2}
+]}
+
+An empty list of rules removes all custom scoring.`,
+		Run: wrap(hostdbsetscoringcmd),
+	}
+
+	hostdbPreviewScoringCmd = &cobra.Command{
+		Use:   "previewscoring [rules.json]",
+		Short: "Preview the effect of host scoring rules.",
+		Long:  "Show the scores the active hosts would have if the scoring rules from the given JSON file were applied, without applying them.",
+		Run:   wrap(hostdbpreviewscoringcmd),
+	}
+
 	hostdbViewCmd = &cobra.Command{
 		Use:   "view [pubkey]",
 		Short: "View the full information for a host.",
@@ -60,12 +92,16 @@ func printScoreBreakdown(info *api.HostdbHostsGET) {
 	fmt.Println("\n  Score Breakdown:")
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
 	fmt.Fprintf(w, "\t\tAge:\t %.3f\n", info.ScoreBreakdown.AgeAdjustment)
+	fmt.Fprintf(w, "\t\tAudit:\t %.3f\n", info.ScoreBreakdown.AuditAdjustment)
 	fmt.Fprintf(w, "\t\tBase Price:\t %.3f\n", info.ScoreBreakdown.BasePriceAdjustment)
 	fmt.Fprintf(w, "\t\tBurn:\t %.3f\n", info.ScoreBreakdown.BurnAdjustment)
 	fmt.Fprintf(w, "\t\tCollateral:\t %.3f\n", info.ScoreBreakdown.CollateralAdjustment/1e96)
+	fmt.Fprintf(w, "\t\tCustom:\t %.3f\n", info.ScoreBreakdown.CustomAdjustment)
 	fmt.Fprintf(w, "\t\tDuration:\t %.3f\n", info.ScoreBreakdown.DurationAdjustment)
 	fmt.Fprintf(w, "\t\tInteraction:\t %.3f\n", info.ScoreBreakdown.InteractionAdjustment)
+	fmt.Fprintf(w, "\t\tLatency:\t %.3f\n", info.ScoreBreakdown.LatencyAdjustment)
 	fmt.Fprintf(w, "\t\tPrice:\t %.3f\n", info.ScoreBreakdown.PriceAdjustment*1e24)
+	fmt.Fprintf(w, "\t\tRegion:\t %.3f\n", info.ScoreBreakdown.RegionAdjustment)
 	fmt.Fprintf(w, "\t\tStorage:\t %.3f\n", info.ScoreBreakdown.StorageRemainingAdjustment)
 	fmt.Fprintf(w, "\t\tUptime:\t %.3f\n", info.ScoreBreakdown.UptimeAdjustment)
 	fmt.Fprintf(w, "\t\tVersion:\t %.3f\n", info.ScoreBreakdown.VersionAdjustment)
@@ -379,6 +415,72 @@ func hostdbsetfiltermodecmd(cmd *cobra.Command, args []string) {
 	fmt.Println("Successfully set the filter mode")
 }
 
+// readHostScoringRules reads a set of host scoring rules from a JSON file.
+func readHostScoringRules(path string) modules.HostScoringRules {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		die("Could not read scoring rules:", err)
+	}
+	var rules modules.HostScoringRules
+	if err := json.Unmarshal(data, &rules); err != nil {
+		die("Could not parse scoring rules:", err)
+	}
+	return rules
+}
+
+// hostdbscoringcmd is the handler for the command `siac hostdb scoring`.
+func hostdbscoringcmd() {
+	hdsg, err := httpClient.HostDbScoringGet()
+	if err != nil {
+		die("Could not get host scoring rules:", err)
+	}
+	if len(hdsg.Rules) == 0 {
+		fmt.Println("No host scoring rules set.")
+		return
+	}
+	fmt.Println("Host Scoring Rules:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  Field\tComparator\tValue\tEffect")
+	for _, rule := range hdsg.Rules {
+		effect := fmt.Sprintf("x%v", rule.Multiplier)
+		if rule.Reject {
+			effect = "reject"
+		}
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%v\n", rule.Field, rule.Comparator, rule.Value, effect)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// hostdbsetscoringcmd is the handler for the command `siac hostdb
+// setscoring`.
+func hostdbsetscoringcmd(path string) {
+	err := httpClient.HostDbScoringPost(readHostScoringRules(path))
+	if err != nil {
+		die("Could not set host scoring rules:", err)
+	}
+	fmt.Println("Successfully set the host scoring rules")
+}
+
+// hostdbpreviewscoringcmd is the handler for the command `siac hostdb
+// previewscoring`.
+func hostdbpreviewscoringcmd(path string) {
+	hdspp, err := httpClient.HostDbScoringPreviewPost(readHostScoringRules(path))
+	if err != nil {
+		die("Could not preview host scoring rules:", err)
+	}
+	fmt.Println(len(hdspp.Hosts), "Active Hosts:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\t\tAddress\tCustom\tCurrent Score\tNew Score")
+	for i, host := range hdspp.Hosts {
+		fmt.Fprintf(w, "\t%v:\t%v\t%.3f\t%v\t%v\n", len(hdspp.Hosts)-i, host.NetAddress, host.ScoreBreakdown.CustomAdjustment, host.CurrentScore, host.ScoreBreakdown.Score)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
 // hostdbviewcmd is the handler for the command `siac hostdb view`.
 // shows detailed information about a host in the hostdb.
 func hostdbviewcmd(pubkey string) {
@@ -458,5 +560,12 @@ func hostdbviewcmd(pubkey string) {
 	fmt.Println("  Recent Successful Interactions:   ", info.Entry.RecentSuccessfulInteractions)
 	fmt.Printf("  Overall Uptime:                    %.3f\n", uptimeRatio)
 
+	// Print the performance measured by the renter's workers.
+	if !info.Entry.LastLatencyUpdate.IsZero() {
+		fmt.Println("  Measured Latency:                 ", info.Entry.Latency)
+		fmt.Println("  Measured Throughput:              ", modules.FilesizeUnits(uint64(info.Entry.Throughput))+"/s")
+		fmt.Println("  Last Latency Update:              ", info.Entry.LastLatencyUpdate)
+	}
+
 	fmt.Println()
 }
@@ -21,33 +21,65 @@ var (
 	siaDir        string // Path to sia data dir
 	verbose       bool   // Display additional information
 
+	// API TLS Flags
+	apiTLS        bool              // Connect to the API over TLS
+	apiTLSOptions client.TLSOptions // The options for connecting over TLS
+
 	// Module Specific Flags
 	//
 	// Daemon Flags
-	daemonStackOutputFile  string // The file that the stack trace will be written to
-	daemonCPUProfile       bool   // Indicates that the CPU profile should be started
-	daemonMemoryProfile    bool   // Indicates that the Memory profile should be started
-	daemonProfileDirectory string // The Directory where the profile logs are saved
-	daemonTraceProfile     bool   // Indicates that the Trace profile should be started
+	daemonStackOutputFile    string // The file that the stack trace will be written to
+	daemonCPUProfile         bool   // Indicates that the CPU profile should be started
+	daemonMemoryProfile      bool   // Indicates that the Memory profile should be started
+	daemonProfileDirectory   string // The Directory where the profile logs are saved
+	daemonTraceProfile       bool   // Indicates that the Trace profile should be started
+	daemonTokenExpiry        string // The duration after which a new API token expires
+	daemonTokenSpendingLimit string // The amount of siacoins a new API token can send
+	daemonTokenTenant        string // The tenant whose files a new API token can access
+	alertsModule             string // The module the alert history is filtered by
+	alertsSeverity           string // The severity the alert history is filtered by
+	alertHookURL             string // The webhook URL of a new alert hook
+	alertHookModules         string // The modules a new alert hook is filtered by
+	alertHookSeverities      string // The severities a new alert hook is filtered by
 
 	// Host Flags
 	hostContractOutputType string // output type for host contracts
 	hostFolderRemoveForce  bool   // force folder remove
 
 	// Renter Flags
-	dataPieces                string // the number of data pieces a file should be uploaded with
-	parityPieces              string // the number of parity pieces a file should be uploaded with
-	renterAllContracts        bool   // Show all active and expired contracts
-	renterBubbleAll           bool   // Bubble the entire directory tree
-	renterDeleteRoot          bool   // Delete path start from root instead of the UserFolder.
-	renterDownloadAsync       bool   // Downloads files asynchronously
-	renterDownloadRecursive   bool   // Downloads folders recursively.
-	renterDownloadRoot        bool   // Download path start from root instead of the UserFolder.
-	renterFuseMountAllowOther bool   // Mount fuse with 'AllowOther' set to true.
-	renterListRecursive       bool   // List files of folder recursively.
-	renterListRoot            bool   // List path start from root instead of the UserFolder.
-	renterRenameRoot          bool   // Rename files relative to root instead of the UserFolder.
-	renterShowHistory         bool   // Show download history in addition to download queue.
+	dataPieces                string   // the number of data pieces a file should be uploaded with
+	parityPieces              string   // the number of parity pieces a file should be uploaded with
+	renterUploadCompression   string   // the compression a file should be uploaded with
+	renterUploadChecksum      string   // the checksum type a file should be uploaded with
+	renterPolicyCipherType    string   // the cipher type of a storage policy
+	renterPolicyThreshold     string   // the repair threshold of a storage policy
+	renterPolicyPriority      string   // the repair priority of a storage policy
+	renterPolicyAllowedHosts  string   // the hosts allowed by a storage policy
+	renterReencodeCipherType  string   // the cipher type a file should be re-encoded with
+	renterAllContracts        bool     // Show all active and expired contracts
+	renterBubbleAll           bool     // Bubble the entire directory tree
+	renterDeleteRoot          bool     // Delete path start from root instead of the UserFolder.
+	renterDownloadAsync       bool     // Downloads files asynchronously
+	renterDownloadRecursive   bool     // Downloads folders recursively.
+	renterVerifyRecursive     bool     // Verifies all files within a folder
+	renterFindSort            string   // the field found files are sorted by
+	renterFindDescending      bool     // sort found files in descending order
+	renterFindLimit           int      // the maximum number of found files
+	renterFindTags            []string // the tags found files need to have
+	renterSyncDelete          bool     // delete remote files which don't exist locally
+	renterSyncDryRun          bool     // only print the planned changes of a sync
+	renterSyncExclude         []string // globs of the files excluded from a sync
+	renterSyncInclude         []string // globs of the files included in a sync
+	renterTenantStorageQuota  string   // the storage quota of a tenant
+	renterTenantUploadQuota   string   // the upload quota of a tenant
+	renterTenantDownloadQuota string   // the download quota of a tenant
+	renterDownloadRoot        bool     // Download path start from root instead of the UserFolder.
+	renterFuseMountAllowOther bool     // Mount fuse with 'AllowOther' set to true.
+	renterListRecursive       bool     // List files of folder recursively.
+	renterListRoot            bool     // List path start from root instead of the UserFolder.
+	renterRenameRoot          bool     // Rename files relative to root instead of the UserFolder.
+	renterCopyRoot            bool     // Copy files relative to root instead of the UserFolder.
+	renterShowHistory         bool     // Show download history in addition to download queue.
 
 	// Renter Allowance Flags
 	allowanceFunds       string // amount of money to be used within a period
@@ -85,6 +117,13 @@ var (
 	walletEndHeight      uint64 // End height for transaction search.
 	walletTxnFeeIncluded bool   // include the fee in the balance being sent
 	insecureInput        bool   // Insecure password/seed input. Disables the shoulder-surfing and Mac secure input feature.
+
+	// Wallet Policy Flags
+	walletPolicyDailyLimit           string // maximum amount of siacoins sent per day
+	walletPolicyApprovalThreshold    string // amount above which spends need to be approved
+	walletPolicyAllowedDestinations  string // comma-separated list of allowed addresses
+	walletPolicyMaxAllowanceFunds    string // maximum funds of the allowance
+	walletPolicyMaxAllowanceIncrease string // maximum increase of the allowance funds
 )
 
 var (
@@ -254,6 +293,9 @@ func main() {
 		// set API password if it was not set
 		setAPIPasswordIfNotSet()
 
+		// set the TLS config if the API is served over TLS
+		setAPITLSConfig()
+
 		// Check if the siaDir is set.
 		if siaDir == "" {
 			// No siaDir passed in, fetch the siaDir
@@ -304,7 +346,7 @@ func initCmds() *cobra.Command {
 	hostFolderRemoveCmd.Flags().BoolVarP(&hostFolderRemoveForce, "force", "f", false, "Force the removal of the folder and its data")
 
 	root.AddCommand(hostdbCmd)
-	hostdbCmd.AddCommand(hostdbFiltermodeCmd, hostdbSetFiltermodeCmd, hostdbViewCmd)
+	hostdbCmd.AddCommand(hostdbFiltermodeCmd, hostdbPreviewScoringCmd, hostdbScoringCmd, hostdbSetFiltermodeCmd, hostdbSetScoringCmd, hostdbViewCmd)
 	hostdbCmd.Flags().IntVarP(&hostdbNumHosts, "numhosts", "n", 0, "Number of hosts to display from the hostdb")
 
 	root.AddCommand(minerCmd)
@@ -316,9 +358,15 @@ func initCmds() *cobra.Command {
 		renterDownloadsCmd, renterExportCmd, renterFilesDeleteCmd, renterFilesDownloadCmd,
 		renterFilesListCmd, renterFilesRenameCmd, renterFilesUnstuckCmd, renterFilesUploadCmd,
 		renterFuseCmd, renterLostCmd, renterPricesCmd, renterRatelimitCmd, renterSetAllowanceCmd,
-		renterSetLocalPathCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterWorkersCmd,
-		renterHealthSummaryCmd)
-	renterWorkersCmd.AddCommand(renterWorkersAccountsCmd, renterWorkersDownloadsCmd, renterWorkersPriceTableCmd, renterWorkersReadJobsCmd, renterWorkersHasSectorJobSCmd, renterWorkersUploadsCmd, renterWorkersReadRegistryCmd, renterWorkersUpdateRegistryCmd)
+		renterSetLocalPathCmd, renterTriggerContractRecoveryScanCmd, renterUploadsCmd, renterVersionsCmd,
+		renterWorkersCmd, renterHealthSummaryCmd, renterTracesCmd, renterPolicyCmd, renterReencodeCmd, renterVerifyCmd, renterMetadataCmd, renterFindCmd, renterSyncCmd, renterFilesCopyCmd, renterTenantsCmd)
+	renterPolicyCmd.AddCommand(renterPolicySetCmd)
+	renterMetadataCmd.AddCommand(renterMetadataSetCmd, renterMetadataDeleteCmd)
+	renterSyncCmd.AddCommand(renterSyncStatusCmd)
+	renterTenantsCmd.AddCommand(renterTenantsSetCmd, renterTenantsRemoveCmd, renterTenantsResetCmd)
+	renterTracesCmd.AddCommand(renterTracesExportCmd, renterTracesOTLPCmd, renterTracesShowCmd)
+	renterVersionsCmd.AddCommand(renterVersionsPolicyCmd, renterVersionsRestoreCmd)
+	renterWorkersCmd.AddCommand(renterWorkersAccountsCmd, renterWorkersAuditCmd, renterWorkersDownloadsCmd, renterWorkersPriceTableCmd, renterWorkersReadJobsCmd, renterWorkersHasSectorJobSCmd, renterWorkersUploadsCmd, renterWorkersReadRegistryCmd, renterWorkersUpdateRegistryCmd)
 
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
 	renterBubbleCmd.Flags().BoolVarP(&renterBubbleAll, "all", "A", false, "Bubble the entire directory tree")
@@ -335,8 +383,32 @@ func initCmds() *cobra.Command {
 	renterFilesListCmd.Flags().BoolVar(&renterListRoot, "root", false, "List files and folders from root instead of from the user home directory")
 	renterFilesUploadCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces a files should be uploaded with")
 	renterFilesUploadCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces a files should be uploaded with")
+	renterFilesUploadCmd.Flags().StringVar(&renterUploadCompression, "compression", "", "the compression a file should be uploaded with, either 'none' or 'gzip'")
+	renterFilesUploadCmd.Flags().StringVar(&renterUploadChecksum, "checksum", "", "the checksum a file should be uploaded with, either 'none', 'blake2b' or 'sha256'")
+	renterPolicySetCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces of new uploads")
+	renterPolicySetCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces of new uploads")
+	renterPolicySetCmd.Flags().StringVar(&renterPolicyCipherType, "cipher-type", "", "the cipher type of new uploads, e.g. 'threefish512' or 'plaintext'")
+	renterPolicySetCmd.Flags().StringVar(&renterPolicyThreshold, "repair-threshold", "", "the health at which files are repaired, between 0 and 1")
+	renterPolicySetCmd.Flags().StringVar(&renterPolicyPriority, "repair-priority", "", "the priority of repairs, higher priorities are repaired first")
+	renterPolicySetCmd.Flags().StringVar(&renterPolicyAllowedHosts, "allowed-hosts", "", "comma separated list of the public keys of the hosts allowed to store the files")
+	renterReencodeCmd.Flags().StringVar(&dataPieces, "data-pieces", "", "the number of data pieces the file should be re-encoded with")
+	renterReencodeCmd.Flags().StringVar(&parityPieces, "parity-pieces", "", "the number of parity pieces the file should be re-encoded with")
+	renterReencodeCmd.Flags().StringVar(&renterReencodeCipherType, "cipher-type", "", "the cipher type the file should be re-encoded with, e.g. 'threefish512' or 'plaintext'")
+	renterVerifyCmd.Flags().BoolVarP(&renterVerifyRecursive, "recursive", "R", false, "Verify all files within the folder in the background")
+	renterFindCmd.Flags().StringVar(&renterFindSort, "sort", "", "the field to sort the files by, either 'health', 'modtime', 'name', 'redundancy' or 'size'")
+	renterFindCmd.Flags().BoolVar(&renterFindDescending, "desc", false, "sort the files in descending order")
+	renterFindCmd.Flags().IntVar(&renterFindLimit, "limit", 0, "the maximum number of files to show, 0 shows all files")
+	renterSyncCmd.Flags().BoolVar(&renterSyncDelete, "delete", false, "delete uploaded files which don't exist locally")
+	renterSyncCmd.Flags().BoolVar(&renterSyncDryRun, "dry-run", false, "only print the planned changes")
+	renterSyncCmd.Flags().StringArrayVar(&renterSyncExclude, "exclude", nil, "exclude files and directories matching the glob")
+	renterSyncCmd.Flags().StringArrayVar(&renterSyncInclude, "include", nil, "only include files matching the glob")
+	renterTenantsSetCmd.Flags().StringVar(&renterTenantStorageQuota, "storage", "", "the maximum size of the tenant's files, e.g. 1TB")
+	renterTenantsSetCmd.Flags().StringVar(&renterTenantUploadQuota, "upload", "", "the maximum amount of data the tenant can upload, e.g. 1TB")
+	renterTenantsSetCmd.Flags().StringVar(&renterTenantDownloadQuota, "download", "", "the maximum amount of data the tenant can download, e.g. 1TB")
+	renterFindCmd.Flags().StringArrayVar(&renterFindTags, "tag", nil, "only show files with the tag, either 'key' or 'key=value'")
 	renterExportCmd.AddCommand(renterExportContractTxnsCmd)
 	renterFilesRenameCmd.Flags().BoolVar(&renterRenameRoot, "root", false, "Rename files relative to root instead of the user homedir")
+	renterFilesCopyCmd.Flags().BoolVar(&renterCopyRoot, "root", false, "Copy files relative to root instead of the user homedir")
 
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceFunds, "amount", "", "amount of money in allowance, specified in currency units")
 	renterSetAllowanceCmd.Flags().StringVar(&allowancePeriod, "period", "", "period of allowance in blocks (b), hours (h), days (d) or weeks (w)")
@@ -357,7 +429,23 @@ func initCmds() *cobra.Command {
 	renterFuseMountCmd.Flags().BoolVarP(&renterFuseMountAllowOther, "allow-other", "", false, "Allow users other than the user that mounted the fuse directory to access and use the fuse directory")
 
 	// Daemon Commands
-	root.AddCommand(alertsCmd, globalRatelimitCmd, profileCmd, stackCmd, stopCmd, updateCmd, versionCmd)
+	root.AddCommand(alertsCmd, globalRatelimitCmd, metricsCmd, profileCmd, stackCmd, stopCmd, updateCmd, versionCmd)
+	metricsCmd.AddCommand(metricsEnableCmd, metricsDisableCmd)
+	alertsCmd.AddCommand(alertsHistoryCmd, alertsHooksCmd)
+	alertsHooksCmd.AddCommand(alertsHooksAddCmd, alertsHooksRemoveCmd)
+	alertsHistoryCmd.Flags().StringVar(&alertsModule, "module", "", "Only show alerts of the module")
+	alertsHistoryCmd.Flags().StringVar(&alertsSeverity, "severity", "", "Only show alerts of the severity (info, warning, error, critical)")
+	alertsHooksAddCmd.Flags().StringVar(&alertHookURL, "url", "", "The webhook URL the alert events are posted to")
+	alertsHooksAddCmd.Flags().StringVar(&alertHookModules, "modules", "", "Comma separated list of modules to receive alerts of, e.g. host,contractmanager")
+	alertsHooksAddCmd.Flags().StringVar(&alertHookSeverities, "severities", "", "Comma separated list of severities to receive alerts of, e.g. error,critical")
+
+	root.AddCommand(daemonCmd)
+	daemonCmd.AddCommand(daemonLoggingCmd, daemonReloadCmd, daemonTokensCmd)
+	daemonLoggingCmd.AddCommand(daemonLoggingFormatCmd, daemonLoggingLevelCmd, daemonLoggingStdoutCmd)
+	daemonTokensCmd.AddCommand(daemonTokensCreateCmd, daemonTokensRevokeCmd)
+	daemonTokensCreateCmd.Flags().StringVar(&daemonTokenExpiry, "expiry", "", "The duration after which the token expires, e.g. 720h")
+	daemonTokensCreateCmd.Flags().StringVar(&daemonTokenSpendingLimit, "spending-limit", "", "The amount of siacoins the token can send, e.g. 10KS")
+	daemonTokensCreateCmd.Flags().StringVar(&daemonTokenTenant, "tenant", "", "The tenant whose files the token can access")
 	profileCmd.AddCommand(profileStartCmd, profileStopCmd)
 	profileStartCmd.Flags().BoolVarP(&daemonCPUProfile, "cpu", "c", false, "Start the CPU profile")
 	profileStartCmd.Flags().BoolVarP(&daemonMemoryProfile, "memory", "m", false, "Start the Memory profile")
@@ -376,7 +464,13 @@ func initCmds() *cobra.Command {
 	root.AddCommand(walletCmd)
 	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletBalanceCmd, walletBroadcastCmd, walletChangepasswordCmd,
 		walletInitCmd, walletInitSeedCmd, walletLoadCmd, walletLockCmd, walletSeedsCmd, walletSendCmd,
-		walletSignCmd, walletSweepCmd, walletTransactionsCmd, walletUnlockCmd)
+		walletSignCmd, walletSweepCmd, walletTransactionsCmd, walletUnlockCmd, walletPolicyCmd, walletApprovalsCmd)
+	walletApprovalsCmd.AddCommand(walletApprovalsApproveCmd, walletApprovalsRejectCmd)
+	walletPolicyCmd.Flags().StringVar(&walletPolicyDailyLimit, "daily-limit", "", "Maximum amount of siacoins sent within 24 hours, 0 disables the limit")
+	walletPolicyCmd.Flags().StringVar(&walletPolicyApprovalThreshold, "approval-threshold", "", "Amount of siacoins above which a spend needs to be approved, 0 disables approvals")
+	walletPolicyCmd.Flags().StringVar(&walletPolicyAllowedDestinations, "allowed-destinations", "", "Comma-separated list of addresses coins can be sent to, 'all' allows all addresses")
+	walletPolicyCmd.Flags().StringVar(&walletPolicyMaxAllowanceFunds, "max-allowance", "", "Maximum funds of the renter allowance, 0 disables the limit")
+	walletPolicyCmd.Flags().StringVar(&walletPolicyMaxAllowanceIncrease, "max-allowance-increase", "", "Maximum amount the allowance funds can be increased by within 24 hours, 0 disables the limit")
 	walletInitCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Prompt for a custom password")
 	walletInitCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet and re-encrypt")
 	walletInitSeedCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
@@ -408,6 +502,25 @@ func initClient(root *cobra.Command, verbose *bool, client *client.Client, siaDi
 	root.PersistentFlags().StringVarP(siaDir, "sia-directory", "d", "", "location of the sia directory")
 	root.PersistentFlags().StringVarP(&client.UserAgent, "useragent", "", "Sia-Agent", "the useragent used by siac to connect to the daemon's API")
 	root.PersistentFlags().BoolVarP(alertSuppress, "alert-suppress", "s", false, "suppress siac alerts")
+	root.PersistentFlags().BoolVarP(&apiTLS, "api-tls", "", false, "connect to the API over TLS, implied by the other --api-tls flags")
+	root.PersistentFlags().StringVarP(&apiTLSOptions.CAFile, "api-tls-ca", "", "", "path of the CA certificates the API's certificate is verified against")
+	root.PersistentFlags().StringVarP(&apiTLSOptions.Fingerprint, "api-tls-fingerprint", "", "", "SHA-256 fingerprint of the API's certificate, e.g. of siad's self-signed certificate")
+	root.PersistentFlags().StringVarP(&apiTLSOptions.CertFile, "api-tls-cert", "", "", "path of the client certificate for the API")
+	root.PersistentFlags().StringVarP(&apiTLSOptions.KeyFile, "api-tls-key", "", "", "path of the key of the client certificate for the API")
+}
+
+// setAPITLSConfig sets the TLS config of the client if any of the TLS flags
+// were set.
+func setAPITLSConfig() {
+	if !apiTLS && apiTLSOptions == (client.TLSOptions{}) {
+		return
+	}
+	tlsConfig, err := client.NewTLSConfig(apiTLSOptions)
+	if err != nil {
+		fmt.Println("Exiting: Error loading API TLS config:", err)
+		os.Exit(exitCodeGeneral)
+	}
+	httpClient.TLSConfig = tlsConfig
 }
 
 // setAPIPasswordIfNotSet sets API password if it was not set
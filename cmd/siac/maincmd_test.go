@@ -173,7 +173,7 @@ func getCmdUsage(t *testing.T, cmd *cobra.Command) string {
 `
 	usage = strings.ReplaceAll(usage, beforeHelpCommand, beforeHelpCommand+nl+helpCommand)
 	beforeHelpFlag := "the password for the API's http authentication"
-	helpFlag := `  -h, --help                   help for .*siac(\.test|)`
+	helpFlag := `  -h, --help                         help for .*siac(\.test|)`
 	cmdUsagePattern := strings.ReplaceAll(usage, beforeHelpFlag, beforeHelpFlag+nl+helpFlag)
 
 	return cmdUsagePattern
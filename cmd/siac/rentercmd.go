@@ -10,6 +10,7 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"reflect"
 	"sort"
 	"strconv"
 	"strings"
@@ -35,6 +36,10 @@ const (
 	// truncateErrLength is the length at which an error string gets truncated
 	truncateErrLength = 24
 
+	// renterFindPageSize is the number of files fetched per request by
+	// `siac renter find`.
+	renterFindPageSize = 1000
+
 	// colourful strings for the console UI
 	pBarJobProcess = "\x1b[34;1mpinning   \x1b[0m" // blue
 	pBarJobUpload  = "\x1b[33;1muploading \x1b[0m" // yellow
@@ -160,6 +165,19 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Run:   renterfileslistcmd,
 	}
 
+	renterFindCmd = &cobra.Command{
+		Use:   "find [filter]",
+		Short: "Find files by health, size, time and stuck status",
+		Long: `Find the files matching a filter. A filter is a comma separated list of
+conditions of the form <field><operator><value>. The fields are 'health',
+'redundancy', 'size', 'modtime' (RFC 3339), 'stuck' and 'name' (glob). The
+operators are =, !=, <, <=, > and >=. The filtering and sorting is done by the
+renter.
+
+Example: siac renter find "health>0.25,stuck=false,name=*.png" --sort size --desc`,
+		Run: renterfindcmd,
+	}
+
 	renterFilesRenameCmd = &cobra.Command{
 		Use:     "rename [path] [newpath]",
 		Aliases: []string{"mv"},
@@ -168,6 +186,92 @@ the command 'siac renter lost' to see the renter's lost files.`,
 		Run:     wrap(renterfilesrenamecmd),
 	}
 
+	renterFilesCopyCmd = &cobra.Command{
+		Use:   "cp [path] [newpath]",
+		Short: "Copy a file without uploading it again",
+		Long: `Copy a file without uploading it again. The copy shares the data of the
+original on the hosts. Deleting the original or the copy doesn't affect the
+other file.`,
+		Run: wrap(renterfilescopycmd),
+	}
+
+	renterReencodeCmd = &cobra.Command{
+		Use:   "reencode [path]",
+		Short: "Re-encode a file with new erasure coding or cipher parameters",
+		Long: `Re-encode a file with new erasure coding or cipher parameters. The file is
+streamed from the network and re-uploaded in the background. Once all of its
+chunks are uploaded, the file's siafile is replaced. Interrupted re-encodes are
+resumed when the renter restarts. Omitted flags keep the file's current value.`,
+		Run: wrap(renterreencodecmd),
+	}
+
+	renterVerifyCmd = &cobra.Command{
+		Use:   "verify [path]",
+		Short: "Verify the data of a file on the network against its checksum",
+		Long: `Verify the data of a file on the network against its checksum. The file is
+downloaded from the hosts without using a local copy and the checksum of the
+downloaded data is compared to the checksum computed during the upload. With
+--recursive all files within the folder are verified in the background and
+mismatches are reported as alerts.`,
+		Run: wrap(renterverifycmd),
+	}
+
+	renterSyncCmd = &cobra.Command{
+		Use:   "sync [localpath] [path]",
+		Short: "Synchronize a local directory to a folder of the renter",
+		Long: `Synchronize a local directory to a folder of the renter. Local files which
+don't exist in the folder are uploaded and local files which differ from the
+uploaded files by size, modification time or checksum are uploaded again. With
+--delete, uploaded files which don't exist locally are deleted.
+
+--include and --exclude globs are matched against the path of a file relative
+to the local directory and against its name. Excluded directories are skipped.
+
+The sync is persisted and resumed after a restart of siad. With --dry-run the
+planned changes are printed without making them.`,
+		Run: wrap(rentersynccmd),
+	}
+
+	renterSyncStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "Show the recent and ongoing syncs",
+		Long:  "Show the progress and outcome of the recent and ongoing syncs.",
+		Run:   wrap(rentersyncstatuscmd),
+	}
+
+	renterTenantsCmd = &cobra.Command{
+		Use:   "tenants",
+		Short: "List the tenants of the renter",
+		Long: `List the tenants of the renter together with their quotas and usage. Every
+tenant has its own folder within /tenants. API tokens of a tenant can only
+access the files within that folder and the files count towards the quotas of
+the tenant.`,
+		Run: wrap(rentertenantscmd),
+	}
+
+	renterTenantsSetCmd = &cobra.Command{
+		Use:   "set [name]",
+		Short: "Create a tenant or update its quotas",
+		Long: `Create a tenant or update the quotas of an existing tenant. Quotas that aren't
+set keep their value and a quota of 0 means unlimited. Create an API token for
+the tenant with 'siac daemon tokens create --tenant'.`,
+		Run: wrap(rentertenantssetcmd),
+	}
+
+	renterTenantsRemoveCmd = &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove a tenant",
+		Long:  "Remove a tenant and revoke its API tokens. The files of the tenant are kept.",
+		Run:   wrap(rentertenantsremovecmd),
+	}
+
+	renterTenantsResetCmd = &cobra.Command{
+		Use:   "reset [name]",
+		Short: "Reset the bandwidth usage of a tenant",
+		Long:  "Reset the uploaded and downloaded bytes of a tenant, e.g. at the start of a billing period.",
+		Run:   wrap(rentertenantsresetcmd),
+	}
+
 	renterFuseCmd = &cobra.Command{
 		Use:   "fuse",
 		Short: "Perform fuse actions.",
@@ -212,7 +316,8 @@ local path where the Sia folder is mounted.`,
 		Use:   "upload [source] [path]",
 		Short: "Upload a file or folder",
 		Long: `Upload a file or folder to [path] on the Sia network. The --data-pieces and --parity-pieces
-flags can be used to set a custom redundancy for the file.`,
+flags can be used to set a custom redundancy for the file. The --compression flag can be used
+to compress the file before uploading it.`,
 		Run: wrap(renterfilesuploadcmd),
 	}
 
@@ -288,6 +393,110 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 		Run:   wrap(renteruploadscmd),
 	}
 
+	renterVersionsCmd = &cobra.Command{
+		Use:   "versions [path]",
+		Short: "List the prior versions of a file",
+		Long: `List the prior versions of a file that were kept when the file was overwritten
+or deleted. Versions are only kept for files within directories with a
+versioning policy.`,
+		Run: wrap(renterversionscmd),
+	}
+
+	renterVersionsRestoreCmd = &cobra.Command{
+		Use:   "restore [path] [id]",
+		Short: "Restore a prior version of a file",
+		Long:  "Restore a prior version of a file. The current version of the file is kept as a prior version.",
+		Run:   wrap(renterversionsrestorecmd),
+	}
+
+	renterVersionsPolicyCmd = &cobra.Command{
+		Use:   "setpolicy [path] [maxversions] [maxage]",
+		Short: "Set the versioning policy of a directory",
+		Long: `Set the versioning policy of a directory. The policy applies to all files within
+the directory and its subdirectories, unless they have a policy of their own.
+[maxversions] is the number of prior versions kept per file and [maxage] is the
+duration after which prior versions are removed, e.g. 720h. A value of 0
+removes the respective limit. Setting both to 0 disables versioning.`,
+		Run: wrap(renterversionspolicycmd),
+	}
+
+	renterPolicyCmd = &cobra.Command{
+		Use:   "policy [path]",
+		Short: "View the storage policy of a directory",
+		Long: `View the storage policy that applies to the files within a directory. The policy
+is either set on the directory itself or inherited from its closest ancestor
+with a policy.`,
+		Run: wrap(renterpolicycmd),
+	}
+
+	renterPolicySetCmd = &cobra.Command{
+		Use:   "set [path]",
+		Short: "Set the storage policy of a directory",
+		Long: `Set the storage policy of a directory. The policy applies to all files within
+the directory and its subdirectories, unless they have a policy of their own.
+New uploads without explicit erasure coding parameters use the policy's
+parameters and cipher type. The repair threshold, repair priority and allowed
+hosts are enforced by the repair loop for all files. Changing the erasure coding
+parameters or cipher type gradually re-encodes the existing files in the
+background. Omitted flags fall back to the renter's defaults. Running the
+command without flags removes the policy of the directory.`,
+		Run: wrap(renterpolicysetcmd),
+	}
+
+	renterMetadataCmd = &cobra.Command{
+		Use:   "metadata [path]",
+		Short: "View the user metadata of a file or directory",
+		Long: `View the application defined key/value metadata of a file or directory. The
+entries of a file's metadata can be used as tags to filter files by.`,
+		Run: wrap(rentermetadatacmd),
+	}
+
+	renterMetadataSetCmd = &cobra.Command{
+		Use:   "set [path] [key] [value]",
+		Short: "Set an entry of the user metadata of a file or directory",
+		Long:  "Set an entry of the application defined metadata of a file or directory.",
+		Run:   wrap(rentermetadatasetcmd),
+	}
+
+	renterMetadataDeleteCmd = &cobra.Command{
+		Use:   "delete [path] [key]",
+		Short: "Delete an entry of the user metadata of a file or directory",
+		Long:  "Delete an entry of the application defined metadata of a file or directory.",
+		Run:   wrap(rentermetadatadeletecmd),
+	}
+
+	renterTracesCmd = &cobra.Command{
+		Use:   "traces",
+		Short: "View the traces of renter operations",
+		Long: `View the recent traces of downloads, uploads and worker jobs. A trace shows
+where the time of an operation went.`,
+		Run: wrap(rentertracescmd),
+	}
+
+	renterTracesExportCmd = &cobra.Command{
+		Use:   "export [destination]",
+		Short: "Export the traces to a file or an OpenTelemetry collector",
+		Long: `Export the finished spans in the OTLP JSON encoding. [destination] is either
+an absolute file path, which receives one export request per line, or the URL
+of an OpenTelemetry collector, e.g. http://localhost:4318/v1/traces. Use
+'none' to disable the export.`,
+		Run: wrap(rentertracesexportcmd),
+	}
+
+	renterTracesOTLPCmd = &cobra.Command{
+		Use:   "otlp [id]",
+		Short: "Print a trace in the OTLP JSON encoding",
+		Long:  "Print a trace in the OTLP JSON encoding, e.g. to import it into a tracing backend.",
+		Run:   wrap(rentertracesotlpcmd),
+	}
+
+	renterTracesShowCmd = &cobra.Command{
+		Use:   "show [id]",
+		Short: "Show the spans of a trace",
+		Long:  "Show the spans of a trace as a tree.",
+		Run:   wrap(rentertracesshowcmd),
+	}
+
 	renterWorkersCmd = &cobra.Command{
 		Use:   "workers",
 		Short: "View the Renter's workers",
@@ -302,6 +511,13 @@ have a reasonable number (>30) of hosts in your hostdb.`,
 		Run:   wrap(renterworkerseacmd),
 	}
 
+	renterWorkersAuditCmd = &cobra.Command{
+		Use:   "audit",
+		Short: "View the workers' host audits",
+		Long:  "View detailed information of the audits the workers performed on their hosts",
+		Run:   wrap(renterworkersauditcmd),
+	}
+
 	renterWorkersDownloadsCmd = &cobra.Command{
 		Use:   "dj",
 		Short: "View the workers' download jobs",
@@ -503,6 +719,256 @@ func renterhealthsummarycmd() {
 	renterFileHealthSummary(dirs)
 }
 
+// renterversionscmd is the handler for the command `siac renter versions`.
+// It lists the prior versions of a file.
+func renterversionscmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	rf, err := httpClient.RenterFileVersionsGet(siaPath)
+	if err != nil {
+		die("Could not get file versions:", err)
+	}
+	if len(rf.Versions) == 0 {
+		fmt.Println("No prior versions of", path)
+		return
+	}
+	fmt.Printf("%v prior versions of %v:\n", len(rf.Versions), path)
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "  ID\tArchived\tSize\tHealth\tRedundancy")
+	for _, v := range rf.Versions {
+		fmt.Fprintf(w, "  %v\t%v\t%v\t%.2f%%\t%.2f\n", v.ID, v.ArchivedTime.Format(time.RFC3339), modules.FilesizeUnits(v.Filesize), modules.HealthPercentage(v.Health), v.Redundancy)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer")
+	}
+}
+
+// renterversionsrestorecmd is the handler for the command `siac renter
+// versions restore`. It restores a prior version of a file.
+func renterversionsrestorecmd(path, id string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	err = httpClient.RenterFileRestoreVersionPost(siaPath, id)
+	if err != nil {
+		die("Could not restore file version:", err)
+	}
+	fmt.Printf("Restored version %v of %v\n", id, path)
+}
+
+// renterversionspolicycmd is the handler for the command `siac renter
+// versions setpolicy`. It sets the versioning policy of a directory.
+func renterversionspolicycmd(path, maxVersions, maxAge string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	var policy modules.VersioningPolicy
+	policy.MaxVersions, err = strconv.ParseUint(maxVersions, 10, 64)
+	if err != nil {
+		die("Could not parse maxversions:", err)
+	}
+	if maxAge != "0" {
+		policy.MaxAge, err = time.ParseDuration(maxAge)
+		if err != nil {
+			die("Could not parse maxage:", err)
+		}
+	}
+	err = httpClient.RenterDirSetVersioningPost(siaPath, policy)
+	if err != nil {
+		die("Could not set versioning policy:", err)
+	}
+	if !policy.Enabled() {
+		fmt.Println("Disabled versioning for", path)
+		return
+	}
+	fmt.Println("Set the versioning policy of", path)
+}
+
+// renterpolicycmd is the handler for the command `siac renter policy`. It
+// prints the storage policy that applies to a directory.
+func renterpolicycmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	rd, err := httpClient.RenterDirGet(siaPath)
+	if err != nil {
+		die("Could not get storage policy:", err)
+	}
+	policy := rd.StoragePolicy
+	if !policy.Active() {
+		fmt.Println("No storage policy applies to", path)
+		return
+	}
+	var own bool
+	if len(rd.Directories) > 0 {
+		own = reflect.DeepEqual(rd.Directories[0].StoragePolicy, policy)
+	}
+	if own {
+		fmt.Printf("Storage policy of %v:\n", path)
+	} else {
+		fmt.Printf("Storage policy of %v (inherited):\n", path)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	if policy.DataPieces > 0 {
+		fmt.Fprintf(w, "  Erasure Coding:\t%v-of-%v\n", policy.DataPieces, policy.DataPieces+policy.ParityPieces)
+	} else {
+		fmt.Fprintf(w, "  Erasure Coding:\tdefault\n")
+	}
+	cipherType := policy.CipherType
+	if cipherType == "" {
+		cipherType = "default"
+	}
+	fmt.Fprintf(w, "  Cipher Type:\t%v\n", cipherType)
+	fmt.Fprintf(w, "  Repair Threshold:\t%v\n", policy.Threshold())
+	fmt.Fprintf(w, "  Repair Priority:\t%v\n", policy.RepairPriority)
+	if len(policy.AllowedHosts) == 0 {
+		fmt.Fprintf(w, "  Allowed Hosts:\tall\n")
+	} else {
+		fmt.Fprintf(w, "  Allowed Hosts:\t%v\n", len(policy.AllowedHosts))
+		for _, pk := range policy.AllowedHosts {
+			fmt.Fprintf(w, "\t%v\n", pk.String())
+		}
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// renterpolicysetcmd is the handler for the command `siac renter policy set`.
+// It sets the storage policy of a directory.
+func renterpolicysetcmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	var policy modules.StoragePolicy
+	policy.DataPieces, policy.ParityPieces, err = api.ParseDataAndParityPieces(dataPieces, parityPieces)
+	if err != nil {
+		die("Could not parse erasure coding parameters:", err)
+	}
+	policy.CipherType = renterPolicyCipherType
+	if renterPolicyThreshold != "" {
+		policy.RepairThreshold, err = strconv.ParseFloat(renterPolicyThreshold, 64)
+		if err != nil {
+			die("Could not parse repair threshold:", err)
+		}
+	}
+	if renterPolicyPriority != "" {
+		policy.RepairPriority, err = strconv.Atoi(renterPolicyPriority)
+		if err != nil {
+			die("Could not parse repair priority:", err)
+		}
+	}
+	if renterPolicyAllowedHosts != "" {
+		for _, str := range strings.Split(renterPolicyAllowedHosts, ",") {
+			var pk types.SiaPublicKey
+			if err := pk.LoadString(strings.TrimSpace(str)); err != nil {
+				die("Could not parse allowed host:", err)
+			}
+			policy.AllowedHosts = append(policy.AllowedHosts, pk)
+		}
+	}
+	if err := policy.Validate(); err != nil {
+		die("Invalid storage policy:", err)
+	}
+	err = httpClient.RenterDirSetStoragePolicyPost(siaPath, policy)
+	if err != nil {
+		die("Could not set storage policy:", err)
+	}
+	if !policy.Active() {
+		fmt.Println("Removed the storage policy of", path)
+		return
+	}
+	fmt.Println("Set the storage policy of", path)
+}
+
+// rentermetadatacmd is the handler for the command `siac renter metadata
+// [path]`. It shows the user metadata of a file or directory.
+func rentermetadatacmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	um, err := renterUserMetadata(siaPath)
+	if err != nil {
+		die("Could not get user metadata:", err)
+	}
+	if len(um) == 0 {
+		fmt.Println("No user metadata set on", path)
+		return
+	}
+	keys := make([]string, 0, len(um))
+	for k := range um {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	for _, k := range keys {
+		fmt.Fprintf(w, "  %v:\t%v\n", k, um[k])
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// rentermetadatasetcmd is the handler for the command `siac renter metadata
+// set [path] [key] [value]`. It sets an entry of the user metadata of a file
+// or directory.
+func rentermetadatasetcmd(path, key, value string) {
+	if value == "" {
+		die("Value can't be empty, use 'siac renter metadata delete' to delete an entry")
+	}
+	renterUpdateUserMetadata(path, modules.UserMetadata{key: value})
+	fmt.Printf("Set %v of %v\n", key, path)
+}
+
+// rentermetadatadeletecmd is the handler for the command `siac renter
+// metadata delete [path] [key]`. It deletes an entry of the user metadata of a
+// file or directory.
+func rentermetadatadeletecmd(path, key string) {
+	renterUpdateUserMetadata(path, modules.UserMetadata{key: ""})
+	fmt.Printf("Deleted %v of %v\n", key, path)
+}
+
+// renterUserMetadata returns the user metadata of the file or directory at
+// siaPath.
+func renterUserMetadata(siaPath modules.SiaPath) (modules.UserMetadata, error) {
+	rf, err := httpClient.RenterFileGet(siaPath)
+	if err == nil {
+		return rf.File.UserMetadata, nil
+	}
+	rd, dirErr := httpClient.RenterDirGet(siaPath)
+	if dirErr != nil {
+		return nil, errors.Compose(err, dirErr)
+	}
+	if len(rd.Directories) == 0 {
+		return nil, errors.New("directory not found")
+	}
+	return rd.Directories[0].UserMetadata, nil
+}
+
+// renterUpdateUserMetadata applies an update to the user metadata of the file
+// or directory at path.
+func renterUpdateUserMetadata(path string, update modules.UserMetadata) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	if _, fileErr := httpClient.RenterFileGet(siaPath); fileErr == nil {
+		err = httpClient.RenterFileUserMetadataPost(siaPath, update)
+	} else {
+		err = httpClient.RenterDirSetUserMetadataPost(siaPath, update)
+	}
+	if err != nil {
+		die("Could not update user metadata:", err)
+	}
+}
+
 // renteruploadscmd is the handler for the command `siac renter uploads`.
 // Lists files currently uploading.
 func renteruploadscmd() {
@@ -1693,6 +2159,61 @@ func rentercontractrecoveryscanprogresscmd() {
 	}
 }
 
+// renterfindcmd is the handler for the command `siac renter find [filter]`.
+// It lists the files matching a filter in the requested order.
+func renterfindcmd(cmd *cobra.Command, args []string) {
+	var filter string
+	switch len(args) {
+	case 0:
+	case 1:
+		filter = args[0]
+	default:
+		_ = cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	q := modules.FileQuery{
+		Sort:       renterFindSort,
+		Descending: renterFindDescending,
+	}
+	var err error
+	q.Filter, err = modules.ParseFileFilter(filter)
+	if err != nil {
+		die("Could not parse filter:", err)
+	}
+	q.Tags, err = modules.NewUserMetadataTags(renterFindTags)
+	if err != nil {
+		die("Could not parse tags:", err)
+	}
+
+	// Fetch the files page by page.
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Path\tSize\tRedundancy\tHealth\tStuck\tModified\n")
+	var found int
+	for {
+		q.Limit = renterFindPageSize
+		if renterFindLimit > 0 && renterFindLimit-found < q.Limit {
+			q.Limit = renterFindLimit - found
+		}
+		rf, err := httpClient.RenterFilesQueryGet(true, q)
+		if err != nil {
+			die("Could not find files:", err)
+		}
+		for _, file := range rf.Files {
+			fmt.Fprintf(w, "%v\t%v\t%.2f\t%.2f%%\t%v\t%v\n", file.SiaPath, modules.FilesizeUnits(file.Filesize),
+				file.Redundancy, modules.HealthPercentage(file.Health), yesNo(file.Stuck), file.ModificationTime.Format(time.RFC3339))
+		}
+		found += len(rf.Files)
+		if rf.NextCursor == "" || (renterFindLimit > 0 && found >= renterFindLimit) {
+			break
+		}
+		q.Cursor = rf.NextCursor
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+	fmt.Printf("\nFound %v files\n", found)
+}
+
 // renterfileslistcmd is the handler for the command `siac renter ls`. Lists
 // files known to the renter on the network.
 func renterfileslistcmd(cmd *cobra.Command, args []string) {
@@ -1836,6 +2357,22 @@ func renterfileslistcmd(cmd *cobra.Command, args []string) {
 	}
 }
 
+// renterfilescopycmd is the handler for the command `siac renter cp [path] [newpath]`.
+// Copies a file on the Sia network.
+func renterfilescopycmd(path, newpath string) {
+	// Parse SiaPath.
+	siaPath, err1 := modules.NewSiaPath(path)
+	newSiaPath, err2 := modules.NewSiaPath(newpath)
+	if err := errors.Compose(err1, err2); err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	err := httpClient.RenterCopyPost(siaPath, newSiaPath, renterCopyRoot)
+	if err != nil {
+		die("Could not copy file:", err)
+	}
+	fmt.Printf("Copied %s to %s\n", path, newpath)
+}
+
 // renterfilesrenamecmd is the handler for the command `siac renter rename [path] [newpath]`.
 // Renames a file on the Sia network.
 func renterfilesrenamecmd(path, newpath string) {
@@ -1852,6 +2389,211 @@ func renterfilesrenamecmd(path, newpath string) {
 	fmt.Printf("Renamed %s to %s\n", path, newpath)
 }
 
+// renterreencodecmd is the handler for the command `siac renter reencode
+// [path]`. It re-encodes a file with new erasure coding or cipher parameters.
+func renterreencodecmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	data, parity, err := api.ParseDataAndParityPieces(dataPieces, parityPieces)
+	if err != nil {
+		die("Could not parse erasure coding parameters:", err)
+	}
+	if data == 0 && renterReencodeCipherType == "" {
+		die("Either --data-pieces and --parity-pieces or --cipher-type need to be set")
+	}
+	err = httpClient.RenterReencodePost(siaPath, uint64(data), uint64(parity), renterReencodeCipherType)
+	if err != nil {
+		die("Could not re-encode file:", err)
+	}
+	fmt.Println("Started re-encoding", path)
+}
+
+// renterverifycmd is the handler for the command `siac renter verify [path]`.
+// It verifies the data of a file or folder against its checksum.
+func renterverifycmd(path string) {
+	siaPath, err := modules.NewSiaPath(path)
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	if renterVerifyRecursive {
+		err = httpClient.RenterVerifyDirPost(siaPath)
+		if err != nil {
+			die("Could not verify folder:", err)
+		}
+		fmt.Println("Started verifying the files in", path)
+		return
+	}
+	fv, err := httpClient.RenterVerifyPost(siaPath)
+	if err != nil {
+		die("Could not verify file:", err)
+	}
+	fmt.Printf("Checksum (%v): %v\n", fv.ChecksumType, fv.Checksum)
+	fmt.Printf("Downloaded:     %v\n", fv.ActualChecksum)
+	if !fv.Verified {
+		die("Verification failed: the data on the network doesn't match the checksum")
+	}
+	fmt.Println("Verified", path)
+}
+
+// rentersynccmd is the handler for the command `siac renter sync [localpath]
+// [path]`. It synchronizes a local directory to a folder of the renter.
+func rentersynccmd(localPath, path string) {
+	var siaPath modules.SiaPath
+	var err error
+	if path == "" || path == "/" || path == "." {
+		siaPath = modules.RootSiaPath()
+	} else {
+		siaPath, err = modules.NewSiaPath(path)
+	}
+	if err != nil {
+		die("Couldn't parse SiaPath:", err)
+	}
+	params := modules.SyncParams{
+		LocalPath: abs(localPath),
+		SiaPath:   siaPath,
+		Delete:    renterSyncDelete,
+		Include:   renterSyncInclude,
+		Exclude:   renterSyncExclude,
+	}
+	run, err := httpClient.RenterSyncPost(params, renterSyncDryRun)
+	if err != nil {
+		die("Could not sync:", err)
+	}
+	if len(run.Actions) == 0 {
+		fmt.Println("Already in sync.")
+		return
+	}
+	if renterSyncDryRun {
+		fmt.Println("Planned changes:")
+	} else {
+		fmt.Printf("Started sync %v:\n", run.ID)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, action := range run.Actions {
+		fmt.Fprintf(w, "  %v\t%v\t%v\n", action.Type, action.SiaPath, modules.FilesizeUnits(action.Size))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// rentersyncstatuscmd is the handler for the command `siac renter sync
+// status`. It shows the progress of the recent and ongoing syncs.
+func rentersyncstatuscmd() {
+	rs, err := httpClient.RenterSyncGet()
+	if err != nil {
+		die("Could not get syncs:", err)
+	}
+	if len(rs.Runs) == 0 {
+		fmt.Println("No syncs.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tLocal Path\tSia Path\tProgress\tStarted\tStatus")
+	for _, run := range rs.Runs {
+		var done int
+		for _, action := range run.Actions {
+			if action.Done {
+				done++
+			}
+		}
+		status := "running"
+		if run.Completed {
+			status = "completed"
+		}
+		if run.Error != "" {
+			status = run.Error
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v/%v\t%v\t%v\n", run.ID, run.Params.LocalPath, run.Params.SiaPath,
+			done, len(run.Actions), run.StartTime.Format(time.RFC3339), status)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// rentertenantscmd is the handler for the command `siac renter tenants`.
+// Lists the tenants of the renter.
+func rentertenantscmd() {
+	rtg, err := httpClient.RenterTenantsGet()
+	if err != nil {
+		die("Could not get tenants:", err)
+	}
+	if len(rtg.Tenants) == 0 {
+		fmt.Println("No tenants.")
+		return
+	}
+	quota := func(usage, quota uint64) string {
+		if quota == 0 {
+			return modules.FilesizeUnits(usage) + " / unlimited"
+		}
+		return modules.FilesizeUnits(usage) + " / " + modules.FilesizeUnits(quota)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tRoot\tStorage\tUploaded\tDownloaded")
+	for _, t := range rtg.Tenants {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", t.Name, t.Root, quota(t.Usage.Storage, t.StorageQuota),
+			quota(t.Usage.Uploaded, t.UploadQuota), quota(t.Usage.Downloaded, t.DownloadQuota))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// rentertenantssetcmd is the handler for the command `siac renter tenants set
+// [name]`. Creates a tenant or updates its quotas.
+func rentertenantssetcmd(name string) {
+	tenant := modules.Tenant{Name: name}
+	rtg, err := httpClient.RenterTenantsGet()
+	if err != nil {
+		die("Could not get tenants:", err)
+	}
+	for _, t := range rtg.Tenants {
+		if t.Name == name {
+			tenant = t.Tenant
+		}
+	}
+	setQuota := func(str string, quota *uint64) {
+		if str == "" {
+			return
+		}
+		size, err := parseFilesize(str)
+		if err != nil {
+			die("Could not parse quota:", err)
+		}
+		if _, err := fmt.Sscan(size, quota); err != nil {
+			die("Could not parse quota:", err)
+		}
+	}
+	setQuota(renterTenantStorageQuota, &tenant.StorageQuota)
+	setQuota(renterTenantUploadQuota, &tenant.UploadQuota)
+	setQuota(renterTenantDownloadQuota, &tenant.DownloadQuota)
+	if err := httpClient.RenterTenantsSetPost(tenant); err != nil {
+		die("Could not set tenant:", err)
+	}
+	fmt.Printf("Set tenant '%v'\n", name)
+}
+
+// rentertenantsremovecmd is the handler for the command `siac renter tenants
+// remove [name]`. Removes a tenant.
+func rentertenantsremovecmd(name string) {
+	if err := httpClient.RenterTenantsRemovePost(name); err != nil {
+		die("Could not remove tenant:", err)
+	}
+	fmt.Printf("Removed tenant '%v'\n", name)
+}
+
+// rentertenantsresetcmd is the handler for the command `siac renter tenants
+// reset [name]`. Resets the bandwidth usage of a tenant.
+func rentertenantsresetcmd(name string) {
+	if err := httpClient.RenterTenantsResetPost(name); err != nil {
+		die("Could not reset usage of tenant:", err)
+	}
+	fmt.Printf("Reset usage of tenant '%v'\n", name)
+}
+
 // renterfusecmd displays the list of directories that are currently mounted via
 // fuse.
 func renterfusecmd() {
@@ -2016,6 +2758,14 @@ func renterfilesuploadcmd(source, path string) {
 	if err != nil {
 		die("Could not parse data and parity pieces:", err)
 	}
+	compression, err := modules.NewCompressionType(renterUploadCompression)
+	if err != nil {
+		die("Could not parse compression:", err)
+	}
+	checksum, err := modules.NewChecksumType(renterUploadChecksum)
+	if err != nil {
+		die("Could not parse checksum:", err)
+	}
 
 	if stat.IsDir() {
 		// folder
@@ -2046,7 +2796,7 @@ func renterfilesuploadcmd(source, path string) {
 			if err != nil {
 				die("Couldn't parse SiaPath:", err)
 			}
-			err = httpClient.RenterUploadPost(abs(file), fSiaPath, uint64(numDataPieces), uint64(numParityPieces))
+			err = httpClient.RenterUploadChecksumPost(abs(file), fSiaPath, uint64(numDataPieces), uint64(numParityPieces), false, compression, checksum)
 			if err != nil {
 				failed++
 				fmt.Printf("Could not upload file %s :%v\n", file, err)
@@ -2060,7 +2810,7 @@ func renterfilesuploadcmd(source, path string) {
 		if err != nil {
 			die("Couldn't parse SiaPath:", err)
 		}
-		err = httpClient.RenterUploadPost(abs(source), siaPath, uint64(numDataPieces), uint64(numParityPieces))
+		err = httpClient.RenterUploadChecksumPost(abs(source), siaPath, uint64(numDataPieces), uint64(numParityPieces), false, compression, checksum)
 		if err != nil {
 			die("Could not upload file:", err)
 		}
@@ -2436,6 +3186,50 @@ func renterworkersrjcmd() {
 	}
 }
 
+// renterworkersauditcmd is the handler for the command `siac renter workers
+// audit`. It lists the results of the host audits for every worker.
+func renterworkersauditcmd() {
+	rw, err := httpClient.RenterWorkersGet()
+	if err != nil {
+		die("Could not get worker statuses:", err)
+	}
+
+	// Sort workers by public key.
+	sort.Slice(rw.Workers, func(i, j int) bool {
+		return rw.Workers[i].HostPubKey.String() < rw.Workers[j].HostPubKey.String()
+	})
+
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	defer func() {
+		err := w.Flush()
+		if err != nil {
+			die("Could not flush tabwriter:", err)
+		}
+	}()
+
+	// print header
+	hostInfo := "Host PubKey"
+	auditInfo := "	Successful	Failed	Last Audit	ErrorAt	Error"
+	header := hostInfo + auditInfo
+	fmt.Fprintln(w, "\nWorker Host Audits  \n\n"+header)
+
+	// print rows
+	for _, worker := range rw.Workers {
+		as := worker.AuditStatus
+
+		// Host Info
+		fmt.Fprintf(w, "%v", worker.HostPubKey.String())
+
+		// Audit Info
+		fmt.Fprintf(w, "\t%v\t%v\t%v\t%v\t%v\n",
+			as.SuccessfulAudits,
+			as.FailedAudits,
+			sanitizeTime(as.LastAuditTime, !as.LastAuditTime.IsZero()),
+			sanitizeTime(as.RecentErrTime, as.RecentErr != ""),
+			sanitizeErr(as.RecentErr))
+	}
+}
+
 // renterworkershsjcmd is the handler for the command `siac renter workers hs`.
 // It lists the status of the has sector job queue for every worker.
 func renterworkershsjcmd() {
@@ -2608,3 +3402,121 @@ func renterworkersupdateregistrycmd() {
 	// Write Upload Info
 	writeWorkerReadUpdateRegistryInfo(false, w, rw)
 }
+
+// rentertracescmd is the handler for the command `siac renter traces`. Prints
+// the recent traces.
+func rentertracescmd() {
+	rtg, err := httpClient.RenterTracesGet()
+	if err != nil {
+		die("Could not get the traces:", err)
+	}
+	destination := rtg.ExportDestination
+	if destination == "" {
+		destination = "none"
+	}
+	fmt.Println("Export Destination:", destination)
+	if len(rtg.Traces) == 0 {
+		fmt.Println("No traces.")
+		return
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tName\tStart\tDuration\tSpans\tError")
+	for _, t := range rtg.Traces {
+		duration := "running"
+		if t.Finished {
+			duration = t.Duration.String()
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", t.ID, t.Name, t.Start.Format(time.RFC3339), duration, t.NumSpans, t.Error)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// rentertracesexportcmd is the handler for the command `siac renter traces
+// export`. Sets the export destination of the traces.
+func rentertracesexportcmd(destination string) {
+	if destination == "none" {
+		destination = ""
+	}
+	if err := httpClient.RenterTracesExportPost(destination); err != nil {
+		die("Could not set the export destination:", err)
+	}
+	if destination == "" {
+		fmt.Println("Disabled the export of the traces")
+		return
+	}
+	fmt.Println("The traces are exported to", destination)
+}
+
+// rentertracesotlpcmd is the handler for the command `siac renter traces
+// otlp`. Prints a trace in the OTLP JSON encoding.
+func rentertracesotlpcmd(id string) {
+	traces, err := httpClient.RenterTraceOTLPGet(id)
+	if err != nil {
+		die("Could not get the trace:", err)
+	}
+	b, err := json.MarshalIndent(traces, "", "  ")
+	if err != nil {
+		die("Could not marshal the trace:", err)
+	}
+	fmt.Println(string(b))
+}
+
+// rentertracesshowcmd is the handler for the command `siac renter traces
+// show`. Prints the spans of a trace as a tree.
+func rentertracesshowcmd(id string) {
+	t, err := httpClient.RenterTraceGet(id)
+	if err != nil {
+		die("Could not get the trace:", err)
+	}
+	fmt.Println("Trace:", t.ID)
+	fmt.Println("Name: ", t.Name)
+	fmt.Println("Start:", t.Start.Format(time.RFC3339Nano))
+	if t.DroppedSpans > 0 {
+		fmt.Println("Dropped Spans:", t.DroppedSpans)
+	}
+	fmt.Println()
+
+	// Spans are sorted by their start time. Print the children of each span
+	// below it. Spans whose parent wasn't recorded yet are printed at the top
+	// level.
+	children := make(map[string][]modules.RenterTraceSpan)
+	ids := make(map[string]struct{}, len(t.Spans))
+	for _, s := range t.Spans {
+		ids[s.ID] = struct{}{}
+	}
+	var roots []modules.RenterTraceSpan
+	for _, s := range t.Spans {
+		if _, exists := ids[s.ParentID]; !exists {
+			roots = append(roots, s)
+			continue
+		}
+		children[s.ParentID] = append(children[s.ParentID], s)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Span\tOffset\tDuration\tAttributes\tError")
+	var printSpan func(s modules.RenterTraceSpan, depth int)
+	printSpan = func(s modules.RenterTraceSpan, depth int) {
+		keys := make([]string, 0, len(s.Attributes))
+		for k := range s.Attributes {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		attributes := make([]string, 0, len(keys))
+		for _, k := range keys {
+			attributes = append(attributes, k+"="+s.Attributes[k])
+		}
+		fmt.Fprintf(w, "%v%v\t+%v\t%v\t%v\t%v\n", strings.Repeat("  ", depth), s.Name, s.Start.Sub(t.Start), s.Duration, strings.Join(attributes, " "), s.Error)
+		for _, c := range children[s.ID] {
+			printSpan(c, depth+1)
+		}
+	}
+	for _, s := range roots {
+		printSpan(s, 0)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"go.sia.tech/siad/types"
+)
+
+var (
+	daemonCmd = &cobra.Command{
+		Use:   "daemon",
+		Short: "Manage the daemon",
+		Long:  "Manage the daemon.",
+		Run:   daemoncmd,
+	}
+
+	daemonTokensCmd = &cobra.Command{
+		Use:   "tokens",
+		Short: "List the API tokens",
+		Long: `List the API tokens. API tokens can be used in place of the API password
+to only grant access to a subset of the API. Set SIA_API_PASSWORD to a token
+to use it with siac.`,
+		Run: wrap(daemontokenscmd),
+	}
+
+	daemonTokensCreateCmd = &cobra.Command{
+		Use:   "create [name] [scopes]",
+		Short: "Create an API token",
+		Long: `Create an API token with a comma separated list of scopes. Valid scopes are:
+  all               all routes except for the token management
+  <group>:read      GET requests to the route group, except for privileged ones
+  <group>:write     all requests to the route group
+  wallet:send       sending siacoins using /wallet/siacoins, requires a
+                    spending limit
+
+Route groups are the first element of the route, e.g. renter for
+/renter/files. The available groups are consensus, daemon, explorer, gateway,
+host, hostdb, metrics, miner, renter, tpool and wallet.
+
+Tokens of a tenant can only access the files of the tenant, relative to the
+folder of the tenant.
+
+The token is only printed once and can't be recovered afterwards.`,
+		Run: wrap(daemontokenscreatecmd),
+	}
+
+	daemonTokensRevokeCmd = &cobra.Command{
+		Use:   "revoke [name]",
+		Short: "Revoke an API token",
+		Long:  "Revoke an API token. The token can't be used anymore afterwards.",
+		Run:   wrap(daemontokensrevokecmd),
+	}
+)
+
+// daemoncmd displays the usage info for the command.
+func daemoncmd(cmd *cobra.Command, args []string) {
+	_ = cmd.UsageFunc()(cmd)
+	os.Exit(exitCodeUsage)
+}
+
+// daemontokenscmd is the handler for the command `siac daemon tokens`.
+// Lists the API tokens.
+func daemontokenscmd() {
+	dtg, err := httpClient.DaemonTokensGet()
+	if err != nil {
+		die("Could not get API tokens:", err)
+	}
+	if len(dtg.Tokens) == 0 {
+		fmt.Println("No API tokens.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tScopes\tTenant\tExpiry\tLast Used\tSpent\tSpending Limit")
+	for _, t := range dtg.Tokens {
+		expiry := "never"
+		if !t.Expiry.IsZero() {
+			expiry = t.Expiry.Format(time.RFC3339)
+		}
+		lastUsed := "never"
+		if !t.LastUsed.IsZero() {
+			lastUsed = t.LastUsed.Format(time.RFC3339)
+		}
+		limit := "none"
+		if !t.SpendingLimit.IsZero() {
+			limit = currencyUnits(t.SpendingLimit)
+		}
+		tenant := "-"
+		if t.Tenant != "" {
+			tenant = t.Tenant
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", t.Name, strings.Join(t.Scopes, ","), tenant, expiry, lastUsed, currencyUnits(t.Spent), limit)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// daemontokenscreatecmd is the handler for the command `siac daemon tokens
+// create [name] [scopes]`. Creates an API token.
+func daemontokenscreatecmd(name, scopes string) {
+	var expiry time.Duration
+	if daemonTokenExpiry != "" {
+		var err error
+		expiry, err = time.ParseDuration(daemonTokenExpiry)
+		if err != nil {
+			die("Could not parse expiry:", err)
+		}
+	}
+	var spendingLimit types.Currency
+	if daemonTokenSpendingLimit != "" {
+		hastings, err := types.ParseCurrency(daemonTokenSpendingLimit)
+		if err != nil {
+			die("Could not parse spending limit:", err)
+		}
+		if _, err := fmt.Sscan(hastings, &spendingLimit); err != nil {
+			die("Could not parse spending limit:", err)
+		}
+	}
+	dtcp, err := httpClient.DaemonTokensCreateTenantPost(name, daemonTokenTenant, strings.Split(scopes, ","), expiry, spendingLimit)
+	if err != nil {
+		die("Could not create API token:", err)
+	}
+	fmt.Printf("Created API token '%v'. It won't be shown again:\n%v\n", dtcp.Name, dtcp.Token)
+}
+
+// daemontokensrevokecmd is the handler for the command `siac daemon tokens
+// revoke [name]`. Revokes an API token.
+func daemontokensrevokecmd(name string) {
+	err := httpClient.DaemonTokensRevokePost(name)
+	if err != nil {
+		die("Could not revoke API token:", err)
+	}
+	fmt.Printf("Revoked API token '%v'\n", name)
+}
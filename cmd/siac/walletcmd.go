@@ -10,6 +10,7 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -140,6 +141,42 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletseedscmd),
 	}
 
+	walletApprovalsCmd = &cobra.Command{
+		Use:   "approvals",
+		Short: "List the spends waiting to be approved",
+		Long: `List the spends that exceed the approval threshold of the spending policy
+and are waiting to be approved. Pending spends expire after a day.`,
+		Run: wrap(walletapprovalscmd),
+	}
+
+	walletApprovalsApproveCmd = &cobra.Command{
+		Use:   "approve [id]",
+		Short: "Approve a pending spend",
+		Long: `Approve a pending spend and send the coins. The wallet password is required
+to approve a spend.`,
+		Run: wrap(walletapprovalsapprovecmd),
+	}
+
+	walletApprovalsRejectCmd = &cobra.Command{
+		Use:   "reject [id]",
+		Short: "Reject a pending spend",
+		Long:  "Reject a pending spend without sending the coins.",
+		Run:   wrap(walletapprovalsrejectcmd),
+	}
+
+	walletPolicyCmd = &cobra.Command{
+		Use:   "policy",
+		Short: "View or change the spending policy",
+		Long: `View or change the spending policy of the wallet. The policy limits the
+siacoins sent per day, the addresses coins can be sent to and the funds of the
+renter allowance. Spends above the approval threshold need to be approved with
+'wallet approvals approve'.
+
+Only the limits that are passed as flags are changed and the wallet password is
+required to change them. Amounts can be specified in units, e.g. 1.23KS.`,
+		Run: wrap(walletpolicycmd),
+	}
+
 	walletSendCmd = &cobra.Command{
 		Use:   "send",
 		Short: "Send either siacoins or siafunds to an address",
@@ -426,10 +463,14 @@ func walletsendsiacoinscmd(amount, dest string) {
 	if _, err := fmt.Sscan(dest, &hash); err != nil {
 		die("Failed to parse destination address", err)
 	}
-	_, err = httpClient.WalletSiacoinsPost(value, hash, walletTxnFeeIncluded)
+	wsp, err := httpClient.WalletSiacoinsPost(value, hash, walletTxnFeeIncluded)
 	if err != nil {
 		die("Could not send siacoins:", err)
 	}
+	if wsp.PendingSpendID != "" {
+		fmt.Printf("The spend exceeds the approval threshold, approve it with 'siac wallet approvals approve %v'\n", wsp.PendingSpendID)
+		return
+	}
 	fmt.Printf("Sent %s hastings to %s\n", hastings, dest)
 }
 
@@ -689,3 +730,141 @@ func walletunlockcmd() {
 		die("Could not unlock wallet:", err)
 	}
 }
+
+// parsePolicyAmount parses an amount of the spending policy.
+func parsePolicyAmount(amount string) types.Currency {
+	hastings, err := types.ParseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	return value
+}
+
+// policyLimitString returns the string of a limit of the spending policy.
+func policyLimitString(limit types.Currency) string {
+	if limit.IsZero() {
+		return "none"
+	}
+	return currencyUnits(limit)
+}
+
+// walletpolicycmd displays or changes the spending policy of the wallet.
+func walletpolicycmd() {
+	wpg, err := httpClient.WalletPolicyGet()
+	if err != nil {
+		die("Could not get spending policy:", err)
+	}
+	policy := wpg.SpendingPolicy
+
+	amounts := []struct {
+		value string
+		field *types.Currency
+	}{
+		{walletPolicyDailyLimit, &policy.DailyLimit},
+		{walletPolicyApprovalThreshold, &policy.ApprovalThreshold},
+		{walletPolicyMaxAllowanceFunds, &policy.MaxAllowanceFunds},
+		{walletPolicyMaxAllowanceIncrease, &policy.MaxAllowanceIncrease},
+	}
+	changed := false
+	for _, a := range amounts {
+		if a.value != "" {
+			*a.field = parsePolicyAmount(a.value)
+			changed = true
+		}
+	}
+	if walletPolicyAllowedDestinations != "" {
+		policy.AllowedDestinations = nil
+		for _, addrStr := range strings.Split(walletPolicyAllowedDestinations, ",") {
+			if addrStr = strings.TrimSpace(addrStr); addrStr == "" || addrStr == "all" {
+				continue
+			}
+			var addr types.UnlockHash
+			if err := addr.LoadString(addrStr); err != nil {
+				die("Failed to parse destination address", err)
+			}
+			policy.AllowedDestinations = append(policy.AllowedDestinations, addr)
+		}
+		changed = true
+	}
+
+	if changed {
+		password, err := passwordPrompt("Wallet password: ")
+		if err != nil {
+			die("Reading password failed:", err)
+		}
+		if err := httpClient.WalletPolicyPost(policy, password); err != nil {
+			die("Could not set spending policy:", err)
+		}
+		fmt.Println("Spending policy updated.")
+	}
+
+	fmt.Printf(`Spending Policy:
+  Daily Limit:            %v
+  Approval Threshold:     %v
+  Max Allowance:          %v
+  Max Allowance Increase: %v
+`, policyLimitString(policy.DailyLimit), policyLimitString(policy.ApprovalThreshold),
+		policyLimitString(policy.MaxAllowanceFunds), policyLimitString(policy.MaxAllowanceIncrease))
+	if len(policy.AllowedDestinations) == 0 {
+		fmt.Println("  Allowed Destinations:   all")
+		return
+	}
+	fmt.Println("  Allowed Destinations:")
+	for _, addr := range policy.AllowedDestinations {
+		fmt.Println("   ", addr)
+	}
+}
+
+// walletapprovalscmd lists the spends waiting to be approved.
+func walletapprovalscmd() {
+	wag, err := httpClient.WalletApprovalsGet()
+	if err != nil {
+		die("Could not get pending spends:", err)
+	}
+	if len(wag.PendingSpends) == 0 {
+		fmt.Println("No spends waiting to be approved.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tAmount\tDestinations\tFee Included\tExpires")
+	for _, ps := range wag.PendingSpends {
+		amount := types.ZeroCurrency
+		var dests []string
+		for _, sco := range ps.Outputs {
+			amount = amount.Add(sco.Value)
+			dests = append(dests, sco.UnlockHash.String())
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", ps.ID, currencyUnits(amount), strings.Join(dests, ","), ps.FeeIncluded, ps.Expires.Format(time.RFC822))
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// walletapprovalsapprovecmd approves a pending spend.
+func walletapprovalsapprovecmd(id string) {
+	password, err := passwordPrompt("Wallet password: ")
+	if err != nil {
+		die("Reading password failed:", err)
+	}
+	wsp, err := httpClient.WalletApprovalsApprovePost(id, password)
+	if err != nil {
+		die("Could not approve spend:", err)
+	}
+	fmt.Println("Approved spend, transaction IDs:")
+	for _, txid := range wsp.TransactionIDs {
+		fmt.Println("  ", txid)
+	}
+}
+
+// walletapprovalsrejectcmd rejects a pending spend.
+func walletapprovalsrejectcmd(id string) {
+	if err := httpClient.WalletApprovalsRejectPost(id); err != nil {
+		die("Could not reject spend:", err)
+	}
+	fmt.Println("Rejected spend", id)
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/node"
+)
+
+var (
+	// backupIncludeConsensus includes the consensus set in a backup.
+	backupIncludeConsensus bool
+
+	// backupRelocations are the storage folder relocations of a restore in
+	// the form old=new.
+	backupRelocations []string
+)
+
+// backupCommand creates the `siad backup` command and its subcommands.
+func backupCommand() *cobra.Command {
+	backupCmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Create and restore whole-node backups",
+		Long: `Create and restore encrypted backups of the whole node, including the wallet,
+the host, the renter and the other modules. Backups are authenticated with the
+password, a backup that was modified is rejected when restoring it.
+
+Backups are taken offline. siad needs to be stopped while a backup is created or
+restored, backups of a running node are not supported. The node's directory is
+locked in the meantime, so siad can't be started until the backup is done.
+
+The sectors stored in the host's storage folders are not part of a backup, only
+their metadata is. Restoring a host on a new machine requires moving the storage
+folders as well. Use --relocate if their paths change.
+
+The password is read from the SIA_BACKUP_PASSWORD environment variable or
+prompted for.`,
+	}
+	backupCmd.PersistentFlags().StringVarP(&globalConfig.Siad.SiaDir, "sia-directory", "d", "", "location of the sia directory")
+
+	createCmd := &cobra.Command{
+		Use:   "create [archive]",
+		Short: "Create a backup of the node",
+		Long: `Create an encrypted backup of the node. The consensus set is excluded unless
+--include-consensus is set, it is rebuilt after restoring the backup.`,
+		Args: cobra.ExactArgs(1),
+		Run:  backupCreateCmd,
+	}
+	createCmd.Flags().BoolVarP(&backupIncludeConsensus, "include-consensus", "", false, "include the consensus set in the backup")
+
+	restoreCmd := &cobra.Command{
+		Use:   "restore [archive]",
+		Short: "Restore a backup of the node",
+		Long: `Restore a backup of the node into the sia directory, which needs to be empty or
+not exist yet. Use --relocate old=new to change the path of a storage folder,
+the flag can be provided multiple times. The metadata of a storage folder is
+restored if the folder doesn't contain it yet.`,
+		Args: cobra.ExactArgs(1),
+		Run:  backupRestoreCmd,
+	}
+	restoreCmd.Flags().StringArrayVarP(&backupRelocations, "relocate", "", nil, "relocate a storage folder, in the form old=new")
+
+	backupCmd.AddCommand(createCmd, restoreCmd)
+	return backupCmd
+}
+
+// backupPassword returns the password of a backup from the environment or
+// prompts for it.
+func backupPassword(confirm bool) string {
+	if pw := build.BackupPassword(); pw != "" {
+		return pw
+	}
+	pw, err := passwordPrompt("Backup password: ")
+	if err != nil {
+		die("Could not read password:", err)
+	}
+	if confirm {
+		pw2, err := passwordPrompt("Confirm backup password: ")
+		if err != nil {
+			die("Could not read password:", err)
+		}
+		if pw != pw2 {
+			die("Passwords don't match")
+		}
+	}
+	return pw
+}
+
+// backupCreateCmd is the handler for the command `siad backup create
+// [archive]`.
+func backupCreateCmd(_ *cobra.Command, args []string) {
+	opts := node.BackupOptions{IncludeConsensus: backupIncludeConsensus}
+	if err := node.CreateBackup(globalConfig.Siad.SiaDir, args[0], backupPassword(true), opts); err != nil {
+		die("Could not create backup:", err)
+	}
+	fmt.Println("Backup created at", args[0])
+}
+
+// backupRestoreCmd is the handler for the command `siad backup restore
+// [archive]`.
+func backupRestoreCmd(_ *cobra.Command, args []string) {
+	relocations := make(map[string]string)
+	for _, r := range backupRelocations {
+		parts := strings.SplitN(r, "=", 2)
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			die("Invalid relocation, expected old=new:", r)
+		}
+		relocations[parts[0]] = parts[1]
+	}
+	if err := node.RestoreBackup(args[0], globalConfig.Siad.SiaDir, backupPassword(false), relocations); err != nil {
+		die("Could not restore backup:", err)
+	}
+	fmt.Println("Backup restored, start siad to load it")
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/spf13/pflag"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/node/api"
+)
+
+// loadConfigFileFlags loads the 'siad' section of the config file. The section
+// maps flag names to their values.
+func loadConfigFileFlags(path string) (map[string]string, error) {
+	cf, err := api.LoadConfigFile(path)
+	if err != nil {
+		return nil, err
+	}
+	values := make(map[string]string)
+	if len(cf.Siad) == 0 {
+		return values, nil
+	}
+	var section map[string]interface{}
+	if err := json.Unmarshal(cf.Siad, &section); err != nil {
+		return nil, errors.AddContext(err, "section 'siad' needs to be an object")
+	}
+	for flag, value := range section {
+		switch value.(type) {
+		case string, bool, float64:
+			values[flag] = fmt.Sprint(value)
+		default:
+			return nil, fmt.Errorf("invalid value for 'siad.%v'", flag)
+		}
+	}
+	return values, nil
+}
+
+// applyConfigFileFlags sets the flags of the 'siad' section of the config file.
+// Flags that were set on the command line take precedence over the file.
+func applyConfigFileFlags(flags *pflag.FlagSet, values map[string]string) error {
+	for name, value := range values {
+		flag := flags.Lookup(name)
+		if flag == nil || name == "config-file" {
+			return fmt.Errorf("unknown setting 'siad.%v'", name)
+		}
+		if flag.Changed {
+			continue
+		}
+		if err := flags.Set(name, value); err != nil {
+			return errors.AddContext(err, fmt.Sprintf("invalid value for 'siad.%v'", name))
+		}
+	}
+	return nil
+}
+
+// diffConfigFileFlags returns the changed flags of the 'siad' section. These
+// changes only take effect after a restart.
+func diffConfigFileFlags(old, new map[string]string) []api.ConfigChange {
+	names := make(map[string]struct{})
+	for name := range old {
+		names[name] = struct{}{}
+	}
+	for name := range new {
+		names[name] = struct{}{}
+	}
+	var changes []api.ConfigChange
+	for name := range names {
+		if old[name] != new[name] {
+			changes = append(changes, api.ConfigChange{
+				Setting: "siad." + name,
+				Old:     old[name],
+				New:     new[name],
+			})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Setting < changes[j].Setting
+	})
+	return changes
+}
+
+// printConfigChanges prints the changes made by applying the config file.
+func printConfigChanges(changes []api.ConfigChange) {
+	for _, c := range changes {
+		fmt.Printf("  %v: %v -> %v\n", c.Setting, c.Old, c.New)
+	}
+}
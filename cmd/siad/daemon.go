@@ -1,6 +1,7 @@
 package main
 
 import (
+	"crypto/tls"
 	"fmt"
 	"os"
 	"os/signal"
@@ -17,7 +18,9 @@ import (
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/node/api/server"
+	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/profile"
+	"go.sia.tech/siad/types"
 )
 
 // passwordPrompt securely reads a password from stdin.
@@ -31,6 +34,15 @@ func passwordPrompt(prompt string) (string, error) {
 // verifyAPISecurity checks that the security values are consistent with a
 // sane, secure system.
 func verifyAPISecurity(config Config) error {
+	// Non-loopback addresses are allowed if the API is served over TLS and
+	// requires authentication.
+	if config.Siad.APITLS && !config.Siad.AllowAPIBind {
+		if !config.Siad.AuthenticateAPI && config.Siad.APITLSClientCA == "" {
+			return errors.New("serving the API over TLS to non-localhost addresses requires an api password or client certificates")
+		}
+		return nil
+	}
+
 	// Make sure that only the loopback address is allowed unless the
 	// --disable-api-security flag has been used.
 	if !config.Siad.AllowAPIBind {
@@ -39,7 +51,7 @@ func verifyAPISecurity(config Config) error {
 			if addr.Host() == "" {
 				return fmt.Errorf("a blank host will listen on all interfaces, did you mean localhost:%v?\nyou must pass --disable-api-security to bind Siad to a non-localhost address", addr.Port())
 			}
-			return errors.New("you must pass --disable-api-security to bind Siad to a non-localhost address")
+			return errors.New("you must pass --api-tls or --disable-api-security to bind Siad to a non-localhost address")
 		}
 		return nil
 	}
@@ -105,6 +117,55 @@ func processModules(modules string) (string, error) {
 	return modules, nil
 }
 
+// processLogLevels parses the --log-level flag. The flag is a comma separated
+// list of 'module=level' pairs and an optional bare level, which is returned
+// as the level of the empty module and sets the default level.
+func processLogLevels(levels string) (map[string]persist.LogLevel, error) {
+	parsed := make(map[string]persist.LogLevel)
+	for _, entry := range strings.Split(levels, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var module, level string
+		if i := strings.Index(entry, "="); i >= 0 {
+			module, level = entry[:i], entry[i+1:]
+			if module == "" {
+				return nil, fmt.Errorf("missing module in log level '%v'", entry)
+			}
+		} else {
+			level = entry
+		}
+		l, err := persist.ParseLogLevel(level)
+		if err != nil {
+			return nil, err
+		}
+		if _, exists := parsed[module]; exists {
+			return nil, fmt.Errorf("duplicate log level for module '%v'", module)
+		}
+		parsed[module] = l
+	}
+	return parsed, nil
+}
+
+// applyLogSettings applies the log flags to the loggers of all modules.
+func applyLogSettings(config Config) error {
+	levels, err := processLogLevels(config.Siad.LogLevel)
+	if err != nil {
+		return errors.AddContext(err, "unable to parse --log-level flag")
+	}
+	if err := persist.SetLogFormat(config.Siad.LogFormat); err != nil {
+		return errors.AddContext(err, "unable to parse --log-format flag")
+	}
+	for module, level := range levels {
+		if err := persist.SetLogLevel(module, level); err != nil {
+			return err
+		}
+	}
+	persist.SetLogStdout(config.Siad.LogStdout)
+	return nil
+}
+
 // processConfig checks the configuration values and performs cleanup on
 // incorrect-but-allowed values.
 func processConfig(config Config) (Config, error) {
@@ -113,11 +174,15 @@ func processConfig(config Config) (Config, error) {
 	config.Siad.RPCaddr = processNetAddr(config.Siad.RPCaddr)
 	config.Siad.HostAddr = processNetAddr(config.Siad.HostAddr)
 	config.Siad.Modules, err1 = processModules(config.Siad.Modules)
+	if config.Siad.APITLSCert != "" || config.Siad.APITLSKey != "" || config.Siad.APITLSClientCA != "" {
+		config.Siad.APITLS = true
+	}
 	if config.Siad.Profile != "" {
 		config.Siad.Profile, err2 = profile.ProcessProfileFlags(config.Siad.Profile)
 	}
 	err3 := verifyAPISecurity(config)
-	err := build.JoinErrors([]error{err1, err2, err3}, ", and ")
+	_, err4 := processLogLevels(config.Siad.LogLevel)
+	err := build.JoinErrors([]error{err1, err2, err3, err4}, ", and ")
 	if err != nil {
 		return Config{}, err
 	}
@@ -185,6 +250,37 @@ func installKillSignalHandler() chan os.Signal {
 	return sigChan
 }
 
+// installReloadSignalHandler installs a signal handler for syscall.SIGHUP and
+// returns a channel that receives a value when it is caught.
+func installReloadSignalHandler() chan os.Signal {
+	hupChan := make(chan os.Signal, 1)
+	signal.Notify(hupChan, syscall.SIGHUP)
+	return hupChan
+}
+
+// reloadConfigFile applies the config file to the modules of the server and
+// prints the changes. Changes of the 'siad' section require a restart and are
+// only reported.
+func reloadConfigFile(srv *server.Server, path string, startupFlags map[string]string) {
+	flags, err := loadConfigFileFlags(path)
+	if err != nil {
+		fmt.Println("Failed to reload config file:", err)
+		return
+	}
+	changes, err := srv.ReloadConfig()
+	if len(changes) > 0 {
+		fmt.Println("Applied config file changes:")
+		printConfigChanges(changes)
+	}
+	if err != nil {
+		fmt.Println("Failed to reload config file:", err)
+	}
+	if restart := diffConfigFileFlags(startupFlags, flags); len(restart) > 0 {
+		fmt.Println("Config file changes that require a restart:")
+		printConfigChanges(restart)
+	}
+}
+
 // startDaemon uses the config parameters to initialize Sia modules and start
 // siad.
 func startDaemon(config Config) (err error) {
@@ -199,6 +295,15 @@ func startDaemon(config Config) (err error) {
 	// Print the siad Version and GitRevision
 	printVersionAndRevision()
 
+	// Load the custom network before any modules are created.
+	if config.Siad.Network != "" {
+		network, err := modules.LoadNetwork(config.Siad.Network)
+		if err != nil {
+			return errors.AddContext(err, "failed to load network")
+		}
+		fmt.Printf("Using network '%v' with genesis block %v\n", network.Name, types.GenesisID)
+	}
+
 	// Install a signal handler that will catch exceptions thrown by mmap'd
 	// files.
 	installMmapSignalHandler()
@@ -211,27 +316,72 @@ func startDaemon(config Config) (err error) {
 	// set the wallet password from the environment variable
 	nodeParams.WalletPassword = build.WalletPassword()
 
+	// Load the TLS config of the API.
+	var tlsConfig *tls.Config
+	if config.Siad.APITLS {
+		tlsConfig, err = server.NewTLSConfig(nodeParams.Dir, server.TLSOptions{
+			CertFile:     config.Siad.APITLSCert,
+			KeyFile:      config.Siad.APITLSKey,
+			ClientCAFile: config.Siad.APITLSClientCA,
+		})
+		if err != nil {
+			return errors.AddContext(err, "failed to load API TLS config")
+		}
+	}
+
 	// Start and run the server.
-	srv, err := server.New(config.Siad.APIaddr, config.Siad.RequiredUserAgent, config.APIPassword, nodeParams, loadStart)
+	srv, err := server.NewWithTLS(config.Siad.APIaddr, config.Siad.RequiredUserAgent, config.APIPassword, tlsConfig, nodeParams, loadStart)
 	if err != nil {
 		return err
 	}
+	if tlsConfig != nil {
+		fmt.Println("API is served over TLS, certificate fingerprint:", srv.TLSFingerprint())
+	}
 
-	// listen for kill signals
+	// Apply the config file to the modules.
+	var startupFlags map[string]string
+	if config.Siad.ConfigFile != "" {
+		startupFlags, err = loadConfigFileFlags(config.Siad.ConfigFile)
+		if err != nil {
+			return errors.Compose(err, srv.Close())
+		}
+		srv.SetConfigFile(config.Siad.ConfigFile)
+		changes, err := srv.ReloadConfig()
+		if err != nil {
+			return errors.Compose(errors.AddContext(err, "failed to apply config file"), srv.Close())
+		}
+		if len(changes) > 0 {
+			fmt.Println("Applied config file changes:")
+			printConfigChanges(changes)
+		}
+	}
+
+	// listen for kill and reload signals
 	sigChan := installKillSignalHandler()
+	hupChan := installReloadSignalHandler()
 
 	// Print a 'startup complete' message.
 	startupTime := time.Since(loadStart)
 	fmt.Printf("Finished full setup in %s\n", startupTime.Truncate(time.Second).String())
 
 	// wait for Serve to return or for kill signal to be caught
+	serveErr := srv.ServeErr()
 	err = func() error {
-		select {
-		case err := <-srv.ServeErr():
-			return err
-		case <-sigChan:
-			fmt.Println("\rCaught stop signal, quitting...")
-			return srv.Close()
+		for {
+			select {
+			case err := <-serveErr:
+				return err
+			case <-hupChan:
+				if config.Siad.ConfigFile == "" {
+					fmt.Println("Caught reload signal but siad wasn't started with a config file")
+					continue
+				}
+				fmt.Println("Caught reload signal, reloading config file...")
+				reloadConfigFile(srv, config.Siad.ConfigFile, startupFlags)
+			case <-sigChan:
+				fmt.Println("\rCaught stop signal, quitting...")
+				return srv.Close()
+			}
 		}
 	}()
 	if err != nil {
@@ -246,11 +396,26 @@ func startDaemon(config Config) (err error) {
 
 // startDaemonCmd is a passthrough function for startDaemon.
 func startDaemonCmd(cmd *cobra.Command, _ []string) {
+	// Apply the startup flags of the config file. Flags set on the command
+	// line take precedence.
+	if globalConfig.Siad.ConfigFile != "" {
+		flags, err := loadConfigFileFlags(globalConfig.Siad.ConfigFile)
+		if err == nil {
+			err = applyConfigFileFlags(cmd.Flags(), flags)
+		}
+		if err != nil {
+			die(errors.AddContext(err, "failed to load config file"))
+		}
+	}
+
 	// Process the config variables after they are parsed by cobra.
 	config, err := processConfig(globalConfig)
 	if err != nil {
 		die(errors.AddContext(err, "failed to parse input parameter"))
 	}
+	if err := applyLogSettings(config); err != nil {
+		die(errors.AddContext(err, "failed to apply log settings"))
+	}
 
 	// Parse profile flags
 	profileCPU := strings.Contains(config.Siad.Profile, "c")
@@ -2,6 +2,10 @@ package main
 
 import (
 	"testing"
+
+	"github.com/spf13/pflag"
+
+	"go.sia.tech/siad/persist"
 )
 
 // TestUnitProcessNetAddr probes the 'processNetAddr' function.
@@ -82,6 +86,25 @@ func TestUnitProcessModules(t *testing.T) {
 	}
 }
 
+// TestUnitProcessLogLevels probes the 'processLogLevels' function.
+func TestUnitProcessLogLevels(t *testing.T) {
+	levels, err := processLogLevels("info, renter=debug,host=WARN")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(levels) != 3 || levels[""] != persist.LogLevelInfo || levels["renter"] != persist.LogLevelDebug || levels["host"] != persist.LogLevelWarn {
+		t.Fatal("unexpected levels", levels)
+	}
+	if levels, err := processLogLevels(""); err != nil || len(levels) != 0 {
+		t.Fatal("unexpected result for empty flag", levels, err)
+	}
+	for _, invalid := range []string{"verbose", "renter=", "=debug", "info,error", "renter=info,renter=debug"} {
+		if _, err := processLogLevels(invalid); err == nil {
+			t.Error("processLogLevels didn't error on invalid levels:", invalid)
+		}
+	}
+}
+
 // TestUnitProcessConfig probes the 'processConfig' function.
 func TestUnitProcessConfig(t *testing.T) {
 	// Test valid configs.
@@ -206,4 +229,70 @@ func TestVerifyAPISecurity(t *testing.T) {
 	if err != nil {
 		t.Error("public + securityOff with authentication was rejected:", err)
 	}
+
+	// Check that a public hostname is accepted when the API is served over
+	// TLS with either an api password or client certificates.
+	var tlsPublic Config
+	tlsPublic.Siad.APIaddr = ":9980"
+	tlsPublic.Siad.APITLS = true
+	if err := verifyAPISecurity(tlsPublic); err == nil {
+		t.Error("public + TLS was accepted without authentication")
+	}
+	tlsPublic.Siad.AuthenticateAPI = true
+	if err := verifyAPISecurity(tlsPublic); err != nil {
+		t.Error("public + TLS with authentication was rejected:", err)
+	}
+	tlsPublic.Siad.AuthenticateAPI = false
+	tlsPublic.Siad.APITLSClientCA = "ca.crt"
+	if err := verifyAPISecurity(tlsPublic); err != nil {
+		t.Error("public + TLS with client certificates was rejected:", err)
+	}
+}
+
+// TestUnitConfigFileFlags probes applying and diffing the startup flags of the
+// config file.
+func TestUnitConfigFileFlags(t *testing.T) {
+	var apiAddr string
+	var upnp bool
+	newFlags := func() *pflag.FlagSet {
+		flags := pflag.NewFlagSet("siad", pflag.ContinueOnError)
+		flags.StringVar(&apiAddr, "api-addr", "localhost:9980", "")
+		flags.BoolVar(&upnp, "upnp", true, "")
+		flags.String("config-file", "", "")
+		return flags
+	}
+
+	// Flags of the file are applied.
+	flags := newFlags()
+	if err := applyConfigFileFlags(flags, map[string]string{"api-addr": "localhost:9000", "upnp": "false"}); err != nil {
+		t.Fatal(err)
+	}
+	if apiAddr != "localhost:9000" || upnp {
+		t.Fatal("flags weren't applied", apiAddr, upnp)
+	}
+
+	// Flags set on the command line take precedence.
+	flags = newFlags()
+	if err := flags.Parse([]string{"--api-addr", "localhost:9001"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := applyConfigFileFlags(flags, map[string]string{"api-addr": "localhost:9000"}); err != nil {
+		t.Fatal(err)
+	}
+	if apiAddr != "localhost:9001" {
+		t.Fatal("command line flag was overwritten", apiAddr)
+	}
+
+	// Unknown flags and invalid values are rejected.
+	for _, values := range []map[string]string{{"foo": "bar"}, {"config-file": "other.json"}, {"upnp": "maybe"}} {
+		if err := applyConfigFileFlags(newFlags(), values); err == nil {
+			t.Fatal("expected error", values)
+		}
+	}
+
+	// Changed flags are reported.
+	changes := diffConfigFileFlags(map[string]string{"api-addr": "localhost:9000", "upnp": "false"}, map[string]string{"api-addr": "localhost:9002", "rpc-addr": ":9981", "upnp": "false"})
+	if len(changes) != 2 || changes[0].Setting != "siad.api-addr" || changes[1].Setting != "siad.rpc-addr" || changes[1].Old != "" {
+		t.Fatal("unexpected changes", changes)
+	}
 }
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 
 	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/persist"
 )
 
 var (
@@ -39,6 +40,13 @@ type Config struct {
 		SiaMuxWSAddr  string
 		AllowAPIBind  bool
 
+		// The API is served over TLS if APITLS is set. Setting any of the
+		// TLS files enables it as well.
+		APITLS         bool
+		APITLSCert     string
+		APITLSKey      string
+		APITLSClientCA string
+
 		Modules           string
 		NoBootstrap       bool
 		UseUPNP           bool
@@ -49,6 +57,20 @@ type Config struct {
 		Profile    string
 		ProfileDir string
 
+		// The log settings apply to the loggers of all modules. LogLevel is
+		// a comma separated list of levels, either 'module=level' or a
+		// bare level for the default level.
+		LogFormat string
+		LogLevel  string
+		LogStdout bool
+
+		// ConfigFile is the path of the declarative config file.
+		ConfigFile string
+
+		// Network is the path of a network file that replaces the network
+		// parameters selected by the build tags.
+		Network string
+
 		// NOTE: SiaDir in this case is referencing the directory that siad is
 		// going to be running out of, not the actual siadir, which is where we
 		// put the apipassword file. This variable should not be altered if it
@@ -188,12 +210,19 @@ func main() {
 		Run:   modulesCmd,
 	})
 
+	root.AddCommand(backupCommand())
+
 	// Set default values, which have the lowest priority.
 	root.Flags().StringVarP(&globalConfig.Siad.RequiredUserAgent, "agent", "", "Sia-Agent", "required substring for the user agent")
 	root.Flags().StringVarP(&globalConfig.Siad.HostAddr, "host-addr", "", defaultRHP2Addr, "which port the host listens on")
 	root.Flags().StringVarP(&globalConfig.Siad.ProfileDir, "profile-directory", "", "profiles", "location of the profiling directory")
+	root.Flags().StringVarP(&globalConfig.Siad.ConfigFile, "config-file", "", "", "path of the siad config file, reloaded on SIGHUP")
+	root.Flags().StringVarP(&globalConfig.Siad.LogFormat, "log-format", "", persist.LogFormatText, "format of the log files, 'text' or 'json'")
+	root.Flags().StringVarP(&globalConfig.Siad.LogLevel, "log-level", "", "", "log levels, e.g. 'info,renter=debug' for debug logs of the renter only")
+	root.Flags().BoolVarP(&globalConfig.Siad.LogStdout, "log-stdout", "", false, "also write the logs of all modules to stdout")
 	root.Flags().StringVarP(&globalConfig.Siad.APIaddr, "api-addr", "", defaultAPIAddr, "which host:port the API server listens on")
 	root.Flags().StringVarP(&globalConfig.Siad.SiaDir, "sia-directory", "d", "", "location of the sia directory")
+	root.Flags().StringVarP(&globalConfig.Siad.Network, "network", "", "", "path of a network file that defines the genesis block and parameters of a custom network")
 	root.Flags().BoolVarP(&globalConfig.Siad.NoBootstrap, "no-bootstrap", "", false, "disable bootstrapping on this run")
 	root.Flags().BoolVarP(&globalConfig.Siad.UseUPNP, "upnp", "", true, "use UPnP for port forwarding and external IP discovery")
 	root.Flags().StringVarP(&globalConfig.Siad.Profile, "profile", "", "", "enable profiling with flags 'cmt' for CPU, memory, trace")
@@ -203,6 +232,10 @@ func main() {
 	root.Flags().StringVarP(&globalConfig.Siad.Modules, "modules", "M", "gctwrhfa", "enabled modules, see 'siad modules' for more info")
 	root.Flags().BoolVarP(&globalConfig.Siad.AuthenticateAPI, "authenticate-api", "", true, "enable API password protection")
 	root.Flags().BoolVarP(&globalConfig.Siad.TempPassword, "temp-password", "", false, "enter a temporary API password during startup")
+	root.Flags().BoolVarP(&globalConfig.Siad.APITLS, "api-tls", "", false, "serve the API over TLS, uses a self-signed certificate unless --api-tls-cert is set")
+	root.Flags().StringVarP(&globalConfig.Siad.APITLSCert, "api-tls-cert", "", "", "path of the API's TLS certificate")
+	root.Flags().StringVarP(&globalConfig.Siad.APITLSKey, "api-tls-key", "", "", "path of the API's TLS key")
+	root.Flags().StringVarP(&globalConfig.Siad.APITLSClientCA, "api-tls-client-ca", "", "", "path of the CA certificates that API client certificates need to be signed by")
 	root.Flags().BoolVarP(&globalConfig.Siad.AllowAPIBind, "disable-api-security", "", false, "allow siad to listen on a non-localhost address (DANGEROUS)")
 
 	// If globalConfig.Siad.SiaDir is not set, use the environment variable provided.
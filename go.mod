@@ -13,6 +13,7 @@ require (
 	github.com/klauspost/reedsolomon v1.9.3
 	github.com/pkg/errors v0.9.1
 	github.com/spf13/cobra v1.0.0
+	github.com/spf13/pflag v1.0.3
 	github.com/vbauerster/mpb/v5 v5.0.3
 	gitlab.com/NebulousLabs/bolt v1.4.4
 	gitlab.com/NebulousLabs/demotemutex v0.0.0-20151003192217-235395f71c40
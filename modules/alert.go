@@ -56,6 +56,9 @@ const (
 	// registered if the host has insufficient collateral budget left to form or
 	// renew a contract
 	AlertIDHostInsufficientCollateral = "host-insufficient-collateral"
+	// AlertIDHostDBGeoIPDatabase is the id of the alert that is registered if
+	// the GeoIP database of the host selection policy can't be loaded.
+	AlertIDHostDBGeoIPDatabase = "hostdb-geoip-database"
 )
 
 // AlertIDSiafileLowRedundancy uses a Siafile's UID to create a unique AlertID
@@ -64,6 +67,19 @@ func AlertIDSiafileLowRedundancy(uid string) AlertID {
 	return AlertID(fmt.Sprintf("low-redundancy:%v", uid))
 }
 
+// AlertIDSiafileVerifyFailed uses a Siafile's UID to create a unique AlertID
+// for a failed verification of the file's data against its checksum.
+func AlertIDSiafileVerifyFailed(uid string) AlertID {
+	return AlertID(fmt.Sprintf("verify-failed:%v", uid))
+}
+
+// AlertIDSiafileUploadFailed uses a SiaPath to create a unique AlertID for a
+// failed background upload of a compressed file. The SiaPath is used since the
+// file is deleted after the upload failed.
+func AlertIDSiafileUploadFailed(siaPath string) AlertID {
+	return AlertID(fmt.Sprintf("upload-failed:%v", siaPath))
+}
+
 type (
 	// Alerter is the interface implemented by all top-level modules. It's an
 	// interface that allows for asking a module about potential issues.
@@ -73,6 +89,8 @@ type (
 
 	// Alert is a type that contains essential information about an alert.
 	Alert struct {
+		// ID is the id of the Alert. It's unique within the module.
+		ID AlertID `json:"id"`
 		// Cause is the cause for the Alert.
 		// e.g. "Wallet is locked"
 		Cause string `json:"cause"`
@@ -201,6 +219,7 @@ func (a *GenericAlerter) RegisterAlert(id AlertID, msg, cause string, severity A
 	a.mu.Lock()
 	defer a.mu.Unlock()
 	a.alerts[id] = Alert{
+		ID:       id,
 		Cause:    cause,
 		Module:   a.module,
 		Msg:      msg,
@@ -143,7 +143,7 @@ func validStorageProofs100e3(tx *bolt.Tx, t types.Transaction) error {
 		// instead of crypto.SegmentSize, due to an error with the modulus
 		// math. This new error has been fixed with the block 100,000 hardfork.
 		height := blockHeight(tx)
-		if (build.Release == "standard" && height < 21e3) || (build.Release == "testnet" && height < 2) || (build.Release == "testing" && height < 10) {
+		if height < types.StorageProofSegmentHardforkHeight {
 			segmentLen = uint64(crypto.SegmentSize)
 		}
 
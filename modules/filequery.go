@@ -0,0 +1,228 @@
+package modules
+
+import (
+	"fmt"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// filequery.go contains the types for querying the files of the renter. A
+// query consists of a filter, the field to sort the matching files by and the
+// size of the pages they are returned in.
+//
+// A filter is a comma separated list of conditions of the form
+// <field><operator><value>, e.g. "health>0.25,stuck=false,name=*.png". A file
+// matches a filter if it matches all of its conditions.
+
+// File query fields. All fields except for stuck can be used to sort by.
+const (
+	FileFieldHealth     = "health"
+	FileFieldModTime    = "modtime"
+	FileFieldName       = "name"
+	FileFieldRedundancy = "redundancy"
+	FileFieldSize       = "size"
+	FileFieldStuck      = "stuck"
+)
+
+// File query operators.
+const (
+	FileOpEqual        = "="
+	FileOpNotEqual     = "!="
+	FileOpLess         = "<"
+	FileOpLessEqual    = "<="
+	FileOpGreater      = ">"
+	FileOpGreaterEqual = ">="
+)
+
+var (
+	// ErrInvalidFileFilter is returned for filters that can't be parsed.
+	ErrInvalidFileFilter = errors.New("invalid file filter")
+
+	// ErrInvalidFileSort is returned for fields files can't be sorted by.
+	ErrInvalidFileSort = errors.New("invalid file sort field")
+
+	// fileFilterOps are the operators of a condition. Operators which are
+	// prefixes of other operators come last.
+	fileFilterOps = []string{FileOpLessEqual, FileOpGreaterEqual, FileOpNotEqual, FileOpLess, FileOpGreater, FileOpEqual}
+)
+
+type (
+	// FileQuery describes which files to return and in which order. Files are
+	// sorted by siapath if Sort is empty and ties are broken by siapath. A
+	// Limit of 0 returns all matching files. Cursor is the NextCursor of a
+	// prior query and continues the listing where that query stopped.
+	FileQuery struct {
+		Filter     FileFilter
+		Tags       UserMetadata
+		Sort       string
+		Descending bool
+		Limit      int
+		Cursor     string
+	}
+
+	// FileFilter is a list of conditions a file needs to match.
+	FileFilter []FileCondition
+
+	// FileCondition compares a field of a file to a value.
+	FileCondition struct {
+		Field string
+		Op    string
+		Value string
+
+		num  float64
+		time time.Time
+		b    bool
+	}
+)
+
+// ParseFileFilter parses a comma separated list of conditions. An empty
+// string results in a filter that matches all files.
+func ParseFileFilter(s string) (FileFilter, error) {
+	var filter FileFilter
+	for _, str := range strings.Split(s, ",") {
+		str = strings.TrimSpace(str)
+		if str == "" {
+			continue
+		}
+		fc, err := parseFileCondition(str)
+		if err != nil {
+			return nil, errors.Compose(ErrInvalidFileFilter, errors.AddContext(err, str))
+		}
+		filter = append(filter, fc)
+	}
+	return filter, nil
+}
+
+// ValidateFileSort checks that files can be sorted by the field. The empty
+// string sorts files by siapath.
+func ValidateFileSort(field string) error {
+	switch field {
+	case "", FileFieldHealth, FileFieldModTime, FileFieldName, FileFieldRedundancy, FileFieldSize:
+		return nil
+	default:
+		return errors.AddContext(ErrInvalidFileSort, field)
+	}
+}
+
+// parseFileCondition parses a single condition of a filter.
+func parseFileCondition(s string) (fc FileCondition, err error) {
+	// The condition is split at the first operator. Values might contain
+	// operators, e.g. name globs.
+LOOP:
+	for i := 1; i < len(s); i++ {
+		for _, op := range fileFilterOps {
+			if strings.HasPrefix(s[i:], op) {
+				fc.Field, fc.Op, fc.Value = strings.TrimSpace(s[:i]), op, strings.TrimSpace(s[i+len(op):])
+				break LOOP
+			}
+		}
+	}
+	if fc.Op == "" {
+		return FileCondition{}, errors.New("condition needs to be of the form <field><operator><value>")
+	}
+	equality := fc.Op == FileOpEqual || fc.Op == FileOpNotEqual
+	switch fc.Field {
+	case FileFieldHealth, FileFieldRedundancy, FileFieldSize:
+		fc.num, err = strconv.ParseFloat(fc.Value, 64)
+	case FileFieldModTime:
+		fc.time, err = time.Parse(time.RFC3339, fc.Value)
+	case FileFieldName:
+		if !equality {
+			return FileCondition{}, fmt.Errorf("%v only supports %v and %v", fc.Field, FileOpEqual, FileOpNotEqual)
+		}
+		_, err = path.Match(fc.Value, "")
+	case FileFieldStuck:
+		if !equality {
+			return FileCondition{}, fmt.Errorf("%v only supports %v and %v", fc.Field, FileOpEqual, FileOpNotEqual)
+		}
+		fc.b, err = strconv.ParseBool(fc.Value)
+	default:
+		return FileCondition{}, fmt.Errorf("unknown field '%v'", fc.Field)
+	}
+	if err != nil {
+		return FileCondition{}, errors.AddContext(err, "invalid value")
+	}
+	return fc, nil
+}
+
+// Match returns true if the file matches all conditions of the filter.
+func (f FileFilter) Match(fi FileInfo) bool {
+	for _, fc := range f {
+		if !fc.Match(fi) {
+			return false
+		}
+	}
+	return true
+}
+
+// String returns the filter in the form it is parsed from.
+func (f FileFilter) String() string {
+	conditions := make([]string, 0, len(f))
+	for _, fc := range f {
+		conditions = append(conditions, fc.Field+fc.Op+fc.Value)
+	}
+	return strings.Join(conditions, ",")
+}
+
+// Match returns true if the file matches the condition.
+func (fc FileCondition) Match(fi FileInfo) bool {
+	var cmp int
+	switch fc.Field {
+	case FileFieldHealth:
+		cmp = compareFloats(fi.Health, fc.num)
+	case FileFieldRedundancy:
+		cmp = compareFloats(fi.Redundancy, fc.num)
+	case FileFieldSize:
+		cmp = compareFloats(float64(fi.Filesize), fc.num)
+	case FileFieldModTime:
+		cmp = compareTimes(fi.ModificationTime, fc.time)
+	case FileFieldName:
+		match, _ := path.Match(fc.Value, fi.Name())
+		return match == (fc.Op == FileOpEqual)
+	case FileFieldStuck:
+		return (fi.Stuck == fc.b) == (fc.Op == FileOpEqual)
+	default:
+		return false
+	}
+	switch fc.Op {
+	case FileOpEqual:
+		return cmp == 0
+	case FileOpNotEqual:
+		return cmp != 0
+	case FileOpLess:
+		return cmp < 0
+	case FileOpLessEqual:
+		return cmp <= 0
+	case FileOpGreater:
+		return cmp > 0
+	case FileOpGreaterEqual:
+		return cmp >= 0
+	}
+	return false
+}
+
+// compareFloats returns -1, 0 or 1 depending on whether a is smaller, equal to
+// or greater than b.
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compareTimes returns -1, 0 or 1 depending on whether a is before, equal to
+// or after b.
+func compareTimes(a, b time.Time) int {
+	if a.Before(b) {
+		return -1
+	} else if a.After(b) {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,115 @@
+package modules
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// TestParseFileFilter tests parsing file filters.
+func TestParseFileFilter(t *testing.T) {
+	tests := []struct {
+		filter     string
+		conditions int
+		valid      bool
+	}{
+		{"", 0, true},
+		{"health>0.25", 1, true},
+		{" health >= 0.25 , stuck = false ", 2, true},
+		{"name=*.png,size<100,redundancy!=3", 3, true},
+		{"modtime<2020-01-01T00:00:00Z", 1, true},
+		{"name!=a<b>c", 1, true},
+		{"health", 0, false},
+		{"=1", 0, false},
+		{"unknown=1", 0, false},
+		{"health>high", 0, false},
+		{"stuck>true", 0, false},
+		{"name<a", 0, false},
+		{"name=[", 0, false},
+		{"modtime<yesterday", 0, false},
+	}
+	for _, test := range tests {
+		filter, err := ParseFileFilter(test.filter)
+		if test.valid && err != nil {
+			t.Fatalf("%v: unexpected error: %v", test.filter, err)
+		}
+		if !test.valid && !errors.Contains(err, ErrInvalidFileFilter) {
+			t.Fatalf("%v: expected ErrInvalidFileFilter but got %v", test.filter, err)
+		}
+		if len(filter) != test.conditions {
+			t.Fatalf("%v: expected %v conditions but got %v", test.filter, test.conditions, len(filter))
+		}
+	}
+
+	// The parsed filter can be turned back into a string.
+	filter, err := ParseFileFilter(" health >= 0.25 , stuck = false ")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if filter.String() != "health>=0.25,stuck=false" {
+		t.Fatal("wrong filter string", filter.String())
+	}
+}
+
+// TestFileFilterMatch tests matching files against file filters.
+func TestFileFilterMatch(t *testing.T) {
+	siaPath, err := NewSiaPath("dir/image.png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi := FileInfo{
+		SiaPath:          siaPath,
+		Filesize:         100,
+		Health:           0.5,
+		Redundancy:       2,
+		ModificationTime: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
+	}
+	tests := []struct {
+		filter string
+		match  bool
+	}{
+		{"", true},
+		{"health=0.5", true},
+		{"health!=0.5", false},
+		{"health<0.5", false},
+		{"health<=0.5", true},
+		{"health>0.25,health<0.75", true},
+		{"redundancy>=2", true},
+		{"redundancy>2", false},
+		{"size<100", false},
+		{"size>=100", true},
+		{"modtime>2020-01-01T00:00:00Z", true},
+		{"modtime<2020-01-01T00:00:00Z", false},
+		{"stuck=false", true},
+		{"stuck!=false", false},
+		{"name=*.png", true},
+		{"name=*.jpg", false},
+		{"name!=*.jpg", true},
+		{"name=dir/*", false},
+		{"name=*.png,size<100", false},
+	}
+	for _, test := range tests {
+		filter, err := ParseFileFilter(test.filter)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filter.Match(fi) != test.match {
+			t.Errorf("%v: expected match to be %v", test.filter, test.match)
+		}
+	}
+}
+
+// TestValidateFileSort tests validating the fields files are sorted by.
+func TestValidateFileSort(t *testing.T) {
+	for _, field := range []string{"", FileFieldHealth, FileFieldModTime, FileFieldName, FileFieldRedundancy, FileFieldSize} {
+		if err := ValidateFileSort(field); err != nil {
+			t.Fatal(field, err)
+		}
+	}
+	for _, field := range []string{FileFieldStuck, "unknown"} {
+		if err := ValidateFileSort(field); !errors.Contains(err, ErrInvalidFileSort) {
+			t.Fatal(field, "expected ErrInvalidFileSort but got", err)
+		}
+	}
+}
@@ -15,6 +15,11 @@ const (
 )
 
 const (
+	// MetadataFile is the name of the file that stores the sector metadata of
+	// a storage folder. It is exported to allow for backing up the metadata
+	// without the sectors.
+	MetadataFile = metadataFile
+
 	// logFile is the name of the file that is used for logging in the contract
 	// manager.
 	logFile = "contractmanager.log"
@@ -2,6 +2,7 @@ package contractmanager
 
 import (
 	"encoding/binary"
+	"fmt"
 	"os"
 	"path/filepath"
 	"sort"
@@ -198,3 +199,46 @@ func (cm *ContractManager) savedSettings() savedSettings {
 	})
 	return ss
 }
+
+// StorageFolderPaths returns the paths of the storage folders of the contract
+// manager in the provided persist directory. The contract manager must not be
+// running.
+func StorageFolderPaths(persistDir string) ([]string, error) {
+	var ss savedSettings
+	err := persist.LoadJSON(settingsMetadata, &ss, filepath.Join(persistDir, settingsFile))
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to load contract manager settings")
+	}
+	paths := make([]string, 0, len(ss.StorageFolders))
+	for _, sf := range ss.StorageFolders {
+		paths = append(paths, sf.Path)
+	}
+	return paths, nil
+}
+
+// RelocateStorageFolders changes the paths of the storage folders of the
+// contract manager in the provided persist directory. relocations maps the
+// old paths to the new ones. This allows for restoring the contract manager on
+// a machine with a different storage layout. The contract manager must not be
+// running.
+func RelocateStorageFolders(persistDir string, relocations map[string]string) error {
+	path := filepath.Join(persistDir, settingsFile)
+	var ss savedSettings
+	err := persist.LoadJSON(settingsMetadata, &ss, path)
+	if err != nil {
+		return errors.AddContext(err, "failed to load contract manager settings")
+	}
+	found := make(map[string]struct{})
+	for i, sf := range ss.StorageFolders {
+		if newPath, exists := relocations[sf.Path]; exists {
+			ss.StorageFolders[i].Path = newPath
+			found[sf.Path] = struct{}{}
+		}
+	}
+	for oldPath := range relocations {
+		if _, exists := found[oldPath]; !exists {
+			return fmt.Errorf("storage folder '%v' doesn't exist", oldPath)
+		}
+	}
+	return persist.SaveJSON(settingsMetadata, &ss, path)
+}
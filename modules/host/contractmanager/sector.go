@@ -13,7 +13,7 @@ import (
 
 var (
 	// ErrSectorNotFound is returned when a lookup for a sector fails.
-	ErrSectorNotFound = errors.New("could not find the desired sector")
+	ErrSectorNotFound = modules.ErrSectorNotFound
 
 	// errDiskTrouble is returned when the host is supposed to have enough
 	// storage to hold a new sector but failures that are likely related to the
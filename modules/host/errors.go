@@ -112,8 +112,9 @@ func (ec ErrorInternal) Error() string {
 }
 
 // mangedLogError will take an error and log it to the host, depending on the
-// type of error and whether or not the DEBUG flag has been set.
-func (h *Host) managedLogError(err error) {
+// type of error and whether or not the DEBUG flag has been set. The optional
+// keysAndValues are added to the entry as fields.
+func (h *Host) managedLogError(err error, keysAndValues ...interface{}) {
 	// Determine the type of error and the number of times that this error has
 	// been logged.
 	var num uint64
@@ -146,10 +147,14 @@ func (h *Host) managedLogError(err error) {
 	// the error as a normal logging statement. Otherwise, probabilistically
 	// log the statement. In debugging mode, log all statements.
 	shouldLog := num < logAllLimit || fastrand.Intn(probability+1) == probability
+	log := h.log
+	if len(keysAndValues) > 0 {
+		log = log.WithFields(keysAndValues...)
+	}
 	if shouldLog {
-		h.log.Println(err)
+		log.Println(err)
 	} else {
-		h.log.Debugln(err)
+		log.Debugln(err)
 		return
 	}
 
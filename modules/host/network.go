@@ -285,7 +285,7 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 	// this if desired.
 	err = conn.SetDeadline(time.Now().Add(defaultConnectionDeadline))
 	if err != nil {
-		h.log.Println("WARN: could not set deadline on connection:", err)
+		h.log.WithFields("remoteaddr", conn.RemoteAddr().String()).Println("WARN: could not set deadline on connection:", err)
 		return
 	}
 
@@ -296,21 +296,21 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 	var id types.Specifier
 	if err := encoding.NewDecoder(conn, encoding.DefaultAllocLimit).Decode(&id); err != nil {
 		atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
-		h.log.Debugf("WARN: incoming conn %v was malformed: %v", conn.RemoteAddr(), err)
+		h.log.WithFields("remoteaddr", conn.RemoteAddr().String()).Debugf("WARN: incoming conn %v was malformed: %v", conn.RemoteAddr(), err)
 		return
 	}
 	if id != modules.RPCLoopEnter {
 		// first 8 bytes should be a length prefix of 16
 		if lp := encoding.DecUint64(id[:8]); lp != 16 {
 			atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
-			h.log.Debugf("WARN: incoming conn %v was malformed: invalid length prefix %v", conn.RemoteAddr(), lp)
+			h.log.WithFields("remoteaddr", conn.RemoteAddr().String()).Debugf("WARN: incoming conn %v was malformed: invalid length prefix %v", conn.RemoteAddr(), lp)
 			return
 		}
 		// shift down 8 bytes, then read next 8
 		copy(id[:8], id[8:])
 		if _, err := io.ReadFull(conn, id[8:]); err != nil {
 			atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
-			h.log.Debugf("WARN: incoming conn %v was malformed: %v", conn.RemoteAddr(), err)
+			h.log.WithFields("remoteaddr", conn.RemoteAddr().String()).Debugf("WARN: incoming conn %v was malformed: %v", conn.RemoteAddr(), err)
 			return
 		}
 	}
@@ -333,15 +333,15 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 		atomic.AddUint64(&h.atomicSettingsCalls, 1)
 		err = extendErr("incoming RPCSettings failed: ", h.managedRPCSettings(conn))
 	case rpcSettingsDeprecated:
-		h.log.Debugln("Received deprecated settings call")
+		h.log.WithFields("remoteaddr", conn.RemoteAddr().String()).Debugln("Received deprecated settings call")
 	default:
-		h.log.Debugf("WARN: incoming conn %v requested unknown RPC \"%v\"", conn.RemoteAddr(), id)
+		h.log.WithFields("remoteaddr", conn.RemoteAddr().String()).Debugf("WARN: incoming conn %v requested unknown RPC \"%v\"", conn.RemoteAddr(), id)
 		atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
 	}
 	if err != nil {
 		atomic.AddUint64(&h.atomicErroredCalls, 1)
 		err = extendErr("error with "+conn.RemoteAddr().String()+": ", err)
-		h.managedLogError(err)
+		h.managedLogError(err, "remoteaddr", conn.RemoteAddr().String(), "rpc", id)
 	}
 }
 
@@ -390,7 +390,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 	// this if desired
 	err = stream.SetDeadline(time.Now().Add(defaultConnectionDeadline))
 	if err != nil {
-		h.log.Println("WARN: could not set deadline on stream:", err)
+		h.log.WithFields("remoteaddr", stream.RemoteAddr().String()).Println("WARN: could not set deadline on stream:", err)
 		return
 	}
 
@@ -422,7 +422,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 	case modules.RPCRenewContract:
 		err = h.managedRPCRenewContract(stream)
 	default:
-		h.log.Debugf("WARN: incoming stream %v requested unknown RPC \"%v\"", stream.RemoteAddr().String(), rpcID)
+		h.log.WithFields("remoteaddr", stream.RemoteAddr().String()).Debugf("WARN: incoming stream %v requested unknown RPC \"%v\"", stream.RemoteAddr().String(), rpcID)
 		err = errors.New(fmt.Sprintf("Unrecognized RPC id %v", rpcID))
 		atomic.AddUint64(&h.atomicUnrecognizedCalls, 1)
 	}
@@ -430,7 +430,7 @@ func (h *Host) threadedHandleStream(stream siamux.Stream) {
 	if err != nil {
 		err = errors.Compose(err, modules.RPCWriteError(stream, err))
 		atomic.AddUint64(&h.atomicErroredCalls, 1)
-		h.managedLogError(err)
+		h.managedLogError(err, "remoteaddr", stream.RemoteAddr().String(), "rpc", rpcID)
 	}
 }
 
@@ -1147,6 +1147,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	defer func() {
 		h.managedUnlockStorageObligation(soid)
 	}()
+	log := h.log.WithFields("contractid", soid)
 
 	// Fetch the storage obligation associated with the storage obligation id.
 	var so storageObligation
@@ -1158,7 +1159,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	})
 	h.mu.RUnlock()
 	if err != nil {
-		h.log.Printf("contract %s action: Could not get storage obligation: %s", soid, err)
+		log.Printf("contract %s action: Could not get storage obligation: %s", soid, err)
 		return
 	}
 
@@ -1176,7 +1177,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		// confirmed.
 		err := h.tpool.AcceptTransactionSet(so.OriginTransactionSet)
 		if err != nil {
-			h.log.Debugf("contract %s action: Could not get origin transaction set accepted: %s", soid, err)
+			log.Debugf("contract %s action: Could not get origin transaction set accepted: %s", soid, err)
 
 			// Check if the transaction is invalid with the current consensus set.
 			// If so, the transaction is highly unlikely to ever be confirmed, and
@@ -1188,12 +1189,12 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			// parents are confirmed, might be some difficulty.
 			_, t := err.(modules.ConsensusConflict)
 			if t {
-				h.log.Printf("contract %s action: Consensus conflict on the origin transaction set", so.id())
+				log.Printf("contract %s action: Consensus conflict on the origin transaction set", so.id())
 				h.mu.Lock()
 				err = h.removeStorageObligation(so, obligationRejected)
 				h.mu.Unlock()
 				if err != nil {
-					h.log.Println("Error removing storage obligation:", err)
+					log.Println("Error removing storage obligation:", err)
 				}
 				return
 			}
@@ -1204,7 +1205,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		err = h.queueActionItem(h.blockHeight+resubmissionTimeout, so.id())
 		h.mu.Unlock()
 		if err != nil {
-			h.log.Printf("contract %s action: Error queuing action item: %s", soid, err)
+			log.Printf("contract %s action: Error queuing action item: %s", soid, err)
 		}
 	}
 
@@ -1213,7 +1214,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		// Sanity check - there should be a file contract revision.
 		rtsLen := len(so.RevisionTransactionSet)
 		if rtsLen < 1 || len(so.RevisionTransactionSet[rtsLen-1].FileContractRevisions) != 1 {
-			h.log.Critical("transaction revision marked as unconfirmed, yet there is no transaction revision")
+			log.Critical("transaction revision marked as unconfirmed, yet there is no transaction revision")
 			return
 		}
 
@@ -1225,7 +1226,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			// be confirmed, and the origin transaction may be confirmed, which
 			// would confuse the revenue stuff a bit. Might happen frequently
 			// due to the dynamic fee pool.
-			h.log.Printf("contract %s action: Full time has elapsed, but the revision transaction could not be submitted to consensus", so.id())
+			log.Printf("contract %s action: Full time has elapsed, but the revision transaction could not be submitted to consensus", so.id())
 			h.mu.Lock()
 			h.removeStorageObligation(so, obligationRejected)
 			h.mu.Unlock()
@@ -1237,7 +1238,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		err := h.queueActionItem(blockHeight+resubmissionTimeout, so.id())
 		h.mu.Unlock()
 		if err != nil {
-			h.log.Printf("contract %s action: Error queuing action item: %s", soid, err)
+			log.Printf("contract %s action: Error queuing action item: %s", soid, err)
 		}
 
 		// Add a miner fee to the transaction and submit it to the blockchain.
@@ -1246,7 +1247,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		revisionTxn := so.RevisionTransactionSet[revisionTxnIndex]
 		builder, err := h.wallet.RegisterTransaction(revisionTxn, revisionParents)
 		if err != nil {
-			h.log.Printf("contract %s action: Error registering transaction: %s", soid, err)
+			log.Printf("contract %s action: Error registering transaction: %s", soid, err)
 			return
 		}
 		_, feeRecommendation := h.tpool.FeeEstimation()
@@ -1262,22 +1263,22 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		requiredFee := feeRecommendation.Mul64(txnSize)
 		err = builder.FundSiacoins(requiredFee)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build revision txn: Error funding transaction fees: %s", soid, err)
+			log.Printf("contract %s action: failed to build revision txn: Error funding transaction fees: %s", soid, err)
 			builder.Drop()
 		}
 		builder.AddMinerFee(requiredFee)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build revision txn: Error adding miner fees: %s", soid, err)
+			log.Printf("contract %s action: failed to build revision txn: Error adding miner fees: %s", soid, err)
 			builder.Drop()
 		}
 		feeAddedRevisionTransactionSet, err := builder.Sign(true)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build revision txn: Error signing transaction: %s", soid, err)
+			log.Printf("contract %s action: failed to build revision txn: Error signing transaction: %s", soid, err)
 			builder.Drop()
 		}
 		err = h.tpool.AcceptTransactionSet(feeAddedRevisionTransactionSet)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build revision txn: Error submitting transaction to transaction pool: %s", soid, err)
+			log.Printf("contract %s action: failed to build revision txn: Error submitting transaction to transaction pool: %s", soid, err)
 			builder.Drop()
 		}
 		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
@@ -1287,31 +1288,31 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 	// Check whether a storage proof is ready to be provided, and whether it
 	// has been accepted. Check for death.
 	if !so.ProofConfirmed && blockHeight >= so.expiration()+resubmissionTimeout {
-		h.log.Debugln("Host is attempting a storage proof for", so.id())
+		log.Debugln("Host is attempting a storage proof for", so.id())
 
 		// If the obligation doesn't require a proof, we can remove the
 		// obligation and avoid submitting a storage proof. In that case the
 		// host payout for the contract includes the contract cost and locked
 		// collateral.
 		if !so.requiresProof() {
-			h.log.Debugf("contract %s action: storage proof not required for unrevised contract", so.id())
+			log.Debugf("contract %s action: storage proof not required for unrevised contract", so.id())
 			h.mu.Lock()
 			err := h.removeStorageObligation(so, obligationSucceeded)
 			h.mu.Unlock()
 			if err != nil {
-				h.log.Printf("contract %s action: Error removing storage obligation: %s", soid, err)
+				log.Printf("contract %s action: Error removing storage obligation: %s", soid, err)
 			}
 			return
 		}
 		// If the window has closed, the host has failed and the obligation can
 		// be removed.
 		if so.proofDeadline() < blockHeight {
-			h.log.Debugln("storage proof not confirmed by deadline, id", so.id())
+			log.Debugln("storage proof not confirmed by deadline, id", so.id())
 			h.mu.Lock()
 			err := h.removeStorageObligation(so, obligationFailed)
 			h.mu.Unlock()
 			if err != nil {
-				h.log.Printf("contract %s action: Error removing failed storage obligation: %s", soid, err)
+				log.Printf("contract %s action: Error removing failed storage obligation: %s", soid, err)
 			}
 			return
 		}
@@ -1326,28 +1327,28 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			err := h.queueActionItem(recheckHeight, so.id())
 			h.mu.Unlock()
 			if err != nil {
-				h.log.Printf("contract %s action: Error queuing action item: %s", soid, err)
+				log.Printf("contract %s action: Error queuing action item: %s", soid, err)
 			}
 		}
 
 		// Get the index of the segment for which to build the proof.
 		segmentIndex, err := h.cs.StorageProofSegment(so.id())
 		if err != nil {
-			h.log.Printf("contract %s action: Host got an error when fetching a storage proof segment: %s", soid, err)
+			log.Printf("contract %s action: Host got an error when fetching a storage proof segment: %s", soid, err)
 			return
 		}
 
 		// Build StorageProof.
 		sp, err := h.managedBuildStorageProof(so, segmentIndex)
 		if err != nil {
-			h.log.Printf("contract %s action: Host encountered an error when building the storage proof: %s", soid, err)
+			log.Printf("contract %s action: Host encountered an error when building the storage proof: %s", soid, err)
 			return
 		}
 
 		// Create and build the transaction with the storage proof.
 		builder, err := h.wallet.StartTransaction()
 		if err != nil {
-			h.log.Printf("contract %s action: Failed to start storage proof transaction: %s", soid, err)
+			log.Printf("contract %s action: Failed to start storage proof transaction: %s", soid, err)
 			return
 		}
 		_, feeRecommendation := h.tpool.FeeEstimation()
@@ -1356,13 +1357,13 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		if so.value().Cmp(requiredFee) < 0 {
 			// There's no sense submitting the storage proof if the fee is more
 			// than the anticipated revenue.
-			h.log.Printf("contract %s action: Host not submitting storage proof due to a value that does not sufficiently exceed the fee cost", soid)
+			log.Printf("contract %s action: Host not submitting storage proof due to a value that does not sufficiently exceed the fee cost", soid)
 			builder.Drop()
 			return
 		}
 		err = builder.FundSiacoins(requiredFee)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build storage proof trransaction: Host error when funding a storage proof transaction fee: %s", soid, err)
+			log.Printf("contract %s action: failed to build storage proof trransaction: Host error when funding a storage proof transaction fee: %s", soid, err)
 			builder.Drop()
 			return
 		}
@@ -1370,13 +1371,13 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		builder.AddStorageProof(sp)
 		storageProofSet, err := builder.Sign(true)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build storage proof trransaction: Host error when signing the storage proof transaction: %s", soid, err)
+			log.Printf("contract %s action: failed to build storage proof trransaction: Host error when signing the storage proof transaction: %s", soid, err)
 			builder.Drop()
 			return
 		}
 		err = h.tpool.AcceptTransactionSet(storageProofSet)
 		if err != nil {
-			h.log.Printf("contract %s action: failed to build storage proof trransaction: Host unable to submit storage proof transaction to transaction pool: %s", soid, err)
+			log.Printf("contract %s action: failed to build storage proof trransaction: Host unable to submit storage proof transaction to transaction pool: %s", soid, err)
 			builder.Drop()
 			return
 		}
@@ -1388,7 +1389,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		err = h.queueActionItem(so.proofDeadline(), so.id())
 		h.mu.Unlock()
 		if err != nil {
-			h.log.Printf("contract %s action: Error queuing action item: %s", soid, err)
+			log.Printf("contract %s action: Error queuing action item: %s", soid, err)
 		}
 	}
 
@@ -1401,13 +1402,13 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		return tx.Bucket(bucketStorageObligations).Put(soid[:], soBytes)
 	})
 	if err != nil {
-		h.log.Printf("contract %s action: Error updating the storage obligations: %s", soid, err)
+		log.Printf("contract %s action: Error updating the storage obligations: %s", soid, err)
 	}
 
 	// Check if all items have succeeded with the required confirmations. Report
 	// success, delete the obligation.
 	if so.ProofConfirmed && blockHeight >= so.proofDeadline() {
-		h.log.Println("file contract complete, id", so.id())
+		log.Println("file contract complete, id", so.id())
 		h.mu.Lock()
 		h.removeStorageObligation(so, obligationSucceeded)
 		h.mu.Unlock()
@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/types"
+)
+
+// Network is the definition of a network as it is loaded from a network file.
+// In addition to the consensus parameters it contains the peers that are used
+// to bootstrap the gateway.
+type Network struct {
+	types.Network
+	BootstrapPeers []NetAddress `json:"bootstrappeers"`
+}
+
+// LoadNetwork loads the network file at the given path and replaces the
+// parameters of the current network with it. Parameters that aren't set in
+// the file keep the values of the network selected by the build tags. It has
+// to be called before any modules are created.
+func LoadNetwork(path string) (Network, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return Network{}, errors.AddContext(err, "unable to read network file")
+	}
+	n := Network{
+		Network:        types.CurrentNetwork(),
+		BootstrapPeers: append([]NetAddress(nil), BootstrapPeers...),
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&n); err != nil {
+		return Network{}, errors.AddContext(err, "unable to parse network file")
+	}
+	for _, addr := range n.BootstrapPeers {
+		if err := addr.IsStdValid(); err != nil {
+			return Network{}, errors.AddContext(err, "invalid bootstrap peer "+string(addr))
+		}
+	}
+	if err := types.SetNetwork(n.Network); err != nil {
+		return Network{}, err
+	}
+	BootstrapPeers = n.BootstrapPeers
+	return n, nil
+}
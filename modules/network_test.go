@@ -0,0 +1,57 @@
+package modules
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/types"
+)
+
+// TestLoadNetwork tests loading a network file. It isn't run in parallel since
+// it modifies the network of all tests.
+func TestLoadNetwork(t *testing.T) {
+	dir := build.TempDir("modules", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	orig := types.CurrentNetwork()
+	origPeers := BootstrapPeers
+	defer func() {
+		BootstrapPeers = origPeers
+		if err := types.SetNetwork(orig); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Write a network file and a file with an unknown parameter.
+	path := filepath.Join(dir, "network.json")
+	network := `{"name": "private", "blockfrequency": 30, "bootstrappeers": ["1.2.3.4:9981"]}`
+	if err := ioutil.WriteFile(path, []byte(network), 0600); err != nil {
+		t.Fatal(err)
+	}
+	unknownPath := filepath.Join(dir, "unknown.json")
+	if err := ioutil.WriteFile(unknownPath, []byte(`{"blocktime": 30}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadNetwork(unknownPath); err == nil {
+		t.Fatal("expected unknown parameter to be rejected")
+	}
+
+	// Parameters that aren't set keep their values.
+	n, err := LoadNetwork(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n.Name != "private" || types.BlockFrequency != 30 || types.MaturityDelay != orig.MaturityDelay {
+		t.Fatal("wrong network parameters", n.Name, types.BlockFrequency, types.MaturityDelay)
+	}
+	if len(BootstrapPeers) != 1 || BootstrapPeers[0] != "1.2.3.4:9981" {
+		t.Fatal("wrong bootstrap peers", BootstrapPeers)
+	}
+	if types.GenesisBlock.Timestamp != orig.GenesisTimestamp {
+		t.Fatal("genesis block shouldn't have changed")
+	}
+}
@@ -2,10 +2,15 @@ package modules
 
 import (
 	"context"
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
+	"hash"
 	"io"
+	"math"
 	"os"
+	"path"
+	"strings"
 	"time"
 
 	"gitlab.com/NebulousLabs/errors"
@@ -13,6 +18,7 @@ import (
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/types"
+	"golang.org/x/crypto/blake2b"
 )
 
 type (
@@ -378,6 +384,17 @@ type DirectoryInfo struct {
 	StuckHealth         float64     `json:"stuckhealth"`
 	StuckSize           uint64      `json:"stucksize"`
 	UID                 uint64      `json:"uid"`
+
+	// VersioningPolicy is the versioning policy set on the directory itself.
+	// Directories without a policy inherit the policy of their parent.
+	VersioningPolicy VersioningPolicy `json:"versioningpolicy"`
+
+	// StoragePolicy is the storage policy set on the directory itself.
+	// Directories without a policy inherit the policy of their parent.
+	StoragePolicy StoragePolicy `json:"storagepolicy"`
+
+	// UserMetadata is the application defined metadata of the directory.
+	UserMetadata UserMetadata `json:"usermetadata"`
 }
 
 // Name implements os.FileInfo.
@@ -414,6 +431,7 @@ type DownloadInfo struct {
 	StartTime            time.Time `json:"starttime"`            // The time when the download was started.
 	StartTimeUnix        int64     `json:"starttimeunix"`        // The time when the download was started in unix format.
 	TotalDataTransferred uint64    `json:"totaldatatransferred"` // Total amount of data transferred, including negotiation, etc.
+	TraceID              string    `json:"traceid,omitempty"`    // The ID of the trace of the download at /renter/traces/:id.
 }
 
 // FileUploadParams contains the information used by the Renter to upload a
@@ -434,6 +452,200 @@ type FileUploadParams struct {
 	// to create a CipherKey with the given CipherType. This value override
 	// CipherType if it is set.
 	CipherKey crypto.CipherKey
+
+	// Compression is the algorithm the data is compressed with before it is
+	// encrypted and erasure coded. If it is left blank, the data isn't
+	// compressed.
+	Compression CompressionType
+
+	// Checksum is the hash function used to compute the whole-file checksum
+	// of the uploaded data. If it is left blank, DefaultChecksumType is used.
+	// ChecksumNone disables the checksum.
+	Checksum ChecksumType
+}
+
+// CompressionType is the algorithm used to compress the data of a file.
+type CompressionType string
+
+const (
+	// CompressionNone indicates that the data of a file isn't compressed.
+	CompressionNone CompressionType = ""
+
+	// CompressionGzip indicates that the data of a file is compressed with
+	// gzip.
+	CompressionGzip CompressionType = "gzip"
+)
+
+// ErrUnknownCompression is returned for compression types that aren't
+// supported.
+var ErrUnknownCompression = errors.New("unknown compression type")
+
+// NewCompressionType parses a compression type. "none" and the empty string
+// disable compression.
+func NewCompressionType(s string) (CompressionType, error) {
+	switch ct := CompressionType(strings.ToLower(s)); ct {
+	case CompressionNone, "none":
+		return CompressionNone, nil
+	case CompressionGzip:
+		return ct, nil
+	default:
+		return CompressionNone, errors.AddContext(ErrUnknownCompression, s)
+	}
+}
+
+// ChecksumType is the hash function used to compute the whole-file checksum of
+// a file.
+type ChecksumType string
+
+const (
+	// ChecksumNone indicates that no checksum is computed for a file.
+	ChecksumNone ChecksumType = "none"
+
+	// ChecksumBLAKE2b indicates that the checksum of a file is computed with
+	// BLAKE2b-256.
+	ChecksumBLAKE2b ChecksumType = "blake2b"
+
+	// ChecksumSHA256 indicates that the checksum of a file is computed with
+	// SHA-256.
+	ChecksumSHA256 ChecksumType = "sha256"
+
+	// DefaultChecksumType is the checksum type of uploads that don't specify
+	// one.
+	DefaultChecksumType = ChecksumBLAKE2b
+)
+
+// ErrUnknownChecksum is returned for checksum types that aren't supported.
+var ErrUnknownChecksum = errors.New("unknown checksum type")
+
+// NewChecksumType parses a checksum type. The empty string selects the
+// default checksum type.
+func NewChecksumType(s string) (ChecksumType, error) {
+	switch ct := ChecksumType(strings.ToLower(s)); ct {
+	case "":
+		return DefaultChecksumType, nil
+	case ChecksumNone, ChecksumBLAKE2b, ChecksumSHA256:
+		return ct, nil
+	default:
+		return ChecksumNone, errors.AddContext(ErrUnknownChecksum, s)
+	}
+}
+
+// NewHash returns a new hash.Hash computing a checksum of the type.
+func (ct ChecksumType) NewHash() (hash.Hash, error) {
+	switch ct {
+	case ChecksumBLAKE2b:
+		return blake2b.New256(nil)
+	case ChecksumSHA256:
+		return sha256.New(), nil
+	default:
+		return nil, errors.AddContext(ErrUnknownChecksum, string(ct))
+	}
+}
+
+// FileVerification is the outcome of verifying the data of a file on the
+// network against its checksum.
+type FileVerification struct {
+	SiaPath        SiaPath      `json:"siapath"`
+	ChecksumType   ChecksumType `json:"checksumtype"`
+	Checksum       string       `json:"checksum"`
+	ActualChecksum string       `json:"actualchecksum"`
+	Verified       bool         `json:"verified"`
+	VerifiedTime   time.Time    `json:"verifiedtime"`
+}
+
+// SyncActionType is the kind of change a sync makes to a remote file.
+type SyncActionType string
+
+const (
+	// SyncActionUpload uploads a local file that doesn't exist remotely.
+	SyncActionUpload SyncActionType = "upload"
+
+	// SyncActionUpdate replaces a remote file with the changed local file.
+	SyncActionUpdate SyncActionType = "update"
+
+	// SyncActionDelete deletes a remote file that doesn't exist locally.
+	SyncActionDelete SyncActionType = "delete"
+)
+
+// SyncParams describes the synchronization of a local directory to a
+// siapath. Include and Exclude are globs which are matched against the path
+// of a file relative to the synced directory as well as against its name.
+type SyncParams struct {
+	LocalPath string   `json:"localpath"`
+	SiaPath   SiaPath  `json:"siapath"`
+	Delete    bool     `json:"delete"`
+	Include   []string `json:"include,omitempty"`
+	Exclude   []string `json:"exclude,omitempty"`
+}
+
+// SyncAction is a single change a sync makes to a remote file.
+type SyncAction struct {
+	Type      SyncActionType `json:"type"`
+	LocalPath string         `json:"localpath,omitempty"`
+	SiaPath   SiaPath        `json:"siapath"`
+	Size      uint64         `json:"size"`
+	Done      bool           `json:"done"`
+	Error     string         `json:"error,omitempty"`
+}
+
+// SyncRun is a synchronization of a local directory to a siapath together
+// with the actions it consists of. Runs without an ID are dry runs which only
+// contain the plan of a sync.
+type SyncRun struct {
+	ID        string       `json:"id,omitempty"`
+	Params    SyncParams   `json:"params"`
+	Actions   []SyncAction `json:"actions"`
+	StartTime time.Time    `json:"starttime"`
+	EndTime   time.Time    `json:"endtime"`
+	Completed bool         `json:"completed"`
+	Error     string       `json:"error,omitempty"`
+}
+
+// ValidateGlobs checks that the include and exclude globs of the params are
+// valid.
+func (sp SyncParams) ValidateGlobs() error {
+	for _, glob := range append(append([]string{}, sp.Include...), sp.Exclude...) {
+		if _, err := path.Match(glob, ""); err != nil {
+			return errors.AddContext(err, fmt.Sprintf("invalid glob '%v'", glob))
+		}
+	}
+	return nil
+}
+
+// Matches returns true if a file at the given slash separated path relative
+// to the synced directory is included in the sync. Files need to match one of
+// the include globs, if there are any, and neither the file nor one of its
+// directories may match one of the exclude globs.
+func (sp SyncParams) Matches(relPath string) bool {
+	if len(sp.Include) > 0 && !matchSyncGlobs(sp.Include, relPath) {
+		return false
+	}
+	for p := relPath; p != "."; p = path.Dir(p) {
+		if sp.Excludes(p) {
+			return false
+		}
+	}
+	return true
+}
+
+// Excludes returns true if a file or directory at the given slash separated
+// path relative to the synced directory matches one of the exclude globs.
+func (sp SyncParams) Excludes(relPath string) bool {
+	return matchSyncGlobs(sp.Exclude, relPath)
+}
+
+// matchSyncGlobs returns true if the path or its last element matches one of
+// the globs.
+func matchSyncGlobs(globs []string, relPath string) bool {
+	for _, glob := range globs {
+		if match, _ := path.Match(glob, relPath); match {
+			return true
+		}
+		if match, _ := path.Match(glob, path.Base(relPath)); match {
+			return true
+		}
+	}
+	return false
 }
 
 // FileInfo provides information about a file.
@@ -441,7 +653,11 @@ type FileInfo struct {
 	AccessTime       time.Time         `json:"accesstime"`
 	Available        bool              `json:"available"`
 	ChangeTime       time.Time         `json:"changetime"`
+	Checksum         string            `json:"checksum"`
+	ChecksumType     ChecksumType      `json:"checksumtype"`
 	CipherType       string            `json:"ciphertype"`
+	CompressedSize   uint64            `json:"compressedsize"`
+	Compression      CompressionType   `json:"compression"`
 	CreateTime       time.Time         `json:"createtime"`
 	Expiration       types.BlockHeight `json:"expiration"`
 	Filesize         uint64            `json:"filesize"`
@@ -451,6 +667,7 @@ type FileInfo struct {
 	MaxHealthPercent float64           `json:"maxhealthpercent"`
 	ModificationTime time.Time         `json:"modtime,siamismatch"` // Stays as 'modtime' in json for compatibility
 	FileMode         os.FileMode       `json:"mode,siamismatch"`    // Field is called FileMode for fuse compatibility
+	LastVerified     time.Time         `json:"lastverified"`
 	NumStuckChunks   uint64            `json:"numstuckchunks"`
 	OnDisk           bool              `json:"ondisk"`
 	Recoverable      bool              `json:"recoverable"`
@@ -465,6 +682,8 @@ type FileInfo struct {
 	UID              uint64            `json:"uid"`
 	UploadedBytes    uint64            `json:"uploadedbytes"`
 	UploadProgress   float64           `json:"uploadprogress"`
+	UserMetadata     UserMetadata      `json:"usermetadata"`
+	VerifyFailed     bool              `json:"verifyfailed"`
 }
 
 // Name implements os.FileInfo.
@@ -508,6 +727,19 @@ type HostDBEntry struct {
 
 	LastHistoricUpdate types.BlockHeight `json:"lasthistoricupdate"`
 
+	// Measurements that are taken whenever the renter audits the host by
+	// requesting a random segment of one of the sectors it is storing for us.
+	HistoricFailedAudits     float64   `json:"historicfailedaudits"`
+	HistoricSuccessfulAudits float64   `json:"historicsuccessfulaudits"`
+	LastAuditTime            time.Time `json:"lastaudittime"`
+
+	// Performance measurements reported by the renter's workers. Latency is
+	// the time it takes the host to answer a small request and Throughput is
+	// measured in bytes per second.
+	Latency           time.Duration `json:"latency"`
+	Throughput        float64       `json:"throughput"`
+	LastLatencyUpdate time.Time     `json:"lastlatencyupdate"`
+
 	// Measurements related to the IP subnet mask.
 	IPNets          []string  `json:"ipnets"`
 	LastIPNetChange time.Time `json:"lastipnetchange"`
@@ -540,12 +772,16 @@ type HostScoreBreakdown struct {
 
 	AcceptContractAdjustment   float64 `json:"acceptcontractadjustment"`
 	AgeAdjustment              float64 `json:"ageadjustment"`
+	AuditAdjustment            float64 `json:"auditadjustment"`
 	BasePriceAdjustment        float64 `json:"basepriceadjustment"`
 	BurnAdjustment             float64 `json:"burnadjustment"`
 	CollateralAdjustment       float64 `json:"collateraladjustment"`
+	CustomAdjustment           float64 `json:"customadjustment"`
 	DurationAdjustment         float64 `json:"durationadjustment"`
 	InteractionAdjustment      float64 `json:"interactionadjustment"`
+	LatencyAdjustment          float64 `json:"latencyadjustment"`
 	PriceAdjustment            float64 `json:"pricesmultiplier,siamismatch"`
+	RegionAdjustment           float64 `json:"regionadjustment"`
 	StorageRemainingAdjustment float64 `json:"storageremainingadjustment"`
 	UptimeAdjustment           float64 `json:"uptimeadjustment"`
 	VersionAdjustment          float64 `json:"versionadjustment"`
@@ -562,6 +798,18 @@ type MemoryStatus struct {
 	System       MemoryManagerStatus `json:"system"`
 }
 
+// RepairStatus contains information about the state of the renter's repair
+// loop.
+type RepairStatus struct {
+	DirectoryHeapSize int `json:"directoryheapsize"`
+	UploadHeapSize    int `json:"uploadheapsize"`
+
+	NumStuckChunks uint64  `json:"numstuckchunks"`
+	RepairSize     uint64  `json:"repairsize"`
+	StuckSize      uint64  `json:"stucksize"`
+	Health         float64 `json:"health"`
+}
+
 // MemoryManagerStatus contains the memory status of a single memory manager.
 type MemoryManagerStatus struct {
 	Available uint64 `json:"available"`
@@ -614,11 +862,373 @@ type RenterPriceEstimation struct {
 
 // RenterSettings control the behavior of the Renter.
 type RenterSettings struct {
-	Allowance        Allowance     `json:"allowance"`
-	IPViolationCheck bool          `json:"ipviolationcheck"`
-	MaxUploadSpeed   int64         `json:"maxuploadspeed"`
-	MaxDownloadSpeed int64         `json:"maxdownloadspeed"`
-	UploadsStatus    UploadsStatus `json:"uploadsstatus"`
+	Allowance           Allowance           `json:"allowance"`
+	HostLatencyWeight   float64             `json:"hostlatencyweight"`
+	HostSelectionPolicy HostSelectionPolicy `json:"hostselectionpolicy"`
+	IPViolationCheck    bool                `json:"ipviolationcheck"`
+	MaxUploadSpeed      int64               `json:"maxuploadspeed"`
+	MaxDownloadSpeed    int64               `json:"maxdownloadspeed"`
+	UploadsStatus       UploadsStatus       `json:"uploadsstatus"`
+}
+
+// HostSelectionPolicy contains the constraints the hostdb applies when
+// selecting hosts for new contracts on top of the IP subnet check. Hosts are
+// located using a local GeoIP database file. A region refers to a country,
+// identified by its ISO 3166-1 alpha-2 code.
+type HostSelectionPolicy struct {
+	// GeoIPDatabase is the path to the GeoIP database file. It is either the
+	// ip2asn-combined.tsv database published by iptoasn.com or a custom file
+	// whose lines contain a network in CIDR notation, a country code and an
+	// AS number, separated by commas.
+	GeoIPDatabase string `json:"geoipdatabase"`
+
+	// MaxHostsPerASN and MaxHostsPerCountry limit the number of hosts the
+	// renter has contracts with within the same autonomous system or country.
+	// A value of 0 disables the limit.
+	MaxHostsPerASN     int `json:"maxhostsperasn"`
+	MaxHostsPerCountry int `json:"maxhostspercountry"`
+
+	// MinRegions is the minimum number of distinct regions the renter's hosts
+	// should be spread across.
+	MinRegions int `json:"minregions"`
+
+	// AllowedRegions restricts host selection to hosts within the listed
+	// regions. Hosts that can't be located are not selected if set.
+	AllowedRegions []string `json:"allowedregions"`
+
+	// PreferredRegions are regions whose hosts have their score multiplied by
+	// PreferredRegionWeight.
+	PreferredRegions      []string `json:"preferredregions"`
+	PreferredRegionWeight float64  `json:"preferredregionweight"`
+}
+
+// Active returns true if any of the policy's constraints are set.
+func (p HostSelectionPolicy) Active() bool {
+	return p.MaxHostsPerASN > 0 || p.MaxHostsPerCountry > 0 || p.MinRegions > 0 ||
+		len(p.AllowedRegions) > 0 || len(p.PreferredRegions) > 0
+}
+
+// Validate checks the policy for invalid values.
+func (p HostSelectionPolicy) Validate() error {
+	if p.MaxHostsPerASN < 0 || p.MaxHostsPerCountry < 0 || p.MinRegions < 0 {
+		return errors.New("host selection limits cannot be negative")
+	}
+	if p.PreferredRegionWeight < 0 {
+		return errors.New("preferred region weight cannot be negative")
+	}
+	if p.Active() && p.GeoIPDatabase == "" {
+		return errors.New("host selection policy requires a GeoIP database")
+	}
+	for _, region := range append(append([]string{}, p.AllowedRegions...), p.PreferredRegions...) {
+		if len(region) != 2 {
+			return fmt.Errorf("invalid region code '%v', expected ISO 3166-1 alpha-2 code", region)
+		}
+	}
+	return nil
+}
+
+// VersioningPolicy determines how many prior versions of the files within a
+// directory are kept when they are overwritten or deleted. Versioning is
+// disabled if neither of the limits is set.
+type VersioningPolicy struct {
+	// MaxVersions is the maximum number of prior versions kept per file. A
+	// value of 0 means that the number of versions is not limited.
+	MaxVersions uint64 `json:"maxversions"`
+
+	// MaxAge is the duration after which a prior version is removed. A value
+	// of 0 means that versions don't expire.
+	MaxAge time.Duration `json:"maxage"`
+}
+
+// Enabled returns true if the policy keeps prior versions of files.
+func (vp VersioningPolicy) Enabled() bool {
+	return vp.MaxVersions > 0 || vp.MaxAge > 0
+}
+
+// StoragePolicy determines how the files within a directory are stored and
+// repaired. New uploads without explicit erasure coding parameters inherit the
+// policy's parameters and the repair loop enforces the repair threshold,
+// priority and host constraints of the policy for all files within the
+// directory. Fields with a zero value fall back to the renter's defaults.
+type StoragePolicy struct {
+	// DataPieces and ParityPieces are the erasure coding parameters of new
+	// uploads.
+	DataPieces   int `json:"datapieces"`
+	ParityPieces int `json:"paritypieces"`
+
+	// CipherType is the name of the cipher used to encrypt new uploads, e.g.
+	// "threefish512".
+	CipherType string `json:"ciphertype"`
+
+	// RepairThreshold is the health at which the files within the directory
+	// are repaired. A lower threshold keeps the files at a higher redundancy.
+	RepairThreshold float64 `json:"repairthreshold"`
+
+	// RepairPriority orders the repairs of chunks from different directories.
+	// Chunks with a higher priority are repaired first.
+	RepairPriority int `json:"repairpriority"`
+
+	// AllowedHosts restricts the hosts that store the files within the
+	// directory. Pieces on other hosts don't count towards the redundancy of
+	// a file and are moved to allowed hosts by the repair loop.
+	AllowedHosts []types.SiaPublicKey `json:"allowedhosts"`
+}
+
+// Active returns true if any of the policy's fields are set.
+func (sp StoragePolicy) Active() bool {
+	return sp.DataPieces > 0 || sp.ParityPieces > 0 || sp.CipherType != "" ||
+		sp.RepairThreshold > 0 || sp.RepairPriority != 0 || len(sp.AllowedHosts) > 0
+}
+
+// Validate checks the policy for invalid values.
+func (sp StoragePolicy) Validate() error {
+	if sp.DataPieces < 0 || sp.ParityPieces < 0 {
+		return errors.New("number of pieces cannot be negative")
+	}
+	if (sp.DataPieces == 0) != (sp.ParityPieces == 0) {
+		return errors.New("data pieces and parity pieces need to be set together")
+	}
+	if _, err := sp.ErasureCode(); err != nil {
+		return errors.AddContext(err, "invalid erasure coding parameters")
+	}
+	if sp.CipherType != "" {
+		var ct crypto.CipherType
+		if err := ct.FromString(sp.CipherType); err != nil {
+			return err
+		}
+	}
+	if sp.RepairThreshold < 0 || sp.RepairThreshold >= 1 {
+		return errors.New("repair threshold needs to be between 0 and 1")
+	}
+	if len(sp.AllowedHosts) > 0 && len(sp.AllowedHosts) < sp.DataPieces+sp.ParityPieces {
+		return fmt.Errorf("policy requires %v pieces but only allows %v hosts", sp.DataPieces+sp.ParityPieces, len(sp.AllowedHosts))
+	}
+	return nil
+}
+
+// ErasureCode returns the erasure coder of the policy or nil if the policy
+// doesn't specify erasure coding parameters.
+func (sp StoragePolicy) ErasureCode() (ErasureCoder, error) {
+	if sp.DataPieces == 0 {
+		return nil, nil
+	}
+	return NewRSSubCode(sp.DataPieces, sp.ParityPieces, crypto.SegmentSize)
+}
+
+// Threshold returns the health at which the files governed by the policy are
+// repaired.
+func (sp StoragePolicy) Threshold() float64 {
+	if sp.RepairThreshold == 0 {
+		return RepairThreshold
+	}
+	return sp.RepairThreshold
+}
+
+// UserMetadata is application defined key/value metadata attached to a file or
+// directory, e.g. the content type of a file or the retention class of a
+// directory. Its entries are also used as tags to filter files by.
+type UserMetadata map[string]string
+
+// MaxUserMetadataSize is the maximum combined size of the keys and values of
+// the user metadata of a file or directory.
+const MaxUserMetadataSize = 4096
+
+var (
+	// ErrInvalidUserMetadataKey is returned for user metadata keys that are
+	// empty or contain a '='.
+	ErrInvalidUserMetadataKey = errors.New("user metadata keys can't be empty or contain '='")
+
+	// ErrUserMetadataTooLarge is returned if the user metadata of a file or
+	// directory exceeds MaxUserMetadataSize.
+	ErrUserMetadataTooLarge = fmt.Errorf("user metadata can't exceed %v bytes", MaxUserMetadataSize)
+)
+
+// NewUserMetadataTags parses tags of the form "key" or "key=value". A tag
+// without a value matches all files with the key.
+func NewUserMetadataTags(tags []string) (UserMetadata, error) {
+	um := make(UserMetadata, len(tags))
+	for _, tag := range tags {
+		kv := strings.SplitN(tag, "=", 2)
+		if kv[0] == "" {
+			return nil, errors.AddContext(ErrInvalidUserMetadataKey, tag)
+		}
+		um[kv[0]] = ""
+		if len(kv) == 2 {
+			um[kv[0]] = kv[1]
+		}
+	}
+	return um, nil
+}
+
+// Copy returns a deep copy of the user metadata.
+func (um UserMetadata) Copy() UserMetadata {
+	if um == nil {
+		return nil
+	}
+	c := make(UserMetadata, len(um))
+	for k, v := range um {
+		c[k] = v
+	}
+	return c
+}
+
+// HasTags returns true if the user metadata contains all of the tags. Tags
+// with an empty value only require the key to be present.
+func (um UserMetadata) HasTags(tags UserMetadata) bool {
+	for k, v := range tags {
+		value, exists := um[k]
+		if !exists || (v != "" && v != value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Update returns a copy of the user metadata with the entries of update
+// applied. Entries with an empty value remove the key.
+func (um UserMetadata) Update(update UserMetadata) UserMetadata {
+	updated := um.Copy()
+	for k, v := range update {
+		if v == "" {
+			delete(updated, k)
+			continue
+		}
+		if updated == nil {
+			updated = make(UserMetadata)
+		}
+		updated[k] = v
+	}
+	if len(updated) == 0 {
+		return nil
+	}
+	return updated
+}
+
+// Validate checks the keys and the size of the user metadata.
+func (um UserMetadata) Validate() error {
+	var size int
+	for k, v := range um {
+		if k == "" || strings.Contains(k, "=") {
+			return errors.AddContext(ErrInvalidUserMetadataKey, k)
+		}
+		size += len(k) + len(v)
+	}
+	if size > MaxUserMetadataSize {
+		return ErrUserMetadataTooLarge
+	}
+	return nil
+}
+
+// FileVersion describes a prior version of a file.
+type FileVersion struct {
+	// ID uniquely identifies the version of the file.
+	ID string `json:"id"`
+
+	// ArchivedTime is the time at which the version was overwritten or
+	// deleted.
+	ArchivedTime time.Time `json:"archivedtime"`
+
+	Filesize   uint64  `json:"filesize"`
+	Health     float64 `json:"health"`
+	Redundancy float64 `json:"redundancy"`
+}
+
+// Tenant is a namespace of the renter with its own siapath root and quotas.
+// API tokens of a tenant can only access the files within the namespace. A
+// quota of 0 means that the tenant is unlimited.
+type Tenant struct {
+	Name string `json:"name"`
+
+	// StorageQuota is the maximum combined size of the tenant's files,
+	// including prior versions of the files kept by a versioning policy.
+	StorageQuota uint64 `json:"storagequota"`
+
+	// UploadQuota and DownloadQuota are the maximum number of bytes the
+	// tenant may upload and download until its usage is reset.
+	UploadQuota   uint64 `json:"uploadquota"`
+	DownloadQuota uint64 `json:"downloadquota"`
+}
+
+// TenantUsage is the usage a tenant's quotas are enforced against.
+type TenantUsage struct {
+	Storage    uint64 `json:"storage"`
+	Uploaded   uint64 `json:"uploaded"`
+	Downloaded uint64 `json:"downloaded"`
+}
+
+// TenantInfo contains a tenant, the root of its namespace and its usage.
+type TenantInfo struct {
+	Tenant
+	Root  SiaPath     `json:"root"`
+	Usage TenantUsage `json:"usage"`
+}
+
+// Root returns the root of the tenant's namespace.
+func (t Tenant) Root() (SiaPath, error) {
+	return TenantsFolder.Join(t.Name)
+}
+
+// Validate checks that the name of the tenant is a valid name for a directory.
+func (t Tenant) Validate() error {
+	if t.Name == "" {
+		return errors.New("tenant name can't be empty")
+	}
+	if strings.Contains(t.Name, "/") {
+		return errors.New("tenant name can't contain '/'")
+	}
+	_, err := t.Root()
+	return errors.AddContext(err, "invalid tenant name")
+}
+
+// HostScoringRules are user defined rules that adjust the scores of the hosts
+// in the hostdb. The rules are applied in order and the multipliers of all
+// matching rules are combined.
+type HostScoringRules struct {
+	Rules []HostScoringRule `json:"rules"`
+}
+
+// HostScoringRule matches hosts by comparing a field of the host to a value.
+// Matching hosts either have their score multiplied by Multiplier or, if
+// Reject is set, are effectively excluded from host selection.
+//
+// Field is the json name of a field of the host's external settings, e.g.
+// "storageprice" or "maxduration", or one of the measured fields "age",
+// "latency", "throughput" and "uptime". Value is parsed according to the type
+// of the field, e.g. "100SC" for currencies, "10s" for durations and "0.95"
+// for the uptime ratio.
+type HostScoringRule struct {
+	Field      string  `json:"field"`
+	Comparator string  `json:"comparator"`
+	Value      string  `json:"value"`
+	Multiplier float64 `json:"multiplier"`
+	Reject     bool    `json:"reject"`
+}
+
+// HostScorePreview contains the score of a host under the current scoring
+// rules next to the score breakdown it would have under a different set of
+// rules.
+type HostScorePreview struct {
+	PublicKey      types.SiaPublicKey `json:"publickey"`
+	NetAddress     NetAddress         `json:"netaddress"`
+	CurrentScore   types.Currency     `json:"currentscore"`
+	ScoreBreakdown HostScoreBreakdown `json:"scorebreakdown"`
+}
+
+// Validate checks the rule for invalid comparators and multipliers. Whether
+// the field and value are valid is checked by the hostdb.
+func (r HostScoringRule) Validate() error {
+	switch r.Comparator {
+	case "<", "<=", "==", "!=", ">=", ">":
+	default:
+		return fmt.Errorf("invalid comparator '%v'", r.Comparator)
+	}
+	if r.Field == "" {
+		return errors.New("scoring rule is missing a field")
+	}
+	if !r.Reject && (r.Multiplier <= 0 || math.IsInf(r.Multiplier, 0) || math.IsNaN(r.Multiplier)) {
+		return errors.New("scoring rule multiplier must be a positive number")
+	}
+	return nil
 }
 
 // UploadsStatus contains information about the Renter's Uploads
@@ -924,6 +1534,21 @@ type (
 
 		// UpdateRegistry Job information
 		UpdateRegistryJobsStatus WorkerUpdateRegistryJobStatus `json:"updateregistryjobsstatus"`
+
+		// Audit information
+		AuditStatus WorkerAuditStatus `json:"auditstatus"`
+	}
+
+	// WorkerAuditStatus contains detailed information about the audits the
+	// worker performed on its host.
+	WorkerAuditStatus struct {
+		FailedAudits     uint64 `json:"failedaudits"`
+		SuccessfulAudits uint64 `json:"successfulaudits"`
+
+		LastAuditTime time.Time `json:"lastaudittime"`
+
+		RecentErr     string    `json:"recenterr"`
+		RecentErrTime time.Time `json:"recenterrtime"`
 	}
 
 	// WorkerGenericJobsStatus contains the common information for worker jobs.
@@ -1056,6 +1681,9 @@ type Renter interface {
 	// MemoryStatus returns the current status of the memory manager
 	MemoryStatus() (MemoryStatus, error)
 
+	// RepairStatus returns the current status of the repair loop.
+	RepairStatus() (RepairStatus, error)
+
 	// Mount mounts a FUSE filesystem at mountPoint, making the contents of sp
 	// available via the local filesystem.
 	Mount(mountPoint string, sp SiaPath, opts MountOptions) error
@@ -1086,6 +1714,10 @@ type Renter interface {
 	// SetFileStuck sets the 'stuck' status of a file.
 	SetFileStuck(siaPath SiaPath, stuck bool) error
 
+	// SetFileUserMetadata updates the user metadata of a file. Entries with
+	// an empty value remove the key.
+	SetFileUserMetadata(siaPath SiaPath, update UserMetadata) error
+
 	// UploadBackup uploads a backup to hosts, such that it can be retrieved
 	// using only the seed.
 	UploadBackup(src string, name string) error
@@ -1133,6 +1765,11 @@ type Renter interface {
 	// should be returned or not.
 	FileList(siaPath SiaPath, recursive, cached bool, flf FileListFunc) error
 
+	// QueryFiles returns the files within a directory that match the query in
+	// the query's order, together with the cursor of the next page if the
+	// query has a limit and there are more matching files.
+	QueryFiles(siaPath SiaPath, recursive, cached bool, q FileQuery) ([]FileInfo, string, error)
+
 	// FileHosts returns a list of hosts that are storing the file data.
 	FileHosts(SiaPath) ([]HostDBEntry, error)
 
@@ -1142,9 +1779,50 @@ type Renter interface {
 	// SetFilterMode sets the renter's hostdb filter mode
 	SetFilterMode(fm FilterMode, hosts []types.SiaPublicKey, netAddresses []string) error
 
+	// HostScoringRules returns the user defined rules that adjust the scores
+	// of the renter's hostdb.
+	HostScoringRules() (HostScoringRules, error)
+
+	// SetHostScoringRules replaces the user defined rules that adjust the
+	// scores of the renter's hostdb.
+	SetHostScoringRules(HostScoringRules) error
+
+	// PreviewHostScoringRules returns the score breakdowns the active hosts
+	// would have if the given scoring rules were applied.
+	PreviewHostScoringRules(HostScoringRules) ([]HostScorePreview, error)
+
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool, error)
 
+	// VerifyFile streams a file from the network and checks its data against
+	// its checksum.
+	VerifyFile(siaPath SiaPath) (FileVerification, error)
+
+	// VerifyDir verifies all files with a checksum within a directory and its
+	// subdirectories in the background.
+	VerifyDir(siaPath SiaPath) error
+
+	// FileVersions returns the prior versions of a file, newest first.
+	FileVersions(siaPath SiaPath) ([]FileVersion, error)
+
+	// RestoreFileVersion restores a prior version of a file. The current
+	// version of the file is kept as a prior version.
+	RestoreFileVersion(siaPath SiaPath, id string) error
+
+	// SetVersioningPolicy sets the versioning policy of a directory.
+	SetVersioningPolicy(siaPath SiaPath, policy VersioningPolicy) error
+
+	// SetStoragePolicy sets the storage policy of a directory.
+	SetStoragePolicy(siaPath SiaPath, policy StoragePolicy) error
+
+	// SetDirUserMetadata updates the user metadata of a directory. Entries
+	// with an empty value remove the key.
+	SetDirUserMetadata(siaPath SiaPath, update UserMetadata) error
+
+	// StoragePolicy returns the storage policy that applies to the files
+	// within a directory.
+	StoragePolicy(siaPath SiaPath) (StoragePolicy, error)
+
 	// InitialScanComplete returns a boolean indicating if the initial scan of the
 	// hostdb is completed.
 	InitialScanComplete() (bool, error)
@@ -1156,6 +1834,15 @@ type Renter interface {
 	// RenameFile changes the path of a file.
 	RenameFile(siaPath, newSiaPath SiaPath) error
 
+	// CopyFile creates a copy of a file which shares the sectors and keys of
+	// the original.
+	CopyFile(siaPath, newSiaPath SiaPath) error
+
+	// ReencodeFile re-uploads a file with new erasure coding and cipher
+	// parameters in the background. Parameters that aren't set keep the
+	// value of the file.
+	ReencodeFile(siaPath SiaPath, ec ErasureCoder, cipherType crypto.CipherType) error
+
 	// RenameDir changes the path of a dir.
 	RenameDir(oldPath, newPath SiaPath) error
 
@@ -1199,6 +1886,33 @@ type Renter interface {
 	// resource.
 	Streamer(siapath SiaPath, disableLocalFetch bool) (string, Streamer, error)
 
+	// Sync synchronizes a local directory to a siapath in the background.
+	// The run is persisted and resumed after a restart.
+	Sync(params SyncParams) (SyncRun, error)
+
+	// SyncPlan returns the actions a sync with the given params would take
+	// without taking them.
+	SyncPlan(params SyncParams) ([]SyncAction, error)
+
+	// SyncRuns returns the recent and ongoing syncs.
+	SyncRuns() ([]SyncRun, error)
+
+	// SetTenant creates a tenant or updates the quotas of an existing one.
+	SetTenant(tenant Tenant) error
+
+	// RemoveTenant removes a tenant. The files within its namespace are
+	// kept.
+	RemoveTenant(name string) error
+
+	// ResetTenantUsage resets the uploaded and downloaded bytes of a tenant.
+	ResetTenantUsage(name string) error
+
+	// Tenant returns the tenant with the given name.
+	Tenant(name string) (TenantInfo, error)
+
+	// Tenants returns all tenants sorted by name.
+	Tenants() ([]TenantInfo, error)
+
 	// Upload uploads a file using the input parameters.
 	Upload(FileUploadParams) error
 
@@ -1215,6 +1929,22 @@ type Renter interface {
 	// DirList lists the directories in a siadir
 	DirList(siaPath SiaPath) ([]DirectoryInfo, error)
 
+	// Trace returns a recent trace of a renter operation including its spans.
+	Trace(id string) (RenterTrace, error)
+
+	// Traces returns the recent traces of renter operations without their
+	// spans, most recent first.
+	Traces() []RenterTrace
+
+	// TraceExportDestination returns the file or OTLP collector URL the
+	// finished spans are exported to.
+	TraceExportDestination() string
+
+	// SetTraceExportDestination sets the file or OTLP collector URL the
+	// finished spans are exported to. An empty destination disables the
+	// export.
+	SetTraceExportDestination(destination string) error
+
 	// WorkerPoolStatus returns the current status of the Renter's worker pool
 	WorkerPoolStatus() (WorkerPoolStatus, error)
 
@@ -1305,6 +2035,10 @@ type HostDB interface {
 	// order of preference.
 	AllHosts() ([]HostDBEntry, error)
 
+	// CheckForPolicyViolations accepts a number of host public keys and
+	// returns the ones that violate the host selection policy.
+	CheckForPolicyViolations([]types.SiaPublicKey) ([]types.SiaPublicKey, error)
+
 	// CheckForIPViolations accepts a number of host public keys and returns the
 	// ones that violate the rules of the addressFilter.
 	CheckForIPViolations([]types.SiaPublicKey) ([]types.SiaPublicKey, error)
@@ -1333,10 +2067,22 @@ type HostDB interface {
 	// a host for a given key
 	IncrementFailedInteractions(types.SiaPublicKey) error
 
+	// IncrementSuccessfulAudits increments the number of successful audits of
+	// a host for a given key
+	IncrementSuccessfulAudits(types.SiaPublicKey) error
+
+	// IncrementFailedAudits increments the number of failed audits of a host
+	// for a given key
+	IncrementFailedAudits(types.SiaPublicKey) error
+
 	// initialScanComplete returns a boolean indicating if the initial scan of the
 	// hostdb is completed.
 	InitialScanComplete() (bool, error)
 
+	// LatencyWeight returns the weight of the latency adjustment in the host
+	// scores.
+	LatencyWeight() (float64, error)
+
 	// IPViolationsCheck returns a boolean indicating if the IP violation check is
 	// enabled or not.
 	IPViolationsCheck() (bool, error)
@@ -1364,7 +2110,34 @@ type HostDB interface {
 	// hostdb.
 	SetIPViolationCheck(enabled bool) error
 
+	// SetLatencyWeight sets the weight of the latency adjustment in the host
+	// scores. A weight of 0 disables the adjustment.
+	SetLatencyWeight(float64) error
+
+	// PreviewScoringRules returns the score breakdowns the active hosts would
+	// have if the given scoring rules were applied.
+	PreviewScoringRules(HostScoringRules) ([]HostScorePreview, error)
+
+	// ScoringRules returns the user defined rules that adjust the host scores.
+	ScoringRules() (HostScoringRules, error)
+
+	// SelectionPolicy returns the policy the hostdb applies when selecting
+	// hosts.
+	SelectionPolicy() (HostSelectionPolicy, error)
+
+	// SetScoringRules replaces the user defined rules that adjust the host
+	// scores.
+	SetScoringRules(HostScoringRules) error
+
+	// SetSelectionPolicy updates the policy the hostdb applies when selecting
+	// hosts.
+	SetSelectionPolicy(HostSelectionPolicy) error
+
 	// UpdateContracts rebuilds the knownContracts of the HostBD using the provided
 	// contracts.
 	UpdateContracts([]RenterContract) error
+
+	// UpdateHostLatency updates the latency and throughput measured for the
+	// host with the given key.
+	UpdateHostLatency(types.SiaPublicKey, time.Duration, float64) error
 }
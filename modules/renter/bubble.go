@@ -168,7 +168,7 @@ func (bs *bubbleScheduler) callThreadedProcessBubbleUpdates() {
 			// Perform the bubble update
 			err := bs.managedPerformBubbleUpdate(siaPath)
 			if err != nil {
-				bs.staticRenter.log.Printf("WARN: error performing bubble on '%v': %v", siaPath, err)
+				bs.staticRenter.log.WithFields("siapath", siaPath).Printf("WARN: error performing bubble on '%v': %v", siaPath, err)
 			}
 
 			// Complete the bubble
@@ -177,7 +177,7 @@ func (bs *bubbleScheduler) callThreadedProcessBubbleUpdates() {
 			// Queue a bubble on the parent directory
 			err = bs.managedQueueParent(siaPath)
 			if err != nil {
-				bs.staticRenter.log.Printf("WARN: error queuing bubble for parent directory on '%v': %v", siaPath, err)
+				bs.staticRenter.log.WithFields("siapath", siaPath).Printf("WARN: error queuing bubble for parent directory on '%v': %v", siaPath, err)
 			}
 		}
 	}
@@ -314,7 +314,7 @@ func (bs *bubbleScheduler) managedPerformBubbleUpdate(siaPath modules.SiaPath) (
 	// loops start at the root directory so there is no point triggering them
 	// until the root directory is updated
 	if siaPath.IsRoot() {
-		if modules.NeedsRepair(metadata.AggregateHealth) {
+		if modules.NeedsRepair(metadata.AggregateHealth) || modules.NeedsRepair(metadata.AggregateRepairHealth) {
 			select {
 			case r.uploadHeap.repairNeeded <- struct{}{}:
 			default:
@@ -0,0 +1,296 @@
+package renter
+
+// checksum.go contains the logic for computing the whole-file checksums of
+// uploaded files and verifying the data on the network against them. The
+// checksum is computed over the uncompressed data of a file, which allows for
+// checking a download without keeping a local copy of the file.
+
+import (
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"io"
+	"io/ioutil"
+	"os"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// errNoChecksum is returned when verifying a file without a checksum.
+	errNoChecksum = errors.New("file doesn't have a checksum")
+)
+
+// checksumReader is an io.Reader which computes the checksum of the data read
+// from the underlying reader.
+type checksumReader struct {
+	staticType modules.ChecksumType
+	h          hash.Hash
+	r          io.Reader
+}
+
+// newChecksumReader creates a new checksumReader.
+func newChecksumReader(ct modules.ChecksumType, r io.Reader) (*checksumReader, error) {
+	h, err := ct.NewHash()
+	if err != nil {
+		return nil, err
+	}
+	return &checksumReader{
+		staticType: ct,
+		h:          h,
+		r:          r,
+	}, nil
+}
+
+// Checksum returns the checksum of the data read so far.
+func (cr *checksumReader) Checksum() (checksum crypto.Hash) {
+	copy(checksum[:], cr.h.Sum(nil))
+	return checksum
+}
+
+// Close closes the underlying reader if it is an io.Closer.
+func (cr *checksumReader) Close() error {
+	if c, ok := cr.r.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// Read implements the io.Reader interface.
+func (cr *checksumReader) Read(b []byte) (int, error) {
+	n, err := cr.r.Read(b)
+	_, _ = cr.h.Write(b[:n])
+	return n, err
+}
+
+// threadedUploadWithChecksum uploads the local file of an upload and stores
+// the checksum of the uploaded data in the siafile.
+func (r *Renter) threadedUploadWithChecksum(entry *filesystem.FileNode, source string, hosts map[string]struct{}, ct modules.ChecksumType) {
+	defer r.tg.Done()
+	err := r.managedUploadWithChecksum(entry, source, hosts, ct)
+	err = errors.Compose(err, entry.Close())
+	if err != nil {
+		r.log.Printf("WARN: failed to upload %v with checksum: %v", source, err)
+	}
+}
+
+// managedUploadWithChecksum reads the local file of an upload once, chunk by
+// chunk, and passes every chunk to the upload heap as a stream shard. The
+// checksum is computed from the same data, which makes sure that it matches
+// the uploaded data even if the local file is modified during the upload.
+// Chunks which fail to upload are repaired from the local file later on, but
+// the file won't have a checksum in that case.
+func (r *Renter) managedUploadWithChecksum(entry *filesystem.FileNode, source string, hosts map[string]struct{}, ct modules.ChecksumType) (err error) {
+	file, err := os.Open(source)
+	if err != nil {
+		return errors.AddContext(err, "unable to open the source file")
+	}
+	defer func() {
+		err = errors.Compose(err, file.Close())
+	}()
+	cr, err := newChecksumReader(ct, file)
+	if err != nil {
+		return err
+	}
+
+	// Build a map of host public keys.
+	pks := make(map[string]types.SiaPublicKey)
+	for _, pk := range entry.HostPublicKeys() {
+		pks[string(pk.Key)] = pk
+	}
+
+	size, chunkSize := entry.Size(), entry.ChunkSize()
+	for chunkIndex := uint64(0); chunkIndex*chunkSize < size; chunkIndex++ {
+		// Block until uploads are resumed if they have been paused.
+		if r.uploadHeap.managedIsPaused() {
+			select {
+			case <-r.tg.StopChan():
+				return errors.New("interrupted by shutdown")
+			case <-r.uploadHeap.pauseChan:
+			}
+		}
+
+		offline, goodForRenew, _ := r.managedContractUtilityMaps()
+		uuc, err := r.managedBuildUnfinishedChunk(entry, chunkIndex, hosts, pks, memoryPriorityHigh, offline, goodForRenew, r.userUploadMemoryManager)
+		if err != nil {
+			return errors.AddContext(err, "unable to fetch chunk for upload")
+		}
+		// The last chunk might be partial. Its length needs to match the data
+		// read from the file to not change the size of the file.
+		if remaining := size - chunkIndex*chunkSize; remaining < chunkSize {
+			uuc.length = remaining
+		}
+
+		// Read the chunk from the file using a shard.
+		ss := NewStreamShard(io.LimitReader(cr, int64(uuc.length)), nil)
+		uuc.sourceReader = ss
+		pushed, err := r.managedPushChunkForRepair(uuc, chunkTypeStreamChunk)
+		if err != nil {
+			return errors.AddContext(err, "unable to push chunk")
+		}
+		if !pushed {
+			// The chunk is already being repaired from the local file. It
+			// still needs to be read to compute the checksum.
+			_, err := io.Copy(ioutil.Discard, ss)
+			if err := errors.Compose(err, ss.Close()); err != nil {
+				return errors.AddContext(err, "unable to read chunk from the source file")
+			}
+			continue
+		}
+
+		// Wait for the shard to be read before reading the next chunk.
+		select {
+		case <-r.tg.StopChan():
+			return errors.New("interrupted by shutdown")
+		case <-ss.signalChan:
+		}
+		n, err := ss.Result()
+		if err != nil && !errors.Contains(err, io.EOF) {
+			return errors.AddContext(err, "unable to read chunk from the source file")
+		}
+		if uint64(n) != uuc.length {
+			return fmt.Errorf("chunk %v wasn't read completely", chunkIndex)
+		}
+	}
+	return entry.SetChecksum(ct, cr.Checksum())
+}
+
+// VerifyFile streams a file from the network and checks its data against its
+// checksum. The outcome is recorded in the file's metadata and an alert is
+// registered if the data doesn't match.
+func (r *Renter) VerifyFile(siaPath modules.SiaPath) (modules.FileVerification, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.FileVerification{}, err
+	}
+	defer r.tg.Done()
+	return r.managedVerifyFile(siaPath)
+}
+
+// VerifyDir verifies all files with a checksum within a directory and its
+// subdirectories in the background.
+func (r *Renter) VerifyDir(siaPath modules.SiaPath) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	exists, err := r.staticFileSystem.DirExists(siaPath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return filesystem.ErrNotExist
+	}
+	go r.threadedVerifyDir(siaPath)
+	return nil
+}
+
+// threadedVerifyDir verifies all files with a checksum within a directory and
+// its subdirectories one by one.
+func (r *Renter) threadedVerifyDir(siaPath modules.SiaPath) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	// Collect the files with a checksum.
+	var mu sync.Mutex
+	var files []modules.SiaPath
+	flf := func(fi modules.FileInfo) {
+		if fi.ChecksumType == "" {
+			return
+		}
+		mu.Lock()
+		files = append(files, fi.SiaPath)
+		mu.Unlock()
+	}
+	err := r.staticFileSystem.CachedList(siaPath, true, flf, func(modules.DirectoryInfo) {})
+	if err != nil {
+		r.log.Printf("WARN: failed to list files to verify in %v: %v", siaPath, err)
+		return
+	}
+
+	var failed int
+	for _, file := range files {
+		select {
+		case <-r.tg.StopChan():
+			return
+		default:
+		}
+		fv, err := r.managedVerifyFile(file)
+		if err != nil {
+			r.log.Printf("WARN: failed to verify %v: %v", file, err)
+			continue
+		}
+		if !fv.Verified {
+			failed++
+		}
+	}
+	r.log.Printf("Verified %v files in %v, %v didn't match their checksum", len(files), siaPath, failed)
+}
+
+// managedVerifyFile streams a file from the network and checks its data
+// against its checksum.
+func (r *Renter) managedVerifyFile(siaPath modules.SiaPath) (_ modules.FileVerification, err error) {
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return modules.FileVerification{}, err
+	}
+	defer func() {
+		err = errors.Compose(err, entry.Close())
+	}()
+	ci := entry.ChecksumInfo()
+	if !ci.HasChecksum() {
+		return modules.FileVerification{}, errNoChecksum
+	}
+
+	// Stream the file without fetching it from disk to make sure that the
+	// data on the network is checked.
+	snap, err := entry.Snapshot(siaPath)
+	if err != nil {
+		return modules.FileVerification{}, errors.AddContext(err, "unable to snapshot file")
+	}
+	stream, err := r.managedStreamer(snap, true)
+	if err != nil {
+		return modules.FileVerification{}, errors.AddContext(err, "unable to stream file")
+	}
+	defer func() {
+		err = errors.Compose(err, stream.Close())
+	}()
+	cr, err := newChecksumReader(ci.Type, stream)
+	if err != nil {
+		return modules.FileVerification{}, err
+	}
+	if _, err := io.Copy(ioutil.Discard, cr); err != nil {
+		return modules.FileVerification{}, errors.AddContext(err, "unable to download file")
+	}
+
+	actual := cr.Checksum()
+	fv := modules.FileVerification{
+		SiaPath:        siaPath,
+		ChecksumType:   ci.Type,
+		Checksum:       hex.EncodeToString(ci.Checksum[:]),
+		ActualChecksum: hex.EncodeToString(actual[:]),
+		Verified:       actual == ci.Checksum,
+		VerifiedTime:   time.Now(),
+	}
+	if err := entry.SetVerified(fv.VerifiedTime, !fv.Verified); err != nil {
+		return modules.FileVerification{}, errors.AddContext(err, "unable to record verification")
+	}
+
+	// Alert the user if the data doesn't match.
+	alertID := modules.AlertIDSiafileVerifyFailed(string(entry.UID()))
+	if fv.Verified {
+		r.staticAlerter.UnregisterAlert(alertID)
+	} else {
+		r.staticAlerter.RegisterAlert(alertID, AlertMSGSiafileVerifyFailed,
+			AlertCauseSiafileVerifyFailed(siaPath, fv.ChecksumType, fv.Checksum, fv.ActualChecksum),
+			modules.SeverityError)
+	}
+	return fv, nil
+}
@@ -0,0 +1,428 @@
+package renter
+
+// compression.go contains the helpers for transparently compressing files.
+// The data of a compressed file is split into blocks of compressionBlockSize
+// bytes which are compressed independently. Only the compressed data is
+// uploaded to the network. Since the blocks are independent, a range of the
+// uncompressed data can be served by downloading and decompressing only the
+// blocks which overlap the range.
+//
+// The compressed blocks are followed by an index containing the end offset of
+// every block. The index is uploaded together with the blocks, which keeps the
+// metadata of the file small no matter how large the file is. To locate the
+// blocks of a range, only the index entries of these blocks are downloaded.
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/modules/renter/filesystem/siafile"
+)
+
+var (
+	// errBlockTooLarge is returned if a block decompresses to more than the
+	// block size.
+	errBlockTooLarge = errors.New("decompressed block exceeds the block size")
+)
+
+type (
+	// compressionReader is an io.Reader which compresses the data read from
+	// the underlying reader block by block. Once the underlying reader is
+	// exhausted, the index of the compressed blocks is appended.
+	compressionReader struct {
+		staticType      modules.CompressionType
+		staticBlockSize uint64
+		r               io.Reader
+
+		buf          []byte
+		index        []byte
+		compressed   uint64
+		size         uint64
+		err          error
+		indexWritten bool
+	}
+
+	// compressedStreamer is a modules.Streamer which serves the uncompressed
+	// data of a compressed file from a streamer over the compressed data.
+	compressedStreamer struct {
+		staticStreamer    modules.Streamer
+		staticInfo        siafile.CompressionInfo
+		staticIndexOffset uint64
+
+		// bounds contains the offsets of the blocks starting at boundsBlock
+		// within the compressed data. Block boundsBlock+i spans the range
+		// [bounds[i], bounds[i+1]).
+		bounds      []uint64
+		boundsBlock int64
+
+		offset    int64
+		block     int64
+		blockData []byte
+	}
+
+	// decompressionWriter is an io.Writer which receives the compressed data
+	// of consecutive blocks in order and writes the uncompressed data of the
+	// range [skip, skip+length) relative to the first block to the
+	// underlying writer.
+	decompressionWriter struct {
+		staticType modules.CompressionType
+		staticSize uint64
+		w          io.Writer
+
+		blocks    []uint64
+		buf       []byte
+		skip      uint64
+		remaining uint64
+	}
+
+	// downloadDestinationDecompressed is a downloadDestination which
+	// decompresses the downloaded data before writing it to the actual
+	// destination.
+	downloadDestinationDecompressed struct {
+		*downloadDestinationWriter
+		staticWriter *decompressionWriter
+		staticCloser io.Closer
+	}
+)
+
+// compressBlock compresses a block of data.
+func compressBlock(ct modules.CompressionType, data []byte) ([]byte, error) {
+	switch ct {
+	case modules.CompressionGzip:
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		_, err := zw.Write(data)
+		if err := errors.Compose(err, zw.Close()); err != nil {
+			return nil, errors.AddContext(err, "failed to compress block")
+		}
+		return buf.Bytes(), nil
+	default:
+		return nil, errors.AddContext(modules.ErrUnknownCompression, string(ct))
+	}
+}
+
+// decompressBlock decompresses a block of data. The decompressed data may not
+// be larger than blockSize.
+func decompressBlock(ct modules.CompressionType, data []byte, blockSize uint64) ([]byte, error) {
+	switch ct {
+	case modules.CompressionGzip:
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to decompress block")
+		}
+		block, err := ioutil.ReadAll(io.LimitReader(zr, int64(blockSize)+1))
+		if err := errors.Compose(err, zr.Close()); err != nil {
+			return nil, errors.AddContext(err, "failed to decompress block")
+		}
+		if uint64(len(block)) > blockSize {
+			return nil, errBlockTooLarge
+		}
+		return block, nil
+	default:
+		return nil, errors.AddContext(modules.ErrUnknownCompression, string(ct))
+	}
+}
+
+// newCompressionReader creates a new compressionReader.
+func newCompressionReader(ct modules.CompressionType, blockSize uint64, r io.Reader) *compressionReader {
+	return &compressionReader{
+		staticType:      ct,
+		staticBlockSize: blockSize,
+		r:               r,
+	}
+}
+
+// Info returns the compression info of the data read so far.
+func (cr *compressionReader) Info() siafile.CompressionInfo {
+	return siafile.CompressionInfo{
+		Type:      cr.staticType,
+		BlockSize: cr.staticBlockSize,
+		Size:      cr.size,
+	}
+}
+
+// Read implements the io.Reader interface.
+func (cr *compressionReader) Read(b []byte) (int, error) {
+	for len(cr.buf) == 0 {
+		if cr.err == io.EOF && !cr.indexWritten {
+			cr.buf = cr.index
+			cr.indexWritten = true
+			continue
+		}
+		if cr.err != nil {
+			return 0, cr.err
+		}
+		block := make([]byte, cr.staticBlockSize)
+		n, err := io.ReadFull(cr.r, block)
+		if errors.Contains(err, io.EOF) || errors.Contains(err, io.ErrUnexpectedEOF) {
+			cr.err = io.EOF
+		} else if err != nil {
+			cr.err = err
+		}
+		if n == 0 {
+			continue
+		}
+		compressed, err := compressBlock(cr.staticType, block[:n])
+		if err != nil {
+			cr.err = err
+			return 0, err
+		}
+		cr.buf = compressed
+		cr.compressed += uint64(len(compressed))
+		cr.index = append(cr.index, make([]byte, siafile.CompressionIndexEntrySize)...)
+		binary.LittleEndian.PutUint64(cr.index[len(cr.index)-siafile.CompressionIndexEntrySize:], cr.compressed)
+		cr.size += uint64(n)
+	}
+	n := copy(b, cr.buf)
+	cr.buf = cr.buf[n:]
+	return n, nil
+}
+
+// compressionIndexOffset returns the offset of the index within the
+// compressed data of a file of the given size.
+func compressionIndexOffset(ci siafile.CompressionInfo, fileSize uint64) (uint64, error) {
+	if ci.IndexSize() > fileSize {
+		return 0, errors.New("file is too small to contain the compression index")
+	}
+	return fileSize - ci.IndexSize(), nil
+}
+
+// readCompressionIndex reads the index entries of the blocks [firstBlock,
+// endBlock) from the compressed data. It returns the offsets of the blocks
+// within the compressed data. Block firstBlock+i spans the range [bounds[i],
+// bounds[i+1]).
+func readCompressionIndex(rs io.ReadSeeker, indexOffset, firstBlock, endBlock uint64) (bounds []uint64, err error) {
+	if endBlock <= firstBlock {
+		return []uint64{0}, nil
+	}
+	// The start of the first block is the end of the previous one.
+	first := firstBlock
+	if first > 0 {
+		first--
+	} else {
+		bounds = append(bounds, 0)
+	}
+	if _, err := rs.Seek(int64(indexOffset+first*siafile.CompressionIndexEntrySize), io.SeekStart); err != nil {
+		return nil, errors.AddContext(err, "failed to seek to compression index")
+	}
+	entries := make([]byte, (endBlock-first)*siafile.CompressionIndexEntrySize)
+	if _, err := io.ReadFull(rs, entries); err != nil {
+		return nil, errors.AddContext(err, "failed to read compression index")
+	}
+	for len(entries) > 0 {
+		bound := binary.LittleEndian.Uint64(entries)
+		if len(bounds) > 0 && bound < bounds[len(bounds)-1] || bound > indexOffset {
+			return nil, errors.New("compression index is corrupted")
+		}
+		bounds = append(bounds, bound)
+		entries = entries[siafile.CompressionIndexEntrySize:]
+	}
+	return bounds, nil
+}
+
+// managedReadCompressionIndex reads the index entries of the blocks
+// [firstBlock, endBlock) of a compressed file from the network.
+func (r *Renter) managedReadCompressionIndex(entry *filesystem.FileNode, siaPath modules.SiaPath, firstBlock, endBlock uint64) (_ []uint64, err error) {
+	indexOffset, err := compressionIndexOffset(entry.CompressionInfo(), entry.Size())
+	if err != nil {
+		return nil, err
+	}
+	if endBlock <= firstBlock {
+		return []uint64{0}, nil
+	}
+	snap, err := entry.Snapshot(siaPath)
+	if err != nil {
+		return nil, err
+	}
+	s := r.managedRawStreamer(snap, false)
+	defer func() {
+		err = errors.Compose(err, s.Close())
+	}()
+	return readCompressionIndex(s, indexOffset, firstBlock, endBlock)
+}
+
+// newCompressedStreamer creates a streamer which decompresses the data of
+// the provided streamer over the compressed data of a file of the given size.
+func newCompressedStreamer(s modules.Streamer, ci siafile.CompressionInfo, fileSize uint64) (*compressedStreamer, error) {
+	indexOffset, err := compressionIndexOffset(ci, fileSize)
+	if err != nil {
+		return nil, err
+	}
+	return &compressedStreamer{
+		staticStreamer:    s,
+		staticInfo:        ci,
+		staticIndexOffset: indexOffset,
+		block:             -1,
+	}, nil
+}
+
+// blockBounds returns the range of the block within the compressed
+// data. The index entries are read in batches to avoid a lookup per block
+// when streaming sequentially.
+func (cs *compressedStreamer) blockBounds(block int64) (start, end uint64, err error) {
+	if block < cs.boundsBlock || block+1 >= cs.boundsBlock+int64(len(cs.bounds)) {
+		endBlock := uint64(block) + compressionIndexBatchSize
+		if numBlocks := cs.staticInfo.NumBlocks(); endBlock > numBlocks {
+			endBlock = numBlocks
+		}
+		cs.bounds, err = readCompressionIndex(cs.staticStreamer, cs.staticIndexOffset, uint64(block), endBlock)
+		if err != nil {
+			return 0, 0, err
+		}
+		cs.boundsBlock = block
+	}
+	i := block - cs.boundsBlock
+	return cs.bounds[i], cs.bounds[i+1], nil
+}
+
+// Close closes the underlying streamer.
+func (cs *compressedStreamer) Close() error {
+	return cs.staticStreamer.Close()
+}
+
+// Read reads the uncompressed data at the current offset. Only a single block
+// is read per call.
+func (cs *compressedStreamer) Read(b []byte) (int, error) {
+	if cs.offset >= int64(cs.staticInfo.Size) {
+		return 0, io.EOF
+	}
+	// Fetch the block containing the offset if it's not cached yet.
+	block := cs.offset / int64(cs.staticInfo.BlockSize)
+	if block != cs.block {
+		if block >= int64(cs.staticInfo.NumBlocks()) {
+			return 0, errors.New("offset is not covered by any compressed block")
+		}
+		start, end, err := cs.blockBounds(block)
+		if err != nil {
+			return 0, err
+		}
+		_, err = cs.staticStreamer.Seek(int64(start), io.SeekStart)
+		if err != nil {
+			return 0, errors.AddContext(err, "failed to seek to compressed block")
+		}
+		compressed := make([]byte, end-start)
+		_, err = io.ReadFull(cs.staticStreamer, compressed)
+		if err != nil {
+			return 0, errors.AddContext(err, "failed to read compressed block")
+		}
+		cs.blockData, err = decompressBlock(cs.staticInfo.Type, compressed, cs.staticInfo.BlockSize)
+		if err != nil {
+			return 0, err
+		}
+		cs.block = block
+	}
+	blockOffset := cs.offset - block*int64(cs.staticInfo.BlockSize)
+	if blockOffset >= int64(len(cs.blockData)) {
+		return 0, io.ErrUnexpectedEOF
+	}
+	n := copy(b, cs.blockData[blockOffset:])
+	cs.offset += int64(n)
+	return n, nil
+}
+
+// Seek sets the offset within the uncompressed data.
+func (cs *compressedStreamer) Seek(offset int64, whence int) (int64, error) {
+	var newOffset int64
+	switch whence {
+	case io.SeekStart:
+		newOffset = 0
+	case io.SeekCurrent:
+		newOffset = cs.offset
+	case io.SeekEnd:
+		newOffset = int64(cs.staticInfo.Size)
+	default:
+		return 0, errors.New("invalid whence")
+	}
+	newOffset += offset
+	if newOffset < 0 {
+		return cs.offset, errors.New("cannot seek to negative offset")
+	}
+	cs.offset = newOffset
+	return cs.offset, nil
+}
+
+// newDecompressionWriter creates a writer which decompresses the blocks with
+// the provided bounds, as returned by readCompressionIndex, and writes length
+// bytes starting at skip to w.
+func newDecompressionWriter(ci siafile.CompressionInfo, bounds []uint64, skip, length uint64, w io.Writer) *decompressionWriter {
+	var blocks []uint64
+	for i := 1; i < len(bounds); i++ {
+		blocks = append(blocks, bounds[i]-bounds[i-1])
+	}
+	return &decompressionWriter{
+		staticType: ci.Type,
+		staticSize: ci.BlockSize,
+		w:          w,
+		blocks:     blocks,
+		skip:       skip,
+		remaining:  length,
+	}
+}
+
+// Write implements the io.Writer interface.
+func (dw *decompressionWriter) Write(b []byte) (int, error) {
+	written := len(b)
+	for len(b) > 0 {
+		if len(dw.blocks) == 0 {
+			return 0, errors.New("received more data than expected")
+		}
+		// Buffer the data until the current block is complete.
+		missing := int(dw.blocks[0]) - len(dw.buf)
+		if missing > len(b) {
+			missing = len(b)
+		}
+		dw.buf = append(dw.buf, b[:missing]...)
+		b = b[missing:]
+		if uint64(len(dw.buf)) < dw.blocks[0] {
+			break
+		}
+		// Decompress the block and write the requested part of it.
+		block, err := decompressBlock(dw.staticType, dw.buf, dw.staticSize)
+		if err != nil {
+			return 0, err
+		}
+		dw.buf = dw.buf[:0]
+		dw.blocks = dw.blocks[1:]
+		if dw.skip >= uint64(len(block)) {
+			dw.skip -= uint64(len(block))
+			continue
+		}
+		block = block[dw.skip:]
+		dw.skip = 0
+		if uint64(len(block)) > dw.remaining {
+			block = block[:dw.remaining]
+		}
+		if _, err := dw.w.Write(block); err != nil {
+			return 0, err
+		}
+		dw.remaining -= uint64(len(block))
+	}
+	return written, nil
+}
+
+// newDownloadDestinationDecompressed creates a new
+// downloadDestinationDecompressed. The closer is closed together with the
+// destination and can be nil.
+func newDownloadDestinationDecompressed(dw *decompressionWriter, closer io.Closer) *downloadDestinationDecompressed {
+	return &downloadDestinationDecompressed{
+		downloadDestinationWriter: newDownloadDestinationWriter(dw),
+		staticWriter:              dw,
+		staticCloser:              closer,
+	}
+}
+
+// Close closes the destination.
+func (ddd *downloadDestinationDecompressed) Close() error {
+	err := ddd.downloadDestinationWriter.Close()
+	if ddd.staticCloser != nil {
+		err = errors.Compose(err, ddd.staticCloser.Close())
+	}
+	return err
+}
@@ -0,0 +1,149 @@
+package renter
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"io/ioutil"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/modules"
+)
+
+// nopCloser is a bytes.Reader which implements modules.Streamer.
+type nopCloser struct {
+	*bytes.Reader
+}
+
+// Close implements io.Closer.
+func (nopCloser) Close() error { return nil }
+
+// compressibleData returns n bytes of data which compress well.
+func compressibleData(n int) []byte {
+	pattern := fastrand.Bytes(64)
+	data := bytes.Repeat(pattern, n/len(pattern)+1)
+	return data[:n]
+}
+
+// TestCompression tests that data compressed by a compressionReader can be
+// read back from a compressedStreamer and a decompressionWriter.
+func TestCompression(t *testing.T) {
+	blockSize := uint64(1 << 10)
+	for _, size := range []int{1, 1000, int(blockSize), 10*int(blockSize) + 17} {
+		data := compressibleData(size)
+
+		// Compress the data.
+		cr := newCompressionReader(modules.CompressionGzip, blockSize, bytes.NewReader(data))
+		compressed, err := ioutil.ReadAll(cr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ci := cr.Info()
+		if ci.Size != uint64(size) {
+			t.Fatalf("expected size %v but got %v", size, ci.Size)
+		}
+		if numBlocks := (uint64(size) + blockSize - 1) / blockSize; ci.NumBlocks() != numBlocks {
+			t.Fatalf("expected %v blocks but got %v", numBlocks, ci.NumBlocks())
+		}
+		// The compressed data ends with the index.
+		indexOffset, err := compressionIndexOffset(ci, uint64(len(compressed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		bounds, err := readCompressionIndex(bytes.NewReader(compressed), indexOffset, 0, ci.NumBlocks())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bounds[len(bounds)-1] != indexOffset {
+			t.Fatalf("expected blocks to end at %v but got %v", indexOffset, bounds[len(bounds)-1])
+		}
+
+		// Read the data from a streamer.
+		cs, err := newCompressedStreamer(nopCloser{bytes.NewReader(compressed)}, ci, uint64(len(compressed)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		streamed, err := ioutil.ReadAll(cs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(streamed, data) {
+			t.Fatal("streamed data doesn't match")
+		}
+		end, err := cs.Seek(0, io.SeekEnd)
+		if err != nil || end != int64(size) {
+			t.Fatal("unexpected end", end, err)
+		}
+
+		// Read random ranges using the streamer and the writer.
+		for i := 0; i < 10; i++ {
+			offset := uint64(fastrand.Intn(size))
+			length := uint64(fastrand.Intn(size-int(offset)) + 1)
+
+			if _, err := cs.Seek(int64(offset), io.SeekStart); err != nil {
+				t.Fatal(err)
+			}
+			b := make([]byte, length)
+			if _, err := io.ReadFull(cs, b); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(b, data[offset:offset+length]) {
+				t.Fatal("streamed range doesn't match")
+			}
+
+			firstBlock, endBlock := ci.BlockRange(offset, length)
+			bounds, err := readCompressionIndex(bytes.NewReader(compressed), indexOffset, firstBlock, endBlock)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			dw := newDecompressionWriter(ci, bounds, offset-firstBlock*blockSize, length, &buf)
+			if _, err := dw.Write(compressed[bounds[0]:bounds[len(bounds)-1]]); err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(buf.Bytes(), data[offset:offset+length]) {
+				t.Fatal("written range doesn't match")
+			}
+		}
+	}
+}
+
+// TestReadCompressionIndexCorrupted tests that an index with decreasing or
+// out of bounds offsets is rejected.
+func TestReadCompressionIndexCorrupted(t *testing.T) {
+	index := func(bounds ...uint64) []byte {
+		// The index follows 10 bytes of compressed data.
+		b := make([]byte, 10, 10+len(bounds)*8)
+		for _, bound := range bounds {
+			b = append(b, make([]byte, 8)...)
+			binary.LittleEndian.PutUint64(b[len(b)-8:], bound)
+		}
+		return b
+	}
+	if _, err := readCompressionIndex(bytes.NewReader(index(5, 10)), 10, 0, 2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readCompressionIndex(bytes.NewReader(index(5, 4)), 10, 0, 2); err == nil {
+		t.Fatal("decreasing offsets should be rejected")
+	}
+	if _, err := readCompressionIndex(bytes.NewReader(index(5, 11)), 10, 0, 2); err == nil {
+		t.Fatal("offsets beyond the index should be rejected")
+	}
+}
+
+// TestDecompressBlockTooLarge tests that blocks which decompress to more than
+// the block size are rejected.
+func TestDecompressBlockTooLarge(t *testing.T) {
+	compressed, err := compressBlock(modules.CompressionGzip, make([]byte, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decompressBlock(modules.CompressionGzip, compressed, 100); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := decompressBlock(modules.CompressionGzip, compressed, 99); err != errBlockTooLarge {
+		t.Fatal("expected errBlockTooLarge, got", err)
+	}
+}
@@ -21,6 +21,12 @@ const (
 	// AlertSiafileLowRedundancyThreshold is the health threshold at which we start
 	// registering the LowRedundancy alert for a Siafile.
 	AlertSiafileLowRedundancyThreshold = 0.75
+	// AlertMSGSiafileVerifyFailed indicates that the data of a file on the
+	// network doesn't match its checksum.
+	AlertMSGSiafileVerifyFailed = "The data of the SiaFile mentioned in the 'Cause' doesn't match its checksum"
+	// AlertMSGSiafileUploadFailed indicates that the upload of a compressed
+	// file failed and the file was deleted.
+	AlertMSGSiafileUploadFailed = "The upload of the compressed SiaFile mentioned in the 'Cause' failed and the file was deleted"
 )
 
 // AlertCauseSiafileLowRedundancy creates a customized "cause" for a siafile
@@ -29,6 +35,18 @@ func AlertCauseSiafileLowRedundancy(siaPath modules.SiaPath, health, redundancy
 	return fmt.Sprintf("Siafile '%v' has a health of %v and redundancy of %v", siaPath.String(), health, redundancy)
 }
 
+// AlertCauseSiafileVerifyFailed creates a customized "cause" for a siafile
+// with a certain path whose data doesn't match its checksum.
+func AlertCauseSiafileVerifyFailed(siaPath modules.SiaPath, ct modules.ChecksumType, expected, actual string) string {
+	return fmt.Sprintf("Siafile '%v' has a %v checksum of %v but %v was expected", siaPath.String(), ct, actual, expected)
+}
+
+// AlertCauseSiafileUploadFailed creates a customized "cause" for a compressed
+// siafile with a certain path whose upload failed.
+func AlertCauseSiafileUploadFailed(siaPath modules.SiaPath, err error) string {
+	return fmt.Sprintf("Upload of siafile '%v' failed: %v", siaPath.String(), err)
+}
+
 // Default redundancy parameters.
 var (
 	// syncCheckInterval is how often the repair heap checks the consensus code
@@ -42,6 +60,24 @@ var (
 		Testing:  time.Second,
 	}).(time.Duration)
 
+	// compressionBlockSize is the size of the blocks the data of compressed
+	// files is split into before compressing it.
+	compressionBlockSize = build.Select(build.Var{
+		Dev:      uint64(1 << 16),
+		Standard: uint64(1 << 20),
+		Testnet:  uint64(1 << 20),
+		Testing:  uint64(1 << 10),
+	}).(uint64)
+
+	// compressionIndexBatchSize is the number of blocks whose index entries
+	// are read at once when streaming a compressed file.
+	compressionIndexBatchSize = build.Select(build.Var{
+		Dev:      uint64(64),
+		Standard: uint64(64),
+		Testnet:  uint64(64),
+		Testing:  uint64(4),
+	}).(uint64)
+
 	// cachedUtilitiesUpdateInterval is how often the renter updates the
 	// cachedUtilities.
 	cachedUtilitiesUpdateInterval = build.Select(build.Var{
@@ -198,9 +234,50 @@ const (
 	// maxStuckChunksInHeap is the maximum number of stuck chunks that the stuck
 	// loop will try to keep in the uploadHeap
 	maxStuckChunksInHeap = 25
+
+	// maxActiveTraces is the maximum number of unfinished traces. Operations
+	// started while there are more unfinished traces aren't traced.
+	maxActiveTraces = 10000
+
+	// maxSpansPerTrace is the maximum number of spans recorded per trace.
+	maxSpansPerTrace = 10000
+
+	// maxPendingExportSpans is the maximum number of finished spans waiting to
+	// be exported. Spans finished while the export is behind are dropped.
+	maxPendingExportSpans = 100000
+
+	// traceExportTimeout is the timeout for exporting spans to a collector.
+	traceExportTimeout = 30 * time.Second
 )
 
 var (
+	// auditInterval defines how much time passes between two rounds of host
+	// audits.
+	auditInterval = build.Select(build.Var{
+		Dev:      5 * time.Minute,
+		Standard: 1 * time.Hour,
+		Testnet:  1 * time.Hour,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+
+	// auditSectorsPerContract is the number of random sectors that are spot
+	// checked per contract during a round of host audits.
+	auditSectorsPerContract = build.Select(build.Var{
+		Dev:      2,
+		Standard: 2,
+		Testnet:  2,
+		Testing:  1,
+	}).(int)
+
+	// auditTimeout is the maximum amount of time a single sector audit may
+	// take before it is considered inconclusive.
+	auditTimeout = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 2 * time.Minute,
+		Testnet:  2 * time.Minute,
+		Testing:  10 * time.Second,
+	}).(time.Duration)
+
 	// healthCheckInterval defines the maximum amount of time that should pass
 	// in between checking the health of a file or directory.
 	healthCheckInterval = build.Select(build.Var{
@@ -210,6 +287,51 @@ var (
 		Testing:  5 * time.Second,
 	}).(time.Duration)
 
+	// fileVersionPruneInterval defines how often the prior versions of files
+	// are checked against the age limit of their versioning policy.
+	fileVersionPruneInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Hour,
+		Testnet:  time.Hour,
+		Testing:  3 * time.Second,
+	}).(time.Duration)
+
+	// reencodeRetryInterval defines how often pending re-encodes that were
+	// interrupted or couldn't be finished are retried.
+	reencodeRetryInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 15 * time.Minute,
+		Testnet:  15 * time.Minute,
+		Testing:  3 * time.Second,
+	}).(time.Duration)
+
+	// traceExportInterval defines how often the finished spans are exported
+	// to the trace export destination.
+	traceExportInterval = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: 10 * time.Second,
+		Testnet:  10 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// maxFinishedTraces is the number of finished traces that are kept in
+	// memory.
+	maxFinishedTraces = build.Select(build.Var{
+		Dev:      1000,
+		Standard: 1000,
+		Testnet:  1000,
+		Testing:  50,
+	}).(int)
+
+	// hostLatencyReportInterval defines how often the latencies measured by
+	// the workers are reported to the hostdb.
+	hostLatencyReportInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 10 * time.Minute,
+		Testnet:  10 * time.Minute,
+		Testing:  3 * time.Second,
+	}).(time.Duration)
+
 	// healthLoopErrorSleepDuration indicates how long the health loop should
 	// sleep before retrying if there is an error preventing progress.
 	healthLoopErrorSleepDuration = build.Select(build.Var{
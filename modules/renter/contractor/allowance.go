@@ -1,8 +1,11 @@
 package contractor
 
 import (
-	"errors"
+	"fmt"
 	"reflect"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
 
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
@@ -38,8 +41,20 @@ var (
 	// ErrAllowanceZeroMaxPeriodChurn is returned if the allowance max period
 	// churn is being set to zero when not cancelling the allowance
 	ErrAllowanceZeroMaxPeriodChurn = errors.New("max period churn must be non-zero")
+	// ErrAllowanceExceedsPolicy is returned if the allowance funds exceed the
+	// maximum allowed by the spending policy of the wallet
+	ErrAllowanceExceedsPolicy = errors.New("allowance funds exceed the maximum of the spending policy")
+	// ErrAllowanceIncreaseExceedsPolicy is returned if the allowance funds are
+	// increased by more than the spending policy of the wallet allows
+	ErrAllowanceIncreaseExceedsPolicy = errors.New("allowance funds increase exceeds the maximum of the spending policy")
 )
 
+// allowanceIncrease is an increase of the allowance funds at a certain time.
+type allowanceIncrease struct {
+	Time   time.Time      `json:"time"`
+	Amount types.Currency `json:"amount"`
+}
+
 // SetAllowance sets the amount of money the Contractor is allowed to spend on
 // contracts over a given time period, divided among the number of hosts
 // specified. Note that Contractor can start forming contracts as soon as
@@ -57,7 +72,10 @@ var (
 //
 // NOTE: At this time, transaction fees are not counted towards the allowance.
 // This means the contractor may spend more than allowance.Funds.
-func (c *Contractor) SetAllowance(a modules.Allowance) error {
+//
+// If the allowance can't be applied, the previous allowance is restored and
+// the increase of the funds doesn't count towards the spending policy.
+func (c *Contractor) SetAllowance(a modules.Allowance) (err error) {
 	if reflect.DeepEqual(a, modules.Allowance{}) {
 		return c.managedCancelAllowance()
 	}
@@ -87,7 +105,15 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 	} else if !c.cs.Synced() {
 		return errAllowanceNotSynced
 	}
-	c.log.Println("INFO: setting allowance to", a)
+
+	// Check the allowance against the spending policy of the wallet.
+	policy, err := c.wallet.SpendingPolicy()
+	if err != nil {
+		return errors.AddContext(err, "unable to get spending policy")
+	}
+	if !policy.MaxAllowanceFunds.IsZero() && a.Funds.Cmp(policy.MaxAllowanceFunds) > 0 {
+		return errors.AddContext(ErrAllowanceExceedsPolicy, fmt.Sprintf("%v > %v", a.Funds.HumanString(), policy.MaxAllowanceFunds.HumanString()))
+	}
 
 	// Set the current period if the existing allowance is empty.
 	//
@@ -108,6 +134,20 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 	// Also remember that we might have to unlock our contracts if the allowance
 	// was set to the empty allowance before.
 	c.mu.Lock()
+	// The increases of the funds within the allowanceIncreaseWindow add up,
+	// which prevents exceeding the policy with multiple smaller increases.
+	var increase allowanceIncrease
+	if a.Funds.Cmp(c.allowance.Funds) > 0 {
+		increase = allowanceIncrease{Time: time.Now(), Amount: a.Funds.Sub(c.allowance.Funds)}
+		total := c.recentAllowanceIncrease().Add(increase.Amount)
+		if !policy.MaxAllowanceIncrease.IsZero() && total.Cmp(policy.MaxAllowanceIncrease) > 0 {
+			c.mu.Unlock()
+			return errors.AddContext(ErrAllowanceIncreaseExceedsPolicy, fmt.Sprintf("%v > %v", total.HumanString(), policy.MaxAllowanceIncrease.HumanString()))
+		}
+		c.allowanceIncreases = append(c.allowanceIncreases, increase)
+	}
+	oldAllowance, oldPeriod := c.allowance, c.currentPeriod
+	c.log.Println("INFO: setting allowance to", a)
 	unlockContracts := false
 	if reflect.DeepEqual(c.allowance, modules.Allowance{}) {
 		c.currentPeriod = c.blockHeight
@@ -117,12 +157,19 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 		unlockContracts = true
 	}
 	c.allowance = a
-	err := c.save()
+	err = c.save()
 	c.mu.Unlock()
 	if err != nil {
 		c.log.Println("Unable to save contractor after setting allowance:", err)
 	}
 
+	// Restore the previous allowance if the new one can't be applied.
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, c.managedRestoreAllowance(oldAllowance, oldPeriod, increase))
+		}
+	}()
+
 	// Cycle through all contracts and unlock them again since they might have
 	// been locked by managedCancelAllowance previously.
 	if unlockContracts {
@@ -164,6 +211,33 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 	return nil
 }
 
+// managedRestoreAllowance restores the allowance and period that were replaced
+// by a failed call to SetAllowance and removes the increase of the funds that
+// was recorded for it.
+func (c *Contractor) managedRestoreAllowance(a modules.Allowance, period types.BlockHeight, increase allowanceIncrease) error {
+	c.mu.Lock()
+	increases := c.allowanceIncreases[:0]
+	for _, ai := range c.allowanceIncreases {
+		if !ai.Time.Equal(increase.Time) || !ai.Amount.Equals(increase.Amount) {
+			increases = append(increases, ai)
+		}
+	}
+	c.allowanceIncreases = increases
+	c.mu.Unlock()
+
+	// Lock the contracts again if the allowance was empty before.
+	if reflect.DeepEqual(a, modules.Allowance{}) {
+		return c.managedCancelAllowance()
+	}
+	c.mu.Lock()
+	c.allowance = a
+	c.currentPeriod = period
+	err := c.save()
+	c.mu.Unlock()
+	c.staticWatchdog.callAllowanceUpdated(a)
+	return errors.Compose(err, c.hdb.SetAllowance(a))
+}
+
 // managedCancelAllowance handles the special case where the allowance is empty.
 func (c *Contractor) managedCancelAllowance() error {
 	c.log.Println("INFO: canceling allowance")
@@ -229,3 +303,19 @@ func (c *Contractor) managedCancelAllowance() error {
 	}
 	return nil
 }
+
+// recentAllowanceIncrease removes the increases of the allowance funds that
+// are older than the allowanceIncreaseWindow and returns the sum of the
+// remaining ones.
+func (c *Contractor) recentAllowanceIncrease() types.Currency {
+	increases := c.allowanceIncreases[:0]
+	total := types.ZeroCurrency
+	for _, ai := range c.allowanceIncreases {
+		if time.Since(ai.Time) < allowanceIncreaseWindow {
+			increases = append(increases, ai)
+			total = total.Add(ai.Amount)
+		}
+	}
+	c.allowanceIncreases = increases
+	return total
+}
@@ -216,7 +216,7 @@ func (cl *churnLimiter) managedCanChurnContract(contract modules.RenterContract)
 // managedMarkContractUtility checks an active contract in the contractor and
 // figures out whether the contract is useful for uploading, and whether the
 // contract should be renewed.
-func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract, minScoreGFR, minScoreGFU types.Currency) (modules.HostScoreBreakdown, modules.ContractUtility, bool, error) {
+func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract, minScoreGFR, minScoreGFU types.Currency, violations map[string]struct{}) (modules.HostScoreBreakdown, modules.ContractUtility, bool, error) {
 	// Acquire contract.
 	sc, ok := c.staticContracts.Acquire(contract.ID)
 	if !ok {
@@ -253,6 +253,17 @@ func (c *Contractor) managedMarkContractUtility(contract modules.RenterContract,
 		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
 	}
 
+	// Check that the host doesn't violate the host selection policy.
+	u, needsUpdate = c.selectionPolicyCheck(sc.Metadata(), violations)
+	if needsUpdate {
+		err := c.managedUpdateContractUtility(sc, u)
+		if err != nil {
+			c.log.Println("Unable to acquire and update contract utility:", err)
+			return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, errors.AddContext(err, "unable to update utility after selectionPolicyCheck")
+		}
+		return modules.HostScoreBreakdown{}, modules.ContractUtility{}, false, nil
+	}
+
 	sb, err := c.hdb.ScoreBreakdown(host)
 	if err != nil {
 		c.log.Println("Unable to get ScoreBreakdown for", host.PublicKey.String(), "got err:", err)
@@ -311,9 +322,27 @@ func (c *Contractor) managedMarkContractsUtility() error {
 	// judgment.
 	suggestedUpdateQueue := make([]contractScoreAndUtil, 0)
 
+	// Find the hosts that violate the host selection policy. If the policy
+	// can't be checked, the utility of the contracts is left as it is.
+	contracts := c.staticContracts.ViewAll()
+	var pks []types.SiaPublicKey
+	for _, contract := range contracts {
+		if !contract.Utility.Locked {
+			pks = append(pks, contract.HostPublicKey)
+		}
+	}
+	violations := make(map[string]struct{})
+	badHosts, err := c.hdb.CheckForPolicyViolations(pks)
+	if err != nil {
+		c.log.Println("Unable to check hosts for host selection policy violations:", err)
+	}
+	for _, host := range badHosts {
+		violations[host.String()] = struct{}{}
+	}
+
 	// Update utility fields for each contract.
-	for _, contract := range c.staticContracts.ViewAll() {
-		sb, utility, update, err := c.managedMarkContractUtility(contract, minScoreGFR, minScoreGFU)
+	for _, contract := range contracts {
+		sb, utility, update, err := c.managedMarkContractUtility(contract, minScoreGFR, minScoreGFU, violations)
 		if err != nil {
 			return err
 		}
@@ -1,6 +1,8 @@
 package contractor
 
 import (
+	"time"
+
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/types"
@@ -79,6 +81,18 @@ var (
 	// failure mode of 'can't retrieve stuff already uploaded'.
 	MinContractFundUploadThreshold = float64(0.05) // 5%
 
+	// maxAuditFailureRatio is the ratio of failed audits to total audits of a
+	// host above which the contracts with that host are marked as having no
+	// utility. A failed audit means that the host was unable to prove that it
+	// still stores a sector of the contract.
+	maxAuditFailureRatio = float64(0.1) // 10%
+
+	// minFailedAuditsForUtilityCheck is the minimum number of failed audits a
+	// host needs to have before the maxAuditFailureRatio is considered. This
+	// prevents a single transient failure of a new host from marking its
+	// contract as having no utility.
+	minFailedAuditsForUtilityCheck = float64(3)
+
 	// randomHostsBufferForScore defines how many extra hosts are queried when trying
 	// to figure out an appropriate minimum score for the hosts that we have.
 	randomHostsBufferForScore = build.Select(build.Var{
@@ -143,3 +157,11 @@ var (
 	// be getting set in a lot more scientific way.
 	scoreLeewayGoodForUpload = types.NewCurrency64(40)
 )
+
+// Constants related to the spending policy of the wallet.
+const (
+	// allowanceIncreaseWindow is the window within which the increases of the
+	// allowance funds add up towards the MaxAllowanceIncrease of the spending
+	// policy.
+	allowanceIncreaseWindow = 24 * time.Hour
+)
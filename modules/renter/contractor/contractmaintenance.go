@@ -57,7 +57,8 @@ type (
 // marks down the host score, and marks the contract as !GoodForRenew and
 // !GoodForUpload.
 func (c *Contractor) callNotifyDoubleSpend(fcID types.FileContractID, blockHeight types.BlockHeight) {
-	c.log.Println("Watchdog found a double-spend: ", fcID, blockHeight)
+	log := c.log.WithFields("contractid", fcID)
+	log.Println("Watchdog found a double-spend: ", fcID, blockHeight)
 
 	// Mark the contract as double-spent. This will cause the contract to be
 	// excluded in period spending.
@@ -67,7 +68,7 @@ func (c *Contractor) callNotifyDoubleSpend(fcID types.FileContractID, blockHeigh
 
 	err := c.MarkContractBad(fcID)
 	if err != nil {
-		c.log.Println("callNotifyDoubleSpend error in MarkContractBad", err)
+		log.Println("callNotifyDoubleSpend error in MarkContractBad", err)
 	}
 }
 
@@ -91,7 +92,7 @@ func (c *Contractor) managedCheckForDuplicates() {
 			} else {
 				newContract, oldContract = contract, rc
 			}
-			c.log.Printf("Duplicate contract found. New contract is %x and old contract is %v", newContract.ID, oldContract.ID)
+			c.log.WithFields("contractid", oldContract.ID, "hostkey", oldContract.HostPublicKey).Printf("Duplicate contract found. New contract is %x and old contract is %v", newContract.ID, oldContract.ID)
 
 			// Get SafeContract
 			oldSC, ok := c.staticContracts.Acquire(oldContract.ID)
@@ -448,14 +449,14 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 		txnBuilder.Drop()
 		// We need to return a funding value because money was spent on this
 		// host, even though the full process could not be completed.
-		c.log.Println("WARN: Attempted to form a new contract with a host that we already have a contrat with.")
+		c.log.WithFields("contractid", contract.ID, "hostkey", contract.HostPublicKey).Println("WARN: Attempted to form a new contract with a host that we already have a contrat with.")
 		return contractFunding, modules.RenterContract{}, fmt.Errorf("We already have a contract with host %v", contract.HostPublicKey)
 	}
 	c.pubKeysToContractID[contract.HostPublicKey.String()] = contract.ID
 	c.mu.Unlock()
 
 	contractValue := contract.RenterFunds
-	c.log.Printf("Formed contract %v with %v for %v", contract.ID, host.NetAddress, contractValue.HumanString())
+	c.log.WithFields("contractid", contract.ID, "hostkey", contract.HostPublicKey).Printf("Formed contract %v with %v for %v", contract.ID, host.NetAddress, contractValue.HumanString())
 
 	// Update the hostdb to include the new contract.
 	err = c.hdb.UpdateContracts(c.staticContracts.ViewAll())
@@ -519,12 +520,12 @@ func (c *Contractor) managedLimitGFUHosts() {
 		}
 		host, ok, err := c.hdb.Host(contract.HostPublicKey)
 		if !ok || err != nil {
-			c.log.Print("managedLimitGFUHosts was run after updating contract utility but found contract without host in hostdb that's GFU", contract.HostPublicKey)
+			c.log.WithFields("contractid", contract.ID, "hostkey", contract.HostPublicKey).Print("managedLimitGFUHosts was run after updating contract utility but found contract without host in hostdb that's GFU", contract.HostPublicKey)
 			continue
 		}
 		score, err := c.hdb.ScoreBreakdown(host)
 		if err != nil {
-			c.log.Print("managedLimitGFUHosts: failed to get score breakdown for GFU host")
+			c.log.WithFields("contractid", contract.ID, "hostkey", contract.HostPublicKey).Print("managedLimitGFUHosts: failed to get score breakdown for GFU host")
 			continue
 		}
 		gfuContracts = append(gfuContracts, gfuContract{
@@ -540,9 +541,10 @@ func (c *Contractor) managedLimitGFUHosts() {
 	var contract gfuContract
 	for uint64(len(gfuContracts)) > wantedHosts {
 		contract, gfuContracts = gfuContracts[0], gfuContracts[1:]
+		log := c.log.WithFields("contractid", contract.c.ID, "hostkey", contract.c.HostPublicKey)
 		sc, ok := c.staticContracts.Acquire(contract.c.ID)
 		if !ok {
-			c.log.Print("managedLimitGFUHosts: failed to acquire GFU contract")
+			log.Print("managedLimitGFUHosts: failed to acquire GFU contract")
 			continue
 		}
 		u := sc.Utility()
@@ -550,7 +552,7 @@ func (c *Contractor) managedLimitGFUHosts() {
 		err := c.managedUpdateContractUtility(sc, u)
 		c.staticContracts.Return(sc)
 		if err != nil {
-			c.log.Print("managedLimitGFUHosts: failed to update GFU contract utility")
+			log.Print("managedLimitGFUHosts: failed to update GFU contract utility")
 			continue
 		}
 	}
@@ -594,6 +596,8 @@ func checkFormContractGouging(allowance modules.Allowance, hostSettings modules.
 // It returns the new contract. This is a blocking call that performs network
 // I/O.
 func (c *Contractor) managedRenew(id types.FileContractID, hpk types.SiaPublicKey, contractFunding types.Currency, newEndHeight types.BlockHeight, hostSettings modules.HostExternalSettings) (_ modules.RenterContract, err error) {
+	log := c.log.WithFields("contractid", id, "hostkey", hpk)
+
 	// Fetch the host associated with this contract.
 	host, ok, err := c.hdb.Host(hpk)
 	if err != nil {
@@ -603,7 +607,7 @@ func (c *Contractor) managedRenew(id types.FileContractID, hpk types.SiaPublicKe
 	host.HostExternalSettings = hostSettings
 
 	if c.staticDeps.Disrupt("DefaultRenewSettings") {
-		c.log.Debugln("Using default host settings")
+		log.Debugln("Using default host settings")
 		host.HostExternalSettings = modules.DefaultHostExternalSettings()
 		// Reset some specific settings, not available through the default.
 		host.HostExternalSettings.NetAddress = hostSettings.NetAddress
@@ -749,7 +753,7 @@ func (c *Contractor) managedRenew(id types.FileContractID, hpk types.SiaPublicKe
 	// Update the hostdb to include the new contract.
 	err = c.hdb.UpdateContracts(c.staticContracts.ViewAll())
 	if err != nil {
-		c.log.Println("Unable to update hostdb contracts:", err)
+		log.Println("Unable to update hostdb contracts:", err)
 	}
 
 	return newContract, nil
@@ -766,6 +770,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	id := renewInstructions.id
 	amount := renewInstructions.amount
 	hostPubKey := renewInstructions.hostPubKey
+	log := c.log.WithFields("contractid", id, "hostkey", hostPubKey)
 
 	// Get a session with the host, before marking it as being renewed.
 	hs, err := c.Session(hostPubKey, c.tg.StopChan())
@@ -777,12 +782,12 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 
 	// Mark the contract as being renewed, and defer logic to unmark it
 	// once renewing is complete.
-	c.log.Debugln("Marking a contract for renew:", id)
+	log.Debugln("Marking a contract for renew:", id)
 	c.mu.Lock()
 	c.renewing[id] = true
 	c.mu.Unlock()
 	defer func() {
-		c.log.Debugln("Unmarking the contract for renew", id)
+		log.Debugln("Unmarking the contract for renew", id)
 		c.mu.Lock()
 		delete(c.renewing, id)
 		c.mu.Unlock()
@@ -796,14 +801,14 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	d, dok := c.downloaders[id]
 	c.mu.RUnlock()
 	if eok {
-		c.log.Debugln("Waiting for editor invalidation")
+		log.Debugln("Waiting for editor invalidation")
 		e.invalidate()
-		c.log.Debugln("Got editor invalidation")
+		log.Debugln("Got editor invalidation")
 	}
 	if dok {
-		c.log.Debugln("Waiting for downloader invalidation")
+		log.Debugln("Waiting for downloader invalidation")
 		d.invalidate()
-		c.log.Debugln("Got downloader invalidation")
+		log.Debugln("Got downloader invalidation")
 	}
 
 	// Use the Settings RPC with the host and then invalidate the session.
@@ -812,18 +817,18 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 		err = errors.AddContext(err, "Unable to get host settings")
 		return
 	}
-	c.log.Debugln("Waiting for session invalidation")
+	log.Debugln("Waiting for session invalidation")
 	s.invalidate()
-	c.log.Debugln("Got session invalidation")
+	log.Debugln("Got session invalidation")
 
 	// Perform the actual renew. If the renew fails, return the
 	// contract. If the renew fails we check how often it has failed
 	// before. Once it has failed for a certain number of blocks in a
 	// row and reached its second half of the renew window, we give up
 	// on renewing it and set goodForRenew to false.
-	c.log.Debugln("calling managedRenew on contract", id)
+	log.Debugln("calling managedRenew on contract", id)
 	newContract, errRenew := c.managedRenew(id, hostPubKey, amount, endHeight, hostSettings)
-	c.log.Debugln("managedRenew has returned with error:", errRenew)
+	log.Debugln("managedRenew has returned with error:", errRenew)
 	oldContract, exists := c.staticContracts.Acquire(id)
 	if !exists {
 		return types.ZeroCurrency, errors.AddContext(errContractNotFound, "failed to acquire oldContract after renewal")
@@ -837,7 +842,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 			c.numFailedRenews[oldContract.Metadata().ID]++
 			totalFailures := c.numFailedRenews[oldContract.Metadata().ID]
 			c.mu.Unlock()
-			c.log.Debugln("remote host determined to be at fault, tallying up failed renews", totalFailures, id)
+			log.Debugln("remote host determined to be at fault, tallying up failed renews", totalFailures, id)
 		}
 
 		// Check if contract has to be replaced.
@@ -853,9 +858,9 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 			oldUtility.Locked = true
 			err := c.callUpdateUtility(oldContract, oldUtility, true)
 			if err != nil {
-				c.log.Println("WARN: failed to mark contract as !goodForRenew:", err)
+				log.Println("WARN: failed to mark contract as !goodForRenew:", err)
 			}
-			c.log.Printf("WARN: consistently failed to renew %v, marked as bad and locked: %v\n",
+			log.Printf("WARN: consistently failed to renew %v, marked as bad and locked: %v\n",
 				oldContract.Metadata().HostPublicKey, errRenew)
 			c.staticContracts.Return(oldContract)
 			return types.ZeroCurrency, errors.AddContext(errRenew, "contract marked as bad for too many consecutive failed renew attempts")
@@ -863,12 +868,12 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 
 		// Seems like it doesn't have to be replaced yet. Log the
 		// failure and number of renews that have failed so far.
-		c.log.Printf("WARN: failed to renew contract %v [%v]: '%v', current height: %v, proposed end height: %v, max duration: %v",
+		log.Printf("WARN: failed to renew contract %v [%v]: '%v', current height: %v, proposed end height: %v, max duration: %v",
 			oldContract.Metadata().HostPublicKey, numRenews, errRenew, blockHeight, endHeight, hostSettings.MaxDuration)
 		c.staticContracts.Return(oldContract)
 		return types.ZeroCurrency, errors.AddContext(errRenew, "contract renewal with host was unsuccessful")
 	}
-	c.log.Printf("Renewed contract %v\n", id)
+	log.Printf("Renewed contract %v\n", id)
 
 	// Skip the deletion of the old contract if required and delete the new
 	// contract to make sure we keep using the old one even though it has been
@@ -889,7 +894,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 		GoodForRenew:  true,
 	}
 	if err := c.managedAcquireAndUpdateContractUtility(newContract.ID, newUtility); err != nil {
-		c.log.Println("Failed to update the contract utilities", err)
+		log.Println("Failed to update the contract utilities", err)
 		c.staticContracts.Return(oldContract)
 		return amount, nil // Error is not returned because the renew succeeded.
 	}
@@ -897,7 +902,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	oldUtility.GoodForUpload = false
 	oldUtility.Locked = true
 	if err := c.callUpdateUtility(oldContract, oldUtility, true); err != nil {
-		c.log.Println("Failed to update the contract utilities", err)
+		log.Println("Failed to update the contract utilities", err)
 		c.staticContracts.Return(oldContract)
 		return amount, nil // Error is not returned because the renew succeeded.
 	}
@@ -917,7 +922,7 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	// Save the contractor.
 	err = c.save()
 	if err != nil {
-		c.log.Println("Failed to save the contractor after creating a new contract.")
+		log.Println("Failed to save the contractor after creating a new contract.")
 	}
 	c.mu.Unlock()
 	// Delete the old contract.
@@ -1091,21 +1096,22 @@ func (c *Contractor) threadedContractMaintenance() {
 	// Iterate through the contracts again, figuring out which contracts to
 	// renew and how much extra funds to renew them with.
 	for _, contract := range c.staticContracts.ViewAll() {
-		c.log.Debugln("Examining a contract:", contract.HostPublicKey, contract.ID)
+		log := c.log.WithFields("contractid", contract.ID, "hostkey", contract.HostPublicKey)
+		log.Debugln("Examining a contract:", contract.HostPublicKey, contract.ID)
 		// Skip any host that does not match our whitelist/blacklist filter
 		// settings.
 		host, _, err := c.hdb.Host(contract.HostPublicKey)
 		if err != nil {
-			c.log.Println("WARN: error getting host", err)
+			log.Println("WARN: error getting host", err)
 			continue
 		}
 		if host.Filtered {
-			c.log.Debugln("Contract skipped because it is filtered")
+			log.Debugln("Contract skipped because it is filtered")
 			continue
 		}
 		// Skip hosts that can't use the current renter-host protocol.
 		if build.VersionCmp(host.Version, modules.MinimumSupportedRenterHostProtocolVersion) < 0 {
-			c.log.Debugln("Contract skipped because host is using an outdated version", host.Version)
+			log.Debugln("Contract skipped because host is using an outdated version", host.Version)
 			continue
 		}
 
@@ -1114,9 +1120,9 @@ func (c *Contractor) threadedContractMaintenance() {
 		utility, ok := c.managedContractUtility(contract.ID)
 		if !ok || !utility.GoodForRenew {
 			if blockHeight-contract.StartHeight < types.BlocksPerWeek {
-				c.log.Debugln("Contract did not last 1 week and is not being renewed", contract.ID)
+				log.Debugln("Contract did not last 1 week and is not being renewed", contract.ID)
 			}
-			c.log.Debugln("Contract skipped because it is not good for renew (utility.GoodForRenew, exists)", utility.GoodForRenew, ok)
+			log.Debugln("Contract skipped because it is not good for renew (utility.GoodForRenew, exists)", utility.GoodForRenew, ok)
 			continue
 		}
 
@@ -1127,7 +1133,7 @@ func (c *Contractor) threadedContractMaintenance() {
 		if blockHeight+allowance.RenewWindow >= contract.EndHeight && !c.staticDeps.Disrupt("disableRenew") {
 			renewAmount, err := c.managedEstimateRenewFundingRequirements(contract, blockHeight, allowance)
 			if err != nil {
-				c.log.Debugln("Contract skipped because there was an error estimating renew funding requirements", renewAmount, err)
+				log.Debugln("Contract skipped because there was an error estimating renew funding requirements", renewAmount, err)
 				continue
 			}
 			renewSet = append(renewSet, fileContractRenewal{
@@ -1135,7 +1141,7 @@ func (c *Contractor) threadedContractMaintenance() {
 				amount:     renewAmount,
 				hostPubKey: contract.HostPublicKey,
 			})
-			c.log.Debugln("Contract has been added to the renew set for being past the renew height")
+			log.Debugln("Contract has been added to the renew set for being past the renew height")
 			continue
 		}
 
@@ -1174,9 +1180,9 @@ func (c *Contractor) threadedContractMaintenance() {
 				amount:     refreshAmount,
 				hostPubKey: contract.HostPublicKey,
 			})
-			c.log.Debugln("Contract identified as needing to be added to refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
+			log.Debugln("Contract identified as needing to be added to refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
 		} else {
-			c.log.Debugln("Contract did not get added to the refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
+			log.Debugln("Contract did not get added to the refresh set", contract.RenterFunds, sectorPrice.Mul64(3), percentRemaining, MinContractFundRenewalThreshold)
 		}
 	}
 	if len(renewSet) != 0 || len(refreshSet) != 0 {
@@ -1271,10 +1277,11 @@ func (c *Contractor) threadedContractMaintenance() {
 			return
 		}
 
-		c.log.Println("Attempting to perform a renewal:", renewal.id)
+		log := c.log.WithFields("contractid", renewal.id, "hostkey", renewal.hostPubKey)
+		log.Println("Attempting to perform a renewal:", renewal.id)
 		// Skip this renewal if we don't have enough funds remaining.
 		if renewal.amount.Cmp(fundsRemaining) > 0 || c.staticDeps.Disrupt("LowFundsRenewal") {
-			c.log.Println("Skipping renewal because there are not enough funds remaining in the allowance", renewal.id, renewal.amount, fundsRemaining)
+			log.Println("Skipping renewal because there are not enough funds remaining in the allowance", renewal.id, renewal.amount, fundsRemaining)
 			registerLowFundsAlert = true
 			continue
 		}
@@ -1285,13 +1292,13 @@ func (c *Contractor) threadedContractMaintenance() {
 		fundsSpent, err := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, endHeight)
 		if errors.Contains(err, errContractNotGFR) {
 			// Do not add a renewal error.
-			c.log.Debugln("Contract skipped because it is not good for renew", renewal.id)
+			log.Debugln("Contract skipped because it is not good for renew", renewal.id)
 		} else if err != nil {
-			c.log.Println("Error renewing a contract", renewal.id, err)
+			log.Println("Error renewing a contract", renewal.id, err)
 			renewErr = errors.Compose(renewErr, err)
 			numRenewFails++
 		} else {
-			c.log.Println("Renewal completed without error")
+			log.Println("Renewal completed without error")
 		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 	}
@@ -1315,9 +1322,10 @@ func (c *Contractor) threadedContractMaintenance() {
 		}
 
 		// Skip this renewal if we don't have enough funds remaining.
-		c.log.Debugln("Attempting to perform a contract refresh:", renewal.id)
+		log := c.log.WithFields("contractid", renewal.id, "hostkey", renewal.hostPubKey)
+		log.Debugln("Attempting to perform a contract refresh:", renewal.id)
 		if renewal.amount.Cmp(fundsRemaining) > 0 || c.staticDeps.Disrupt("LowFundsRefresh") {
-			c.log.Println("skipping refresh because there are not enough funds remaining in the allowance", renewal.amount.HumanString(), fundsRemaining.HumanString())
+			log.Println("skipping refresh because there are not enough funds remaining in the allowance", renewal.amount.HumanString(), fundsRemaining.HumanString())
 			registerLowFundsAlert = true
 			continue
 		}
@@ -1327,11 +1335,11 @@ func (c *Contractor) threadedContractMaintenance() {
 		// 'fundsSpent' will return '0'.
 		fundsSpent, err := c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, endHeight)
 		if err != nil {
-			c.log.Println("Error refreshing a contract", renewal.id, err)
+			log.Println("Error refreshing a contract", renewal.id, err)
 			renewErr = errors.Compose(renewErr, err)
 			numRenewFails++
 		} else {
-			c.log.Println("Refresh completed without error")
+			log.Println("Refresh completed without error")
 		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
 	}
@@ -1449,7 +1457,7 @@ func (c *Contractor) threadedContractMaintenance() {
 		start := time.Now()
 		fundsSpent, newContract, err := c.managedNewContract(host, contractFunds, endHeight)
 		if err != nil {
-			c.log.Printf("Attempted to form a contract with %v, time spent %v, but negotiation failed: %v\n", host.NetAddress, time.Since(start).Round(time.Millisecond), err)
+			c.log.WithFields("hostkey", host.PublicKey).Printf("Attempted to form a contract with %v, time spent %v, but negotiation failed: %v\n", host.NetAddress, time.Since(start).Round(time.Millisecond), err)
 			continue
 		}
 		fundsRemaining = fundsRemaining.Sub(fundsSpent)
@@ -1457,18 +1465,20 @@ func (c *Contractor) threadedContractMaintenance() {
 
 		sb, err := c.hdb.ScoreBreakdown(host)
 		if err == nil {
-			c.log.Println("A new contract has been formed with a host:", newContract.ID)
-			c.log.Println("Score:    ", sb.Score)
-			c.log.Println("Age Adjustment:        ", sb.AgeAdjustment)
-			c.log.Println("Base Price Adjustment: ", sb.BasePriceAdjustment)
-			c.log.Println("Burn Adjustment:       ", sb.BurnAdjustment)
-			c.log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
-			c.log.Println("Duration Adjustment:   ", sb.DurationAdjustment)
-			c.log.Println("Interaction Adjustment:", sb.InteractionAdjustment)
-			c.log.Println("Price Adjustment:      ", sb.PriceAdjustment)
-			c.log.Println("Storage Adjustment:    ", sb.StorageRemainingAdjustment)
-			c.log.Println("Uptime Adjustment:     ", sb.UptimeAdjustment)
-			c.log.Println("Version Adjustment:    ", sb.VersionAdjustment)
+			log := c.log.WithFields("contractid", newContract.ID, "hostkey", newContract.HostPublicKey)
+			log.Println("A new contract has been formed with a host:", newContract.ID)
+			log.Println("Score:    ", sb.Score)
+			log.Println("Age Adjustment:        ", sb.AgeAdjustment)
+			log.Println("Audit Adjustment:      ", sb.AuditAdjustment)
+			log.Println("Base Price Adjustment: ", sb.BasePriceAdjustment)
+			log.Println("Burn Adjustment:       ", sb.BurnAdjustment)
+			log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
+			log.Println("Duration Adjustment:   ", sb.DurationAdjustment)
+			log.Println("Interaction Adjustment:", sb.InteractionAdjustment)
+			log.Println("Price Adjustment:      ", sb.PriceAdjustment)
+			log.Println("Storage Adjustment:    ", sb.StorageRemainingAdjustment)
+			log.Println("Uptime Adjustment:     ", sb.UptimeAdjustment)
+			log.Println("Version Adjustment:    ", sb.VersionAdjustment)
 		}
 
 		// Add this contract to the contractor and save.
@@ -77,6 +77,10 @@ type Contractor struct {
 	currentPeriod types.BlockHeight
 	lastChange    modules.ConsensusChangeID
 
+	// allowanceIncreases are the recent increases of the allowance funds
+	// that count towards the MaxAllowanceIncrease of the spending policy.
+	allowanceIncreases []allowanceIncrease
+
 	// recentRecoveryChange is the first ConsensusChange that was missed while
 	// trying to find recoverable contracts. This is where we need to start
 	// rescanning the blockchain for recoverable contracts the next time the wallet
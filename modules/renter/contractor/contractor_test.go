@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -347,6 +348,108 @@ func TestIntegrationSetAllowance(t *testing.T) {
 	}
 }
 
+type (
+	// policyWallet is a wallet with a fixed spending policy.
+	policyWallet struct {
+		modules.Wallet
+		staticPolicy modules.SpendingPolicy
+	}
+
+	// failingAllowanceHostDB is a hostdb that fails to set the allowance if
+	// fail is set.
+	failingAllowanceHostDB struct {
+		modules.HostDB
+		fail bool
+	}
+)
+
+// SpendingPolicy returns the fixed spending policy of the wallet.
+func (w *policyWallet) SpendingPolicy() (modules.SpendingPolicy, error) {
+	return w.staticPolicy, nil
+}
+
+// SetAllowance fails if fail is set.
+func (hdb *failingAllowanceHostDB) SetAllowance(a modules.Allowance) error {
+	if hdb.fail {
+		return errors.New("failed to set allowance")
+	}
+	return hdb.HostDB.SetAllowance(a)
+}
+
+// TestSetAllowanceRestore tests that a failed call to SetAllowance restores
+// the previous allowance and doesn't count towards the maximum increase of the
+// allowance funds.
+func TestSetAllowanceRestore(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	dir := build.TempDir("contractor", t.Name())
+	cs, w, tpool, _, hdb, closeFn, err := newModules(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tryClose(closeFn, t)
+	pw := &policyWallet{
+		Wallet: w,
+		staticPolicy: modules.SpendingPolicy{
+			MaxAllowanceIncrease: types.SiacoinPrecision.Mul64(150),
+		},
+	}
+	fhdb := &failingAllowanceHostDB{HostDB: hdb}
+	c, errChan := New(cs, pw, tpool, fhdb, ratelimit.NewRateLimit(0, 0, 0), dir)
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	defer tryClose(c.Close, t)
+
+	// Setting the first allowance fails. The contractor keeps the empty
+	// allowance and the increase isn't recorded.
+	a := modules.DefaultAllowance
+	a.Funds = types.SiacoinPrecision.Mul64(100)
+	fhdb.fail = true
+	if err := c.SetAllowance(a); err == nil {
+		t.Fatal("expected SetAllowance to fail")
+	}
+	c.mu.Lock()
+	allowance, increases := c.allowance, len(c.allowanceIncreases)
+	c.mu.Unlock()
+	if !reflect.DeepEqual(allowance, modules.Allowance{}) || increases != 0 {
+		t.Fatal("allowance wasn't restored", allowance, increases)
+	}
+
+	// Setting it again succeeds since the failed increase didn't count.
+	fhdb.fail = false
+	if err := c.SetAllowance(a); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed increase of the funds restores the previous allowance.
+	b := a
+	b.Funds = types.SiacoinPrecision.Mul64(150)
+	fhdb.fail = true
+	if err := c.SetAllowance(b); err == nil {
+		t.Fatal("expected SetAllowance to fail")
+	}
+	c.mu.Lock()
+	allowance, increases = c.allowance, len(c.allowanceIncreases)
+	c.mu.Unlock()
+	if !reflect.DeepEqual(allowance, a) || increases != 1 {
+		t.Fatal("allowance wasn't restored", allowance, increases)
+	}
+	fhdb.fail = false
+	if err := c.SetAllowance(b); err != nil {
+		t.Fatal(err)
+	}
+
+	// Further increases exceed the policy.
+	b.Funds = types.SiacoinPrecision.Mul64(151)
+	if err := c.SetAllowance(b); !errors.Contains(err, ErrAllowanceIncreaseExceedsPolicy) {
+		t.Fatal("expected ErrAllowanceIncreaseExceedsPolicy, got", err)
+	}
+}
+
 // TestHostMaxDuration tests that a host will not be used if their max duration
 // is not sufficient when renewing contracts
 func TestHostMaxDuration(t *testing.T) {
@@ -2,6 +2,7 @@ package contractor
 
 import (
 	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/modules/renter/proto"
 	"go.sia.tech/siad/types"
@@ -119,6 +120,18 @@ func (c *Contractor) ContractByPublicKey(pk types.SiaPublicKey) (modules.RenterC
 	return c.managedContractByPublicKey(pk)
 }
 
+// RandomContractRoots returns up to n randomly chosen sector roots of the
+// contract with the host specified by the key.
+func (c *Contractor) RandomContractRoots(pk types.SiaPublicKey, n int) ([]crypto.Hash, error) {
+	c.mu.RLock()
+	id, ok := c.pubKeysToContractID[pk.String()]
+	c.mu.RUnlock()
+	if !ok {
+		return nil, errors.New("no contract with that host")
+	}
+	return c.staticContracts.RandomMerkleRoots(id, n)
+}
+
 // CancelContract cancels the Contractor's contract by marking it !GoodForRenew
 // and !GoodForUpload
 func (c *Contractor) CancelContract(id types.FileContractID) error {
@@ -72,6 +72,7 @@ func (c *Contractor) managedCheckHostScore(contract modules.RenterContract, sb m
 			c.log.Println("Min Score:", minScoreGFR)
 			c.log.Println("Score:    ", sb.Score)
 			c.log.Println("Age Adjustment:        ", sb.AgeAdjustment)
+			c.log.Println("Audit Adjustment:      ", sb.AuditAdjustment)
 			c.log.Println("Base Price Adjustment: ", sb.BasePriceAdjustment)
 			c.log.Println("Burn Adjustment:       ", sb.BurnAdjustment)
 			c.log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
@@ -102,6 +103,7 @@ func (c *Contractor) managedCheckHostScore(contract modules.RenterContract, sb m
 			c.log.Println("Min Score:", minScoreGFU)
 			c.log.Println("Score:    ", sb.Score)
 			c.log.Println("Age Adjustment:        ", sb.AgeAdjustment)
+			c.log.Println("Audit Adjustment:      ", sb.AuditAdjustment)
 			c.log.Println("Base Price Adjustment: ", sb.BasePriceAdjustment)
 			c.log.Println("Burn Adjustment:       ", sb.BurnAdjustment)
 			c.log.Println("Collateral Adjustment: ", sb.CollateralAdjustment)
@@ -163,6 +165,11 @@ func (c *Contractor) managedCriticalUtilityChecks(sc *proto.SafeContract, host m
 		return u, needsUpdate
 	}
 
+	u, needsUpdate = c.auditCheck(contract, host)
+	if needsUpdate {
+		return u, needsUpdate
+	}
+
 	u, needsUpdate = c.upForRenewalCheck(contract, renewWindow, blockHeight)
 	if needsUpdate {
 		return u, needsUpdate
@@ -225,6 +232,45 @@ func (c *Contractor) offlineCheck(contract modules.RenterContract, host modules.
 	return u, false
 }
 
+// auditCheck checks if the host for this contract failed too many of the
+// renter's audits of the data it stores.
+// Returns true if a check fails and the utility returned must be used to update
+// the contract state.
+func (c *Contractor) auditCheck(contract modules.RenterContract, host modules.HostDBEntry) (modules.ContractUtility, bool) {
+	u := contract.Utility
+	failed := host.HistoricFailedAudits
+	total := host.HistoricSuccessfulAudits + failed
+	if failed < minFailedAuditsForUtilityCheck || failed/total <= maxAuditFailureRatio {
+		return u, false
+	}
+	// Contract has no utility if the host can't prove that it stores our data.
+	if u.GoodForUpload || u.GoodForRenew {
+		c.log.Printf("Marking contract as having no utility because of failed audits: %.2f of %.2f - %v", failed, total, contract.ID)
+	}
+	u.GoodForUpload = false
+	u.GoodForRenew = false
+	return u, true
+}
+
+// selectionPolicyCheck checks if the host for this contract violates the host
+// selection policy of the renter.
+// Returns true if a check fails and the utility returned must be used to update
+// the contract state.
+func (c *Contractor) selectionPolicyCheck(contract modules.RenterContract, violations map[string]struct{}) (modules.ContractUtility, bool) {
+	u := contract.Utility
+	if _, violates := violations[contract.HostPublicKey.String()]; !violates {
+		return u, false
+	}
+	// Contract has no utility if the host is outside of the allowed regions
+	// or exceeds the limits of hosts per AS or country.
+	if u.GoodForUpload || u.GoodForRenew {
+		c.log.Println("Marking contract as having no utility because the host violates the host selection policy:", contract.ID)
+	}
+	u.GoodForUpload = false
+	u.GoodForRenew = false
+	return u, true
+}
+
 // upForRenewalCheck checks if this contract is up for renewal.
 // Returns true if a check fails and the utility returned must be used to update
 // the contract state.
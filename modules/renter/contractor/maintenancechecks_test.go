@@ -0,0 +1,87 @@
+package contractor
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+// TestAuditCheck tests that contracts are only marked as having no utility once
+// the host failed enough of the renter's audits.
+func TestAuditCheck(t *testing.T) {
+	log, err := persist.NewLogger(ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Contractor{log: log}
+	contract := modules.RenterContract{
+		Utility: modules.ContractUtility{
+			GoodForUpload: true,
+			GoodForRenew:  true,
+		},
+	}
+
+	tests := []struct {
+		successful float64
+		failed     float64
+		fail       bool
+	}{
+		{0, 0, false},
+		{0, minFailedAuditsForUtilityCheck - 1, false},
+		{0, minFailedAuditsForUtilityCheck, true},
+		{1000, minFailedAuditsForUtilityCheck, false},
+		{90, 10, false},
+		{89, 11, true},
+	}
+	for i, test := range tests {
+		host := modules.HostDBEntry{
+			HistoricSuccessfulAudits: test.successful,
+			HistoricFailedAudits:     test.failed,
+		}
+		u, needsUpdate := c.auditCheck(contract, host)
+		if needsUpdate != test.fail {
+			t.Fatalf("%v: expected needsUpdate to be %v", i, test.fail)
+		}
+		if test.fail && (u.GoodForUpload || u.GoodForRenew) {
+			t.Fatalf("%v: contract should have no utility", i)
+		}
+		if !test.fail && (u != contract.Utility) {
+			t.Fatalf("%v: utility shouldn't change", i)
+		}
+	}
+}
+
+// TestSelectionPolicyCheck tests that contracts are marked as having no utility
+// if their host violates the host selection policy.
+func TestSelectionPolicyCheck(t *testing.T) {
+	log, err := persist.NewLogger(ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &Contractor{log: log}
+	contract := modules.RenterContract{
+		HostPublicKey: types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}},
+		Utility: modules.ContractUtility{
+			GoodForUpload: true,
+			GoodForRenew:  true,
+		},
+	}
+	other := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+
+	// The contract keeps its utility if its host isn't violating the policy.
+	violations := map[string]struct{}{other.String(): {}}
+	u, needsUpdate := c.selectionPolicyCheck(contract, violations)
+	if needsUpdate || u != contract.Utility {
+		t.Fatal("utility shouldn't change", needsUpdate, u)
+	}
+
+	// The contract loses its utility if its host violates the policy.
+	violations[contract.HostPublicKey.String()] = struct{}{}
+	u, needsUpdate = c.selectionPolicyCheck(contract, violations)
+	if !needsUpdate || u.GoodForUpload || u.GoodForRenew {
+		t.Fatal("contract should have no utility", needsUpdate, u)
+	}
+}
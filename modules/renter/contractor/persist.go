@@ -28,6 +28,7 @@ var (
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
 	Allowance            modules.Allowance               `json:"allowance"`
+	AllowanceIncreases   []allowanceIncrease             `json:"allowanceincreases"`
 	BlockHeight          types.BlockHeight               `json:"blockheight"`
 	CurrentPeriod        types.BlockHeight               `json:"currentperiod"`
 	LastChange           modules.ConsensusChangeID       `json:"lastchange"`
@@ -54,6 +55,7 @@ func (c *Contractor) persistData() contractorPersist {
 	}
 	data := contractorPersist{
 		Allowance:            c.allowance,
+		AllowanceIncreases:   append([]allowanceIncrease{}, c.allowanceIncreases...),
 		BlockHeight:          c.blockHeight,
 		CurrentPeriod:        c.currentPeriod,
 		LastChange:           c.lastChange,
@@ -114,6 +116,7 @@ func (c *Contractor) load() error {
 	}
 
 	c.allowance = data.Allowance
+	c.allowanceIncreases = data.AllowanceIncreases
 	c.blockHeight = data.BlockHeight
 	c.currentPeriod = data.CurrentPeriod
 	c.lastChange = data.LastChange
@@ -317,6 +317,18 @@ func (r *Renter) managedPushUnexploredDirectory(siaPath modules.SiaPath) (err er
 		return err
 	}
 
+	// Scale the healths by the repair threshold of the directory's storage
+	// policy. The aggregate values of subdirectories with different policies
+	// are taken into account by the AggregateRepairHealth.
+	policy, err := r.staticFileSystem.StoragePolicy(siaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to get storage policy")
+	}
+	metadata.Health = repairHealth(metadata.Health, policy)
+	metadata.RemoteHealth = repairHealth(metadata.RemoteHealth, policy)
+	metadata.AggregateHealth = math.Max(repairHealth(metadata.AggregateHealth, policy), metadata.AggregateRepairHealth)
+	metadata.AggregateRemoteHealth = repairHealth(metadata.AggregateRemoteHealth, policy)
+
 	// Push unexplored directory onto heap.
 	r.directoryHeap.managedPushDirectory(siaPath, metadata, false)
 	return nil
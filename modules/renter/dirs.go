@@ -26,6 +26,8 @@ func (r *Renter) DeleteDir(siaPath modules.SiaPath) error {
 		return err
 	}
 	defer r.tg.Done()
+	r.staticReencodes.swapMu.RLock()
+	defer r.staticReencodes.swapMu.RUnlock()
 	return r.staticFileSystem.DeleteDir(siaPath)
 }
 
@@ -69,5 +71,7 @@ func (r *Renter) RenameDir(oldPath, newPath modules.SiaPath) error {
 	if newPath.IsRoot() {
 		return errors.New("cannot rename a file to the root directory")
 	}
+	r.staticReencodes.swapMu.RLock()
+	defer r.staticReencodes.swapMu.RUnlock()
 	return r.staticFileSystem.RenameDir(oldPath, newPath)
 }
@@ -53,6 +53,10 @@ type (
 
 		staticParams downloadParams
 
+		// staticSpan is the span of the download. Its trace contains the
+		// spans of the chunks and the worker jobs.
+		staticSpan *traceSpan
+
 		// Retrieval settings for the file.
 		staticLatencyTarget time.Duration // In milliseconds. Lower latency results in lower total system throughput.
 		staticOverdrive     int           // How many extra pieces to download to prevent slow hosts from being a bottleneck.
@@ -79,6 +83,10 @@ type (
 
 		staticMemoryManager *memoryManager
 
+		// staticParentSpan is the span the download is traced in. If it's not
+		// set, a new trace is started for the download.
+		staticParentSpan *traceSpan
+
 		// staticSpendingCategory specifies what field to update when we track
 		// the amount of money spent from an ephemeral account
 		staticSpendingCategory spendingCategory
@@ -102,7 +110,7 @@ func (d *download) managedFail(err error) {
 	if complete && d.err != nil {
 		return
 	} else if complete && d.err == nil {
-		d.r.log.Critical("download is marked as completed without error, but then managedFail was called with err:", err)
+		d.r.log.WithFields("siapath", d.staticSiaPath).Critical("download is marked as completed without error, but then managedFail was called with err:", err)
 		return
 	}
 
@@ -134,7 +142,7 @@ func (d *download) markComplete() {
 	}
 	// Log potential errors.
 	if err != nil {
-		d.r.log.Println("Failed to execute at least one downloadCompleteFunc", err)
+		d.r.log.WithFields("siapath", d.staticSiaPath).Println("Failed to execute at least one downloadCompleteFunc", err)
 	}
 	// Set downloadCompleteFuncs to nil to avoid executing them multiple times.
 	d.downloadCompleteFuncs = nil
@@ -149,7 +157,7 @@ func (d *download) onComplete(f func(error) error) {
 	select {
 	case <-d.completeChan:
 		if err := f(d.err); err != nil {
-			d.r.log.Println("Failed to execute downloadCompleteFunc", err)
+			d.r.log.WithFields("siapath", d.staticSiaPath).Println("Failed to execute downloadCompleteFunc", err)
 		}
 		return
 	default:
@@ -269,25 +277,68 @@ func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (_ *downloa
 	if p.Destination != "" && !filepath.IsAbs(p.Destination) {
 		return nil, errors.New("destination must be an absolute path")
 	}
-	if p.Offset == entry.Size() && entry.Size() != 0 {
+	// The offset and length of compressed files refer to the uncompressed
+	// data.
+	ci := entry.CompressionInfo()
+	size := entry.Size()
+	if ci.Compressed() {
+		size = ci.Size
+	}
+	if p.Offset == size && size != 0 {
 		return nil, errors.New("offset equals filesize")
 	}
 	// Sentinel: if length == 0, download the entire file.
 	if p.Length == 0 {
-		if p.Offset > entry.Size() {
+		if p.Offset > size {
 			return nil, errors.New("offset cannot be greater than file size")
 		}
-		p.Length = entry.Size() - p.Offset
+		p.Length = size - p.Offset
 	}
 	// Check whether offset and length is valid.
-	if p.Offset < 0 || p.Offset+p.Length > entry.Size() {
-		return nil, fmt.Errorf("offset and length combination invalid, max byte is at index %d", entry.Size()-1)
+	if p.Offset < 0 || p.Offset+p.Length > size {
+		return nil, fmt.Errorf("offset and length combination invalid, max byte is at index %d", size-1)
+	}
+	// Count the download towards the download quota of the file's tenant.
+	// The download is refunded if it fails.
+	if err := r.staticFileSystem.RecordTenantDownload(p.SiaPath, p.Length); err != nil {
+		return nil, err
 	}
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, r.staticFileSystem.RefundTenantDownload(p.SiaPath, p.Length))
+		}
+	}()
 
 	// Instantiate the correct downloadWriter implementation.
 	var dw downloadDestination
 	var destinationType string
-	if isHTTPResp {
+	offset, length := p.Offset, p.Length
+	if ci.Compressed() {
+		// Download the compressed blocks containing the requested range and
+		// decompress them.
+		firstBlock, endBlock := ci.BlockRange(p.Offset, p.Length)
+		bounds, err := r.managedReadCompressionIndex(entry, p.SiaPath, firstBlock, endBlock)
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to read compression index")
+		}
+		skip := p.Offset - firstBlock*ci.BlockSize
+		var w io.Writer
+		var closer io.Closer
+		if isHTTPResp {
+			w = p.Httpwriter
+			destinationType = "http stream"
+		} else {
+			osFile, err := os.OpenFile(p.Destination, os.O_CREATE|os.O_WRONLY, entry.Mode())
+			if err != nil {
+				return nil, err
+			}
+			w = NewSectionWriter(osFile, 0, int64(p.Length))
+			closer = osFile
+			destinationType = "file"
+		}
+		dw = newDownloadDestinationDecompressed(newDecompressionWriter(ci, bounds, skip, p.Length, w), closer)
+		offset, length = bounds[0], bounds[len(bounds)-1]-bounds[0]
+	} else if isHTTPResp {
 		dw = newDownloadDestinationWriter(p.Httpwriter)
 		destinationType = "http stream"
 	} else {
@@ -313,7 +364,7 @@ func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (_ *downloa
 	}
 
 	// Prepare snapshot.
-	snap, err := entry.SnapshotRange(p.SiaPath, p.Offset, p.Length)
+	snap, err := entry.SnapshotRange(p.SiaPath, offset, length)
 	if err != nil {
 		return nil, err
 	}
@@ -326,9 +377,9 @@ func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (_ *downloa
 		file:              snap,
 
 		latencyTarget: 25e3 * time.Millisecond, // TODO: high default until full latency support is added.
-		length:        p.Length,
+		length:        length,
 		needsMemory:   true,
-		offset:        p.Offset,
+		offset:        offset,
 		overdrive:     3, // TODO: moderate default until full overdrive support is added.
 		priority:      5, // TODO: moderate default until full priority support is added.
 
@@ -343,16 +394,21 @@ func (r *Renter) managedDownload(p modules.RenterDownloadParameters) (_ *downloa
 	}
 
 	// Register some cleanup for when the download is done.
-	d.OnComplete(func(_ error) error {
+	d.OnComplete(func(downloadErr error) error {
+		// failed downloads don't count towards the download quota.
+		var refundErr error
+		if downloadErr != nil {
+			refundErr = r.staticFileSystem.RefundTenantDownload(p.SiaPath, p.Length)
+		}
 		// close the destination if possible.
 		if closer, ok := dw.(io.Closer); ok {
-			return closer.Close()
+			return errors.Compose(refundErr, closer.Close())
 		}
 		// sanity check that we close files.
 		if destinationType == "file" {
 			build.Critical("file wasn't closed after download")
 		}
-		return nil
+		return refundErr
 	})
 
 	// Add the download object to the download history if it's not a stream.
@@ -404,10 +460,18 @@ func (r *Renter) managedNewDownload(params downloadParams) (*download, error) {
 		staticParams: params,
 	}
 
+	// Trace the download.
+	attributes := []interface{}{"siapath", d.staticSiaPath, "offset", params.offset, "length", params.length, "destinationtype", params.destinationType}
+	d.staticSpan = params.staticParentSpan.startChild("download", attributes...)
+	if d.staticSpan == nil {
+		d.staticSpan = r.staticTracer.startTrace("download", attributes...)
+	}
+
 	// Update the endTime of the download when it's done. Also nil out the
 	// destination pointer so that the garbage collector does not think any
 	// memory is still being used.
-	d.onComplete(func(_ error) error {
+	d.onComplete(func(err error) error {
+		d.staticSpan.finish(err)
 		d.endTime = time.Now()
 		d.destination = nil
 		d.staticParams.file = nil
@@ -441,7 +505,9 @@ func (d *download) Start() error {
 	}
 	// Make sure the requested chunks are within the boundaries.
 	if minChunk == params.file.NumChunks() || maxChunk == params.file.NumChunks() {
-		return errors.New("download is requesting a chunk that is past the boundary of the file")
+		err := errors.New("download is requesting a chunk that is past the boundary of the file")
+		d.staticSpan.finish(err)
+		return err
 	}
 
 	// For each chunk, assemble a mapping from the contract id to the index of
@@ -458,7 +524,7 @@ func (d *download) Start() error {
 				// the same chunk.
 				_, exists := chunkMaps[chunkIndex-minChunk][piece.HostPubKey.String()]
 				if exists {
-					d.r.log.Println("ERROR: Worker has multiple pieces uploaded for the same chunk.", params.file.SiaPath(), chunkIndex, pieceIndex, piece.HostPubKey.String())
+					d.r.log.WithFields("siapath", params.file.SiaPath(), "hostkey", piece.HostPubKey).Println("ERROR: Worker has multiple pieces uploaded for the same chunk.", params.file.SiaPath(), chunkIndex, pieceIndex, piece.HostPubKey.String())
 				}
 				chunkMaps[chunkIndex-minChunk][piece.HostPubKey.String()] = downloadPieceInfo{
 					index: uint64(pieceIndex),
@@ -505,6 +571,7 @@ func (d *download) Start() error {
 
 			download:            d,
 			staticMemoryManager: params.staticMemoryManager,
+			staticSpan:          d.staticSpan.startChild("download.chunk", "chunkindex", i),
 			renterFile:          params.file,
 		}
 
@@ -566,6 +633,7 @@ func (r *Renter) DownloadByUID(uid modules.DownloadID) (modules.DownloadInfo, bo
 		StartTime:            d.staticStartTime,
 		StartTimeUnix:        d.staticStartTime.UnixNano(),
 		TotalDataTransferred: atomic.LoadUint64(&d.atomicTotalDataTransferred),
+		TraceID:              d.staticSpan.traceID(),
 	}, true
 }
 
@@ -610,6 +678,7 @@ func (r *Renter) DownloadHistory() []modules.DownloadInfo {
 			StartTime:            d.staticStartTime,
 			StartTimeUnix:        d.staticStartTime.UnixNano(),
 			TotalDataTransferred: atomic.LoadUint64(&d.atomicTotalDataTransferred),
+			TraceID:              d.staticSpan.traceID(),
 		}
 		// Release download lock before calling d.Err(), which will acquire the
 		// lock. The error needs to be checked separately because we need to
@@ -75,6 +75,10 @@ type unfinishedDownloadChunk struct {
 	// Memory management variables.
 	memoryAllocated uint64
 
+	// staticSpan is the span of the chunk download. The jobs of the workers
+	// are traced as its children.
+	staticSpan *traceSpan
+
 	// The download object, mostly to update download progress.
 	download *download
 	mu       sync.Mutex
@@ -94,6 +98,7 @@ func (udc *unfinishedDownloadChunk) fail(err error) {
 	}
 	udc.download.managedFail(fmt.Errorf("chunk %v failed: %v", udc.staticChunkIndex, err))
 	udc.destination = nil
+	udc.staticSpan.finish(err)
 }
 
 // managedCleanUp will check if the download has failed, and if not it will add
@@ -149,6 +154,7 @@ func (udc *unfinishedDownloadChunk) managedFinalizeRecovery() {
 	udc.physicalChunkData = nil
 	udc.recoveryComplete = true
 	udc.mu.Unlock()
+	udc.staticSpan.finish(nil)
 
 	// Update the download and signal completion of this chunk.
 	udc.download.mu.Lock()
@@ -492,8 +492,15 @@ func (r *Renter) Streamer(siaPath modules.SiaPath, disableLocalFetch bool) (_ st
 	if err != nil {
 		return "", nil, err
 	}
-	s := r.managedStreamer(snap, disableLocalFetch)
-	return siaPath.String(), s, nil
+	s, err := r.managedStreamer(snap, disableLocalFetch)
+	if err != nil {
+		return "", nil, err
+	}
+	return siaPath.String(), &tenantStreamer{
+		Streamer:         s,
+		staticFileSystem: r.staticFileSystem,
+		staticSiaPath:    siaPath,
+	}, nil
 }
 
 // StreamerByNode will open a streamer for the renter, taking a FileNode as
@@ -511,13 +518,28 @@ func (r *Renter) StreamerByNode(node *filesystem.FileNode, disableLocalFetch boo
 	if err != nil {
 		return nil, err
 	}
-	s := r.managedStreamer(snap, disableLocalFetch)
-	return s, nil
+	return r.managedStreamer(snap, disableLocalFetch)
 }
 
 // managedStreamer creates a streamer from a siafile snapshot and starts filling
 // its cache.
-func (r *Renter) managedStreamer(snapshot *siafile.Snapshot, disableLocalFetch bool) modules.Streamer {
+func (r *Renter) managedStreamer(snapshot *siafile.Snapshot, disableLocalFetch bool) (modules.Streamer, error) {
+	s := r.managedRawStreamer(snapshot, disableLocalFetch)
+
+	// Decompress the data of compressed files.
+	if ci := snapshot.CompressionInfo(); ci.Compressed() {
+		cs, err := newCompressedStreamer(s, ci, snapshot.Size())
+		if err != nil {
+			return nil, errors.Compose(err, s.Close())
+		}
+		return cs, nil
+	}
+	return s, nil
+}
+
+// managedRawStreamer creates a streamer from a siafile snapshot that returns
+// the data as it is stored on the network, without decompressing it.
+func (r *Renter) managedRawStreamer(snapshot *siafile.Snapshot, disableLocalFetch bool) *streamer {
 	s := &streamer{
 		staticFile: snapshot,
 		r:          r,
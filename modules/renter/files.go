@@ -18,10 +18,21 @@ func (r *Renter) DeleteFile(siaPath modules.SiaPath) error {
 	}
 	defer r.tg.Done()
 
-	// Perform the delete operation.
-	err = r.staticFileSystem.DeleteFile(siaPath)
+	// Keep the file as a prior version if versioning is enabled for its
+	// directory. Otherwise perform the delete operation.
+	r.staticReencodes.swapMu.RLock()
+	archived, err := r.staticFileSystem.ArchiveFile(siaPath)
 	if err != nil {
-		return errors.AddContext(err, "unable to delete siafile from filesystem")
+		err = errors.AddContext(err, "unable to archive siafile")
+	} else if !archived {
+		err = errors.AddContext(r.staticFileSystem.DeleteFile(siaPath), "unable to delete siafile from filesystem")
+	}
+	r.staticReencodes.swapMu.RUnlock()
+	if err != nil {
+		return err
+	}
+	if archived {
+		r.managedBubbleFileVersions(siaPath)
 	}
 
 	// Update the filesystem metadata.
@@ -63,6 +74,21 @@ func (r *Renter) FileList(siaPath modules.SiaPath, recursive, cached bool, flf m
 	return err
 }
 
+// QueryFiles returns the files within the directory specified by siaPath that
+// match the query in the query's order. If the query has a limit and there are
+// more matching files, the cursor of the next page is returned as well.
+func (r *Renter) QueryFiles(siaPath modules.SiaPath, recursive, cached bool, q modules.FileQuery) ([]modules.FileInfo, string, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, "", err
+	}
+	defer r.tg.Done()
+	if cached {
+		return r.staticFileSystem.QueryFiles(siaPath, recursive, true, q, nil, nil, nil)
+	}
+	offlineMap, goodForRenewMap, contractsMap := r.managedContractUtilityMaps()
+	return r.staticFileSystem.QueryFiles(siaPath, recursive, false, q, offlineMap, goodForRenewMap, contractsMap)
+}
+
 // File returns file from siaPath queried by user.
 // Update based on FileList
 func (r *Renter) File(siaPath modules.SiaPath) (modules.FileInfo, error) {
@@ -98,7 +124,9 @@ func (r *Renter) RenameFile(currentName, newName modules.SiaPath) error {
 	defer r.tg.Done()
 
 	// Rename file.
+	r.staticReencodes.swapMu.RLock()
 	err := r.staticFileSystem.RenameFile(currentName, newName)
+	r.staticReencodes.swapMu.RUnlock()
 	if err != nil {
 		return err
 	}
@@ -125,6 +153,51 @@ func (r *Renter) RenameFile(currentName, newName modules.SiaPath) error {
 	return bubblePaths.callRefreshAll()
 }
 
+// CopyFile creates a copy of a file which shares the sectors and keys of the
+// original. No data is uploaded. Since deleting a file doesn't remove its
+// sectors from the hosts, deleting the original or the copy doesn't affect the
+// other file.
+func (r *Renter) CopyFile(siaPath, newSiaPath modules.SiaPath) (err error) {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	// The copy counts towards the storage quota of its tenant. The
+	// reservation is refunded if the copy fails.
+	fi, err := r.staticFileSystem.CachedFileInfo(siaPath)
+	if err != nil {
+		return err
+	}
+	tu, err := r.staticFileSystem.BeginTenantUpload(newSiaPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			err = errors.Compose(err, tu.Refund())
+		}
+		err = errors.Compose(err, tu.Close())
+	}()
+	if err := tu.ReserveStorage(fi.Filesize); err != nil {
+		return err
+	}
+	r.staticReencodes.swapMu.RLock()
+	err = r.staticFileSystem.CopyFile(siaPath, newSiaPath)
+	r.staticReencodes.swapMu.RUnlock()
+	if err != nil {
+		return err
+	}
+
+	// Queue a bubble for the directory of the copy to update its metadata.
+	dirSiaPath, err := newSiaPath.Dir()
+	if err != nil {
+		return err
+	}
+	_ = r.staticBubbleScheduler.callQueueBubble(dirSiaPath)
+	return nil
+}
+
 // SetFileStuck sets the Stuck field of the whole siafile to stuck.
 func (r *Renter) SetFileStuck(siaPath modules.SiaPath, stuck bool) (err error) {
 	if err := r.tg.Add(); err != nil {
@@ -0,0 +1,62 @@
+package filesystem
+
+import (
+	"bytes"
+	"io/ioutil"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem/siafile"
+)
+
+// CopyFile creates a copy of the siafile at src at dst. The copy shares the
+// sectors and keys of the original, which means that no data needs to be
+// uploaded. The sectors don't need to be reference counted since the renter
+// never deletes sectors from its hosts, they are kept until the contracts
+// expire. The copy gets a new UID and no local path since the local file of
+// the original might change independently of the copy. If dst is taken,
+// ErrExists is returned.
+func (fs *FileSystem) CopyFile(src, dst modules.SiaPath) (err error) {
+	// Open the original.
+	sf, err := fs.OpenSiaFile(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, sf.Close())
+	}()
+
+	// Load the copy from the original. The snapshot reader holds a lock on
+	// the original until it is closed.
+	sr, err := sf.SnapshotReader()
+	if err != nil {
+		return err
+	}
+	b, err := ioutil.ReadAll(sr)
+	err = errors.Compose(err, sr.Close())
+	if err != nil {
+		return errors.AddContext(err, "unable to read siafile")
+	}
+	cpy, chunks, err := siafile.LoadSiaFileFromReaderWithChunks(bytes.NewReader(b), fs.FilePath(dst), fs.staticWal)
+	if err != nil {
+		return errors.AddContext(err, "unable to load copy of siafile")
+	}
+
+	// Add the copy to its dir. The dir checks that dst is free while holding
+	// its lock, which prevents concurrent copies or uploads to dst.
+	dirSiaPath, err := dst.Dir()
+	if err != nil {
+		return err
+	}
+	if err := fs.managedNewSiaDir(dirSiaPath, cpy.Mode()); err != nil {
+		return err
+	}
+	dir, err := fs.managedOpenDir(dirSiaPath.String())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, dir.Close())
+	}()
+	return dir.managedNewSiaFileCopy(dst.Name(), cpy, chunks)
+}
@@ -0,0 +1,143 @@
+package filesystem
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+// TestCopyFile tests copying siafiles.
+func TestCopyFile(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	root := filepath.Join(testDir(t.Name()), "fs-root")
+	fs := newTestFileSystem(root)
+
+	// Create a file with a piece.
+	src := newSiaPath("src")
+	ec, err := modules.NewRSSubCode(10, 20, crypto.SegmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.NewSiaFile(src, "/local/src", ec, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), 100, persist.DefaultDiskPermissionsTest, false); err != nil {
+		t.Fatal(err)
+	}
+	var root1 crypto.Hash
+	fastrand.Read(root1[:])
+	sf, err := fs.OpenSiaFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sf.AddPiece(types.SiaPublicKey{Key: []byte{1}}, 0, 0, root1); err != nil {
+		t.Fatal(err)
+	}
+	if err := sf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Copy the file twice.
+	dst1, dst2 := newSiaPath("dir/dst1"), newSiaPath("dir/dst2")
+	for _, dst := range []modules.SiaPath{dst1, dst2} {
+		if err := fs.CopyFile(src, dst); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := fs.CopyFile(src, dst1); !errors.Contains(err, ErrExists) {
+		t.Fatal("expected ErrExists but got", err)
+	}
+	if err := fs.CopyFile(src, newSiaPath("dir")); !errors.Contains(err, ErrExists) {
+		t.Fatal("expected ErrExists but got", err)
+	}
+
+	// Only one of multiple concurrent copies to the same siapath succeeds
+	// and no copy is created under a different name.
+	dst3 := newSiaPath("dir/dst3")
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var succeeded int
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := fs.CopyFile(src, dst3)
+			if err != nil && !errors.Contains(err, ErrExists) {
+				t.Error(err)
+			}
+			mu.Lock()
+			if err == nil {
+				succeeded++
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		t.Fatalf("expected 1 successful copy but got %v", succeeded)
+	}
+	fis, err := fs.ReadDir(newSiaPath("dir"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fis) != 4 {
+		t.Fatalf("expected 3 siafiles and the dir metadata but got %v entries", len(fis))
+	}
+
+	// The copy shares the pieces and key but has a new UID and no local
+	// path.
+	orig, err := fs.OpenSiaFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpy, err := fs.OpenSiaFile(dst1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := cpy.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces[0]) != 1 || pieces[0][0].MerkleRoot != root1 {
+		t.Fatal("copy doesn't share the pieces", pieces)
+	}
+	if cpy.UID() == orig.UID() || cpy.LocalPath() != "" || cpy.Size() != orig.Size() {
+		t.Fatal("wrong copy", cpy.UID(), cpy.LocalPath(), cpy.Size())
+	}
+	if !bytes.Equal(cpy.MasterKey().Key(), orig.MasterKey().Key()) {
+		t.Fatal("copy doesn't share the key")
+	}
+	if err := errors.Compose(orig.Close(), cpy.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting the original doesn't affect the copies.
+	if err := fs.DeleteFile(src); err != nil {
+		t.Fatal(err)
+	}
+	cpy, err = fs.OpenSiaFile(dst2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err = cpy.Pieces(0)
+	err = errors.Compose(err, cpy.Close())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces[0]) != 1 || pieces[0][0].MerkleRoot != root1 {
+		t.Fatal("copy lost its pieces", pieces)
+	}
+
+	// Copying a file that doesn't exist fails.
+	if err := fs.CopyFile(src, dst1); !errors.Contains(err, ErrNotExist) {
+		t.Fatal("expected ErrNotExist but got", err)
+	}
+}
@@ -360,6 +360,33 @@ func (n *DirNode) managedNewSiaFileFromExisting(sf *siafile.SiaFile, chunks siaf
 	return nil
 }
 
+// managedNewSiaFileCopy adds a copy of an existing SiaFile to the directory
+// under the given name. Unlike managedNewSiaFileFromExisting, it fails with
+// ErrExists instead of picking a different name if the name is taken.
+func (n *DirNode) managedNewSiaFileCopy(fileName string, sf *siafile.SiaFile, chunks siafile.Chunks) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	// Make sure we don't have a file or folder with that name already.
+	if exists := n.childExists(fileName); exists {
+		return ErrExists
+	}
+	// The copy gets its own UID and no local path.
+	sf.UpdateUniqueID()
+	sf.SetSiaFilePath(filepath.Join(n.absPath(), fileName+modules.SiaFileExtension))
+	if err := sf.SaveWithChunks(chunks); err != nil {
+		return err
+	}
+	if err := sf.SetLocalPath(""); err != nil {
+		return errors.Compose(err, os.Remove(sf.SiaFilePath()))
+	}
+	// Add the node to the dir.
+	n.files[fileName] = &FileNode{
+		node:    newNode(n, sf.SiaFilePath(), fileName, 0, n.staticWal, n.staticLog),
+		SiaFile: sf,
+	}
+	return nil
+}
+
 // managedNewSiaFileFromLegacyData adds an existing SiaFile to the filesystem
 // using the provided siafile.FileData object.
 func (n *DirNode) managedNewSiaFileFromLegacyData(fileName string, fd siafile.FileData) (*FileNode, error) {
@@ -609,6 +636,9 @@ func (n *DirNode) managedInfo(siaPath modules.SiaPath) (modules.DirectoryInfo, e
 		StuckSize:           metadata.StuckSize,
 		SiaPath:             siaPath,
 		UID:                 n.staticUID,
+		StoragePolicy:       metadata.StoragePolicy,
+		UserMetadata:        metadata.UserMetadata.Copy(),
+		VersioningPolicy:    metadata.VersioningPolicy,
 	}, nil
 }
 
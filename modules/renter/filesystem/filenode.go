@@ -1,6 +1,7 @@
 package filesystem
 
 import (
+	"encoding/hex"
 	"math"
 	"os"
 	"path/filepath"
@@ -163,7 +164,14 @@ func (n *FileNode) managedFileInfo(siaPath modules.SiaPath, offline map[string]b
 		UID:              n.staticUID,
 		UploadedBytes:    uploadedBytes,
 		UploadProgress:   uploadProgress,
+		UserMetadata:     n.UserMetadata(),
 	}
+	if ci := n.CompressionInfo(); ci.Compressed() {
+		fileInfo.Compression = ci.Type
+		fileInfo.CompressedSize = fileInfo.Filesize
+		fileInfo.Filesize = ci.Size
+	}
+	setFileInfoChecksum(&fileInfo, n.ChecksumInfo())
 	return fileInfo, nil
 }
 
@@ -247,6 +255,29 @@ func (n *FileNode) staticCachedInfo(siaPath modules.SiaPath) (modules.FileInfo,
 		UID:              n.staticUID,
 		UploadedBytes:    md.CachedUploadedBytes,
 		UploadProgress:   md.CachedUploadProgress,
+		UserMetadata:     md.UserMetadata.Copy(),
+	}
+	if md.Compression != modules.CompressionNone {
+		fileInfo.Compression = md.Compression
+		fileInfo.CompressedSize = fileInfo.Filesize
+		fileInfo.Filesize = md.UncompressedSize
 	}
+	setFileInfoChecksum(&fileInfo, siafile.ChecksumInfo{
+		Type:         md.ChecksumType,
+		Checksum:     md.Checksum,
+		LastVerified: md.LastVerified,
+		VerifyFailed: md.VerifyFailed,
+	})
 	return fileInfo, nil
 }
+
+// setFileInfoChecksum fills in the checksum fields of a FileInfo.
+func setFileInfoChecksum(fileInfo *modules.FileInfo, ci siafile.ChecksumInfo) {
+	if !ci.HasChecksum() {
+		return
+	}
+	fileInfo.ChecksumType = ci.Type
+	fileInfo.Checksum = hex.EncodeToString(ci.Checksum[:])
+	fileInfo.LastVerified = ci.LastVerified
+	fileInfo.VerifyFailed = ci.VerifyFailed
+}
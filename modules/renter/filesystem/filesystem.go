@@ -40,6 +40,10 @@ type (
 	// future.
 	FileSystem struct {
 		DirNode
+
+		// staticTenants are the tenants with a namespace within the
+		// filesystem.
+		staticTenants *tenants
 	}
 
 	// node is a struct that contains the common fields of every node.
@@ -163,6 +167,10 @@ func New(root string, log *persist.Logger, wal *writeaheadlog.WAL) (*FileSystem,
 	if err != nil && !errors.Contains(err, ErrExists) {
 		return nil, err
 	}
+	fs.staticTenants, err = loadTenants(filepath.Join(root, tenantsFilename))
+	if err != nil {
+		return nil, err
+	}
 	return fs, nil
 }
 
@@ -0,0 +1,202 @@
+package filesystem
+
+// query.go contains the logic for querying the files of the filesystem. The
+// files are filtered and sorted while they are listed. If the query has a
+// limit, only the best files seen so far are kept in a heap, which bounds the
+// memory used by a query on large filesystems.
+//
+// The cursor of a query is the sort key and siapath of the last file of the
+// previous page. Only files that come after it are considered for the next
+// page, which makes paging stable as long as the sort keys don't change.
+
+import (
+	"container/heap"
+	"encoding/base64"
+	"encoding/json"
+	"sort"
+	"strings"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// ErrInvalidCursor is returned for cursors which weren't returned by a query
+// with the same sort order.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+type (
+	// fileSortKey is the key files are sorted by. Numeric fields use num,
+	// names use str. Ties are broken by siapath.
+	fileSortKey struct {
+		Num     float64 `json:"num,omitempty"`
+		Str     string  `json:"str,omitempty"`
+		SiaPath string  `json:"siapath"`
+	}
+
+	// fileCursor is the decoded cursor of a query.
+	fileCursor struct {
+		Sort       string      `json:"sort"`
+		Descending bool        `json:"descending"`
+		Key        fileSortKey `json:"key"`
+	}
+
+	// queriedFile is a file matching a query together with its sort key.
+	queriedFile struct {
+		fi  modules.FileInfo
+		key fileSortKey
+	}
+
+	// fileQueryHeap is a max-heap of the files of a query. The file that
+	// comes last in the order of the query is at the top, which allows for
+	// dropping it once the limit is exceeded.
+	fileQueryHeap struct {
+		files []queriedFile
+		less  func(a, b fileSortKey) bool
+	}
+)
+
+// Implementation of heap.Interface for fileQueryHeap.
+func (fqh fileQueryHeap) Len() int { return len(fqh.files) }
+func (fqh fileQueryHeap) Less(i, j int) bool {
+	return fqh.less(fqh.files[j].key, fqh.files[i].key)
+}
+func (fqh fileQueryHeap) Swap(i, j int)       { fqh.files[i], fqh.files[j] = fqh.files[j], fqh.files[i] }
+func (fqh *fileQueryHeap) Push(x interface{}) { fqh.files = append(fqh.files, x.(queriedFile)) }
+func (fqh *fileQueryHeap) Pop() interface{} {
+	old := fqh.files
+	n := len(old)
+	file := old[n-1]
+	fqh.files = old[:n-1]
+	return file
+}
+
+// QueryFiles returns the files within the directory at siaPath which match the
+// query in the query's order. If the query has a limit and there are more
+// matching files, a cursor for the next page is returned as well.
+func (fs *FileSystem) QueryFiles(siaPath modules.SiaPath, recursive, cached bool, q modules.FileQuery, offlineMap, goodForRenewMap map[string]bool, contractsMap map[string]modules.RenterContract) ([]modules.FileInfo, string, error) {
+	if err := modules.ValidateFileSort(q.Sort); err != nil {
+		return nil, "", err
+	}
+	if q.Limit < 0 {
+		return nil, "", errors.New("limit can't be negative")
+	}
+	less := fileSortLess(q.Descending)
+
+	// Decode the cursor.
+	var cursor *fileSortKey
+	if q.Cursor != "" {
+		fc, err := decodeFileCursor(q.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		if fc.Sort != q.Sort || fc.Descending != q.Descending {
+			return nil, "", errors.AddContext(ErrInvalidCursor, "cursor belongs to a query with a different order")
+		}
+		cursor = &fc.Key
+	}
+
+	// Collect the matching files. One more file than the limit is kept to
+	// find out whether there is another page.
+	var mu sync.Mutex
+	fqh := &fileQueryHeap{less: less}
+	flf := func(fi modules.FileInfo) {
+		if !fi.UserMetadata.HasTags(q.Tags) || !q.Filter.Match(fi) {
+			return
+		}
+		key := newFileSortKey(fi, q.Sort)
+		if cursor != nil && !less(*cursor, key) {
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		heap.Push(fqh, queriedFile{fi: fi, key: key})
+		if q.Limit > 0 && fqh.Len() > q.Limit+1 {
+			heap.Pop(fqh)
+		}
+	}
+	err := fs.managedList(siaPath, recursive, cached, offlineMap, goodForRenewMap, contractsMap, flf, func(modules.DirectoryInfo) {})
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Sort the files and create the cursor of the next page.
+	sort.Slice(fqh.files, func(i, j int) bool {
+		return less(fqh.files[i].key, fqh.files[j].key)
+	})
+	var next string
+	if q.Limit > 0 && len(fqh.files) > q.Limit {
+		fqh.files = fqh.files[:q.Limit]
+		next, err = encodeFileCursor(fileCursor{
+			Sort:       q.Sort,
+			Descending: q.Descending,
+			Key:        fqh.files[q.Limit-1].key,
+		})
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	files := make([]modules.FileInfo, 0, len(fqh.files))
+	for _, file := range fqh.files {
+		files = append(files, file.fi)
+	}
+	return files, next, nil
+}
+
+// newFileSortKey returns the key of a file for the given sort field.
+func newFileSortKey(fi modules.FileInfo, field string) fileSortKey {
+	key := fileSortKey{SiaPath: fi.SiaPath.String()}
+	switch field {
+	case modules.FileFieldHealth:
+		key.Num = fi.Health
+	case modules.FileFieldModTime:
+		key.Num = float64(fi.ModificationTime.UnixNano())
+	case modules.FileFieldName:
+		key.Str = fi.Name()
+	case modules.FileFieldRedundancy:
+		key.Num = fi.Redundancy
+	case modules.FileFieldSize:
+		key.Num = float64(fi.Filesize)
+	}
+	return key
+}
+
+// fileSortLess returns a function which returns true if a file with key a
+// comes before a file with key b.
+func fileSortLess(descending bool) func(a, b fileSortKey) bool {
+	return func(a, b fileSortKey) bool {
+		if a == b {
+			return false
+		}
+		var aLess bool
+		if a.Num != b.Num {
+			aLess = a.Num < b.Num
+		} else if a.Str != b.Str {
+			aLess = a.Str < b.Str
+		} else {
+			aLess = a.SiaPath < b.SiaPath
+		}
+		return aLess != descending
+	}
+}
+
+// encodeFileCursor encodes a cursor as an opaque string.
+func encodeFileCursor(fc fileCursor) (string, error) {
+	b, err := json.Marshal(fc)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// decodeFileCursor decodes a cursor created by encodeFileCursor.
+func decodeFileCursor(s string) (fc fileCursor, err error) {
+	b, err := base64.RawURLEncoding.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return fileCursor{}, errors.Compose(ErrInvalidCursor, err)
+	}
+	if err := json.Unmarshal(b, &fc); err != nil {
+		return fileCursor{}, errors.Compose(ErrInvalidCursor, err)
+	}
+	return fc, nil
+}
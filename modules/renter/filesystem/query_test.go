@@ -0,0 +1,114 @@
+package filesystem
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+)
+
+// TestQueryFiles tests filtering, sorting and paging through the files of a
+// filesystem.
+func TestQueryFiles(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	fs := newTestFileSystem(filepath.Join(testDir(t.Name()), "fs-root"))
+	ec, err := modules.NewRSSubCode(10, 20, crypto.SegmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Add files of different sizes. The files are created in a different
+	// order than their size to make sure that the results are sorted.
+	sizes := []uint64{30, 10, 50, 0, 40, 20}
+	for i, size := range sizes {
+		sp := newSiaPath(fmt.Sprintf("dir/sub%v/file%v", i%2, i))
+		err := fs.NewSiaFile(sp, "", ec, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), size, persist.DefaultDiskPermissionsTest, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	filter, err := modules.ParseFileFilter("size>=10,name=file*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := func(q modules.FileQuery) ([]modules.FileInfo, string, error) {
+		return fs.QueryFiles(newSiaPath("dir"), true, true, q, nil, nil, nil)
+	}
+
+	// Page through the files by descending size.
+	q := modules.FileQuery{
+		Filter:     filter,
+		Sort:       modules.FileFieldSize,
+		Descending: true,
+		Limit:      2,
+	}
+	var found []uint64
+	for pages := 0; ; pages++ {
+		if pages > len(sizes) {
+			t.Fatal("too many pages")
+		}
+		files, next, err := query(q)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, fi := range files {
+			found = append(found, fi.Filesize)
+		}
+		if next == "" {
+			break
+		}
+		q.Cursor = next
+	}
+	expected := []uint64{50, 40, 30, 20, 10}
+	if fmt.Sprint(found) != fmt.Sprint(expected) {
+		t.Fatalf("expected sizes %v but got %v", expected, found)
+	}
+
+	// Without a limit all files are returned in ascending order by siapath.
+	files, next, err := query(modules.FileQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(sizes) || next != "" {
+		t.Fatalf("expected %v files without a cursor but got %v files and cursor '%v'", len(sizes), len(files), next)
+	}
+	for i := 1; i < len(files); i++ {
+		if files[i-1].SiaPath.String() >= files[i].SiaPath.String() {
+			t.Fatal("files aren't sorted by siapath", files[i-1].SiaPath, files[i].SiaPath)
+		}
+	}
+
+	// A non-recursive query only returns the files of the directory itself.
+	files, _, err = fs.QueryFiles(newSiaPath("dir/sub0"), false, true, modules.FileQuery{}, nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != len(sizes)/2 {
+		t.Fatalf("expected %v files but got %v", len(sizes)/2, len(files))
+	}
+
+	// Invalid queries are rejected.
+	_, next, err = query(modules.FileQuery{Sort: modules.FileFieldSize, Limit: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := query(modules.FileQuery{Sort: modules.FileFieldHealth, Cursor: next}); !errors.Contains(err, ErrInvalidCursor) {
+		t.Fatal("expected ErrInvalidCursor for cursor of different order but got", err)
+	}
+	if _, _, err := query(modules.FileQuery{Cursor: "not a cursor"}); !errors.Contains(err, ErrInvalidCursor) {
+		t.Fatal("expected ErrInvalidCursor but got", err)
+	}
+	if _, _, err := query(modules.FileQuery{Sort: modules.FileFieldStuck}); !errors.Contains(err, modules.ErrInvalidFileSort) {
+		t.Fatal("expected ErrInvalidFileSort but got", err)
+	}
+	if _, _, err := query(modules.FileQuery{Limit: -1}); err == nil {
+		t.Fatal("expected error for negative limit")
+	}
+}
@@ -150,6 +150,9 @@ func (sd *SiaDir) UpdateBubbledMetadata(metadata Metadata) error {
 	defer sd.mu.Unlock()
 	metadata.Mode = sd.metadata.Mode
 	metadata.Version = sd.metadata.Version
+	metadata.VersioningPolicy = sd.metadata.VersioningPolicy
+	metadata.StoragePolicy = sd.metadata.StoragePolicy
+	metadata.UserMetadata = sd.metadata.UserMetadata
 	return sd.updateMetadata(metadata)
 }
 
@@ -206,6 +209,7 @@ func (sd *SiaDir) updateMetadata(metadata Metadata) error {
 	sd.metadata.AggregateNumStuckChunks = metadata.AggregateNumStuckChunks
 	sd.metadata.AggregateNumSubDirs = metadata.AggregateNumSubDirs
 	sd.metadata.AggregateRemoteHealth = metadata.AggregateRemoteHealth
+	sd.metadata.AggregateRepairHealth = metadata.AggregateRepairHealth
 	sd.metadata.AggregateRepairSize = metadata.AggregateRepairSize
 	sd.metadata.AggregateSize = metadata.AggregateSize
 	sd.metadata.AggregateStuckHealth = metadata.AggregateStuckHealth
@@ -226,6 +230,9 @@ func (sd *SiaDir) updateMetadata(metadata Metadata) error {
 	sd.metadata.StuckSize = metadata.StuckSize
 
 	sd.metadata.Version = metadata.Version
+	sd.metadata.VersioningPolicy = metadata.VersioningPolicy
+	sd.metadata.StoragePolicy = metadata.StoragePolicy
+	sd.metadata.UserMetadata = metadata.UserMetadata
 
 	// Testing check to ensure new fields aren't missed
 	if build.Release == "testing" && !reflect.DeepEqual(sd.metadata, metadata) {
@@ -54,6 +54,12 @@ type (
 		//
 		// StuckHealth is the health of the most in need siafile in the siadir,
 		// stuck or not stuck
+		//
+		// AggregateRepairHealth only exists as an aggregate value. It is the
+		// health of the most in need siafile that is not stuck, scaled by the
+		// repair threshold of the storage policy that applies to the siafile.
+		// A siafile needs to be repaired if its scaled health is at or above
+		// modules.RepairThreshold.
 
 		// The following fields are aggregate values of the siadir. These values are
 		// the totals of the siadir and any sub siadirs, or are calculated based on
@@ -66,6 +72,7 @@ type (
 		AggregateNumStuckChunks      uint64    `json:"aggregatenumstuckchunks"`
 		AggregateNumSubDirs          uint64    `json:"aggregatenumsubdirs"`
 		AggregateRemoteHealth        float64   `json:"aggregateremotehealth"`
+		AggregateRepairHealth        float64   `json:"aggregaterepairhealth"`
 		AggregateRepairSize          uint64    `json:"aggregaterepairsize"`
 		AggregateSize                uint64    `json:"aggregatesize"`
 		AggregateStuckHealth         float64   `json:"aggregatestuckhealth"`
@@ -89,6 +96,18 @@ type (
 
 		// Version is the used version of the header file.
 		Version string `json:"version"`
+
+		// VersioningPolicy determines how many prior versions of the files
+		// within the siadir are kept. It isn't bubbled.
+		VersioningPolicy modules.VersioningPolicy `json:"versioningpolicy"`
+
+		// StoragePolicy determines how the files within the siadir are stored
+		// and repaired. It isn't bubbled.
+		StoragePolicy modules.StoragePolicy `json:"storagepolicy"`
+
+		// UserMetadata is the application defined metadata of the siadir. It
+		// isn't bubbled.
+		UserMetadata modules.UserMetadata `json:"usermetadata,omitempty"`
 	}
 )
 
@@ -0,0 +1,93 @@
+package siafile
+
+import (
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// ChecksumInfo describes the whole-file checksum of a file and the outcome of
+// the last verification of the file's data against it.
+type ChecksumInfo struct {
+	Type     modules.ChecksumType
+	Checksum crypto.Hash
+
+	LastVerified time.Time
+	VerifyFailed bool
+}
+
+// HasChecksum returns true if a checksum was computed for the file.
+func (ci ChecksumInfo) HasChecksum() bool {
+	return ci.Type != "" && ci.Type != modules.ChecksumNone
+}
+
+// ChecksumInfo returns the whole-file checksum of the file.
+func (sf *SiaFile) ChecksumInfo() ChecksumInfo {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return ChecksumInfo{
+		Type:         sf.staticMetadata.ChecksumType,
+		Checksum:     sf.staticMetadata.Checksum,
+		LastVerified: sf.staticMetadata.LastVerified,
+		VerifyFailed: sf.staticMetadata.VerifyFailed,
+	}
+}
+
+// SetChecksum sets the whole-file checksum of the file. Setting a checksum
+// resets the outcome of prior verifications.
+func (sf *SiaFile) SetChecksum(ct modules.ChecksumType, checksum crypto.Hash) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if sf.deleted {
+		return errors.AddContext(ErrDeleted, "can't set checksum of deleted file")
+	}
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.ChecksumType = ct
+	sf.staticMetadata.Checksum = checksum
+	sf.staticMetadata.LastVerified = time.Time{}
+	sf.staticMetadata.VerifyFailed = false
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	return sf.createAndApplyTransaction(updates...)
+}
+
+// SetVerified records the outcome of verifying the file's data against its
+// checksum.
+func (sf *SiaFile) SetVerified(verifiedTime time.Time, failed bool) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if sf.deleted {
+		return errors.AddContext(ErrDeleted, "can't set verification of deleted file")
+	}
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.LastVerified = verifiedTime
+	sf.staticMetadata.VerifyFailed = failed
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	return sf.createAndApplyTransaction(updates...)
+}
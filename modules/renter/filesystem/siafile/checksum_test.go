@@ -0,0 +1,54 @@
+package siafile
+
+import (
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+// TestChecksumInfo tests setting and persisting the checksum of a file and the
+// outcome of its verification.
+func TestChecksumInfo(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	sf, wal, _ := newBlankTestFileAndWAL(1)
+	if sf.ChecksumInfo().HasChecksum() {
+		t.Fatal("new file shouldn't have a checksum")
+	}
+
+	// Set a checksum and a failed verification.
+	checksum := crypto.HashBytes([]byte("data"))
+	if err := sf.SetChecksum(modules.ChecksumSHA256, checksum); err != nil {
+		t.Fatal(err)
+	}
+	verifiedTime := time.Unix(time.Now().Unix(), 0)
+	if err := sf.SetVerified(verifiedTime, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// The checksum and verification are persisted.
+	sf, err := loadSiaFile(sf.siaFilePath, wal, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ci := sf.ChecksumInfo()
+	if !ci.HasChecksum() || ci.Type != modules.ChecksumSHA256 || ci.Checksum != checksum {
+		t.Fatal("checksum wasn't persisted", ci)
+	}
+	if !ci.LastVerified.Equal(verifiedTime) || !ci.VerifyFailed {
+		t.Fatal("verification wasn't persisted", ci)
+	}
+
+	// A new checksum resets the verification.
+	if err := sf.SetChecksum(modules.ChecksumBLAKE2b, checksum); err != nil {
+		t.Fatal(err)
+	}
+	if ci := sf.ChecksumInfo(); !ci.LastVerified.IsZero() || ci.VerifyFailed {
+		t.Fatal("verification wasn't reset", ci)
+	}
+}
@@ -0,0 +1,111 @@
+package siafile
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+)
+
+// CompressionIndexEntrySize is the size of an entry of the index of a
+// compressed file.
+const CompressionIndexEntrySize = 8
+
+// CompressionInfo describes how the data of a compressed file is laid out. The
+// uncompressed data is split into blocks of BlockSize bytes which are
+// compressed independently and stored back to back. This allows for reading a
+// range of the uncompressed data by only fetching and decompressing the blocks
+// that overlap the range.
+//
+// The blocks are followed by an index that contains the end offset of every
+// block within the compressed data as a little endian uint64. The index is
+// uploaded together with the blocks instead of being stored in the metadata
+// since its size grows with the size of the file.
+type CompressionInfo struct {
+	Type      modules.CompressionType
+	BlockSize uint64
+
+	// Size is the size of the uncompressed data.
+	Size uint64
+}
+
+// Compressed returns true if the data is compressed.
+func (ci CompressionInfo) Compressed() bool {
+	return ci.Type != modules.CompressionNone
+}
+
+// NumBlocks returns the number of compressed blocks.
+func (ci CompressionInfo) NumBlocks() uint64 {
+	if ci.BlockSize == 0 {
+		return 0
+	}
+	return (ci.Size + ci.BlockSize - 1) / ci.BlockSize
+}
+
+// IndexSize returns the size of the index that follows the compressed blocks.
+func (ci CompressionInfo) IndexSize() uint64 {
+	return ci.NumBlocks() * CompressionIndexEntrySize
+}
+
+// BlockRange returns the range of blocks [firstBlock, endBlock) that contain
+// the uncompressed range [offset, offset+length).
+func (ci CompressionInfo) BlockRange(offset, length uint64) (firstBlock, endBlock uint64) {
+	if length == 0 || ci.BlockSize == 0 {
+		return 0, 0
+	}
+	firstBlock = offset / ci.BlockSize
+	endBlock = (offset + length + ci.BlockSize - 1) / ci.BlockSize
+	if numBlocks := ci.NumBlocks(); endBlock > numBlocks {
+		endBlock = numBlocks
+	}
+	return firstBlock, endBlock
+}
+
+// CompressionInfo returns how the data of the file is compressed.
+func (sf *SiaFile) CompressionInfo() CompressionInfo {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.staticMetadata.compressionInfo()
+}
+
+// SetCompressionInfo records how the data of the file is compressed. The file
+// needs to be large enough to contain the index of the compressed blocks.
+func (sf *SiaFile) SetCompressionInfo(ci CompressionInfo) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if sf.deleted {
+		return errors.AddContext(ErrDeleted, "can't set compression info of deleted file")
+	}
+	if ci.Compressed() && ci.BlockSize == 0 {
+		return errors.New("block size of compressed file can't be zero")
+	}
+	if ci.IndexSize() > uint64(sf.staticMetadata.FileSize) {
+		return errors.New("file is too small to contain the index of the compressed blocks")
+	}
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.Compression = ci.Type
+	sf.staticMetadata.CompressionBlockSize = ci.BlockSize
+	sf.staticMetadata.UncompressedSize = ci.Size
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	return sf.createAndApplyTransaction(updates...)
+}
+
+// compressionInfo returns the compression info of the metadata.
+func (md *Metadata) compressionInfo() CompressionInfo {
+	return CompressionInfo{
+		Type:      md.Compression,
+		BlockSize: md.CompressionBlockSize,
+		Size:      md.UncompressedSize,
+	}
+}
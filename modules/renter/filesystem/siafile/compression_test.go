@@ -0,0 +1,81 @@
+package siafile
+
+import (
+	"reflect"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+)
+
+// TestCompressionInfo tests setting and persisting the compression info of a
+// file.
+func TestCompressionInfo(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	sf, wal, _ := newBlankTestFileAndWAL(1)
+	if sf.CompressionInfo().Compressed() {
+		t.Fatal("new file shouldn't be compressed")
+	}
+
+	// The file needs to be large enough to contain the index.
+	size := sf.Size()
+	ci := CompressionInfo{
+		Type:      modules.CompressionGzip,
+		BlockSize: 100,
+		Size:      100,
+	}
+	tooLarge := CompressionInfo{Type: modules.CompressionGzip, BlockSize: 1, Size: size/CompressionIndexEntrySize + 1}
+	if err := sf.SetCompressionInfo(tooLarge); err == nil {
+		t.Fatal("index not fitting into the file should be rejected")
+	}
+	if err := sf.SetCompressionInfo(CompressionInfo{Type: modules.CompressionGzip, Size: 100}); err == nil {
+		t.Fatal("zero block size should be rejected")
+	}
+	if err := sf.SetCompressionInfo(ci); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sf.CompressionInfo(), ci) {
+		t.Fatal("compression info doesn't match", sf.CompressionInfo(), ci)
+	}
+
+	// The compression info is persisted.
+	sf, err := loadSiaFile(sf.siaFilePath, wal, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(sf.CompressionInfo(), ci) {
+		t.Fatal("compression info wasn't persisted", sf.CompressionInfo(), ci)
+	}
+}
+
+// TestBlockRange tests the BlockRange method of CompressionInfo.
+func TestBlockRange(t *testing.T) {
+	ci := CompressionInfo{
+		Type:      modules.CompressionGzip,
+		BlockSize: 10,
+		Size:      35,
+	}
+	if ci.NumBlocks() != 4 || ci.IndexSize() != 4*CompressionIndexEntrySize {
+		t.Fatal("unexpected number of blocks", ci.NumBlocks(), ci.IndexSize())
+	}
+	tests := []struct {
+		offset, length uint64
+		first, end     uint64
+	}{
+		{0, 35, 0, 4},
+		{0, 1, 0, 1},
+		{9, 2, 0, 2},
+		{10, 10, 1, 2},
+		{25, 10, 2, 4},
+		{0, 0, 0, 0},
+	}
+	for _, test := range tests {
+		first, end := ci.BlockRange(test.offset, test.length)
+		if first != test.first || end != test.end {
+			t.Errorf("BlockRange(%v, %v) = %v %v", test.offset, test.length, first, end)
+		}
+	}
+}
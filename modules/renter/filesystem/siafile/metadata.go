@@ -55,6 +55,27 @@ type (
 		StaticSharingKey     []byte            `json:"sharingkey"` // key used to encrypt shared pieces
 		StaticSharingKeyType crypto.CipherType `json:"sharingkeytype"`
 
+		// Fields for compressed files. The data of a compressed file is split
+		// into blocks of CompressionBlockSize bytes which are compressed
+		// independently. UncompressedSize is the size of the data before
+		// compression. FileSize is the size of the compressed data including
+		// the index of the blocks that follows them.
+		Compression          modules.CompressionType `json:"compression,omitempty"`
+		CompressionBlockSize uint64                  `json:"compressionblocksize,omitempty"`
+		UncompressedSize     uint64                  `json:"uncompressedsize,omitempty"`
+
+		// Fields for the whole-file checksum. The checksum is computed over
+		// the uncompressed data of the file during the upload. LastVerified
+		// and VerifyFailed record the outcome of the last verification of the
+		// data on the network against the checksum.
+		ChecksumType modules.ChecksumType `json:"checksumtype,omitempty"`
+		Checksum     crypto.Hash          `json:"checksum"`
+		LastVerified time.Time            `json:"lastverified"`
+		VerifyFailed bool                 `json:"verifyfailed,omitempty"`
+
+		// UserMetadata is the application defined metadata of the file.
+		UserMetadata modules.UserMetadata `json:"usermetadata,omitempty"`
+
 		// Fields for partial uploads
 		DisablePartialChunk bool               `json:"disablepartialchunk"` // determines whether the file should be treated like legacy files
 		PartialChunks       []PartialChunkInfo `json:"partialchunks"`       // information about the partial chunk.
@@ -337,6 +358,14 @@ func (md Metadata) backup() (b Metadata) {
 	b.GroupID = md.GroupID
 	b.ChunkOffset = md.ChunkOffset
 	b.PubKeyTableOffset = md.PubKeyTableOffset
+	b.Compression = md.Compression
+	b.CompressionBlockSize = md.CompressionBlockSize
+	b.UncompressedSize = md.UncompressedSize
+	b.ChecksumType = md.ChecksumType
+	b.Checksum = md.Checksum
+	b.LastVerified = md.LastVerified
+	b.VerifyFailed = md.VerifyFailed
+	b.UserMetadata = md.UserMetadata.Copy()
 	// Special handling for slice since reflect.DeepEqual is false when
 	// comparing empty slice to nil.
 	if md.PartialChunks == nil {
@@ -389,6 +418,14 @@ func (md *Metadata) restore(b Metadata) {
 	md.GroupID = b.GroupID
 	md.ChunkOffset = b.ChunkOffset
 	md.PubKeyTableOffset = b.PubKeyTableOffset
+	md.Compression = b.Compression
+	md.CompressionBlockSize = b.CompressionBlockSize
+	md.UncompressedSize = b.UncompressedSize
+	md.ChecksumType = b.ChecksumType
+	md.Checksum = b.Checksum
+	md.LastVerified = b.LastVerified
+	md.VerifyFailed = b.VerifyFailed
+	md.UserMetadata = b.UserMetadata
 	// If the backup was successful it should match the backup.
 	if build.Release == "testing" && !md.equals(b) {
 		fmt.Println("md:\n", md)
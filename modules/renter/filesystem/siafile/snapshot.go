@@ -30,6 +30,7 @@ type (
 		staticLocalPath       string
 		staticPartialChunks   []PartialChunkInfo
 		staticUID             SiafileUID
+		staticCompression     CompressionInfo
 	}
 )
 
@@ -118,6 +119,11 @@ func (s *Snapshot) PartialChunks() []PartialChunkInfo {
 	return s.staticPartialChunks
 }
 
+// CompressionInfo returns how the data of the file is compressed.
+func (s *Snapshot) CompressionInfo() CompressionInfo {
+	return s.staticCompression
+}
+
 // ErasureCode returns the erasure coder used by the file.
 func (s *Snapshot) ErasureCode() modules.ErasureCoder {
 	return s.staticErasureCode
@@ -294,6 +300,7 @@ func (sf *SiaFile) readlockSnapshot(sp modules.SiaPath, chunks []chunk) (*Snapsh
 		staticSiaPath:         sp,
 		staticLocalPath:       localPath,
 		staticUID:             uid,
+		staticCompression:     sf.staticMetadata.compressionInfo(),
 	}, nil
 }
 
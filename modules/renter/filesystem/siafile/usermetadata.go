@@ -0,0 +1,44 @@
+package siafile
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+)
+
+// UserMetadata returns a copy of the application defined metadata of the file.
+func (sf *SiaFile) UserMetadata() modules.UserMetadata {
+	sf.mu.RLock()
+	defer sf.mu.RUnlock()
+	return sf.staticMetadata.UserMetadata.Copy()
+}
+
+// UpdateUserMetadata applies the entries of update to the user metadata of the
+// file. Entries with an empty value remove the key.
+func (sf *SiaFile) UpdateUserMetadata(update modules.UserMetadata) (err error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	if sf.deleted {
+		return errors.AddContext(ErrDeleted, "can't set user metadata of deleted file")
+	}
+	um := sf.staticMetadata.UserMetadata.Update(update)
+	if err := um.Validate(); err != nil {
+		return err
+	}
+	// backup the changed metadata before changing it. Revert the change on
+	// error.
+	defer func(backup Metadata) {
+		if err != nil {
+			sf.staticMetadata.restore(backup)
+		}
+	}(sf.staticMetadata.backup())
+
+	sf.staticMetadata.UserMetadata = um
+
+	// Save changes to metadata to disk.
+	updates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return err
+	}
+	return sf.createAndApplyTransaction(updates...)
+}
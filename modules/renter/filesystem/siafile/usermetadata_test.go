@@ -0,0 +1,56 @@
+package siafile
+
+import (
+	"reflect"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+)
+
+// TestUpdateUserMetadata tests updating and persisting the user metadata of a
+// file.
+func TestUpdateUserMetadata(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	sf, wal, _ := newBlankTestFileAndWAL(1)
+	if um := sf.UserMetadata(); um != nil {
+		t.Fatal("new file shouldn't have user metadata", um)
+	}
+
+	// Set some metadata.
+	expected := modules.UserMetadata{"contenttype": "text/plain", "retention": "30d"}
+	if err := sf.UpdateUserMetadata(expected); err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid updates are rejected and don't change the metadata.
+	if err := sf.UpdateUserMetadata(modules.UserMetadata{"a=b": "c"}); err == nil {
+		t.Fatal("invalid update should fail")
+	}
+
+	// The metadata is persisted.
+	sf, err := loadSiaFile(sf.siaFilePath, wal, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if um := sf.UserMetadata(); !reflect.DeepEqual(um, expected) {
+		t.Fatal("user metadata wasn't persisted", um)
+	}
+
+	// Modifying the returned metadata doesn't change the file's metadata.
+	sf.UserMetadata()["contenttype"] = "image/png"
+	if um := sf.UserMetadata(); um["contenttype"] != "text/plain" {
+		t.Fatal("user metadata was modified", um)
+	}
+
+	// Entries can be removed.
+	if err := sf.UpdateUserMetadata(modules.UserMetadata{"retention": ""}); err != nil {
+		t.Fatal(err)
+	}
+	if um := sf.UserMetadata(); len(um) != 1 || um["contenttype"] != "text/plain" {
+		t.Fatal("entry wasn't removed", um)
+	}
+}
@@ -0,0 +1,71 @@
+package filesystem
+
+// storagepolicy.go contains the logic for setting and resolving the storage
+// policies of directories. A policy applies to all files within the directory
+// and its subdirectories, unless a subdirectory has a policy of its own.
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// StoragePolicy returns the storage policy that applies to the files within
+// the directory at the given siapath. If the directory doesn't have a policy,
+// the policy of the closest ancestor with a policy is returned.
+func (fs *FileSystem) StoragePolicy(dirSiaPath modules.SiaPath) (modules.StoragePolicy, error) {
+	for {
+		policy, err := fs.managedDirStoragePolicy(dirSiaPath)
+		if err != nil && !errors.Contains(err, ErrNotExist) {
+			return modules.StoragePolicy{}, err
+		}
+		if err == nil && policy.Active() {
+			return policy, nil
+		}
+		if dirSiaPath.IsRoot() {
+			return modules.StoragePolicy{}, nil
+		}
+		dirSiaPath, err = dirSiaPath.Dir()
+		if err != nil {
+			return modules.StoragePolicy{}, err
+		}
+	}
+}
+
+// SetStoragePolicy sets the storage policy of the directory at the given
+// siapath. The policy also applies to all subdirectories without a policy of
+// their own. An inactive policy removes the directory's policy.
+func (fs *FileSystem) SetStoragePolicy(dirSiaPath modules.SiaPath, policy modules.StoragePolicy) (err error) {
+	if err := policy.Validate(); err != nil {
+		return errors.AddContext(err, "invalid storage policy")
+	}
+	dir, err := fs.managedOpenSiaDir(dirSiaPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, dir.Close())
+	}()
+	md, err := dir.Metadata()
+	if err != nil {
+		return err
+	}
+	md.StoragePolicy = policy
+	return dir.UpdateMetadata(md)
+}
+
+// managedDirStoragePolicy returns the storage policy set on the directory at
+// the given siapath.
+func (fs *FileSystem) managedDirStoragePolicy(dirSiaPath modules.SiaPath) (_ modules.StoragePolicy, err error) {
+	dir, err := fs.managedOpenSiaDir(dirSiaPath)
+	if err != nil {
+		return modules.StoragePolicy{}, err
+	}
+	defer func() {
+		err = errors.Compose(err, dir.Close())
+	}()
+	md, err := dir.Metadata()
+	if err != nil {
+		return modules.StoragePolicy{}, err
+	}
+	return md.StoragePolicy, nil
+}
@@ -0,0 +1,100 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestStoragePolicy tests setting storage policies and their inheritance.
+func TestStoragePolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	fs := newTestFileSystem(filepath.Join(testDir(t.Name()), "fs-root"))
+
+	// Create a dir tree.
+	if err := fs.NewSiaDir(newSiaPath("a/b/c"), modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// There is no policy by default.
+	policy, err := fs.StoragePolicy(newSiaPath("a/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Active() {
+		t.Fatal("there shouldn't be a policy", policy)
+	}
+
+	// Set a policy on 'a'. It should be inherited by its subdirs.
+	expected := modules.StoragePolicy{DataPieces: 2, ParityPieces: 4, RepairThreshold: 0.1}
+	if err := fs.SetStoragePolicy(newSiaPath("a"), expected); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = fs.StoragePolicy(newSiaPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(policy, expected) {
+		t.Fatal("wrong policy", policy)
+	}
+	policy, err = fs.StoragePolicy(modules.RootSiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Active() {
+		t.Fatal("root shouldn't have a policy", policy)
+	}
+
+	// A policy on a subdir takes precedence.
+	own := modules.StoragePolicy{RepairPriority: 1, CipherType: "plaintext"}
+	if err := fs.SetStoragePolicy(newSiaPath("a/b"), own); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = fs.StoragePolicy(newSiaPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(policy, own) {
+		t.Fatal("wrong policy", policy)
+	}
+
+	// The policy is part of the directory info.
+	di, err := fs.DirInfo(newSiaPath("a/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(di.StoragePolicy, own) {
+		t.Fatal("policy missing from dir info", di.StoragePolicy)
+	}
+
+	// Invalid policies are rejected.
+	invalid := []modules.StoragePolicy{
+		{DataPieces: 1},
+		{CipherType: "unknown"},
+		{RepairThreshold: 1},
+		{DataPieces: 1, ParityPieces: 1, AllowedHosts: []types.SiaPublicKey{{}}},
+	}
+	for _, p := range invalid {
+		if err := fs.SetStoragePolicy(newSiaPath("a"), p); err == nil {
+			t.Fatal("invalid policy should be rejected", p)
+		}
+	}
+
+	// Removing the policy of 'a/b' restores the inherited policy.
+	if err := fs.SetStoragePolicy(newSiaPath("a/b"), modules.StoragePolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = fs.StoragePolicy(newSiaPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(policy, expected) {
+		t.Fatal("wrong policy", policy)
+	}
+}
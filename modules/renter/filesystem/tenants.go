@@ -0,0 +1,640 @@
+package filesystem
+
+// tenants.go contains the namespaces of the renter's tenants. Every tenant has
+// its own directory within the tenants folder together with quotas for the
+// storage, uploads and downloads of the files within that directory.
+//
+// The storage of a tenant is the combined size of its files, including the
+// prior versions of its files within the VersionsFolder, and computed from the
+// filesystem when it is needed. While uploads of a tenant are in progress, the
+// storage of the tenant is the storage when the first of them began, without
+// the files replaced by the uploads, plus the data reserved by all of them.
+// That way concurrent uploads can't exceed the storage quota together, even
+// though their files only grow as the data is uploaded. The storage is
+// computed without holding the lock of the tenants, other uploads of the same
+// tenant wait for it. The uploaded and downloaded bytes are counted as the
+// data is read and only persisted occasionally, since persisting them on every
+// read would be too expensive. An unclean shutdown can therefore lose the most
+// recent usage.
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+)
+
+const (
+	// tenantsFilename is the name of the file within the root of the
+	// filesystem that the tenants are persisted to.
+	tenantsFilename = ".tenants"
+)
+
+var (
+	// ErrUnknownTenant is returned if a tenant doesn't exist.
+	ErrUnknownTenant = errors.New("unknown tenant")
+
+	// ErrStorageQuotaExceeded is returned if a file would exceed the storage
+	// quota of its tenant.
+	ErrStorageQuotaExceeded = errors.New("storage quota of tenant exceeded")
+
+	// ErrUploadQuotaExceeded is returned if an upload would exceed the upload
+	// quota of its tenant.
+	ErrUploadQuotaExceeded = errors.New("upload quota of tenant exceeded")
+
+	// ErrDownloadQuotaExceeded is returned if a download would exceed the
+	// download quota of its tenant.
+	ErrDownloadQuotaExceeded = errors.New("download quota of tenant exceeded")
+
+	// tenantsMetadata is the metadata of the persisted tenants.
+	tenantsMetadata = persist.Metadata{
+		Header:  "Tenants",
+		Version: "1.0",
+	}
+
+	// tenantsPersistInterval is the interval at which changes to the usage of
+	// the tenants are persisted.
+	tenantsPersistInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 5 * time.Minute,
+		Testnet:  5 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+)
+
+type (
+	// tenants tracks the tenants of the filesystem and their usage.
+	tenants struct {
+		tenants       map[string]*persistTenant
+		uploads       map[string]*tenantUploads
+		lastPersisted time.Time
+
+		staticPath string
+		mu         sync.Mutex
+	}
+
+	// persistTenant is a tenant as it is persisted to disk.
+	persistTenant struct {
+		modules.Tenant
+		Uploaded   uint64 `json:"uploaded"`
+		Downloaded uint64 `json:"downloaded"`
+	}
+
+	// tenantUploads tracks the storage reserved by the uploads of a tenant
+	// that are in progress.
+	tenantUploads struct {
+		active   int
+		base     uint64
+		replaced map[modules.SiaPath]uint64
+		reserved uint64
+
+		// ready is closed once base was computed by the first upload. err is
+		// set if that failed.
+		ready chan struct{}
+		err   error
+	}
+
+	// TenantUpload reserves the storage and upload quotas of a tenant for an
+	// upload within the tenant's namespace. Uploads outside of the namespace
+	// of a tenant aren't limited.
+	TenantUpload struct {
+		closed   bool
+		credited bool
+		reserved uint64
+		uploaded uint64
+
+		staticName    string
+		staticSiaPath modules.SiaPath
+		staticTenants *tenants
+	}
+)
+
+// loadTenants loads the tenants from the given path.
+func loadTenants(path string) (*tenants, error) {
+	t := &tenants{
+		tenants:    make(map[string]*persistTenant),
+		uploads:    make(map[string]*tenantUploads),
+		staticPath: path,
+	}
+	var pts []persistTenant
+	err := persist.LoadJSON(tenantsMetadata, &pts, path)
+	if os.IsNotExist(err) {
+		return t, nil
+	}
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load tenants")
+	}
+	for i := range pts {
+		t.tenants[pts[i].Name] = &pts[i]
+	}
+	return t, nil
+}
+
+// save persists the tenants.
+func (t *tenants) save() error {
+	pts := make([]persistTenant, 0, len(t.tenants))
+	for _, pt := range t.tenants {
+		pts = append(pts, *pt)
+	}
+	sort.Slice(pts, func(i, j int) bool {
+		return pts[i].Name < pts[j].Name
+	})
+	t.lastPersisted = time.Now()
+	return persist.SaveJSON(tenantsMetadata, pts, t.staticPath)
+}
+
+// managedRecord adds n bytes to the usage returned by usage if that doesn't
+// exceed the quota returned by quota. The changed usage is persisted
+// occasionally.
+func (t *tenants) managedRecord(name string, n uint64, usage func(*persistTenant) *uint64, quota func(*persistTenant) uint64, errQuota error) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pt, exists := t.tenants[name]
+	if !exists {
+		return nil
+	}
+	u := usage(pt)
+	if q := quota(pt); q > 0 && *u+n > q {
+		return errQuota
+	}
+	*u += n
+	return t.saveOccasionally()
+}
+
+// saveOccasionally persists the tenants if they weren't persisted within the
+// last tenantsPersistInterval.
+func (t *tenants) saveOccasionally() error {
+	if time.Since(t.lastPersisted) < tenantsPersistInterval {
+		return nil
+	}
+	return errors.AddContext(t.save(), "unable to persist tenant usage")
+}
+
+// storage returns the storage of the tenant while the uploads are in
+// progress.
+func (tu *tenantUploads) storage() uint64 {
+	storage := tu.base + tu.reserved
+	for _, size := range tu.replaced {
+		if size > storage {
+			return 0
+		}
+		storage -= size
+	}
+	return storage
+}
+
+// managedTenantOf returns the tenant whose namespace contains the siapath.
+func (t *tenants) managedTenantOf(siaPath modules.SiaPath) (persistTenant, bool) {
+	prefix := modules.TenantsFolder.String() + "/"
+	if !strings.HasPrefix(siaPath.String(), prefix) {
+		return persistTenant{}, false
+	}
+	name := strings.SplitN(strings.TrimPrefix(siaPath.String(), prefix), "/", 2)[0]
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pt, exists := t.tenants[name]
+	if !exists {
+		return persistTenant{}, false
+	}
+	return *pt, true
+}
+
+// SetTenant creates a tenant together with the root of its namespace or
+// updates the quotas of an existing tenant.
+func (fs *FileSystem) SetTenant(tenant modules.Tenant) error {
+	if err := tenant.Validate(); err != nil {
+		return err
+	}
+	root, err := tenant.Root()
+	if err != nil {
+		return err
+	}
+	if err := fs.NewSiaDir(root, modules.DefaultDirPerm); err != nil && !errors.Contains(err, ErrExists) {
+		return errors.AddContext(err, "unable to create root of tenant")
+	}
+
+	fs.staticTenants.mu.Lock()
+	defer fs.staticTenants.mu.Unlock()
+	pt, exists := fs.staticTenants.tenants[tenant.Name]
+	if !exists {
+		pt = &persistTenant{}
+		fs.staticTenants.tenants[tenant.Name] = pt
+	}
+	pt.Tenant = tenant
+	return fs.staticTenants.save()
+}
+
+// RemoveTenant removes a tenant. The files within the namespace of the tenant
+// are kept.
+func (fs *FileSystem) RemoveTenant(name string) error {
+	fs.staticTenants.mu.Lock()
+	defer fs.staticTenants.mu.Unlock()
+	if _, exists := fs.staticTenants.tenants[name]; !exists {
+		return ErrUnknownTenant
+	}
+	delete(fs.staticTenants.tenants, name)
+	return fs.staticTenants.save()
+}
+
+// ResetTenantUsage resets the uploaded and downloaded bytes of a tenant.
+func (fs *FileSystem) ResetTenantUsage(name string) error {
+	fs.staticTenants.mu.Lock()
+	defer fs.staticTenants.mu.Unlock()
+	pt, exists := fs.staticTenants.tenants[name]
+	if !exists {
+		return ErrUnknownTenant
+	}
+	pt.Uploaded, pt.Downloaded = 0, 0
+	return fs.staticTenants.save()
+}
+
+// SaveTenants persists the tenants including their most recent usage.
+func (fs *FileSystem) SaveTenants() error {
+	fs.staticTenants.mu.Lock()
+	defer fs.staticTenants.mu.Unlock()
+	return fs.staticTenants.save()
+}
+
+// Tenant returns the tenant with the given name.
+func (fs *FileSystem) Tenant(name string) (modules.TenantInfo, error) {
+	fs.staticTenants.mu.Lock()
+	pt, exists := fs.staticTenants.tenants[name]
+	var cpy persistTenant
+	if exists {
+		cpy = *pt
+	}
+	fs.staticTenants.mu.Unlock()
+	if !exists {
+		return modules.TenantInfo{}, ErrUnknownTenant
+	}
+	return fs.managedTenantInfo(cpy)
+}
+
+// Tenants returns all tenants sorted by name.
+func (fs *FileSystem) Tenants() ([]modules.TenantInfo, error) {
+	fs.staticTenants.mu.Lock()
+	pts := make([]persistTenant, 0, len(fs.staticTenants.tenants))
+	for _, pt := range fs.staticTenants.tenants {
+		pts = append(pts, *pt)
+	}
+	fs.staticTenants.mu.Unlock()
+	sort.Slice(pts, func(i, j int) bool {
+		return pts[i].Name < pts[j].Name
+	})
+
+	infos := make([]modules.TenantInfo, 0, len(pts))
+	for _, pt := range pts {
+		info, err := fs.managedTenantInfo(pt)
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// TenantStorageRemaining returns the size the file at the given siapath may
+// have without exceeding the storage quota of its tenant. The size of an
+// existing file at the siapath isn't counted since the file is replaced,
+// unless the file is kept as a prior version. The bool is false if the file
+// isn't limited by a storage quota.
+func (fs *FileSystem) TenantStorageRemaining(siaPath modules.SiaPath) (uint64, bool, error) {
+	pt, exists := fs.staticTenants.managedTenantOf(siaPath)
+	if !exists || pt.StorageQuota == 0 {
+		return 0, false, nil
+	}
+	replaced, err := fs.managedReplacedSize(siaPath)
+	if err != nil {
+		return 0, false, err
+	}
+	uploads, err := fs.managedAcquireTenantUploads(pt)
+	if err != nil {
+		return 0, false, err
+	}
+	t := fs.staticTenants
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	defer t.releaseUploads(pt.Name)
+	storage := uploads.storage()
+	if _, credited := uploads.replaced[siaPath]; !credited {
+		if replaced > storage {
+			replaced = storage
+		}
+		storage -= replaced
+	}
+	if storage >= pt.StorageQuota {
+		return 0, true, nil
+	}
+	return pt.StorageQuota - storage, true, nil
+}
+
+// BeginTenantUpload begins an upload of the file at the given siapath. The
+// data of the upload needs to be reserved using the returned TenantUpload,
+// which needs to be closed once the upload is done.
+func (fs *FileSystem) BeginTenantUpload(siaPath modules.SiaPath) (*TenantUpload, error) {
+	pt, exists := fs.staticTenants.managedTenantOf(siaPath)
+	if !exists {
+		return &TenantUpload{}, nil
+	}
+	replaced, err := fs.managedReplacedSize(siaPath)
+	if err != nil {
+		return nil, err
+	}
+	uploads, err := fs.managedAcquireTenantUploads(pt)
+	if err != nil {
+		return nil, err
+	}
+	// The file that is replaced by the upload doesn't count towards the
+	// storage of the tenant anymore. Concurrent uploads of the same file
+	// only exclude it once.
+	t := fs.staticTenants
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	_, credited := uploads.replaced[siaPath]
+	if !credited {
+		uploads.replaced[siaPath] = replaced
+	}
+	return &TenantUpload{
+		credited:      !credited,
+		staticName:    pt.Name,
+		staticSiaPath: siaPath,
+		staticTenants: t,
+	}, nil
+}
+
+// managedAcquireTenantUploads registers an upload of the tenant and returns
+// the storage tracking of the tenant's uploads. If no other upload of the
+// tenant is in progress, the storage of the tenant is computed from the
+// filesystem without holding the lock. Otherwise it waits for the storage
+// computed by the first upload. The uploads need to be released again using
+// releaseUploads.
+func (fs *FileSystem) managedAcquireTenantUploads(pt persistTenant) (*tenantUploads, error) {
+	t := fs.staticTenants
+	t.mu.Lock()
+	uploads, active := t.uploads[pt.Name]
+	if !active {
+		uploads = &tenantUploads{
+			replaced: make(map[modules.SiaPath]uint64),
+			ready:    make(chan struct{}),
+		}
+		t.uploads[pt.Name] = uploads
+	}
+	uploads.active++
+	t.mu.Unlock()
+
+	if !active {
+		root, err := pt.Root()
+		var storage uint64
+		if err == nil {
+			storage, err = fs.managedTenantStorage(root)
+		}
+		t.mu.Lock()
+		uploads.base, uploads.err = storage, err
+		t.mu.Unlock()
+		close(uploads.ready)
+	}
+	<-uploads.ready
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if uploads.err != nil {
+		t.releaseUploads(pt.Name)
+		return nil, uploads.err
+	}
+	return uploads, nil
+}
+
+// releaseUploads releases an upload of the tenant acquired by
+// managedAcquireTenantUploads. Once all uploads of the tenant are done, its
+// storage is computed from the filesystem again.
+func (t *tenants) releaseUploads(name string) {
+	uploads := t.uploads[name]
+	uploads.active--
+	if uploads.active == 0 {
+		delete(t.uploads, name)
+	}
+}
+
+// Reserve reserves n bytes of the storage and upload quotas of the tenant. If
+// that would exceed one of the quotas, ErrStorageQuotaExceeded or
+// ErrUploadQuotaExceeded is returned and nothing is reserved.
+func (tu *TenantUpload) Reserve(n uint64) error {
+	return tu.managedReserve(n, true)
+}
+
+// ReserveStorage reserves n bytes of the storage quota of the tenant for a
+// file that is created without uploading any data, like a copy. If that would
+// exceed the quota, ErrStorageQuotaExceeded is returned and nothing is
+// reserved.
+func (tu *TenantUpload) ReserveStorage(n uint64) error {
+	return tu.managedReserve(n, false)
+}
+
+// managedReserve reserves n bytes of the storage quota and, if upload is
+// true, the upload quota of the tenant.
+func (tu *TenantUpload) managedReserve(n uint64, upload bool) error {
+	if tu.staticName == "" {
+		return nil
+	}
+	t := tu.staticTenants
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tu.closed {
+		return errors.New("upload of tenant already closed")
+	}
+	pt, exists := t.tenants[tu.staticName]
+	if !exists {
+		return nil // the tenant was removed
+	}
+	uploads := t.uploads[tu.staticName]
+	if pt.StorageQuota > 0 && uploads.storage()+n > pt.StorageQuota {
+		return ErrStorageQuotaExceeded
+	}
+	uploads.reserved += n
+	tu.reserved += n
+	if !upload {
+		return nil
+	}
+	if pt.UploadQuota > 0 && pt.Uploaded+n > pt.UploadQuota {
+		uploads.reserved -= n
+		tu.reserved -= n
+		return ErrUploadQuotaExceeded
+	}
+	pt.Uploaded += n
+	tu.uploaded += n
+	return t.saveOccasionally()
+}
+
+// Refund returns the data reserved by the upload to the quotas of the tenant.
+// It is called if an upload fails before its data was uploaded. The file the
+// upload would have replaced counts towards the storage again.
+func (tu *TenantUpload) Refund() error {
+	if tu.staticName == "" {
+		return nil
+	}
+	t := tu.staticTenants
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tu.closed {
+		return errors.New("upload of tenant already closed")
+	}
+	uploads := t.uploads[tu.staticName]
+	uploads.reserved -= tu.reserved
+	if tu.credited {
+		delete(uploads.replaced, tu.staticSiaPath)
+		tu.credited = false
+	}
+	if pt, exists := t.tenants[tu.staticName]; exists {
+		if pt.Uploaded < tu.uploaded {
+			pt.Uploaded = 0
+		} else {
+			pt.Uploaded -= tu.uploaded
+		}
+	}
+	tu.reserved, tu.uploaded = 0, 0
+	return t.saveOccasionally()
+}
+
+// Close ends the upload. Once all uploads of the tenant are done, its storage
+// is computed from the filesystem again.
+func (tu *TenantUpload) Close() error {
+	if tu.staticName == "" {
+		return nil
+	}
+	t := tu.staticTenants
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if tu.closed {
+		return nil
+	}
+	tu.closed = true
+	t.releaseUploads(tu.staticName)
+	return nil
+}
+
+// RecordTenantDownload adds n downloaded bytes to the usage of the tenant
+// whose namespace contains the siapath. If that would exceed the download
+// quota of the tenant, ErrDownloadQuotaExceeded is returned and the bytes
+// aren't added.
+func (fs *FileSystem) RecordTenantDownload(siaPath modules.SiaPath, n uint64) error {
+	pt, exists := fs.staticTenants.managedTenantOf(siaPath)
+	if !exists {
+		return nil
+	}
+	usage := func(pt *persistTenant) *uint64 { return &pt.Downloaded }
+	quota := func(pt *persistTenant) uint64 { return pt.DownloadQuota }
+	return fs.staticTenants.managedRecord(pt.Name, n, usage, quota, ErrDownloadQuotaExceeded)
+}
+
+// RefundTenantDownload removes n downloaded bytes recorded by
+// RecordTenantDownload from the usage of the tenant whose namespace contains
+// the siapath. It is called if a download fails.
+func (fs *FileSystem) RefundTenantDownload(siaPath modules.SiaPath, n uint64) error {
+	tenant, exists := fs.staticTenants.managedTenantOf(siaPath)
+	if !exists {
+		return nil
+	}
+	t := fs.staticTenants
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	pt, exists := t.tenants[tenant.Name]
+	if !exists {
+		return nil
+	}
+	if pt.Downloaded < n {
+		pt.Downloaded = 0
+	} else {
+		pt.Downloaded -= n
+	}
+	return t.saveOccasionally()
+}
+
+// managedTenantInfo returns the info of a tenant including its storage.
+func (fs *FileSystem) managedTenantInfo(pt persistTenant) (modules.TenantInfo, error) {
+	root, err := pt.Root()
+	if err != nil {
+		return modules.TenantInfo{}, err
+	}
+	storage, err := fs.managedTenantStorage(root)
+	if err != nil {
+		return modules.TenantInfo{}, err
+	}
+	return modules.TenantInfo{
+		Tenant: pt.Tenant,
+		Root:   root,
+		Usage: modules.TenantUsage{
+			Storage:    storage,
+			Uploaded:   pt.Uploaded,
+			Downloaded: pt.Downloaded,
+		},
+	}, nil
+}
+
+// managedReplacedSize returns the size of the file at the given siapath that
+// is freed if the file is replaced. That is 0 if the file doesn't exist or is
+// kept as a prior version.
+func (fs *FileSystem) managedReplacedSize(siaPath modules.SiaPath) (uint64, error) {
+	dirSiaPath, err := siaPath.Dir()
+	if err != nil {
+		return 0, err
+	}
+	policy, err := fs.VersioningPolicy(dirSiaPath)
+	if err != nil {
+		return 0, errors.AddContext(err, "failed to get versioning policy")
+	}
+	if policy.Enabled() {
+		return 0, nil
+	}
+	fi, err := fs.CachedFileInfo(siaPath)
+	if errors.Contains(err, ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return fi.Filesize, nil
+}
+
+// managedTenantStorage returns the combined size of the files within the
+// namespace of a tenant and their prior versions.
+func (fs *FileSystem) managedTenantStorage(root modules.SiaPath) (uint64, error) {
+	storage, err := fs.managedStorage(root)
+	if err != nil {
+		return 0, err
+	}
+	dir, err := versionsDir(root)
+	if err != nil {
+		return 0, err
+	}
+	versions, err := fs.managedStorage(dir)
+	if err != nil {
+		return 0, err
+	}
+	return storage + versions, nil
+}
+
+// managedStorage returns the combined size of the files within the directory
+// at the given siapath.
+func (fs *FileSystem) managedStorage(dirSiaPath modules.SiaPath) (uint64, error) {
+	var storage uint64
+	var mu sync.Mutex
+	flf := func(fi modules.FileInfo) {
+		mu.Lock()
+		defer mu.Unlock()
+		storage += fi.Filesize
+	}
+	err := fs.CachedList(dirSiaPath, true, flf, func(modules.DirectoryInfo) {})
+	if errors.Contains(err, ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.AddContext(err, "unable to compute storage of tenant")
+	}
+	return storage, nil
+}
@@ -0,0 +1,248 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+)
+
+// TestTenants tests managing tenants and enforcing their quotas.
+func TestTenants(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	root := filepath.Join(testDir(t.Name()), "fs-root")
+	fs := newTestFileSystem(root)
+
+	// Invalid names are rejected.
+	for _, name := range []string{"", "a/b", ".."} {
+		if err := fs.SetTenant(modules.Tenant{Name: name}); err == nil {
+			t.Fatalf("expected error for tenant name '%v'", name)
+		}
+	}
+
+	// Create a tenant with a file.
+	tenant := modules.Tenant{
+		Name:          "team",
+		StorageQuota:  1000,
+		UploadQuota:   2000,
+		DownloadQuota: 3000,
+	}
+	if err := fs.SetTenant(tenant); err != nil {
+		t.Fatal(err)
+	}
+	tenantRoot, err := tenant.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if exists, err := fs.DirExists(tenantRoot); err != nil || !exists {
+		t.Fatal("root of tenant wasn't created", err)
+	}
+	file, err := tenantRoot.Join("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ec, err := modules.NewRSSubCode(10, 20, crypto.SegmentSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.NewSiaFile(file, "", ec, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), 600, persist.DefaultDiskPermissionsTest, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// The storage of the file counts towards the quota unless it is replaced.
+	other, err := tenantRoot.Join("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if remaining, limited, err := fs.TenantStorageRemaining(other); err != nil || !limited || remaining != 400 {
+		t.Fatal("wrong remaining storage", remaining, limited, err)
+	}
+	if remaining, limited, err := fs.TenantStorageRemaining(file); err != nil || !limited || remaining != 1000 {
+		t.Fatal("wrong remaining storage", remaining, limited, err)
+	}
+
+	// Prior versions of the tenant's files count towards the quota as well.
+	// With versioning enabled, replacing a file keeps the existing file.
+	if err := fs.SetVersioningPolicy(tenantRoot, modules.VersioningPolicy{MaxVersions: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, limited, err := fs.TenantStorageRemaining(file); err != nil || !limited || remaining != 400 {
+		t.Fatal("wrong remaining storage", remaining, limited, err)
+	}
+	if archived, err := fs.ArchiveFile(file); err != nil || !archived {
+		t.Fatal("file wasn't archived", err)
+	}
+	if remaining, limited, err := fs.TenantStorageRemaining(other); err != nil || !limited || remaining != 400 {
+		t.Fatal("wrong remaining storage", remaining, limited, err)
+	}
+	info, err := fs.Tenant(tenant.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Usage.Storage != 600 {
+		t.Fatal("archived version wasn't counted", info.Usage.Storage)
+	}
+	versions, err := fs.FileVersions(file)
+	if err != nil || len(versions) != 1 {
+		t.Fatal("wrong versions", versions, err)
+	}
+	if err := fs.RestoreFileVersion(file, versions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SetVersioningPolicy(tenantRoot, modules.VersioningPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Files outside of a tenant's namespace aren't limited.
+	for _, sp := range []modules.SiaPath{newSiaPath("file"), newSiaPath("tenants/unknown/file"), newSiaPath("tenantsteam/file")} {
+		if _, limited, err := fs.TenantStorageRemaining(sp); err != nil || limited {
+			t.Fatal("file shouldn't be limited", sp, err)
+		}
+		if err := fs.RecordTenantDownload(sp, 10000); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Concurrent uploads can't exceed the storage quota together.
+	other2, err := tenantRoot.Join("other2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	tu1, err := fs.BeginTenantUpload(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tu2, err := fs.BeginTenantUpload(other2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tu1.Reserve(300); err != nil {
+		t.Fatal(err)
+	}
+	if err := tu2.Reserve(101); !errors.Contains(err, ErrStorageQuotaExceeded) {
+		t.Fatal("expected ErrStorageQuotaExceeded but got", err)
+	}
+	if err := tu2.Reserve(100); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, limited, err := fs.TenantStorageRemaining(other); err != nil || !limited || remaining != 0 {
+		t.Fatal("wrong remaining storage", remaining, limited, err)
+	}
+
+	// Refunded data doesn't count towards the quotas.
+	if err := tu2.Refund(); err != nil {
+		t.Fatal(err)
+	}
+	if remaining, limited, err := fs.TenantStorageRemaining(other); err != nil || !limited || remaining != 100 {
+		t.Fatal("wrong remaining storage", remaining, limited, err)
+	}
+	if err := errors.Compose(tu1.Close(), tu2.Close()); err != nil {
+		t.Fatal(err)
+	}
+	if err := tu1.Reserve(1); err == nil {
+		t.Fatal("closed upload shouldn't reserve data")
+	}
+
+	// Replacing a file doesn't count the existing file, even if another
+	// upload of the tenant is already in progress.
+	tu1, err = fs.BeginTenantUpload(other)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tu3, err := fs.BeginTenantUpload(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tu3.Reserve(1000); err != nil {
+		t.Fatal(err)
+	}
+	if err := errors.Compose(tu1.Close(), tu3.Close()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Uploads and downloads are limited by the quotas.
+	tu4, err := fs.BeginTenantUpload(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tu4.Reserve(700); err != nil {
+		t.Fatal(err)
+	}
+	if err := tu4.Reserve(1); !errors.Contains(err, ErrUploadQuotaExceeded) {
+		t.Fatal("expected ErrUploadQuotaExceeded but got", err)
+	}
+	if err := tu4.ReserveStorage(1); err != nil {
+		t.Fatal("storage reservations shouldn't count towards the upload quota", err)
+	}
+	if err := tu4.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RecordTenantDownload(file, 3000); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RecordTenantDownload(file, 1); !errors.Contains(err, ErrDownloadQuotaExceeded) {
+		t.Fatal("expected ErrDownloadQuotaExceeded but got", err)
+	}
+	if err := fs.RefundTenantDownload(file, 1); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RecordTenantDownload(file, 1); err != nil {
+		t.Fatal("refunded download wasn't returned to the quota", err)
+	}
+	info, err = fs.Tenant(tenant.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := modules.TenantUsage{Storage: 600, Uploaded: 2000, Downloaded: 3000}
+	if info.Usage != expected || info.Tenant != tenant || !info.Root.Equals(tenantRoot) {
+		t.Fatal("wrong tenant info", info)
+	}
+
+	// The usage is persisted.
+	if err := fs.SaveTenants(); err != nil {
+		t.Fatal(err)
+	}
+	fs = newTestFileSystem(root)
+	infos, err := fs.Tenants()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(infos) != 1 || infos[0].Usage != expected {
+		t.Fatal("usage wasn't persisted", infos)
+	}
+
+	// Resetting the usage keeps the storage.
+	if err := fs.ResetTenantUsage(tenant.Name); err != nil {
+		t.Fatal(err)
+	}
+	info, err = fs.Tenant(tenant.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Usage != (modules.TenantUsage{Storage: 600}) {
+		t.Fatal("usage wasn't reset", info.Usage)
+	}
+
+	// Removing the tenant keeps its files but removes its quotas.
+	if err := fs.RemoveTenant(tenant.Name); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.RemoveTenant(tenant.Name); !errors.Contains(err, ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+	if _, err := fs.Tenant(tenant.Name); !errors.Contains(err, ErrUnknownTenant) {
+		t.Fatal("expected ErrUnknownTenant but got", err)
+	}
+	if exists, err := fs.FileExists(file); err != nil || !exists {
+		t.Fatal("file of tenant was removed", err)
+	}
+	if _, limited, err := fs.TenantStorageRemaining(other); err != nil || limited {
+		t.Fatal("file shouldn't be limited after the tenant was removed", err)
+	}
+}
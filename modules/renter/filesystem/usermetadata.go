@@ -0,0 +1,28 @@
+package filesystem
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// SetDirUserMetadata applies the entries of update to the user metadata of the
+// directory at the given siapath. Entries with an empty value remove the key.
+func (fs *FileSystem) SetDirUserMetadata(dirSiaPath modules.SiaPath, update modules.UserMetadata) (err error) {
+	dir, err := fs.managedOpenSiaDir(dirSiaPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, dir.Close())
+	}()
+	md, err := dir.Metadata()
+	if err != nil {
+		return err
+	}
+	um := md.UserMetadata.Update(update)
+	if err := um.Validate(); err != nil {
+		return err
+	}
+	md.UserMetadata = um
+	return dir.UpdateMetadata(md)
+}
@@ -0,0 +1,67 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem/siadir"
+)
+
+// TestDirUserMetadata tests setting the user metadata of a directory.
+func TestDirUserMetadata(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	fs := newTestFileSystem(filepath.Join(testDir(t.Name()), "fs-root"))
+	dirSiaPath := newSiaPath("a/b")
+	if err := fs.NewSiaDir(dirSiaPath, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Set some metadata.
+	expected := modules.UserMetadata{"retention": "archive"}
+	if err := fs.SetDirUserMetadata(dirSiaPath, expected); err != nil {
+		t.Fatal(err)
+	}
+	di, err := fs.DirInfo(dirSiaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(di.UserMetadata, expected) {
+		t.Fatal("wrong user metadata", di.UserMetadata)
+	}
+
+	// Bubbled metadata updates keep the user metadata.
+	dir, err := fs.OpenSiaDir(dirSiaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := dir.UpdateBubbledMetadata(siadir.Metadata{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := dir.Close(); err != nil {
+		t.Fatal(err)
+	}
+	di, err = fs.DirInfo(dirSiaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(di.UserMetadata, expected) {
+		t.Fatal("user metadata was lost during bubble", di.UserMetadata)
+	}
+
+	// Removing all entries clears the metadata.
+	if err := fs.SetDirUserMetadata(dirSiaPath, modules.UserMetadata{"retention": ""}); err != nil {
+		t.Fatal(err)
+	}
+	di, err = fs.DirInfo(dirSiaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(di.UserMetadata) != 0 {
+		t.Fatal("user metadata wasn't removed", di.UserMetadata)
+	}
+}
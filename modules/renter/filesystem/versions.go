@@ -0,0 +1,313 @@
+package filesystem
+
+// versions.go contains the logic for keeping prior versions of siafiles. When
+// a file within a directory with an enabled versioning policy is overwritten or
+// deleted, the siafile is moved into the VersionsFolder instead of being
+// deleted. Since the siafile is still part of the filesystem, its sectors are
+// retained and repaired like those of any other file.
+//
+// The versions of a file are stored in a directory with the file's siapath
+// within the VersionsFolder. Each version is named after the time at which it
+// was archived in nanoseconds, which also serves as the version's ID.
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// ErrInvalidVersion is returned if a version ID can't be parsed.
+	ErrInvalidVersion = errors.New("invalid file version")
+)
+
+// isVersionPath returns true if the siapath points to a file or dir within the
+// VersionsFolder.
+func isVersionPath(siaPath modules.SiaPath) bool {
+	return siaPath.Equals(modules.VersionsFolder) || strings.HasPrefix(siaPath.Path, modules.VersionsFolder.Path+"/")
+}
+
+// versionsDir returns the directory within the VersionsFolder that contains
+// the versions of the file at the given siapath.
+func versionsDir(siaPath modules.SiaPath) (modules.SiaPath, error) {
+	return modules.VersionsFolder.Join(siaPath.String())
+}
+
+// versionSiaPath returns the siapath of the version of the file at the given
+// siapath with the given ID.
+func versionSiaPath(siaPath modules.SiaPath, id string) (modules.SiaPath, error) {
+	if _, err := strconv.ParseInt(id, 10, 64); err != nil {
+		return modules.SiaPath{}, errors.Compose(ErrInvalidVersion, err)
+	}
+	dir, err := versionsDir(siaPath)
+	if err != nil {
+		return modules.SiaPath{}, err
+	}
+	return dir.Join(id)
+}
+
+// VersioningPolicy returns the versioning policy that applies to the files
+// within the directory at the given siapath. If the directory doesn't have a
+// policy, the policy of the closest ancestor with a policy is returned.
+func (fs *FileSystem) VersioningPolicy(dirSiaPath modules.SiaPath) (modules.VersioningPolicy, error) {
+	for {
+		policy, err := fs.managedDirVersioningPolicy(dirSiaPath)
+		if err != nil && !errors.Contains(err, ErrNotExist) {
+			return modules.VersioningPolicy{}, err
+		}
+		if err == nil && policy.Enabled() {
+			return policy, nil
+		}
+		if dirSiaPath.IsRoot() {
+			return modules.VersioningPolicy{}, nil
+		}
+		dirSiaPath, err = dirSiaPath.Dir()
+		if err != nil {
+			return modules.VersioningPolicy{}, err
+		}
+	}
+}
+
+// SetVersioningPolicy sets the versioning policy of the directory at the given
+// siapath. The policy also applies to all subdirectories without a policy of
+// their own.
+func (fs *FileSystem) SetVersioningPolicy(dirSiaPath modules.SiaPath, policy modules.VersioningPolicy) (err error) {
+	if isVersionPath(dirSiaPath) {
+		return errors.New("can't set a versioning policy within the versions folder")
+	}
+	dir, err := fs.managedOpenSiaDir(dirSiaPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, dir.Close())
+	}()
+	md, err := dir.Metadata()
+	if err != nil {
+		return err
+	}
+	md.VersioningPolicy = policy
+	return dir.UpdateMetadata(md)
+}
+
+// ArchiveFile keeps the file at the given siapath as a prior version if the
+// versioning policy of its directory is enabled. If versioning is disabled,
+// the file is left untouched and false is returned, in which case the caller
+// is expected to delete the file.
+func (fs *FileSystem) ArchiveFile(siaPath modules.SiaPath) (bool, error) {
+	if isVersionPath(siaPath) {
+		return false, nil
+	}
+	dirSiaPath, err := siaPath.Dir()
+	if err != nil {
+		return false, err
+	}
+	policy, err := fs.VersioningPolicy(dirSiaPath)
+	if err != nil {
+		return false, errors.AddContext(err, "failed to get versioning policy")
+	}
+	if !policy.Enabled() {
+		return false, nil
+	}
+	if err := fs.managedArchiveFile(siaPath); err != nil {
+		return false, err
+	}
+	_, err = fs.managedPruneFileVersions(siaPath, policy, time.Now())
+	return true, err
+}
+
+// FileVersions returns the prior versions of the file at the given siapath,
+// newest first.
+func (fs *FileSystem) FileVersions(siaPath modules.SiaPath) ([]modules.FileVersion, error) {
+	dir, err := versionsDir(siaPath)
+	if err != nil {
+		return nil, err
+	}
+	var mu sync.Mutex
+	var versions []modules.FileVersion
+	flf := func(fi modules.FileInfo) {
+		id := fi.SiaPath.Name()
+		nanos, err := strconv.ParseInt(id, 10, 64)
+		if err != nil {
+			return // not a version
+		}
+		mu.Lock()
+		versions = append(versions, modules.FileVersion{
+			ID:           id,
+			ArchivedTime: time.Unix(0, nanos),
+			Filesize:     fi.Filesize,
+			Health:       fi.Health,
+			Redundancy:   fi.Redundancy,
+		})
+		mu.Unlock()
+	}
+	err = fs.CachedList(dir, false, flf, func(modules.DirectoryInfo) {})
+	if errors.Contains(err, ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(versions, func(i, j int) bool {
+		return versions[i].ArchivedTime.After(versions[j].ArchivedTime)
+	})
+	return versions, nil
+}
+
+// RestoreFileVersion restores the version of the file at the given siapath
+// with the given ID. If the file currently exists, it is kept as a prior
+// version regardless of the versioning policy to make sure that restoring a
+// version never loses data.
+func (fs *FileSystem) RestoreFileVersion(siaPath modules.SiaPath, id string) error {
+	versionPath, err := versionSiaPath(siaPath, id)
+	if err != nil {
+		return err
+	}
+	exists, err := fs.FileExists(versionPath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.AddContext(ErrNotExist, "version doesn't exist")
+	}
+	// Archive the current version of the file.
+	exists, err = fs.FileExists(siaPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		if err := fs.managedArchiveFile(siaPath); err != nil {
+			return errors.AddContext(err, "failed to archive current version")
+		}
+	}
+	return fs.RenameFile(versionPath, siaPath)
+}
+
+// PruneFileVersions removes all versions that exceed the age limit of the
+// versioning policy of their file's directory. It returns the siapaths of the
+// files that had versions removed.
+func (fs *FileSystem) PruneFileVersions() ([]modules.SiaPath, error) {
+	// Collect the files that have versions.
+	var mu sync.Mutex
+	files := make(map[modules.SiaPath]struct{})
+	flf := func(fi modules.FileInfo) {
+		dir, err := fi.SiaPath.Dir()
+		if err != nil {
+			return
+		}
+		siaPath, err := dir.Rebase(modules.VersionsFolder, modules.RootSiaPath())
+		if err != nil {
+			return
+		}
+		mu.Lock()
+		files[siaPath] = struct{}{}
+		mu.Unlock()
+	}
+	err := fs.CachedList(modules.VersionsFolder, true, flf, func(modules.DirectoryInfo) {})
+	if errors.Contains(err, ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// Prune the versions of every file according to its current policy.
+	// Versions of files whose directory no longer has a policy are kept
+	// until they are removed manually.
+	var pruned []modules.SiaPath
+	now := time.Now()
+	for siaPath := range files {
+		dirSiaPath, err := siaPath.Dir()
+		if err != nil {
+			continue
+		}
+		policy, err := fs.VersioningPolicy(dirSiaPath)
+		if err != nil {
+			return pruned, err
+		}
+		if !policy.Enabled() {
+			continue
+		}
+		removed, err := fs.managedPruneFileVersions(siaPath, policy, now)
+		if removed > 0 {
+			pruned = append(pruned, siaPath)
+		}
+		if err != nil {
+			return pruned, err
+		}
+	}
+	return pruned, nil
+}
+
+// managedArchiveFile moves the file at the given siapath into the
+// VersionsFolder.
+func (fs *FileSystem) managedArchiveFile(siaPath modules.SiaPath) (err error) {
+	id := strconv.FormatInt(time.Now().UnixNano(), 10)
+	versionPath, err := versionSiaPath(siaPath, id)
+	if err != nil {
+		return err
+	}
+	if err := fs.RenameFile(siaPath, versionPath); err != nil {
+		return err
+	}
+	// The local file now belongs to the new version of the file, so the
+	// archived version must not be repaired from it.
+	sf, err := fs.OpenSiaFile(versionPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, sf.Close())
+	}()
+	return sf.SetLocalPath("")
+}
+
+// managedDirVersioningPolicy returns the versioning policy set on the
+// directory at the given siapath.
+func (fs *FileSystem) managedDirVersioningPolicy(dirSiaPath modules.SiaPath) (_ modules.VersioningPolicy, err error) {
+	dir, err := fs.managedOpenSiaDir(dirSiaPath)
+	if err != nil {
+		return modules.VersioningPolicy{}, err
+	}
+	defer func() {
+		err = errors.Compose(err, dir.Close())
+	}()
+	md, err := dir.Metadata()
+	if err != nil {
+		return modules.VersioningPolicy{}, err
+	}
+	return md.VersioningPolicy, nil
+}
+
+// managedPruneFileVersions removes the versions of the file at the given
+// siapath that exceed the limits of the given policy. It returns the number of
+// removed versions.
+func (fs *FileSystem) managedPruneFileVersions(siaPath modules.SiaPath, policy modules.VersioningPolicy, now time.Time) (removed int, errs error) {
+	versions, err := fs.FileVersions(siaPath)
+	if err != nil {
+		return 0, err
+	}
+	for i, version := range versions {
+		tooMany := policy.MaxVersions > 0 && uint64(i) >= policy.MaxVersions
+		tooOld := policy.MaxAge > 0 && now.Sub(version.ArchivedTime) > policy.MaxAge
+		if !tooMany && !tooOld {
+			continue
+		}
+		versionPath, err := versionSiaPath(siaPath, version.ID)
+		if err != nil {
+			errs = errors.Compose(errs, err)
+			continue
+		}
+		if err := fs.DeleteFile(versionPath); err != nil {
+			errs = errors.Compose(errs, err)
+			continue
+		}
+		removed++
+	}
+	return removed, errs
+}
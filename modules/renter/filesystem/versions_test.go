@@ -0,0 +1,260 @@
+package filesystem
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// TestVersioningPolicy tests setting versioning policies and their
+// inheritance.
+func TestVersioningPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	fs := newTestFileSystem(filepath.Join(testDir(t.Name()), "fs-root"))
+
+	// Create a dir tree.
+	if err := fs.NewSiaDir(newSiaPath("a/b/c"), modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+
+	// Versioning is disabled by default.
+	policy, err := fs.VersioningPolicy(newSiaPath("a/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Enabled() {
+		t.Fatal("versioning should be disabled", policy)
+	}
+
+	// Set a policy on 'a'. It should be inherited by its subdirs.
+	expected := modules.VersioningPolicy{MaxVersions: 3, MaxAge: time.Hour}
+	if err := fs.SetVersioningPolicy(newSiaPath("a"), expected); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = fs.VersioningPolicy(newSiaPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy != expected {
+		t.Fatal("policy wasn't inherited", policy)
+	}
+	// The root dir isn't affected.
+	policy, err = fs.VersioningPolicy(modules.RootSiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy.Enabled() {
+		t.Fatal("root shouldn't have a policy", policy)
+	}
+
+	// A subdir's own policy takes precedence.
+	own := modules.VersioningPolicy{MaxVersions: 1}
+	if err := fs.SetVersioningPolicy(newSiaPath("a/b"), own); err != nil {
+		t.Fatal(err)
+	}
+	policy, err = fs.VersioningPolicy(newSiaPath("a/b/c"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if policy != own {
+		t.Fatal("wrong policy", policy)
+	}
+
+	// The policy is part of the directory info.
+	di, err := fs.DirInfo(newSiaPath("a/b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if di.VersioningPolicy != own {
+		t.Fatal("policy missing from dir info", di.VersioningPolicy)
+	}
+
+	// Policies can't be set within the versions folder.
+	if err := fs.SetVersioningPolicy(modules.VersionsFolder, own); err == nil {
+		t.Fatal("policy shouldn't be settable within the versions folder")
+	}
+}
+
+// TestArchiveAndRestoreFile tests archiving files and restoring their prior
+// versions.
+func TestArchiveAndRestoreFile(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	fs := newTestFileSystem(filepath.Join(testDir(t.Name()), "fs-root"))
+	siaPath := newSiaPath("dir/file")
+	fs.addTestSiaFile(siaPath)
+
+	// Without a policy, the file isn't archived.
+	archived, err := fs.ArchiveFile(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if archived {
+		t.Fatal("file shouldn't be archived without a policy")
+	}
+	if exists, _ := fs.FileExists(siaPath); !exists {
+		t.Fatal("file should still exist")
+	}
+
+	// Enable versioning and archive the file a few times.
+	policy := modules.VersioningPolicy{MaxVersions: 2}
+	if err := fs.SetVersioningPolicy(newSiaPath("dir"), policy); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 3; i++ {
+		if i > 0 {
+			fs.addTestSiaFile(siaPath)
+		}
+		sf, err := fs.OpenSiaFile(siaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sf.SetLocalPath("/local/file"); err != nil {
+			t.Fatal(err)
+		}
+		if err := sf.Close(); err != nil {
+			t.Fatal(err)
+		}
+		archived, err := fs.ArchiveFile(siaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !archived {
+			t.Fatal("file should be archived")
+		}
+		if exists, _ := fs.FileExists(siaPath); exists {
+			t.Fatal("archived file shouldn't exist anymore")
+		}
+	}
+
+	// Only the 2 most recent versions are kept.
+	versions, err := fs.FileVersions(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(versions) != 2 {
+		t.Fatal("wrong number of versions", len(versions))
+	}
+	if !versions[0].ArchivedTime.After(versions[1].ArchivedTime) {
+		t.Fatal("versions should be sorted newest first")
+	}
+
+	// The archived versions aren't repaired from the local file.
+	versionPath, err := versionSiaPath(siaPath, versions[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sf, err := fs.OpenSiaFile(versionPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sf.LocalPath() != "" {
+		t.Fatal("local path of archived version should be cleared", sf.LocalPath())
+	}
+	if err := sf.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Archiving files within the versions folder is a no-op.
+	archived, err = fs.ArchiveFile(versionPath)
+	if err != nil || archived {
+		t.Fatal("versions shouldn't be archived", archived, err)
+	}
+
+	// Restore the oldest version.
+	if err := fs.RestoreFileVersion(siaPath, versions[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := fs.FileExists(siaPath); !exists {
+		t.Fatal("restored file should exist")
+	}
+	remaining, err := fs.FileVersions(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != versions[0].ID {
+		t.Fatal("wrong versions after restore", remaining)
+	}
+
+	// Restoring again keeps the current file as a version.
+	if err := fs.RestoreFileVersion(siaPath, versions[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	remaining, err = fs.FileVersions(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(remaining) != 1 || remaining[0].ID == versions[0].ID {
+		t.Fatal("current file should have been archived", remaining)
+	}
+
+	// Restoring unknown versions fails.
+	if err := fs.RestoreFileVersion(siaPath, versions[0].ID); !errors.Contains(err, ErrNotExist) {
+		t.Fatal("expected ErrNotExist but got", err)
+	}
+	if err := fs.RestoreFileVersion(siaPath, "invalid"); !errors.Contains(err, ErrInvalidVersion) {
+		t.Fatal("expected ErrInvalidVersion but got", err)
+	}
+}
+
+// TestPruneFileVersions tests removing versions that exceed the age limit of
+// their policy.
+func TestPruneFileVersions(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	fs := newTestFileSystem(filepath.Join(testDir(t.Name()), "fs-root"))
+
+	// Archive a file in a dir with an age limit and one in a dir without.
+	expiring := newSiaPath("expiring/file")
+	kept := newSiaPath("kept/file")
+	fs.addTestSiaFile(expiring)
+	fs.addTestSiaFile(kept)
+	if err := fs.SetVersioningPolicy(newSiaPath("expiring"), modules.VersioningPolicy{MaxAge: time.Hour}); err != nil {
+		t.Fatal(err)
+	}
+	if err := fs.SetVersioningPolicy(newSiaPath("kept"), modules.VersioningPolicy{MaxVersions: 10}); err != nil {
+		t.Fatal(err)
+	}
+	for _, sp := range []modules.SiaPath{expiring, kept} {
+		if archived, err := fs.ArchiveFile(sp); err != nil || !archived {
+			t.Fatal("failed to archive file", archived, err)
+		}
+	}
+
+	// Nothing is pruned while the versions are young.
+	pruned, err := fs.PruneFileVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 0 {
+		t.Fatal("nothing should be pruned", pruned)
+	}
+
+	// Lower the age limit and prune again.
+	if err := fs.SetVersioningPolicy(newSiaPath("expiring"), modules.VersioningPolicy{MaxAge: time.Nanosecond}); err != nil {
+		t.Fatal(err)
+	}
+	pruned, err = fs.PruneFileVersions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pruned) != 1 || !pruned[0].Equals(expiring) {
+		t.Fatal("wrong files pruned", pruned)
+	}
+	if versions, err := fs.FileVersions(expiring); err != nil || len(versions) != 0 {
+		t.Fatal("versions should be pruned", versions, err)
+	}
+	if versions, err := fs.FileVersions(kept); err != nil || len(versions) != 1 {
+		t.Fatal("versions should be kept", versions, err)
+	}
+}
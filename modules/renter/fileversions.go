@@ -0,0 +1,84 @@
+package renter
+
+// fileversions.go contains the renter's interface to the prior versions of
+// files kept by the filesystem.
+
+import (
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// FileVersions returns the prior versions of a file, newest first.
+func (r *Renter) FileVersions(siaPath modules.SiaPath) ([]modules.FileVersion, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.FileVersions(siaPath)
+}
+
+// RestoreFileVersion restores a prior version of a file. The current version
+// of the file is kept as a prior version.
+func (r *Renter) RestoreFileVersion(siaPath modules.SiaPath, id string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	err := r.staticFileSystem.RestoreFileVersion(siaPath, id)
+	if err != nil {
+		return errors.AddContext(err, "unable to restore file version")
+	}
+	r.managedBubbleFileVersions(siaPath)
+	dirSiaPath, err := siaPath.Dir()
+	if err == nil {
+		_ = r.staticBubbleScheduler.callQueueBubble(dirSiaPath)
+	}
+	return nil
+}
+
+// SetVersioningPolicy sets the versioning policy of a directory.
+func (r *Renter) SetVersioningPolicy(siaPath modules.SiaPath, policy modules.VersioningPolicy) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.SetVersioningPolicy(siaPath, policy)
+}
+
+// managedBubbleFileVersions queues a bubble for the directory containing the
+// versions of a file.
+func (r *Renter) managedBubbleFileVersions(siaPath modules.SiaPath) {
+	dir, err := modules.VersionsFolder.Join(siaPath.String())
+	if err != nil {
+		r.log.Printf("Unable to fetch the versions directory of siafile %v: %v", siaPath, err)
+		return
+	}
+	_ = r.staticBubbleScheduler.callQueueBubble(dir)
+}
+
+// threadedPruneFileVersions periodically removes the prior versions of files
+// that exceed the age limit of their versioning policy.
+func (r *Renter) threadedPruneFileVersions() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(fileVersionPruneInterval):
+		}
+		pruned, err := r.staticFileSystem.PruneFileVersions()
+		if err != nil {
+			r.log.Printf("WARN: failed to prune file versions: %v", err)
+		}
+		for _, siaPath := range pruned {
+			r.managedBubbleFileVersions(siaPath)
+		}
+	}
+}
@@ -7,6 +7,16 @@ import (
 )
 
 const (
+	// AlertMSGGeoIPDatabase indicates that the GeoIP database of the host
+	// selection policy couldn't be loaded and that no hosts are selected for
+	// new contracts until it is loaded.
+	AlertMSGGeoIPDatabase = "The GeoIP database of the host selection policy couldn't be loaded, no new contracts are formed"
+
+	// historicAuditDecay defines the decay of the HistoricSuccessfulAudits and
+	// HistoricFailedAudits of a host entry that is applied every time a new
+	// audit result is recorded.
+	historicAuditDecay = 0.99
+
 	// historicInteractionDecay defines the decay of the HistoricSuccessfulInteractions
 	// and HistoricFailedInteractions after every block for a host entry.
 	historicInteractionDecay = 0.9995
@@ -22,6 +32,13 @@ const (
 	// scan.
 	hostScanDeadline = 4 * time.Minute
 
+	// latencyReference is the latency at which a host's latency doesn't affect
+	// its score.
+	latencyReference = 250 * time.Millisecond
+
+	// maxLatencyWeight is the maximum weight of the latency adjustment.
+	maxLatencyWeight = 10
+
 	// maxHostDowntime specifies the maximum amount of time that a host is
 	// allowed to be offline while still being in the hostdb.
 	maxHostDowntime       = maxHostDownTimeInDays * 24 * time.Hour
@@ -50,6 +67,10 @@ const (
 	// than half the total weight at this limit.
 	recentInteractionWeightLimit = 0.01
 
+	// throughputReference is the throughput in bytes per second at which a
+	// host's throughput doesn't affect its score.
+	throughputReference = 1 << 22
+
 	// saveFrequency defines how frequently the hostdb will save to disk. Hostdb
 	// will also save immediately prior to shutdown.
 	saveFrequency = 2 * time.Minute
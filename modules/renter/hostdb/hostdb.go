@@ -6,6 +6,7 @@ package hostdb
 
 import (
 	"fmt"
+	"math"
 	"net"
 	"os"
 	"path/filepath"
@@ -35,6 +36,10 @@ var (
 	errNilTPool              = errors.New("cannot create hostdb with nil transaction pool")
 	errNilSiaMux             = errors.New("cannot create hostdb with nil siamux")
 
+	// errGeoIPDatabaseUnavailable is returned when the hostdb can't enforce
+	// the host selection policy because its GeoIP database isn't loaded.
+	errGeoIPDatabaseUnavailable = errors.New("the GeoIP database of the host selection policy isn't loaded")
+
 	// errHostNotFoundInTree is returned when the host is not found in the
 	// hosttree
 	errHostNotFoundInTree = errors.New("host not found in hosttree")
@@ -200,6 +205,21 @@ type HostDB struct {
 	// filteredDomains tracks blocked domains for the hostdb.
 	filteredDomains *filteredDomains
 
+	// selectionPolicy contains additional constraints for selecting hosts.
+	// The geoIPDB is loaded from the file specified in the policy and is used
+	// to locate the hosts.
+	selectionPolicy modules.HostSelectionPolicy
+	geoIPDB         *hosttree.GeoIPDatabase
+
+	// latencyWeight is the weight of the latency adjustment in the host
+	// scores.
+	latencyWeight float64
+
+	// scoringRules are the user defined rules that adjust the host scores.
+	// They are compiled into compiledScoringRules when set.
+	scoringRules         modules.HostScoringRules
+	compiledScoringRules []scoringRule
+
 	blockHeight types.BlockHeight
 	lastChange  modules.ConsensusChangeID
 }
@@ -387,10 +407,19 @@ func hostdbBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 	// Load the prior persistence structures.
 	hdb.mu.Lock()
 	err = hdb.load()
+	rebuildWeightFn := hdb.selectionPolicy.Active() || hdb.latencyWeight > 0 || len(hdb.compiledScoringRules) > 0
 	hdb.mu.Unlock()
 	if err != nil && !os.IsNotExist(err) {
 		return nil, err
 	}
+	// If a selection policy, latency weight or scoring rules were loaded, the
+	// weight function needs to be updated to take them into account.
+	if rebuildWeightFn {
+		err = hdb.managedSetWeightFunction(hdb.managedCalculateHostWeightFn(hdb.allowance))
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to apply host selection policy")
+		}
+	}
 	err = hdb.tg.AfterStop(func() error {
 		hdb.mu.Lock()
 		err := hdb.saveSync()
@@ -622,6 +651,49 @@ func (hdb *HostDB) CheckForIPViolations(hosts []types.SiaPublicKey) ([]types.Sia
 	return badHosts, nil
 }
 
+// CheckForPolicyViolations accepts a number of host public keys and returns
+// the ones that violate the host selection policy. A host violates the policy
+// if it isn't within one of the allowed regions or if it exceeds the limit of
+// hosts per AS or country. Hosts that have been on their subnet for longer are
+// preferred when enforcing the limits. Hosts that aren't in the hostdb are
+// ignored.
+func (hdb *HostDB) CheckForPolicyViolations(hosts []types.SiaPublicKey) ([]types.SiaPublicKey, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	if !hdb.selectionPolicy.Active() {
+		return nil, nil
+	}
+	if hdb.geoIPDB == nil {
+		return nil, errGeoIPDatabaseUnavailable
+	}
+
+	var entries []modules.HostDBEntry
+	for _, host := range hosts {
+		entry, exists := hdb.staticHostTree.Select(host)
+		if exists {
+			entries = append(entries, entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].LastIPNetChange.Before(entries[j].LastIPNetChange)
+	})
+
+	var badHosts []types.SiaPublicKey
+	pf := hosttree.NewPolicyFilter(hdb.selectionPolicy, hdb.geoIPDB)
+	for _, entry := range entries {
+		if pf.Filtered(entry) {
+			badHosts = append(badHosts, entry.PublicKey)
+			continue
+		}
+		pf.Add(entry)
+	}
+	return badHosts, nil
+}
+
 // Close closes the hostdb, terminating its scanning threads
 func (hdb *HostDB) Close() error {
 	return hdb.tg.Stop()
@@ -822,6 +894,183 @@ func (hdb *HostDB) SetIPViolationCheck(enabled bool) error {
 	return nil
 }
 
+// LatencyWeight returns the weight of the latency adjustment in the host
+// scores.
+func (hdb *HostDB) LatencyWeight() (float64, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return 0, errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	return hdb.latencyWeight, nil
+}
+
+// ScoringRules returns the user defined rules that adjust the host scores.
+func (hdb *HostDB) ScoringRules() (modules.HostScoringRules, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return modules.HostScoringRules{}, errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	return hdb.scoringRules, nil
+}
+
+// SelectionPolicy returns the policy the hostdb applies when selecting hosts.
+func (hdb *HostDB) SelectionPolicy() (modules.HostSelectionPolicy, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return modules.HostSelectionPolicy{}, errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	return hdb.selectionPolicy, nil
+}
+
+// SetLatencyWeight sets the weight of the latency adjustment in the host scores
+// and rebuilds the hosttree to apply it. A weight of 0 disables the
+// adjustment.
+func (hdb *HostDB) SetLatencyWeight(weight float64) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	if math.IsNaN(weight) || weight < 0 || weight > maxLatencyWeight {
+		return fmt.Errorf("latency weight must be between 0 and %v", maxLatencyWeight)
+	}
+
+	hdb.mu.Lock()
+	if hdb.latencyWeight == weight {
+		hdb.mu.Unlock()
+		return nil
+	}
+	// Only keep the new weight if it was saved.
+	oldWeight := hdb.latencyWeight
+	hdb.latencyWeight = weight
+	err := hdb.saveSync()
+	if err != nil {
+		hdb.latencyWeight = oldWeight
+	}
+	allowance := hdb.allowance
+	hdb.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "failed to save latency weight")
+	}
+
+	// Update the weight function.
+	wf := hdb.managedCalculateHostWeightFn(allowance)
+	return hdb.managedSetWeightFunction(wf)
+}
+
+// SetScoringRules replaces the user defined rules that adjust the host scores
+// and rebuilds the hosttree to apply them.
+func (hdb *HostDB) SetScoringRules(rules modules.HostScoringRules) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	compiled, err := compileScoringRules(rules)
+	if err != nil {
+		return err
+	}
+
+	hdb.mu.Lock()
+	hdb.scoringRules = rules
+	hdb.compiledScoringRules = compiled
+	allowance := hdb.allowance
+	err = hdb.saveSync()
+	hdb.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "failed to save scoring rules")
+	}
+
+	// Update the weight function.
+	wf := hdb.managedCalculateHostWeightFn(allowance)
+	return hdb.managedSetWeightFunction(wf)
+}
+
+// SetSelectionPolicy updates the policy the hostdb applies when selecting
+// hosts. The GeoIP database specified in the policy is loaded from disk and
+// the hosttree is rebuilt to apply the region weighting.
+func (hdb *HostDB) SetSelectionPolicy(policy modules.HostSelectionPolicy) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+	if err := policy.Validate(); err != nil {
+		return errors.AddContext(err, "invalid host selection policy")
+	}
+
+	hdb.mu.RLock()
+	unchanged := reflect.DeepEqual(policy, hdb.selectionPolicy)
+	loaded := hdb.geoIPDB != nil || !policy.Active()
+	allowance := hdb.allowance
+	hdb.mu.RUnlock()
+
+	// Nothing to do if the policy didn't change and its database is loaded.
+	if unchanged && loaded {
+		return nil
+	}
+
+	// Retry loading the database of an unchanged policy, e.g. after the file
+	// was restored. The renter sets the policy with every change of its
+	// settings, which is why failing to load the database doesn't fail the
+	// call. The alert of the database stays registered instead.
+	if unchanged {
+		hdb.mu.Lock()
+		err := hdb.loadGeoIPDatabase()
+		hdb.mu.Unlock()
+		if err != nil {
+			hdb.staticLog.Println("WARN: failed to reload GeoIP database:", err)
+			return nil
+		}
+		return hdb.managedSetWeightFunction(hdb.managedCalculateHostWeightFn(allowance))
+	}
+
+	// A changed policy is only applied if its database can be loaded.
+	var db *hosttree.GeoIPDatabase
+	if policy.Active() {
+		var err error
+		db, err = hosttree.LoadGeoIPDatabase(policy.GeoIPDatabase)
+		if err != nil {
+			return err
+		}
+	}
+	hdb.mu.Lock()
+	hdb.selectionPolicy = policy
+	hdb.geoIPDB = db
+	hdb.staticAlerter.UnregisterAlert(modules.AlertIDHostDBGeoIPDatabase)
+	err := hdb.saveSync()
+	hdb.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "failed to apply host selection policy")
+	}
+
+	// Update the weight function.
+	wf := hdb.managedCalculateHostWeightFn(allowance)
+	return hdb.managedSetWeightFunction(wf)
+}
+
+// loadGeoIPDatabase loads the GeoIP database of the selection policy. If that
+// fails, an alert is registered and the hostdb doesn't select hosts until the
+// database is loaded, since it can't enforce the policy without it.
+func (hdb *HostDB) loadGeoIPDatabase() error {
+	hdb.geoIPDB = nil
+	if !hdb.selectionPolicy.Active() {
+		hdb.staticAlerter.UnregisterAlert(modules.AlertIDHostDBGeoIPDatabase)
+		return nil
+	}
+	db, err := hosttree.LoadGeoIPDatabase(hdb.selectionPolicy.GeoIPDatabase)
+	if err != nil {
+		hdb.staticAlerter.RegisterAlert(modules.AlertIDHostDBGeoIPDatabase, AlertMSGGeoIPDatabase, err.Error(), modules.SeverityError)
+		return err
+	}
+	hdb.staticAlerter.UnregisterAlert(modules.AlertIDHostDBGeoIPDatabase)
+	hdb.geoIPDB = db
+	return nil
+}
+
 // UpdateContracts rebuilds the knownContracts of the HostBD using the provided
 // contracts.
 func (hdb *HostDB) UpdateContracts(contracts []modules.RenterContract) error {
@@ -1185,3 +1185,154 @@ func TestFilterModeNetAddresses(t *testing.T) {
 		t.Fatal("entry3 wrongly marked as filtered")
 	}
 }
+
+// TestSelectionPolicy tests setting and persisting the host selection policy.
+func TestSelectionPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdbt, err := newHDBTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Write a GeoIP database.
+	dbPath := filepath.Join(hdbt.persistDir, "geoip.csv")
+	err = ioutil.WriteFile(dbPath, []byte("1.0.0.0/8,DE,100\n2.0.0.0/8,US,200\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid policies are rejected.
+	err = hdbt.hdb.SetSelectionPolicy(modules.HostSelectionPolicy{MinRegions: 2})
+	if err == nil {
+		t.Fatal("policy without database should be rejected")
+	}
+	err = hdbt.hdb.SetSelectionPolicy(modules.HostSelectionPolicy{MinRegions: 2, GeoIPDatabase: filepath.Join(hdbt.persistDir, "missing.csv")})
+	if err == nil {
+		t.Fatal("policy with missing database should be rejected")
+	}
+
+	// Set a valid policy.
+	policy := modules.HostSelectionPolicy{
+		GeoIPDatabase:         dbPath,
+		MaxHostsPerASN:        1,
+		PreferredRegions:      []string{"DE"},
+		PreferredRegionWeight: 5,
+	}
+	if err := hdbt.hdb.SetSelectionPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	p, err := hdbt.hdb.SelectionPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.MaxHostsPerASN != policy.MaxHostsPerASN || p.GeoIPDatabase != dbPath {
+		t.Fatal("policy wasn't set", p)
+	}
+
+	// Hosts within the preferred region should have a higher score.
+	entryDE := makeHostDBEntry()
+	entryDE.IPNets = []string{"1.2.3.0/24"}
+	entryUS := makeHostDBEntry()
+	entryUS.IPNets = []string{"2.2.3.0/24"}
+	sbDE, err := hdbt.hdb.ScoreBreakdown(entryDE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sbUS, err := hdbt.hdb.ScoreBreakdown(entryUS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sbDE.RegionAdjustment != 5 || sbUS.RegionAdjustment != 1 {
+		t.Fatal("wrong region adjustments", sbDE.RegionAdjustment, sbUS.RegionAdjustment)
+	}
+
+	// Restart the hostdb and check that the policy was persisted.
+	if err := hdbt.hdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var errChan <-chan error
+	hdbt.hdb, errChan = NewCustomHostDB(hdbt.gateway, hdbt.cs, hdbt.tpool, hdbt.mux, filepath.Join(hdbt.persistDir, modules.RenterDir), &quitAfterLoadDeps{})
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	hdbt.hdb.mu.RLock()
+	loadedPolicy := hdbt.hdb.selectionPolicy
+	geoIPDB := hdbt.hdb.geoIPDB
+	hdbt.hdb.mu.RUnlock()
+	if loadedPolicy.MaxHostsPerASN != policy.MaxHostsPerASN || geoIPDB == nil {
+		t.Fatal("policy wasn't loaded", loadedPolicy)
+	}
+
+	// Only the newer of two hosts within the same AS violates the policy.
+	entryDE.LastIPNetChange = time.Now().Add(-time.Hour)
+	entryDE2 := makeHostDBEntry()
+	entryDE2.IPNets = []string{"1.3.3.0/24"}
+	entryDE2.LastIPNetChange = time.Now()
+	entryUS.LastIPNetChange = time.Now()
+	for _, entry := range []modules.HostDBEntry{entryDE2, entryDE, entryUS} {
+		if err := hdbt.hdb.staticHostTree.Insert(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	pks := []types.SiaPublicKey{entryDE.PublicKey, entryDE2.PublicKey, entryUS.PublicKey}
+	badHosts, err := hdbt.hdb.CheckForPolicyViolations(pks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(badHosts) != 1 || !badHosts[0].Equals(entryDE2.PublicKey) {
+		t.Fatal("wrong hosts violate the policy", badHosts)
+	}
+
+	// Restart the hostdb without the database. The policy is kept but no
+	// hosts are selected until the database is available again.
+	if err := hdbt.hdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(dbPath); err != nil {
+		t.Fatal(err)
+	}
+	hdbt.hdb, errChan = NewCustomHostDB(hdbt.gateway, hdbt.cs, hdbt.tpool, hdbt.mux, filepath.Join(hdbt.persistDir, modules.RenterDir), &quitAfterLoadDeps{})
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	hdbt.hdb.mu.Lock()
+	hdbt.hdb.initialScanComplete = true
+	loadedPolicy = hdbt.hdb.selectionPolicy
+	hdbt.hdb.mu.Unlock()
+	if loadedPolicy.MaxHostsPerASN != policy.MaxHostsPerASN {
+		t.Fatal("policy wasn't loaded", loadedPolicy)
+	}
+	_, errAlerts, _, _ := hdbt.hdb.Alerts()
+	if len(errAlerts) != 1 || errAlerts[0].Msg != AlertMSGGeoIPDatabase {
+		t.Fatal("expected an alert for the missing database", errAlerts)
+	}
+	if _, err := hdbt.hdb.RandomHosts(1, nil, nil); !errors.Contains(err, errGeoIPDatabaseUnavailable) {
+		t.Fatal("expected errGeoIPDatabaseUnavailable", err)
+	}
+	if _, err := hdbt.hdb.CheckForPolicyViolations(pks); !errors.Contains(err, errGeoIPDatabaseUnavailable) {
+		t.Fatal("expected errGeoIPDatabaseUnavailable", err)
+	}
+
+	// Setting the same policy again fails silently until the database is
+	// back.
+	if err := hdbt.hdb.SetSelectionPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	err = ioutil.WriteFile(dbPath, []byte("1.0.0.0/8,DE,100\n2.0.0.0/8,US,200\n"), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := hdbt.hdb.SetSelectionPolicy(policy); err != nil {
+		t.Fatal(err)
+	}
+	_, errAlerts, _, _ = hdbt.hdb.Alerts()
+	if len(errAlerts) != 0 {
+		t.Fatal("alert wasn't unregistered", errAlerts)
+	}
+	if _, err := hdbt.hdb.RandomHosts(1, nil, nil); errors.Contains(err, errGeoIPDatabaseUnavailable) {
+		t.Fatal("database should be available", err)
+	}
+}
@@ -2,6 +2,7 @@ package hostdb
 
 import (
 	"math"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/modules"
@@ -73,6 +74,15 @@ func updateHostHistoricInteractions(host *modules.HostDBEntry, bh types.BlockHei
 	host.LastHistoricUpdate = bh
 }
 
+// updateHostHistoricAudits applies the audit decay to the historic audits of a
+// host entry. This should be called every time before a new audit result is
+// added to the entry.
+func updateHostHistoricAudits(host *modules.HostDBEntry) {
+	host.HistoricSuccessfulAudits *= historicAuditDecay
+	host.HistoricFailedAudits *= historicAuditDecay
+	host.LastAuditTime = time.Now()
+}
+
 // IncrementSuccessfulInteractions increments the number of successful
 // interactions with a host for a given key
 func (hdb *HostDB) IncrementSuccessfulInteractions(key types.SiaPublicKey) error {
@@ -129,3 +139,75 @@ func (hdb *HostDB) IncrementFailedInteractions(key types.SiaPublicKey) error {
 	hdb.staticHostTree.Modify(host)
 	return nil
 }
+
+// IncrementSuccessfulAudits increments the number of successful audits of a
+// host for a given key
+func (hdb *HostDB) IncrementSuccessfulAudits(key types.SiaPublicKey) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// Fetch the host.
+	host, haveHost := hdb.staticHostTree.Select(key)
+	if !haveHost {
+		return errors.AddContext(errHostNotFoundInTree, "unable to increment successful audit:")
+	}
+
+	// Decay the historic values and increment the successful audits.
+	updateHostHistoricAudits(&host)
+	host.HistoricSuccessfulAudits++
+	return hdb.modify(host)
+}
+
+// IncrementFailedAudits increments the number of failed audits of a host for a
+// given key
+func (hdb *HostDB) IncrementFailedAudits(key types.SiaPublicKey) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// Fetch the host.
+	host, haveHost := hdb.staticHostTree.Select(key)
+	if !haveHost {
+		return errors.AddContext(errHostNotFoundInTree, "unable to increment failed audit:")
+	}
+
+	// Decay the historic values and increment the failed audits.
+	updateHostHistoricAudits(&host)
+	host.HistoricFailedAudits++
+	return hdb.modify(host)
+}
+
+// UpdateHostLatency updates the latency and throughput measured for the host
+// with the given key. A throughput of 0 means that the throughput is unknown
+// and the previous measurement is kept.
+func (hdb *HostDB) UpdateHostLatency(key types.SiaPublicKey, latency time.Duration, throughput float64) error {
+	if err := hdb.tg.Add(); err != nil {
+		return errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+
+	// Fetch the host.
+	host, haveHost := hdb.staticHostTree.Select(key)
+	if !haveHost {
+		return errors.AddContext(errHostNotFoundInTree, "unable to update host latency:")
+	}
+
+	host.Latency = latency
+	if throughput > 0 {
+		host.Throughput = throughput
+	}
+	host.LastLatencyUpdate = time.Now()
+	return hdb.modify(host)
+}
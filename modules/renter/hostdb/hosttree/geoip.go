@@ -0,0 +1,220 @@
+package hosttree
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+type (
+	// GeoLocation describes where a host is located.
+	GeoLocation struct {
+		Country string
+		ASN     uint32
+	}
+
+	// GeoIPDatabase maps IP networks to their location. It is loaded from a
+	// local file and can't be modified after loading, which makes it safe to
+	// use from multiple threads.
+	GeoIPDatabase struct {
+		// networks maps a prefix length to the networks of that length. The
+		// networks are keyed by their masked IP. IPv4 and IPv6 networks are
+		// kept apart to avoid collisions between the two.
+		networks4 map[int]map[string]GeoLocation
+		networks6 map[int]map[string]GeoLocation
+
+		// ranges contains the IP ranges of the database sorted by their
+		// first IP. The IPs are stored in their 16 byte form.
+		ranges []geoIPRange
+	}
+
+	// geoIPRange is an IP range of the database.
+	geoIPRange struct {
+		start net.IP
+		end   net.IP
+		loc   GeoLocation
+	}
+)
+
+// LoadGeoIPDatabase loads a GeoIP database from disk. Two formats are
+// supported. The file can be the ip2asn-combined.tsv database published by
+// iptoasn.com, whose tab separated lines have the format
+//
+//	<first IP>	<last IP>	<AS number>	<country code>	<AS description>
+//
+// Alternatively, the lines can have the format
+//
+//	<network in CIDR notation>,<country code>,<AS number>
+//
+// e.g. "1.2.3.0/24,DE,3320", which allows for creating a custom database. The
+// AS number may be omitted. Empty lines and lines that start with '#' are
+// ignored. The formats can be mixed, networks take precedence over ranges.
+func LoadGeoIPDatabase(path string) (_ *GeoIPDatabase, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to open GeoIP database")
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+
+	db := &GeoIPDatabase{
+		networks4: make(map[int]map[string]GeoLocation),
+		networks6: make(map[int]map[string]GeoLocation),
+	}
+	scanner := bufio.NewScanner(f)
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if err := db.addLine(line); err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("invalid GeoIP database entry on line %v", lineNum))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.AddContext(err, "failed to read GeoIP database")
+	}
+	sort.Slice(db.ranges, func(i, j int) bool {
+		return bytes.Compare(db.ranges[i].start, db.ranges[j].start) < 0
+	})
+	return db, nil
+}
+
+// addLine parses a line of the database file and adds it to the database.
+func (db *GeoIPDatabase) addLine(line string) error {
+	if strings.Contains(line, "\t") {
+		return db.addRangeLine(line)
+	}
+	fields := strings.Split(line, ",")
+	if len(fields) < 2 || len(fields) > 3 {
+		return fmt.Errorf("expected 2 or 3 fields but got %v", len(fields))
+	}
+	_, ipnet, err := net.ParseCIDR(strings.TrimSpace(fields[0]))
+	if err != nil {
+		return err
+	}
+	loc := GeoLocation{
+		Country: strings.ToUpper(strings.TrimSpace(fields[1])),
+	}
+	if len(fields) == 3 && strings.TrimSpace(fields[2]) != "" {
+		asn, err := strconv.ParseUint(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(fields[2])), "AS"), 10, 32)
+		if err != nil {
+			return errors.AddContext(err, "invalid AS number")
+		}
+		loc.ASN = uint32(asn)
+	}
+
+	ones, _ := ipnet.Mask.Size()
+	networks := db.networks6
+	if ipnet.IP.To4() != nil {
+		networks = db.networks4
+	}
+	if _, exists := networks[ones]; !exists {
+		networks[ones] = make(map[string]GeoLocation)
+	}
+	networks[ones][ipnet.IP.String()] = loc
+	return nil
+}
+
+// addRangeLine parses a line of an ip2asn database and adds it to the
+// database. Ranges that aren't routed have the AS number 0 and the country
+// code "None". They are skipped.
+func (db *GeoIPDatabase) addRangeLine(line string) error {
+	fields := strings.Split(line, "\t")
+	if len(fields) < 4 {
+		return fmt.Errorf("expected at least 4 fields but got %v", len(fields))
+	}
+	start, end := net.ParseIP(fields[0]), net.ParseIP(fields[1])
+	if start == nil || end == nil {
+		return errors.New("invalid IP range")
+	}
+	start, end = start.To16(), end.To16()
+	if bytes.Compare(start, end) > 0 {
+		return errors.New("first IP of range is greater than last IP")
+	}
+	asn, err := strconv.ParseUint(fields[2], 10, 32)
+	if err != nil {
+		return errors.AddContext(err, "invalid AS number")
+	}
+	country := strings.ToUpper(strings.TrimSpace(fields[3]))
+	if asn == 0 && country == "NONE" {
+		return nil
+	}
+	if len(country) != 2 {
+		country = ""
+	}
+	db.ranges = append(db.ranges, geoIPRange{
+		start: start,
+		end:   end,
+		loc:   GeoLocation{Country: country, ASN: uint32(asn)},
+	})
+	return nil
+}
+
+// Lookup returns the location of the given IP. The most specific network
+// containing the IP is used. If no network contains the IP, the range that
+// contains it is used.
+func (db *GeoIPDatabase) Lookup(ip net.IP) (GeoLocation, bool) {
+	if db == nil || ip == nil {
+		return GeoLocation{}, false
+	}
+	if loc, ok := db.lookupNetworks(ip); ok {
+		return loc, true
+	}
+	return db.lookupRanges(ip)
+}
+
+// lookupRanges returns the location of the range that contains the given IP.
+func (db *GeoIPDatabase) lookupRanges(ip net.IP) (GeoLocation, bool) {
+	ip = ip.To16()
+	i := sort.Search(len(db.ranges), func(i int) bool {
+		return bytes.Compare(db.ranges[i].start, ip) > 0
+	})
+	if i == 0 || bytes.Compare(ip, db.ranges[i-1].end) > 0 {
+		return GeoLocation{}, false
+	}
+	return db.ranges[i-1].loc, true
+}
+
+// lookupNetworks returns the location of the most specific network that
+// contains the given IP.
+func (db *GeoIPDatabase) lookupNetworks(ip net.IP) (GeoLocation, bool) {
+	networks, bits := db.networks6, 128
+	if ip4 := ip.To4(); ip4 != nil {
+		networks, bits, ip = db.networks4, 32, ip4
+	}
+	for ones := bits; ones >= 0; ones-- {
+		nets, exists := networks[ones]
+		if !exists {
+			continue
+		}
+		if loc, exists := nets[ip.Mask(net.CIDRMask(ones, bits)).String()]; exists {
+			return loc, true
+		}
+	}
+	return GeoLocation{}, false
+}
+
+// LookupIPNets returns the location of the first of the given subnets that can
+// be located. The subnets are expected to be in the format of the IPNets field
+// of a HostDBEntry.
+func (db *GeoIPDatabase) LookupIPNets(ipNets []string) (GeoLocation, bool) {
+	for _, ipNet := range ipNets {
+		ip, _, err := net.ParseCIDR(ipNet)
+		if err != nil {
+			ip = net.ParseIP(ipNet)
+		}
+		if loc, ok := db.Lookup(ip); ok {
+			return loc, true
+		}
+	}
+	return GeoLocation{}, false
+}
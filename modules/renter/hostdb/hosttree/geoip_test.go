@@ -0,0 +1,131 @@
+package hosttree
+
+import (
+	"io/ioutil"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.sia.tech/siad/build"
+)
+
+// testGeoIPDatabase is the content of the GeoIP database used in the tests.
+const testGeoIPDatabase = `# network,country,asn
+1.0.0.0/8,US,100
+1.2.0.0/16,DE,AS200
+2.0.0.0/8,FR,300
+3.0.0.0/8,DE,400
+4.0.0.0/8,JP
+2001:db8::/32,NL,500
+`
+
+// newTestGeoIPDatabase writes the test GeoIP database to disk and loads it.
+func newTestGeoIPDatabase(t *testing.T) *GeoIPDatabase {
+	dir := build.TempDir("hosttree", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "geoip.csv")
+	if err := ioutil.WriteFile(path, []byte(testGeoIPDatabase), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadGeoIPDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// TestGeoIPDatabase tests loading a GeoIP database and looking up IPs.
+func TestGeoIPDatabase(t *testing.T) {
+	t.Parallel()
+	db := newTestGeoIPDatabase(t)
+
+	tests := []struct {
+		ip      string
+		loc     GeoLocation
+		located bool
+	}{
+		{"1.1.1.1", GeoLocation{"US", 100}, true},
+		{"1.2.3.4", GeoLocation{"DE", 200}, true},
+		{"2.2.2.2", GeoLocation{"FR", 300}, true},
+		{"4.4.4.4", GeoLocation{"JP", 0}, true},
+		{"5.5.5.5", GeoLocation{}, false},
+		{"2001:db8::1", GeoLocation{"NL", 500}, true},
+		{"2001:db9::1", GeoLocation{}, false},
+	}
+	for _, test := range tests {
+		loc, ok := db.Lookup(net.ParseIP(test.ip))
+		if ok != test.located || loc != test.loc {
+			t.Errorf("%v: expected %v %v but got %v %v", test.ip, test.loc, test.located, loc, ok)
+		}
+	}
+
+	// Lookup by subnets.
+	loc, ok := db.LookupIPNets([]string{"5.5.5.0/24", "1.2.3.0/24"})
+	if !ok || loc.Country != "DE" {
+		t.Fatal("wrong location", loc, ok)
+	}
+
+	// A nil database can't locate anything.
+	var nilDB *GeoIPDatabase
+	if _, ok := nilDB.LookupIPNets([]string{"1.2.3.0/24"}); ok {
+		t.Fatal("nil database shouldn't locate hosts")
+	}
+
+	// Invalid lines are rejected.
+	dir := build.TempDir("hosttree", t.Name(), "invalid")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "invalid.csv")
+	if err := ioutil.WriteFile(path, []byte("1.2.3.0/24,DE,notanumber\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadGeoIPDatabase(path); err == nil {
+		t.Fatal("expected invalid database to fail loading")
+	}
+}
+
+// TestGeoIPDatabaseRanges tests loading a database in the ip2asn format.
+func TestGeoIPDatabaseRanges(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("hosttree", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "ip2asn-combined.tsv")
+	ranges := "1.0.0.0\t1.0.0.255\t13335\tUS\tCLOUDFLARENET\n" +
+		"1.0.1.0\t1.0.3.255\t0\tNone\tNot routed\n" +
+		"1.0.4.0\t1.0.7.255\t38803\tAU\tGTELECOM\n" +
+		"2a00::\t2a00:ffff:ffff:ffff:ffff:ffff:ffff:ffff\t3320\tDE\tDTAG\n"
+	if err := ioutil.WriteFile(path, []byte(ranges), 0600); err != nil {
+		t.Fatal(err)
+	}
+	db, err := LoadGeoIPDatabase(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		ip      string
+		loc     GeoLocation
+		located bool
+	}{
+		{"0.255.255.255", GeoLocation{}, false},
+		{"1.0.0.0", GeoLocation{"US", 13335}, true},
+		{"1.0.0.255", GeoLocation{"US", 13335}, true},
+		{"1.0.2.1", GeoLocation{}, false},
+		{"1.0.5.1", GeoLocation{"AU", 38803}, true},
+		{"1.0.8.0", GeoLocation{}, false},
+		{"2a00:1::1", GeoLocation{"DE", 3320}, true},
+		{"2a01::1", GeoLocation{}, false},
+	}
+	for _, test := range tests {
+		loc, ok := db.Lookup(net.ParseIP(test.ip))
+		if ok != test.located || loc != test.loc {
+			t.Errorf("%v: expected %v %v but got %v %v", test.ip, test.loc, test.located, loc, ok)
+		}
+	}
+}
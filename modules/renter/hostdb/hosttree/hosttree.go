@@ -306,6 +306,15 @@ func (ht *HostTree) Select(spk types.SiaPublicKey) (modules.HostDBEntry, bool) {
 // intentionally being given a low score to indicate that the host should not be
 // used.
 func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaPublicKey) []modules.HostDBEntry {
+	return ht.SelectRandomWithPolicy(n, blacklist, addressBlacklist, nil)
+}
+
+// SelectRandomWithPolicy works like SelectRandom but additionally enforces the
+// host selection policy of the provided PolicyFilter. Hosts that don't add a
+// new region while the policy's minimum number of regions isn't reached yet
+// are only returned if there are not enough other hosts. The PolicyFilter may
+// be nil.
+func (ht *HostTree) SelectRandomWithPolicy(n int, blacklist, addressBlacklist []types.SiaPublicKey, pf *PolicyFilter) []modules.HostDBEntry {
 	ht.mu.Lock()
 	defer ht.mu.Unlock()
 
@@ -339,6 +348,7 @@ func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaP
 	}
 
 	var hosts []modules.HostDBEntry
+	var deferredEntries []*hostEntry
 
 	for len(hosts) < n && len(ht.hosts) > 0 {
 		randWeight := fastrand.BigIntn(ht.root.weight.Big())
@@ -349,14 +359,22 @@ func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaP
 			len(node.entry.ScanHistory) > 0 &&
 			node.entry.ScanHistory[len(node.entry.ScanHistory)-1].Success &&
 			!filter.Filtered(node.entry.NetAddress) &&
+			(pf == nil || !pf.Filtered(node.entry.HostDBEntry)) &&
 			node.entry.weight.Cmp(weightOne) > 0 {
 			// The host must be online and accepting contracts to be returned
 			// by the random function. It also has to pass the addressFilter
-			// check.
-			hosts = append(hosts, node.entry.HostDBEntry)
-
-			// If the host passed the filter, we add it to the filter.
-			filter.Add(node.entry.NetAddress)
+			// and policy checks.
+			if pf != nil && pf.NeedsNewRegion(node.entry.HostDBEntry) {
+				deferredEntries = append(deferredEntries, node.entry)
+			} else {
+				hosts = append(hosts, node.entry.HostDBEntry)
+
+				// If the host passed the filters, we add it to the filters.
+				filter.Add(node.entry.NetAddress)
+				if pf != nil {
+					pf.Add(node.entry.HostDBEntry)
+				}
+			}
 		}
 
 		removedEntries = append(removedEntries, node.entry)
@@ -364,6 +382,20 @@ func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaP
 		delete(ht.hosts, node.entry.PublicKey.String())
 	}
 
+	// If there are not enough hosts in new regions, fall back to the hosts
+	// that were skipped for not adding a new region.
+	for _, entry := range deferredEntries {
+		if len(hosts) >= n {
+			break
+		}
+		if filter.Filtered(entry.NetAddress) || pf.Filtered(entry.HostDBEntry) {
+			continue
+		}
+		hosts = append(hosts, entry.HostDBEntry)
+		filter.Add(entry.NetAddress)
+		pf.Add(entry.HostDBEntry)
+	}
+
 	for _, entry := range removedEntries {
 		_, node := ht.root.recursiveInsert(entry)
 		ht.hosts[entry.PublicKey.String()] = node
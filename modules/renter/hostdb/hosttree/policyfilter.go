@@ -0,0 +1,83 @@
+package hosttree
+
+import (
+	"strings"
+
+	"go.sia.tech/siad/modules"
+)
+
+// PolicyFilter enforces a modules.HostSelectionPolicy while selecting hosts.
+// Similar to the Filter, hosts that are already used by the renter are added
+// to the PolicyFilter before selecting new ones.
+type PolicyFilter struct {
+	db     *GeoIPDatabase
+	policy modules.HostSelectionPolicy
+
+	allowed   map[string]struct{}
+	asns      map[uint32]int
+	countries map[string]int
+}
+
+// NewPolicyFilter creates a new PolicyFilter for the given policy. The db is
+// used to locate the hosts.
+func NewPolicyFilter(policy modules.HostSelectionPolicy, db *GeoIPDatabase) *PolicyFilter {
+	pf := &PolicyFilter{
+		db:        db,
+		policy:    policy,
+		allowed:   make(map[string]struct{}),
+		asns:      make(map[uint32]int),
+		countries: make(map[string]int),
+	}
+	for _, region := range policy.AllowedRegions {
+		pf.allowed[strings.ToUpper(region)] = struct{}{}
+	}
+	return pf
+}
+
+// Add adds a host to the filter, counting it towards the policy's limits.
+func (pf *PolicyFilter) Add(entry modules.HostDBEntry) {
+	loc, ok := pf.db.LookupIPNets(entry.IPNets)
+	if !ok {
+		return
+	}
+	if loc.ASN != 0 {
+		pf.asns[loc.ASN]++
+	}
+	if loc.Country != "" {
+		pf.countries[loc.Country]++
+	}
+}
+
+// Filtered returns true if adding the host would violate the policy.
+func (pf *PolicyFilter) Filtered(entry modules.HostDBEntry) bool {
+	loc, ok := pf.db.LookupIPNets(entry.IPNets)
+	if len(pf.allowed) > 0 {
+		if _, allowed := pf.allowed[loc.Country]; !ok || !allowed {
+			return true
+		}
+	}
+	if !ok {
+		return false
+	}
+	if pf.policy.MaxHostsPerASN > 0 && loc.ASN != 0 && pf.asns[loc.ASN] >= pf.policy.MaxHostsPerASN {
+		return true
+	}
+	if pf.policy.MaxHostsPerCountry > 0 && loc.Country != "" && pf.countries[loc.Country] >= pf.policy.MaxHostsPerCountry {
+		return true
+	}
+	return false
+}
+
+// NeedsNewRegion returns true if the policy's minimum number of regions isn't
+// reached yet and the host wouldn't add a new region.
+func (pf *PolicyFilter) NeedsNewRegion(entry modules.HostDBEntry) bool {
+	if len(pf.countries) >= pf.policy.MinRegions {
+		return false
+	}
+	loc, ok := pf.db.LookupIPNets(entry.IPNets)
+	if !ok || loc.Country == "" {
+		return true
+	}
+	_, exists := pf.countries[loc.Country]
+	return exists
+}
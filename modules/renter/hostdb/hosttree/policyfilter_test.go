@@ -0,0 +1,93 @@
+package hosttree
+
+import (
+	"fmt"
+	"testing"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// makePolicyTestEntry creates a host entry using the /24 subnet of the given
+// IP.
+func makePolicyTestEntry(ip string) modules.HostDBEntry {
+	entry := makeHostDBEntry()
+	entry.NetAddress = modules.NetAddress(fmt.Sprintf("%v:9982", ip))
+	entry.IPNets = []string{fmt.Sprintf("%v/24", ip)}
+	return entry
+}
+
+// TestSelectRandomWithPolicy tests that SelectRandomWithPolicy enforces the
+// host selection policy.
+func TestSelectRandomWithPolicy(t *testing.T) {
+	t.Parallel()
+	db := newTestGeoIPDatabase(t)
+
+	tree := New(func(dbe modules.HostDBEntry) ScoreBreakdown {
+		return newCustomScoreBreakdown(types.NewCurrency64(20))
+	}, modules.ProductionResolver{})
+
+	// Insert 5 hosts in the US within the same ASN, 2 in France and one in
+	// Germany.
+	var entries []modules.HostDBEntry
+	for i := 0; i < 5; i++ {
+		entries = append(entries, makePolicyTestEntry(fmt.Sprintf("1.1.%v.0", i)))
+	}
+	entries = append(entries, makePolicyTestEntry("2.1.1.0"))
+	entries = append(entries, makePolicyTestEntry("2.1.2.0"))
+	entries = append(entries, makePolicyTestEntry("3.1.1.0"))
+	for _, entry := range entries {
+		if err := tree.Insert(entry); err != nil {
+			t.Fatal(err)
+		}
+	}
+	countries := func(hosts []modules.HostDBEntry) map[string]int {
+		m := make(map[string]int)
+		for _, host := range hosts {
+			loc, _ := db.LookupIPNets(host.IPNets)
+			m[loc.Country]++
+		}
+		return m
+	}
+
+	// Without a policy all hosts are returned.
+	if hosts := tree.SelectRandomWithPolicy(len(entries), nil, nil, nil); len(hosts) != len(entries) {
+		t.Fatal("expected all hosts", len(hosts))
+	}
+
+	// Limit the hosts per ASN.
+	pf := NewPolicyFilter(modules.HostSelectionPolicy{MaxHostsPerASN: 2}, db)
+	hosts := tree.SelectRandomWithPolicy(len(entries), nil, nil, pf)
+	if c := countries(hosts); len(hosts) != 5 || c["US"] != 2 {
+		t.Fatal("unexpected selection", len(hosts), c)
+	}
+
+	// Limit the hosts per country. Hosts that are already used count towards
+	// the limit.
+	pf = NewPolicyFilter(modules.HostSelectionPolicy{MaxHostsPerCountry: 1}, db)
+	pf.Add(entries[5])
+	hosts = tree.SelectRandomWithPolicy(len(entries), nil, nil, pf)
+	if c := countries(hosts); len(hosts) != 2 || c["US"] != 1 || c["DE"] != 1 {
+		t.Fatal("unexpected selection", len(hosts), c)
+	}
+
+	// Only allow hosts within Germany and France.
+	pf = NewPolicyFilter(modules.HostSelectionPolicy{AllowedRegions: []string{"de", "FR"}}, db)
+	hosts = tree.SelectRandomWithPolicy(len(entries), nil, nil, pf)
+	if c := countries(hosts); len(hosts) != 3 || c["US"] != 0 {
+		t.Fatal("unexpected selection", len(hosts), c)
+	}
+
+	// Require 3 regions. The first 3 hosts need to be in distinct regions,
+	// the remaining hosts are selected afterwards.
+	for i := 0; i < 20; i++ {
+		pf = NewPolicyFilter(modules.HostSelectionPolicy{MinRegions: 3}, db)
+		hosts = tree.SelectRandomWithPolicy(len(entries), nil, nil, pf)
+		if len(hosts) != len(entries) {
+			t.Fatal("expected all hosts", len(hosts))
+		}
+		if c := countries(hosts[:3]); len(c) != 3 {
+			t.Fatal("first hosts should be in distinct regions", c)
+		}
+	}
+}
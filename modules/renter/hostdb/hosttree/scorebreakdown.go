@@ -19,12 +19,16 @@ type ScoreBreakdown interface {
 type HostAdjustments struct {
 	AcceptContractAdjustment   float64
 	AgeAdjustment              float64
+	AuditAdjustment            float64
 	BasePriceAdjustment        float64
 	BurnAdjustment             float64
 	CollateralAdjustment       float64
+	CustomAdjustment           float64
 	DurationAdjustment         float64
 	InteractionAdjustment      float64
+	LatencyAdjustment          float64
 	PriceAdjustment            float64
+	RegionAdjustment           float64
 	StorageRemainingAdjustment float64
 	UptimeAdjustment           float64
 	VersionAdjustment          float64
@@ -71,12 +75,16 @@ func (h HostAdjustments) HostScoreBreakdown(totalScore types.Currency, ignoreAge
 
 		AcceptContractAdjustment:   h.AcceptContractAdjustment,
 		AgeAdjustment:              h.AgeAdjustment,
+		AuditAdjustment:            h.AuditAdjustment,
 		BasePriceAdjustment:        h.BasePriceAdjustment,
 		BurnAdjustment:             h.BurnAdjustment,
 		CollateralAdjustment:       h.CollateralAdjustment,
+		CustomAdjustment:           h.CustomAdjustment,
 		DurationAdjustment:         h.DurationAdjustment,
 		InteractionAdjustment:      h.InteractionAdjustment,
+		LatencyAdjustment:          h.LatencyAdjustment,
 		PriceAdjustment:            h.PriceAdjustment,
+		RegionAdjustment:           h.RegionAdjustment,
 		StorageRemainingAdjustment: h.StorageRemainingAdjustment,
 		UptimeAdjustment:           h.UptimeAdjustment,
 		VersionAdjustment:          h.VersionAdjustment,
@@ -89,12 +97,16 @@ func (h HostAdjustments) Score() types.Currency {
 	// Combine the adjustments.
 	fullPenalty := h.AgeAdjustment *
 		h.AcceptContractAdjustment *
+		h.AuditAdjustment *
 		h.BasePriceAdjustment *
 		h.BurnAdjustment *
 		h.CollateralAdjustment *
+		h.CustomAdjustment *
 		h.DurationAdjustment *
 		h.InteractionAdjustment *
+		h.LatencyAdjustment *
 		h.PriceAdjustment *
+		h.RegionAdjustment *
 		h.StorageRemainingAdjustment *
 		h.UptimeAdjustment *
 		h.VersionAdjustment
@@ -3,6 +3,7 @@ package hostdb
 import (
 	"fmt"
 	"math"
+	"sort"
 	"strings"
 	"time"
 
@@ -15,6 +16,12 @@ import (
 )
 
 const (
+	// auditExponentiation determines how heavily we penalize hosts for failing
+	// audits. A failed audit means that the host was unable to prove that it
+	// still stores data it was paid to store, which is a lot more severe than a
+	// failed interaction, so the exponentiation is very high.
+	auditExponentiation = 20
+
 	// collateralExponentiation is the power to which we raise the weight
 	// during collateral adjustment when the collateral is large. This sublinear
 	// number ensures that there is not an overpreference on collateral when
@@ -81,8 +88,33 @@ const (
 	// This is necessary to prevent exploits where a host gets an unreasonable
 	// score by putting it's price way too low.
 	priceFloor = 0.1
+
+	// latencyRatioCap limits how much the measured latency and throughput of a
+	// host can differ from the reference values before the adjustment stops
+	// changing. This prevents a single outlier measurement from dominating the
+	// score.
+	latencyRatioCap = 10.0
+
+	// defaultPreferredRegionWeight is the multiplier applied to the score of
+	// hosts within a preferred region if the host selection policy doesn't
+	// specify one.
+	defaultPreferredRegionWeight = 10
 )
 
+// auditAdjustments determine the penalty to be applied to a host for failing
+// the renter's audits of the data it stores.
+func (hdb *HostDB) auditAdjustments(entry modules.HostDBEntry) float64 {
+	// Give the host a baseline of 10 successful audits. This prevents the
+	// very first failed audit from immediately dropping the host's score to
+	// almost zero, which could be caused by a transient error on the host.
+	hsa := entry.HistoricSuccessfulAudits + 10
+	hfa := entry.HistoricFailedAudits
+
+	// Determine the audit ratio based off of the historic audits.
+	ratio := hsa / (hsa + hfa)
+	return math.Pow(ratio, auditExponentiation)
+}
+
 // basePriceAdjustments will adjust the weight of the entry according to the prices
 // that it has set for BaseRPCPrice and SectorAccessPrice
 func (hdb *HostDB) basePriceAdjustments(entry modules.HostDBEntry) float64 {
@@ -346,6 +378,50 @@ func (hdb *HostDB) priceAdjustments(entry modules.HostDBEntry, allowance modules
 	return 1 / (smallWeight * largeWeight)
 }
 
+// latencyAdjustments adjusts the weight of the entry according to the latency
+// and throughput measured by the renter's workers. Hosts that are faster than
+// the reference values are rewarded, slower hosts are penalized. Hosts without
+// measurements are not adjusted. The measurements are kept up to date by the
+// workers, which update the host's entry and therefore its score.
+func latencyAdjustments(entry modules.HostDBEntry, weight float64) float64 {
+	if weight == 0 || entry.LastLatencyUpdate.IsZero() {
+		return 1
+	}
+	capRatio := func(ratio float64) float64 {
+		return math.Max(1.0/latencyRatioCap, math.Min(latencyRatioCap, ratio))
+	}
+	adjustment := 1.0
+	if entry.Latency > 0 {
+		adjustment *= capRatio(float64(latencyReference) / float64(entry.Latency))
+	}
+	if entry.Throughput > 0 {
+		adjustment *= capRatio(entry.Throughput / throughputReference)
+	}
+	return math.Pow(adjustment, weight)
+}
+
+// regionAdjustments adjusts the weight of the entry according to the preferred
+// regions of the host selection policy.
+func regionAdjustments(entry modules.HostDBEntry, policy modules.HostSelectionPolicy, db *hosttree.GeoIPDatabase) float64 {
+	if len(policy.PreferredRegions) == 0 {
+		return 1
+	}
+	loc, ok := db.LookupIPNets(entry.IPNets)
+	if !ok {
+		return 1
+	}
+	for _, region := range policy.PreferredRegions {
+		if !strings.EqualFold(region, loc.Country) {
+			continue
+		}
+		if policy.PreferredRegionWeight == 0 {
+			return defaultPreferredRegionWeight
+		}
+		return policy.PreferredRegionWeight
+	}
+	return 1
+}
+
 // storageRemainingAdjustments adjusts the weight of the entry according to how
 // much storage it has remaining.
 func (hdb *HostDB) storageRemainingAdjustments(entry modules.HostDBEntry, allowance modules.Allowance) float64 {
@@ -453,6 +529,46 @@ func (hdb *HostDB) lifetimeAdjustments(entry modules.HostDBEntry) float64 {
 	return base
 }
 
+// measuredUptime returns the total uptime and downtime of the host as measured
+// by the hostdb's scans.
+func (hdb *HostDB) measuredUptime(entry modules.HostDBEntry) (uptime, downtime time.Duration) {
+	downtime = entry.HistoricDowntime
+	uptime = entry.HistoricUptime
+	if len(entry.ScanHistory) == 0 {
+		return uptime, downtime
+	}
+	recentTime := entry.ScanHistory[0].Timestamp
+	recentSuccess := entry.ScanHistory[0].Success
+	for _, scan := range entry.ScanHistory[1:] {
+		if recentTime.After(scan.Timestamp) {
+			if build.DEBUG {
+				hdb.staticLog.Critical("Host entry scan history not sorted.")
+			} else {
+				hdb.staticLog.Print("WARN: Host entry scan history not sorted.")
+			}
+			// Ignore the unsorted scan entry.
+			continue
+		}
+		if recentSuccess {
+			uptime += scan.Timestamp.Sub(recentTime)
+		} else {
+			downtime += scan.Timestamp.Sub(recentTime)
+		}
+		recentTime = scan.Timestamp
+		recentSuccess = scan.Success
+	}
+
+	// One more check to incorporate the uptime or downtime of the most recent
+	// scan, we assume that if we scanned them right now, their uptime /
+	// downtime status would be equal to what it currently is.
+	if recentSuccess {
+		uptime += time.Now().Sub(recentTime)
+	} else {
+		downtime += time.Now().Sub(recentTime)
+	}
+	return uptime, downtime
+}
+
 // uptimeAdjustments penalizes the host for having poor uptime, and for being
 // offline.
 //
@@ -489,37 +605,7 @@ func (hdb *HostDB) uptimeAdjustments(entry modules.HostDBEntry) float64 {
 
 	// Compute the total measured uptime and total measured downtime for this
 	// host.
-	downtime := entry.HistoricDowntime
-	uptime := entry.HistoricUptime
-	recentTime := entry.ScanHistory[0].Timestamp
-	recentSuccess := entry.ScanHistory[0].Success
-	for _, scan := range entry.ScanHistory[1:] {
-		if recentTime.After(scan.Timestamp) {
-			if build.DEBUG {
-				hdb.staticLog.Critical("Host entry scan history not sorted.")
-			} else {
-				hdb.staticLog.Print("WARN: Host entry scan history not sorted.")
-			}
-			// Ignore the unsorted scan entry.
-			continue
-		}
-		if recentSuccess {
-			uptime += scan.Timestamp.Sub(recentTime)
-		} else {
-			downtime += scan.Timestamp.Sub(recentTime)
-		}
-		recentTime = scan.Timestamp
-		recentSuccess = scan.Success
-	}
-
-	// One more check to incorporate the uptime or downtime of the most recent
-	// scan, we assume that if we scanned them right now, their uptime /
-	// downtime status would be equal to what it currently is.
-	if recentSuccess {
-		uptime += time.Now().Sub(recentTime)
-	} else {
-		downtime += time.Now().Sub(recentTime)
-	}
+	uptime, downtime := hdb.measuredUptime(entry)
 
 	// Sanity check against 0 total time.
 	if uptime == 0 && downtime == 0 {
@@ -563,21 +649,40 @@ func (hdb *HostDB) uptimeAdjustments(entry modules.HostDBEntry) float64 {
 // NOTE: the hosttree.WeightFunc that is returned accesses fields of the hostdb.
 // The hostdb lock must be held while utilizing the WeightFunc
 func (hdb *HostDB) managedCalculateHostWeightFn(allowance modules.Allowance) hosttree.WeightFunc {
-	// Get the txnFees.
+	hdb.mu.RLock()
+	rules := hdb.compiledScoringRules
+	hdb.mu.RUnlock()
+	return hdb.managedCalculateHostWeightFnWithRules(allowance, rules)
+}
+
+// managedCalculateHostWeightFnWithRules creates a hosttree.WeightFunc given an
+// Allowance and a set of compiled scoring rules.
+//
+// NOTE: the hosttree.WeightFunc that is returned accesses fields of the hostdb.
+// The hostdb lock must be held while utilizing the WeightFunc
+func (hdb *HostDB) managedCalculateHostWeightFnWithRules(allowance modules.Allowance, rules []scoringRule) hosttree.WeightFunc {
+	// Get the txnFees and the selection policy.
 	hdb.mu.RLock()
 	txnFees := hdb.txnFees
+	policy := hdb.selectionPolicy
+	geoIPDB := hdb.geoIPDB
+	latencyWeight := hdb.latencyWeight
 	hdb.mu.RUnlock()
 	// Create the weight function.
 	return func(entry modules.HostDBEntry) hosttree.ScoreBreakdown {
 		return hosttree.HostAdjustments{
 			AcceptContractAdjustment:   hdb.acceptContractAdjustments(entry),
 			AgeAdjustment:              hdb.lifetimeAdjustments(entry),
+			AuditAdjustment:            hdb.auditAdjustments(entry),
 			BasePriceAdjustment:        hdb.basePriceAdjustments(entry),
 			BurnAdjustment:             1,
 			CollateralAdjustment:       hdb.collateralAdjustments(entry, allowance),
+			CustomAdjustment:           hdb.customAdjustments(entry, rules),
 			DurationAdjustment:         hdb.durationAdjustments(entry, allowance),
 			InteractionAdjustment:      hdb.interactionAdjustments(entry),
+			LatencyAdjustment:          latencyAdjustments(entry, latencyWeight),
 			PriceAdjustment:            hdb.priceAdjustments(entry, allowance, txnFees),
+			RegionAdjustment:           regionAdjustments(entry, policy, geoIPDB),
 			StorageRemainingAdjustment: hdb.storageRemainingAdjustments(entry, allowance),
 			UptimeAdjustment:           hdb.uptimeAdjustments(entry),
 			VersionAdjustment:          versionAdjustments(entry),
@@ -605,6 +710,50 @@ func (hdb *HostDB) ScoreBreakdown(entry modules.HostDBEntry) (modules.HostScoreB
 	return hdb.managedScoreBreakdown(entry, false, false, false)
 }
 
+// PreviewScoringRules returns the score breakdowns the active hosts would have
+// if the given scoring rules were applied. The hosts are sorted by their score
+// under the new rules, highest first.
+func (hdb *HostDB) PreviewScoringRules(rules modules.HostScoringRules) ([]modules.HostScorePreview, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer hdb.tg.Done()
+	compiled, err := compileScoringRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	hosts, err := hdb.ActiveHosts()
+	if err != nil {
+		return nil, errors.AddContext(err, "error getting Active hosts:")
+	}
+	hdb.mu.RLock()
+	allowance := hdb.allowance
+	hdb.mu.RUnlock()
+	weightFunc := hdb.managedCalculateHostWeightFnWithRules(allowance, compiled)
+
+	// Compute the totalScore under the new rules.
+	hdb.mu.Lock()
+	defer hdb.mu.Unlock()
+	totalScore := types.Currency{}
+	for _, host := range hosts {
+		totalScore = totalScore.Add(weightFunc(host).Score())
+	}
+	// Compute the breakdowns.
+	previews := make([]modules.HostScorePreview, 0, len(hosts))
+	for _, host := range hosts {
+		previews = append(previews, modules.HostScorePreview{
+			PublicKey:      host.PublicKey,
+			NetAddress:     host.NetAddress,
+			CurrentScore:   hdb.weightFunc(host).Score(),
+			ScoreBreakdown: weightFunc(host).HostScoreBreakdown(totalScore, false, false, false),
+		})
+	}
+	sort.SliceStable(previews, func(i, j int) bool {
+		return previews[i].ScoreBreakdown.Score.Cmp(previews[j].ScoreBreakdown.Score) > 0
+	})
+	return previews, nil
+}
+
 // managedEstimatedScoreBreakdown computes the score breakdown of a host.
 // Certain adjustments can be ignored.
 func (hdb *HostDB) managedEstimatedScoreBreakdown(entry modules.HostDBEntry, allowance modules.Allowance, ignoreAge, ignoreDuration, ignoreUptime bool) (modules.HostScoreBreakdown, error) {
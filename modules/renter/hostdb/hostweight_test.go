@@ -746,3 +746,78 @@ func TestHostWeightAcceptContract(t *testing.T) {
 		t.Error("Entry2 should have smallest weight")
 	}
 }
+
+// TestHostWeightAuditDifferences checks that hosts which failed audits have
+// lower weights.
+func TestHostWeightAuditDifferences(t *testing.T) {
+	t.Parallel()
+	hdb := bareHostDB()
+	hdb.blockHeight = 10000
+
+	entry := DefaultHostDBEntry
+	entry.HistoricSuccessfulAudits = 100
+
+	entry2 := entry
+	entry2.HistoricFailedAudits = 5
+
+	// A host without any audits shouldn't be penalized.
+	if adj := hdb.auditAdjustments(DefaultHostDBEntry); adj != 1 {
+		t.Fatal("unaudited host should not be penalized", adj)
+	}
+
+	w1 := hdb.weightFunc(entry).Score()
+	w2 := hdb.weightFunc(entry2).Score()
+	if w1.Cmp(w2) <= 0 {
+		t.Log(w1)
+		t.Log(w2)
+		t.Error("A host with failed audits should have a lower score")
+	}
+}
+
+// TestHostWeightLatency checks that the latency adjustment favours responsive
+// hosts if enabled.
+func TestHostWeightLatency(t *testing.T) {
+	t.Parallel()
+
+	fast := DefaultHostDBEntry
+	fast.Latency = 50 * time.Millisecond
+	fast.Throughput = 1 << 24
+	fast.LastLatencyUpdate = time.Now()
+
+	slow := fast
+	slow.Latency = time.Second
+	slow.Throughput = 1 << 20
+
+	// The adjustment is disabled by default.
+	if adj := latencyAdjustments(fast, 0); adj != 1 {
+		t.Fatal("adjustment should be disabled", adj)
+	}
+	// Hosts without measurements aren't adjusted.
+	if adj := latencyAdjustments(DefaultHostDBEntry, 1); adj != 1 {
+		t.Fatal("host without measurements shouldn't be adjusted", adj)
+	}
+
+	fastAdj := latencyAdjustments(fast, 1)
+	slowAdj := latencyAdjustments(slow, 1)
+	if fastAdj <= 1 || slowAdj >= 1 {
+		t.Fatal("unexpected adjustments", fastAdj, slowAdj)
+	}
+	// A higher weight amplifies the adjustment.
+	if latencyAdjustments(fast, 2) <= fastAdj {
+		t.Fatal("higher weight should increase the adjustment")
+	}
+	// The adjustment is capped.
+	extreme := fast
+	extreme.Latency = time.Nanosecond
+	extreme.Throughput = 1 << 40
+	if adj := latencyAdjustments(extreme, 1); adj != latencyRatioCap*latencyRatioCap {
+		t.Fatal("adjustment should be capped", adj)
+	}
+	// The penalty for slow hosts is capped as well.
+	outlier := fast
+	outlier.Latency = time.Hour
+	outlier.Throughput = 1
+	if adj := latencyAdjustments(outlier, 1); math.Abs(adj-1/(latencyRatioCap*latencyRatioCap)) > 1e-9 {
+		t.Fatal("penalty should be capped", adj)
+	}
+}
@@ -33,6 +33,9 @@ type hdbPersist struct {
 	LastChange               modules.ConsensusChangeID
 	FilteredHosts            map[string]types.SiaPublicKey
 	FilterMode               modules.FilterMode
+	SelectionPolicy          modules.HostSelectionPolicy
+	LatencyWeight            float64
+	ScoringRules             modules.HostScoringRules
 }
 
 // persistData returns the data in the hostdb that will be saved to disk.
@@ -45,6 +48,9 @@ func (hdb *HostDB) persistData() (data hdbPersist) {
 	data.LastChange = hdb.lastChange
 	data.FilteredHosts = hdb.filteredHosts
 	data.FilterMode = hdb.filterMode
+	data.SelectionPolicy = hdb.selectionPolicy
+	data.LatencyWeight = hdb.latencyWeight
+	data.ScoringRules = hdb.scoringRules
 	return data
 }
 
@@ -70,6 +76,24 @@ func (hdb *HostDB) load() error {
 	hdb.knownContracts = data.KnownContracts
 	hdb.filteredHosts = data.FilteredHosts
 	hdb.filterMode = data.FilterMode
+	hdb.selectionPolicy = data.SelectionPolicy
+	hdb.latencyWeight = data.LatencyWeight
+
+	// Compile the scoring rules. Invalid rules are dropped rather than
+	// preventing the hostdb from starting.
+	compiled, err := compileScoringRules(data.ScoringRules)
+	if err != nil {
+		hdb.staticLog.Println("WARN: dropping invalid host scoring rules:", err)
+	} else {
+		hdb.scoringRules = data.ScoringRules
+		hdb.compiledScoringRules = compiled
+	}
+
+	// Load the GeoIP database used by the selection policy. If that fails,
+	// the hostdb doesn't select hosts until the database is loaded.
+	if err := hdb.loadGeoIPDatabase(); err != nil {
+		hdb.staticLog.Println("WARN: failed to load GeoIP database:", err)
+	}
 
 	// Overwrite the initialized filteredDomains with the data loaded
 	// from disk
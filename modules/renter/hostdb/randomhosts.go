@@ -11,20 +11,26 @@ import (
 // RandomHosts implements the HostDB interface's RandomHosts() method. It takes
 // a number of hosts to return, and a slice of netaddresses to ignore, and
 // returns a slice of entries. If the IP violation check was disabled, the
-// addressBlacklist is ignored.
+// addressBlacklist is ignored by the subnet check but still counts towards the
+// limits of the host selection policy.
 func (hdb *HostDB) RandomHosts(n int, blacklist, addressBlacklist []types.SiaPublicKey) ([]modules.HostDBEntry, error) {
 	hdb.mu.RLock()
 	initialScanComplete := hdb.initialScanComplete
 	ipCheckDisabled := hdb.disableIPViolationCheck
 	filteredTree := hdb.filteredTree
+	pf := hdb.newPolicyFilter(addressBlacklist)
+	geoIPMissing := hdb.selectionPolicy.Active() && hdb.geoIPDB == nil
 	hdb.mu.RUnlock()
 	if !initialScanComplete {
 		return []modules.HostDBEntry{}, ErrInitialScanIncomplete
 	}
+	if geoIPMissing {
+		return []modules.HostDBEntry{}, errGeoIPDatabaseUnavailable
+	}
 	if ipCheckDisabled {
-		return filteredTree.SelectRandom(n, blacklist, nil), nil
+		return filteredTree.SelectRandomWithPolicy(n, blacklist, nil, pf), nil
 	}
-	return filteredTree.SelectRandom(n, blacklist, addressBlacklist), nil
+	return filteredTree.SelectRandomWithPolicy(n, blacklist, addressBlacklist, pf), nil
 }
 
 // RandomHostsWithAllowance works as RandomHosts but uses a temporary hosttree
@@ -35,10 +41,14 @@ func (hdb *HostDB) RandomHostsWithAllowance(n int, blacklist, addressBlacklist [
 	initialScanComplete := hdb.initialScanComplete
 	filteredHosts := hdb.filteredHosts
 	filterType := hdb.filterMode
+	geoIPMissing := hdb.selectionPolicy.Active() && hdb.geoIPDB == nil
 	hdb.mu.RUnlock()
 	if !initialScanComplete && !hdb.staticDeps.Disrupt("InitialScanComplete") {
 		return []modules.HostDBEntry{}, ErrInitialScanIncomplete
 	}
+	if geoIPMissing {
+		return []modules.HostDBEntry{}, errGeoIPDatabaseUnavailable
+	}
 	// Create a temporary hosttree from the given allowance.
 	ht := hosttree.New(hdb.managedCalculateHostWeightFn(allowance), hdb.staticDeps.Resolver())
 
@@ -60,5 +70,24 @@ func (hdb *HostDB) RandomHostsWithAllowance(n int, blacklist, addressBlacklist [
 	}
 
 	// Select hosts from the temporary hosttree.
-	return ht.SelectRandom(n, blacklist, addressBlacklist), insertErrs
+	pf := hdb.newPolicyFilter(addressBlacklist)
+	return ht.SelectRandomWithPolicy(n, blacklist, addressBlacklist, pf), insertErrs
+}
+
+// newPolicyFilter creates a PolicyFilter for the hostdb's selection policy.
+// The hosts the renter is already using are added to the filter to count
+// towards the policy's limits. If no policy is set, nil is returned.
+func (hdb *HostDB) newPolicyFilter(usedHosts []types.SiaPublicKey) *hosttree.PolicyFilter {
+	if !hdb.selectionPolicy.Active() {
+		return nil
+	}
+	pf := hosttree.NewPolicyFilter(hdb.selectionPolicy, hdb.geoIPDB)
+	for _, pk := range usedHosts {
+		entry, exists := hdb.staticHostTree.Select(pk)
+		if !exists {
+			continue
+		}
+		pf.Add(entry)
+	}
+	return pf
 }
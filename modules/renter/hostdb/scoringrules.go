@@ -0,0 +1,278 @@
+package hostdb
+
+// scoringrules.go contains the logic for applying the user defined scoring
+// rules to the hosts. The rules are compiled once when they are set, so that
+// evaluating them in the weight function doesn't require parsing any values.
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// The kinds of values a scoring rule can compare.
+const (
+	ruleKindBool ruleKind = iota
+	ruleKindCurrency
+	ruleKindDuration
+	ruleKindFloat
+	ruleKindString
+	ruleKindUint
+	ruleKindVersion
+)
+
+type (
+	// ruleKind describes how the value of a field is parsed and compared.
+	ruleKind int
+
+	// scoringField is a field of a host that can be used in a scoring rule.
+	scoringField struct {
+		kind  ruleKind
+		value func(hdb *HostDB, entry modules.HostDBEntry) interface{}
+	}
+
+	// scoringRule is a compiled modules.HostScoringRule.
+	scoringRule struct {
+		field      scoringField
+		comparator string
+		value      interface{}
+		multiplier float64
+		reject     bool
+	}
+)
+
+var (
+	// errUnknownScoringField is returned if a scoring rule refers to a field
+	// that doesn't exist.
+	errUnknownScoringField = errors.New("unknown scoring rule field")
+
+	// scoringFields contains all the fields that can be used in scoring rules
+	// keyed by their name.
+	scoringFields = buildScoringFields()
+)
+
+// buildScoringFields creates the scoringFields map from the fields of the
+// HostExternalSettings and the measured fields of the host.
+func buildScoringFields() map[string]scoringField {
+	fields := map[string]scoringField{
+		"age": {
+			kind: ruleKindUint,
+			value: func(hdb *HostDB, entry modules.HostDBEntry) interface{} {
+				if hdb.blockHeight < entry.FirstSeen {
+					return uint64(0)
+				}
+				return uint64(hdb.blockHeight - entry.FirstSeen)
+			},
+		},
+		"latency": {
+			kind: ruleKindDuration,
+			value: func(_ *HostDB, entry modules.HostDBEntry) interface{} {
+				return entry.Latency
+			},
+		},
+		"throughput": {
+			kind: ruleKindFloat,
+			value: func(_ *HostDB, entry modules.HostDBEntry) interface{} {
+				return entry.Throughput
+			},
+		},
+		"uptime": {
+			kind: ruleKindFloat,
+			value: func(hdb *HostDB, entry modules.HostDBEntry) interface{} {
+				uptime, downtime := hdb.measuredUptime(entry)
+				if uptime+downtime == 0 {
+					return float64(0)
+				}
+				return float64(uptime) / float64(uptime+downtime)
+			},
+		},
+	}
+
+	currencyType := reflect.TypeOf(types.Currency{})
+	durationType := reflect.TypeOf(time.Duration(0))
+	settingsType := reflect.TypeOf(modules.HostExternalSettings{})
+	for i := 0; i < settingsType.NumField(); i++ {
+		f := settingsType.Field(i)
+		name := strings.Split(f.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		var kind ruleKind
+		switch {
+		case f.Type == currencyType:
+			kind = ruleKindCurrency
+		case f.Type == durationType:
+			kind = ruleKindDuration
+		case f.Type.Kind() == reflect.Bool:
+			kind = ruleKindBool
+		case f.Type.Kind() == reflect.Uint64:
+			kind = ruleKindUint
+		case f.Type.Kind() == reflect.String && name == "version":
+			kind = ruleKindVersion
+		case f.Type.Kind() == reflect.String:
+			kind = ruleKindString
+		default:
+			continue
+		}
+		index := i
+		fields[name] = scoringField{
+			kind: kind,
+			value: func(_ *HostDB, entry modules.HostDBEntry) interface{} {
+				v := reflect.ValueOf(entry.HostExternalSettings).Field(index)
+				switch kind {
+				case ruleKindUint:
+					return v.Uint()
+				case ruleKindString, ruleKindVersion:
+					return v.String()
+				default:
+					return v.Interface()
+				}
+			},
+		}
+	}
+	return fields
+}
+
+// parseRuleValue parses the value of a scoring rule according to the kind of
+// the field it is compared to.
+func parseRuleValue(kind ruleKind, value string) (interface{}, error) {
+	switch kind {
+	case ruleKindBool:
+		return strconv.ParseBool(value)
+	case ruleKindCurrency:
+		hastings, err := types.ParseCurrency(value)
+		if err != nil {
+			return nil, err
+		}
+		var c types.Currency
+		_, err = fmt.Sscan(hastings, &c)
+		return c, err
+	case ruleKindDuration:
+		return time.ParseDuration(value)
+	case ruleKindFloat:
+		return strconv.ParseFloat(value, 64)
+	case ruleKindUint:
+		return strconv.ParseUint(value, 10, 64)
+	case ruleKindVersion:
+		if !build.IsVersion(value) {
+			return nil, fmt.Errorf("'%v' is not a valid version", value)
+		}
+		return value, nil
+	default:
+		return value, nil
+	}
+}
+
+// compareRuleValues compares a and b which are both of the given kind. It
+// returns -1, 0 or 1 if a is smaller, equal or larger than b.
+func compareRuleValues(kind ruleKind, a, b interface{}) int {
+	switch kind {
+	case ruleKindBool:
+		if a.(bool) == b.(bool) {
+			return 0
+		}
+		return 1
+	case ruleKindCurrency:
+		return a.(types.Currency).Cmp(b.(types.Currency))
+	case ruleKindDuration:
+		return compareOrdered(float64(a.(time.Duration)), float64(b.(time.Duration)))
+	case ruleKindFloat:
+		return compareOrdered(a.(float64), b.(float64))
+	case ruleKindUint:
+		x, y := a.(uint64), b.(uint64)
+		if x < y {
+			return -1
+		} else if x > y {
+			return 1
+		}
+		return 0
+	case ruleKindVersion:
+		return build.VersionCmp(a.(string), b.(string))
+	default:
+		return strings.Compare(a.(string), b.(string))
+	}
+}
+
+// compareOrdered compares two floats.
+func compareOrdered(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// compileScoringRules validates the given rules and compiles them.
+func compileScoringRules(rules modules.HostScoringRules) ([]scoringRule, error) {
+	compiled := make([]scoringRule, 0, len(rules.Rules))
+	for i, rule := range rules.Rules {
+		if err := rule.Validate(); err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("invalid scoring rule %v", i))
+		}
+		field, exists := scoringFields[strings.ToLower(rule.Field)]
+		if !exists {
+			return nil, errors.AddContext(errUnknownScoringField, fmt.Sprintf("invalid scoring rule %v: '%v'", i, rule.Field))
+		}
+		if (field.kind == ruleKindBool || field.kind == ruleKindString) && rule.Comparator != "==" && rule.Comparator != "!=" {
+			return nil, fmt.Errorf("invalid scoring rule %v: field '%v' only supports == and !=", i, rule.Field)
+		}
+		value, err := parseRuleValue(field.kind, rule.Value)
+		if err != nil {
+			return nil, errors.AddContext(err, fmt.Sprintf("invalid scoring rule %v: failed to parse value '%v'", i, rule.Value))
+		}
+		compiled = append(compiled, scoringRule{
+			field:      field,
+			comparator: rule.Comparator,
+			value:      value,
+			multiplier: rule.Multiplier,
+			reject:     rule.Reject,
+		})
+	}
+	return compiled, nil
+}
+
+// matches returns true if the host matches the rule.
+func (rule scoringRule) matches(hdb *HostDB, entry modules.HostDBEntry) bool {
+	c := compareRuleValues(rule.field.kind, rule.field.value(hdb, entry), rule.value)
+	switch rule.comparator {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case "==":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">=":
+		return c >= 0
+	case ">":
+		return c > 0
+	}
+	return false
+}
+
+// customAdjustments applies the user defined scoring rules to the host. Hosts
+// matching a rejecting rule receive the lowest possible adjustment.
+func (hdb *HostDB) customAdjustments(entry modules.HostDBEntry, rules []scoringRule) float64 {
+	adjustment := 1.0
+	for _, rule := range rules {
+		if !rule.matches(hdb, entry) {
+			continue
+		}
+		if rule.reject {
+			return math.SmallestNonzeroFloat64
+		}
+		adjustment *= rule.multiplier
+	}
+	return adjustment
+}
@@ -0,0 +1,206 @@
+package hostdb
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestCompileScoringRules tests that invalid scoring rules are rejected.
+func TestCompileScoringRules(t *testing.T) {
+	t.Parallel()
+
+	valid := []modules.HostScoringRule{
+		{Field: "storageprice", Comparator: ">", Value: "1KS", Reject: true},
+		{Field: "maxduration", Comparator: ">=", Value: "4320", Multiplier: 2},
+		{Field: "acceptingcontracts", Comparator: "==", Value: "true", Multiplier: 0.5},
+		{Field: "ephemeralaccountexpiry", Comparator: "<", Value: "1h", Multiplier: 0.5},
+		{Field: "version", Comparator: "<", Value: "1.5.4", Reject: true},
+		{Field: "netaddress", Comparator: "!=", Value: "host.sia:9982", Multiplier: 3},
+		{Field: "uptime", Comparator: "<", Value: "0.95", Multiplier: 0.1},
+		{Field: "latency", Comparator: "<", Value: "100ms", Multiplier: 2},
+		{Field: "throughput", Comparator: ">", Value: "1e6", Multiplier: 2},
+		{Field: "age", Comparator: "<", Value: "1000", Multiplier: 0.5},
+	}
+	for _, rule := range valid {
+		if _, err := compileScoringRules(modules.HostScoringRules{Rules: []modules.HostScoringRule{rule}}); err != nil {
+			t.Error("valid rule was rejected", rule, err)
+		}
+	}
+
+	invalid := []modules.HostScoringRule{
+		{Field: "storageprice", Comparator: "~", Value: "1KS", Reject: true},
+		{Field: "unknown", Comparator: ">", Value: "1", Multiplier: 2},
+		{Field: "storageprice", Comparator: ">", Value: "lots", Multiplier: 2},
+		{Field: "storageprice", Comparator: ">", Value: "1KS", Multiplier: 0},
+		{Field: "storageprice", Comparator: ">", Value: "1KS", Multiplier: -1},
+		{Field: "acceptingcontracts", Comparator: ">", Value: "true", Multiplier: 2},
+		{Field: "netaddress", Comparator: "<", Value: "host.sia:9982", Multiplier: 2},
+		{Field: "version", Comparator: "<", Value: "v1", Multiplier: 2},
+		{Field: "latency", Comparator: "<", Value: "100", Multiplier: 2},
+	}
+	for _, rule := range invalid {
+		if _, err := compileScoringRules(modules.HostScoringRules{Rules: []modules.HostScoringRule{rule}}); err == nil {
+			t.Error("invalid rule was accepted", rule)
+		}
+	}
+}
+
+// TestCustomAdjustments tests that the scoring rules are applied correctly.
+func TestCustomAdjustments(t *testing.T) {
+	t.Parallel()
+	hdb := bareHostDB()
+
+	cheap := DefaultHostDBEntry
+	cheap.Latency = 50 * time.Millisecond
+	expensive := DefaultHostDBEntry
+	expensive.StoragePrice = types.SiacoinPrecision
+	expensive.Latency = time.Second
+
+	rules, err := compileScoringRules(modules.HostScoringRules{Rules: []modules.HostScoringRule{
+		{Field: "latency", Comparator: "<", Value: "100ms", Multiplier: 2},
+		{Field: "acceptingcontracts", Comparator: "==", Value: "true", Multiplier: 1.5},
+		{Field: "storageprice", Comparator: ">", Value: "1mS", Reject: true},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without rules the hosts aren't adjusted.
+	if adj := hdb.customAdjustments(cheap, nil); adj != 1 {
+		t.Fatal("unexpected adjustment", adj)
+	}
+	// The multipliers of all matching rules are combined.
+	if adj := hdb.customAdjustments(cheap, rules); adj != 3 {
+		t.Fatal("unexpected adjustment", adj)
+	}
+	// Rejected hosts receive the lowest possible adjustment.
+	if adj := hdb.customAdjustments(expensive, rules); adj != math.SmallestNonzeroFloat64 {
+		t.Fatal("host should be rejected", adj)
+	}
+
+	// The adjustment is part of the host's score.
+	wf := hdb.managedCalculateHostWeightFnWithRules(DefaultTestAllowance, rules)
+	baseWF := hdb.managedCalculateHostWeightFnWithRules(DefaultTestAllowance, nil)
+	if wf(cheap).Score().Cmp(baseWF(cheap).Score()) <= 0 {
+		t.Fatal("rules should increase the score")
+	}
+	if wf(expensive).Score().Cmp(baseWF(expensive).Score()) >= 0 {
+		t.Fatal("rules should decrease the score")
+	}
+}
+
+// TestScoringRules tests setting, persisting and previewing the scoring rules.
+func TestScoringRules(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdbt, err := newHDBTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid rules are rejected.
+	err = hdbt.hdb.SetScoringRules(modules.HostScoringRules{Rules: []modules.HostScoringRule{
+		{Field: "unknown", Comparator: "==", Value: "1", Multiplier: 2},
+	}})
+	if err == nil {
+		t.Fatal("invalid rules should be rejected")
+	}
+
+	rules := modules.HostScoringRules{Rules: []modules.HostScoringRule{
+		{Field: "acceptingcontracts", Comparator: "==", Value: "true", Multiplier: 4},
+	}}
+
+	// Previewing the rules doesn't apply them.
+	if _, err := hdbt.hdb.PreviewScoringRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	entry := makeHostDBEntry()
+	entry.AcceptingContracts = true
+	sb, err := hdbt.hdb.ScoreBreakdown(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.CustomAdjustment != 1 {
+		t.Fatal("preview shouldn't apply the rules", sb.CustomAdjustment)
+	}
+
+	// Set the rules.
+	if err := hdbt.hdb.SetScoringRules(rules); err != nil {
+		t.Fatal(err)
+	}
+	sb, err = hdbt.hdb.ScoreBreakdown(entry)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sb.CustomAdjustment != 4 {
+		t.Fatal("rules weren't applied", sb.CustomAdjustment)
+	}
+
+	// Restart the hostdb and check that the rules were persisted.
+	if err := hdbt.hdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var errChan <-chan error
+	hdbt.hdb, errChan = NewCustomHostDB(hdbt.gateway, hdbt.cs, hdbt.tpool, hdbt.mux, filepath.Join(hdbt.persistDir, modules.RenterDir), &quitAfterLoadDeps{})
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	hdbt.hdb.mu.RLock()
+	loaded := hdbt.hdb.scoringRules
+	compiled := hdbt.hdb.compiledScoringRules
+	hdbt.hdb.mu.RUnlock()
+	if len(loaded.Rules) != 1 || loaded.Rules[0] != rules.Rules[0] || len(compiled) != 1 {
+		t.Fatal("rules weren't loaded", loaded)
+	}
+}
+
+// TestSetLatencyWeight tests that invalid latency weights are rejected and that
+// valid ones are persisted.
+func TestSetLatencyWeight(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdbt, err := newHDBTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Invalid weights are rejected and don't change the current weight.
+	for _, weight := range []float64{-1, maxLatencyWeight + 1, math.NaN(), math.Inf(1)} {
+		if err := hdbt.hdb.SetLatencyWeight(weight); err == nil {
+			t.Fatal("invalid weight was accepted", weight)
+		}
+	}
+	if weight, err := hdbt.hdb.LatencyWeight(); err != nil || weight != 0 {
+		t.Fatal("invalid weight was applied", weight, err)
+	}
+
+	// Set a valid weight.
+	if err := hdbt.hdb.SetLatencyWeight(2); err != nil {
+		t.Fatal(err)
+	}
+	if weight, err := hdbt.hdb.LatencyWeight(); err != nil || weight != 2 {
+		t.Fatal("weight wasn't applied", weight, err)
+	}
+
+	// Restart the hostdb and check that the weight was persisted.
+	if err := hdbt.hdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	var errChan <-chan error
+	hdbt.hdb, errChan = NewCustomHostDB(hdbt.gateway, hdbt.cs, hdbt.tpool, hdbt.mux, filepath.Join(hdbt.persistDir, modules.RenterDir), &quitAfterLoadDeps{})
+	if err := <-errChan; err != nil {
+		t.Fatal(err)
+	}
+	if weight, err := hdbt.hdb.LatencyWeight(); err != nil || weight != 2 {
+		t.Fatal("weight wasn't persisted", weight, err)
+	}
+}
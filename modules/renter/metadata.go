@@ -45,6 +45,7 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 		AggregateNumStuckChunks:      uint64(0),
 		AggregateNumSubDirs:          uint64(0),
 		AggregateRemoteHealth:        siadir.DefaultDirHealth,
+		AggregateRepairHealth:        siadir.DefaultDirHealth,
 		AggregateRepairSize:          uint64(0),
 		AggregateSize:                uint64(0),
 		AggregateStuckHealth:         siadir.DefaultDirHealth,
@@ -70,6 +71,14 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 		return siadir.Metadata{}, err
 	}
 
+	// Get the storage policy that applies to the files to calculate their
+	// repair health.
+	policy, err := r.staticFileSystem.StoragePolicy(siaPath)
+	if err != nil {
+		r.log.Printf("WARN: Error in getting the storage policy of directory %v : %v\n", siaPath.String(), err)
+		return siadir.Metadata{}, err
+	}
+
 	// Iterate over directory and collect the file and dir siapaths.
 	var fileSiaPaths, dirSiaPaths []modules.SiaPath
 	for _, fi := range fileinfos {
@@ -97,6 +106,11 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 				r.log.Println("unable to join siapath with dirpath while calculating directory metadata:", err)
 				continue
 			}
+			// The siafiles of pending re-encodes are only temporary and
+			// don't affect the health of the renter's files.
+			if isReencodePath(dirSiaPath) {
+				continue
+			}
 			dirSiaPaths = append(dirSiaPaths, dirSiaPath)
 		}
 	}
@@ -121,7 +135,7 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 
 	for len(bubbledMetadatas)+len(dirMetadatas) > 0 {
 		// Aggregate Fields
-		var aggregateHealth, aggregateRemoteHealth, aggregateStuckHealth, aggregateMinRedundancy float64
+		var aggregateHealth, aggregateRemoteHealth, aggregateRepairHealth, aggregateStuckHealth, aggregateMinRedundancy float64
 		var aggregateLastHealthCheckTime, aggregateModTime time.Time
 		if len(bubbledMetadatas) > 0 {
 			// Get next file's metadata.
@@ -161,6 +175,7 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 
 			// Record Values that compare against sub directories
 			aggregateHealth = fileMetadata.Health
+			aggregateRepairHealth = repairHealth(fileMetadata.Health, policy)
 			aggregateStuckHealth = fileMetadata.StuckHealth
 			aggregateMinRedundancy = fileMetadata.Redundancy
 			aggregateLastHealthCheckTime = fileMetadata.LastHealthCheckTime
@@ -219,6 +234,7 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 
 			// Record Values that compare against files
 			aggregateHealth = dirMetadata.AggregateHealth
+			aggregateRepairHealth = dirMetadata.AggregateRepairHealth
 			aggregateStuckHealth = dirMetadata.AggregateStuckHealth
 			aggregateMinRedundancy = dirMetadata.AggregateMinRedundancy
 			aggregateLastHealthCheckTime = dirMetadata.AggregateLastHealthCheckTime
@@ -242,6 +258,7 @@ func (r *Renter) callCalculateDirectoryMetadata(siaPath modules.SiaPath) (siadir
 		// Track the max value of aggregate health values
 		metadata.AggregateHealth = math.Max(metadata.AggregateHealth, aggregateHealth)
 		metadata.AggregateRemoteHealth = math.Max(metadata.AggregateRemoteHealth, aggregateRemoteHealth)
+		metadata.AggregateRepairHealth = math.Max(metadata.AggregateRepairHealth, aggregateRepairHealth)
 		metadata.AggregateStuckHealth = math.Max(metadata.AggregateStuckHealth, aggregateStuckHealth)
 		// Track the min value for AggregateMinRedundancy
 		if aggregateMinRedundancy != -1 {
@@ -60,6 +60,10 @@ type (
 		MaxUploadSpeed   int64
 		UploadedBackups  []modules.UploadedBackup
 		SyncedContracts  []types.FileContractID
+
+		// TraceExportDestination is the file or OTLP collector URL the
+		// finished spans are exported to.
+		TraceExportDestination string
 	}
 )
 
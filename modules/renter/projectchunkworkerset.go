@@ -583,6 +583,10 @@ func (pcws *projectChunkWorkerSet) managedDownload(ctx context.Context, pricePer
 	// extra goroutines to be spawned.
 	workerResponseChan := make(chan *jobReadResponse, ec.NumPieces()*5)
 
+	// Trace the download. The read jobs of the workers are traced as children
+	// of the pdc's span.
+	span, ctx := pcws.staticRenter.staticTracer.startSpan(ctx, "projectDownloadChunk", "chunkindex", pcws.staticChunkIndex, "offset", offset, "length", length)
+
 	// Build the full pdc.
 	pdc := &projectDownloadChunk{
 		offsetInChunk: offset,
@@ -597,6 +601,7 @@ func (pcws *projectChunkWorkerSet) managedDownload(ctx context.Context, pricePer
 		dataPieces:      make([][]byte, ec.NumPieces()),
 
 		ctx:                  ctx,
+		span:                 span,
 		workerResponseChan:   workerResponseChan,
 		downloadResponseChan: make(chan *downloadResponse, 1),
 		workerSet:            pcws,
@@ -610,6 +615,7 @@ func (pcws *projectChunkWorkerSet) managedDownload(ctx context.Context, pricePer
 	// Launch the initial set of workers for the pdc.
 	err = pdc.launchInitialWorkers()
 	if err != nil {
+		span.finish(err)
 		return nil, errors.Compose(err, ErrRootNotFound)
 	}
 
@@ -98,6 +98,7 @@ type (
 		// The completed data gets sent down the response chan once the full
 		// download is done.
 		ctx                  context.Context
+		span                 *traceSpan
 		downloadResponseChan chan *downloadResponse
 		workerResponseChan   chan *jobReadResponse
 		workerSet            *projectChunkWorkerSet
@@ -300,6 +301,7 @@ func (pdc *projectDownloadChunk) handleJobReadResponse(jrr *jobReadResponse) {
 
 // fail will send an error down the download response channel.
 func (pdc *projectDownloadChunk) fail(err error) {
+	pdc.span.finish(err)
 	dr := &downloadResponse{
 		data: nil,
 		err:  err,
@@ -339,6 +341,7 @@ func (pdc *projectDownloadChunk) finalize() {
 
 		launchedWorkers: pdc.launchedWorkers,
 	}
+	pdc.span.finish(nil)
 	pdc.downloadResponseChan <- dr
 }
 
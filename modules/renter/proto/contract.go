@@ -10,6 +10,7 @@ import (
 	"sync"
 
 	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
 	"gitlab.com/NebulousLabs/writeaheadlog"
 
 	"gitlab.com/NebulousLabs/encoding"
@@ -269,6 +270,33 @@ func (c *SafeContract) PublicKey() crypto.PublicKey {
 	return c.header.SecretKey.PublicKey()
 }
 
+// RandomMerkleRoots returns up to n distinct sector roots of the contract which
+// are chosen at random. If the contract contains fewer than n roots, all of
+// them are returned in random order.
+func (c *SafeContract) RandomMerkleRoots(n int) ([]crypto.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	numRoots := c.merkleRoots.len()
+	if n > numRoots {
+		n = numRoots
+	}
+	// Pick the indices without creating a permutation of all the roots since
+	// contracts might contain millions of them.
+	indices := make(map[int]struct{}, n)
+	for len(indices) < n {
+		indices[fastrand.Intn(numRoots)] = struct{}{}
+	}
+	roots := make([]crypto.Hash, 0, n)
+	for i := range indices {
+		root, err := c.merkleRoots.merkleRootsFromIndexFromDisk(i, i+1)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to read root from disk")
+		}
+		roots = append(roots, root...)
+	}
+	return roots, nil
+}
+
 // RecordPaymentIntent will records the changes we are about to make to the
 // revision in order to pay a host for an RPC.
 func (c *SafeContract) RecordPaymentIntent(rev types.FileContractRevision, amount types.Currency, details modules.SpendingDetails) (*unappliedWalTxn, error) {
@@ -159,6 +159,18 @@ func (cs *ContractSet) PublicKey(id types.FileContractID) (crypto.PublicKey, boo
 	return safeContract.PublicKey(), true
 }
 
+// RandomMerkleRoots returns up to n randomly chosen sector roots of the
+// contract with the specified id. The contract is not locked.
+func (cs *ContractSet) RandomMerkleRoots(id types.FileContractID, n int) ([]crypto.Hash, error) {
+	cs.mu.Lock()
+	safeContract, ok := cs.contracts[id]
+	cs.mu.Unlock()
+	if !ok {
+		return nil, errors.New("no contract with that id")
+	}
+	return safeContract.RandomMerkleRoots(n)
+}
+
 // ViewAll returns the metadata of each contract in the set. The contracts are
 // not locked.
 func (cs *ContractSet) ViewAll() []modules.RenterContract {
@@ -413,3 +413,73 @@ func TestInsertContractTotalCost(t *testing.T) {
 		t.Fatal("wrong TotalCost", contract.TotalCost, expectedTotalCost)
 	}
 }
+
+// TestContractSetRandomMerkleRoots tests fetching random sector roots from a
+// contract in the set.
+func TestContractSetRandomMerkleRoots(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// create contract set
+	testDir := build.TempDir(t.Name())
+	rl := ratelimit.NewRateLimit(0, 0, 0)
+	cs, err := NewContractSet(testDir, rl, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// insert a contract with a few roots
+	header := contractHeader{Transaction: types.Transaction{
+		FileContractRevisions: []types.FileContractRevision{{
+			ParentID:             types.FileContractID{1},
+			NewValidProofOutputs: []types.SiacoinOutput{{}, {}},
+			UnlockConditions: types.UnlockConditions{
+				PublicKeys: []types.SiaPublicKey{{}, {}},
+			},
+		}},
+	}}
+	roots := make([]crypto.Hash, 10)
+	rootsMap := make(map[crypto.Hash]struct{})
+	for i := range roots {
+		fastrand.Read(roots[i][:])
+		rootsMap[roots[i]] = struct{}{}
+	}
+	_, err = cs.managedInsertContract(header, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// fetching roots from an unknown contract should fail
+	_, err = cs.RandomMerkleRoots(types.FileContractID{2}, 1)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+
+	// fetch a few roots and make sure they are distinct and belong to the
+	// contract
+	for _, n := range []int{0, 1, 5, 10, 20} {
+		random, err := cs.RandomMerkleRoots(header.ID(), n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := n
+		if expected > len(roots) {
+			expected = len(roots)
+		}
+		if len(random) != expected {
+			t.Fatalf("expected %v roots but got %v", expected, len(random))
+		}
+		seen := make(map[crypto.Hash]struct{})
+		for _, root := range random {
+			if _, exists := rootsMap[root]; !exists {
+				t.Fatal("unknown root returned")
+			}
+			if _, exists := seen[root]; exists {
+				t.Fatal("root returned twice")
+			}
+			seen[root] = struct{}{}
+		}
+	}
+}
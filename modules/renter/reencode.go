@@ -0,0 +1,604 @@
+package renter
+
+// reencode.go contains the logic for re-encoding files with new erasure coding
+// and cipher parameters. The file is streamed through the download path and
+// uploaded into a new siafile within the ReencodeFolder. Once all pieces of its
+// chunks are uploaded, the new siafile replaces the original one. The siafiles
+// within the ReencodeFolder are neither repaired by the repair loops nor count
+// towards the health of the root directory.
+//
+// The new siafile of a file with the UID 'uid' at siapath 'path' is stored at
+// '/reencode/new/uid/path'. Since the new siafile keeps track of the pieces
+// that were already uploaded, re-encodes survive restarts. Pending re-encodes
+// are resumed on startup and retried periodically, skipping the chunks that
+// were uploaded before. Re-encodes queued for the files of a storage policy
+// are run by the same loop, one file at a time.
+//
+// To replace the original siafile, it is first moved to '/reencode/old/uid/path'
+// before the new siafile is moved to 'path'. Only then is the original siafile
+// deleted. That way an interrupted swap can always be finished on startup.
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/modules/renter/filesystem/siafile"
+	"go.sia.tech/siad/types"
+)
+
+var (
+	// reencodeNewFolder is the folder containing the new siafiles of pending
+	// re-encodes.
+	reencodeNewFolder = modules.NewGlobalSiaPath(modules.ReencodeFolder.String() + "/new")
+
+	// reencodeOldFolder is the folder containing the original siafiles while
+	// they are being replaced.
+	reencodeOldFolder = modules.NewGlobalSiaPath(modules.ReencodeFolder.String() + "/old")
+
+	// errReencodeIncomplete is returned if not all chunks of a file could be
+	// uploaded with the new parameters. The re-encode is retried later.
+	errReencodeIncomplete = errors.New("not all chunks could be re-encoded yet")
+
+	// errReencodeInProgress is returned if a file is already being
+	// re-encoded.
+	errReencodeInProgress = errors.New("file is already being re-encoded")
+
+	// errReencodeUnchanged is returned if a file already uses the parameters
+	// it should be re-encoded with.
+	errReencodeUnchanged = errors.New("file already uses the requested parameters")
+)
+
+// reencodeSet keeps track of the files that are currently being re-encoded.
+type reencodeSet struct {
+	active map[modules.SiaPath]struct{}
+	mu     sync.Mutex
+
+	// wakeChan wakes up the loop that runs the pending re-encodes.
+	wakeChan chan struct{}
+
+	// swapMu is held while an original siafile is replaced by its re-encoded
+	// siafile. The renter's operations that create, delete or rename
+	// siafiles hold it for reading to not interleave with a swap.
+	swapMu sync.RWMutex
+}
+
+// newReencodeSet creates a new, empty reencodeSet.
+func newReencodeSet() *reencodeSet {
+	return &reencodeSet{
+		active:   make(map[modules.SiaPath]struct{}),
+		wakeChan: make(chan struct{}, 1),
+	}
+}
+
+// managedStart marks the file at the given siapath as being re-encoded. It
+// returns false if the file is already being re-encoded.
+func (rs *reencodeSet) managedStart(siaPath modules.SiaPath) bool {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	if _, exists := rs.active[siaPath]; exists {
+		return false
+	}
+	rs.active[siaPath] = struct{}{}
+	return true
+}
+
+// managedFinish marks the re-encode of the file at the given siapath as done.
+func (rs *reencodeSet) managedFinish(siaPath modules.SiaPath) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+	delete(rs.active, siaPath)
+}
+
+// callWake wakes up the loop that runs the pending re-encodes without waiting
+// for the next retry.
+func (rs *reencodeSet) callWake() {
+	select {
+	case rs.wakeChan <- struct{}{}:
+	default:
+	}
+}
+
+// isReencodePath returns true if the siapath points to a file or dir within
+// the ReencodeFolder.
+func isReencodePath(siaPath modules.SiaPath) bool {
+	return siaPath.Equals(modules.ReencodeFolder) || strings.HasPrefix(siaPath.Path, modules.ReencodeFolder.Path+"/")
+}
+
+// reencodePaths returns the siapaths of the new and the original siafile of a
+// re-encode while the original file is being replaced.
+func reencodePaths(siaPath modules.SiaPath, uid string) (newPath, oldPath modules.SiaPath, err error) {
+	newPath, err = reencodeNewFolder.Join(uid + "/" + siaPath.String())
+	if err != nil {
+		return modules.SiaPath{}, modules.SiaPath{}, err
+	}
+	oldPath, err = reencodeOldFolder.Join(uid + "/" + siaPath.String())
+	if err != nil {
+		return modules.SiaPath{}, modules.SiaPath{}, err
+	}
+	return newPath, oldPath, nil
+}
+
+// parseReencodePath returns the siapath and UID of the file that is re-encoded
+// into the siafile at the given siapath within the given folder.
+func parseReencodePath(folder, path modules.SiaPath) (siaPath modules.SiaPath, uid string, err error) {
+	rel, err := path.Rebase(folder, modules.RootSiaPath())
+	if err != nil {
+		return modules.SiaPath{}, "", err
+	}
+	parts := strings.SplitN(rel.String(), "/", 2)
+	if len(parts) != 2 {
+		return modules.SiaPath{}, "", errors.New("invalid re-encode path " + path.String())
+	}
+	siaPath, err = modules.NewSiaPath(parts[1])
+	return siaPath, parts[0], err
+}
+
+// ReencodeFile re-uploads the file at the given siapath with new erasure
+// coding and cipher parameters in the background. Parameters that aren't set
+// keep the value of the file. Once all chunks are uploaded, the original
+// siafile is replaced.
+func (r *Renter) ReencodeFile(siaPath modules.SiaPath, ec modules.ErasureCoder, cipherType crypto.CipherType) (err error) {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+
+	if isReencodePath(siaPath) {
+		return errors.New("can't re-encode a file within the re-encode folder")
+	}
+	if !r.staticReencodes.managedStart(siaPath) {
+		return errReencodeInProgress
+	}
+	uid, err := r.managedQueueReencode(siaPath, ec, cipherType)
+	if err != nil {
+		r.staticReencodes.managedFinish(siaPath)
+		return err
+	}
+	go func() {
+		defer r.staticReencodes.managedFinish(siaPath)
+		r.threadedReencodeFile(siaPath, uid)
+	}()
+	return nil
+}
+
+// managedQueueReencode creates the new siafile of a re-encode of the file at
+// the given siapath and returns the UID of the file. The re-encode is run by
+// the caller or by the loop that resumes pending re-encodes. The caller needs
+// to mark the file as being re-encoded.
+func (r *Renter) managedQueueReencode(siaPath modules.SiaPath, ec modules.ErasureCoder, cipherType crypto.CipherType) (_ string, err error) {
+	// Fill in the parameters that weren't set from the original file.
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return "", errors.AddContext(err, "unable to open file")
+	}
+	defer func() {
+		err = errors.Compose(err, entry.Close())
+	}()
+	if ec == nil {
+		ec = entry.ErasureCode()
+	}
+	if cipherType == (crypto.CipherType{}) {
+		cipherType = entry.MasterKey().Type()
+	}
+	if ec.Identifier() == entry.ErasureCode().Identifier() && cipherType == entry.MasterKey().Type() {
+		return "", errReencodeUnchanged
+	}
+
+	// Create the new siafile. If it already exists, the file is already
+	// being re-encoded and the re-encode will be resumed.
+	uid := string(entry.UID())
+	newPath, _, err := reencodePaths(siaPath, uid)
+	if err != nil {
+		return "", err
+	}
+	key := crypto.GenerateSiaKey(cipherType)
+	err = r.staticFileSystem.NewSiaFile(newPath, "", ec, key, entry.Size(), entry.Mode(), true)
+	if errors.Contains(err, filesystem.ErrExists) {
+		return "", errReencodeInProgress
+	}
+	if err != nil {
+		return "", errors.AddContext(err, "unable to create re-encoded siafile")
+	}
+	if err := r.managedCopyReencodeMetadata(newPath, entry); err != nil {
+		return "", errors.Compose(err, r.staticFileSystem.DeleteFile(newPath))
+	}
+	return uid, nil
+}
+
+// managedCopyReencodeMetadata copies the compression info and checksum of the
+// original file to the re-encoded siafile. The compressed data is re-encoded
+// as it is.
+func (r *Renter) managedCopyReencodeMetadata(newPath modules.SiaPath, entry *filesystem.FileNode) (err error) {
+	newEntry, err := r.staticFileSystem.OpenSiaFile(newPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, newEntry.Close())
+	}()
+	if ci := entry.CompressionInfo(); ci.Compressed() {
+		if err := newEntry.SetCompressionInfo(ci); err != nil {
+			return err
+		}
+	}
+	if ci := entry.ChecksumInfo(); ci.HasChecksum() {
+		return newEntry.SetChecksum(ci.Type, ci.Checksum)
+	}
+	return nil
+}
+
+// threadedReencodeFile re-encodes the file with the given siapath and UID and
+// logs the outcome.
+func (r *Renter) threadedReencodeFile(siaPath modules.SiaPath, uid string) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	err := r.managedReencodeFile(siaPath, uid)
+	if errors.Contains(err, errReencodeIncomplete) {
+		r.log.Printf("Re-encode of %v will be retried: %v", siaPath, err)
+	} else if err != nil {
+		r.log.Printf("WARN: failed to re-encode %v: %v", siaPath, err)
+	} else {
+		r.log.Printf("Re-encoded %v", siaPath)
+	}
+}
+
+// threadedResumeReencodes resumes the pending re-encodes on startup and
+// periodically retries the ones that couldn't be finished.
+func (r *Renter) threadedResumeReencodes() {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		r.managedResumeReencodes()
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-r.staticReencodes.wakeChan:
+		case <-time.After(reencodeRetryInterval):
+		}
+	}
+}
+
+// managedResumeReencodes resumes all pending re-encodes that aren't running
+// and removes the original siafiles of finished swaps.
+func (r *Renter) managedResumeReencodes() {
+	// Collect the new siafiles of the pending re-encodes and the original
+	// siafiles of swaps.
+	var mu sync.Mutex
+	var pending, old []modules.SiaPath
+	list := func(folder modules.SiaPath, paths *[]modules.SiaPath) {
+		flf := func(fi modules.FileInfo) {
+			mu.Lock()
+			*paths = append(*paths, fi.SiaPath)
+			mu.Unlock()
+		}
+		err := r.staticFileSystem.CachedList(folder, true, flf, func(modules.DirectoryInfo) {})
+		if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+			r.log.Printf("WARN: failed to list pending re-encodes in %v: %v", folder, err)
+		}
+	}
+	list(reencodeNewFolder, &pending)
+	list(reencodeOldFolder, &old)
+
+	for _, path := range pending {
+		siaPath, uid, err := parseReencodePath(reencodeNewFolder, path)
+		if err != nil {
+			r.log.Printf("WARN: %v", err)
+			continue
+		}
+		if !r.staticReencodes.managedStart(siaPath) {
+			continue // already running
+		}
+		r.threadedReencodeFile(siaPath, uid)
+		r.staticReencodes.managedFinish(siaPath)
+	}
+
+	// The original siafiles without a new siafile belong to finished swaps
+	// and can be deleted.
+	for _, path := range old {
+		siaPath, uid, err := parseReencodePath(reencodeOldFolder, path)
+		if err != nil {
+			r.log.Printf("WARN: %v", err)
+			continue
+		}
+		newPath, oldPath, err := reencodePaths(siaPath, uid)
+		if err != nil {
+			continue
+		}
+		if exists, err := r.staticFileSystem.FileExists(newPath); err != nil || exists {
+			continue
+		}
+		if err := r.managedCleanupReencode(siaPath, uid, oldPath); err != nil {
+			r.log.Printf("WARN: failed to clean up re-encode of %v: %v", siaPath, err)
+		}
+	}
+}
+
+// managedReencodeFile uploads the missing chunks of the re-encoded siafile of
+// the file with the given siapath and UID and replaces the original siafile
+// afterwards.
+func (r *Renter) managedReencodeFile(siaPath modules.SiaPath, uid string) error {
+	newPath, oldPath, err := reencodePaths(siaPath, uid)
+	if err != nil {
+		return err
+	}
+	// If the original siafile was already moved out of the way, the swap was
+	// interrupted and only needs to be finished.
+	swapping, err := r.staticFileSystem.FileExists(oldPath)
+	if err != nil {
+		return err
+	}
+	if swapping {
+		return r.managedSwapReencodedFile(siaPath, uid)
+	}
+
+	// Make sure that the original file wasn't deleted or replaced in the
+	// meantime. Otherwise the re-encode is aborted.
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if errors.Contains(err, filesystem.ErrNotExist) {
+		return r.managedAbortReencode(siaPath, uid)
+	}
+	if err != nil {
+		return errors.AddContext(err, "unable to open original file")
+	}
+	if string(entry.UID()) != uid {
+		return errors.Compose(entry.Close(), r.managedAbortReencode(siaPath, uid))
+	}
+	snap, err := entry.Snapshot(siaPath)
+	err = errors.Compose(err, entry.Close())
+	if err != nil {
+		return errors.AddContext(err, "unable to snapshot original file")
+	}
+	if err := r.managedUploadReencodedChunks(siaPath, newPath, snap); err != nil {
+		return err
+	}
+	return r.managedSwapReencodedFile(siaPath, uid)
+}
+
+// managedUploadReencodedChunks streams the original file through the download
+// path and uploads all chunks of the re-encoded siafile that are missing
+// pieces.
+func (r *Renter) managedUploadReencodedChunks(siaPath, newPath modules.SiaPath, snap *siafile.Snapshot) (err error) {
+	newEntry, err := r.staticFileSystem.OpenSiaFile(newPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to open re-encoded siafile")
+	}
+	defer func() {
+		err = errors.Compose(err, newEntry.Close())
+	}()
+	if newEntry.NumChunks() != snap.NumChunks() {
+		return errors.New("number of chunks of re-encoded siafile doesn't match the original file")
+	}
+
+	// Build a map of host public keys.
+	pks := make(map[string]types.SiaPublicKey)
+	for _, pk := range newEntry.HostPublicKeys() {
+		pks[string(pk.Key)] = pk
+	}
+
+	// Restrict the hosts to the ones allowed by the storage policy of the
+	// original file.
+	dirSiaPath, err := siaPath.Dir()
+	if err != nil {
+		return err
+	}
+	policy, err := r.staticFileSystem.StoragePolicy(dirSiaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to get storage policy")
+	}
+	hosts := filterPolicyHosts(r.managedRefreshHostsAndWorkers(), policy)
+
+	// Stream the data as it is stored on the network. Compressed data is
+	// re-encoded without decompressing it.
+	stream := r.managedRawStreamer(snap, false)
+	defer func() {
+		err = errors.Compose(err, stream.Close())
+	}()
+
+	// Upload the chunks that are missing pieces one by one. The chunks are
+	// uploaded with low priority using the repair memory manager to not
+	// interfere with user uploads.
+	var chunks []*unfinishedUploadChunk
+	var skipped int
+	chunkSize := newEntry.ChunkSize()
+	for chunkIndex := uint64(0); chunkIndex < newEntry.NumChunks(); chunkIndex++ {
+		offline, goodForRenew, _ := r.managedContractUtilityMaps()
+		uuc, err := r.managedBuildUnfinishedChunk(newEntry, chunkIndex, hosts, pks, memoryPriorityLow, offline, goodForRenew, r.repairMemoryManager)
+		if err != nil {
+			return errors.AddContext(err, "unable to fetch chunk for re-encode")
+		}
+		if uuc.piecesCompleted >= uuc.staticPiecesNeeded {
+			continue // chunk was uploaded before
+		}
+
+		// The last chunk might be partial. Its length needs to match the data
+		// read from the original file to not change the size of the file.
+		offset := chunkIndex * chunkSize
+		if remaining := snap.Size() - offset; remaining < chunkSize {
+			uuc.length = remaining
+		}
+
+		// Read the chunk from the original file using a shard.
+		if _, err := stream.Seek(int64(offset), io.SeekStart); err != nil {
+			return errors.AddContext(err, "unable to seek original file")
+		}
+		ss := NewStreamShard(io.LimitReader(stream, int64(chunkSize)), nil)
+		uuc.sourceReader = ss
+		pushed, err := r.managedPushChunkForRepair(uuc, chunkTypeStreamChunk)
+		if err != nil {
+			return errors.AddContext(err, "unable to push chunk")
+		}
+		if !pushed {
+			// The chunk is already being repaired. It is checked again
+			// during the next attempt.
+			skipped++
+			if err := ss.Close(); err != nil {
+				return err
+			}
+			continue
+		}
+		chunks = append(chunks, uuc)
+
+		// Wait for the shard to be read before seeking to the next chunk.
+		select {
+		case <-r.tg.StopChan():
+			return errors.New("interrupted by shutdown")
+		case <-ss.signalChan:
+		}
+		if _, err := ss.Result(); err != nil && !errors.Contains(err, io.EOF) && !errors.Contains(err, io.ErrUnexpectedEOF) {
+			return errors.AddContext(err, "unable to read chunk from original file")
+		}
+	}
+
+	// Wait for the chunks to finish uploading.
+	for _, chunk := range chunks {
+		select {
+		case <-r.tg.StopChan():
+			return errors.New("interrupted by shutdown")
+		case <-chunk.staticUploadCompletedChan:
+		}
+		chunk.mu.Lock()
+		err, completed := chunk.err, chunk.piecesCompleted
+		chunk.mu.Unlock()
+		if err != nil {
+			return errors.AddContext(err, "unable to upload chunk")
+		}
+		// The original file is only replaced once the new siafile is as
+		// healthy as possible, since the new siafile can't be repaired from
+		// the original one afterwards.
+		if completed < chunk.staticPiecesNeeded {
+			skipped++
+		}
+	}
+	if skipped > 0 {
+		return errors.AddContext(errReencodeIncomplete, fmt.Sprintf("%v chunks remaining", skipped))
+	}
+	// Restore the exact size of the file since uploading the last chunk might
+	// have changed it.
+	return newEntry.SetFileSize(snap.Size())
+}
+
+// managedSwapReencodedFile replaces the original siafile of a file with its
+// re-encoded siafile.
+func (r *Renter) managedSwapReencodedFile(siaPath modules.SiaPath, uid string) error {
+	newPath, oldPath, err := reencodePaths(siaPath, uid)
+	if err != nil {
+		return err
+	}
+	r.staticReencodes.swapMu.Lock()
+	defer r.staticReencodes.swapMu.Unlock()
+
+	// Move the original siafile out of the way unless that already happened
+	// during an interrupted swap.
+	exists, err := r.staticFileSystem.FileExists(oldPath)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		// The file might have been deleted or replaced while its chunks were
+		// uploaded. Since the swap lock is held, it can't change anymore
+		// until the swap is done.
+		entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+		if errors.Contains(err, filesystem.ErrNotExist) {
+			return r.managedAbortReencode(siaPath, uid)
+		}
+		if err != nil {
+			return errors.AddContext(err, "unable to open original file")
+		}
+		if string(entry.UID()) != uid {
+			return errors.Compose(entry.Close(), r.managedAbortReencode(siaPath, uid))
+		}
+		err = r.managedCopyReencodeMutableMetadata(entry, newPath)
+		err = errors.Compose(err, entry.Close())
+		if err != nil {
+			return err
+		}
+		if err := r.staticFileSystem.RenameFile(siaPath, oldPath); err != nil {
+			return errors.AddContext(err, "unable to move original siafile")
+		}
+	}
+	// Move the new siafile into place. If that fails, the original file is
+	// restored.
+	if err := r.staticFileSystem.RenameFile(newPath, siaPath); err != nil {
+		err = errors.AddContext(err, "unable to move re-encoded siafile")
+		return errors.Compose(err, r.staticFileSystem.RenameFile(oldPath, siaPath))
+	}
+	err = r.managedCleanupReencode(siaPath, uid, oldPath)
+
+	// Update the health of the file's directory.
+	dirSiaPath, dirErr := siaPath.Dir()
+	if dirErr == nil {
+		_ = r.staticBubbleScheduler.callQueueBubble(dirSiaPath)
+	}
+	return err
+}
+
+// managedCopyReencodeMutableMetadata sets the local path and user metadata of
+// the re-encoded siafile to the ones of the original file. They are copied
+// right before the swap since they might change during the re-encode.
+func (r *Renter) managedCopyReencodeMutableMetadata(entry *filesystem.FileNode, newPath modules.SiaPath) (err error) {
+	localPath := entry.LocalPath()
+	um := entry.UserMetadata()
+	if localPath == "" && len(um) == 0 {
+		return nil
+	}
+	newEntry, err := r.staticFileSystem.OpenSiaFile(newPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to open re-encoded siafile")
+	}
+	defer func() {
+		err = errors.Compose(err, newEntry.Close())
+	}()
+	if localPath != "" {
+		if err := newEntry.SetLocalPath(localPath); err != nil {
+			return err
+		}
+	}
+	if len(um) == 0 {
+		return nil
+	}
+	return newEntry.UpdateUserMetadata(um)
+}
+
+// managedAbortReencode deletes the re-encoded siafile of a file that was
+// deleted or replaced while it was being re-encoded.
+func (r *Renter) managedAbortReencode(siaPath modules.SiaPath, uid string) error {
+	newPath, oldPath, err := reencodePaths(siaPath, uid)
+	if err != nil {
+		return err
+	}
+	err = errors.Compose(r.staticFileSystem.DeleteFile(newPath), r.managedCleanupReencode(siaPath, uid, oldPath))
+	return errors.Compose(errors.New("original file was deleted or replaced, aborting re-encode"), err)
+}
+
+// managedCleanupReencode deletes the original siafile after a swap and the
+// directories of the re-encode.
+func (r *Renter) managedCleanupReencode(siaPath modules.SiaPath, uid string, oldPath modules.SiaPath) error {
+	err := r.staticFileSystem.DeleteFile(oldPath)
+	if errors.Contains(err, filesystem.ErrNotExist) {
+		err = nil
+	}
+	for _, folder := range []modules.SiaPath{reencodeNewFolder, reencodeOldFolder} {
+		dir, joinErr := folder.Join(uid)
+		if joinErr != nil {
+			return errors.Compose(err, joinErr)
+		}
+		if delErr := r.staticFileSystem.DeleteDir(dir); delErr != nil && !errors.Contains(delErr, filesystem.ErrNotExist) {
+			err = errors.Compose(err, delErr)
+		}
+		_ = r.staticBubbleScheduler.callQueueBubble(folder)
+	}
+	return err
+}
@@ -0,0 +1,192 @@
+package renter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/siatest/dependencies"
+)
+
+// TestReencodePaths tests that the siapath and UID of a re-encoded file can be
+// recovered from the paths of its new and original siafile.
+func TestReencodePaths(t *testing.T) {
+	siaPath, err := modules.UserFolder.Join("dir/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	newPath, oldPath, err := reencodePaths(siaPath, "uid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isReencodePath(newPath) || !isReencodePath(oldPath) || isReencodePath(siaPath) {
+		t.Fatal("wrong re-encode paths", newPath, oldPath)
+	}
+	for folder, path := range map[modules.SiaPath]modules.SiaPath{reencodeNewFolder: newPath, reencodeOldFolder: oldPath} {
+		sp, uid, err := parseReencodePath(folder, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !sp.Equals(siaPath) || uid != "uid" {
+			t.Fatalf("expected %v and %v but got %v and %v", siaPath, "uid", sp, uid)
+		}
+	}
+	// A path without a UID is invalid.
+	if _, _, err := parseReencodePath(reencodeNewFolder, reencodeNewFolder); err == nil {
+		t.Fatal("expected error for invalid path")
+	}
+}
+
+// TestReencodeSet tests that a file can only be re-encoded once at a time.
+func TestReencodeSet(t *testing.T) {
+	rs := newReencodeSet()
+	siaPath := modules.RandomSiaPath()
+	if !rs.managedStart(siaPath) {
+		t.Fatal("re-encode should start")
+	}
+	if rs.managedStart(siaPath) {
+		t.Fatal("re-encode shouldn't start twice")
+	}
+	rs.managedFinish(siaPath)
+	if !rs.managedStart(siaPath) {
+		t.Fatal("re-encode should start after finishing")
+	}
+}
+
+// TestReencodeFolderExcluded tests that the siafiles of pending re-encodes
+// neither count towards the health of the root directory nor are visited by
+// the repair loops.
+func TestReencodeFolderExcluded(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a file and the new siafile of a pending re-encode of it.
+	siaPath := modules.RandomSiaPath()
+	newPath, _, err := reencodePaths(siaPath, "uid")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := modules.NewRSCode(1, 1)
+	for _, sp := range []modules.SiaPath{siaPath, newPath} {
+		err = rt.renter.staticFileSystem.NewSiaFile(sp, "", rsc, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), 100, persist.DefaultDiskPermissionsTest, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	newDir, err := newPath.Dir()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.bubbleAll([]modules.SiaPath{newDir, modules.RootSiaPath()}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the original file counts towards the root directory.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		dirInfo, err := rt.renter.staticFileSystem.DirInfo(modules.RootSiaPath())
+		if err != nil {
+			return err
+		}
+		if dirInfo.AggregateNumFiles != 1 {
+			return fmt.Errorf("AggregateNumFiles incorrect, got %v expected %v", dirInfo.AggregateNumFiles, 1)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The repair loops don't visit the re-encode folder.
+	subDirs, err := rt.renter.managedSubDirectories(modules.RootSiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, subDir := range subDirs {
+		if isReencodePath(subDir) {
+			t.Fatal("re-encode folder shouldn't be visited", subDir)
+		}
+	}
+}
+
+// TestSwapReencodedFileReplaced tests that a re-encoded siafile doesn't replace
+// a file that was replaced while it was being re-encoded.
+func TestSwapReencodedFileReplaced(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a file and the new siafile of a re-encode of it.
+	fs := rt.renter.staticFileSystem
+	siaPath := modules.RandomSiaPath()
+	rsc, _ := modules.NewRSCode(1, 1)
+	newFile := func(sp modules.SiaPath) {
+		err := fs.NewSiaFile(sp, "", rsc, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), 100, persist.DefaultDiskPermissionsTest, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	uid := func(sp modules.SiaPath) string {
+		entry, err := fs.OpenSiaFile(sp)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			if err := entry.Close(); err != nil {
+				t.Fatal(err)
+			}
+		}()
+		return string(entry.UID())
+	}
+	newFile(siaPath)
+	oldUID := uid(siaPath)
+	newPath, _, err := reencodePaths(siaPath, oldUID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newFile(newPath)
+
+	// Replace the file before the swap.
+	if err := rt.renter.DeleteFile(siaPath); err != nil {
+		t.Fatal(err)
+	}
+	newFile(siaPath)
+	replacedUID := uid(siaPath)
+
+	// The swap is aborted and the replacing file is kept.
+	if err := rt.renter.managedSwapReencodedFile(siaPath, oldUID); err == nil {
+		t.Fatal("expected swap to be aborted")
+	}
+	if uid(siaPath) != replacedUID {
+		t.Fatal("replacing file was swapped")
+	}
+	if exists, err := fs.FileExists(newPath); err != nil || exists {
+		t.Fatal("re-encoded siafile wasn't deleted", err)
+	}
+}
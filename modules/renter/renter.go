@@ -135,6 +135,10 @@ type hostContractor interface {
 	// Session creates a Session from the specified contract ID.
 	Session(types.SiaPublicKey, <-chan struct{}) (contractor.Session, error)
 
+	// RandomContractRoots returns up to n distinct, randomly chosen sector
+	// roots from the renter's contract with the specified host.
+	RandomContractRoots(types.SiaPublicKey, int) ([]crypto.Hash, error)
+
 	// RecoverableContracts returns the contracts that the contractor deems
 	// recoverable. That means they are not expired yet and also not part of the
 	// active contracts. Usually this should return an empty slice unless the host
@@ -258,12 +262,15 @@ type Renter struct {
 	staticFileSystem                   *filesystem.FileSystem
 	staticFuseManager                  renterFuseManager
 	staticStreamBufferSet              *streamBufferSet
+	staticTracer                       *tracer
 	tg                                 threadgroup.ThreadGroup
 	tpool                              modules.TransactionPool
 	wal                                *writeaheadlog.WAL
 	staticWorkerPool                   *workerPool
 	staticMux                          *siamux.SiaMux
 	memoryManager                      *memoryManager
+	staticReencodes                    *reencodeSet
+	staticSyncManager                  *syncManager
 	staticUploadChunkDistributionQueue *uploadChunkDistributionQueue
 }
 
@@ -299,6 +306,28 @@ func (r *Renter) MemoryStatus() (modules.MemoryStatus, error) {
 	}, nil
 }
 
+// RepairStatus returns the current status of the repair loop.
+func (r *Renter) RepairStatus() (modules.RepairStatus, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.RepairStatus{}, err
+	}
+	defer r.tg.Done()
+
+	di, err := r.staticFileSystem.DirInfo(modules.RootSiaPath())
+	if err != nil {
+		return modules.RepairStatus{}, errors.AddContext(err, "unable to get root directory info")
+	}
+	return modules.RepairStatus{
+		DirectoryHeapSize: r.directoryHeap.managedLen(),
+		UploadHeapSize:    r.uploadHeap.managedLen(),
+
+		NumStuckChunks: di.AggregateNumStuckChunks,
+		RepairSize:     di.AggregateRepairSize,
+		StuckSize:      di.AggregateStuckSize,
+		Health:         di.AggregateHealth,
+	}, nil
+}
+
 // PriceEstimation estimates the cost in siacoins of performing various storage
 // and data operations.  The estimation will be done using the provided
 // allowance, if an empty allowance is provided then the renter's current
@@ -614,6 +643,18 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	// Set IPViolationsCheck
 	r.hostDB.SetIPViolationCheck(s.IPViolationCheck)
 
+	// Set the host selection policy.
+	err = r.hostDB.SetSelectionPolicy(s.HostSelectionPolicy)
+	if err != nil {
+		return err
+	}
+
+	// Set the weight of the host latency adjustment.
+	err = r.hostDB.SetLatencyWeight(s.HostLatencyWeight)
+	if err != nil {
+		return err
+	}
+
 	// Set the bandwidth limits.
 	err = r.setBandwidthLimits(s.MaxDownloadSpeed, s.MaxUploadSpeed)
 	if err != nil {
@@ -656,6 +697,11 @@ func (r *Renter) SetFileTrackingPath(siaPath modules.SiaPath, newPath string) (e
 		err = errors.Compose(err, entry.Close())
 	}()
 
+	// The uploaded data of compressed files doesn't match the data on disk.
+	if entry.CompressionInfo().Compressed() {
+		return errors.New("can't track the local path of a compressed file")
+	}
+
 	// Sanity check that a file with the correct size exists at the new
 	// location.
 	fi, err := os.Stat(newPath)
@@ -715,6 +761,36 @@ func (r *Renter) SetFilterMode(lm modules.FilterMode, hosts []types.SiaPublicKey
 	return nil
 }
 
+// HostScoringRules returns the user defined rules that adjust the scores of
+// the renter's hostdb.
+func (r *Renter) HostScoringRules() (modules.HostScoringRules, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.HostScoringRules{}, err
+	}
+	defer r.tg.Done()
+	return r.hostDB.ScoringRules()
+}
+
+// SetHostScoringRules replaces the user defined rules that adjust the scores
+// of the renter's hostdb.
+func (r *Renter) SetHostScoringRules(rules modules.HostScoringRules) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.hostDB.SetScoringRules(rules)
+}
+
+// PreviewHostScoringRules returns the score breakdowns the active hosts would
+// have if the given scoring rules were applied.
+func (r *Renter) PreviewHostScoringRules(rules modules.HostScoringRules) ([]modules.HostScorePreview, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.hostDB.PreviewScoringRules(rules)
+}
+
 // Host returns the host associated with the given public key
 func (r *Renter) Host(spk types.SiaPublicKey) (modules.HostDBEntry, bool, error) {
 	return r.hostDB.Host(spk)
@@ -816,12 +892,22 @@ func (r *Renter) Settings() (modules.RenterSettings, error) {
 	if err != nil {
 		return modules.RenterSettings{}, errors.AddContext(err, "error getting IPViolationsCheck:")
 	}
+	policy, err := r.hostDB.SelectionPolicy()
+	if err != nil {
+		return modules.RenterSettings{}, errors.AddContext(err, "error getting host selection policy:")
+	}
+	latencyWeight, err := r.hostDB.LatencyWeight()
+	if err != nil {
+		return modules.RenterSettings{}, errors.AddContext(err, "error getting host latency weight:")
+	}
 	paused, endTime := r.uploadHeap.managedPauseStatus()
 	return modules.RenterSettings{
-		Allowance:        r.hostContractor.Allowance(),
-		IPViolationCheck: enabled,
-		MaxDownloadSpeed: download,
-		MaxUploadSpeed:   upload,
+		Allowance:           r.hostContractor.Allowance(),
+		HostLatencyWeight:   latencyWeight,
+		HostSelectionPolicy: policy,
+		IPViolationCheck:    enabled,
+		MaxDownloadSpeed:    download,
+		MaxUploadSpeed:      upload,
 		UploadsStatus: modules.UploadsStatus{
 			Paused:       paused,
 			PauseEndTime: endTime,
@@ -927,6 +1013,8 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 	}
 	r.staticBubbleScheduler = newBubbleScheduler(r)
 	r.staticStreamBufferSet = newStreamBufferSet(&r.tg)
+	r.staticTracer = newTracer()
+	r.staticReencodes = newReencodeSet()
 	r.staticUploadChunkDistributionQueue = newUploadChunkDistributionQueue(r)
 	r.staticRRS = newReadRegistryStats(ReadRegistryBackgroundTimeout, readRegistryStatsInterval, readRegistryStatsDecay, readRegistryStatsPercentile)
 	close(r.uploadHeap.pauseChan)
@@ -979,6 +1067,21 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 		return nil, err
 	}
 
+	// Load the sync runs.
+	r.staticSyncManager, err = newSyncManager(r.persistDir)
+	if err != nil {
+		return nil, err
+	}
+
+	// Persist the most recent usage of the tenants on shutdown.
+	if err := r.tg.AfterStop(r.staticFileSystem.SaveTenants); err != nil {
+		return nil, err
+	}
+
+	// Export the traces if an export destination was set.
+	r.staticTracer.managedSetExportDestination(r.persist.TraceExportDestination)
+	go r.threadedExportTraces()
+
 	// After persist is initialized, create the worker pool.
 	r.staticWorkerPool = r.newWorkerPool()
 
@@ -1043,6 +1146,20 @@ func renterAsyncStartup(r *Renter, cs modules.ConsensusSet) error {
 	if !r.deps.Disrupt("DisableSnapshotSync") {
 		go r.threadedSynchronizeSnapshots()
 	}
+	// Spin up the host audit thread.
+	if !r.deps.Disrupt("DisableHostAudits") {
+		go r.threadedAuditHosts()
+	}
+	// Spin up the thread reporting the measured host latencies.
+	go r.threadedReportHostLatencies()
+	// Spin up the thread removing expired file versions.
+	go r.threadedPruneFileVersions()
+	// Spin up the thread resuming pending re-encodes.
+	if !r.deps.Disrupt("DisableRepairAndHealthLoops") {
+		go r.threadedResumeReencodes()
+	}
+	// Spin up the thread resuming interrupted syncs.
+	go r.threadedResumeSyncs()
 	return nil
 }
 
@@ -316,8 +316,9 @@ func (r *Renter) managedStuckDirectory() (modules.SiaPath, error) {
 				return siaPath, nil
 			}
 
-			// Skip directories with no stuck chunks
-			if directories[i].AggregateNumStuckChunks == uint64(0) {
+			// Skip directories with no stuck chunks and the directories of
+			// pending re-encodes
+			if directories[i].AggregateNumStuckChunks == uint64(0) || isReencodePath(directories[i].SiaPath) {
 				continue
 			}
 
@@ -439,6 +440,11 @@ func (r *Renter) managedSubDirectories(siaPath modules.SiaPath) ([]modules.SiaPa
 			if err != nil {
 				return nil, err
 			}
+			// The directories of pending re-encodes are skipped since
+			// their siafiles are maintained by the re-encode itself.
+			if isReencodePath(subDir) {
+				continue
+			}
 			folders = append(folders, subDir)
 		}
 	}
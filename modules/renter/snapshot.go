@@ -249,7 +249,10 @@ func (r *Renter) DownloadBackup(dst string, name string) (err error) {
 	if err != nil {
 		return err
 	}
-	s := r.managedStreamer(snap, false)
+	s, err := r.managedStreamer(snap, false)
+	if err != nil {
+		return err
+	}
 	_, err = io.Copy(dstFile, s)
 	return errors.Compose(err, s.Close())
 }
@@ -0,0 +1,188 @@
+package renter
+
+// storagepolicy.go contains the renter's interface to the storage policies of
+// directories and the helpers the upload and repair code uses to apply them.
+//
+// The repair loop compares the health of files and chunks against
+// modules.RepairThreshold. To enforce the repair threshold of a policy, the
+// health of the chunks governed by the policy is scaled by the ratio of the
+// default threshold to the policy's threshold before it enters the directory
+// and upload heaps. That way chunks from directories with different policies
+// can be compared with each other.
+//
+// Changing the erasure coding parameters or cipher type of a policy queues
+// re-encodes of the existing files governed by it. The queued re-encodes are
+// persisted like any other re-encode and run one at a time in the background.
+
+import (
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+)
+
+// SetStoragePolicy sets the storage policy of a directory.
+func (r *Renter) SetStoragePolicy(siaPath modules.SiaPath, policy modules.StoragePolicy) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	oldPolicy, err := r.staticFileSystem.StoragePolicy(siaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to get storage policy")
+	}
+	err = r.staticFileSystem.SetStoragePolicy(siaPath, policy)
+	if err != nil {
+		return errors.AddContext(err, "unable to set storage policy")
+	}
+	// The repair health of the whole subtree depends on the policy, so it
+	// needs to be bubbled again.
+	urp, err := r.callPrepareForBubble(siaPath, true)
+	if err != nil {
+		return errors.AddContext(err, "unable to prepare subtree for bubble")
+	}
+	if err := urp.callRefreshAll(); err != nil {
+		return err
+	}
+	// Files that were uploaded with other parameters are re-encoded.
+	if sameEncoding(oldPolicy, policy) {
+		return nil
+	}
+	return errors.AddContext(r.managedQueuePolicyReencodes(siaPath, policy), "unable to queue re-encodes")
+}
+
+// managedQueuePolicyReencodes queues re-encodes of the files governed by the
+// storage policy of a directory which don't use the policy's erasure coding
+// parameters or cipher type.
+func (r *Renter) managedQueuePolicyReencodes(dirSiaPath modules.SiaPath, policy modules.StoragePolicy) error {
+	ec, err := policy.ErasureCode()
+	if err != nil {
+		return err
+	}
+	var ct crypto.CipherType
+	if policy.CipherType != "" {
+		if err := ct.FromString(policy.CipherType); err != nil {
+			return err
+		}
+	}
+	if ec == nil && ct == (crypto.CipherType{}) {
+		return nil
+	}
+
+	// Collect the files within the directory.
+	var mu sync.Mutex
+	var siaPaths []modules.SiaPath
+	flf := func(fi modules.FileInfo) {
+		mu.Lock()
+		siaPaths = append(siaPaths, fi.SiaPath)
+		mu.Unlock()
+	}
+	err = r.staticFileSystem.CachedList(dirSiaPath, true, flf, func(modules.DirectoryInfo) {})
+	if err != nil {
+		return err
+	}
+
+	policies := make(map[modules.SiaPath]modules.StoragePolicy)
+	for _, siaPath := range siaPaths {
+		if isReencodePath(siaPath) {
+			continue
+		}
+		// Skip the files of subdirectories with a policy of their own that
+		// uses other parameters.
+		fileDir, err := siaPath.Dir()
+		if err != nil {
+			return err
+		}
+		filePolicy, exists := policies[fileDir]
+		if !exists {
+			filePolicy, err = r.staticFileSystem.StoragePolicy(fileDir)
+			if err != nil {
+				return err
+			}
+			policies[fileDir] = filePolicy
+		}
+		if !sameEncoding(filePolicy, policy) {
+			continue
+		}
+		if !r.staticReencodes.managedStart(siaPath) {
+			continue
+		}
+		_, err = r.managedQueueReencode(siaPath, ec, ct)
+		r.staticReencodes.managedFinish(siaPath)
+		if errors.Contains(err, errReencodeUnchanged) || errors.Contains(err, errReencodeInProgress) || errors.Contains(err, filesystem.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return errors.AddContext(err, "unable to queue re-encode of "+siaPath.String())
+		}
+	}
+	r.staticReencodes.callWake()
+	return nil
+}
+
+// StoragePolicy returns the storage policy that applies to the files within a
+// directory.
+func (r *Renter) StoragePolicy(siaPath modules.SiaPath) (modules.StoragePolicy, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.StoragePolicy{}, err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.StoragePolicy(siaPath)
+}
+
+// managedFileStoragePolicy returns the storage policy that applies to a file.
+func (r *Renter) managedFileStoragePolicy(entry *filesystem.FileNode) (modules.StoragePolicy, error) {
+	dirSiaPath, err := r.staticFileSystem.FileSiaPath(entry).Dir()
+	if err != nil {
+		return modules.StoragePolicy{}, err
+	}
+	return r.staticFileSystem.StoragePolicy(dirSiaPath)
+}
+
+// sameEncoding returns true if both policies specify the same erasure coding
+// parameters and cipher type.
+func sameEncoding(a, b modules.StoragePolicy) bool {
+	return a.DataPieces == b.DataPieces && a.ParityPieces == b.ParityPieces && a.CipherType == b.CipherType
+}
+
+// applyStoragePolicy fills in the parameters of an upload that weren't set
+// explicitly with the parameters of the policy.
+func applyStoragePolicy(up *modules.FileUploadParams, policy modules.StoragePolicy) error {
+	if up.ErasureCode == nil && !up.Repair {
+		ec, err := policy.ErasureCode()
+		if err != nil {
+			return errors.AddContext(err, "invalid erasure coding parameters in storage policy")
+		}
+		up.ErasureCode = ec
+	}
+	if up.CipherType == (crypto.CipherType{}) && policy.CipherType != "" {
+		if err := up.CipherType.FromString(policy.CipherType); err != nil {
+			return errors.AddContext(err, "invalid cipher type in storage policy")
+		}
+	}
+	return nil
+}
+
+// repairHealth scales the health of a file or chunk governed by the policy
+// such that it needs to be repaired if the scaled health is at or above
+// modules.RepairThreshold.
+func repairHealth(health float64, policy modules.StoragePolicy) float64 {
+	return health * modules.RepairThreshold / policy.Threshold()
+}
+
+// filterPolicyHosts returns the subset of hosts the policy allows to store
+// pieces on.
+func filterPolicyHosts(hosts map[string]struct{}, policy modules.StoragePolicy) map[string]struct{} {
+	if len(policy.AllowedHosts) == 0 {
+		return hosts
+	}
+	allowed := make(map[string]struct{}, len(policy.AllowedHosts))
+	for _, pk := range policy.AllowedHosts {
+		if _, exists := hosts[pk.String()]; exists {
+			allowed[pk.String()] = struct{}{}
+		}
+	}
+	return allowed
+}
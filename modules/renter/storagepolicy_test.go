@@ -0,0 +1,208 @@
+package renter
+
+import (
+	"container/heap"
+	"testing"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/siatest/dependencies"
+	"go.sia.tech/siad/types"
+)
+
+// TestApplyStoragePolicy tests that the parameters of a storage policy are
+// only applied to uploads that don't set them explicitly.
+func TestApplyStoragePolicy(t *testing.T) {
+	policy := modules.StoragePolicy{DataPieces: 2, ParityPieces: 5, CipherType: crypto.TypePlain.String()}
+
+	// The policy fills in missing parameters.
+	var up modules.FileUploadParams
+	if err := applyStoragePolicy(&up, policy); err != nil {
+		t.Fatal(err)
+	}
+	if up.ErasureCode == nil || up.ErasureCode.MinPieces() != 2 || up.ErasureCode.NumPieces() != 7 {
+		t.Fatal("erasure code of the policy wasn't applied", up.ErasureCode)
+	}
+	if up.CipherType != crypto.TypePlain {
+		t.Fatal("cipher type of the policy wasn't applied", up.CipherType)
+	}
+
+	// Explicit parameters take precedence.
+	up = modules.FileUploadParams{
+		ErasureCode: modules.NewRSSubCodeDefault(),
+		CipherType:  crypto.TypeThreefish,
+	}
+	if err := applyStoragePolicy(&up, policy); err != nil {
+		t.Fatal(err)
+	}
+	if up.ErasureCode.MinPieces() != modules.RenterDefaultDataPieces {
+		t.Fatal("explicit erasure code was overwritten")
+	}
+	if up.CipherType != crypto.TypeThreefish {
+		t.Fatal("explicit cipher type was overwritten")
+	}
+
+	// Repairs keep the erasure code of the existing file.
+	up = modules.FileUploadParams{Repair: true}
+	if err := applyStoragePolicy(&up, policy); err != nil {
+		t.Fatal(err)
+	}
+	if up.ErasureCode != nil {
+		t.Fatal("erasure code shouldn't be set for repairs")
+	}
+
+	// An empty policy leaves the params untouched.
+	up = modules.FileUploadParams{}
+	if err := applyStoragePolicy(&up, modules.StoragePolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if up.ErasureCode != nil || up.CipherType != (crypto.CipherType{}) {
+		t.Fatal("empty policy shouldn't set any params")
+	}
+}
+
+// TestRepairHealth tests scaling healths by the repair threshold of a policy.
+func TestRepairHealth(t *testing.T) {
+	// Without a threshold the health is unchanged.
+	if h := repairHealth(0.2, modules.StoragePolicy{}); h != 0.2 {
+		t.Fatal("health shouldn't be scaled", h)
+	}
+	// A stricter threshold makes a file need repair earlier.
+	strict := modules.StoragePolicy{RepairThreshold: modules.RepairThreshold / 2}
+	if modules.NeedsRepair(0.2) || !modules.NeedsRepair(repairHealth(0.2, strict)) {
+		t.Fatal("file should only need repair with the strict policy")
+	}
+	// A looser threshold makes a file need repair later.
+	loose := modules.StoragePolicy{RepairThreshold: modules.RepairThreshold * 2}
+	if !modules.NeedsRepair(0.3) || modules.NeedsRepair(repairHealth(0.3, loose)) {
+		t.Fatal("file should only need repair without the loose policy")
+	}
+}
+
+// TestFilterPolicyHosts tests restricting hosts to the ones allowed by a
+// policy.
+func TestFilterPolicyHosts(t *testing.T) {
+	pk1 := types.Ed25519PublicKey(crypto.PublicKey{1})
+	pk2 := types.Ed25519PublicKey(crypto.PublicKey{2})
+	pk3 := types.Ed25519PublicKey(crypto.PublicKey{3})
+	hosts := map[string]struct{}{
+		pk1.String(): {},
+		pk2.String(): {},
+	}
+
+	// Without allowed hosts all hosts are used.
+	if filtered := filterPolicyHosts(hosts, modules.StoragePolicy{}); len(filtered) != 2 {
+		t.Fatal("all hosts should be allowed", filtered)
+	}
+	// Only allowed hosts the renter has contracts with are used.
+	policy := modules.StoragePolicy{AllowedHosts: []types.SiaPublicKey{pk2, pk3}}
+	filtered := filterPolicyHosts(hosts, policy)
+	if _, exists := filtered[pk2.String()]; !exists || len(filtered) != 1 {
+		t.Fatal("wrong hosts", filtered)
+	}
+}
+
+// TestUploadHeapRepairPriority tests that chunks with a higher repair
+// priority are popped off the upload heap first.
+func TestUploadHeapRepairPriority(t *testing.T) {
+	var uch uploadChunkHeap
+	heap.Push(&uch, &unfinishedUploadChunk{health: 1})
+	heap.Push(&uch, &unfinishedUploadChunk{health: 0.5, staticRepairPriority: 1})
+	heap.Push(&uch, &unfinishedUploadChunk{health: 0.3, staticRepairPriority: 2})
+	heap.Push(&uch, &unfinishedUploadChunk{health: 0.1, staticPriority: true})
+
+	expected := []float64{0.1, 0.3, 0.5, 1}
+	for _, health := range expected {
+		if uuc := heap.Pop(&uch).(*unfinishedUploadChunk); uuc.health != health {
+			t.Fatalf("expected chunk with health %v but got %v", health, uuc.health)
+		}
+	}
+}
+
+// TestSetStoragePolicyReencodes tests that changing the erasure coding
+// parameters of a storage policy queues re-encodes of the files governed by
+// the policy.
+func TestSetStoragePolicyReencodes(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	rt, err := newRenterTesterWithDependency(t.Name(), &dependencies.DependencyDisableRepairAndHealthLoops{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	// Create files in a directory and in subdirectories with and without a
+	// policy of their own.
+	dir := modules.RandomSiaPath()
+	newFile := func(path string, data, parity int) modules.SiaPath {
+		siaPath, err := dir.Join(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rsc, err := modules.NewRSSubCode(data, parity, crypto.SegmentSize)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = r.staticFileSystem.NewSiaFile(siaPath, "", rsc, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), 100, persist.DefaultDiskPermissionsTest, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return siaPath
+	}
+	queued := []modules.SiaPath{newFile("a", 1, 1), newFile("sub/b", 1, 1)}
+	unchanged := []modules.SiaPath{newFile("c", 2, 2), newFile("other/d", 1, 1)}
+	otherDir, err := dir.Join("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetStoragePolicy(otherDir, modules.StoragePolicy{RepairThreshold: 0.5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.SetStoragePolicy(otherDir, modules.StoragePolicy{DataPieces: 1, ParityPieces: 1}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the files governed by the policy which use other parameters are
+	// queued.
+	if err := r.SetStoragePolicy(dir, modules.StoragePolicy{DataPieces: 2, ParityPieces: 2}); err != nil {
+		t.Fatal(err)
+	}
+	isQueued := func(siaPath modules.SiaPath) bool {
+		entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		uid := string(entry.UID())
+		if err := entry.Close(); err != nil {
+			t.Fatal(err)
+		}
+		newPath, _, err := reencodePaths(siaPath, uid)
+		if err != nil {
+			t.Fatal(err)
+		}
+		exists, err := r.staticFileSystem.FileExists(newPath)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exists
+	}
+	for _, siaPath := range queued {
+		if !isQueued(siaPath) {
+			t.Fatal("re-encode wasn't queued", siaPath)
+		}
+	}
+	for _, siaPath := range unchanged {
+		if isQueued(siaPath) {
+			t.Fatal("re-encode shouldn't be queued", siaPath)
+		}
+	}
+}
@@ -0,0 +1,479 @@
+package renter
+
+// sync.go contains the logic for synchronizing a local directory to a siapath.
+// A sync compares the local files against the remote files by size, modtime
+// and checksum and plans the uploads, updates and deletes which make the
+// remote directory match the local one.
+//
+// The plan of a run is persisted before any of its actions is taken and the
+// progress of the run is persisted while they are taken. Runs which were
+// interrupted by a shutdown are resumed on startup, skipping the actions that
+// were already taken.
+
+import (
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/persist"
+)
+
+const (
+	// syncPersistFilename is the name of the file the sync runs are persisted
+	// to.
+	syncPersistFilename = "sync.json"
+
+	// syncPersistInterval is the number of actions after which the progress of
+	// a sync run is persisted. Actions that were taken after the progress was
+	// last persisted are taken again when the run is resumed.
+	syncPersistInterval = 100
+
+	// maxFinishedSyncRuns is the number of finished sync runs that are kept.
+	maxFinishedSyncRuns = 20
+)
+
+var (
+	// errSyncInProgress is returned when starting a sync of a siapath which
+	// overlaps with the siapath of a sync that is still running.
+	errSyncInProgress = errors.New("a sync of the siapath is already in progress")
+
+	// syncMetadata is the metadata of the persisted sync runs.
+	syncMetadata = persist.Metadata{
+		Header:  "Renter Sync Runs",
+		Version: "1.0",
+	}
+)
+
+type (
+	// syncManager keeps track of the sync runs of the renter and persists
+	// them.
+	syncManager struct {
+		runs []*modules.SyncRun
+
+		staticPersistPath string
+		mu                sync.Mutex
+	}
+
+	// syncPersistence is the persisted data of the syncManager.
+	syncPersistence struct {
+		Runs []*modules.SyncRun `json:"runs"`
+	}
+)
+
+// newSyncManager creates a new syncManager and loads the persisted sync runs
+// from the persist dir.
+func newSyncManager(persistDir string) (*syncManager, error) {
+	sm := &syncManager{
+		staticPersistPath: filepath.Join(persistDir, syncPersistFilename),
+	}
+	var sp syncPersistence
+	err := persist.LoadJSON(syncMetadata, &sp, sm.staticPersistPath)
+	if os.IsNotExist(err) {
+		return sm, nil
+	}
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load sync runs")
+	}
+	sm.runs = sp.Runs
+	return sm, nil
+}
+
+// copySyncRun returns a deep copy of a sync run.
+func copySyncRun(run *modules.SyncRun) modules.SyncRun {
+	cpy := *run
+	cpy.Actions = append([]modules.SyncAction{}, run.Actions...)
+	cpy.Params.Include = append([]string{}, run.Params.Include...)
+	cpy.Params.Exclude = append([]string{}, run.Params.Exclude...)
+	return cpy
+}
+
+// siaPathsOverlap returns true if one of the siapaths contains the other.
+func siaPathsOverlap(a, b modules.SiaPath) bool {
+	if a.IsRoot() || b.IsRoot() || a.Equals(b) {
+		return true
+	}
+	return strings.HasPrefix(a.Path, b.Path+"/") || strings.HasPrefix(b.Path, a.Path+"/")
+}
+
+// managedAdd adds a new run and persists it. Old finished runs are dropped.
+func (sm *syncManager) managedAdd(run modules.SyncRun) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	for _, r := range sm.runs {
+		if !r.Completed && siaPathsOverlap(r.Params.SiaPath, run.Params.SiaPath) {
+			return errSyncInProgress
+		}
+	}
+
+	// Drop the oldest finished runs.
+	var finished int
+	for i := len(sm.runs) - 1; i >= 0; i-- {
+		if !sm.runs[i].Completed {
+			continue
+		}
+		finished++
+		if finished >= maxFinishedSyncRuns {
+			sm.runs = append(sm.runs[:i], sm.runs[i+1:]...)
+		}
+	}
+	sm.runs = append(sm.runs, &run)
+	return sm.save()
+}
+
+// managedFinishAction records the outcome of an action of a run.
+func (sm *syncManager) managedFinishAction(id string, index int, err error) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	run := sm.run(id)
+	if run == nil || index >= len(run.Actions) {
+		return
+	}
+	run.Actions[index].Done = true
+	if err != nil {
+		run.Actions[index].Error = err.Error()
+	}
+}
+
+// managedFinishRun marks a run as completed and persists it.
+func (sm *syncManager) managedFinishRun(id string) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	run := sm.run(id)
+	if run == nil {
+		return errors.New("unknown sync run " + id)
+	}
+	var failed int
+	for _, action := range run.Actions {
+		if action.Error != "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		run.Error = fmt.Sprintf("%v of %v actions failed", failed, len(run.Actions))
+	}
+	run.Completed = true
+	run.EndTime = time.Now()
+	return sm.save()
+}
+
+// managedRun returns a copy of the run with the given ID.
+func (sm *syncManager) managedRun(id string) (modules.SyncRun, bool) {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	run := sm.run(id)
+	if run == nil {
+		return modules.SyncRun{}, false
+	}
+	return copySyncRun(run), true
+}
+
+// managedRuns returns copies of all runs.
+func (sm *syncManager) managedRuns() []modules.SyncRun {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	runs := make([]modules.SyncRun, 0, len(sm.runs))
+	for _, run := range sm.runs {
+		runs = append(runs, copySyncRun(run))
+	}
+	return runs
+}
+
+// managedSave persists the runs.
+func (sm *syncManager) managedSave() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+	return sm.save()
+}
+
+// run returns the run with the given ID or nil if it doesn't exist.
+func (sm *syncManager) run(id string) *modules.SyncRun {
+	for _, run := range sm.runs {
+		if run.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+// save persists the runs.
+func (sm *syncManager) save() error {
+	return persist.SaveJSON(syncMetadata, syncPersistence{Runs: sm.runs}, sm.staticPersistPath)
+}
+
+// Sync plans the synchronization of a local directory to a siapath and takes
+// the planned actions in the background.
+func (r *Renter) Sync(params modules.SyncParams) (modules.SyncRun, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.SyncRun{}, err
+	}
+	defer r.tg.Done()
+	actions, err := r.managedPlanSync(params)
+	if err != nil {
+		return modules.SyncRun{}, err
+	}
+	run := modules.SyncRun{
+		ID:        hex.EncodeToString(fastrand.Bytes(8)),
+		Params:    params,
+		Actions:   actions,
+		StartTime: time.Now(),
+	}
+	cpy := copySyncRun(&run)
+	if err := r.staticSyncManager.managedAdd(run); err != nil {
+		return modules.SyncRun{}, err
+	}
+	go r.threadedSync(run.ID)
+	return cpy, nil
+}
+
+// SyncPlan returns the actions a sync with the given params would take.
+func (r *Renter) SyncPlan(params modules.SyncParams) ([]modules.SyncAction, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.managedPlanSync(params)
+}
+
+// SyncRuns returns the recent and ongoing sync runs.
+func (r *Renter) SyncRuns() ([]modules.SyncRun, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.staticSyncManager.managedRuns(), nil
+}
+
+// threadedResumeSyncs resumes the sync runs which were interrupted by a
+// shutdown.
+func (r *Renter) threadedResumeSyncs() {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	for _, run := range r.staticSyncManager.managedRuns() {
+		if !run.Completed {
+			go r.threadedSync(run.ID)
+		}
+	}
+}
+
+// threadedSync takes the actions of a sync run.
+func (r *Renter) threadedSync(id string) {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	if err := r.managedExecuteSync(id); err != nil {
+		r.log.Printf("WARN: sync %v failed: %v", id, err)
+	}
+}
+
+// managedExecuteSync takes the actions of a sync run that weren't taken yet.
+// If the renter shuts down, the progress is persisted and the run is resumed
+// on the next startup.
+func (r *Renter) managedExecuteSync(id string) error {
+	run, exists := r.staticSyncManager.managedRun(id)
+	if !exists {
+		return errors.New("unknown sync run " + id)
+	}
+	var taken int
+	for i, action := range run.Actions {
+		if action.Done {
+			continue
+		}
+		select {
+		case <-r.tg.StopChan():
+			return r.staticSyncManager.managedSave()
+		default:
+		}
+		var err error
+		switch action.Type {
+		case modules.SyncActionUpload, modules.SyncActionUpdate:
+			// Uploads are forced in case the file was uploaded before the
+			// progress of the run was persisted.
+			err = r.Upload(modules.FileUploadParams{
+				Source:  action.LocalPath,
+				SiaPath: action.SiaPath,
+				Force:   true,
+			})
+		case modules.SyncActionDelete:
+			err = r.DeleteFile(action.SiaPath)
+			if errors.Contains(err, filesystem.ErrNotExist) {
+				err = nil
+			}
+		default:
+			err = fmt.Errorf("unknown action type '%v'", action.Type)
+		}
+		if err != nil {
+			r.log.Printf("WARN: sync %v failed to %v %v: %v", id, action.Type, action.SiaPath, err)
+		}
+		r.staticSyncManager.managedFinishAction(id, i, err)
+		taken++
+		if taken%syncPersistInterval == 0 {
+			if err := r.staticSyncManager.managedSave(); err != nil {
+				r.log.Printf("WARN: failed to persist progress of sync %v: %v", id, err)
+			}
+		}
+	}
+	return r.staticSyncManager.managedFinishRun(id)
+}
+
+// managedPlanSync compares a local directory against the files at a siapath
+// and returns the actions needed to make the siapath match the directory.
+func (r *Renter) managedPlanSync(params modules.SyncParams) ([]modules.SyncAction, error) {
+	if !filepath.IsAbs(params.LocalPath) {
+		return nil, errors.New("local path needs to be absolute")
+	}
+	info, err := os.Stat(params.LocalPath)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to stat local path")
+	}
+	if !info.IsDir() {
+		return nil, errors.New("local path needs to be a directory")
+	}
+	if err := params.ValidateGlobs(); err != nil {
+		return nil, err
+	}
+
+	// Collect the remote files by their path relative to the siapath.
+	var mu sync.Mutex
+	remote := make(map[string]modules.FileInfo)
+	exists, err := r.staticFileSystem.DirExists(params.SiaPath)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		flf := func(fi modules.FileInfo) {
+			mu.Lock()
+			defer mu.Unlock()
+			remote[syncRelPath(params.SiaPath, fi.SiaPath)] = fi
+		}
+		err = r.staticFileSystem.CachedList(params.SiaPath, true, flf, func(modules.DirectoryInfo) {})
+		if err != nil {
+			return nil, errors.AddContext(err, "unable to list remote files")
+		}
+	}
+
+	// Compare the local files against the remote ones.
+	var actions []modules.SyncAction
+	err = filepath.Walk(params.LocalPath, func(localPath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if localPath == params.LocalPath {
+			return nil
+		}
+		relPath, err := filepath.Rel(params.LocalPath, localPath)
+		if err != nil {
+			return err
+		}
+		relPath = filepath.ToSlash(relPath)
+		if info.IsDir() {
+			if params.Excludes(relPath) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.Mode().IsRegular() || !params.Matches(relPath) {
+			return nil
+		}
+		siaPath, err := params.SiaPath.Join(relPath)
+		if err != nil {
+			return errors.AddContext(err, "invalid siapath for "+localPath)
+		}
+		action := modules.SyncAction{
+			Type:      modules.SyncActionUpload,
+			LocalPath: localPath,
+			SiaPath:   siaPath,
+			Size:      uint64(info.Size()),
+		}
+		fi, exists := remote[relPath]
+		delete(remote, relPath)
+		if exists {
+			changed, err := syncFileChanged(localPath, info, fi)
+			if err != nil {
+				return err
+			}
+			if !changed {
+				return nil
+			}
+			action.Type = modules.SyncActionUpdate
+		}
+		actions = append(actions, action)
+		return nil
+	})
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to walk local directory")
+	}
+
+	// Delete the remote files which don't exist locally.
+	if params.Delete {
+		for relPath, fi := range remote {
+			if !params.Matches(relPath) {
+				continue
+			}
+			actions = append(actions, modules.SyncAction{
+				Type:    modules.SyncActionDelete,
+				SiaPath: fi.SiaPath,
+				Size:    fi.Filesize,
+			})
+		}
+	}
+	sort.Slice(actions, func(i, j int) bool {
+		return actions[i].SiaPath.String() < actions[j].SiaPath.String()
+	})
+	return actions, nil
+}
+
+// syncRelPath returns the slash separated path of a remote file relative to
+// the synced siapath.
+func syncRelPath(dir, siaPath modules.SiaPath) string {
+	if dir.IsRoot() {
+		return siaPath.Path
+	}
+	return strings.TrimPrefix(siaPath.Path, dir.Path+"/")
+}
+
+// syncFileChanged returns true if the local file differs from the remote file.
+// Files of different size always differ. Files which weren't modified after
+// the remote file was last modified are considered equal. Otherwise the
+// checksum of the local file is compared to the remote checksum, if there is
+// one.
+func syncFileChanged(localPath string, info os.FileInfo, fi modules.FileInfo) (_ bool, err error) {
+	if uint64(info.Size()) != fi.Filesize {
+		return true, nil
+	}
+	if !info.ModTime().After(fi.ModificationTime) {
+		return false, nil
+	}
+	if fi.Checksum == "" {
+		return true, nil
+	}
+	file, err := os.Open(localPath)
+	if err != nil {
+		return false, errors.AddContext(err, "unable to open local file")
+	}
+	defer func() {
+		err = errors.Compose(err, file.Close())
+	}()
+	cr, err := newChecksumReader(fi.ChecksumType, file)
+	if err != nil {
+		return false, err
+	}
+	if _, err := io.Copy(ioutil.Discard, cr); err != nil {
+		return false, errors.AddContext(err, "unable to read local file")
+	}
+	checksum := cr.Checksum()
+	return hex.EncodeToString(checksum[:]) != fi.Checksum, nil
+}
@@ -0,0 +1,270 @@
+package renter
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+)
+
+// TestSyncManager tests adding, finishing and persisting sync runs.
+func TestSyncManager(t *testing.T) {
+	dir := build.TempDir("renter", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	sm, err := newSyncManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	siaPath, err := modules.UserFolder.Join("sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	run := modules.SyncRun{
+		ID:      "run",
+		Params:  modules.SyncParams{LocalPath: "/tmp", SiaPath: siaPath},
+		Actions: []modules.SyncAction{{Type: modules.SyncActionUpload}, {Type: modules.SyncActionDelete}},
+	}
+	if err := sm.managedAdd(run); err != nil {
+		t.Fatal(err)
+	}
+
+	// Syncs of overlapping siapaths are rejected while the run is going on.
+	for _, sp := range []modules.SiaPath{siaPath, modules.UserFolder, modules.RootSiaPath()} {
+		overlapping := run
+		overlapping.ID = "overlapping"
+		overlapping.Params.SiaPath = sp
+		if err := sm.managedAdd(overlapping); !errors.Contains(err, errSyncInProgress) {
+			t.Fatal("expected errSyncInProgress for", sp, "but got", err)
+		}
+	}
+
+	// The progress of the run is persisted.
+	sm.managedFinishAction(run.ID, 0, nil)
+	sm.managedFinishAction(run.ID, 1, errors.New("failed"))
+	if err := sm.managedSave(); err != nil {
+		t.Fatal(err)
+	}
+	sm, err = newSyncManager(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded, exists := sm.managedRun(run.ID)
+	if !exists {
+		t.Fatal("run wasn't persisted")
+	}
+	if loaded.Completed || !loaded.Actions[0].Done || loaded.Actions[1].Error != "failed" {
+		t.Fatal("progress wasn't persisted", loaded)
+	}
+	if err := sm.managedFinishRun(run.ID); err != nil {
+		t.Fatal(err)
+	}
+	loaded, _ = sm.managedRun(run.ID)
+	if !loaded.Completed || loaded.Error == "" {
+		t.Fatal("run should be completed with an error", loaded)
+	}
+
+	// Only the most recent finished runs are kept.
+	for i := 0; i < 2*maxFinishedSyncRuns; i++ {
+		run.ID = string(rune('a' + i))
+		if err := sm.managedAdd(run); err != nil {
+			t.Fatal(err)
+		}
+		if err := sm.managedFinishRun(run.ID); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if runs := sm.managedRuns(); len(runs) != maxFinishedSyncRuns {
+		t.Fatalf("expected %v runs but got %v", maxFinishedSyncRuns, len(runs))
+	}
+}
+
+// TestSyncParamsMatches tests matching files against the globs of a sync.
+func TestSyncParamsMatches(t *testing.T) {
+	params := modules.SyncParams{
+		Include: []string{"*.txt", "docs/*"},
+		Exclude: []string{"tmp", "secret*"},
+	}
+	tests := []struct {
+		path  string
+		match bool
+	}{
+		{"a.txt", true},
+		{"dir/a.txt", true},
+		{"docs/a.pdf", true},
+		{"a.pdf", false},
+		{"secret.txt", false},
+		{"dir/secret.txt", false},
+		{"tmp", false},
+		{"tmp/a.txt", false},
+		{"dir/tmp/a.txt", false},
+	}
+	for _, test := range tests {
+		if params.Matches(test.path) != test.match {
+			t.Errorf("%v: expected match to be %v", test.path, test.match)
+		}
+	}
+	if !params.Excludes("dir/tmp") || params.Excludes("dir") {
+		t.Fatal("wrong excluded directories")
+	}
+	params.Exclude = append(params.Exclude, "[")
+	if err := params.ValidateGlobs(); err == nil {
+		t.Fatal("expected error for invalid glob")
+	}
+}
+
+// TestSync tests planning and running syncs of a local directory.
+func TestSync(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := rt.renter
+
+	// Create a local directory with some files.
+	localDir := filepath.Join(rt.dir, "sync")
+	writeFile := func(name string, data []byte) {
+		path := filepath.Join(localDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	data := fastrand.Bytes(100)
+	writeFile("a", data)
+	writeFile("dir/b", fastrand.Bytes(200))
+	writeFile("tmp/c", fastrand.Bytes(300))
+	siaPath, err := modules.UserFolder.Join("sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := modules.SyncParams{
+		LocalPath: localDir,
+		SiaPath:   siaPath,
+		Delete:    true,
+		Exclude:   []string{"tmp"},
+	}
+
+	// checkPlan checks the types of the planned actions.
+	checkPlan := func(expected ...modules.SyncActionType) {
+		t.Helper()
+		actions, err := r.SyncPlan(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(actions) != len(expected) {
+			t.Fatalf("expected %v actions but got %v", len(expected), actions)
+		}
+		for i, action := range actions {
+			if action.Type != expected[i] {
+				t.Fatalf("expected action %v to be %v but got %v", i, expected[i], action)
+			}
+		}
+	}
+	// sync runs a sync and waits for it to finish.
+	sync := func() {
+		t.Helper()
+		run, err := r.Sync(params)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = build.Retry(100, 100*time.Millisecond, func() error {
+			run, _ = r.staticSyncManager.managedRun(run.ID)
+			if !run.Completed {
+				return errors.New("sync not completed")
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.Error != "" {
+			t.Fatal(run.Error, run.Actions)
+		}
+	}
+
+	// The excluded directory isn't synced.
+	checkPlan(modules.SyncActionUpload, modules.SyncActionUpload)
+	sync()
+	checkPlan()
+
+	// Remote files within the excluded directory aren't deleted.
+	tmpSiaPath, err := siaPath.Join("tmp/d")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsc, _ := modules.NewRSCode(1, 1)
+	err = r.staticFileSystem.NewSiaFile(tmpSiaPath, "", rsc, crypto.GenerateSiaKey(crypto.TypeDefaultRenter), 100, persist.DefaultDiskPermissionsTest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkPlan()
+
+	// Touching a file with a checksum doesn't change it.
+	fileSiaPath, err := siaPath.Join("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		fi, err := r.File(fileSiaPath)
+		if err != nil {
+			return err
+		}
+		if fi.Checksum == "" {
+			return errors.New("checksum not computed yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(localDir, "a"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	checkPlan()
+
+	// Changing the content of the file does.
+	data[0]++
+	writeFile("a", data)
+	if err := os.Chtimes(filepath.Join(localDir, "a"), future, future); err != nil {
+		t.Fatal(err)
+	}
+	checkPlan(modules.SyncActionUpdate)
+
+	// Removed files are deleted.
+	if err := os.RemoveAll(filepath.Join(localDir, "dir")); err != nil {
+		t.Fatal(err)
+	}
+	checkPlan(modules.SyncActionUpdate, modules.SyncActionDelete)
+	sync()
+	checkPlan()
+	if _, err := r.File(fileSiaPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// Syncs of files need a directory.
+	params.LocalPath = filepath.Join(localDir, "a")
+	if _, err := r.SyncPlan(params); err == nil {
+		t.Fatal("expected error for syncing a file")
+	}
+}
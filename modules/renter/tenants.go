@@ -0,0 +1,103 @@
+package renter
+
+// tenants.go enforces the quotas of the renter's tenants. Uploads and
+// downloads of files within the namespace of a tenant are counted towards the
+// usage of the tenant and fail if they would exceed one of its quotas.
+
+import (
+	"io"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+)
+
+type (
+	// tenantUploadReader counts the data read from an upload stream towards
+	// the upload and storage quotas of a tenant.
+	tenantUploadReader struct {
+		r io.Reader
+
+		staticUpload *filesystem.TenantUpload
+	}
+
+	// tenantStreamer counts the data read from a streamer towards the
+	// download quota of a tenant.
+	tenantStreamer struct {
+		modules.Streamer
+
+		staticFileSystem *filesystem.FileSystem
+		staticSiaPath    modules.SiaPath
+	}
+)
+
+// Read implements io.Reader. The data is only returned if it doesn't exceed
+// the quotas of the tenant.
+func (tr *tenantUploadReader) Read(b []byte) (int, error) {
+	n, err := tr.r.Read(b)
+	if n == 0 {
+		return n, err
+	}
+	if err := tr.staticUpload.Reserve(uint64(n)); err != nil {
+		return 0, err
+	}
+	return n, err
+}
+
+// Read implements io.Reader. The data is only returned if it doesn't exceed
+// the download quota of the tenant.
+func (ts *tenantStreamer) Read(b []byte) (int, error) {
+	n, err := ts.Streamer.Read(b)
+	if n == 0 {
+		return n, err
+	}
+	if err := ts.staticFileSystem.RecordTenantDownload(ts.staticSiaPath, uint64(n)); err != nil {
+		return 0, err
+	}
+	return n, err
+}
+
+// SetTenant creates a tenant or updates the quotas of an existing one.
+func (r *Renter) SetTenant(tenant modules.Tenant) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return errors.AddContext(r.staticFileSystem.SetTenant(tenant), "unable to set tenant")
+}
+
+// RemoveTenant removes a tenant. The files within its namespace are kept.
+func (r *Renter) RemoveTenant(name string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.RemoveTenant(name)
+}
+
+// ResetTenantUsage resets the uploaded and downloaded bytes of a tenant.
+func (r *Renter) ResetTenantUsage(name string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.ResetTenantUsage(name)
+}
+
+// Tenant returns the tenant with the given name.
+func (r *Renter) Tenant(name string) (modules.TenantInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.TenantInfo{}, err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.Tenant(name)
+}
+
+// Tenants returns all tenants sorted by name.
+func (r *Renter) Tenants() ([]modules.TenantInfo, error) {
+	if err := r.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer r.tg.Done()
+	return r.staticFileSystem.Tenants()
+}
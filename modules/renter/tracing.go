@@ -0,0 +1,521 @@
+package renter
+
+// tracing.go contains a lightweight tracer for the renter. A trace records
+// where the time of an operation like a download went. It consists of spans
+// with parent IDs, e.g. a download has a span per chunk which in turn has a
+// span per read sector job of a worker. Spans are passed to the jobs using
+// their context. Jobs that are created without a span in their context aren't
+// traced.
+//
+// The recent traces are kept in memory and can be queried using the API. The
+// finished spans can also be exported in the OTLP JSON encoding to a file or
+// an OpenTelemetry collector.
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/modules"
+)
+
+var (
+	// errTraceNotFound is returned if a trace doesn't exist.
+	errTraceNotFound = errors.New("trace not found")
+
+	// errInvalidTraceExportDestination is returned if the export destination
+	// is neither an absolute path nor an http URL.
+	errInvalidTraceExportDestination = errors.New("trace export destination needs to be an absolute file path or an http(s) URL")
+)
+
+type (
+	// traceID is the ID of a trace. It has the size of an OpenTelemetry
+	// trace ID.
+	traceID [16]byte
+
+	// spanID is the ID of a span. It has the size of an OpenTelemetry span
+	// ID.
+	spanID [8]byte
+
+	// tracer keeps the recent traces of the renter.
+	tracer struct {
+		// traces contains the unfinished and finished traces. finished
+		// contains the IDs of the finished traces, oldest first.
+		traces    map[traceID]*trace
+		finished  []traceID
+		numActive int
+
+		// exportDestination is the file or URL the finished spans are
+		// exported to. exportQueue contains the spans waiting to be exported
+		// by trace.
+		exportDestination string
+		exportQueue       map[traceID][]modules.RenterTraceSpan
+		numExportQueued   int
+
+		mu sync.Mutex
+	}
+
+	// trace is a trace of a renter operation. All fields are protected by
+	// the mutex of the tracer.
+	trace struct {
+		staticID     traceID
+		staticRoot   *traceSpan
+		staticTracer *tracer
+
+		attributes map[string]string
+		end        time.Time
+		err        string
+		finished   bool
+		spans      []modules.RenterTraceSpan
+		dropped    int
+	}
+
+	// traceSpan is a timed operation within a trace. All methods of a span can
+	// be called on a nil span, which makes tracing optional for the callers.
+	traceSpan struct {
+		staticID       spanID
+		staticParentID spanID
+		staticName     string
+		staticStart    time.Time
+		staticTrace    *trace
+
+		attributes map[string]string
+		finished   bool
+		mu         sync.Mutex
+	}
+
+	// traceSpanKey is the context key of the span of an operation.
+	traceSpanKey struct{}
+)
+
+// String implements fmt.Stringer.
+func (id traceID) String() string {
+	return hex.EncodeToString(id[:])
+}
+
+// String returns the hex encoding of the span ID and an empty string for the
+// zero ID.
+func (id spanID) String() string {
+	if id == (spanID{}) {
+		return ""
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// newTracer creates a new tracer.
+func newTracer() *tracer {
+	return &tracer{
+		traces:      make(map[traceID]*trace),
+		exportQueue: make(map[traceID][]modules.RenterTraceSpan),
+	}
+}
+
+// spanFromContext returns the span of the context or nil.
+func spanFromContext(ctx context.Context) *traceSpan {
+	span, _ := ctx.Value(traceSpanKey{}).(*traceSpan)
+	return span
+}
+
+// childSpan starts a span that is a child of the span of the context. If the
+// context doesn't have a span, nil is returned.
+func childSpan(ctx context.Context, name string, keysAndValues ...interface{}) *traceSpan {
+	return spanFromContext(ctx).startChild(name, keysAndValues...)
+}
+
+// spanAttributes converts alternating keys and values to span attributes.
+func spanAttributes(attributes map[string]string, keysAndValues ...interface{}) map[string]string {
+	if len(keysAndValues) == 0 {
+		return attributes
+	}
+	if attributes == nil {
+		attributes = make(map[string]string, len(keysAndValues)/2)
+	}
+	for i := 0; i < len(keysAndValues); i += 2 {
+		value := "MISSING"
+		if i+1 < len(keysAndValues) {
+			value = fmt.Sprint(keysAndValues[i+1])
+		}
+		attributes[fmt.Sprint(keysAndValues[i])] = value
+	}
+	return attributes
+}
+
+// startTrace starts a new trace and returns its root span. If there are too
+// many unfinished traces, nil is returned.
+func (t *tracer) startTrace(name string, keysAndValues ...interface{}) *traceSpan {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.numActive >= maxActiveTraces {
+		return nil
+	}
+	tr := &trace{staticTracer: t}
+	fastrand.Read(tr.staticID[:])
+	root := &traceSpan{
+		staticName:  name,
+		staticStart: time.Now(),
+		staticTrace: tr,
+		attributes:  spanAttributes(nil, keysAndValues...),
+	}
+	fastrand.Read(root.staticID[:])
+	tr.staticRoot = root
+	t.traces[tr.staticID] = tr
+	t.numActive++
+	return root
+}
+
+// startSpan starts a span that is a child of the span of the context. If the
+// context doesn't have a span, a new trace is started. The returned context
+// contains the new span.
+func (t *tracer) startSpan(ctx context.Context, name string, keysAndValues ...interface{}) (*traceSpan, context.Context) {
+	span := spanFromContext(ctx).startChild(name, keysAndValues...)
+	if span == nil {
+		span = t.startTrace(name, keysAndValues...)
+	}
+	return span, span.context(ctx)
+}
+
+// startChild starts a child span of the span.
+func (s *traceSpan) startChild(name string, keysAndValues ...interface{}) *traceSpan {
+	if s == nil {
+		return nil
+	}
+	child := &traceSpan{
+		staticParentID: s.staticID,
+		staticName:     name,
+		staticStart:    time.Now(),
+		staticTrace:    s.staticTrace,
+		attributes:     spanAttributes(nil, keysAndValues...),
+	}
+	fastrand.Read(child.staticID[:])
+	return child
+}
+
+// traceID returns the ID of the trace of the span.
+func (s *traceSpan) traceID() string {
+	if s == nil {
+		return ""
+	}
+	return s.staticTrace.staticID.String()
+}
+
+// context returns a copy of the context that contains the span.
+func (s *traceSpan) context(ctx context.Context) context.Context {
+	if s == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, traceSpanKey{}, s)
+}
+
+// setAttributes adds attributes to the span. Attributes that are added after
+// the span was finished are ignored.
+func (s *traceSpan) setAttributes(keysAndValues ...interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.finished {
+		return
+	}
+	s.attributes = spanAttributes(s.attributes, keysAndValues...)
+}
+
+// finish finishes the span. If the span is the root span of its trace, the
+// trace is finished as well. Only the first call has an effect.
+func (s *traceSpan) finish(err error) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.finished {
+		s.mu.Unlock()
+		return
+	}
+	s.finished = true
+	span := modules.RenterTraceSpan{
+		ID:         s.staticID.String(),
+		ParentID:   s.staticParentID.String(),
+		Name:       s.staticName,
+		Start:      s.staticStart,
+		Duration:   time.Since(s.staticStart),
+		Attributes: s.attributes,
+	}
+	s.attributes = nil
+	if err != nil {
+		span.Error = err.Error()
+	}
+	s.mu.Unlock()
+
+	tr := s.staticTrace
+	tr.staticTracer.managedRecordSpan(tr, span, s == tr.staticRoot)
+}
+
+// managedRecordSpan records a finished span of a trace.
+func (t *tracer) managedRecordSpan(tr *trace, span modules.RenterTraceSpan, root bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(tr.spans) < maxSpansPerTrace {
+		tr.spans = append(tr.spans, span)
+		if t.exportDestination != "" && t.numExportQueued < maxPendingExportSpans {
+			t.exportQueue[tr.staticID] = append(t.exportQueue[tr.staticID], span)
+			t.numExportQueued++
+		}
+	} else {
+		tr.dropped++
+	}
+	if !root {
+		return
+	}
+
+	// Finish the trace and drop the oldest trace if necessary. Spans that
+	// finish after their root span are still recorded as long as the trace is
+	// kept.
+	tr.attributes = span.Attributes
+	tr.finished = true
+	tr.end = span.Start.Add(span.Duration)
+	tr.err = span.Error
+	t.numActive--
+	t.finished = append(t.finished, tr.staticID)
+	if len(t.finished) > maxFinishedTraces {
+		delete(t.traces, t.finished[0])
+		t.finished = t.finished[1:]
+	}
+}
+
+// info returns the information about the trace. The spans are only included
+// if withSpans is set. The tracer's mutex needs to be held.
+func (tr *trace) info(withSpans bool) modules.RenterTrace {
+	// The attributes of the root span are moved to the trace when the root
+	// span is finished.
+	root := tr.staticRoot
+	attributes := make(map[string]string)
+	if tr.finished {
+		for k, v := range tr.attributes {
+			attributes[k] = v
+		}
+	} else {
+		root.mu.Lock()
+		for k, v := range root.attributes {
+			attributes[k] = v
+		}
+		root.mu.Unlock()
+	}
+
+	info := modules.RenterTrace{
+		ID:           tr.staticID.String(),
+		Name:         root.staticName,
+		Start:        root.staticStart,
+		Finished:     tr.finished,
+		Error:        tr.err,
+		Attributes:   attributes,
+		NumSpans:     len(tr.spans),
+		DroppedSpans: tr.dropped,
+	}
+	if tr.finished {
+		info.Duration = tr.end.Sub(root.staticStart)
+	}
+	if withSpans {
+		info.Spans = append([]modules.RenterTraceSpan{}, tr.spans...)
+		sort.SliceStable(info.Spans, func(i, j int) bool {
+			return info.Spans[i].Start.Before(info.Spans[j].Start)
+		})
+	}
+	return info
+}
+
+// managedTrace returns a trace including its spans.
+func (t *tracer) managedTrace(id string) (modules.RenterTrace, error) {
+	var tid traceID
+	b, err := hex.DecodeString(id)
+	if err != nil || len(b) != len(tid) {
+		return modules.RenterTrace{}, errTraceNotFound
+	}
+	copy(tid[:], b)
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tr, exists := t.traces[tid]
+	if !exists {
+		return modules.RenterTrace{}, errTraceNotFound
+	}
+	return tr.info(true), nil
+}
+
+// managedTraces returns all traces without their spans, most recent first.
+func (t *tracer) managedTraces() []modules.RenterTrace {
+	t.mu.Lock()
+	traces := make([]modules.RenterTrace, 0, len(t.traces))
+	for _, tr := range t.traces {
+		traces = append(traces, tr.info(false))
+	}
+	t.mu.Unlock()
+	sort.Slice(traces, func(i, j int) bool {
+		return traces[i].Start.After(traces[j].Start)
+	})
+	return traces
+}
+
+// managedSetExportDestination sets the export destination. Queued spans are
+// dropped if the export is disabled.
+func (t *tracer) managedSetExportDestination(destination string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.exportDestination = destination
+	if destination == "" {
+		t.exportQueue = make(map[traceID][]modules.RenterTraceSpan)
+		t.numExportQueued = 0
+	}
+}
+
+// managedPopExportQueue returns the queued spans and the destination they
+// need to be exported to.
+func (t *tracer) managedPopExportQueue() (string, []modules.RenterTrace) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.numExportQueued == 0 {
+		return t.exportDestination, nil
+	}
+	traces := make([]modules.RenterTrace, 0, len(t.exportQueue))
+	for id, spans := range t.exportQueue {
+		traces = append(traces, modules.RenterTrace{ID: id.String(), Spans: spans})
+	}
+	t.exportQueue = make(map[traceID][]modules.RenterTraceSpan)
+	t.numExportQueued = 0
+	return t.exportDestination, traces
+}
+
+// validateTraceExportDestination checks that the destination is either an
+// absolute file path or an http(s) URL.
+func validateTraceExportDestination(destination string) error {
+	if destination == "" {
+		return nil
+	}
+	if strings.HasPrefix(destination, "http://") || strings.HasPrefix(destination, "https://") {
+		u, err := url.Parse(destination)
+		if err != nil || u.Host == "" {
+			return errInvalidTraceExportDestination
+		}
+		return nil
+	}
+	if !filepath.IsAbs(destination) {
+		return errInvalidTraceExportDestination
+	}
+	return nil
+}
+
+// exportTraces writes the spans of the traces to the destination in the OTLP
+// JSON encoding. Files receive one export request per line, URLs receive the
+// export request in a POST request.
+func exportTraces(ctx context.Context, destination string, traces []modules.RenterTrace) error {
+	b, err := json.Marshal(modules.OTLP(traces))
+	if err != nil {
+		return err
+	}
+	if !strings.HasPrefix(destination, "http://") && !strings.HasPrefix(destination, "https://") {
+		f, err := os.OpenFile(destination, os.O_WRONLY|os.O_CREATE|os.O_APPEND, modules.DefaultFilePerm)
+		if err != nil {
+			return errors.AddContext(err, "failed to open trace export file")
+		}
+		_, err = f.Write(append(b, '\n'))
+		return errors.Compose(err, f.Close())
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, destination, bytes.NewReader(b))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return errors.AddContext(err, "failed to send traces to collector")
+	}
+	if err := resp.Body.Close(); err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("collector responded with status %v", resp.Status)
+	}
+	return nil
+}
+
+// threadedExportTraces periodically exports the finished spans.
+func (r *Renter) threadedExportTraces() {
+	if err := r.tg.Add(); err != nil {
+		return
+	}
+	defer r.tg.Done()
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(traceExportInterval):
+		}
+		destination, traces := r.staticTracer.managedPopExportQueue()
+		if len(traces) == 0 {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(r.tg.StopCtx(), traceExportTimeout)
+		err := exportTraces(ctx, destination, traces)
+		cancel()
+		if err != nil {
+			r.log.Println("WARN: failed to export traces:", err)
+		}
+	}
+}
+
+// Trace returns a recent trace of a renter operation including its spans.
+func (r *Renter) Trace(id string) (modules.RenterTrace, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.RenterTrace{}, err
+	}
+	defer r.tg.Done()
+	return r.staticTracer.managedTrace(id)
+}
+
+// Traces returns the recent traces of renter operations without their spans,
+// most recent first.
+func (r *Renter) Traces() []modules.RenterTrace {
+	return r.staticTracer.managedTraces()
+}
+
+// TraceExportDestination returns the file or OTLP collector URL the finished
+// spans are exported to.
+func (r *Renter) TraceExportDestination() string {
+	id := r.mu.RLock()
+	defer r.mu.RUnlock(id)
+	return r.persist.TraceExportDestination
+}
+
+// SetTraceExportDestination sets the file or OTLP collector URL the finished
+// spans are exported to. An empty destination disables the export.
+func (r *Renter) SetTraceExportDestination(destination string) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	if err := validateTraceExportDestination(destination); err != nil {
+		return err
+	}
+	id := r.mu.Lock()
+	defer r.mu.Unlock(id)
+	r.persist.TraceExportDestination = destination
+	if err := r.saveSync(); err != nil {
+		return errors.AddContext(err, "unable to persist the trace export destination")
+	}
+	r.staticTracer.managedSetExportDestination(destination)
+	return nil
+}
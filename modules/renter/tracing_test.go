@@ -0,0 +1,170 @@
+package renter
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+)
+
+// TestTracer tests that spans are recorded with their parents and that the
+// traces are listed and evicted correctly.
+func TestTracer(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	tr := newTracer()
+
+	// Trace a download with a chunk and a job that is started from the context
+	// of the chunk.
+	root := tr.startTrace("download", "siapath", "foo")
+	chunk := root.startChild("download.chunk", "chunkindex", 0)
+	job := childSpan(chunk.context(context.Background()), "worker.readSector", "hostkey", "ed25519:abcd")
+	job.finish(errors.New("read failed"))
+	chunk.finish(nil)
+	chunk.finish(errors.New("ignored"))
+	root.setAttributes("received", 10)
+
+	// The unfinished trace is listed.
+	traces := tr.managedTraces()
+	if len(traces) != 1 || traces[0].Finished || traces[0].NumSpans != 2 || traces[0].Attributes["received"] != "10" {
+		t.Fatal("unexpected traces", traces)
+	}
+	root.finish(nil)
+	root.setAttributes("ignored", true)
+
+	trace, err := tr.managedTrace(root.traceID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trace.Finished || trace.Name != "download" || trace.Attributes["siapath"] != "foo" || trace.Attributes["ignored"] != "" {
+		t.Fatal("unexpected trace", trace)
+	}
+	if len(trace.Spans) != 3 {
+		t.Fatal("unexpected number of spans", len(trace.Spans))
+	}
+	spans := make(map[string]modules.RenterTraceSpan)
+	for _, s := range trace.Spans {
+		spans[s.Name] = s
+	}
+	if spans["download"].ParentID != "" || spans["download.chunk"].ParentID != spans["download"].ID || spans["worker.readSector"].ParentID != spans["download.chunk"].ID {
+		t.Fatal("spans have wrong parents", trace.Spans)
+	}
+	if spans["worker.readSector"].Error != "read failed" || spans["download.chunk"].Error != "" {
+		t.Fatal("spans have wrong errors", trace.Spans)
+	}
+	if spans["worker.readSector"].Attributes["hostkey"] != "ed25519:abcd" {
+		t.Fatal("span has wrong attributes", spans["worker.readSector"])
+	}
+
+	// Jobs without a span in their context aren't traced and nil spans can be
+	// used like regular spans.
+	if span := childSpan(context.Background(), "worker.readSector"); span != nil {
+		t.Fatal("job without parent was traced")
+	}
+	var nilSpan *traceSpan
+	nilSpan.setAttributes("foo", "bar")
+	nilSpan.finish(nil)
+	if nilSpan.context(context.Background()) != context.Background() {
+		t.Fatal("nil span changed the context")
+	}
+
+	// Unknown traces aren't found.
+	if _, err := tr.managedTrace("abcd"); !errors.Contains(err, errTraceNotFound) {
+		t.Fatal("expected errTraceNotFound, got", err)
+	}
+
+	// The oldest finished traces are evicted.
+	for i := 0; i < maxFinishedTraces; i++ {
+		tr.startTrace("worker.updatePriceTable").finish(nil)
+	}
+	if _, err := tr.managedTrace(root.traceID()); !errors.Contains(err, errTraceNotFound) {
+		t.Fatal("oldest trace wasn't evicted", err)
+	}
+	if n := len(tr.managedTraces()); n != maxFinishedTraces {
+		t.Fatal("unexpected number of traces", n)
+	}
+}
+
+// TestTraceExport tests exporting the finished spans to a file.
+func TestTraceExport(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// The destination needs to be an absolute path or an http URL.
+	for _, destination := range []string{"traces.json", "ftp://localhost/traces", "http://"} {
+		if err := validateTraceExportDestination(destination); !errors.Contains(err, errInvalidTraceExportDestination) {
+			t.Fatal("invalid destination was accepted", destination, err)
+		}
+	}
+	dir := build.TempDir("renter", t.Name())
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(dir, modules.DefaultDirPerm); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "traces.json")
+	for _, destination := range []string{"", path, "http://localhost:4318/v1/traces"} {
+		if err := validateTraceExportDestination(destination); err != nil {
+			t.Fatal("valid destination was rejected", destination, err)
+		}
+	}
+
+	// Spans are only queued while a destination is set.
+	tr := newTracer()
+	tr.startTrace("download").finish(nil)
+	if _, traces := tr.managedPopExportQueue(); len(traces) != 0 {
+		t.Fatal("spans were queued without a destination")
+	}
+	tr.managedSetExportDestination(path)
+	root := tr.startTrace("download")
+	root.startChild("download.chunk").finish(nil)
+	root.finish(nil)
+	destination, traces := tr.managedPopExportQueue()
+	if destination != path || len(traces) != 1 || len(traces[0].Spans) != 2 {
+		t.Fatal("unexpected export queue", destination, traces)
+	}
+	if _, traces := tr.managedPopExportQueue(); len(traces) != 0 {
+		t.Fatal("queue wasn't emptied", traces)
+	}
+
+	// Export the spans twice to check that the file is appended to.
+	for i := 0; i < 2; i++ {
+		if err := exportTraces(context.Background(), path, traces); err != nil {
+			t.Fatal(err)
+		}
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines int
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines++
+		var otlp modules.OTLPTraces
+		if err := json.Unmarshal(s.Bytes(), &otlp); err != nil {
+			t.Fatal(err)
+		}
+		spans := otlp.ResourceSpans[0].ScopeSpans[0].Spans
+		if len(spans) != 2 || spans[0].TraceID != root.traceID() {
+			t.Fatal("unexpected spans", spans)
+		}
+	}
+	if lines != 2 {
+		t.Fatal("unexpected number of lines", lines)
+	}
+}
@@ -27,7 +27,7 @@ var (
 
 // Upload instructs the renter to start tracking a file. The renter will
 // automatically upload and repair tracked files using a background loop.
-func (r *Renter) Upload(up modules.FileUploadParams) error {
+func (r *Renter) Upload(up modules.FileUploadParams) (err error) {
 	if err := r.tg.Add(); err != nil {
 		return err
 	}
@@ -52,6 +52,43 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 		return errors.AddContext(err, "unable to close file after checking permissions")
 	}
 
+	// Check the checksum type.
+	checksumType, err := modules.NewChecksumType(string(up.Checksum))
+	if err != nil {
+		return err
+	}
+
+	// Reserve the quotas of the file's tenant before the existing file is
+	// replaced. The reservation is refunded if the upload fails to start.
+	tu, err := r.staticFileSystem.BeginTenantUpload(up.SiaPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// Compressed uploads take over the reservation once they were
+		// started.
+		if tu == nil {
+			return
+		}
+		if err != nil {
+			err = errors.Compose(err, tu.Refund())
+		}
+		err = errors.Compose(err, tu.Close())
+	}()
+	if err := tu.Reserve(uint64(sourceInfo.Size())); err != nil {
+		return err
+	}
+
+	// Compressed files are uploaded by streaming the compressed data since
+	// the repair loop can't compress the file on the fly.
+	if up.Compression != modules.CompressionNone {
+		err = r.managedUploadCompressed(up, tu)
+		if err == nil {
+			tu = nil
+		}
+		return err
+	}
+
 	// Delete existing file if overwrite flag is set. Ignore ErrUnknownPath.
 	if up.Force {
 		err := r.DeleteFile(up.SiaPath)
@@ -60,7 +97,19 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 		}
 	}
 
-	// Fill in any missing upload params with sensible defaults.
+	// Fill in any missing upload params from the storage policy of the
+	// directory and then with sensible defaults.
+	dirSiaPath, err := up.SiaPath.Dir()
+	if err != nil {
+		return err
+	}
+	policy, err := r.staticFileSystem.StoragePolicy(dirSiaPath)
+	if err != nil {
+		return errors.AddContext(err, "unable to get storage policy")
+	}
+	if err := applyStoragePolicy(&up, policy); err != nil {
+		return err
+	}
 	if up.ErasureCode == nil {
 		up.ErasureCode = modules.NewRSSubCodeDefault()
 	}
@@ -75,13 +124,6 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 		return fmt.Errorf("not enough contracts to upload file: got %v, needed %v", numContracts, (up.ErasureCode.NumPieces()+up.ErasureCode.MinPieces())/2)
 	}
 
-	// Create the directory path on disk. Renter directory is already present so
-	// only files not in top level directory need to have directories created
-	dirSiaPath, err := up.SiaPath.Dir()
-	if err != nil {
-		return err
-	}
-
 	// Determine what type of encryption key to use. If no cipher type has been
 	// set, the default renter type will be used.
 	var ct crypto.CipherType
@@ -92,7 +134,9 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 	cipherKey := crypto.GenerateSiaKey(up.CipherType)
 
 	// Create the Siafile and add to renter
+	r.staticReencodes.swapMu.RLock()
 	err = r.staticFileSystem.NewSiaFile(up.SiaPath, up.Source, up.ErasureCode, cipherKey, uint64(sourceInfo.Size()), sourceInfo.Mode(), up.DisablePartialChunk)
+	r.staticReencodes.swapMu.RUnlock()
 	if err != nil {
 		return errors.AddContext(err, "could not create a new sia file")
 	}
@@ -103,7 +147,10 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 
 	// No need to upload zero-byte files.
 	if sourceInfo.Size() == 0 {
-		return nil
+		if checksumType != modules.ChecksumNone {
+			err = r.managedUploadWithChecksum(entry, up.Source, nil, checksumType)
+		}
+		return errors.Compose(err, entry.Close())
 	}
 
 	// Bubble the health of the SiaFile directory to ensure the health is
@@ -119,8 +166,19 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 	// having the worst possible health which is accurate since the file hasn't
 	// been uploaded yet
 	nilMap := make(map[string]bool)
-	// Send the upload to the repair loop.
 	hosts := r.managedRefreshHostsAndWorkers()
+
+	// Files with a checksum are read once in the background to compute the
+	// checksum from the same data that is uploaded.
+	if checksumType != modules.ChecksumNone {
+		if err := r.tg.Add(); err != nil {
+			return errors.Compose(err, entry.Close())
+		}
+		go r.threadedUploadWithChecksum(entry, up.Source, hosts, checksumType)
+		return nil
+	}
+
+	// Send the upload to the repair loop.
 	r.callBuildAndPushChunks([]*filesystem.FileNode{entry}, hosts, targetUnstuckChunks, nilMap, nilMap)
 	select {
 	case r.uploadHeap.newUploads <- struct{}{}:
@@ -128,3 +186,59 @@ func (r *Renter) Upload(up modules.FileUploadParams) error {
 	}
 	return nil
 }
+
+// managedUploadCompressed creates the file of a compressed upload and starts
+// streaming the compressed data of the source file to the network in the
+// background, just like regular uploads are handed to the repair loop. The
+// upload takes over the reservation of the tenant's quotas in tu if it was
+// started.
+func (r *Renter) managedUploadCompressed(up modules.FileUploadParams, tu *filesystem.TenantUpload) error {
+	file, err := os.Open(up.Source)
+	if err != nil {
+		return errors.AddContext(err, "unable to open the source file")
+	}
+	fileNode, err := r.managedInitUploadStream(up)
+	if err != nil {
+		return errors.Compose(err, file.Close())
+	}
+	if err := r.tg.Add(); err != nil {
+		err = errors.Compose(err, fileNode.Close(), file.Close())
+		return errors.Compose(err, r.managedDeleteFailedUpload(up.SiaPath))
+	}
+	r.staticAlerter.UnregisterAlert(modules.AlertIDSiafileUploadFailed(up.SiaPath.String()))
+	go r.threadedUploadCompressed(up, fileNode, file, tu)
+	return nil
+}
+
+// threadedUploadCompressed streams the compressed data of the source file to
+// the file created by managedUploadCompressed. The file doesn't track the
+// source since the uploaded data doesn't match the data on disk, which means
+// that a partial upload can't be repaired. That's why the file is deleted and
+// the reservation is refunded if the upload fails. The failure is reported
+// with an alert.
+func (r *Renter) threadedUploadCompressed(up modules.FileUploadParams, fileNode *filesystem.FileNode, file *os.File, tu *filesystem.TenantUpload) {
+	defer r.tg.Done()
+	fileNode, err := r.callUploadStreamToNode(up, fileNode, file, tu)
+	if err == nil {
+		err = fileNode.Close()
+	}
+	err = errors.Compose(err, file.Close())
+	if err != nil {
+		err = errors.Compose(err, r.managedDeleteFailedUpload(up.SiaPath), tu.Refund())
+		r.log.WithFields("siapath", up.SiaPath).Printf("WARN: failed to upload compressed file %v: %v", up.SiaPath, err)
+		r.staticAlerter.RegisterAlert(modules.AlertIDSiafileUploadFailed(up.SiaPath.String()), AlertMSGSiafileUploadFailed,
+			AlertCauseSiafileUploadFailed(up.SiaPath, err), modules.SeverityError)
+	}
+	if err := tu.Close(); err != nil {
+		r.log.WithFields("siapath", up.SiaPath).Println("WARN: failed to close the tenant upload:", err)
+	}
+}
+
+// managedDeleteFailedUpload deletes the file of a failed upload.
+func (r *Renter) managedDeleteFailedUpload(siaPath modules.SiaPath) error {
+	err := r.DeleteFile(siaPath)
+	if err != nil && !errors.Contains(err, filesystem.ErrNotExist) {
+		return errors.AddContext(err, "unable to delete the file of the failed upload")
+	}
+	return nil
+}
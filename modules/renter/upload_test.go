@@ -3,10 +3,16 @@ package renter
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/renter/filesystem"
+	"go.sia.tech/siad/persist"
 )
 
 // TestRenterUploadDirectory verifies that the renter returns an error if a
@@ -48,3 +54,134 @@ func TestRenterUploadDirectory(t *testing.T) {
 		t.Fatal("expected ErrUploadDirectory, got", err)
 	}
 }
+
+// TestRenterUploadUnknownCompression verifies that an upload with an unknown
+// compression is rejected before an existing file is overwritten.
+func TestRenterUploadUnknownCompression(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	entry, err := rt.renter.newRenterTestFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	siaPath := rt.renter.staticFileSystem.FileSiaPath(entry)
+	if err := entry.Close(); err != nil {
+		t.Fatal(err)
+	}
+	source, err := rt.createZeroByteFileOnDisk()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	params := modules.FileUploadParams{
+		Source:      source,
+		SiaPath:     siaPath,
+		ErasureCode: modules.NewRSCodeDefault(),
+		Force:       true,
+		Compression: modules.CompressionType("zip"),
+	}
+	err = rt.renter.Upload(params)
+	if !errors.Contains(err, modules.ErrUnknownCompression) {
+		t.Fatal("expected ErrUnknownCompression, got", err)
+	}
+	if _, err := rt.renter.File(siaPath); err != nil {
+		t.Fatal("existing file shouldn't have been deleted", err)
+	}
+}
+
+// TestRenterUploadCompressedFailure verifies that a failed compressed upload
+// is reported to the caller, doesn't leave a partial file behind and refunds
+// the quotas of the file's tenant.
+func TestRenterUploadCompressedFailure(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := rt.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tenant := modules.Tenant{
+		Name:         "team",
+		StorageQuota: 1000,
+		UploadQuota:  1000,
+	}
+	if err := rt.renter.SetTenant(tenant); err != nil {
+		t.Fatal(err)
+	}
+	root, err := tenant.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	siaPath, err := root.Join("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	source := filepath.Join(rt.renter.staticFileSystem.Root(), persist.RandomSuffix())
+	if err := ioutil.WriteFile(source, fastrand.Bytes(500), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// The upload is started in the background but fails since the renter
+	// doesn't have any workers.
+	params := modules.FileUploadParams{
+		Source:      source,
+		SiaPath:     siaPath,
+		ErasureCode: modules.NewRSCodeDefault(),
+		Compression: modules.CompressionGzip,
+	}
+	if err := rt.renter.Upload(params); err != nil {
+		t.Fatal(err)
+	}
+	alertID := modules.AlertIDSiafileUploadFailed(siaPath.String())
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		_, errs, _, _ := rt.renter.Alerts()
+		for _, a := range errs {
+			if a.ID == alertID {
+				return nil
+			}
+		}
+		return errors.New("failed upload wasn't reported")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rt.renter.File(siaPath); !errors.Contains(err, filesystem.ErrNotExist) {
+		t.Fatal("partial file shouldn't be kept", err)
+	}
+	ti, err := rt.renter.Tenant(tenant.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ti.Usage.Storage != 0 || ti.Usage.Uploaded != 0 {
+		t.Fatal("quotas of the tenant weren't refunded", ti.Usage)
+	}
+
+	// Uploads exceeding the quotas of the tenant are rejected before any
+	// file is created.
+	if err := ioutil.WriteFile(source, fastrand.Bytes(1500), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.renter.Upload(params); !errors.Contains(err, filesystem.ErrStorageQuotaExceeded) {
+		t.Fatal("expected ErrStorageQuotaExceeded, got", err)
+	}
+	if _, err := rt.renter.File(siaPath); !errors.Contains(err, filesystem.ErrNotExist) {
+		t.Fatal("file shouldn't be created", err)
+	}
+}
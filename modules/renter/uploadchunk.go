@@ -49,6 +49,10 @@ type unfinishedUploadChunk struct {
 	staticSiaPath  string
 	staticPriority bool // indicates if the chunk should get access to priority memory
 
+	// staticRepairPriority is the repair priority of the storage policy that
+	// applies to the chunk's file.
+	staticRepairPriority int
+
 	// The logical data is the data that is presented to the user when the user
 	// requests the chunk. The physical data is all of the pieces that get
 	// stored across the network.
@@ -114,6 +118,7 @@ type unfinishedUploadChunk struct {
 	piecesCompleted  int                 // number of pieces that have been fully uploaded.
 	piecesRegistered int                 // number of pieces that are being uploaded, but aren't finished yet (may fail).
 	released         bool                // whether this chunk has been released from the active chunks set.
+	span             *traceSpan          // the span of the repair, finished when the chunk is released.
 	unusedHosts      map[string]struct{} // hosts that aren't yet storing any pieces or performing any work.
 	workersRemaining int                 // number of inactive workers still able to upload a piece.
 	workersStandby   []*worker           // workers that can be used if other workers fail.
@@ -123,6 +128,13 @@ type unfinishedUploadChunk struct {
 	cancelWG sync.WaitGroup // WaitGroup to wait on after canceling the uploadchunk.
 }
 
+// managedSpan returns the span of the repair of the chunk.
+func (uc *unfinishedUploadChunk) managedSpan() *traceSpan {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+	return uc.span
+}
+
 // managedSetStuckAndClose sets the unfinishedUploadChunk's stuck status and
 // closes the fileEntry.
 func (uc *unfinishedUploadChunk) managedSetStuckAndClose(setStuck bool) error {
@@ -298,6 +310,7 @@ func (r *Renter) managedDownloadLogicalChunkData(chunk *unfinishedUploadChunk) e
 		priority:      0, // Repair downloads are completely de-prioritized.
 
 		staticMemoryManager:    chunk.staticMemoryManager, // Same memory manager as upload chunk
+		staticParentSpan:       chunk.managedSpan(),
 		staticSpendingCategory: categoryRepairDownload,
 	})
 	if err != nil {
@@ -375,8 +388,16 @@ func (r *Renter) threadedFetchAndRepairChunk(chunk *unfinishedUploadChunk) {
 		}
 	}
 
+	// Trace the repair of the chunk.
+	span := r.staticTracer.startTrace("upload.chunk", "siapath", chunk.staticSiaPath, "chunkindex", chunk.staticIndex, "stuckrepair", chunk.stuckRepair)
+	chunk.mu.Lock()
+	chunk.span = span
+	chunk.mu.Unlock()
+
 	// Fetch the logical data for the chunk.
+	fetchSpan := span.startChild("upload.fetchLogicalData")
 	err = r.managedFetchLogicalChunkData(chunk)
+	fetchSpan.finish(err)
 	if err != nil {
 		// Return the erasure coding memory. This is not handled by the cleanup
 		// code.
@@ -565,7 +586,7 @@ func (r *Renter) managedFetchLogicalChunkData(uc *unfinishedUploadChunk) error {
 			// NOTE: we are removing the localpath here to avoid potential
 			// future corruption by a different file with the same filename
 			// being added at the localpath location.
-			r.log.Println("WARN: local file not found on disk, setting localpath to '' to avoid corruption for", uc.fileEntry.SiaFilePath())
+			r.log.WithFields("siapath", uc.staticSiaPath).Println("WARN: local file not found on disk, setting localpath to '' to avoid corruption for", uc.fileEntry.SiaFilePath())
 			err = errors.Compose(err, uc.fileEntry.SetLocalPath(""))
 		}
 		if err != nil {
@@ -587,7 +608,7 @@ func (r *Renter) managedFetchLogicalChunkData(uc *unfinishedUploadChunk) error {
 		return nil
 	}()
 	if err != nil {
-		r.log.Printf("falling back to remote download for repair: fetch from local file %v failed: %v", uc.fileEntry.LocalPath(), err)
+		r.log.WithFields("siapath", uc.staticSiaPath).Printf("falling back to remote download for repair: fetch from local file %v failed: %v", uc.fileEntry.LocalPath(), err)
 		return r.managedDownloadLogicalChunkData(uc)
 	}
 	return nil
@@ -654,6 +675,7 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 			close(uc.staticAvailableChan)
 		}
 		uc.released = true
+		uc.span.finish(uc.err)
 
 		// Create a log message with all of the timings of the chunk uploading.
 		failedTimes := make([]int, 0, len(uc.chunkFailedProcessTimes))
@@ -695,14 +717,14 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 		offlineMap, goodForRenewMap, contracts, used := r.callRenterContractsAndUtilities()
 		err := r.managedUpdateFileMetadata(uc.fileEntry, offlineMap, goodForRenewMap, contracts, used)
 		if err != nil {
-			r.log.Print("managedCleanUpUploadChunk: failed to update file metadata", err)
+			r.log.WithFields("siapath", uc.staticSiaPath).Print("managedCleanUpUploadChunk: failed to update file metadata", err)
 		}
 
 		// Close the file entry for the completed chunk unless disrupted.
 		if !r.deps.Disrupt("disableCloseUploadEntry") {
 			err := uc.fileEntry.Close()
 			if err != nil {
-				r.log.Println("WARN: unable to close file entry for chunk", uc.fileEntry.SiaFilePath())
+				r.log.WithFields("siapath", uc.staticSiaPath).Println("WARN: unable to close file entry for chunk", uc.fileEntry.SiaFilePath())
 			}
 		}
 		// Remove the chunk from the repairingChunks map
@@ -719,7 +741,7 @@ func (r *Renter) managedCleanUpUploadChunk(uc *unfinishedUploadChunk) {
 	if canceled && workersRemaining == 0 && !chunkComplete {
 		err := uc.fileEntry.Close()
 		if err != nil {
-			r.log.Println("WARN: unable to close file entry for chunk", uc.fileEntry.SiaFilePath())
+			r.log.WithFields("siapath", uc.staticSiaPath).Println("WARN: unable to close file entry for chunk", uc.fileEntry.SiaFilePath())
 		}
 	}
 	// Sanity check - all memory should be released if the chunk is complete.
@@ -753,6 +775,7 @@ func (r *Renter) managedUpdateUploadChunkStuckStatus(uc *unfinishedUploadChunk)
 	piecesNeeded := uc.staticPiecesNeeded
 	stuckRepair := uc.stuckRepair
 	uc.mu.Unlock()
+	log := r.log.WithFields("siapath", uc.staticSiaPath)
 
 	// Determine if repair was successful.
 	health := siafile.CalculateHealth(piecesCompleted, minimumPieces, piecesNeeded)
@@ -772,27 +795,27 @@ func (r *Renter) managedUpdateUploadChunkStuckStatus(uc *unfinishedUploadChunk)
 
 	// If the repair was unsuccessful and there was a renter error then return
 	if !successfulRepair && renterError {
-		r.log.Debugln("WARN: repair unsuccessful for chunk", uc.id, "due to an error with the renter")
+		log.Debugln("WARN: repair unsuccessful for chunk", uc.id, "due to an error with the renter")
 		return
 	}
 	// Log if the repair was unsuccessful
 	if !successfulRepair {
-		r.log.Debugln("WARN: repair unsuccessful, marking chunk", uc.id, "as stuck", float64(piecesCompleted)/float64(piecesNeeded))
+		log.Debugln("WARN: repair unsuccessful, marking chunk", uc.id, "as stuck", float64(piecesCompleted)/float64(piecesNeeded))
 	} else {
-		r.log.Debugln("SUCCESS: repair successful, marking chunk as non-stuck:", uc.id)
+		log.Debugln("SUCCESS: repair successful, marking chunk as non-stuck:", uc.id)
 	}
 	// Update chunk stuck status unless the dependency to skip this step is
 	// enabled.
 	if !r.deps.Disrupt("DontUpdateChunkStatus") {
 		if err := uc.fileEntry.SetStuck(index, !successfulRepair); err != nil {
-			r.log.Printf("WARN: could not set chunk %v stuck status for file %v: %v", uc.id, uc.fileEntry.SiaFilePath(), err)
+			log.Printf("WARN: could not set chunk %v stuck status for file %v: %v", uc.id, uc.fileEntry.SiaFilePath(), err)
 		}
 	}
 
 	// Check to see if the chunk was stuck and now is successfully repaired by
 	// the stuck loop
 	if stuck && successfulRepair && stuckRepair {
-		r.log.Debugln("Stuck chunk", uc.id, "successfully repaired")
+		log.Debugln("Stuck chunk", uc.id, "successfully repaired")
 		// Add file to the successful stuck repair stack if there are still
 		// stuck chunks to repair
 		if uc.fileEntry.NumStuckChunks() > 0 {
@@ -801,7 +824,7 @@ func (r *Renter) managedUpdateUploadChunkStuckStatus(uc *unfinishedUploadChunk)
 		// Signal the stuck loop that the chunk was successfully repaired
 		select {
 		case <-r.tg.StopChan():
-			r.log.Debugln("WARN: renter shut down before the stuck loop was signalled that the stuck repair was successful")
+			log.Debugln("WARN: renter shut down before the stuck loop was signalled that the stuck repair was successful")
 			return
 		case r.uploadHeap.stuckChunkSuccess <- struct{}{}:
 		default:
@@ -78,19 +78,24 @@ func (uch uploadChunkHeap) Less(i, j int) bool {
 	//      than all other chunks. An example would be if the upload of a single
 	//      chunk is a blocking task.
 	//
-	//  2) File Recently Successful Chunks
+	//  2) Storage Policy Priority
+	//    - Chunks governed by a storage policy with a higher repair priority
+	//      are repaired first
+	//
+	//  3) File Recently Successful Chunks
 	//    - These are stuck chunks that are from a file that recently had a
 	//      successful repair
 	//
-	//  3) Stuck Chunks
+	//  4) Stuck Chunks
 	//    - These are chunks added by the stuck loop
 	//
-	//  4) Remote Chunks
+	//  5) Remote Chunks
 	//    - These are chunks of a siafile that do not have a local file to repair
 	//    from
 	//
-	//  5) Worst Health Chunk
-	//    - The base priority of chunks in the heap is by the worst health
+	//  6) Worst Health Chunk
+	//    - The base priority of chunks in the heap is by the worst health. The
+	//      health is scaled by the repair threshold of the storage policy
 
 	// Check for Priority chunks
 	//
@@ -103,6 +108,11 @@ func (uch uploadChunkHeap) Less(i, j int) bool {
 		return false
 	}
 
+	// Check for the repair priority of the storage policies
+	if uch[i].staticRepairPriority != uch[j].staticRepairPriority {
+		return uch[i].staticRepairPriority > uch[j].staticRepairPriority
+	}
+
 	// Check for File Recently Successful Chunks
 	//
 	// If only chunk i's file was recently successful, return true to prioritize
@@ -677,6 +687,14 @@ func (r *Renter) managedBuildUnfinishedChunks(entry *filesystem.FileNode, hosts
 		pks[string(pk.Key)] = pk
 	}
 
+	// Apply the storage policy of the file. Pieces on hosts that aren't
+	// allowed by the policy don't count towards the redundancy of the chunks.
+	policy, err := r.managedFileStoragePolicy(entry)
+	if err != nil {
+		r.log.Println("WARN: unable to get storage policy, using the defaults:", err)
+	}
+	hosts = filterPolicyHosts(hosts, policy)
+
 	// Assemble the set of chunks.
 	newUnfinishedChunks := make([]*unfinishedUploadChunk, 0, len(chunkIndexes))
 	for _, index := range chunkIndexes {
@@ -691,6 +709,8 @@ func (r *Renter) managedBuildUnfinishedChunks(entry *filesystem.FileNode, hosts
 			r.log.Debugln("Error when building an unfinished chunk:", err)
 			continue
 		}
+		chunk.health = repairHealth(chunk.health, policy)
+		chunk.staticRepairPriority = policy.RepairPriority
 		newUnfinishedChunks = append(newUnfinishedChunks, chunk)
 	}
 
@@ -710,7 +730,7 @@ func (r *Renter) managedBuildUnfinishedChunks(entry *filesystem.FileNode, hosts
 		// accessed without error. If there is an error accessing the file then
 		// it is likely that we can not read the file in which case it can not
 		// be used for repair.
-		repairable := chunk.health <= 1 || chunk.onDisk
+		repairable := chunk.piecesCompleted >= chunk.staticMinimumPieces || chunk.onDisk
 		needsRepair := modules.NeedsRepair(chunk.health)
 
 		if r.deps.Disrupt("AddUnrepairableChunks") && needsRepair {
@@ -919,6 +939,10 @@ func (r *Renter) callBuildAndPushChunks(files []*filesystem.FileNode, hosts map[
 	// it gets added behind the next directory, ensuring progress is made.
 	var tempChunkHeap uploadChunkHeap
 	nextDirHealth, nextDirRemote := r.directoryHeap.managedPeekHealth()
+	policy, err := r.managedFileStoragePolicy(files[0])
+	if err != nil {
+		r.log.Println("WARN: unable to get storage policy, using the defaults:", err)
+	}
 	wh := worstIgnoredHealth{
 		nextDirHealth: nextDirHealth,
 		nextDirRemote: nextDirRemote,
@@ -931,7 +955,7 @@ func (r *Renter) callBuildAndPushChunks(files []*filesystem.FileNode, hosts map[
 		// this file can be skipped. This only counts for unstuck chunks, if we
 		// are adding stuck files, we ignore health as a consideration.
 		fileMetadata := file.Metadata()
-		fileHealth := fileMetadata.CachedHealth
+		fileHealth := repairHealth(fileMetadata.CachedHealth, policy)
 		_, err := os.Stat(fileMetadata.LocalPath)
 		remoteFile := fileMetadata.LocalPath == "" || err != nil
 		if wh.canSkip(fileHealth, remoteFile) {
@@ -1061,7 +1085,7 @@ func (r *Renter) callBuildAndPushChunks(files []*filesystem.FileNode, hosts map[
 	}
 	// We are done with the temporary heap, reset it so the resources are closed
 	// and the memory is released.
-	err := tempChunkHeap.reset()
+	err = tempChunkHeap.reset()
 	if err != nil {
 		r.log.Println("WARN: error resetting the temporary upload heap:", err)
 	}
@@ -1140,6 +1164,11 @@ func (r *Renter) managedBuildChunkHeap(dirSiaPath modules.SiaPath, hosts map[str
 		r.log.Println("WARN: could not read directory:", err)
 		return
 	}
+	// Get the storage policy that applies to the files.
+	policy, err := r.staticFileSystem.StoragePolicy(dirSiaPath)
+	if err != nil {
+		r.log.Println("WARN: unable to get storage policy, using the defaults:", err)
+	}
 	// Build files from fileinfos
 	var files []*filesystem.FileNode
 	for _, fi := range fileinfos {
@@ -1178,7 +1207,7 @@ func (r *Renter) managedBuildChunkHeap(dirSiaPath modules.SiaPath, hosts map[str
 		// information updated by bubble this cached health is accurate enough
 		// to use in order to determine if a file has any chunks that need
 		// repair
-		ignore := file.NumChunks() == file.NumStuckChunks() || !modules.NeedsRepair(file.Metadata().CachedHealth)
+		ignore := file.NumChunks() == file.NumStuckChunks() || !modules.NeedsRepair(repairHealth(file.Metadata().CachedHealth, policy))
 		if target == targetUnstuckChunks && ignore {
 			err = file.Close()
 			if err != nil {
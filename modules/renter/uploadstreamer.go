@@ -127,6 +127,13 @@ func (r *Renter) UploadStreamFromReader(up modules.FileUploadParams, reader io.R
 
 	// Perform the upload, close the filenode, and return.
 	fileNode, err := r.callUploadStreamFromReader(up, reader)
+	if errors.Contains(err, filesystem.ErrStorageQuotaExceeded) || errors.Contains(err, filesystem.ErrUploadQuotaExceeded) {
+		// Don't keep the partial upload since it would count towards the
+		// storage of the tenant.
+		if deleteErr := r.DeleteFile(up.SiaPath); deleteErr != nil && !errors.Contains(deleteErr, filesystem.ErrNotExist) {
+			err = errors.Compose(err, deleteErr)
+		}
+	}
 	if err != nil {
 		return errors.AddContext(err, "unable to stream an upload from a reader")
 	}
@@ -136,9 +143,24 @@ func (r *Renter) UploadStreamFromReader(up modules.FileUploadParams, reader io.R
 // managedInitUploadStream verifies the upload parameters and prepares an empty
 // SiaFile for the upload.
 func (r *Renter) managedInitUploadStream(up modules.FileUploadParams) (*filesystem.FileNode, error) {
+	// Fill in any missing upload params from the storage policy of the
+	// directory.
+	dirSiaPath, err := up.SiaPath.Dir()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := r.staticFileSystem.StoragePolicy(dirSiaPath)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to get storage policy")
+	}
+	if err := applyStoragePolicy(&up, policy); err != nil {
+		return nil, err
+	}
+	if up.CipherType == (crypto.CipherType{}) {
+		up.CipherType = crypto.TypeDefaultRenter
+	}
 	siaPath, ec, force, repair, cipherType := up.SiaPath, up.ErasureCode, up.Force, up.Repair, up.CipherType
 	// Check if ec was set. If not use defaults.
-	var err error
 	if ec == nil && !repair {
 		ec = modules.NewRSSubCodeDefault()
 		up.ErasureCode = ec
@@ -151,6 +173,16 @@ func (r *Renter) managedInitUploadStream(up modules.FileUploadParams) (*filesyst
 		return nil, errors.New("'force' and 'repair' can't both be set")
 	}
 
+	// Compressed files can't be repaired from a stream since the stream
+	// would need to be compressed exactly the same way.
+	compressed := up.Compression != modules.CompressionNone
+	if compressed && repair {
+		return nil, errors.New("'compression' and 'repair' can't both be set")
+	}
+	if compressed && up.Compression != modules.CompressionGzip {
+		return nil, errors.AddContext(modules.ErrUnknownCompression, string(up.Compression))
+	}
+
 	// Delete existing file if overwrite flag is set. Ignore ErrUnknownPath.
 	if force {
 		err := r.DeleteFile(siaPath)
@@ -164,6 +196,9 @@ func (r *Renter) managedInitUploadStream(up modules.FileUploadParams) (*filesyst
 		if err != nil {
 			return nil, err
 		}
+		if entry.CompressionInfo().Compressed() {
+			return nil, errors.Compose(errors.New("can't repair compressed file from a stream"), entry.Close())
+		}
 		return entry, nil
 	}
 	// Check that we have contracts to upload to. We need at least data +
@@ -183,8 +218,17 @@ func (r *Renter) managedInitUploadStream(up modules.FileUploadParams) (*filesyst
 		cipherKey = crypto.GenerateSiaKey(cipherType)
 	}
 
+	// Compressed files don't track the source since the uploaded data
+	// doesn't match the data on disk.
+	source := up.Source
+	if compressed {
+		source = ""
+	}
+
 	// Create the Siafile and add to renter
-	err = r.staticFileSystem.NewSiaFile(siaPath, up.Source, up.ErasureCode, cipherKey, 0, defaultFilePerm, up.DisablePartialChunk)
+	r.staticReencodes.swapMu.RLock()
+	err = r.staticFileSystem.NewSiaFile(siaPath, source, up.ErasureCode, cipherKey, 0, defaultFilePerm, up.DisablePartialChunk)
+	r.staticReencodes.swapMu.RUnlock()
 	if err != nil {
 		return nil, err
 	}
@@ -200,12 +244,21 @@ func (r *Renter) managedInitUploadStream(up modules.FileUploadParams) (*filesyst
 // the Sia network, this will happen faster than the entire upload is complete -
 // the streamer may continue uploading in the background after returning while
 // it is boosting redundancy.
-func (r *Renter) callUploadStreamFromReader(up modules.FileUploadParams, reader io.Reader) (fileNode *filesystem.FileNode, err error) {
+func (r *Renter) callUploadStreamFromReader(up modules.FileUploadParams, reader io.Reader) (*filesystem.FileNode, error) {
 	// Check the upload params first.
-	fileNode, err = r.managedInitUploadStream(up)
+	fileNode, err := r.managedInitUploadStream(up)
 	if err != nil {
 		return nil, err
 	}
+	return r.callUploadStreamToNode(up, fileNode, reader, nil)
+}
+
+// callUploadStreamToNode reads from the provided reader until io.EOF is
+// reached and uploads the data to the file created by managedInitUploadStream.
+// The fileNode is closed if an error is returned. If tu is not nil, the quotas
+// of the file's tenant were already reserved by the caller. Otherwise the data
+// read from the stream is counted towards them.
+func (r *Renter) callUploadStreamToNode(up modules.FileUploadParams, fileNode *filesystem.FileNode, reader io.Reader, tu *filesystem.TenantUpload) (_ *filesystem.FileNode, err error) {
 	// Need to make a copy of this value for the defer statement. Because
 	// 'fileNode' is a named value, if you run the call `return nil, err`, then
 	// 'fileNode' will be set to 'nil' when 'fileNode.Close()' gets called in
@@ -218,10 +271,61 @@ func (r *Renter) callUploadStreamFromReader(up modules.FileUploadParams, reader
 		}
 	}()
 
+	// Compute the checksum of the uncompressed data. Repairs keep the
+	// checksum of the existing file.
+	var csr *checksumReader
+	checksumType, err := modules.NewChecksumType(string(up.Checksum))
+	if err != nil {
+		return nil, err
+	}
+	if !up.Repair && checksumType != modules.ChecksumNone {
+		csr, err = newChecksumReader(checksumType, reader)
+		if err != nil {
+			return nil, err
+		}
+		reader = csr
+	}
+	setChecksum := func() error {
+		if csr == nil {
+			return nil
+		}
+		return errors.AddContext(fileNode.SetChecksum(csr.staticType, csr.Checksum()), "unable to set checksum")
+	}
+
+	// Compress the stream if requested and record the compression before
+	// any data is uploaded.
+	var cr *compressionReader
+	if up.Compression != modules.CompressionNone {
+		cr = newCompressionReader(up.Compression, compressionBlockSize, reader)
+		reader = cr
+		if err := fileNode.SetCompressionInfo(cr.Info()); err != nil {
+			return nil, errors.AddContext(err, "unable to set compression info")
+		}
+	}
+
+	// Count the uploaded data towards the quotas of the file's tenant.
+	// Repairs don't add any data to the file.
+	if !up.Repair && tu == nil {
+		tu, err = r.staticFileSystem.BeginTenantUpload(up.SiaPath)
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			err = errors.Compose(err, tu.Close())
+		}()
+		reader = &tenantUploadReader{
+			r:            reader,
+			staticUpload: tu,
+		}
+	}
+
 	// Check if stream has at least one byte. No need to upload empty data.
 	peek := []byte{0}
 	_, err = io.ReadFull(reader, peek)
 	if errors.Contains(err, io.EOF) || errors.Contains(err, io.ErrUnexpectedEOF) {
+		if err := setChecksum(); err != nil {
+			return nil, err
+		}
 		return fileNode, nil
 	} else if err != nil {
 		return nil, err
@@ -233,8 +337,14 @@ func (r *Renter) callUploadStreamFromReader(up modules.FileUploadParams, reader
 		pks[string(pk.Key)] = pk
 	}
 
-	// Get the most recent workers.
+	// Get the most recent workers and restrict the hosts to the ones allowed
+	// by the storage policy of the file.
 	hosts := r.managedRefreshHostsAndWorkers()
+	policy, err := r.managedFileStoragePolicy(fileNode)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to get storage policy")
+	}
+	hosts = filterPolicyHosts(hosts, policy)
 
 	// Check if we currently have enough workers for the specified redundancy.
 	minWorkers := fileNode.ErasureCode().MinPieces()
@@ -341,6 +451,18 @@ func (r *Renter) callUploadStreamFromReader(up modules.FileUploadParams, reader
 		}
 	}
 
+	// Record the compressed blocks now that the whole stream was read.
+	if cr != nil {
+		if err := fileNode.SetCompressionInfo(cr.Info()); err != nil {
+			return nil, errors.AddContext(err, "unable to set compression info")
+		}
+	}
+
+	// Record the checksum now that the whole stream was read.
+	if err := setChecksum(); err != nil {
+		return nil, err
+	}
+
 	// Disrupt to force an error and ensure the fileNode is being closed
 	// correctly.
 	if r.deps.Disrupt("failUploadStreamFromReader") {
@@ -0,0 +1,33 @@
+package renter
+
+import (
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// SetFileUserMetadata updates the user metadata of a file. Entries with an
+// empty value remove the key.
+func (r *Renter) SetFileUserMetadata(siaPath modules.SiaPath, update modules.UserMetadata) (err error) {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, entry.Close())
+	}()
+	return errors.AddContext(entry.UpdateUserMetadata(update), "unable to set user metadata")
+}
+
+// SetDirUserMetadata updates the user metadata of a directory. Entries with an
+// empty value remove the key.
+func (r *Renter) SetDirUserMetadata(siaPath modules.SiaPath, update modules.UserMetadata) error {
+	if err := r.tg.Add(); err != nil {
+		return err
+	}
+	defer r.tg.Done()
+	return errors.AddContext(r.staticFileSystem.SetDirUserMetadata(siaPath, update), "unable to set user metadata")
+}
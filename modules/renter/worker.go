@@ -102,6 +102,10 @@ type (
 		staticAccount       *account
 		staticBalanceTarget types.Currency
 
+		// The audit state contains the results of the audits the worker
+		// performed on its host.
+		staticAuditState *workerAuditState
+
 		// The loop state contains information about the worker loop. It is
 		// mostly atomic variables that the worker uses to ratelimit the
 		// launching of async jobs.
@@ -257,6 +261,7 @@ func (r *Renter) newWorker(hostPubKey types.SiaPublicKey) (*worker, error) {
 	}
 	w.newPriceTable()
 	w.newMaintenanceState()
+	w.newAuditState()
 	w.initJobHasSectorQueue()
 	w.initJobReadQueue()
 	w.initJobLowPrioReadQueue()
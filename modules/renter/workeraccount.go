@@ -41,6 +41,7 @@ const (
 	// corresponding spending field in the account's spending details whenever
 	// we pay for an rpc request using the ephemeral account as payment method.
 	categoryErr spendingCategory = iota
+	categoryAudit
 	categoryDownload
 	categoryRegistryRead
 	categoryRegistryWrite
@@ -163,6 +164,7 @@ type (
 	// these categories. Every field of this struct should have a corresponding
 	// 'spendingCategory'.
 	spendingDetails struct {
+		audits            types.Currency
 		downloads         types.Currency
 		registryReads     types.Currency
 		registryWrites    types.Currency
@@ -188,6 +190,8 @@ func (s *spendingDetails) update(category spendingCategory, amount types.Currenc
 	}
 
 	switch category {
+	case categoryAudit:
+		s.audits = s.audits.Add(amount)
 	case categoryDownload:
 		s.downloads = s.downloads.Add(amount)
 	case categorySnapshotDownload:
@@ -656,6 +660,10 @@ func (w *worker) managedRefillAccount() {
 	// the error on the deposit
 	w.staticAccount.managedTrackDeposit(amount)
 	var err error
+	span := w.renter.staticTracer.startTrace("worker.refillAccount", "hostkey", w.staticHostPubKey, "amount", amount)
+	defer func() {
+		span.finish(err)
+	}()
 	defer func() {
 		// If there was no error, the account should now be full, and will not
 		// need to be refilled until the worker has spent up the funds in the
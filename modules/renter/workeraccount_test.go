@@ -284,7 +284,8 @@ func testAccountTrackSpending(t *testing.T) {
 		!a.spending.repairDownloads.IsZero() ||
 		!a.spending.repairUploads.IsZero() ||
 		!a.spending.subscriptions.IsZero() ||
-		!a.spending.uploads.IsZero() {
+		!a.spending.uploads.IsZero() ||
+		!a.spending.audits.IsZero() {
 		t.Fatal("unexpected")
 	}
 
@@ -298,6 +299,7 @@ func testAccountTrackSpending(t *testing.T) {
 	a.trackSpending(categoryRepairUpload, hasting.Mul64(7))
 	a.trackSpending(categorySubscription, hasting.Mul64(8))
 	a.trackSpending(categoryUpload, hasting.Mul64(9))
+	a.trackSpending(categoryAudit, hasting.Mul64(10))
 	if !a.spending.downloads.Equals(hasting.Mul64(1)) ||
 		!a.spending.snapshotDownloads.Equals(hasting.Mul64(2)) ||
 		!a.spending.snapshotUploads.Equals(hasting.Mul64(3)) ||
@@ -306,7 +308,8 @@ func testAccountTrackSpending(t *testing.T) {
 		!a.spending.repairDownloads.Equals(hasting.Mul64(6)) ||
 		!a.spending.repairUploads.Equals(hasting.Mul64(7)) ||
 		!a.spending.subscriptions.Equals(hasting.Mul64(8)) ||
-		!a.spending.uploads.Equals(hasting.Mul64(9)) {
+		!a.spending.uploads.Equals(hasting.Mul64(9)) ||
+		!a.spending.audits.Equals(hasting.Mul64(10)) {
 		t.Fatal("unexpected")
 	}
 
@@ -103,6 +103,10 @@ type (
 		SpendingSnapshotUploads   types.Currency
 		SpendingSubscriptions     types.Currency
 		SpendingUploads           types.Currency
+
+		// SpendingAudits was added after the other spending details. It's
+		// appended to stay compatible with the accounts persisted before.
+		SpendingAudits types.Currency
 	}
 
 	// accountPersistenceV150 is how the account persistence struct looked
@@ -161,6 +165,7 @@ func (a *account) persist() error {
 		SpendingSnapshotUploads:   a.spending.snapshotUploads,
 		SpendingSubscriptions:     a.spending.subscriptions,
 		SpendingUploads:           a.spending.uploads,
+		SpendingAudits:            a.spending.audits,
 	}
 
 	_, err := a.staticFile.WriteAt(accountData.bytes(), a.staticOffset)
@@ -567,6 +572,7 @@ func (am *accountManager) readAccountAt(offset int64) (*account, error) {
 			snapshotUploads:   accountData.SpendingSnapshotUploads,
 			subscriptions:     accountData.SpendingSubscriptions,
 			uploads:           accountData.SpendingUploads,
+			audits:            accountData.SpendingAudits,
 		},
 
 		staticReady:  make(chan struct{}),
@@ -0,0 +1,169 @@
+package renter
+
+// workeraudit.go contains the logic for auditing the hosts the renter has
+// contracts with. Periodically every worker picks a few random sectors from
+// its contract and downloads a random segment of each sector together with a
+// Merkle proof. If the host no longer stores the sector or provides an invalid
+// proof, the audit fails. Audit results are reported to the hostdb where they
+// affect the host's score and the contract's utility.
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+)
+
+type (
+	// workerAuditState contains the results of the audits the worker
+	// performed on its host.
+	workerAuditState struct {
+		failedAudits     uint64
+		successfulAudits uint64
+		lastAuditTime    time.Time
+		recentErr        error
+		recentErrTime    time.Time
+
+		mu sync.Mutex
+	}
+)
+
+// isAuditFailure returns true if the given error proves that the host failed
+// to serve a sector it is supposed to store. Any other error, such as a
+// network failure or a timeout, renders the audit inconclusive.
+func isAuditFailure(err error) bool {
+	return errors.Contains(err, errReadSectorProofInvalid) ||
+		errors.Contains(err, modules.ErrSectorNotFound)
+}
+
+// managedRecordAudit updates the audit state with the outcome of an audit.
+func (was *workerAuditState) managedRecordAudit(err error) {
+	was.mu.Lock()
+	defer was.mu.Unlock()
+	was.lastAuditTime = time.Now()
+	if err == nil {
+		was.successfulAudits++
+		return
+	}
+	if isAuditFailure(err) {
+		was.failedAudits++
+	}
+	was.recentErr = err
+	was.recentErrTime = was.lastAuditTime
+}
+
+// managedStatus returns the status of the audit state.
+func (was *workerAuditState) managedStatus() modules.WorkerAuditStatus {
+	was.mu.Lock()
+	defer was.mu.Unlock()
+	var recentErrStr string
+	if was.recentErr != nil {
+		recentErrStr = was.recentErr.Error()
+	}
+	return modules.WorkerAuditStatus{
+		FailedAudits:     was.failedAudits,
+		SuccessfulAudits: was.successfulAudits,
+		LastAuditTime:    was.lastAuditTime,
+		RecentErr:        recentErrStr,
+		RecentErrTime:    was.recentErrTime,
+	}
+}
+
+// callAuditStatus returns the status of the worker's host audits.
+func (w *worker) callAuditStatus() modules.WorkerAuditStatus {
+	return w.staticAuditState.managedStatus()
+}
+
+// managedAuditHost spot checks a few random sectors of the worker's contract
+// and reports the results to the hostdb.
+func (w *worker) managedAuditHost() {
+	// Don't bother auditing a host we can't download from right now.
+	if w.staticJobLowPrioReadQueue.callOnCooldown() {
+		return
+	}
+	roots, err := w.renter.hostContractor.RandomContractRoots(w.staticHostPubKey, auditSectorsPerContract)
+	if err != nil {
+		w.renter.log.Debugf("Worker %v: unable to fetch roots for audit: %v", w.staticHostPubKeyStr, err)
+		return
+	}
+	for _, root := range roots {
+		// Sectors uploaded through a session, e.g. backups, are recorded with
+		// an empty root since the renter doesn't know the root of those
+		// sectors. They can't be audited.
+		if root == (crypto.Hash{}) {
+			continue
+		}
+		err := w.managedAuditSector(root)
+		w.staticAuditState.managedRecordAudit(err)
+		if err == nil {
+			err = w.renter.hostDB.IncrementSuccessfulAudits(w.staticHostPubKey)
+		} else if isAuditFailure(err) {
+			w.renter.log.Printf("Worker %v: host failed audit of sector %v: %v", w.staticHostPubKeyStr, root, err)
+			err = w.renter.hostDB.IncrementFailedAudits(w.staticHostPubKey)
+		} else {
+			w.renter.log.Debugf("Worker %v: audit of sector %v was inconclusive: %v", w.staticHostPubKeyStr, root, err)
+			continue
+		}
+		if err != nil {
+			w.renter.log.Printf("Worker %v: failed to report audit result to hostdb: %v", w.staticHostPubKeyStr, err)
+		}
+	}
+}
+
+// managedAuditSector downloads a random segment of the sector with the given
+// root from the host. The segment's Merkle proof is verified by the read
+// sector job.
+func (w *worker) managedAuditSector(root crypto.Hash) error {
+	numSegments := modules.SectorSize / crypto.SegmentSize
+	offset := fastrand.Uint64n(numSegments) * crypto.SegmentSize
+
+	ctx, cancel := context.WithTimeout(w.renter.tg.StopCtx(), auditTimeout)
+	defer cancel()
+	_, err := w.ReadSectorLowPrio(ctx, categoryAudit, root, offset, crypto.SegmentSize)
+	return err
+}
+
+// newAuditState initializes the worker's audit state.
+func (w *worker) newAuditState() {
+	if w.staticAuditState != nil {
+		w.renter.log.Critical("auditstate already exists")
+	}
+	w.staticAuditState = new(workerAuditState)
+}
+
+// managedAuditHosts performs a round of audits on all of the renter's
+// workers in parallel.
+func (r *Renter) managedAuditHosts() {
+	var wg sync.WaitGroup
+	for _, w := range r.staticWorkerPool.callWorkers() {
+		wg.Add(1)
+		go func(w *worker) {
+			defer wg.Done()
+			w.managedAuditHost()
+		}(w)
+	}
+	wg.Wait()
+}
+
+// threadedAuditHosts periodically audits the hosts the renter has contracts
+// with.
+func (r *Renter) threadedAuditHosts() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(auditInterval):
+		}
+		r.managedAuditHosts()
+	}
+}
@@ -0,0 +1,45 @@
+package renter
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/modules"
+)
+
+// TestWorkerAuditState is a unit test that verifies the audit state correctly
+// distinguishes between failed and inconclusive audits.
+func TestWorkerAuditState(t *testing.T) {
+	t.Parallel()
+
+	// Check the error classification.
+	if isAuditFailure(nil) {
+		t.Fatal("nil error is not an audit failure")
+	}
+	if !isAuditFailure(errors.AddContext(errReadSectorProofInvalid, "context")) {
+		t.Fatal("invalid proof should be an audit failure")
+	}
+	if !isAuditFailure(errors.AddContext(modules.ErrSectorNotFound, "managedRead: program execution was interrupted")) {
+		t.Fatal("missing sector should be an audit failure")
+	}
+	if isAuditFailure(errors.New("Read interrupted")) {
+		t.Fatal("timeout should be inconclusive")
+	}
+
+	// Record a success, a failure and an inconclusive audit.
+	was := new(workerAuditState)
+	was.managedRecordAudit(nil)
+	was.managedRecordAudit(errReadSectorProofInvalid)
+	was.managedRecordAudit(errors.New("Read interrupted"))
+
+	status := was.managedStatus()
+	if status.SuccessfulAudits != 1 || status.FailedAudits != 1 {
+		t.Fatal("unexpected audit counters", status.SuccessfulAudits, status.FailedAudits)
+	}
+	if status.LastAuditTime.IsZero() || status.RecentErrTime.IsZero() {
+		t.Fatal("audit times should be set")
+	}
+	if status.RecentErr != "Read interrupted" {
+		t.Fatal("unexpected recent error", status.RecentErr)
+	}
+}
@@ -120,11 +120,13 @@ func (w *worker) threadedPerformDownloadChunkJob(udc *unfinishedDownloadChunk) {
 	// whether successful or failed, the worker needs to be removed.
 	defer udc.managedRemoveWorker()
 
+	log := w.renter.log.WithFields("siapath", udc.download.staticSiaPath, "hostkey", w.staticHostPubKey)
+
 	// Before performing the download, check for price gouging.
 	allowance := w.renter.hostContractor.Allowance()
 	err := checkDownloadGouging(allowance, &w.staticPriceTable().staticPriceTable)
 	if err != nil {
-		w.renter.log.Debugln("worker downloader is not being used because price gouging was detected:", err)
+		log.Debugln("worker downloader is not being used because price gouging was detected:", err)
 		udc.managedUnregisterWorker(w)
 		return
 	}
@@ -133,9 +135,9 @@ func (w *worker) threadedPerformDownloadChunkJob(udc *unfinishedDownloadChunk) {
 	// unregistered with the chunk.
 	fetchOffset, fetchLength := sectorOffsetAndLength(udc.staticFetchOffset, udc.staticFetchLength, udc.erasureCode)
 	root := udc.staticChunkMap[w.staticHostPubKey.String()].root
-	pieceData, err := w.ReadSectorLowPrio(w.renter.tg.StopCtx(), udc.staticSpendingCategory, root, fetchOffset, fetchLength)
+	pieceData, err := w.ReadSectorLowPrio(udc.staticSpan.context(w.renter.tg.StopCtx()), udc.staticSpendingCategory, root, fetchOffset, fetchLength)
 	if err != nil {
-		w.renter.log.Debugln("worker failed to download sector:", err)
+		log.Debugln("worker failed to download sector:", err)
 		udc.managedUnregisterWorker(w)
 		return
 	}
@@ -154,7 +156,7 @@ func (w *worker) threadedPerformDownloadChunkJob(udc *unfinishedDownloadChunk) {
 	key := udc.masterKey.Derive(udc.staticChunkIndex, pieceIndex)
 	decryptedPiece, err := key.DecryptBytesInPlace(pieceData, uint64(fetchOffset/crypto.SegmentSize))
 	if err != nil {
-		w.renter.log.Debugln("worker failed to decrypt piece:", err)
+		log.Debugln("worker failed to decrypt piece:", err)
 		udc.managedUnregisterWorker(w)
 		return
 	}
@@ -181,7 +183,7 @@ func (w *worker) threadedPerformDownloadChunkJob(udc *unfinishedDownloadChunk) {
 		atomic.AddUint64(&udc.download.atomicDataReceived, udc.staticFetchLength-addedReceivedData)
 		// Recover the logical data.
 		if err := w.renter.tg.Add(); err != nil {
-			w.renter.log.Debugln("worker failed to decrypt piece:", err)
+			log.Debugln("worker failed to decrypt piece:", err)
 			udc.mu.Unlock()
 			return
 		}
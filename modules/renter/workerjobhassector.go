@@ -88,8 +88,10 @@ func (j *jobHasSector) callDiscard(err error) {
 func (j *jobHasSector) callExecute() {
 	start := time.Now()
 	w := j.staticQueue.staticWorker()
+	span := childSpan(j.staticCtx, "worker.hasSector", "hostkey", w.staticHostPubKey, "sectors", len(j.staticSectors), "queuewait", start.Sub(j.externJobStartTime))
 	availables, err := j.managedHasSector()
 	jobTime := time.Since(start)
+	span.finish(err)
 
 	// Send the response.
 	response := &jobHasSectorResponse{
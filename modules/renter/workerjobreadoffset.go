@@ -20,10 +20,14 @@ type (
 
 // callExecute executes the jobReadOffset.
 func (j *jobReadOffset) callExecute() {
+	w := j.staticQueue.staticWorker()
+	span := childSpan(j.staticCtx, "worker.readOffset", "hostkey", w.staticHostPubKey, "offset", j.staticOffset, "length", j.staticLength)
+
 	// Track how long the job takes.
 	start := time.Now()
 	data, err := j.managedReadOffset()
 	jobTime := time.Since(start)
+	span.finish(err)
 
 	// Finish the execution.
 	j.jobRead.managedFinishExecute(data, err, jobTime)
@@ -9,6 +9,10 @@ import (
 	"go.sia.tech/siad/modules"
 )
 
+// errReadSectorProofInvalid is returned by a read sector job if the proof
+// provided by the host doesn't match the requested sector root.
+var errReadSectorProofInvalid = errors.New("proof verification failed")
+
 type (
 	// jobReadSector contains information about a readSector query.
 	jobReadSector struct {
@@ -21,10 +25,14 @@ type (
 
 // callExecute executes the jobReadSector.
 func (j *jobReadSector) callExecute() {
+	w := j.staticQueue.staticWorker()
+	span := childSpan(j.staticCtx, "worker.readSector", "hostkey", w.staticHostPubKey, "root", j.staticSector, "offset", j.staticOffset, "length", j.staticLength)
+
 	// Track how long the job takes.
 	start := time.Now()
 	data, err := j.managedReadSector()
 	jobTime := time.Since(start)
+	span.finish(err)
 
 	// Finish the execution.
 	j.jobRead.managedFinishExecute(data, err, jobTime)
@@ -56,7 +64,7 @@ func (j *jobReadSector) managedReadSector() ([]byte, error) {
 	proofStart := int(j.staticOffset) / crypto.SegmentSize
 	proofEnd := int(j.staticOffset+j.staticLength) / crypto.SegmentSize
 	if !crypto.VerifyRangeProof(data, proof, proofStart, proofEnd, j.staticSector) {
-		return nil, errors.New("proof verification failed")
+		return nil, errReadSectorProofInvalid
 	}
 	return data, nil
 }
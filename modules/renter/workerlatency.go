@@ -0,0 +1,63 @@
+package renter
+
+// workerlatency.go contains the logic for reporting the performance the
+// workers measure for their hosts to the hostdb. The hostdb persists the
+// measurements and uses them to favour responsive hosts.
+
+import (
+	"time"
+)
+
+// hostLatencyMetrics computes a host's latency and throughput from the
+// expected job times of the worker's has sector and read job queues. The has
+// sector job barely transfers any data which makes it a good approximation of
+// the latency. The throughput is derived from the difference between small and
+// large reads. If it can't be computed, 0 is returned for the throughput.
+func hostLatencyMetrics(hasSectorTime, read64kTime, read4mTime time.Duration) (latency time.Duration, throughput float64) {
+	latency = hasSectorTime
+	if latency <= 0 {
+		latency = read64kTime
+	}
+	if read4mTime > read64kTime && read64kTime > 0 {
+		throughput = float64((1<<22)-(1<<16)) / (read4mTime - read64kTime).Seconds()
+	}
+	return
+}
+
+// managedReportLatency reports the latency and throughput measured by the
+// worker to the hostdb.
+func (w *worker) managedReportLatency() {
+	latency, throughput := hostLatencyMetrics(
+		w.staticJobHasSectorQueue.callExpectedJobTime(),
+		w.staticJobReadQueue.callExpectedJobTime(1<<16),
+		w.staticJobReadQueue.callExpectedJobTime(1<<22),
+	)
+	if latency <= 0 {
+		return // nothing measured yet
+	}
+	err := w.renter.hostDB.UpdateHostLatency(w.staticHostPubKey, latency, throughput)
+	if err != nil {
+		w.renter.log.Debugf("Worker %v: failed to report latency to hostdb: %v", w.staticHostPubKeyStr, err)
+	}
+}
+
+// threadedReportHostLatencies periodically reports the latencies measured by
+// the workers to the hostdb.
+func (r *Renter) threadedReportHostLatencies() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(hostLatencyReportInterval):
+		}
+		for _, w := range r.staticWorkerPool.callWorkers() {
+			w.managedReportLatency()
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package renter
+
+import (
+	"testing"
+	"time"
+)
+
+// TestHostLatencyMetrics is a unit test for hostLatencyMetrics.
+func TestHostLatencyMetrics(t *testing.T) {
+	t.Parallel()
+
+	// Latency is taken from the has sector jobs and throughput from the
+	// difference between small and large reads.
+	latency, throughput := hostLatencyMetrics(100*time.Millisecond, 200*time.Millisecond, 1200*time.Millisecond)
+	if latency != 100*time.Millisecond {
+		t.Fatal("wrong latency", latency)
+	}
+	if expected := float64((1 << 22) - (1 << 16)); throughput != expected {
+		t.Fatal("wrong throughput", throughput, expected)
+	}
+
+	// Without has sector measurements the small reads are used.
+	latency, _ = hostLatencyMetrics(0, 200*time.Millisecond, 1200*time.Millisecond)
+	if latency != 200*time.Millisecond {
+		t.Fatal("wrong latency", latency)
+	}
+
+	// If large reads aren't slower than small ones the throughput is unknown.
+	_, throughput = hostLatencyMetrics(100*time.Millisecond, 200*time.Millisecond, 200*time.Millisecond)
+	if throughput != 0 {
+		t.Fatal("throughput should be unknown", throughput)
+	}
+}
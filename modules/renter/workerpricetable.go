@@ -209,7 +209,12 @@ func (w *worker) staticUpdatePriceTable() {
 		})
 	}()
 
+	// Trace the update.
 	var err error
+	span := w.renter.staticTracer.startTrace("worker.updatePriceTable", "hostkey", w.staticHostPubKey)
+	defer func() {
+		span.finish(err)
+	}()
 
 	// If this is the first time we are fetching a price table update from the
 	// host, we use the time it took for a single round trip as an initial
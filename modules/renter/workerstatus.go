@@ -72,6 +72,9 @@ func (w *worker) callStatus() modules.WorkerStatus {
 		AccountBalanceTarget: w.staticBalanceTarget,
 		AccountStatus:        w.staticAccount.managedStatus(),
 
+		// Audit Information
+		AuditStatus: w.callAuditStatus(),
+
 		// Price Table Information
 		PriceTableStatus: w.staticPriceTableStatus(),
 
@@ -178,16 +178,19 @@ func (w *worker) managedPerformUploadChunkJob() {
 	if uc == nil {
 		return
 	}
+	span := uc.managedSpan().startChild("worker.uploadPiece", "hostkey", w.staticHostPubKey, "pieceindex", pieceIndex)
+
 	// Open an editing connection to the host.
 	e, err := w.renter.hostContractor.Editor(w.staticHostPubKey, w.renter.tg.StopChan())
 	if err != nil {
 		failureErr := fmt.Errorf("Worker failed to acquire an editor: %v", err)
+		span.finish(failureErr)
 		w.managedUploadFailed(uc, pieceIndex, failureErr)
 		return
 	}
 	defer func() {
 		if err := e.Close(); err != nil {
-			w.renter.log.Print("managedPerformUploadChunkJob: failed to close editor", err)
+			w.renter.log.WithFields("siapath", uc.staticSiaPath, "hostkey", w.staticHostPubKey).Print("managedPerformUploadChunkJob: failed to close editor", err)
 		}
 	}()
 
@@ -197,6 +200,7 @@ func (w *worker) managedPerformUploadChunkJob() {
 	err = checkUploadGouging(allowance, hostSettings)
 	if err != nil && !w.renter.deps.Disrupt("DisableUploadGouging") {
 		failureErr := errors.AddContext(err, "worker uploader is not being used because price gouging was detected")
+		span.finish(failureErr)
 		w.managedUploadFailed(uc, pieceIndex, failureErr)
 		return
 	}
@@ -210,17 +214,20 @@ func (w *worker) managedPerformUploadChunkJob() {
 	ignoreErr := build.VersionCmp(hostSettings.Version, "1.5.5") < 0 && err != nil && strings.Contains(err.Error(), modules.ErrMaxVirtualSectors.Error())
 	if err != nil && !ignoreErr {
 		failureErr := fmt.Errorf("Worker failed to upload root %v via the editor: %v", root, err)
+		span.finish(failureErr)
 		w.managedUploadFailed(uc, pieceIndex, failureErr)
 		return
 	}
 	w.mu.Lock()
 	w.uploadConsecutiveFailures = 0
 	w.mu.Unlock()
+	span.setAttributes("root", root)
 
 	// Add piece to renterFile
 	err = uc.fileEntry.AddPiece(w.staticHostPubKey, uc.staticIndex, pieceIndex, root)
 	if err != nil {
 		failureErr := fmt.Errorf("Worker failed to add new piece to SiaFile: %v", err)
+		span.finish(failureErr)
 		w.managedUploadFailed(uc, pieceIndex, failureErr)
 		return
 	}
@@ -238,6 +245,7 @@ func (w *worker) managedPerformUploadChunkJob() {
 	uc.memoryReleased += uint64(releaseSize)
 	uc.chunkSuccessProcessTimes = append(uc.chunkSuccessProcessTimes, time.Now())
 	uc.mu.Unlock()
+	span.finish(nil)
 	uc.staticMemoryManager.Return(uint64(releaseSize))
 	w.renter.managedCleanUpUploadChunk(uc)
 }
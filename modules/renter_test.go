@@ -282,3 +282,46 @@ func BenchmarkSliceCryptoHashSave(b *testing.B) {
 		}
 	}
 }
+
+// TestUserMetadata tests updating, validating and filtering by user metadata.
+func TestUserMetadata(t *testing.T) {
+	um := UserMetadata{"contenttype": "image/png"}
+
+	// Updates add, change and remove entries without modifying the original.
+	updated := um.Update(UserMetadata{"owner": "alice", "contenttype": "image/jpeg"})
+	if len(updated) != 2 || updated["owner"] != "alice" || updated["contenttype"] != "image/jpeg" {
+		t.Fatal("wrong metadata after update", updated)
+	}
+	if um["contenttype"] != "image/png" {
+		t.Fatal("original metadata was modified", um)
+	}
+	if removed := updated.Update(UserMetadata{"owner": "", "contenttype": ""}); removed != nil {
+		t.Fatal("all entries should be removed", removed)
+	}
+
+	// Tags with and without values.
+	tags, err := NewUserMetadataTags([]string{"owner", "contenttype=image/jpeg"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.HasTags(tags) || um.HasTags(tags) {
+		t.Fatal("wrong tag matches")
+	}
+	if !um.HasTags(nil) {
+		t.Fatal("empty tags should match everything")
+	}
+	if _, err := NewUserMetadataTags([]string{"=value"}); err == nil {
+		t.Fatal("tag without key should fail")
+	}
+
+	// Validation.
+	if err := (UserMetadata{"a=b": "c"}).Validate(); err == nil {
+		t.Fatal("key with '=' should be invalid")
+	}
+	if err := (UserMetadata{"key": string(fastrand.Bytes(MaxUserMetadataSize))}).Validate(); err == nil {
+		t.Fatal("metadata exceeding the size limit should be invalid")
+	}
+	if err := updated.Validate(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,12 +3,12 @@ package modules
 import (
 	"encoding/hex"
 	"encoding/json"
-	"errors"
 	"io"
 	"strings"
 	"time"
 
 	"gitlab.com/NebulousLabs/encoding"
+	"gitlab.com/NebulousLabs/errors"
 	"go.sia.tech/siad/build"
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/types"
@@ -55,6 +55,15 @@ var (
 	// table has expired.
 	ErrPriceTableExpired = errors.New("Price table requested is expired")
 
+	// ErrSectorNotFound is returned by the host if it doesn't store a
+	// requested sector.
+	ErrSectorNotFound = errors.New("could not find the desired sector")
+
+	// knownProgramErrors are the errors of a program's instructions that are
+	// restored when a program response is decoded. That way they can be
+	// detected with errors.Contains.
+	knownProgramErrors = []error{ErrSectorNotFound}
+
 	// SubscriptionPeriod is the duration by which a period gets extended after
 	// a payment.
 	SubscriptionPeriod = build.Select(build.Var{
@@ -400,11 +409,26 @@ func (epr *RPCExecuteProgramResponse) UnmarshalSia(r io.Reader) error {
 	_ = dc.Decode(&epr.TotalCost)
 	_ = dc.Decode(&epr.FailureRefund)
 	if errStr != "" {
-		epr.Error = errors.New(errStr)
+		epr.Error = decodeProgramError(errStr)
 	}
 	return dc.Err()
 }
 
+// decodeProgramError turns the error string of a program response back into
+// an error. If the string ends with one of the known program errors, the known
+// error is returned together with the context the host added to it.
+func decodeProgramError(errStr string) error {
+	for _, known := range knownProgramErrors {
+		if errStr == known.Error() {
+			return known
+		}
+		if strings.HasSuffix(errStr, ": "+known.Error()) {
+			return errors.AddContext(known, strings.TrimSuffix(errStr, ": "+known.Error()))
+		}
+	}
+	return errors.New(errStr)
+}
+
 // RPCReadMaxLen tries to read the given object from the stream. It will
 // allocate at most maxLen bytes for the object.
 func RPCReadMaxLen(r io.Reader, obj interface{}, maxLen uint64) error {
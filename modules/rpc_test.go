@@ -213,3 +213,30 @@ func TestIsPriceTableInvalidErr(t *testing.T) {
 		}
 	}
 }
+
+// TestDecodeProgramError verifies that known program errors can be detected
+// with errors.Contains after decoding a program response.
+func TestDecodeProgramError(t *testing.T) {
+	t.Parallel()
+
+	var tests = []struct {
+		errStr   string
+		expected bool
+	}{
+		{"err", false},
+		{ErrSectorNotFound.Error(), true},
+		{"failed to read: " + ErrSectorNotFound.Error(), true},
+		{ErrSectorNotFound.Error() + " twice", false},
+	}
+	for _, test := range tests {
+		err := decodeProgramError(test.errStr)
+		for _, part := range strings.Split(test.errStr, ": ") {
+			if !strings.Contains(err.Error(), part) {
+				t.Fatalf("'%v' is missing from '%v'", part, err)
+			}
+		}
+		if errors.Contains(err, ErrSectorNotFound) != test.expected {
+			t.Fatal("unexpected", test.errStr)
+		}
+	}
+}
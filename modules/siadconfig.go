@@ -19,6 +19,9 @@ type (
 		WriteBPS           int64  `json:"writebps"`
 		PacketSize         uint64 `json:"packetsize"`
 
+		// EnableMetrics enables the /metrics endpoint of the API.
+		EnableMetrics bool `json:"enablemetrics"`
+
 		// path of config on disk.
 		path string
 		mu   sync.Mutex
@@ -59,6 +62,22 @@ func (cfg *SiadConfig) SetRatelimit(readBPS, writeBPS int64) error {
 	return cfg.save()
 }
 
+// MetricsEnabled returns whether the /metrics endpoint is enabled.
+func (cfg *SiadConfig) MetricsEnabled() bool {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	return cfg.EnableMetrics
+}
+
+// SetMetricsEnabled enables or disables the /metrics endpoint and persists the
+// setting to disk.
+func (cfg *SiadConfig) SetMetricsEnabled(enabled bool) error {
+	cfg.mu.Lock()
+	defer cfg.mu.Unlock()
+	cfg.EnableMetrics = enabled
+	return cfg.save()
+}
+
 // save saves the config to disk.
 func (cfg *SiadConfig) save() error {
 	return persist.SaveJSON(configMetadata, cfg, cfg.path)
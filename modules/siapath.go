@@ -53,8 +53,20 @@ var (
 	// accessible data.
 	HomeFolder = NewGlobalSiaPath("/home")
 
+	// TenantsFolder is the Sia folder that contains the namespaces of the
+	// renter's tenants.
+	TenantsFolder = NewGlobalSiaPath("/tenants")
+
 	// UserFolder is the Sia folder that is used to store the renter's siafiles.
 	UserFolder = NewGlobalSiaPath("/home/user")
+
+	// VersionsFolder is the Sia folder where the prior versions of overwritten
+	// and deleted siafiles are kept.
+	VersionsFolder = NewGlobalSiaPath("/versions")
+
+	// ReencodeFolder is the Sia folder where the new siafiles of files that
+	// are being re-encoded are kept until they replace the original ones.
+	ReencodeFolder = NewGlobalSiaPath("/reencode")
 )
 
 type (
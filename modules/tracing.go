@@ -0,0 +1,181 @@
+package modules
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"go.sia.tech/siad/build"
+)
+
+// OTLPScopeName is the name of the instrumentation scope of the exported
+// traces.
+const OTLPScopeName = "go.sia.tech/siad/modules/renter"
+
+type (
+	// RenterTrace is a trace of a renter operation, e.g. the download of a
+	// chunk. It consists of spans which form a tree with the root span of the
+	// trace at the top.
+	RenterTrace struct {
+		ID       string        `json:"id"`
+		Name     string        `json:"name"`
+		Start    time.Time     `json:"start"`
+		Duration time.Duration `json:"duration"` // zero until the trace is finished
+		Finished bool          `json:"finished"`
+		Error    string        `json:"error,omitempty"`
+
+		// Attributes are the attributes of the root span.
+		Attributes map[string]string `json:"attributes,omitempty"`
+
+		// NumSpans is the number of finished spans and DroppedSpans the number
+		// of spans that weren't recorded because the trace was too large.
+		NumSpans     int `json:"numspans"`
+		DroppedSpans int `json:"droppedspans"`
+
+		// Spans contains the finished spans of the trace ordered by their
+		// start time. It's only set when a single trace is requested.
+		Spans []RenterTraceSpan `json:"spans,omitempty"`
+	}
+
+	// RenterTraceSpan is a timed operation within a trace, e.g. a read sector
+	// job of a worker.
+	RenterTraceSpan struct {
+		ID         string            `json:"id"`
+		ParentID   string            `json:"parentid,omitempty"`
+		Name       string            `json:"name"`
+		Start      time.Time         `json:"start"`
+		Duration   time.Duration     `json:"duration"`
+		Attributes map[string]string `json:"attributes,omitempty"`
+		Error      string            `json:"error,omitempty"`
+	}
+
+	// OTLPTraces is an OpenTelemetry ExportTraceServiceRequest in the OTLP
+	// JSON encoding. It can be sent to the /v1/traces endpoint of a collector.
+	OTLPTraces struct {
+		ResourceSpans []OTLPResourceSpans `json:"resourceSpans"`
+	}
+
+	// OTLPResourceSpans are the spans of a resource.
+	OTLPResourceSpans struct {
+		Resource   OTLPResource     `json:"resource"`
+		ScopeSpans []OTLPScopeSpans `json:"scopeSpans"`
+	}
+
+	// OTLPResource describes the entity producing the spans.
+	OTLPResource struct {
+		Attributes []OTLPKeyValue `json:"attributes"`
+	}
+
+	// OTLPScopeSpans are the spans of an instrumentation scope.
+	OTLPScopeSpans struct {
+		Scope OTLPScope  `json:"scope"`
+		Spans []OTLPSpan `json:"spans"`
+	}
+
+	// OTLPScope is an instrumentation scope.
+	OTLPScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	// OTLPSpan is a span in the OTLP JSON encoding. The IDs are hex encoded
+	// and the timestamps are unix nanoseconds encoded as strings.
+	OTLPSpan struct {
+		TraceID           string         `json:"traceId"`
+		SpanID            string         `json:"spanId"`
+		ParentSpanID      string         `json:"parentSpanId,omitempty"`
+		Name              string         `json:"name"`
+		Kind              int            `json:"kind"`
+		StartTimeUnixNano string         `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string         `json:"endTimeUnixNano"`
+		Attributes        []OTLPKeyValue `json:"attributes,omitempty"`
+		Status            OTLPStatus     `json:"status"`
+	}
+
+	// OTLPKeyValue is a string attribute.
+	OTLPKeyValue struct {
+		Key   string       `json:"key"`
+		Value OTLPAnyValue `json:"value"`
+	}
+
+	// OTLPAnyValue is the value of an attribute.
+	OTLPAnyValue struct {
+		StringValue string `json:"stringValue"`
+	}
+
+	// OTLPStatus is the status of a span.
+	OTLPStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+const (
+	// otlpSpanKindInternal is the kind of all spans.
+	otlpSpanKindInternal = 1
+
+	// otlpStatusCodeOK and otlpStatusCodeError are the status codes of
+	// successful and failed spans.
+	otlpStatusCodeOK    = 1
+	otlpStatusCodeError = 2
+)
+
+// OTLP converts the spans of traces to the OTLP JSON encoding.
+func OTLP(traces []RenterTrace) OTLPTraces {
+	var otlpSpans []OTLPSpan
+	for _, t := range traces {
+		otlpSpans = append(otlpSpans, otlpSpansOfTrace(t)...)
+	}
+	if otlpSpans == nil {
+		otlpSpans = []OTLPSpan{}
+	}
+	return OTLPTraces{
+		ResourceSpans: []OTLPResourceSpans{{
+			Resource: OTLPResource{
+				Attributes: otlpAttributes(map[string]string{
+					"service.name":    build.BinaryName,
+					"service.version": build.NodeVersion,
+				}),
+			},
+			ScopeSpans: []OTLPScopeSpans{{
+				Scope: OTLPScope{Name: OTLPScopeName, Version: build.NodeVersion},
+				Spans: otlpSpans,
+			}},
+		}},
+	}
+}
+
+// otlpSpansOfTrace converts the spans of a trace to OTLP spans.
+func otlpSpansOfTrace(t RenterTrace) []OTLPSpan {
+	otlpSpans := make([]OTLPSpan, 0, len(t.Spans))
+	for _, s := range t.Spans {
+		status := OTLPStatus{Code: otlpStatusCodeOK}
+		if s.Error != "" {
+			status = OTLPStatus{Code: otlpStatusCodeError, Message: s.Error}
+		}
+		otlpSpans = append(otlpSpans, OTLPSpan{
+			TraceID:           t.ID,
+			SpanID:            s.ID,
+			ParentSpanID:      s.ParentID,
+			Name:              s.Name,
+			Kind:              otlpSpanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.Start.Add(s.Duration).UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+			Status:            status,
+		})
+	}
+	return otlpSpans
+}
+
+// otlpAttributes converts attributes to OTLP attributes sorted by key.
+func otlpAttributes(attributes map[string]string) []OTLPKeyValue {
+	kvs := make([]OTLPKeyValue, 0, len(attributes))
+	for k, v := range attributes {
+		kvs = append(kvs, OTLPKeyValue{Key: k, Value: OTLPAnyValue{StringValue: v}})
+	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	return kvs
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"time"
 	"unicode"
 
 	mnemonics "gitlab.com/NebulousLabs/entropy-mnemonics"
@@ -49,6 +50,22 @@ var (
 	// ErrWalletShutdown is returned when a method can't continue execution due
 	// to the wallet shutting down.
 	ErrWalletShutdown = errors.New("wallet is shutting down")
+
+	// ErrDestinationNotAllowed is returned if coins are sent to an address
+	// that isn't on the allowlist of the spending policy.
+	ErrDestinationNotAllowed = errors.New("destination is not on the allowlist of the spending policy")
+
+	// ErrDailyLimitExceeded is returned if a spend would exceed the daily
+	// limit of the spending policy.
+	ErrDailyLimitExceeded = errors.New("spend exceeds the daily limit of the spending policy")
+
+	// ErrPendingSpendNotFound is returned if a pending spend doesn't exist or
+	// has expired.
+	ErrPendingSpendNotFound = errors.New("pending spend not found")
+
+	// ErrSigningRestricted is returned if a transaction is signed while the
+	// spending policy limits the wallet.
+	ErrSigningRestricted = errors.New("transactions can't be signed while the spending policy limits the wallet")
 )
 
 type (
@@ -529,14 +546,95 @@ type (
 		// WatchAddresses returns the set of addresses that the wallet is
 		// currently watching.
 		WatchAddresses() ([]types.UnlockHash, error)
+
+		// SpendingPolicy returns the spending policy of the wallet.
+		SpendingPolicy() (SpendingPolicy, error)
+
+		// SetSpendingPolicy sets the spending policy. The masterKey needs to
+		// be the key the wallet is encrypted with.
+		SetSpendingPolicy(masterKey crypto.CipherKey, policy SpendingPolicy) error
+
+		// PendingSpends returns the spends waiting to be approved.
+		PendingSpends() ([]PendingSpend, error)
+
+		// ApproveSpend sends a pending spend. The masterKey needs to be the
+		// key the wallet is encrypted with.
+		ApproveSpend(masterKey crypto.CipherKey, id string) ([]types.Transaction, error)
+
+		// RejectSpend removes a pending spend without sending it.
+		RejectSpend(id string) error
 	}
 
 	// WalletSettings control the behavior of the Wallet.
 	WalletSettings struct {
 		NoDefrag bool `json:"nodefrag"`
 	}
+
+	// SpendingPolicy limits what can be spent using the API. The limits of the
+	// wallet apply to siacoins sent with SendSiacoins, SendSiacoinsFeeIncluded
+	// and SendSiacoinsMulti, the allowance limits apply to the allowance of
+	// the renter. A zero value disables the respective limit. Arbitrary
+	// transactions can't be signed while the wallet is limited since they
+	// could spend the coins of the wallet without being checked.
+	SpendingPolicy struct {
+		// DailyLimit is the maximum amount of siacoins sent within 24 hours.
+		DailyLimit types.Currency `json:"dailylimit"`
+
+		// ApprovalThreshold is the amount of siacoins above which a spend
+		// needs to be approved before it is sent.
+		ApprovalThreshold types.Currency `json:"approvalthreshold"`
+
+		// AllowedDestinations are the addresses coins can be sent to. If it's
+		// empty, coins can be sent to any address.
+		AllowedDestinations []types.UnlockHash `json:"alloweddestinations"`
+
+		// MaxAllowanceFunds is the maximum amount of funds of the allowance
+		// and MaxAllowanceIncrease the maximum amount the funds can be
+		// increased by within 24 hours.
+		MaxAllowanceFunds    types.Currency `json:"maxallowancefunds"`
+		MaxAllowanceIncrease types.Currency `json:"maxallowanceincrease"`
+	}
+
+	// PendingSpend is a spend above the approval threshold of the spending
+	// policy that is waiting to be approved.
+	PendingSpend struct {
+		ID          string                `json:"id"`
+		Outputs     []types.SiacoinOutput `json:"outputs"`
+		FeeIncluded bool                  `json:"feeincluded"`
+		Created     time.Time             `json:"created"`
+		Expires     time.Time             `json:"expires"`
+	}
+
+	// PendingSpendError is returned if a spend needs to be approved before it
+	// is sent.
+	PendingSpendError struct {
+		ID string
+	}
 )
 
+// Error implements the error interface.
+func (e PendingSpendError) Error() string {
+	return fmt.Sprintf("spend exceeds the approval threshold of the spending policy and needs to be approved, pending spend %v", e.ID)
+}
+
+// IsAllowedDestination returns whether coins can be sent to the address.
+func (sp SpendingPolicy) IsAllowedDestination(addr types.UnlockHash) bool {
+	if len(sp.AllowedDestinations) == 0 {
+		return true
+	}
+	for _, allowed := range sp.AllowedDestinations {
+		if allowed == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// LimitsWallet returns whether the policy limits the coins sent by the wallet.
+func (sp SpendingPolicy) LimitsWallet() bool {
+	return !sp.DailyLimit.IsZero() || !sp.ApprovalThreshold.IsZero() || len(sp.AllowedDestinations) > 0
+}
+
 // CalculateWalletTransactionID is a helper function for determining the id of
 // a wallet transaction.
 func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) WalletTransactionID {
@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"time"
+
 	"go.sia.tech/siad/build"
 )
 
@@ -35,6 +37,15 @@ var (
 		Testnet:  uint64(1000),
 		Testing:  uint64(10),
 	}).(uint64)
+
+	// pendingSpendExpiry is the time after which a pending spend that hasn't
+	// been approved is dropped.
+	pendingSpendExpiry = build.Select(build.Var{
+		Dev:      time.Hour,
+		Standard: 24 * time.Hour,
+		Testnet:  24 * time.Hour,
+		Testing:  time.Minute,
+	}).(time.Duration)
 )
 
 func init() {
@@ -118,9 +118,7 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) ([]t
 	}
 	defer w.tg.Done()
 
-	_, fee := w.tpool.FeeEstimation()
-	fee = fee.Mul64(estimatedTransactionSize)
-	return w.managedSendSiacoins(amount, fee, dest)
+	return w.managedSpend([]types.SiacoinOutput{{Value: amount, UnlockHash: dest}}, false)
 }
 
 // SendSiacoinsFeeIncluded creates a transaction sending 'amount' to 'dest'. The
@@ -133,8 +131,17 @@ func (w *Wallet) SendSiacoinsFeeIncluded(amount types.Currency, dest types.Unloc
 	}
 	defer w.tg.Done()
 
+	return w.managedSpend([]types.SiacoinOutput{{Value: amount, UnlockHash: dest}}, true)
+}
+
+// managedSendSiacoinsSingle sends 'amount' to 'dest'. If feeIncluded is set,
+// the fee is subtracted from the amount, otherwise it is added to it.
+func (w *Wallet) managedSendSiacoinsSingle(amount types.Currency, dest types.UnlockHash, feeIncluded bool) ([]types.Transaction, error) {
 	_, fee := w.tpool.FeeEstimation()
 	fee = fee.Mul64(estimatedTransactionSize)
+	if !feeIncluded {
+		return w.managedSendSiacoins(amount, fee, dest)
+	}
 	// Don't allow sending an amount equal to the fee, as zero spending is not
 	// allowed and would error out later.
 	if amount.Cmp(fee) <= 0 {
@@ -210,6 +217,13 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.
 		return nil, err
 	}
 	defer w.tg.Done()
+
+	return w.managedSpend(outputs, false)
+}
+
+// managedSendSiacoinsMulti creates a transaction that includes the specified
+// outputs and submits it to the transaction pool.
+func (w *Wallet) managedSendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.Transaction, err error) {
 	w.log.Println("Beginning call to SendSiacoinsMulti")
 
 	// Check if consensus is synced
@@ -292,6 +306,17 @@ func (w *Wallet) SendSiafunds(amount types.Currency, dest types.UnlockHash) (txn
 	}
 	defer w.tg.Done()
 
+	// Siafunds aren't counted towards the daily limit but the destination
+	// needs to be allowed.
+	policy, err := w.SpendingPolicy()
+	if err != nil {
+		return nil, err
+	}
+	if !policy.IsAllowedDestination(dest) {
+		w.log.Println("WARN: Attempt to send siafunds has failed - destination not allowed:", dest)
+		return nil, errors.AddContext(modules.ErrDestinationNotAllowed, dest.String())
+	}
+
 	// Check if consensus is synced
 	if !w.cs.Synced() || w.deps.Disrupt("UnsyncedConsensus") {
 		return nil, errors.New("cannot send siafunds until fully synced")
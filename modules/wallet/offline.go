@@ -149,13 +149,24 @@ func (w *Wallet) AddUnlockConditions(uc types.UnlockConditions) error {
 // SignTransaction signs txn using secret keys known to the wallet. The
 // transaction should be complete with the exception of the Signature fields
 // of each TransactionSignature referenced by toSign. For convenience, if
-// toSign is empty, SignTransaction signs everything that it can.
+// toSign is empty, SignTransaction signs everything that it can. Signing is
+// refused while the spending policy limits the wallet since the transaction
+// could send the wallet's coins anywhere.
 func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) error {
 	if err := w.tg.Add(); err != nil {
 		return err
 	}
 	defer w.tg.Done()
 
+	policy, err := w.SpendingPolicy()
+	if err != nil {
+		return err
+	}
+	if policy.LimitsWallet() {
+		w.log.Println("WARN: Attempt to sign a transaction has failed - the spending policy limits the wallet")
+		return modules.ErrSigningRestricted
+	}
+
 	w.mu.Lock()
 	defer w.mu.Unlock()
 	if !w.unlocked {
@@ -118,6 +118,11 @@ func (w *Wallet) initPersist() error {
 		return w.log.Close()
 	})
 
+	// Load the spending policy.
+	if err := w.loadSpendingPolicy(); err != nil {
+		return err
+	}
+
 	// Open the database.
 	dbFilename := filepath.Join(w.persistDir, dbFile)
 	compatFilename := filepath.Join(w.persistDir, compatFile)
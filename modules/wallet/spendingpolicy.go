@@ -0,0 +1,283 @@
+package wallet
+
+// spendingpolicy.go enforces the spending policy of the wallet. The policy
+// limits the siacoins sent through the API, e.g. by a compromised script or
+// a fat-fingered amount. Spends above the approval threshold aren't sent
+// right away but are kept as pending spends until they are approved. Changing
+// the policy and approving spends requires the wallet password in addition to
+// the API password.
+
+import (
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// spendingPolicyFile is the file the spending policy is persisted to.
+	spendingPolicyFile = "spendingpolicy.json"
+
+	// spendingWindow is the window the daily limit applies to.
+	spendingWindow = 24 * time.Hour
+)
+
+var (
+	// spendingPolicyMetadata is the header of the spending policy file.
+	spendingPolicyMetadata = persist.Metadata{
+		Header:  "Wallet Spending Policy",
+		Version: "1.5.9",
+	}
+)
+
+type (
+	// spendingPolicy contains the spending policy, the recent spends that
+	// count towards the daily limit and the pending spends.
+	spendingPolicy struct {
+		Policy  modules.SpendingPolicy `json:"policy"`
+		Spends  []spendRecord          `json:"spends"`
+		Pending []modules.PendingSpend `json:"pending"`
+
+		mu sync.Mutex
+	}
+
+	// spendRecord is an amount of siacoins sent at a certain time.
+	spendRecord struct {
+		Time   time.Time      `json:"time"`
+		Amount types.Currency `json:"amount"`
+	}
+)
+
+// loadSpendingPolicy loads the spending policy. A missing file means that
+// there is no policy.
+func (w *Wallet) loadSpendingPolicy() error {
+	err := persist.LoadJSON(spendingPolicyMetadata, &w.staticSpendingPolicy, filepath.Join(w.persistDir, spendingPolicyFile))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return errors.AddContext(err, "unable to load spending policy")
+}
+
+// saveSpendingPolicy persists the spending policy. The mutex of the policy
+// needs to be held.
+func (w *Wallet) saveSpendingPolicy() error {
+	return persist.SaveJSON(spendingPolicyMetadata, &w.staticSpendingPolicy, filepath.Join(w.persistDir, spendingPolicyFile))
+}
+
+// removePending removes the pending spend with the given ID and returns
+// whether it was found. The mutex of the policy needs to be held.
+func (sp *spendingPolicy) removePending(id string) bool {
+	for i, ps := range sp.Pending {
+		if ps.ID == id {
+			sp.Pending = append(sp.Pending[:i], sp.Pending[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// prune removes the spends outside of the spending window and
+// the expired pending spends. The mutex of the policy needs to be held.
+func (sp *spendingPolicy) prune() {
+	now := time.Now()
+	spends := sp.Spends[:0]
+	for _, s := range sp.Spends {
+		if now.Sub(s.Time) < spendingWindow {
+			spends = append(spends, s)
+		}
+	}
+	sp.Spends = spends
+	pending := sp.Pending[:0]
+	for _, ps := range sp.Pending {
+		if now.Before(ps.Expires) {
+			pending = append(pending, ps)
+		}
+	}
+	sp.Pending = pending
+}
+
+// spentToday returns the amount of siacoins sent within the spending window.
+// The mutex of the policy needs to be held.
+func (sp *spendingPolicy) spentToday() types.Currency {
+	spent := types.ZeroCurrency
+	for _, s := range sp.Spends {
+		spent = spent.Add(s.Amount)
+	}
+	return spent
+}
+
+// managedCheckMasterKey returns modules.ErrBadEncryptionKey if masterKey isn't
+// the key the wallet is encrypted with.
+func (w *Wallet) managedCheckMasterKey(masterKey crypto.CipherKey) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return checkMasterKey(w.dbTx, masterKey)
+}
+
+// managedSpend sends the outputs if the spending policy allows it.
+func (w *Wallet) managedSpend(outputs []types.SiacoinOutput, feeIncluded bool) ([]types.Transaction, error) {
+	w.staticSpendingPolicy.mu.Lock()
+	defer w.staticSpendingPolicy.mu.Unlock()
+	return w.spend(outputs, feeIncluded, false)
+}
+
+// spend checks the outputs against the spending policy and sends them. If the
+// spend needs to be approved, it is added to the pending spends and a
+// modules.PendingSpendError is returned. The mutex of the policy needs to be
+// held.
+func (w *Wallet) spend(outputs []types.SiacoinOutput, feeIncluded, approved bool) ([]types.Transaction, error) {
+	sp := &w.staticSpendingPolicy
+	sp.prune()
+
+	// Check the destinations and the daily limit.
+	total := types.ZeroCurrency
+	for _, sco := range outputs {
+		if !sp.Policy.IsAllowedDestination(sco.UnlockHash) {
+			w.log.Println("WARN: Attempt to send coins has failed - destination not allowed:", sco.UnlockHash)
+			return nil, errors.AddContext(modules.ErrDestinationNotAllowed, sco.UnlockHash.String())
+		}
+		total = total.Add(sco.Value)
+	}
+	if !sp.Policy.DailyLimit.IsZero() && sp.spentToday().Add(total).Cmp(sp.Policy.DailyLimit) > 0 {
+		w.log.Println("WARN: Attempt to send coins has failed - daily limit exceeded:", total.HumanString())
+		return nil, modules.ErrDailyLimitExceeded
+	}
+
+	// Spends above the approval threshold need to be approved first.
+	if !approved && !sp.Policy.ApprovalThreshold.IsZero() && total.Cmp(sp.Policy.ApprovalThreshold) > 0 {
+		ps := modules.PendingSpend{
+			ID:          hex.EncodeToString(fastrand.Bytes(16)),
+			Outputs:     outputs,
+			FeeIncluded: feeIncluded,
+			Created:     time.Now(),
+		}
+		ps.Expires = ps.Created.Add(pendingSpendExpiry)
+		sp.Pending = append(sp.Pending, ps)
+		if err := w.saveSpendingPolicy(); err != nil {
+			return nil, errors.AddContext(err, "unable to persist pending spend")
+		}
+		w.log.Println("INFO: Spend of", total.HumanString(), "needs to be approved, pending spend", ps.ID)
+		return nil, modules.PendingSpendError{ID: ps.ID}
+	}
+
+	// Send the coins and record the spend.
+	var txns []types.Transaction
+	var err error
+	if len(outputs) == 1 {
+		txns, err = w.managedSendSiacoinsSingle(outputs[0].Value, outputs[0].UnlockHash, feeIncluded)
+	} else {
+		txns, err = w.managedSendSiacoinsMulti(outputs)
+	}
+	if err != nil {
+		return nil, err
+	}
+	sp.Spends = append(sp.Spends, spendRecord{Time: time.Now(), Amount: total})
+	if err := w.saveSpendingPolicy(); err != nil {
+		w.log.Println("ERROR: Unable to persist spend:", err)
+	}
+	return txns, nil
+}
+
+// SpendingPolicy returns the spending policy of the wallet.
+func (w *Wallet) SpendingPolicy() (modules.SpendingPolicy, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.SpendingPolicy{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.staticSpendingPolicy.mu.Lock()
+	defer w.staticSpendingPolicy.mu.Unlock()
+	policy := w.staticSpendingPolicy.Policy
+	policy.AllowedDestinations = append([]types.UnlockHash{}, policy.AllowedDestinations...)
+	return policy, nil
+}
+
+// SetSpendingPolicy sets the spending policy of the wallet.
+func (w *Wallet) SetSpendingPolicy(masterKey crypto.CipherKey, policy modules.SpendingPolicy) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if err := w.managedCheckMasterKey(masterKey); err != nil {
+		return err
+	}
+	w.staticSpendingPolicy.mu.Lock()
+	defer w.staticSpendingPolicy.mu.Unlock()
+	old := w.staticSpendingPolicy.Policy
+	w.staticSpendingPolicy.Policy = policy
+	if err := w.saveSpendingPolicy(); err != nil {
+		w.staticSpendingPolicy.Policy = old
+		return errors.AddContext(err, "unable to persist spending policy")
+	}
+	w.log.Printf("INFO: Changed the spending policy to %+v", policy)
+	return nil
+}
+
+// PendingSpends returns the spends waiting to be approved.
+func (w *Wallet) PendingSpends() ([]modules.PendingSpend, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.staticSpendingPolicy.mu.Lock()
+	defer w.staticSpendingPolicy.mu.Unlock()
+	w.staticSpendingPolicy.prune()
+	return append([]modules.PendingSpend{}, w.staticSpendingPolicy.Pending...), nil
+}
+
+// ApproveSpend sends a pending spend. The destinations and the daily limit are
+// checked again since the policy might have changed in the meantime.
+func (w *Wallet) ApproveSpend(masterKey crypto.CipherKey, id string) ([]types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if err := w.managedCheckMasterKey(masterKey); err != nil {
+		return nil, err
+	}
+	w.staticSpendingPolicy.mu.Lock()
+	defer w.staticSpendingPolicy.mu.Unlock()
+	sp := &w.staticSpendingPolicy
+	sp.prune()
+	for _, ps := range sp.Pending {
+		if ps.ID != id {
+			continue
+		}
+		txns, err := w.spend(ps.Outputs, ps.FeeIncluded, true)
+		if err != nil {
+			return nil, err
+		}
+		sp.removePending(id)
+		if err := w.saveSpendingPolicy(); err != nil {
+			w.log.Println("ERROR: Unable to persist approved spend:", err)
+		}
+		w.log.Println("INFO: Approved pending spend", id)
+		return txns, nil
+	}
+	return nil, modules.ErrPendingSpendNotFound
+}
+
+// RejectSpend removes a pending spend without sending it.
+func (w *Wallet) RejectSpend(id string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.staticSpendingPolicy.mu.Lock()
+	defer w.staticSpendingPolicy.mu.Unlock()
+	sp := &w.staticSpendingPolicy
+	sp.prune()
+	if !sp.removePending(id) {
+		return modules.ErrPendingSpendNotFound
+	}
+	w.log.Println("INFO: Rejected pending spend", id)
+	return errors.AddContext(w.saveSpendingPolicy(), "unable to persist rejected spend")
+}
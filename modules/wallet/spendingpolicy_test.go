@@ -0,0 +1,147 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestSpendingPolicy tests that the spending policy is enforced, that pending
+// spends can be approved and rejected and that the policy is persisted.
+func TestSpendingPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := wt.closeWt(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	allowed := types.UnlockHash{1}
+	policy := modules.SpendingPolicy{
+		DailyLimit:          types.SiacoinPrecision.Mul64(100),
+		ApprovalThreshold:   types.SiacoinPrecision.Mul64(10),
+		AllowedDestinations: []types.UnlockHash{allowed},
+	}
+
+	// The policy can only be set with the master key.
+	if err := wt.wallet.SetSpendingPolicy(crypto.GenerateSiaKey(crypto.TypeDefaultWallet), policy); !errors.Contains(err, modules.ErrBadEncryptionKey) {
+		t.Fatal("expected ErrBadEncryptionKey, got", err)
+	}
+	if err := wt.wallet.SetSpendingPolicy(wt.walletMasterKey, policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// Coins can't be sent to other addresses.
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{2}); !errors.Contains(err, modules.ErrDestinationNotAllowed) {
+		t.Fatal("expected ErrDestinationNotAllowed, got", err)
+	}
+	if _, err := wt.wallet.SendSiafunds(types.NewCurrency64(1), types.UnlockHash{2}); !errors.Contains(err, modules.ErrDestinationNotAllowed) {
+		t.Fatal("expected ErrDestinationNotAllowed, got", err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(5), allowed); err != nil {
+		t.Fatal(err)
+	}
+
+	// Transactions can't be signed since they could send the coins anywhere.
+	if err := wt.wallet.SignTransaction(&types.Transaction{}, nil); !errors.Contains(err, modules.ErrSigningRestricted) {
+		t.Fatal("expected ErrSigningRestricted, got", err)
+	}
+
+	// Spends above the threshold need to be approved.
+	_, err = wt.wallet.SendSiacoinsMulti([]types.SiacoinOutput{
+		{Value: types.SiacoinPrecision.Mul64(6), UnlockHash: allowed},
+		{Value: types.SiacoinPrecision.Mul64(6), UnlockHash: allowed},
+	})
+	pse, ok := err.(modules.PendingSpendError)
+	if !ok {
+		t.Fatal("expected PendingSpendError, got", err)
+	}
+	if _, err := wt.wallet.SendSiacoinsFeeIncluded(types.SiacoinPrecision.Mul64(20), allowed); err == nil {
+		t.Fatal("spend above the threshold was sent")
+	}
+	pending, err := wt.wallet.PendingSpends()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pending) != 2 || pending[0].ID != pse.ID || len(pending[0].Outputs) != 2 || !pending[1].FeeIncluded {
+		t.Fatal("unexpected pending spends", pending)
+	}
+
+	// Reject the second spend and approve the first one.
+	if err := wt.wallet.RejectSpend(pending[1].ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.ApproveSpend(crypto.GenerateSiaKey(crypto.TypeDefaultWallet), pse.ID); !errors.Contains(err, modules.ErrBadEncryptionKey) {
+		t.Fatal("expected ErrBadEncryptionKey, got", err)
+	}
+	txns, err := wt.wallet.ApproveSpend(wt.walletMasterKey, pse.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txns) == 0 {
+		t.Fatal("no transactions were returned")
+	}
+	if _, err := wt.wallet.ApproveSpend(wt.walletMasterKey, pse.ID); !errors.Contains(err, modules.ErrPendingSpendNotFound) {
+		t.Fatal("expected ErrPendingSpendNotFound, got", err)
+	}
+	if pending, err := wt.wallet.PendingSpends(); err != nil || len(pending) != 0 {
+		t.Fatal("unexpected pending spends", pending, err)
+	}
+
+	// 17 SC have been sent, 9 more spends of 9 SC reach 98 SC and the next one
+	// exceeds the daily limit.
+	for i := 0; i < 9; i++ {
+		if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(9), allowed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(9), allowed); !errors.Contains(err, modules.ErrDailyLimitExceeded) {
+		t.Fatal("expected ErrDailyLimitExceeded, got", err)
+	}
+
+	// The policy and the spends are persisted.
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	w, err := New(wt.cs, wt.tpool, wt.wallet.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet = w
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	p, err := wt.wallet.SpendingPolicy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !p.DailyLimit.Equals(policy.DailyLimit) || len(p.AllowedDestinations) != 1 || p.AllowedDestinations[0] != allowed {
+		t.Fatal("policy wasn't persisted", p)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(9), allowed); !errors.Contains(err, modules.ErrDailyLimitExceeded) {
+		t.Fatal("expected ErrDailyLimitExceeded, got", err)
+	}
+
+	// Removing the limits of the wallet allows all spends and signing again.
+	// The allowance limits don't restrict the wallet.
+	policy = modules.SpendingPolicy{MaxAllowanceFunds: types.SiacoinPrecision}
+	if err := wt.wallet.SetSpendingPolicy(wt.walletMasterKey, policy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(20), types.UnlockHash{2}); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SignTransaction(&types.Transaction{}, nil); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -116,6 +116,10 @@ type Wallet struct {
 	// defragDisabled determines if the wallet is set to defrag outputs once it
 	// reaches a certain threshold
 	defragDisabled bool
+
+	// staticSpendingPolicy limits the siacoins sent by the wallet. It is
+	// protected by its own mutex.
+	staticSpendingPolicy spendingPolicy
 }
 
 // Height return the internal processed consensus height of the wallet
@@ -0,0 +1,763 @@
+package api
+
+// alerts.go contains the alert history and the alert hooks. The modules only
+// keep their currently registered alerts in memory. The alert monitor polls
+// them, records when alerts were registered and unregistered in a persistent
+// history and pushes these events to the registered hooks. A hook is either a
+// webhook URL which receives the event as JSON in a POST request or a local
+// command which receives the event as JSON on stdin. Command hooks can only be
+// set in the config file since an API password must not allow running commands
+// on the machine.
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+)
+
+const (
+	// AlertsFile is the name of the file the alert history and hooks are
+	// persisted to.
+	AlertsFile = "alerts.json"
+
+	// AlertEventRegistered is the event of an alert being registered.
+	AlertEventRegistered = "registered"
+
+	// AlertEventUnregistered is the event of an alert being unregistered.
+	AlertEventUnregistered = "unregistered"
+
+	// alertEventQueueSize is the number of events that can be queued for
+	// delivery before new events are dropped.
+	alertEventQueueSize = 1000
+
+	// alertHistoryLimit is the maximum number of alerts in the history. Once
+	// it's reached, the oldest unregistered alerts are removed.
+	alertHistoryLimit = 1000
+
+	// alertHookAttempts is the number of times the delivery of an event to a
+	// hook is attempted.
+	alertHookAttempts = 3
+)
+
+var (
+	// alertPollInterval is the interval at which the alerts of the modules
+	// are polled.
+	alertPollInterval = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: 10 * time.Second,
+		Testnet:  10 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// alertHookTimeout is the timeout for delivering an event to a hook.
+	alertHookTimeout = build.Select(build.Var{
+		Dev:      30 * time.Second,
+		Standard: 30 * time.Second,
+		Testnet:  30 * time.Second,
+		Testing:  5 * time.Second,
+	}).(time.Duration)
+
+	// alertHookRetryInterval is the time between two delivery attempts.
+	alertHookRetryInterval = build.Select(build.Var{
+		Dev:      5 * time.Second,
+		Standard: 30 * time.Second,
+		Testnet:  30 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// errAlertHookCommand is returned when a command hook is added through the
+	// API.
+	errAlertHookCommand = errors.New("command hooks can only be set in the config file")
+
+	// alertsMetadata is the header of the alerts file.
+	alertsMetadata = persist.Metadata{
+		Header:  "Alerts",
+		Version: "1.0.0",
+	}
+)
+
+type (
+	// AlertHistoryEntry is an alert of the alert history.
+	AlertHistoryEntry struct {
+		modules.Alert
+		FirstSeen time.Time `json:"firstseen"`
+		// Cleared is the time the alert was unregistered. It's zero for alerts
+		// that are still registered.
+		Cleared time.Time `json:"cleared"`
+	}
+
+	// AlertEvent is the event that is pushed to alert hooks.
+	AlertEvent struct {
+		Event     string            `json:"event"`
+		Timestamp time.Time         `json:"timestamp"`
+		Alert     AlertHistoryEntry `json:"alert"`
+	}
+
+	// AlertHook is a webhook or command that alert events are pushed to.
+	AlertHook struct {
+		Name    string `json:"name"`
+		URL     string `json:"url,omitempty"`
+		Command string `json:"command,omitempty"`
+		// Modules and Severities filter the events that are pushed to the
+		// hook. Empty filters match all alerts.
+		Modules    []string                `json:"modules"`
+		Severities []modules.AlertSeverity `json:"severities"`
+
+		// Config is true for hooks of the config file. They can only be
+		// changed in the config file and aren't persisted.
+		Config bool `json:"config"`
+
+		LastDelivery time.Time `json:"lastdelivery"`
+		LastError    string    `json:"lasterror,omitempty"`
+	}
+
+	// DaemonAlertsHistoryGET contains the alert history.
+	DaemonAlertsHistoryGET struct {
+		History []AlertHistoryEntry `json:"history"`
+	}
+
+	// DaemonAlertsHooksGET contains the alert hooks.
+	DaemonAlertsHooksGET struct {
+		Hooks []AlertHook `json:"hooks"`
+	}
+
+	// persistAlerts is the persisted state of the alert monitor.
+	persistAlerts struct {
+		History []AlertHistoryEntry `json:"history"`
+		Hooks   []AlertHook         `json:"hooks"`
+	}
+
+	// alertMonitor keeps track of the alert history and pushes alert events
+	// to hooks.
+	alertMonitor struct {
+		active  map[string]*AlertHistoryEntry
+		history []*AlertHistoryEntry
+		hooks   map[string]*AlertHook
+		path    string
+
+		started      bool
+		cancel       context.CancelFunc
+		staticEvents chan AlertEvent
+		wg           sync.WaitGroup
+		mu           sync.Mutex
+	}
+)
+
+// alertKey returns the key that identifies an alert in the history. Alerts
+// without an ID are identified by their message.
+func alertKey(a modules.Alert) string {
+	if a.ID == "" {
+		return fmt.Sprintf("%v|%v|%v", a.Module, a.Severity, a.Msg)
+	}
+	return a.Module + "|" + string(a.ID)
+}
+
+// parseAlertSeverity parses the string representation of a severity.
+func parseAlertSeverity(s string) (modules.AlertSeverity, error) {
+	var severity modules.AlertSeverity
+	err := severity.UnmarshalJSON([]byte(fmt.Sprintf("%q", s)))
+	return severity, err
+}
+
+// matches returns whether an alert passes the filters of the hook.
+func (h AlertHook) matches(a modules.Alert) bool {
+	moduleMatch := len(h.Modules) == 0
+	for _, m := range h.Modules {
+		moduleMatch = moduleMatch || m == a.Module
+	}
+	severityMatch := len(h.Severities) == 0
+	for _, s := range h.Severities {
+		severityMatch = severityMatch || s == a.Severity
+	}
+	return moduleMatch && severityMatch
+}
+
+// newAlertMonitor creates a new alert monitor.
+func newAlertMonitor() *alertMonitor {
+	return &alertMonitor{
+		active:       make(map[string]*AlertHistoryEntry),
+		hooks:        make(map[string]*AlertHook),
+		staticEvents: make(chan AlertEvent, alertEventQueueSize),
+	}
+}
+
+// load loads the alert history and the hooks from disk. Subsequent changes
+// are persisted to the same path.
+func (am *alertMonitor) load(path string) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	am.path = path
+	var p persistAlerts
+	err := persist.LoadJSON(alertsMetadata, &p, path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.AddContext(err, "failed to load alerts")
+	}
+	am.history = make([]*AlertHistoryEntry, 0, len(p.History))
+	am.active = make(map[string]*AlertHistoryEntry)
+	for i := range p.History {
+		entry := &p.History[i]
+		am.history = append(am.history, entry)
+		if entry.Cleared.IsZero() {
+			am.active[alertKey(entry.Alert)] = entry
+		}
+	}
+	am.hooks = make(map[string]*AlertHook)
+	for i := range p.Hooks {
+		// Command hooks that were added through the API by older versions are
+		// dropped. They need to be set in the config file.
+		if p.Hooks[i].Command != "" || p.Hooks[i].Config {
+			continue
+		}
+		am.hooks[p.Hooks[i].Name] = &p.Hooks[i]
+	}
+	return nil
+}
+
+// save persists the alert history and the hooks if the monitor has a path.
+func (am *alertMonitor) save() error {
+	if am.path == "" {
+		return nil
+	}
+	var p persistAlerts
+	for _, entry := range am.history {
+		p.History = append(p.History, *entry)
+	}
+	// The hooks of the config file are set again on startup.
+	p.Hooks = []AlertHook{}
+	for _, h := range am.hookList() {
+		if !h.Config {
+			p.Hooks = append(p.Hooks, h)
+		}
+	}
+	return persist.SaveJSON(alertsMetadata, p, am.path)
+}
+
+// hookList returns the hooks sorted by name.
+func (am *alertMonitor) hookList() []AlertHook {
+	hooks := make([]AlertHook, 0, len(am.hooks))
+	for _, h := range am.hooks {
+		hooks = append(hooks, *h)
+	}
+	sort.Slice(hooks, func(i, j int) bool {
+		return hooks[i].Name < hooks[j].Name
+	})
+	return hooks
+}
+
+// managedUpdate compares the currently registered alerts to the active alerts
+// of the history, updates the history and queues the resulting events.
+func (am *alertMonitor) managedUpdate(alerts []modules.Alert, now time.Time) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	var events []AlertEvent
+	seen := make(map[string]struct{}, len(alerts))
+	for _, a := range alerts {
+		key := alertKey(a)
+		seen[key] = struct{}{}
+		if entry, exists := am.active[key]; exists {
+			// Keep track of changes to the cause or message of the alert.
+			entry.Alert = a
+			continue
+		}
+		entry := &AlertHistoryEntry{
+			Alert:     a,
+			FirstSeen: now,
+		}
+		am.active[key] = entry
+		am.history = append(am.history, entry)
+		events = append(events, AlertEvent{
+			Event:     AlertEventRegistered,
+			Timestamp: now,
+			Alert:     *entry,
+		})
+	}
+	for key, entry := range am.active {
+		if _, exists := seen[key]; exists {
+			continue
+		}
+		entry.Cleared = now
+		delete(am.active, key)
+		events = append(events, AlertEvent{
+			Event:     AlertEventUnregistered,
+			Timestamp: now,
+			Alert:     *entry,
+		})
+	}
+	if len(events) == 0 {
+		return nil
+	}
+
+	// Queue the events without blocking the monitor.
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Event == AlertEventUnregistered && events[j].Event != AlertEventUnregistered
+	})
+	dropped := 0
+	for _, e := range events {
+		select {
+		case am.staticEvents <- e:
+		default:
+			dropped++
+		}
+	}
+	// Record dropped events in the history since the hooks never receive
+	// them.
+	if dropped > 0 {
+		am.history = append(am.history, &AlertHistoryEntry{
+			Alert: modules.Alert{
+				Module:   "daemon",
+				Msg:      fmt.Sprintf("%v alert events weren't pushed to the alert hooks because the queue was full", dropped),
+				Severity: modules.SeverityError,
+			},
+			FirstSeen: now,
+			Cleared:   now,
+		})
+	}
+
+	// Remove the oldest unregistered alerts if the history is full.
+	for i := 0; len(am.history) > alertHistoryLimit && i < len(am.history); {
+		if am.history[i].Cleared.IsZero() {
+			i++
+			continue
+		}
+		am.history = append(am.history[:i], am.history[i+1:]...)
+	}
+	return am.save()
+}
+
+// managedStart starts polling the alerts returned by fetch and delivering the
+// events to the hooks.
+func (am *alertMonitor) managedStart(fetch func() []modules.Alert) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if am.started {
+		build.Critical("alert monitor was started twice")
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	am.started = true
+	am.cancel = cancel
+	am.wg.Add(2)
+	go am.threadedMonitor(ctx, fetch)
+	go am.threadedDeliverEvents(ctx)
+}
+
+// managedStop stops the monitor and persists its state.
+func (am *alertMonitor) managedStop() error {
+	am.mu.Lock()
+	started := am.started
+	am.mu.Unlock()
+	if started {
+		am.cancel()
+		am.wg.Wait()
+	}
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	return am.save()
+}
+
+// threadedMonitor polls the alerts until the context is closed.
+func (am *alertMonitor) threadedMonitor(ctx context.Context, fetch func() []modules.Alert) {
+	defer am.wg.Done()
+	ticker := time.NewTicker(alertPollInterval)
+	defer ticker.Stop()
+	for {
+		// Errors are ignored since the history is persisted again on the
+		// next change and on shutdown.
+		_ = am.managedUpdate(fetch(), time.Now())
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// threadedDeliverEvents delivers the queued events to the hooks in order until
+// the context is closed.
+func (am *alertMonitor) threadedDeliverEvents(ctx context.Context) {
+	defer am.wg.Done()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e := <-am.staticEvents:
+			am.mu.Lock()
+			hooks := am.hookList()
+			am.mu.Unlock()
+			for _, h := range hooks {
+				if !h.matches(e.Alert.Alert) {
+					continue
+				}
+				err := managedDeliverAlertEvent(ctx, h, e)
+				am.mu.Lock()
+				if hook, exists := am.hooks[h.Name]; exists {
+					hook.LastDelivery = time.Now()
+					hook.LastError = ""
+					if err != nil {
+						hook.LastError = err.Error()
+					}
+				}
+				am.mu.Unlock()
+			}
+		}
+	}
+}
+
+// managedDeliverAlertEvent delivers an event to a hook. Failed deliveries are
+// retried.
+func managedDeliverAlertEvent(ctx context.Context, h AlertHook, e AlertEvent) error {
+	payload, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	for attempt := 1; ; attempt++ {
+		attemptCtx, cancel := context.WithTimeout(ctx, alertHookTimeout)
+		if h.URL != "" {
+			err = deliverAlertWebhook(attemptCtx, h.URL, payload)
+		} else {
+			err = deliverAlertCommand(attemptCtx, h.Command, e, payload)
+		}
+		cancel()
+		if err == nil || attempt == alertHookAttempts {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return errors.Compose(err, ctx.Err())
+		case <-time.After(alertHookRetryInterval):
+		}
+	}
+}
+
+// deliverAlertWebhook posts the JSON encoded event to a webhook.
+func deliverAlertWebhook(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sia-Agent")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %v", resp.Status)
+	}
+	return nil
+}
+
+// deliverAlertCommand runs a command with the JSON encoded event on stdin.
+// The most important fields of the event are also passed as environment
+// variables.
+func deliverAlertCommand(ctx context.Context, command string, e AlertEvent, payload []byte) error {
+	args := strings.Fields(command)
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = append(os.Environ(),
+		"SIA_ALERT_EVENT="+e.Event,
+		"SIA_ALERT_ID="+string(e.Alert.ID),
+		"SIA_ALERT_MODULE="+e.Alert.Module,
+		"SIA_ALERT_SEVERITY="+e.Alert.Severity.String(),
+		"SIA_ALERT_MSG="+e.Alert.Msg,
+		"SIA_ALERT_CAUSE="+e.Alert.Cause,
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		return errors.AddContext(err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// History returns the alert history filtered by module and severity, the most
+// recent alerts first. Empty filters match all alerts.
+func (am *alertMonitor) History(module string, severity modules.AlertSeverity) []AlertHistoryEntry {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	history := make([]AlertHistoryEntry, 0, len(am.history))
+	for i := len(am.history) - 1; i >= 0; i-- {
+		entry := am.history[i]
+		if module != "" && entry.Module != module {
+			continue
+		}
+		if severity != modules.SeverityUnknown && entry.Severity != severity {
+			continue
+		}
+		history = append(history, *entry)
+	}
+	return history
+}
+
+// Hooks returns the alert hooks sorted by name.
+func (am *alertMonitor) Hooks() []AlertHook {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	return am.hookList()
+}
+
+// validate checks the name, target and filters of the hook.
+func (h AlertHook) validate() error {
+	if h.Name == "" {
+		return errors.New("hook name can't be empty")
+	}
+	if (h.URL == "") == (h.Command == "") {
+		return errors.New("hook needs either a url or a command")
+	}
+	if h.URL != "" {
+		u, err := url.Parse(h.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("invalid webhook url '%v'", h.URL)
+		}
+	}
+	if h.Command != "" && len(strings.Fields(h.Command)) == 0 {
+		return errors.New("hook command can't be empty")
+	}
+	for _, s := range h.Severities {
+		if s == modules.SeverityUnknown || s > modules.SeverityCritical {
+			return errors.New("invalid severity")
+		}
+	}
+	return nil
+}
+
+// AddHook adds a new webhook. Command hooks can only be set in the config file.
+func (am *alertMonitor) AddHook(h AlertHook) error {
+	if h.Command != "" {
+		return errAlertHookCommand
+	}
+	if err := h.validate(); err != nil {
+		return err
+	}
+	h.Config = false
+	h.LastDelivery = time.Time{}
+	h.LastError = ""
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	if _, exists := am.hooks[h.Name]; exists {
+		return fmt.Errorf("hook '%v' already exists", h.Name)
+	}
+	am.hooks[h.Name] = &h
+	err := am.save()
+	if err != nil {
+		delete(am.hooks, h.Name)
+		return errors.AddContext(err, "failed to persist hook")
+	}
+	return nil
+}
+
+// RemoveHook removes an alert hook.
+func (am *alertMonitor) RemoveHook(name string) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	h, exists := am.hooks[name]
+	if !exists {
+		return fmt.Errorf("hook '%v' doesn't exist", name)
+	}
+	if h.Config {
+		return fmt.Errorf("hook '%v' is set in the config file", name)
+	}
+	delete(am.hooks, name)
+	err := am.save()
+	if err != nil {
+		am.hooks[name] = h
+		return errors.AddContext(err, "failed to persist hook removal")
+	}
+	return nil
+}
+
+// ConfigHooks returns the hooks of the config file sorted by name.
+func (am *alertMonitor) ConfigHooks() []AlertHook {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	var hooks []AlertHook
+	for _, h := range am.hookList() {
+		if h.Config {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// SetConfigHooks replaces the hooks of the config file. Unlike the hooks added
+// through the API they can run commands.
+func (am *alertMonitor) SetConfigHooks(hooks []AlertHook) error {
+	names := make(map[string]struct{}, len(hooks))
+	for _, h := range hooks {
+		if err := h.validate(); err != nil {
+			return errors.AddContext(err, fmt.Sprintf("invalid hook '%v'", h.Name))
+		}
+		if _, exists := names[h.Name]; exists {
+			return fmt.Errorf("hook '%v' exists twice", h.Name)
+		}
+		names[h.Name] = struct{}{}
+	}
+
+	am.mu.Lock()
+	defer am.mu.Unlock()
+	for _, h := range hooks {
+		if existing, exists := am.hooks[h.Name]; exists && !existing.Config {
+			return fmt.Errorf("hook '%v' already exists", h.Name)
+		}
+	}
+	for name, h := range am.hooks {
+		if h.Config {
+			delete(am.hooks, name)
+		}
+	}
+	for i := range hooks {
+		h := hooks[i]
+		h.Config = true
+		h.LastDelivery = time.Time{}
+		h.LastError = ""
+		am.hooks[h.Name] = &h
+	}
+	return nil
+}
+
+// managedAlerts returns the registered alerts of all loaded modules.
+func (api *API) managedAlerts() (crit, err, warn, info []modules.Alert) {
+	alerters := []modules.Alerter{}
+	if api.gateway != nil {
+		alerters = append(alerters, api.gateway)
+	}
+	if api.cs != nil {
+		alerters = append(alerters, api.cs)
+	}
+	if api.tpool != nil {
+		alerters = append(alerters, api.tpool)
+	}
+	if api.wallet != nil {
+		alerters = append(alerters, api.wallet)
+	}
+	if api.renter != nil {
+		alerters = append(alerters, api.renter)
+	}
+	if api.host != nil {
+		alerters = append(alerters, api.host)
+	}
+	for _, alerter := range alerters {
+		c, e, w, i := alerter.Alerts()
+		crit = append(crit, c...)
+		err = append(err, e...)
+		warn = append(warn, w...)
+		info = append(info, i...)
+	}
+	return
+}
+
+// LoadAlerts loads the alert history and the alert hooks from the provided
+// path.
+func (api *API) LoadAlerts(path string) error {
+	return api.staticAlerts.load(path)
+}
+
+// StartAlertMonitor starts recording the alerts of the loaded modules and
+// pushing them to the alert hooks. It must be called after the modules were
+// set.
+func (api *API) StartAlertMonitor() {
+	api.staticAlerts.managedStart(func() []modules.Alert {
+		crit, err, warn, info := api.managedAlerts()
+		return append(append(crit, err...), append(warn, info...)...)
+	})
+}
+
+// StopAlertMonitor stops the alert monitor and persists the alert history.
+func (api *API) StopAlertMonitor() error {
+	return api.staticAlerts.managedStop()
+}
+
+// daemonAlertsHistoryHandlerGET handles the API call that returns the alert
+// history.
+func (api *API) daemonAlertsHistoryHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var severity modules.AlertSeverity
+	if s := req.FormValue("severity"); s != "" {
+		var err error
+		severity, err = parseAlertSeverity(s)
+		if err != nil {
+			WriteError(w, Error{"unable to parse severity: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	WriteJSON(w, DaemonAlertsHistoryGET{
+		History: api.staticAlerts.History(req.FormValue("module"), severity),
+	})
+}
+
+// daemonAlertsHooksHandlerGET handles the API call that lists the alert
+// hooks.
+func (api *API) daemonAlertsHooksHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, DaemonAlertsHooksGET{
+		Hooks: api.staticAlerts.Hooks(),
+	})
+}
+
+// daemonAlertsHooksAddHandlerPOST handles the API call that adds an alert
+// hook.
+func (api *API) daemonAlertsHooksAddHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if req.FormValue("command") != "" {
+		WriteError(w, Error{"failed to add hook: " + errAlertHookCommand.Error()}, http.StatusBadRequest)
+		return
+	}
+	hook := AlertHook{
+		Name:       req.FormValue("name"),
+		URL:        req.FormValue("url"),
+		Modules:    []string{},
+		Severities: []modules.AlertSeverity{},
+	}
+	if m := req.FormValue("modules"); m != "" {
+		for _, module := range strings.Split(m, ",") {
+			hook.Modules = append(hook.Modules, strings.TrimSpace(module))
+		}
+	}
+	if s := req.FormValue("severities"); s != "" {
+		for _, str := range strings.Split(s, ",") {
+			severity, err := parseAlertSeverity(strings.TrimSpace(str))
+			if err != nil {
+				WriteError(w, Error{"unable to parse severities: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			hook.Severities = append(hook.Severities, severity)
+		}
+	}
+	if err := api.staticAlerts.AddHook(hook); err != nil {
+		WriteError(w, Error{"failed to add hook: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// daemonAlertsHooksRemoveHandlerPOST handles the API call that removes an
+// alert hook.
+func (api *API) daemonAlertsHooksRemoveHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.staticAlerts.RemoveHook(req.FormValue("name")); err != nil {
+		WriteError(w, Error{"failed to remove hook: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
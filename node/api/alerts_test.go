@@ -0,0 +1,327 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/persist"
+)
+
+// TestAlertMonitorUpdate tests recording the alert history.
+func TestAlertMonitorUpdate(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, AlertsFile)
+
+	am := newAlertMonitor()
+	if err := am.load(path); err != nil {
+		t.Fatal(err)
+	}
+	disk := modules.Alert{ID: modules.AlertIDHostDiskTrouble, Module: "contractmanager", Msg: "disk trouble", Severity: modules.SeverityCritical}
+	offline := modules.Alert{ID: modules.AlertIDGatewayOffline, Module: "gateway", Msg: "offline", Severity: modules.SeverityWarning}
+
+	start := time.Now()
+	if err := am.managedUpdate([]modules.Alert{disk, offline}, start); err != nil {
+		t.Fatal(err)
+	}
+	// Changing the cause of an alert doesn't create a new entry.
+	offline.Cause = "no peers"
+	if err := am.managedUpdate([]modules.Alert{disk, offline}, start.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.managedUpdate([]modules.Alert{offline}, start.Add(2*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	history := am.History("", modules.SeverityUnknown)
+	if len(history) != 2 {
+		t.Fatal("unexpected history", history)
+	}
+	if history[1].ID != disk.ID || !history[1].FirstSeen.Equal(start) || !history[1].Cleared.Equal(start.Add(2*time.Second)) {
+		t.Fatal("unexpected entry", history[1])
+	}
+	if history[0].Cause != "no peers" || !history[0].Cleared.IsZero() {
+		t.Fatal("unexpected entry", history[0])
+	}
+	if h := am.History("gateway", modules.SeverityUnknown); len(h) != 1 || h[0].Module != "gateway" {
+		t.Fatal("module filter failed", h)
+	}
+	if h := am.History("", modules.SeverityCritical); len(h) != 1 || h[0].Severity != modules.SeverityCritical {
+		t.Fatal("severity filter failed", h)
+	}
+
+	// Check the queued events.
+	var events []string
+	for len(am.staticEvents) > 0 {
+		e := <-am.staticEvents
+		events = append(events, e.Event+" "+e.Alert.Module)
+	}
+	expected := []string{"registered contractmanager", "registered gateway", "unregistered contractmanager"}
+	if len(events) != len(expected) {
+		t.Fatal("unexpected events", events)
+	}
+	for i := range events {
+		if events[i] != expected[i] {
+			t.Fatal("unexpected events", events)
+		}
+	}
+
+	// Load the history. The alert that is still registered isn't registered
+	// again and is cleared once it's gone.
+	am2 := newAlertMonitor()
+	if err := am2.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := am2.managedUpdate([]modules.Alert{offline}, start.Add(3*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if len(am2.staticEvents) != 0 || len(am2.History("", modules.SeverityUnknown)) != 2 {
+		t.Fatal("reloaded alert shouldn't be registered again")
+	}
+	if err := am2.managedUpdate(nil, start.Add(4*time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	if e := <-am2.staticEvents; e.Event != AlertEventUnregistered || e.Alert.Module != "gateway" {
+		t.Fatal("unexpected event", e)
+	}
+}
+
+// TestAlertHooks tests adding alert hooks and delivering events to them.
+func TestAlertHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a webhook that records the events and fails the first request.
+	var mu sync.Mutex
+	var received []AlertEvent
+	requests := 0
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		var e AlertEvent
+		if err := json.NewDecoder(req.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		received = append(received, e)
+	}))
+	defer srv.Close()
+
+	am := newAlertMonitor()
+	invalid := []AlertHook{
+		{URL: srv.URL},
+		{Name: "both", URL: srv.URL, Command: "true"},
+		{Name: "none"},
+		{Name: "ftp", URL: "ftp://example.com"},
+		{Name: "severity", URL: srv.URL, Severities: []modules.AlertSeverity{modules.SeverityUnknown}},
+	}
+	for _, h := range invalid {
+		if err := am.AddHook(h); err == nil {
+			t.Fatal("invalid hook was added", h)
+		}
+	}
+	if err := am.AddHook(AlertHook{Name: "web", URL: srv.URL, Modules: []string{"contractmanager"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddHook(AlertHook{Name: "web", URL: srv.URL}); err == nil {
+		t.Fatal("hook names should be unique")
+	}
+	// Command hooks can only be set in the config file.
+	out := filepath.Join(dir, "out.json")
+	cmd := AlertHook{Name: "cmd", Command: "tee " + out, Severities: []modules.AlertSeverity{modules.SeverityWarning}}
+	if err := am.AddHook(cmd); !errors.Contains(err, errAlertHookCommand) {
+		t.Fatal("command hook shouldn't be added through the API", err)
+	}
+	if err := am.SetConfigHooks([]AlertHook{cmd, {Name: "web", URL: srv.URL}}); err == nil {
+		t.Fatal("config hook shouldn't replace an API hook")
+	}
+	if err := am.SetConfigHooks([]AlertHook{cmd}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Start the monitor with alerts that can be changed by the test.
+	var alerts []modules.Alert
+	am.managedStart(func() []modules.Alert {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]modules.Alert{}, alerts...)
+	})
+	defer func() {
+		if err := am.managedStop(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	mu.Lock()
+	alerts = []modules.Alert{
+		{ID: modules.AlertIDHostDiskTrouble, Module: "contractmanager", Msg: "disk trouble", Severity: modules.SeverityCritical},
+		{ID: modules.AlertIDGatewayOffline, Module: "gateway", Msg: "offline", Severity: modules.SeverityWarning},
+	}
+	mu.Unlock()
+
+	// The webhook only receives the contractmanager alert after a retry.
+	err := build.Retry(100, 100*time.Millisecond, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(received) != 1 {
+			return errors.New("event wasn't received")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if e := received[0]; e.Event != AlertEventRegistered || e.Alert.Module != "contractmanager" || requests != 2 {
+		t.Fatal("unexpected event", e, requests)
+	}
+	alerts = alerts[1:]
+	mu.Unlock()
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		mu.Lock()
+		defer mu.Unlock()
+		if len(received) != 2 {
+			return errors.New("event wasn't received")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	if e := received[1]; e.Event != AlertEventUnregistered || e.Alert.Cleared.IsZero() {
+		t.Fatal("unexpected event", e)
+	}
+	mu.Unlock()
+
+	// The command only receives the warning.
+	raw, err := os.ReadFile(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var e AlertEvent
+	if err := json.Unmarshal(raw, &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Event != AlertEventRegistered || e.Alert.Module != "gateway" {
+		t.Fatal("unexpected event", e)
+	}
+	for _, h := range am.Hooks() {
+		if h.LastDelivery.IsZero() || h.LastError != "" {
+			t.Fatal("unexpected hook state", h)
+		}
+	}
+
+	// Remove the hooks.
+	if err := am.RemoveHook("web"); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.RemoveHook("web"); err == nil {
+		t.Fatal("removing a hook twice should fail")
+	}
+	if err := am.RemoveHook("cmd"); err == nil {
+		t.Fatal("config hooks shouldn't be removed through the API")
+	}
+	if hooks := am.Hooks(); len(hooks) != 1 || hooks[0].Name != "cmd" || !hooks[0].Config {
+		t.Fatal("unexpected hooks", hooks)
+	}
+}
+
+// TestAlertConfigHooks tests that the hooks of the config file aren't
+// persisted and that command hooks persisted by older versions are dropped.
+func TestAlertConfigHooks(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, AlertsFile)
+
+	am := newAlertMonitor()
+	if err := am.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.AddHook(AlertHook{Name: "web", URL: "http://localhost:1234"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := am.SetConfigHooks([]AlertHook{{Name: "cmd", Command: "true"}, {Name: "cmd", Command: "false"}}); err == nil {
+		t.Fatal("config hook names should be unique")
+	}
+	if err := am.SetConfigHooks([]AlertHook{{Name: "cmd", Command: "true"}}); err != nil {
+		t.Fatal(err)
+	}
+	if hooks := am.ConfigHooks(); len(hooks) != 1 || hooks[0].Name != "cmd" {
+		t.Fatal("unexpected config hooks", hooks)
+	}
+	if err := am.managedStop(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the webhook is persisted.
+	am2 := newAlertMonitor()
+	if err := am2.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if hooks := am2.Hooks(); len(hooks) != 1 || hooks[0].Name != "web" {
+		t.Fatal("unexpected hooks", hooks)
+	}
+
+	// Command hooks of the alerts file are dropped.
+	p := persistAlerts{Hooks: []AlertHook{{Name: "cmd", Command: "true"}, {Name: "web", URL: "http://localhost:1234"}}}
+	if err := persist.SaveJSON(alertsMetadata, p, path); err != nil {
+		t.Fatal(err)
+	}
+	am3 := newAlertMonitor()
+	if err := am3.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if hooks := am3.Hooks(); len(hooks) != 1 || hooks[0].Name != "web" {
+		t.Fatal("unexpected hooks", hooks)
+	}
+}
+
+// TestAlertEventsDropped tests that dropped events are recorded in the
+// history.
+func TestAlertEventsDropped(t *testing.T) {
+	t.Parallel()
+	am := newAlertMonitor()
+
+	// Fill the queue.
+	for i := 0; i < alertEventQueueSize; i++ {
+		am.staticEvents <- AlertEvent{}
+	}
+	alerts := []modules.Alert{
+		{ID: modules.AlertIDGatewayOffline, Module: "gateway", Msg: "offline", Severity: modules.SeverityWarning},
+		{ID: modules.AlertIDHostDiskTrouble, Module: "contractmanager", Msg: "disk trouble", Severity: modules.SeverityCritical},
+	}
+	if err := am.managedUpdate(alerts, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	history := am.History("daemon", modules.SeverityError)
+	if len(history) != 1 || !strings.HasPrefix(history[0].Msg, "2 alert events") || history[0].Cleared.IsZero() {
+		t.Fatal("dropped events weren't recorded", history)
+	}
+}
@@ -130,6 +130,11 @@ type (
 		siadConfig        *modules.SiadConfig
 
 		staticStartTime time.Time
+		staticTokens    *tokenStore
+		staticAlerts    *alertMonitor
+
+		configFile string
+		configMu   sync.Mutex
 
 		staticDeps modules.Dependencies
 	}
@@ -220,6 +225,8 @@ func NewCustom(cfg *modules.SiadConfig, requiredUserAgent string, requiredPasswo
 
 		staticDeps:      deps,
 		staticStartTime: time.Now(),
+		staticTokens:    newTokenStore(),
+		staticAlerts:    newAlertMonitor(),
 	}
 
 	// Register API handlers
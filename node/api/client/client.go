@@ -2,6 +2,7 @@ package client
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -19,6 +20,9 @@ type (
 	// A Client makes requests to the siad HTTP API.
 	Client struct {
 		Options
+
+		// transport is the transport used for requests over TLS.
+		transport *clientTransport
 	}
 
 	// Options defines the options that are available when creating a
@@ -34,6 +38,10 @@ type (
 		// set, it defaults to "Sia-Agent".
 		UserAgent string
 
+		// TLSConfig enables connecting to the API over TLS if set. See
+		// NewTLSConfig.
+		TLSConfig *tls.Config
+
 		// CheckRedirect is an optional handler to be called if the request
 		// receives a redirect status code.
 		// For more see https://golang.org/pkg/net/http/#Client
@@ -79,7 +87,7 @@ func (uc *UnsafeClient) GetWithHeaders(resource string, headers http.Header) (*h
 		}
 	}
 
-	httpClient := http.Client{CheckRedirect: uc.CheckRedirect}
+	httpClient := uc.httpClient()
 	return httpClient.Do(req)
 }
 
@@ -88,7 +96,8 @@ func (uc *UnsafeClient) GetWithHeaders(resource string, headers http.Header) (*h
 // can be changed manually by the caller after the client is returned.
 func New(opts Options) *Client {
 	return &Client{
-		Options: opts,
+		Options:   opts,
+		transport: new(clientTransport),
 	}
 }
 
@@ -110,7 +119,11 @@ func DefaultOptions() (Options, error) {
 // NewRequest constructs a request to the siad HTTP API, setting the correct
 // User-Agent and Basic Auth. The resource path must begin with /.
 func (c *Client) NewRequest(method, resource string, body io.Reader) (*http.Request, error) {
-	url := "http://" + c.Address + resource
+	scheme := "http://"
+	if c.TLSConfig != nil {
+		scheme = "https://"
+	}
+	url := scheme + c.Address + resource
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, err
@@ -173,7 +186,7 @@ func (c *Client) getReaderResponse(resource string) (http.Header, io.ReadCloser,
 	if err != nil {
 		return nil, nil, errors.AddContext(err, "failed to construct GET request")
 	}
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return nil, nil, errors.AddContext(err, "GET request failed")
@@ -211,7 +224,7 @@ func (c *Client) getRawPartialResponse(resource string, from, to uint64) ([]byte
 	}
 	req.Header.Add("Range", fmt.Sprintf("bytes=%d-%d", from, to-1))
 
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return nil, errors.AddContext(err, "GET request failed")
@@ -268,7 +281,7 @@ func (c *Client) head(resource string) (int, http.Header, error) {
 	if err != nil {
 		return 0, nil, errors.AddContext(err, "failed to construct HEAD request")
 	}
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return 0, nil, errors.AddContext(err, "HEAD request failed")
@@ -302,7 +315,7 @@ func (c *Client) postRawResponseWithHeaders(resource string, body io.Reader, hea
 		}
 	}
 
-	httpClient := http.Client{CheckRedirect: c.CheckRedirect}
+	httpClient := c.httpClient()
 	res, err := httpClient.Do(req)
 	if err != nil {
 		return http.Header{}, nil, errors.AddContext(err, "POST request failed")
@@ -3,8 +3,12 @@ package client
 import (
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
+	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/node/api"
+	"go.sia.tech/siad/types"
 )
 
 // DaemonGlobalRateLimitPost uses the /daemon/settings endpoint to change the
@@ -18,18 +22,172 @@ func (c *Client) DaemonGlobalRateLimitPost(downloadSpeed, uploadSpeed int64) (er
 	return
 }
 
+// DaemonEnableMetricsPost uses the /daemon/settings endpoint to enable or
+// disable the /metrics endpoint.
+func (c *Client) DaemonEnableMetricsPost(enable bool) (err error) {
+	values := url.Values{}
+	values.Set("enablemetrics", strconv.FormatBool(enable))
+	err = c.post("/daemon/settings", values.Encode(), nil)
+	return
+}
+
 // DaemonAlertsGet requests the /daemon/alerts resource.
 func (c *Client) DaemonAlertsGet() (dag api.DaemonAlertsGet, err error) {
 	err = c.get("/daemon/alerts", &dag)
 	return
 }
 
+// DaemonAlertsHistoryGet requests the /daemon/alerts/history resource. The
+// history can be filtered by module and severity. Empty filters match all
+// alerts.
+func (c *Client) DaemonAlertsHistoryGet(module string, severity modules.AlertSeverity) (dahg api.DaemonAlertsHistoryGET, err error) {
+	values := url.Values{}
+	if module != "" {
+		values.Set("module", module)
+	}
+	if severity != modules.SeverityUnknown {
+		values.Set("severity", severity.String())
+	}
+	err = c.get("/daemon/alerts/history?"+values.Encode(), &dahg)
+	return
+}
+
+// DaemonAlertsHooksGet requests the /daemon/alerts/hooks resource.
+func (c *Client) DaemonAlertsHooksGet() (dahg api.DaemonAlertsHooksGET, err error) {
+	err = c.get("/daemon/alerts/hooks", &dahg)
+	return
+}
+
+// DaemonAlertsHooksAddPost uses the /daemon/alerts/hooks/add endpoint to add an
+// alert hook.
+func (c *Client) DaemonAlertsHooksAddPost(hook api.AlertHook) (err error) {
+	values := url.Values{}
+	values.Set("name", hook.Name)
+	if hook.URL != "" {
+		values.Set("url", hook.URL)
+	}
+	if len(hook.Modules) > 0 {
+		values.Set("modules", strings.Join(hook.Modules, ","))
+	}
+	var severities []string
+	for _, s := range hook.Severities {
+		severities = append(severities, s.String())
+	}
+	if len(severities) > 0 {
+		values.Set("severities", strings.Join(severities, ","))
+	}
+	err = c.post("/daemon/alerts/hooks/add", values.Encode(), nil)
+	return
+}
+
+// DaemonAlertsHooksRemovePost uses the /daemon/alerts/hooks/remove endpoint to
+// remove an alert hook.
+func (c *Client) DaemonAlertsHooksRemovePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/daemon/alerts/hooks/remove", values.Encode(), nil)
+	return
+}
+
+// DaemonTokensGet requests the /daemon/tokens resource.
+func (c *Client) DaemonTokensGet() (dtg api.DaemonTokensGET, err error) {
+	err = c.get("/daemon/tokens", &dtg)
+	return
+}
+
+// DaemonTokensCreatePost uses the /daemon/tokens/create endpoint to create a
+// new API token. A zero expiry means that the token doesn't expire and a zero
+// spending limit means that sends aren't limited.
+func (c *Client) DaemonTokensCreatePost(name string, scopes []string, expiry time.Duration, spendingLimit types.Currency) (dtcp api.DaemonTokensCreatePOST, err error) {
+	return c.DaemonTokensCreateTenantPost(name, "", scopes, expiry, spendingLimit)
+}
+
+// DaemonTokensCreateTenantPost uses the /daemon/tokens/create endpoint to
+// create a new API token for a tenant. The token can only access the files of
+// the tenant.
+func (c *Client) DaemonTokensCreateTenantPost(name, tenant string, scopes []string, expiry time.Duration, spendingLimit types.Currency) (dtcp api.DaemonTokensCreatePOST, err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	if tenant != "" {
+		values.Set("tenant", tenant)
+	}
+	values.Set("scopes", strings.Join(scopes, ","))
+	if expiry > 0 {
+		values.Set("expiry", expiry.String())
+	}
+	if !spendingLimit.IsZero() {
+		values.Set("spendinglimit", spendingLimit.String())
+	}
+	err = c.post("/daemon/tokens/create", values.Encode(), &dtcp)
+	return
+}
+
+// DaemonTokensRevokePost uses the /daemon/tokens/revoke endpoint to revoke an
+// API token.
+func (c *Client) DaemonTokensRevokePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/daemon/tokens/revoke", values.Encode(), nil)
+	return
+}
+
+// DaemonLoggingGet requests the /daemon/logging resource.
+func (c *Client) DaemonLoggingGet() (dlg api.DaemonLoggingGET, err error) {
+	err = c.get("/daemon/logging", &dlg)
+	return
+}
+
+// DaemonLoggingLevelPost uses the /daemon/logging endpoint to set the log
+// level of a module. An empty module sets the default level and an empty
+// level removes the level of the module.
+func (c *Client) DaemonLoggingLevelPost(module, level string) (err error) {
+	values := url.Values{}
+	if module != "" {
+		values.Set("module", module)
+	}
+	values.Set("level", level)
+	err = c.post("/daemon/logging", values.Encode(), nil)
+	return
+}
+
+// DaemonLoggingFormatPost uses the /daemon/logging endpoint to set the log
+// format, either 'text' or 'json'.
+func (c *Client) DaemonLoggingFormatPost(format string) (err error) {
+	values := url.Values{}
+	values.Set("format", format)
+	err = c.post("/daemon/logging", values.Encode(), nil)
+	return
+}
+
+// DaemonLoggingStdoutPost uses the /daemon/logging endpoint to enable or
+// disable writing the logs of all modules to stdout.
+func (c *Client) DaemonLoggingStdoutPost(stdout bool) (err error) {
+	values := url.Values{}
+	values.Set("stdout", strconv.FormatBool(stdout))
+	err = c.post("/daemon/logging", values.Encode(), nil)
+	return
+}
+
+// DaemonReloadPost uses the /daemon/reload endpoint to reload the config file
+// of the daemon.
+func (c *Client) DaemonReloadPost() (drp api.DaemonReloadPOST, err error) {
+	err = c.post("/daemon/reload", "", &drp)
+	return
+}
+
 // DaemonVersionGet requests the /daemon/version resource.
 func (c *Client) DaemonVersionGet() (dvg api.DaemonVersionGet, err error) {
 	err = c.get("/daemon/version", &dvg)
 	return
 }
 
+// MetricsGet requests the /metrics resource and returns the metrics in the
+// Prometheus text exposition format.
+func (c *Client) MetricsGet() ([]byte, error) {
+	_, resp, err := c.getRawResponse("/metrics")
+	return resp, err
+}
+
 // DaemonSettingsGet requests the /daemon/settings api resource.
 func (c *Client) DaemonSettingsGet() (dsg api.DaemonSettingsGet, err error) {
 	err = c.get("/daemon/settings", &dsg)
@@ -49,6 +49,32 @@ func (c *Client) HostDbFilterModePost(fm modules.FilterMode, hosts []types.SiaPu
 	return
 }
 
+// HostDbScoringGet requests the /hostdb/scoring GET endpoint
+func (c *Client) HostDbScoringGet() (hdsg api.HostdbScoringGET, err error) {
+	err = c.get("/hostdb/scoring", &hdsg)
+	return
+}
+
+// HostDbScoringPost requests the /hostdb/scoring POST endpoint
+func (c *Client) HostDbScoringPost(rules modules.HostScoringRules) (err error) {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	err = c.post("/hostdb/scoring", string(data), nil)
+	return
+}
+
+// HostDbScoringPreviewPost requests the /hostdb/scoring/preview POST endpoint
+func (c *Client) HostDbScoringPreviewPost(rules modules.HostScoringRules) (hdspp api.HostdbScoringPreviewPOST, err error) {
+	data, err := json.Marshal(rules)
+	if err != nil {
+		return hdspp, err
+	}
+	err = c.post("/hostdb/scoring/preview", string(data), &hdspp)
+	return
+}
+
 // HostDbHostsGet request the /hostdb/hosts/:pubkey endpoint's resources.
 func (c *Client) HostDbHostsGet(pk types.SiaPublicKey) (hhg api.HostdbHostsGET, err error) {
 	err = c.get("/hostdb/hosts/"+pk.String(), &hhg)
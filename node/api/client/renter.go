@@ -1,6 +1,7 @@
 package client
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"math"
@@ -445,12 +446,89 @@ func (c *Client) RenterFileGet(siaPath modules.SiaPath) (rf api.RenterFile, err
 	return
 }
 
+// RenterFileVersionsGet uses the /renter/file/:siapath endpoint to query a
+// file and its prior versions.
+func (c *Client) RenterFileVersionsGet(siaPath modules.SiaPath) (rf api.RenterFile, err error) {
+	sp := escapeSiaPath(siaPath)
+	err = c.get("/renter/file/"+sp+"?versions=true", &rf)
+	return
+}
+
+// RenterFileRestoreVersionPost uses the /renter/file/:siapath endpoint to
+// restore a prior version of a file.
+func (c *Client) RenterFileRestoreVersionPost(siaPath modules.SiaPath, id string) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("restoreversion", id)
+	err = c.post(fmt.Sprintf("/renter/file/%v", sp), values.Encode(), nil)
+	return
+}
+
+// RenterFileUserMetadataPost uses the /renter/file/:siapath endpoint to
+// update the user metadata of a file. Entries with an empty value remove the
+// key.
+func (c *Client) RenterFileUserMetadataPost(siaPath modules.SiaPath, update modules.UserMetadata) (err error) {
+	sp := escapeSiaPath(siaPath)
+	md, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set("metadata", string(md))
+	err = c.post(fmt.Sprintf("/renter/file/%v", sp), values.Encode(), nil)
+	return
+}
+
 // RenterFilesGet requests the /renter/files resource.
 func (c *Client) RenterFilesGet(cached bool) (rf api.RenterFiles, err error) {
 	err = c.get("/renter/files?cached="+fmt.Sprint(cached), &rf)
 	return
 }
 
+// RenterFilesQueryGet requests the /renter/files resource and only returns the
+// files matching the query in the query's order.
+func (c *Client) RenterFilesQueryGet(cached bool, q modules.FileQuery) (rf api.RenterFiles, err error) {
+	values := url.Values{}
+	values.Set("cached", fmt.Sprint(cached))
+	if len(q.Filter) > 0 {
+		values.Set("filter", q.Filter.String())
+	}
+	for k, v := range q.Tags {
+		if v == "" {
+			values.Add("tag", k)
+		} else {
+			values.Add("tag", k+"="+v)
+		}
+	}
+	if q.Sort != "" {
+		values.Set("sort", q.Sort)
+	}
+	if q.Descending {
+		values.Set("order", "desc")
+	}
+	if q.Limit > 0 {
+		values.Set("limit", strconv.Itoa(q.Limit))
+	}
+	if q.Cursor != "" {
+		values.Set("cursor", q.Cursor)
+	}
+	err = c.get("/renter/files?"+values.Encode(), &rf)
+	return
+}
+
+// RenterFilesTaggedGet requests the /renter/files resource and only returns
+// the files with all of the given tags. Tags are of the form "key" or
+// "key=value".
+func (c *Client) RenterFilesTaggedGet(cached bool, tags ...string) (rf api.RenterFiles, err error) {
+	values := url.Values{}
+	values.Set("cached", fmt.Sprint(cached))
+	for _, tag := range tags {
+		values.Add("tag", tag)
+	}
+	err = c.get("/renter/files?"+values.Encode(), &rf)
+	return
+}
+
 // RenterGet requests the /renter resource.
 func (c *Client) RenterGet() (rg api.RenterGET, err error) {
 	err = c.get("/renter", &rg)
@@ -497,6 +575,17 @@ func (c *Client) RenterRateLimitPost(readBPS, writeBPS int64) (err error) {
 	return
 }
 
+// RenterCopyPost uses the /renter/copy/:siapath endpoint to copy a file
+// without uploading its data again.
+func (c *Client) RenterCopyPost(siaPath, newSiaPath modules.SiaPath, root bool) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("newsiapath", newSiaPath.String())
+	values.Set("root", fmt.Sprint(root))
+	err = c.post(fmt.Sprintf("/renter/copy/%s", sp), values.Encode(), nil)
+	return
+}
+
 // RenterRenamePost uses the /renter/rename/:siapath endpoint to rename a file.
 func (c *Client) RenterRenamePost(siaPathOld, siaPathNew modules.SiaPath, root bool) (err error) {
 	spo := escapeSiaPath(siaPathOld)
@@ -507,6 +596,104 @@ func (c *Client) RenterRenamePost(siaPathOld, siaPathNew modules.SiaPath, root b
 	return
 }
 
+// RenterReencodePost uses the /renter/reencode endpoint to re-encode a file
+// with new erasure coding and cipher parameters. Zero pieces or an empty
+// cipher type keep the value of the file.
+func (c *Client) RenterReencodePost(siaPath modules.SiaPath, dataPieces, parityPieces uint64, cipherType string) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	if dataPieces > 0 || parityPieces > 0 {
+		values.Set("datapieces", fmt.Sprint(dataPieces))
+		values.Set("paritypieces", fmt.Sprint(parityPieces))
+	}
+	if cipherType != "" {
+		values.Set("ciphertype", cipherType)
+	}
+	err = c.post(fmt.Sprintf("/renter/reencode/%s", sp), values.Encode(), nil)
+	return
+}
+
+// RenterVerifyPost uses the /renter/verify endpoint to verify the data of a
+// file on the network against its checksum.
+func (c *Client) RenterVerifyPost(siaPath modules.SiaPath) (fv modules.FileVerification, err error) {
+	sp := escapeSiaPath(siaPath)
+	err = c.post(fmt.Sprintf("/renter/verify/%s", sp), "", &fv)
+	return
+}
+
+// RenterVerifyDirPost uses the /renter/verify endpoint to verify all files
+// within a directory and its subdirectories in the background.
+func (c *Client) RenterVerifyDirPost(siaPath modules.SiaPath) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("recursive", "true")
+	err = c.post(fmt.Sprintf("/renter/verify/%s", sp), values.Encode(), nil)
+	return
+}
+
+// RenterSyncGet requests the /renter/sync resource to get the recent and
+// ongoing syncs.
+func (c *Client) RenterSyncGet() (rs api.RenterSyncGET, err error) {
+	err = c.get("/renter/sync", &rs)
+	return
+}
+
+// RenterSyncPost uses the /renter/sync endpoint to synchronize a local
+// directory to the siapath of the params. A dry run only returns the plan of
+// the sync.
+func (c *Client) RenterSyncPost(params modules.SyncParams, dryRun bool) (run modules.SyncRun, err error) {
+	sp := escapeSiaPath(params.SiaPath)
+	values := url.Values{}
+	values.Set("localpath", params.LocalPath)
+	values.Set("delete", fmt.Sprint(params.Delete))
+	values.Set("dryrun", fmt.Sprint(dryRun))
+	for _, glob := range params.Include {
+		values.Add("include", glob)
+	}
+	for _, glob := range params.Exclude {
+		values.Add("exclude", glob)
+	}
+	err = c.post(fmt.Sprintf("/renter/sync/%s", sp), values.Encode(), &run)
+	return
+}
+
+// RenterTenantsGet requests the /renter/tenants resource. Tenants only get
+// their own tenant.
+func (c *Client) RenterTenantsGet() (rtg api.RenterTenantsGET, err error) {
+	err = c.get("/renter/tenants", &rtg)
+	return
+}
+
+// RenterTenantsSetPost uses the /renter/tenants/set endpoint to create a
+// tenant or update the quotas of an existing one.
+func (c *Client) RenterTenantsSetPost(tenant modules.Tenant) (err error) {
+	values := url.Values{}
+	values.Set("name", tenant.Name)
+	values.Set("storagequota", fmt.Sprint(tenant.StorageQuota))
+	values.Set("uploadquota", fmt.Sprint(tenant.UploadQuota))
+	values.Set("downloadquota", fmt.Sprint(tenant.DownloadQuota))
+	err = c.post("/renter/tenants/set", values.Encode(), nil)
+	return
+}
+
+// RenterTenantsRemovePost uses the /renter/tenants/remove endpoint to remove a
+// tenant and revoke its API tokens.
+func (c *Client) RenterTenantsRemovePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/tenants/remove", values.Encode(), nil)
+	return
+}
+
+// RenterTenantsResetPost uses the /renter/tenants/reset endpoint to reset the
+// uploaded and downloaded bytes of a tenant.
+func (c *Client) RenterTenantsResetPost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/tenants/reset", values.Encode(), nil)
+	return
+}
+
 // RenterSetStreamCacheSizePost uses the /renter endpoint to change the renter's
 // streamCacheSize for streaming
 func (c *Client) RenterSetStreamCacheSizePost(cacheSize uint64) (err error) {
@@ -525,6 +712,28 @@ func (c *Client) RenterSetCheckIPViolationPost(enabled bool) (err error) {
 	return
 }
 
+// RenterSetHostLatencyWeightPost uses the /renter endpoint to set the weight of
+// the latency adjustment in the host scores.
+func (c *Client) RenterSetHostLatencyWeightPost(weight float64) (err error) {
+	values := url.Values{}
+	values.Set("hostlatencyweight", fmt.Sprint(weight))
+	err = c.post("/renter", values.Encode(), nil)
+	return
+}
+
+// RenterSetHostSelectionPolicyPost uses the /renter endpoint to set the host
+// selection policy of the renter.
+func (c *Client) RenterSetHostSelectionPolicyPost(policy modules.HostSelectionPolicy) (err error) {
+	b, err := json.Marshal(policy)
+	if err != nil {
+		return errors.AddContext(err, "unable to marshal policy")
+	}
+	values := url.Values{}
+	values.Set("hostselectionpolicy", string(b))
+	err = c.post("/renter", values.Encode(), nil)
+	return
+}
+
 // RenterStreamGet uses the /renter/stream endpoint to download data as a
 // stream.
 func (c *Client) RenterStreamGet(siaPath modules.SiaPath, disableLocalFetch, root bool) (resp []byte, err error) {
@@ -576,12 +785,31 @@ func (c *Client) RenterUploadPost(path string, siaPath modules.SiaPath, dataPiec
 // RenterUploadForcePost uses the /renter/upload endpoint to upload a file
 // and to overwrite if the file already exists
 func (c *Client) RenterUploadForcePost(path string, siaPath modules.SiaPath, dataPieces, parityPieces uint64, force bool) (err error) {
+	return c.RenterUploadCompressedPost(path, siaPath, dataPieces, parityPieces, force, modules.CompressionNone)
+}
+
+// RenterUploadCompressedPost uses the /renter/upload endpoint to upload a
+// file which is compressed using the provided compression type.
+func (c *Client) RenterUploadCompressedPost(path string, siaPath modules.SiaPath, dataPieces, parityPieces uint64, force bool, compression modules.CompressionType) (err error) {
+	return c.RenterUploadChecksumPost(path, siaPath, dataPieces, parityPieces, force, compression, "")
+}
+
+// RenterUploadChecksumPost uses the /renter/upload endpoint to upload a file
+// whose whole-file checksum is computed with the provided checksum type. An
+// empty checksum type uses the renter's default.
+func (c *Client) RenterUploadChecksumPost(path string, siaPath modules.SiaPath, dataPieces, parityPieces uint64, force bool, compression modules.CompressionType, checksum modules.ChecksumType) (err error) {
 	sp := escapeSiaPath(siaPath)
 	values := url.Values{}
 	values.Set("source", path)
 	values.Set("datapieces", strconv.FormatUint(dataPieces, 10))
 	values.Set("paritypieces", strconv.FormatUint(parityPieces, 10))
 	values.Set("force", strconv.FormatBool(force))
+	if compression != modules.CompressionNone {
+		values.Set("compression", string(compression))
+	}
+	if checksum != "" {
+		values.Set("checksum", string(checksum))
+	}
 	err = c.post(fmt.Sprintf("/renter/upload/%s", sp), values.Encode(), nil)
 	return
 }
@@ -598,12 +826,21 @@ func (c *Client) RenterUploadDefaultPost(path string, siaPath modules.SiaPath) (
 
 // RenterUploadStreamPost uploads data using a stream.
 func (c *Client) RenterUploadStreamPost(r io.Reader, siaPath modules.SiaPath, dataPieces, parityPieces uint64, force bool) error {
+	return c.RenterUploadStreamCompressedPost(r, siaPath, dataPieces, parityPieces, force, modules.CompressionNone)
+}
+
+// RenterUploadStreamCompressedPost uploads data using a stream. The data is
+// compressed using the provided compression type.
+func (c *Client) RenterUploadStreamCompressedPost(r io.Reader, siaPath modules.SiaPath, dataPieces, parityPieces uint64, force bool, compression modules.CompressionType) error {
 	sp := escapeSiaPath(siaPath)
 	values := url.Values{}
 	values.Set("datapieces", strconv.FormatUint(dataPieces, 10))
 	values.Set("paritypieces", strconv.FormatUint(parityPieces, 10))
 	values.Set("force", strconv.FormatBool(force))
 	values.Set("stream", strconv.FormatBool(true))
+	if compression != modules.CompressionNone {
+		values.Set("compression", string(compression))
+	}
 	_, _, err := c.postRawResponse(fmt.Sprintf("/renter/uploadstream/%s?%s", sp, values.Encode()), r)
 	return err
 }
@@ -661,6 +898,64 @@ func (c *Client) RenterDirRenamePost(siaPath, newSiaPath modules.SiaPath) (err e
 	return
 }
 
+// RenterDirSetVersioningPost uses the /renter/dir/ endpoint to set the
+// versioning policy of a directory.
+func (c *Client) RenterDirSetVersioningPost(siaPath modules.SiaPath, policy modules.VersioningPolicy) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("action", "setversioning")
+	values.Set("maxversions", strconv.FormatUint(policy.MaxVersions, 10))
+	values.Set("maxage", policy.MaxAge.String())
+	err = c.post(fmt.Sprintf("/renter/dir/%s", sp), values.Encode(), nil)
+	return
+}
+
+// RenterDirSetStoragePolicyPost uses the /renter/dir/ endpoint to set the
+// storage policy of a directory. An empty policy removes the directory's
+// policy.
+func (c *Client) RenterDirSetStoragePolicyPost(siaPath modules.SiaPath, policy modules.StoragePolicy) (err error) {
+	sp := escapeSiaPath(siaPath)
+	values := url.Values{}
+	values.Set("action", "setstoragepolicy")
+	if policy.DataPieces > 0 || policy.ParityPieces > 0 {
+		values.Set("datapieces", strconv.Itoa(policy.DataPieces))
+		values.Set("paritypieces", strconv.Itoa(policy.ParityPieces))
+	}
+	if policy.CipherType != "" {
+		values.Set("ciphertype", policy.CipherType)
+	}
+	if policy.RepairThreshold != 0 {
+		values.Set("repairthreshold", strconv.FormatFloat(policy.RepairThreshold, 'f', -1, 64))
+	}
+	if policy.RepairPriority != 0 {
+		values.Set("repairpriority", strconv.Itoa(policy.RepairPriority))
+	}
+	if len(policy.AllowedHosts) > 0 {
+		hosts := make([]string, 0, len(policy.AllowedHosts))
+		for _, pk := range policy.AllowedHosts {
+			hosts = append(hosts, pk.String())
+		}
+		values.Set("allowedhosts", strings.Join(hosts, ","))
+	}
+	err = c.post(fmt.Sprintf("/renter/dir/%s", sp), values.Encode(), nil)
+	return
+}
+
+// RenterDirSetUserMetadataPost uses the /renter/dir/ endpoint to update the
+// user metadata of a directory. Entries with an empty value remove the key.
+func (c *Client) RenterDirSetUserMetadataPost(siaPath modules.SiaPath, update modules.UserMetadata) (err error) {
+	sp := escapeSiaPath(siaPath)
+	md, err := json.Marshal(update)
+	if err != nil {
+		return err
+	}
+	values := url.Values{}
+	values.Set("action", "setmetadata")
+	values.Set("metadata", string(md))
+	err = c.post(fmt.Sprintf("/renter/dir/%s", sp), values.Encode(), nil)
+	return
+}
+
 // RenterDirRootGet uses the /renter/dir/ endpoint to query a directory,
 // starting from the root path.
 func (c *Client) RenterDirRootGet(siaPath modules.SiaPath) (rd api.RenterDirectory, err error) {
@@ -763,6 +1058,37 @@ func (c *Client) RenterWorkersGet() (wps modules.WorkerPoolStatus, err error) {
 	return
 }
 
+// RenterTracesGet uses the /renter/traces endpoint to get the recent traces
+// of renter operations.
+func (c *Client) RenterTracesGet() (rtg api.RenterTracesGET, err error) {
+	err = c.get("/renter/traces", &rtg)
+	return
+}
+
+// RenterTracesExportPost uses the /renter/traces endpoint to set the file or
+// OTLP collector URL the finished spans are exported to. An empty destination
+// disables the export.
+func (c *Client) RenterTracesExportPost(destination string) (err error) {
+	values := url.Values{}
+	values.Set("destination", destination)
+	err = c.post("/renter/traces", values.Encode(), nil)
+	return
+}
+
+// RenterTraceGet uses the /renter/traces/:id endpoint to get a trace
+// including its spans.
+func (c *Client) RenterTraceGet(id string) (rt modules.RenterTrace, err error) {
+	err = c.get("/renter/traces/"+id, &rt)
+	return
+}
+
+// RenterTraceOTLPGet uses the /renter/traces/:id endpoint to get a trace in
+// the OTLP JSON encoding.
+func (c *Client) RenterTraceOTLPGet(id string) (traces modules.OTLPTraces, err error) {
+	err = c.get("/renter/traces/"+id+"?format=otlp", &traces)
+	return
+}
+
 // RenterBubblePost uses the /renter/bubble endpoint to manually trigger an
 // update to the directories metadata.
 func (c *Client) RenterBubblePost(siaPath modules.SiaPath, force, recursive bool) (err error) {
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/node/api"
+)
+
+// TLSOptions are the options for connecting to an API that is served over
+// TLS.
+type TLSOptions struct {
+	// CAFile is the path of the CA certificates the server certificate is
+	// verified against. If neither CAFile nor Fingerprint are set, the
+	// system's root CAs are used.
+	CAFile string
+
+	// Fingerprint is the SHA-256 fingerprint of the server certificate. If
+	// set, the server certificate is only accepted if it matches the
+	// fingerprint. This allows for using the self-signed certificate of siad.
+	// It can't be combined with CAFile.
+	Fingerprint string
+
+	// CertFile and KeyFile are the paths of the client certificate and its
+	// key for servers that require client certificates.
+	CertFile string
+	KeyFile  string
+}
+
+// clientTransport holds the transport of a client's TLS config. It is reused
+// across requests to allow for reusing connections.
+type clientTransport struct {
+	config    *tls.Config
+	transport *http.Transport
+	mu        sync.Mutex
+}
+
+// NewTLSConfig creates the TLS config of a client from the provided options.
+func NewTLSConfig(opts TLSOptions) (*tls.Config, error) {
+	if opts.CAFile != "" && opts.Fingerprint != "" {
+		return nil, errors.New("a CA file and a fingerprint can't be used together")
+	}
+	config := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+	if opts.CAFile != "" {
+		pem, err := os.ReadFile(opts.CAFile)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to read CA file")
+		}
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("CA file doesn't contain any certificates")
+		}
+	}
+	if opts.Fingerprint != "" {
+		fingerprint := api.NormalizeFingerprint(opts.Fingerprint)
+		// The certificate chain isn't verified since the pinned certificate
+		// is usually self-signed. Instead the fingerprint is checked.
+		config.InsecureSkipVerify = true
+		config.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errors.New("server didn't present a certificate")
+			}
+			if actual := api.CertificateFingerprint(rawCerts[0]); actual != fingerprint {
+				return fmt.Errorf("server certificate fingerprint %v doesn't match the pinned fingerprint", actual)
+			}
+			return nil
+		}
+	}
+	if (opts.CertFile == "") != (opts.KeyFile == "") {
+		return nil, errors.New("both the client certificate and the key need to be provided")
+	}
+	if opts.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to load client certificate")
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	return config, nil
+}
+
+// httpClient returns the http.Client used for requests to the API.
+func (c *Client) httpClient() *http.Client {
+	client := &http.Client{CheckRedirect: c.CheckRedirect}
+	if c.TLSConfig == nil {
+		return client
+	}
+	// Clients that weren't created with New get their transport on the first
+	// request.
+	if c.transport == nil {
+		c.transport = new(clientTransport)
+	}
+	ct := c.transport
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	if ct.transport == nil || ct.config != c.TLSConfig {
+		if ct.transport != nil {
+			ct.transport.CloseIdleConnections()
+		}
+		ct.transport = http.DefaultTransport.(*http.Transport).Clone()
+		ct.transport.TLSClientConfig = c.TLSConfig
+		ct.config = c.TLSConfig
+	}
+	client.Transport = ct.transport
+	return client
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	mnemonics "gitlab.com/NebulousLabs/entropy-mnemonics"
 	"gitlab.com/NebulousLabs/errors"
@@ -278,3 +279,56 @@ func (c *Client) Wallet033xPost(path, password string) (err error) {
 	err = c.post("/wallet/033x", values.Encode(), nil)
 	return
 }
+
+// WalletPolicyGet uses the /wallet/policy endpoint to get the spending policy
+// of the wallet.
+func (c *Client) WalletPolicyGet() (wpg api.WalletPolicyGET, err error) {
+	err = c.get("/wallet/policy", &wpg)
+	return
+}
+
+// WalletPolicyPost uses the /wallet/policy endpoint to set the spending policy
+// of the wallet. The password needs to be the encryption password of the
+// wallet.
+func (c *Client) WalletPolicyPost(policy modules.SpendingPolicy, password string) (err error) {
+	addrs := make([]string, 0, len(policy.AllowedDestinations))
+	for _, addr := range policy.AllowedDestinations {
+		addrs = append(addrs, addr.String())
+	}
+	values := url.Values{}
+	values.Set("dailylimit", policy.DailyLimit.String())
+	values.Set("approvalthreshold", policy.ApprovalThreshold.String())
+	values.Set("alloweddestinations", strings.Join(addrs, ","))
+	values.Set("maxallowancefunds", policy.MaxAllowanceFunds.String())
+	values.Set("maxallowanceincrease", policy.MaxAllowanceIncrease.String())
+	values.Set("encryptionpassword", password)
+	err = c.post("/wallet/policy", values.Encode(), nil)
+	return
+}
+
+// WalletApprovalsGet uses the /wallet/approvals endpoint to get the spends
+// waiting to be approved.
+func (c *Client) WalletApprovalsGet() (wag api.WalletApprovalsGET, err error) {
+	err = c.get("/wallet/approvals", &wag)
+	return
+}
+
+// WalletApprovalsApprovePost uses the /wallet/approvals/approve endpoint to
+// send a pending spend. The password needs to be the encryption password of
+// the wallet.
+func (c *Client) WalletApprovalsApprovePost(id, password string) (wsp api.WalletSiacoinsPOST, err error) {
+	values := url.Values{}
+	values.Set("id", id)
+	values.Set("encryptionpassword", password)
+	err = c.post("/wallet/approvals/approve", values.Encode(), &wsp)
+	return
+}
+
+// WalletApprovalsRejectPost uses the /wallet/approvals/reject endpoint to
+// remove a pending spend without sending it.
+func (c *Client) WalletApprovalsRejectPost(id string) (err error) {
+	values := url.Values{}
+	values.Set("id", id)
+	err = c.post("/wallet/approvals/reject", values.Encode(), nil)
+	return
+}
@@ -0,0 +1,362 @@
+package api
+
+// configfile.go contains the logic for applying the declarative siad config
+// file. Every section of the file is a partial overlay of the settings of a
+// module in the same JSON format the API uses for these settings. Only the
+// settings within the file are changed, all other settings keep their current
+// values. Sections of modules that aren't loaded are ignored, which allows for
+// using the same file for a fleet of nodes with different modules.
+//
+// The 'siad' section contains the startup flags of siad. It's applied by siad
+// before the node is started and ignored by the API.
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+)
+
+type (
+	// ConfigFile is the declarative siad config file.
+	ConfigFile struct {
+		Siad    json.RawMessage `json:"siad,omitempty"`
+		Daemon  json.RawMessage `json:"daemon,omitempty"`
+		Gateway json.RawMessage `json:"gateway,omitempty"`
+		Host    json.RawMessage `json:"host,omitempty"`
+		Renter  json.RawMessage `json:"renter,omitempty"`
+	}
+
+	// ConfigChange describes a setting that was changed by applying the config
+	// file.
+	ConfigChange struct {
+		Setting string `json:"setting"`
+		Old     string `json:"old"`
+		New     string `json:"new"`
+	}
+
+	// DaemonReloadPOST contains the changes made by reloading the config
+	// file.
+	DaemonReloadPOST struct {
+		Changes []ConfigChange `json:"changes"`
+	}
+
+	// configDaemonSettings are the daemon settings of the config file.
+	configDaemonSettings struct {
+		MaxDownloadSpeed int64             `json:"maxdownloadspeed"`
+		MaxUploadSpeed   int64             `json:"maxuploadspeed"`
+		EnableMetrics    bool              `json:"enablemetrics"`
+		AlertHooks       []configAlertHook `json:"alerthooks"`
+	}
+
+	// configAlertHook is an alert hook of the config file.
+	configAlertHook struct {
+		Name       string                  `json:"name"`
+		URL        string                  `json:"url,omitempty"`
+		Command    string                  `json:"command,omitempty"`
+		Modules    []string                `json:"modules,omitempty"`
+		Severities []modules.AlertSeverity `json:"severities,omitempty"`
+	}
+
+	// configGatewaySettings are the gateway settings of the config file.
+	configGatewaySettings struct {
+		MaxDownloadSpeed int64    `json:"maxdownloadspeed"`
+		MaxUploadSpeed   int64    `json:"maxuploadspeed"`
+		Blocklist        []string `json:"blocklist"`
+	}
+)
+
+// LoadConfigFile loads the config file at the given path.
+func LoadConfigFile(path string) (ConfigFile, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return ConfigFile{}, errors.AddContext(err, "failed to read config file")
+	}
+	var cf ConfigFile
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cf); err != nil {
+		return ConfigFile{}, errors.AddContext(err, "failed to parse config file")
+	}
+	return cf, nil
+}
+
+// decodeJSONObject decodes a JSON object while preserving numbers.
+func decodeJSONObject(raw []byte) (map[string]interface{}, error) {
+	var m map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// mergeConfigSection overlays the settings of a config file section onto the
+// current settings and decodes the result into target. Settings that don't
+// exist in the current settings are rejected. The changed settings are
+// returned with the given prefix.
+func mergeConfigSection(prefix string, current interface{}, overlay json.RawMessage, target interface{}) ([]ConfigChange, error) {
+	currentJSON, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	merged, err := decodeJSONObject(currentJSON)
+	if err != nil {
+		return nil, err
+	}
+	overlayMap, err := decodeJSONObject(overlay)
+	if err != nil {
+		return nil, errors.AddContext(err, fmt.Sprintf("section '%v' needs to be an object", prefix))
+	}
+	changes, err := mergeConfigMaps(prefix, merged, overlayMap)
+	if err != nil {
+		return nil, err
+	}
+	mergedJSON, err := json.Marshal(merged)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(mergedJSON, target); err != nil {
+		return nil, errors.AddContext(err, fmt.Sprintf("invalid settings in section '%v'", prefix))
+	}
+	return changes, nil
+}
+
+// mergeConfigMaps recursively overlays overlay onto current.
+func mergeConfigMaps(prefix string, current, overlay map[string]interface{}) ([]ConfigChange, error) {
+	keys := make([]string, 0, len(overlay))
+	for key := range overlay {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []ConfigChange
+	for _, key := range keys {
+		setting := prefix + "." + key
+		old, exists := current[key]
+		if !exists {
+			return nil, fmt.Errorf("unknown setting '%v'", setting)
+		}
+		oldMap, oldIsMap := old.(map[string]interface{})
+		newMap, newIsMap := overlay[key].(map[string]interface{})
+		if oldIsMap && newIsMap {
+			nested, err := mergeConfigMaps(setting, oldMap, newMap)
+			if err != nil {
+				return nil, err
+			}
+			changes = append(changes, nested...)
+			continue
+		}
+		oldJSON, _ := json.Marshal(old)
+		newJSON, _ := json.Marshal(overlay[key])
+		if !bytes.Equal(oldJSON, newJSON) {
+			changes = append(changes, ConfigChange{
+				Setting: setting,
+				Old:     string(oldJSON),
+				New:     string(newJSON),
+			})
+		}
+		current[key] = overlay[key]
+	}
+	return changes, nil
+}
+
+// SetConfigFile sets the path of the config file that is applied by
+// ReloadConfig.
+func (api *API) SetConfigFile(path string) {
+	api.configMu.Lock()
+	defer api.configMu.Unlock()
+	api.configFile = path
+}
+
+// ReloadConfig applies the config file to the loaded modules and returns the
+// changed settings. If applying a section fails, the changes of the previous
+// sections are still returned.
+func (api *API) ReloadConfig() ([]ConfigChange, error) {
+	api.configMu.Lock()
+	defer api.configMu.Unlock()
+	if api.configFile == "" {
+		return nil, errors.New("siad wasn't started with a config file")
+	}
+	cf, err := LoadConfigFile(api.configFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []ConfigChange
+	apply := func(section json.RawMessage, loaded bool, fn func(json.RawMessage) ([]ConfigChange, error)) error {
+		if len(section) == 0 || !loaded {
+			return nil
+		}
+		c, err := fn(section)
+		changes = append(changes, c...)
+		return err
+	}
+	err = apply(cf.Daemon, api.siadConfig != nil, api.applyDaemonConfig)
+	if err == nil {
+		err = apply(cf.Gateway, api.gateway != nil, api.applyGatewayConfig)
+	}
+	if err == nil {
+		err = apply(cf.Host, api.host != nil, api.applyHostConfig)
+	}
+	if err == nil {
+		err = apply(cf.Renter, api.renter != nil, api.applyRenterConfig)
+	}
+	return changes, err
+}
+
+// applyDaemonConfig applies the daemon section of the config file. The alert
+// hooks replace the current hooks of the config file.
+func (api *API) applyDaemonConfig(section json.RawMessage) ([]ConfigChange, error) {
+	download, upload, _ := modules.GlobalRateLimits.Limits()
+	var hooks []configAlertHook
+	for _, h := range api.staticAlerts.ConfigHooks() {
+		hooks = append(hooks, configAlertHook{
+			Name:       h.Name,
+			URL:        h.URL,
+			Command:    h.Command,
+			Modules:    h.Modules,
+			Severities: h.Severities,
+		})
+	}
+	current := configDaemonSettings{
+		MaxDownloadSpeed: download,
+		MaxUploadSpeed:   upload,
+		EnableMetrics:    api.siadConfig.MetricsEnabled(),
+		AlertHooks:       hooks,
+	}
+	var settings configDaemonSettings
+	changes, err := mergeConfigSection("daemon", current, section, &settings)
+	if err != nil {
+		return nil, err
+	}
+	var applied []ConfigChange
+	for _, c := range changes {
+		switch c.Setting {
+		case "daemon.alerthooks":
+			// Ignore changes that only differ in the encoding of the hooks.
+			oldJSON, _ := json.Marshal(hooks)
+			newJSON, _ := json.Marshal(settings.AlertHooks)
+			if len(hooks) == 0 && len(settings.AlertHooks) == 0 || bytes.Equal(oldJSON, newJSON) {
+				continue
+			}
+			newHooks := make([]AlertHook, 0, len(settings.AlertHooks))
+			for _, h := range settings.AlertHooks {
+				newHooks = append(newHooks, AlertHook{
+					Name:       h.Name,
+					URL:        h.URL,
+					Command:    h.Command,
+					Modules:    h.Modules,
+					Severities: h.Severities,
+				})
+			}
+			if err := api.staticAlerts.SetConfigHooks(newHooks); err != nil {
+				return applied, errors.AddContext(err, "failed to set alert hooks")
+			}
+		case "daemon.enablemetrics":
+			if err := api.siadConfig.SetMetricsEnabled(settings.EnableMetrics); err != nil {
+				return applied, errors.AddContext(err, "failed to set enablemetrics")
+			}
+		default:
+			if err := api.siadConfig.SetRatelimit(settings.MaxDownloadSpeed, settings.MaxUploadSpeed); err != nil {
+				return applied, errors.AddContext(err, "failed to set daemon ratelimit")
+			}
+		}
+		applied = append(applied, c)
+	}
+	return applied, nil
+}
+
+// applyGatewayConfig applies the gateway section of the config file. The
+// blocklist replaces the current blocklist.
+func (api *API) applyGatewayConfig(section json.RawMessage) ([]ConfigChange, error) {
+	download, upload := api.gateway.RateLimits()
+	blocklist, err := api.gateway.Blocklist()
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to get gateway blocklist")
+	}
+	sort.Strings(blocklist)
+	current := configGatewaySettings{
+		MaxDownloadSpeed: download,
+		MaxUploadSpeed:   upload,
+		Blocklist:        blocklist,
+	}
+	var settings configGatewaySettings
+	changes, err := mergeConfigSection("gateway", current, section, &settings)
+	if err != nil {
+		return nil, err
+	}
+	// Ignore reorderings of the blocklist.
+	sort.Strings(settings.Blocklist)
+	var applied []ConfigChange
+	for _, c := range changes {
+		switch c.Setting {
+		case "gateway.blocklist":
+			if strings.Join(settings.Blocklist, ",") == strings.Join(blocklist, ",") {
+				continue
+			}
+			if err := api.gateway.SetBlocklist(settings.Blocklist); err != nil {
+				return applied, errors.AddContext(err, "failed to set gateway blocklist")
+			}
+		default:
+			if err := api.gateway.SetRateLimits(settings.MaxDownloadSpeed, settings.MaxUploadSpeed); err != nil {
+				return applied, errors.AddContext(err, "failed to set gateway ratelimit")
+			}
+		}
+		applied = append(applied, c)
+	}
+	return applied, nil
+}
+
+// applyHostConfig applies the host section of the config file. It contains the
+// host's internal settings.
+func (api *API) applyHostConfig(section json.RawMessage) ([]ConfigChange, error) {
+	var settings modules.HostInternalSettings
+	changes, err := mergeConfigSection("host", api.host.InternalSettings(), section, &settings)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	if err := api.host.SetInternalSettings(settings); err != nil {
+		return nil, errors.AddContext(err, "failed to set host settings")
+	}
+	return changes, nil
+}
+
+// applyRenterConfig applies the renter section of the config file. It contains
+// the renter's settings including the allowance.
+func (api *API) applyRenterConfig(section json.RawMessage) ([]ConfigChange, error) {
+	current, err := api.renter.Settings()
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to get renter settings")
+	}
+	var settings modules.RenterSettings
+	changes, err := mergeConfigSection("renter", current, section, &settings)
+	if err != nil || len(changes) == 0 {
+		return nil, err
+	}
+	if err := api.renter.SetSettings(settings); err != nil {
+		return nil, errors.AddContext(err, "failed to set renter settings")
+	}
+	return changes, nil
+}
+
+// daemonReloadHandlerPOST handles the API call that reloads the config file.
+func (api *API) daemonReloadHandlerPOST(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	changes, err := api.ReloadConfig()
+	if err != nil {
+		WriteError(w, Error{"failed to reload config: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, DaemonReloadPOST{
+		Changes: changes,
+	})
+}
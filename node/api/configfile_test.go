@@ -0,0 +1,139 @@
+package api
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+// TestLoadConfigFile tests loading the config file.
+func TestLoadConfigFile(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "siad.json")
+
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Fatal("missing file should fail")
+	}
+	if err := os.WriteFile(path, []byte(`{"renter": {}, "wallet": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadConfigFile(path); err == nil {
+		t.Fatal("unknown section should fail")
+	}
+	if err := os.WriteFile(path, []byte(`{"renter": {"maxuploadspeed": 10}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cf, err := LoadConfigFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cf.Renter) == 0 || len(cf.Host) != 0 {
+		t.Fatal("unexpected sections", cf)
+	}
+}
+
+// TestMergeConfigSection tests overlaying config file sections onto the current
+// settings.
+func TestMergeConfigSection(t *testing.T) {
+	t.Parallel()
+
+	current := modules.RenterSettings{
+		Allowance: modules.Allowance{
+			Funds:  types.SiacoinPrecision.Mul64(1e6),
+			Hosts:  50,
+			Period: 1000,
+		},
+		MaxUploadSpeed: 100,
+	}
+
+	// Nested settings are merged and large numbers keep their precision.
+	funds := types.SiacoinPrecision.Mul64(1e9).Add64(1)
+	overlay := json.RawMessage(`{"allowance": {"funds": "` + funds.String() + `", "hosts": 50}, "maxdownloadspeed": 200}`)
+	var settings modules.RenterSettings
+	changes, err := mergeConfigSection("renter", current, overlay, &settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !settings.Allowance.Funds.Equals(funds) || settings.Allowance.Hosts != 50 || settings.Allowance.Period != 1000 {
+		t.Fatal("allowance wasn't merged", settings.Allowance)
+	}
+	if settings.MaxUploadSpeed != 100 || settings.MaxDownloadSpeed != 200 {
+		t.Fatal("settings weren't merged", settings)
+	}
+	if len(changes) != 2 || changes[0].Setting != "renter.allowance.funds" || changes[1].Setting != "renter.maxdownloadspeed" {
+		t.Fatal("unexpected changes", changes)
+	}
+	if changes[1].Old != "0" || changes[1].New != "200" {
+		t.Fatal("unexpected change", changes[1])
+	}
+
+	// Unknown settings are rejected.
+	for _, overlay := range []string{`{"maxuploadspeeed": 1}`, `{"allowance": {"foo": 1}}`} {
+		if _, err := mergeConfigSection("renter", current, json.RawMessage(overlay), &settings); err == nil {
+			t.Fatal("unknown setting should be rejected", overlay)
+		}
+	}
+	// Invalid values are rejected.
+	if _, err := mergeConfigSection("renter", current, json.RawMessage(`{"maxuploadspeed": "fast"}`), &settings); err == nil {
+		t.Fatal("invalid value should be rejected")
+	}
+	if _, err := mergeConfigSection("renter", current, json.RawMessage(`[]`), &settings); err == nil {
+		t.Fatal("section that isn't an object should be rejected")
+	}
+}
+
+// TestApplyDaemonConfigAlertHooks tests setting alert hooks in the daemon
+// section of the config file.
+func TestApplyDaemonConfigAlertHooks(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := modules.NewConfig(filepath.Join(dir, modules.ConfigName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	api := New(cfg, "", "", nil, nil, nil, nil, nil, nil, nil, nil, nil)
+
+	section := json.RawMessage(`{"alerthooks": [{"name": "pager", "command": "page-oncall", "severities": ["critical"]}]}`)
+	changes, err := api.applyDaemonConfig(section)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Setting != "daemon.alerthooks" {
+		t.Fatal("unexpected changes", changes)
+	}
+	hooks := api.staticAlerts.Hooks()
+	if len(hooks) != 1 || hooks[0].Command != "page-oncall" || !hooks[0].Config || hooks[0].Severities[0] != modules.SeverityCritical {
+		t.Fatal("unexpected hooks", hooks)
+	}
+
+	// Applying the same hooks again doesn't change anything.
+	changes, err = api.applyDaemonConfig(section)
+	if err != nil || len(changes) != 0 {
+		t.Fatal("unexpected changes", changes, err)
+	}
+
+	// Invalid hooks are rejected.
+	if _, err := api.applyDaemonConfig(json.RawMessage(`{"alerthooks": [{"name": "pager"}]}`)); err == nil {
+		t.Fatal("invalid hook should be rejected")
+	}
+
+	// An empty list removes the hooks.
+	if _, err := api.applyDaemonConfig(json.RawMessage(`{"alerthooks": []}`)); err != nil {
+		t.Fatal(err)
+	}
+	if hooks := api.staticAlerts.Hooks(); len(hooks) != 0 {
+		t.Fatal("hooks weren't removed", hooks)
+	}
+}
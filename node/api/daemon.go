@@ -137,6 +137,7 @@ type (
 		MaxDownloadSpeed int64         `json:"maxdownloadspeed"`
 		MaxUploadSpeed   int64         `json:"maxuploadspeed"`
 		Modules          configModules `json:"modules"`
+		EnableMetrics    bool          `json:"enablemetrics"`
 	}
 
 	// DaemonVersion holds the version information for siad
@@ -308,48 +309,11 @@ func (api *API) daemonAlertsHandlerGET(w http.ResponseWriter, _ *http.Request, _
 	err := make([]modules.Alert, 0, 6)
 	warn := make([]modules.Alert, 0, 6)
 	info := make([]modules.Alert, 0, 6)
-	if api.gateway != nil {
-		c, e, w, i := api.gateway.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
-		info = append(info, i...)
-	}
-	if api.cs != nil {
-		c, e, w, i := api.cs.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
-		info = append(info, i...)
-	}
-	if api.tpool != nil {
-		c, e, w, i := api.tpool.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
-		info = append(info, i...)
-	}
-	if api.wallet != nil {
-		c, e, w, i := api.wallet.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
-		info = append(info, i...)
-	}
-	if api.renter != nil {
-		c, e, w, i := api.renter.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
-		info = append(info, i...)
-	}
-	if api.host != nil {
-		c, e, w, i := api.host.Alerts()
-		crit = append(crit, c...)
-		err = append(err, e...)
-		warn = append(warn, w...)
-		info = append(info, i...)
-	}
+	c, e, wa, i := api.managedAlerts()
+	crit = append(crit, c...)
+	err = append(err, e...)
+	warn = append(warn, wa...)
+	info = append(info, i...)
 	// Sort alerts by severity. Critical first, then Error and finally Warning.
 	alerts := append(append(crit, append(err, warn...)...), info...)
 	WriteJSON(w, DaemonAlertsGet{
@@ -514,6 +478,7 @@ func (api *API) daemonSettingsHandlerGET(w http.ResponseWriter, _ *http.Request,
 		MaxDownloadSpeed: gmds,
 		MaxUploadSpeed:   gmus,
 		Modules:          api.staticConfigModules,
+		EnableMetrics:    api.siadConfig != nil && api.siadConfig.MetricsEnabled(),
 	})
 }
 
@@ -539,6 +504,18 @@ func (api *API) daemonSettingsHandlerPOST(w http.ResponseWriter, req *http.Reque
 		}
 		maxUploadSpeed = uploadSpeed
 	}
+	// Enable or disable the metrics endpoint. (optional parameter)
+	if m := req.FormValue("enablemetrics"); m != "" {
+		enable, err := scanBool(m)
+		if err != nil {
+			WriteError(w, Error{"unable to parse enablemetrics: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err := api.siadConfig.SetMetricsEnabled(enable); err != nil {
+			WriteError(w, Error{"unable to set enablemetrics: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
 	// Set the limit.
 	if err := api.siadConfig.SetRatelimit(maxDownloadSpeed, maxUploadSpeed); err != nil {
 		WriteError(w, Error{"unable to set limits: " + err.Error()}, http.StatusBadRequest)
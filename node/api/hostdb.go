@@ -57,6 +57,18 @@ type (
 		Hosts        []types.SiaPublicKey `json:"hosts"`
 		NetAddresses []string             `json:"netaddresses"`
 	}
+
+	// HostdbScoringGET contains the user defined rules that adjust the host
+	// scores.
+	HostdbScoringGET struct {
+		Rules []modules.HostScoringRule `json:"rules"`
+	}
+
+	// HostdbScoringPreviewPOST contains the score breakdowns the active hosts
+	// would have if the submitted scoring rules were applied.
+	HostdbScoringPreviewPOST struct {
+		Hosts []modules.HostScorePreview `json:"hosts"`
+	}
 )
 
 // hostdbHandler handles the API call asking for the list of active
@@ -211,3 +223,51 @@ func (api *API) hostdbFilterModeHandlerPOST(w http.ResponseWriter, req *http.Req
 	}
 	WriteSuccess(w)
 }
+
+// hostdbScoringHandlerGET handles the API call to get the hostdb's scoring
+// rules.
+func (api *API) hostdbScoringHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	rules, err := api.renter.HostScoringRules()
+	if err != nil {
+		WriteError(w, Error{"unable to get scoring rules: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostdbScoringGET{
+		Rules: rules.Rules,
+	})
+}
+
+// hostdbScoringHandlerPOST handles the API call to set the hostdb's scoring
+// rules.
+func (api *API) hostdbScoringHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var rules modules.HostScoringRules
+	err := json.NewDecoder(req.Body).Decode(&rules)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.SetHostScoringRules(rules); err != nil {
+		WriteError(w, Error{"failed to set the scoring rules: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostdbScoringPreviewHandlerPOST handles the API call to preview the effect
+// of scoring rules on the host scores without applying them.
+func (api *API) hostdbScoringPreviewHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var rules modules.HostScoringRules
+	err := json.NewDecoder(req.Body).Decode(&rules)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	previews, err := api.renter.PreviewHostScoringRules(rules)
+	if err != nil {
+		WriteError(w, Error{"failed to preview the scoring rules: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, HostdbScoringPreviewPOST{
+		Hosts: previews,
+	})
+}
@@ -0,0 +1,84 @@
+package api
+
+// logging.go contains the API for the log settings of the daemon. The log
+// settings are global to the process and apply to the loggers of all modules.
+// The level of a module can be changed at runtime, e.g. to debug a single
+// module without restarting siad.
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"go.sia.tech/siad/persist"
+)
+
+// DaemonLoggingGET contains the log settings of the daemon.
+type DaemonLoggingGET struct {
+	persist.LogSettings
+}
+
+// daemonLoggingHandlerGET handles the API call that returns the log
+// settings.
+func (api *API) daemonLoggingHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, DaemonLoggingGET{persist.CurrentLogSettings()})
+}
+
+// daemonLoggingHandlerPOST handles the API call that changes the log
+// settings.
+func (api *API) daemonLoggingHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// Parse all parameters before applying any of them.
+	module := req.FormValue("module")
+	_, levelSet := req.Form["level"]
+	var level persist.LogLevel
+	if l := req.FormValue("level"); l != "" {
+		var err error
+		level, err = persist.ParseLogLevel(l)
+		if err != nil {
+			WriteError(w, Error{"unable to parse level: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	} else if levelSet && module == "" {
+		WriteError(w, Error{"the default level can't be cleared"}, http.StatusBadRequest)
+		return
+	}
+	format := req.FormValue("format")
+	if format != "" && format != persist.LogFormatText && format != persist.LogFormatJSON {
+		WriteError(w, Error{"unknown format, use 'text' or 'json'"}, http.StatusBadRequest)
+		return
+	}
+	var stdout bool
+	s := req.FormValue("stdout")
+	if s != "" {
+		var err error
+		stdout, err = scanBool(s)
+		if err != nil {
+			WriteError(w, Error{"unable to parse stdout: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if module != "" && !levelSet {
+		WriteError(w, Error{"module requires level"}, http.StatusBadRequest)
+		return
+	}
+
+	// Apply the settings.
+	if levelSet {
+		if req.FormValue("level") == "" {
+			persist.UnsetLogLevel(module)
+		} else if err := persist.SetLogLevel(module, level); err != nil {
+			WriteError(w, Error{"unable to set level: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if format != "" {
+		if err := persist.SetLogFormat(format); err != nil {
+			WriteError(w, Error{"unable to set format: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if s != "" {
+		persist.SetLogStdout(stdout)
+	}
+	WriteSuccess(w)
+}
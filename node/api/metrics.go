@@ -0,0 +1,421 @@
+package api
+
+// metrics.go contains the /metrics endpoint which exports the operational data
+// of all loaded modules in the Prometheus text exposition format. Modules that
+// are not loaded are skipped, as are modules that fail to report their status.
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// metricsContentType is the content type of the Prometheus text exposition
+	// format.
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// workerJobTimeBuckets are the upper bounds of the buckets of the worker
+	// job time histograms in seconds.
+	workerJobTimeBuckets = []float64{0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+)
+
+type (
+	// metricsWriter writes metrics in the Prometheus text exposition format.
+	metricsWriter struct {
+		buf bytes.Buffer
+	}
+
+	// metricSample is a single sample of a metric with its labels. The labels
+	// are given as alternating names and values.
+	metricSample struct {
+		labels []string
+		value  float64
+	}
+)
+
+// formatMetricValue formats a sample value.
+func formatMetricValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// formatMetricLabels formats the labels of a sample.
+func formatMetricLabels(labels []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	pairs := make([]string, 0, len(labels)/2)
+	for i := 0; i+1 < len(labels); i += 2 {
+		pairs = append(pairs, fmt.Sprintf(`%s="%s"`, labels[i], escaper.Replace(labels[i+1])))
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// boolMetric converts a bool into a sample value.
+func boolMetric(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// currencyMetric converts a currency into a sample value in hastings.
+func currencyMetric(c types.Currency) float64 {
+	f, _ := c.Float64()
+	return f
+}
+
+// header writes the HELP and TYPE lines of a metric.
+func (mw *metricsWriter) header(name, help, kind string) {
+	fmt.Fprintf(&mw.buf, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, kind)
+}
+
+// samples writes a metric of the given kind with the given samples.
+func (mw *metricsWriter) samples(name, help, kind string, samples ...metricSample) {
+	if len(samples) == 0 {
+		return
+	}
+	mw.header(name, help, kind)
+	for _, s := range samples {
+		fmt.Fprintf(&mw.buf, "%s%s %s\n", name, formatMetricLabels(s.labels), formatMetricValue(s.value))
+	}
+}
+
+// gauge writes an unlabeled gauge.
+func (mw *metricsWriter) gauge(name, help string, value float64) {
+	mw.samples(name, help, "gauge", metricSample{value: value})
+}
+
+// histogram writes a histogram of the given observations using the given
+// bucket upper bounds which need to be sorted in ascending order.
+func (mw *metricsWriter) histogram(name, help string, buckets []float64, observations []float64) {
+	mw.header(name, help, "histogram")
+	counts := make([]uint64, len(buckets))
+	var sum float64
+	for _, o := range observations {
+		sum += o
+		for i, upper := range buckets {
+			if o <= upper {
+				counts[i]++
+			}
+		}
+	}
+	for i, upper := range buckets {
+		fmt.Fprintf(&mw.buf, "%s_bucket%s %d\n", name, formatMetricLabels([]string{"le", formatMetricValue(upper)}), counts[i])
+	}
+	fmt.Fprintf(&mw.buf, "%s_bucket%s %d\n", name, formatMetricLabels([]string{"le", "+Inf"}), len(observations))
+	fmt.Fprintf(&mw.buf, "%s_sum %s\n", name, formatMetricValue(sum))
+	fmt.Fprintf(&mw.buf, "%s_count %d\n", name, len(observations))
+}
+
+// metricsHandlerGET handles the API call for the metrics of all loaded
+// modules.
+func (api *API) metricsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	if api.siadConfig == nil || !api.siadConfig.MetricsEnabled() {
+		WriteError(w, Error{"the metrics endpoint is disabled, enable it using the 'enablemetrics' daemon setting"}, http.StatusNotFound)
+		return
+	}
+
+	var mw metricsWriter
+	mw.samples("siad_build_info", "Version information about siad.", "gauge", metricSample{
+		labels: []string{"version", build.NodeVersion, "release", build.Release},
+		value:  1,
+	})
+	mw.gauge("siad_uptime_seconds", "Time since siad was started.", time.Since(api.staticStartTime).Seconds())
+
+	if api.accounting != nil {
+		api.writeAccountingMetrics(&mw)
+	}
+	if api.cs != nil {
+		api.writeConsensusMetrics(&mw)
+	}
+	if api.explorer != nil {
+		api.writeExplorerMetrics(&mw)
+	}
+	if api.gateway != nil {
+		api.writeGatewayMetrics(&mw)
+	}
+	if api.host != nil {
+		api.writeHostMetrics(&mw)
+	}
+	if api.miner != nil {
+		api.writeMinerMetrics(&mw)
+	}
+	if api.renter != nil {
+		api.writeRenterMetrics(&mw)
+	}
+	if api.tpool != nil {
+		api.writeTransactionPoolMetrics(&mw)
+	}
+	if api.wallet != nil {
+		api.writeWalletMetrics(&mw)
+	}
+
+	w.Header().Set("Content-Type", metricsContentType)
+	w.Write(mw.buf.Bytes())
+}
+
+// writeAccountingMetrics writes the metrics of the accounting module.
+func (api *API) writeAccountingMetrics(mw *metricsWriter) {
+	ai, err := api.accounting.Accounting()
+	if err != nil {
+		return
+	}
+	mw.samples("siad_accounting_renter_hastings", "Funds of the renter's contracts in hastings.", "gauge",
+		metricSample{labels: []string{"metric", "unspentunallocated"}, value: currencyMetric(ai.Renter.UnspentUnallocated)},
+		metricSample{labels: []string{"metric", "withheldfunds"}, value: currencyMetric(ai.Renter.WithheldFunds)},
+	)
+	mw.gauge("siad_accounting_wallet_siacoins_hastings", "Confirmed siacoin balance of the wallet in hastings.", currencyMetric(ai.Wallet.ConfirmedSiacoinBalance))
+	mw.gauge("siad_accounting_wallet_siafunds", "Confirmed siafund balance of the wallet.", currencyMetric(ai.Wallet.ConfirmedSiafundBalance))
+}
+
+// writeConsensusMetrics writes the metrics of the consensus set.
+func (api *API) writeConsensusMetrics(mw *metricsWriter) {
+	mw.gauge("siad_consensus_height", "Current block height.", float64(api.cs.Height()))
+	mw.gauge("siad_consensus_synced", "Whether the consensus set is synced.", boolMetric(api.cs.Synced()))
+}
+
+// writeExplorerMetrics writes the metrics of the explorer.
+func (api *API) writeExplorerMetrics(mw *metricsWriter) {
+	facts := api.explorer.LatestBlockFacts()
+	mw.gauge("siad_explorer_height", "Block height of the explorer.", float64(facts.Height))
+	mw.gauge("siad_explorer_difficulty", "Current difficulty.", currencyMetric(facts.Difficulty))
+	mw.gauge("siad_explorer_estimated_hashrate", "Estimated hashrate of the network in hashes per second.", currencyMetric(facts.EstimatedHashrate))
+	mw.gauge("siad_explorer_active_contracts", "Number of active file contracts.", float64(facts.ActiveContractCount))
+	mw.gauge("siad_explorer_active_contract_bytes", "Size of the active file contracts.", currencyMetric(facts.ActiveContractSize))
+}
+
+// writeGatewayMetrics writes the metrics of the gateway.
+func (api *API) writeGatewayMetrics(mw *metricsWriter) {
+	peers := api.gateway.Peers()
+	var inbound float64
+	for _, p := range peers {
+		if p.Inbound {
+			inbound++
+		}
+	}
+	mw.samples("siad_gateway_peers", "Number of connected peers.", "gauge",
+		metricSample{labels: []string{"direction", "inbound"}, value: inbound},
+		metricSample{labels: []string{"direction", "outbound"}, value: float64(len(peers)) - inbound},
+	)
+	if upload, download, _, err := api.gateway.BandwidthCounters(); err == nil {
+		mw.samples("siad_gateway_bandwidth_bytes_total", "Bytes transferred by the gateway.", "counter",
+			metricSample{labels: []string{"direction", "upload"}, value: float64(upload)},
+			metricSample{labels: []string{"direction", "download"}, value: float64(download)},
+		)
+	}
+}
+
+// writeHostMetrics writes the metrics of the host.
+func (api *API) writeHostMetrics(mw *metricsWriter) {
+	nm := api.host.NetworkMetrics()
+	mw.samples("siad_host_rpc_calls_total", "Number of RPC calls handled by the host.", "counter",
+		metricSample{labels: []string{"rpc", "download"}, value: float64(nm.DownloadCalls)},
+		metricSample{labels: []string{"rpc", "error"}, value: float64(nm.ErrorCalls)},
+		metricSample{labels: []string{"rpc", "formcontract"}, value: float64(nm.FormContractCalls)},
+		metricSample{labels: []string{"rpc", "renew"}, value: float64(nm.RenewCalls)},
+		metricSample{labels: []string{"rpc", "revise"}, value: float64(nm.ReviseCalls)},
+		metricSample{labels: []string{"rpc", "settings"}, value: float64(nm.SettingsCalls)},
+		metricSample{labels: []string{"rpc", "unrecognized"}, value: float64(nm.UnrecognizedCalls)},
+	)
+
+	fm := api.host.FinancialMetrics()
+	mw.gauge("siad_host_contracts", "Number of contracts of the host.", float64(fm.ContractCount))
+	financial := []struct {
+		name  string
+		value types.Currency
+	}{
+		{"accountfunding", fm.AccountFunding},
+		{"contractcompensation", fm.ContractCompensation},
+		{"downloadbandwidthrevenue", fm.DownloadBandwidthRevenue},
+		{"lockedstoragecollateral", fm.LockedStorageCollateral},
+		{"lostrevenue", fm.LostRevenue},
+		{"loststoragecollateral", fm.LostStorageCollateral},
+		{"potentialaccountfunding", fm.PotentialAccountFunding},
+		{"potentialcontractcompensation", fm.PotentialContractCompensation},
+		{"potentialdownloadbandwidthrevenue", fm.PotentialDownloadBandwidthRevenue},
+		{"potentialstoragerevenue", fm.PotentialStorageRevenue},
+		{"potentialuploadbandwidthrevenue", fm.PotentialUploadBandwidthRevenue},
+		{"riskedstoragecollateral", fm.RiskedStorageCollateral},
+		{"storagerevenue", fm.StorageRevenue},
+		{"transactionfeeexpenses", fm.TransactionFeeExpenses},
+		{"uploadbandwidthrevenue", fm.UploadBandwidthRevenue},
+	}
+	var financialSamples []metricSample
+	for _, f := range financial {
+		financialSamples = append(financialSamples, metricSample{labels: []string{"metric", f.name}, value: currencyMetric(f.value)})
+	}
+	mw.samples("siad_host_financial_hastings", "Financial metrics of the host in hastings.", "gauge", financialSamples...)
+
+	if upload, download, _, err := api.host.BandwidthCounters(); err == nil {
+		mw.samples("siad_host_bandwidth_bytes_total", "Bytes transferred by the host.", "counter",
+			metricSample{labels: []string{"direction", "upload"}, value: float64(upload)},
+			metricSample{labels: []string{"direction", "download"}, value: float64(download)},
+		)
+	}
+
+	folders := api.host.StorageFolders()
+	var capacity, remaining, reads, writes []metricSample
+	for _, sf := range folders {
+		labels := []string{"folder", sf.Path}
+		success := []string{"folder", sf.Path, "result", "success"}
+		failure := []string{"folder", sf.Path, "result", "failure"}
+		capacity = append(capacity, metricSample{labels: labels, value: float64(sf.Capacity)})
+		remaining = append(remaining, metricSample{labels: labels, value: float64(sf.CapacityRemaining)})
+		reads = append(reads,
+			metricSample{labels: success, value: float64(sf.SuccessfulReads)},
+			metricSample{labels: failure, value: float64(sf.FailedReads)},
+		)
+		writes = append(writes,
+			metricSample{labels: success, value: float64(sf.SuccessfulWrites)},
+			metricSample{labels: failure, value: float64(sf.FailedWrites)},
+		)
+	}
+	mw.samples("siad_host_storage_folder_capacity_bytes", "Capacity of the host's storage folders.", "gauge", capacity...)
+	mw.samples("siad_host_storage_folder_remaining_bytes", "Remaining capacity of the host's storage folders.", "gauge", remaining...)
+	mw.samples("siad_host_storage_folder_reads_total", "Reads from the host's storage folders.", "counter", reads...)
+	mw.samples("siad_host_storage_folder_writes_total", "Writes to the host's storage folders.", "counter", writes...)
+}
+
+// writeMinerMetrics writes the metrics of the miner.
+func (api *API) writeMinerMetrics(mw *metricsWriter) {
+	good, stale := api.miner.BlocksMined()
+	mw.samples("siad_miner_blocks_mined_total", "Number of blocks mined by the miner.", "counter",
+		metricSample{labels: []string{"status", "good"}, value: float64(good)},
+		metricSample{labels: []string{"status", "stale"}, value: float64(stale)},
+	)
+	mw.gauge("siad_miner_cpu_mining", "Whether the CPU miner is running.", boolMetric(api.miner.CPUMining()))
+	mw.gauge("siad_miner_cpu_hashrate", "Hashrate of the CPU miner in hashes per second.", float64(api.miner.CPUHashrate()))
+}
+
+// writeRenterMetrics writes the metrics of the renter.
+func (api *API) writeRenterMetrics(mw *metricsWriter) {
+	if ms, err := api.renter.MemoryStatus(); err == nil {
+		managers := []struct {
+			name   string
+			status modules.MemoryManagerStatus
+		}{
+			{"registry", ms.Registry},
+			{"system", ms.System},
+			{"userdownload", ms.UserDownload},
+			{"userupload", ms.UserUpload},
+		}
+		var available, base, requested []metricSample
+		for _, m := range managers {
+			labels := []string{"manager", m.name}
+			available = append(available, metricSample{labels: labels, value: float64(m.status.Available)})
+			base = append(base, metricSample{labels: labels, value: float64(m.status.Base)})
+			requested = append(requested, metricSample{labels: labels, value: float64(m.status.Requested)})
+		}
+		mw.samples("siad_renter_memory_available_bytes", "Memory available in the renter's memory managers.", "gauge", available...)
+		mw.samples("siad_renter_memory_base_bytes", "Total memory of the renter's memory managers.", "gauge", base...)
+		mw.samples("siad_renter_memory_requested_bytes", "Memory requested from the renter's memory managers.", "gauge", requested...)
+	}
+
+	if rs, err := api.renter.RepairStatus(); err == nil {
+		mw.gauge("siad_renter_directory_heap_size", "Number of directories in the repair loop's directory heap.", float64(rs.DirectoryHeapSize))
+		mw.gauge("siad_renter_upload_heap_size", "Number of chunks in the upload heap.", float64(rs.UploadHeapSize))
+		mw.gauge("siad_renter_stuck_chunks", "Number of stuck chunks.", float64(rs.NumStuckChunks))
+		mw.gauge("siad_renter_repair_bytes", "Bytes that need to be repaired by the repair loop.", float64(rs.RepairSize))
+		mw.gauge("siad_renter_stuck_bytes", "Bytes that need to be repaired by the stuck loop.", float64(rs.StuckSize))
+		mw.gauge("siad_renter_health", "Health of the least healthy file.", rs.Health)
+	}
+
+	wps, err := api.renter.WorkerPoolStatus()
+	if err != nil {
+		return
+	}
+	mw.gauge("siad_renter_workers", "Number of workers.", float64(wps.NumWorkers))
+	mw.samples("siad_renter_workers_on_cooldown", "Number of workers on cooldown.", "gauge",
+		metricSample{labels: []string{"kind", "download"}, value: float64(wps.TotalDownloadCoolDown)},
+		metricSample{labels: []string{"kind", "maintenance"}, value: float64(wps.TotalMaintenanceCoolDown)},
+		metricSample{labels: []string{"kind", "upload"}, value: float64(wps.TotalUploadCoolDown)},
+	)
+
+	// Sort the workers to produce a stable output.
+	workers := wps.Workers
+	sort.Slice(workers, func(i, j int) bool {
+		return workers[i].HostPubKey.String() < workers[j].HostPubKey.String()
+	})
+	var queues []metricSample
+	var readTimes, hasSectorTimes []float64
+	for _, ws := range workers {
+		host := ws.HostPubKey.String()
+		jobs := []struct {
+			name string
+			size float64
+		}{
+			{"download", float64(ws.DownloadQueueSize)},
+			{"downloadsnapshot", float64(ws.DownloadSnapshotJobQueueSize)},
+			{"hassector", float64(ws.HasSectorJobsStatus.JobQueueSize)},
+			{"read", float64(ws.ReadJobsStatus.JobQueueSize)},
+			{"readregistry", float64(ws.ReadRegistryJobsStatus.JobQueueSize)},
+			{"updateregistry", float64(ws.UpdateRegistryJobsStatus.JobQueueSize)},
+			{"upload", float64(ws.UploadQueueSize)},
+			{"uploadsnapshot", float64(ws.UploadSnapshotJobQueueSize)},
+		}
+		for _, job := range jobs {
+			queues = append(queues, metricSample{labels: []string{"host", host, "job", job.name}, value: job.size})
+		}
+		// Only workers that completed jobs have a meaningful job time.
+		if ws.ReadJobsStatus.AvgJobTime64k > 0 {
+			readTimes = append(readTimes, (time.Duration(ws.ReadJobsStatus.AvgJobTime64k) * time.Millisecond).Seconds())
+		}
+		if ws.HasSectorJobsStatus.AvgJobTime > 0 {
+			hasSectorTimes = append(hasSectorTimes, (time.Duration(ws.HasSectorJobsStatus.AvgJobTime) * time.Millisecond).Seconds())
+		}
+	}
+	mw.samples("siad_renter_worker_job_queue_length", "Number of queued jobs per worker.", "gauge", queues...)
+	mw.histogram("siad_renter_worker_read_job_seconds", "Average time of the workers' 64kib read jobs.", workerJobTimeBuckets, readTimes)
+	mw.histogram("siad_renter_worker_has_sector_job_seconds", "Average time of the workers' has sector jobs.", workerJobTimeBuckets, hasSectorTimes)
+}
+
+// writeTransactionPoolMetrics writes the metrics of the transaction pool.
+func (api *API) writeTransactionPoolMetrics(mw *metricsWriter) {
+	mw.gauge("siad_tpool_transactions", "Number of transactions in the transaction pool.", float64(len(api.tpool.TransactionList())))
+	min, max := api.tpool.FeeEstimation()
+	mw.samples("siad_tpool_fee_hastings_per_byte", "Recommended transaction fee in hastings per byte.", "gauge",
+		metricSample{labels: []string{"bound", "min"}, value: currencyMetric(min)},
+		metricSample{labels: []string{"bound", "max"}, value: currencyMetric(max)},
+	)
+}
+
+// writeWalletMetrics writes the metrics of the wallet.
+func (api *API) writeWalletMetrics(mw *metricsWriter) {
+	unlocked, err := api.wallet.Unlocked()
+	if err != nil {
+		return
+	}
+	mw.gauge("siad_wallet_unlocked", "Whether the wallet is unlocked.", boolMetric(unlocked))
+	if !unlocked {
+		return
+	}
+	if height, err := api.wallet.Height(); err == nil {
+		mw.gauge("siad_wallet_height", "Block height of the wallet.", float64(height))
+	}
+	if siacoins, _, _, err := api.wallet.ConfirmedBalance(); err == nil {
+		mw.gauge("siad_wallet_confirmed_balance_hastings", "Confirmed siacoin balance of the wallet in hastings.", currencyMetric(siacoins))
+	}
+}
@@ -0,0 +1,39 @@
+package api
+
+import (
+	"math"
+	"testing"
+)
+
+// TestMetricsWriter tests the formatting of the metrics.
+func TestMetricsWriter(t *testing.T) {
+	t.Parallel()
+
+	var mw metricsWriter
+	mw.gauge("gauge", "A gauge.", 1.5)
+	mw.samples("labeled", "A labeled counter.", "counter",
+		metricSample{labels: []string{"a", "x", "b", "quoted \"value\""}, value: 2},
+		metricSample{labels: []string{"a", "y"}, value: math.Inf(1)},
+	)
+	mw.samples("empty", "Metrics without samples are skipped.", "gauge")
+	mw.histogram("hist", "A histogram.", []float64{1, 2.5}, []float64{0.5, 1, 2, 3})
+
+	expected := `# HELP gauge A gauge.
+# TYPE gauge gauge
+gauge 1.5
+# HELP labeled A labeled counter.
+# TYPE labeled counter
+labeled{a="x",b="quoted \"value\""} 2
+labeled{a="y"} +Inf
+# HELP hist A histogram.
+# TYPE hist histogram
+hist_bucket{le="1"} 2
+hist_bucket{le="2.5"} 3
+hist_bucket{le="+Inf"} 4
+hist_sum 6.5
+hist_count 4
+`
+	if got := mw.buf.String(); got != expected {
+		t.Fatalf("unexpected output\n%v\nexpected\n%v", got, expected)
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -8,7 +9,6 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
-	"sort"
 	"strconv"
 	"strings"
 	"sync"
@@ -23,6 +23,7 @@ import (
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/modules/renter"
 	"go.sia.tech/siad/modules/renter/contractor"
+	"go.sia.tech/siad/modules/renter/filesystem"
 	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/types"
 )
@@ -166,6 +167,10 @@ type (
 	RenterDirectory struct {
 		Directories []modules.DirectoryInfo `json:"directories"`
 		Files       []modules.FileInfo      `json:"files"`
+
+		// StoragePolicy is the storage policy that applies to the files
+		// within the directory, which might be inherited from a parent.
+		StoragePolicy modules.StoragePolicy `json:"storagepolicy"`
 	}
 
 	// RenterDownloadQueue contains the renter's download queue.
@@ -175,12 +180,22 @@ type (
 
 	// RenterFile lists the file queried.
 	RenterFile struct {
-		File modules.FileInfo `json:"file"`
+		File     modules.FileInfo      `json:"file"`
+		Versions []modules.FileVersion `json:"versions,omitempty"`
 	}
 
 	// RenterFiles lists the files known to the renter.
 	RenterFiles struct {
 		Files []modules.FileInfo `json:"files"`
+
+		// NextCursor is set if a query with a limit has more matching files.
+		// It is passed as the cursor of the next query to get the next page.
+		NextCursor string `json:"nextcursor,omitempty"`
+	}
+
+	// RenterSyncGET lists the recent and ongoing syncs of the renter.
+	RenterSyncGET struct {
+		Runs []modules.SyncRun `json:"runs"`
 	}
 
 	// RenterFuseInfo contains information about mounted fuse filesystems.
@@ -258,11 +273,14 @@ type (
 		StartTime            time.Time `json:"starttime"`            // The time when the download was started.
 		StartTimeUnix        int64     `json:"starttimeunix"`        // The time when the download was started in unix format.
 		TotalDataTransferred uint64    `json:"totaldatatransferred"` // The total amount of data transferred, including negotiation, overdrive etc.
+		TraceID              string    `json:"traceid,omitempty"`    // The ID of the trace of the download.
 	}
 )
 
-// Returns the boolean value of the 'root' parameter of req or an error if
-// it exists but is not parsable as bool.
+// isCalledWithRootFlag returns the boolean value of the 'root' parameter of
+// req or an error if it exists but is not parsable as bool. Siapaths relative
+// to the root give access to all files, which is why the flag can't be used
+// with the token of a tenant.
 func isCalledWithRootFlag(req *http.Request) (bool, error) {
 	rootStr := req.FormValue("root")
 	if rootStr == "" {
@@ -272,25 +290,40 @@ func isCalledWithRootFlag(req *http.Request) (bool, error) {
 	if err != nil {
 		return false, errors.New("unable to parse 'root' arg: " + err.Error())
 	}
-	return root, nil
+	if !root {
+		return false, nil
+	}
+	if requestTenant(req) != "" {
+		return false, errTenantRoot
+	}
+	return true, nil
 }
 
 // rebaseInputSiaPath rebases the SiaPath provided by the user to one that is
-// prefixed by the user's home directory.
-func rebaseInputSiaPath(siaPath modules.SiaPath) (modules.SiaPath, error) {
+// prefixed by the user's home directory or the root of the tenant of the
+// request.
+func rebaseInputSiaPath(req *http.Request, siaPath modules.SiaPath) (modules.SiaPath, error) {
+	folder, err := userFolder(req)
+	if err != nil {
+		return modules.SiaPath{}, err
+	}
 	// Prepend the provided siapath with the /home/siafiles dir.
 	if siaPath.IsRoot() {
-		return modules.UserFolder, nil
+		return folder, nil
 	}
-	return modules.UserFolder.Join(siaPath.String())
+	return folder.Join(siaPath.String())
 }
 
 // trimSiaDirFolder is a helper method to trim /home/siafiles off of the
 // siapaths of the dirinfos since the user expects a path relative to
 // /home/siafiles and not relative to root.
-func trimSiaDirFolder(dis ...modules.DirectoryInfo) (_ []modules.DirectoryInfo, err error) {
+func trimSiaDirFolder(req *http.Request, dis ...modules.DirectoryInfo) (_ []modules.DirectoryInfo, err error) {
+	folder, err := userFolder(req)
+	if err != nil {
+		return nil, err
+	}
 	for i := range dis {
-		dis[i].SiaPath, err = dis[i].SiaPath.Rebase(modules.UserFolder, modules.RootSiaPath())
+		dis[i].SiaPath, err = dis[i].SiaPath.Rebase(folder, modules.RootSiaPath())
 		if err != nil {
 			return nil, err
 		}
@@ -301,9 +334,13 @@ func trimSiaDirFolder(dis ...modules.DirectoryInfo) (_ []modules.DirectoryInfo,
 // trimSiaDirFolderOnFiles is a helper method to trim /home/siafiles off of the
 // siapaths of the fileinfos since the user expects a path relative to
 // /home/siafiles and not relative to root.
-func trimSiaDirFolderOnFiles(fis ...modules.FileInfo) (_ []modules.FileInfo, err error) {
+func trimSiaDirFolderOnFiles(req *http.Request, fis ...modules.FileInfo) (_ []modules.FileInfo, err error) {
+	folder, err := userFolder(req)
+	if err != nil {
+		return nil, err
+	}
 	for i := range fis {
-		fis[i].SiaPath, err = fis[i].SiaPath.Rebase(modules.UserFolder, modules.RootSiaPath())
+		fis[i].SiaPath, err = fis[i].SiaPath.Rebase(folder, modules.RootSiaPath())
 		if err != nil {
 			return nil, errors.AddContext(err, "unable to trim the user sia path from a provided fileinfo")
 		}
@@ -959,6 +996,30 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		settings.IPViolationCheck = ipviolationcheck
 	}
 
+	// Scan the host latency weight.
+	if hlw := req.FormValue("hostlatencyweight"); hlw != "" {
+		var weight float64
+		if _, err := fmt.Sscan(hlw, &weight); err != nil {
+			WriteError(w, Error{"unable to parse hostlatencyweight: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.HostLatencyWeight = weight
+	}
+
+	// Scan the host selection policy.
+	if hsp := req.FormValue("hostselectionpolicy"); hsp != "" {
+		var policy modules.HostSelectionPolicy
+		if err := json.Unmarshal([]byte(hsp), &policy); err != nil {
+			WriteError(w, Error{"unable to parse hostselectionpolicy: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err := policy.Validate(); err != nil {
+			WriteError(w, Error{"invalid hostselectionpolicy: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.HostSelectionPolicy = policy
+	}
+
 	// Set the settings in the renter.
 	err = api.renter.SetSettings(settings)
 	if err != nil {
@@ -1301,6 +1362,7 @@ func (api *API) renterDownloadsHandler(w http.ResponseWriter, req *http.Request,
 			StartTime:            di.StartTime,
 			StartTimeUnix:        di.StartTimeUnix,
 			TotalDataTransferred: di.TotalDataTransferred,
+			TraceID:              di.TraceID,
 		})
 	}
 	WriteJSON(w, RenterDownloadQueue{
@@ -1337,6 +1399,7 @@ func (api *API) renterDownloadByUIDHandlerGET(w http.ResponseWriter, _ *http.Req
 		StartTime:            di.StartTime,
 		StartTimeUnix:        di.StartTimeUnix,
 		TotalDataTransferred: di.TotalDataTransferred,
+		TraceID:              di.TraceID,
 	})
 }
 
@@ -1371,7 +1434,7 @@ func (api *API) renterFuseMountHandlerPOST(w http.ResponseWriter, req *http.Requ
 			return
 		}
 	}
-	siaPath, err = rebaseInputSiaPath(siaPath)
+	siaPath, err = rebaseInputSiaPath(req, siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -1453,12 +1516,12 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
 		}
-		newSiaPath, err = rebaseInputSiaPath(newSiaPath)
+		newSiaPath, err = rebaseInputSiaPath(req, newSiaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1472,6 +1535,242 @@ func (api *API) renterRenameHandler(w http.ResponseWriter, req *http.Request, ps
 	WriteSuccess(w)
 }
 
+// renterCopyHandler handles the API call to copy a file entry in the renter
+// without uploading its data again.
+func (api *API) renterCopyHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	// Parse the siaPath and the newSiaPath
+	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	newSiaPath, err := modules.NewSiaPath(req.FormValue("newsiapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// Determine whether the user is requesting a user siapath, or a root siapath.
+	root, err := isCalledWithRootFlag(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Rebase the user's input to the user folder if the user is requesting a user siapath.
+	if !root {
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		newSiaPath, err = rebaseInputSiaPath(req, newSiaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	err = api.renter.CopyFile(siaPath, newSiaPath)
+	if err != nil {
+		WriteError(w, Error{"failed to copy file: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterReencodeHandler handles the API call to re-encode a file with new
+// erasure coding and cipher parameters.
+func (api *API) renterReencodeHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Determine whether the user is requesting a user siapath, or a root siapath.
+	root, err := isCalledWithRootFlag(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Rebase the user's input to the user folder if the user is requesting a user siapath.
+	if !root {
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Parse the new parameters. Parameters that aren't set keep the value of
+	// the file.
+	ec, err := parseErasureCodingParameters(req.FormValue("datapieces"), req.FormValue("paritypieces"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse erasure code settings: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var cipherType crypto.CipherType
+	if ct := req.FormValue("ciphertype"); ct != "" {
+		if err := cipherType.FromString(ct); err != nil {
+			WriteError(w, Error{"unable to parse ciphertype: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if ec == nil && cipherType == (crypto.CipherType{}) {
+		WriteError(w, Error{"either erasure code settings or a cipher type need to be provided"}, http.StatusBadRequest)
+		return
+	}
+	err = api.renter.ReencodeFile(siaPath, ec, cipherType)
+	if err != nil {
+		WriteError(w, Error{"failed to re-encode file: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterVerifyHandler handles the API call to verify the data of a file or
+// the files within a directory against their checksums.
+func (api *API) renterVerifyHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Determine whether the user is requesting a user siapath, or a root siapath.
+	root, err := isCalledWithRootFlag(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Rebase the user's input to the user folder if the user is requesting a user siapath.
+	if !root {
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	recursive, err := scanBool(req.FormValue("recursive"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse recursive flag: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Directories are verified in the background.
+	if recursive {
+		if err := api.renter.VerifyDir(siaPath); err != nil {
+			WriteError(w, Error{"failed to verify directory: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+	fv, err := api.renter.VerifyFile(siaPath)
+	if err != nil {
+		WriteError(w, Error{"failed to verify file: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, fv)
+}
+
+// renterSyncHandlerGET handles the API call to /renter/sync.
+func (api *API) renterSyncHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	runs, err := api.renter.SyncRuns()
+	if err != nil {
+		WriteError(w, Error{"failed to get sync runs: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	for i := range runs {
+		runs[i] = trimSiaDirFolderOnSyncRun(runs[i])
+	}
+	WriteJSON(w, RenterSyncGET{Runs: runs})
+}
+
+// renterSyncHandlerPOST handles the API call to /renter/sync/:siapath.
+func (api *API) renterSyncHandlerPOST(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var siaPath modules.SiaPath
+	var err error
+	str := ps.ByName("siapath")
+	if str == "" || str == "/" {
+		siaPath = modules.RootSiaPath()
+	} else {
+		siaPath, err = modules.NewSiaPath(str)
+	}
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Determine whether the user is requesting a user siapath, or a root siapath.
+	root, err := isCalledWithRootFlag(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Rebase the user's input to the user folder if the user is requesting a user siapath.
+	if !root {
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	if err := req.ParseForm(); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	params := modules.SyncParams{
+		LocalPath: req.FormValue("localpath"),
+		SiaPath:   siaPath,
+		Include:   req.Form["include"],
+		Exclude:   req.Form["exclude"],
+	}
+	if !filepath.IsAbs(params.LocalPath) {
+		WriteError(w, Error{"localpath must be an absolute path"}, http.StatusBadRequest)
+		return
+	}
+	if err := params.ValidateGlobs(); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	params.Delete, err = scanBool(req.FormValue("delete"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse delete flag: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	dryRun, err := scanBool(req.FormValue("dryrun"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse dryrun flag: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	// A dry run only returns the plan of the sync.
+	var run modules.SyncRun
+	if dryRun {
+		run.Params = params
+		run.Actions, err = api.renter.SyncPlan(params)
+	} else {
+		run, err = api.renter.Sync(params)
+	}
+	if err != nil {
+		WriteError(w, Error{"failed to sync: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, trimSiaDirFolderOnSyncRun(run))
+}
+
+// trimSiaDirFolderOnSyncRun is a helper method to trim /home/siafiles off of
+// the siapaths of a sync run. Siapaths outside of /home/siafiles are kept.
+func trimSiaDirFolderOnSyncRun(run modules.SyncRun) modules.SyncRun {
+	trim := func(siaPath modules.SiaPath) modules.SiaPath {
+		rebased, err := siaPath.Rebase(modules.UserFolder, modules.RootSiaPath())
+		if err != nil {
+			return siaPath
+		}
+		return rebased
+	}
+	run.Params.SiaPath = trim(run.Params.SiaPath)
+	for i := range run.Actions {
+		run.Actions[i].SiaPath = trim(run.Actions[i].SiaPath)
+	}
+	return run
+}
+
 // renterFileHandler handles GET requests to the /renter/file/:siapath API endpoint.
 func (api *API) renterFileHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	// Determine the siapath that the user wants to get the file from.
@@ -1489,24 +1788,42 @@ func (api *API) renterFileHandlerGET(w http.ResponseWriter, req *http.Request, p
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
 
-	// Fetch the file.
+	// Fetch the prior versions of the file if requested.
+	var versions []modules.FileVersion
+	if v := req.FormValue("versions"); v != "" {
+		includeVersions, err := scanBool(v)
+		if err != nil {
+			WriteError(w, Error{"unable to parse 'versions' arg: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if includeVersions {
+			versions, err = api.renter.FileVersions(siaPath)
+			if err != nil {
+				WriteError(w, Error{"unable to get file versions: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+	}
+
+	// Fetch the file. A deleted file might still have prior versions which
+	// are returned without the file.
 	file, err := api.renter.File(siaPath)
-	if err != nil {
+	if err != nil && (len(versions) == 0 || !errors.Contains(err, filesystem.ErrNotExist)) {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
 
 	// If the user requested the user siapath, trim the dir folder so that the
 	// output is all centered around the user's folder.
-	if !root {
-		files, err := trimSiaDirFolderOnFiles(file)
+	if !root && err == nil {
+		files, err := trimSiaDirFolderOnFiles(req, file)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1515,7 +1832,8 @@ func (api *API) renterFileHandlerGET(w http.ResponseWriter, req *http.Request, p
 	}
 
 	WriteJSON(w, RenterFile{
-		File: file,
+		File:     file,
+		Versions: versions,
 	})
 }
 
@@ -1523,9 +1841,9 @@ func (api *API) renterFileHandlerGET(w http.ResponseWriter, req *http.Request, p
 func (api *API) renterFileHandlerPOST(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	newTrackingPath := req.FormValue("trackingpath")
 	stuck := req.FormValue("stuck")
-	root, err := scanBool(req.FormValue("root"))
+	root, err := isCalledWithRootFlag(req)
 	if err != nil {
-		WriteError(w, Error{"unable to parse root flag: " + err.Error()}, http.StatusBadRequest)
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
 	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
@@ -1534,7 +1852,7 @@ func (api *API) renterFileHandlerPOST(w http.ResponseWriter, req *http.Request,
 		return
 	}
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1547,6 +1865,25 @@ func (api *API) renterFileHandlerPOST(w http.ResponseWriter, req *http.Request,
 			return
 		}
 	}
+	// Handle restoring a prior version of a file.
+	if id := req.FormValue("restoreversion"); id != "" {
+		if err := api.renter.RestoreFileVersion(siaPath, id); err != nil {
+			WriteError(w, Error{"failed to restore file version: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	// Handle updating the user metadata of a file.
+	if md := req.FormValue("metadata"); md != "" {
+		update, err := parseUserMetadata(md)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		if err := api.renter.SetFileUserMetadata(siaPath, update); err != nil {
+			WriteError(w, Error{"failed to set user metadata: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
 	// Handle changing the 'stuck' status of a file.
 	if stuck != "" {
 		s, err := strconv.ParseBool(stuck)
@@ -1573,28 +1910,29 @@ func (api *API) renterFilesHandler(w http.ResponseWriter, req *http.Request, _ h
 			return
 		}
 	}
-	var files []modules.FileInfo
-	var mu sync.Mutex
-	err = api.renter.FileList(modules.UserFolder, true, c, func(fi modules.FileInfo) {
-		mu.Lock()
-		files = append(files, fi)
-		mu.Unlock()
-	})
+	q, err := parseFileQuery(req)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
-	// Sort slices by SiaPath.
-	sort.Slice(files, func(i, j int) bool {
-		return files[i].SiaPath.String() < files[j].SiaPath.String()
-	})
-	files, err = trimSiaDirFolderOnFiles(files...)
+	folder, err := userFolder(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	files, next, err := api.renter.QueryFiles(folder, true, c, q)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	files, err = trimSiaDirFolderOnFiles(req, files...)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusInternalServerError)
 		return
 	}
 	WriteJSON(w, RenterFiles{
-		Files: files,
+		Files:      files,
+		NextCursor: next,
 	})
 }
 
@@ -1697,7 +2035,7 @@ func (api *API) renterDeleteHandler(w http.ResponseWriter, req *http.Request, ps
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1805,10 +2143,6 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 	// If httprespparam is present, this parameter is ignored.
 	asyncparam := req.FormValue("async")
 
-	// Determines whether to interpret the siapath as a root path or originating
-	// from /home/user.
-	rootparam := req.FormValue("root")
-
 	// disablelocalfetchparam determines whether downloads will be fetched from
 	// disk if available.
 	disablelocalfetchparam := req.FormValue("disablelocalfetch")
@@ -1840,10 +2174,10 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 		return modules.RenterDownloadParameters{}, errors.AddContext(err, "async parameter could not be parsed")
 	}
 
-	// Parse the root parameter. If it is set we rebase the siapath.
-	root, err := scanBool(rootparam)
+	// Parse the root parameter. If it is not set we rebase the siapath.
+	root, err := isCalledWithRootFlag(req)
 	if err != nil {
-		return modules.RenterDownloadParameters{}, errors.AddContext(err, "root parameter could not be parsed")
+		return modules.RenterDownloadParameters{}, err
 	}
 
 	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
@@ -1853,7 +2187,7 @@ func parseDownloadParameters(w http.ResponseWriter, req *http.Request, ps httpro
 
 	// If root is not set we need to rebase the siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			return modules.RenterDownloadParameters{}, err
 		}
@@ -1889,14 +2223,13 @@ func (api *API) renterStreamHandler(w http.ResponseWriter, req *http.Request, ps
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
-	root, err := scanBool(req.FormValue("root"))
+	root, err := isCalledWithRootFlag(req)
 	if err != nil {
-		err = errors.AddContext(err, "error parsing the root flag")
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -1949,6 +2282,18 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		WriteError(w, Error{"unable to parse erasure code settings: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
+	// Parse the compression.
+	compression, err := modules.NewCompressionType(req.FormValue("compression"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse 'compression' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	// Parse the checksum type.
+	checksum, err := modules.NewChecksumType(req.FormValue("checksum"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse 'checksum' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 
 	// Call the renter to upload the file.
 	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
@@ -1956,7 +2301,7 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
-	siaPath, err = rebaseInputSiaPath(siaPath)
+	siaPath, err = rebaseInputSiaPath(req, siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -1967,9 +2312,11 @@ func (api *API) renterUploadHandler(w http.ResponseWriter, req *http.Request, ps
 		ErasureCode:         ec,
 		Force:               force,
 		DisablePartialChunk: true, // TODO: remove this
+		Compression:         compression,
+		Checksum:            checksum,
 
-		// NOTE: can make this an optional param.
-		CipherType: crypto.TypeDefaultRenter,
+		// NOTE: the cipher type is taken from the storage policy of the
+		// directory or the renter's default. Can make this an optional param.
 	})
 	if err != nil {
 		WriteError(w, Error{"upload failed: " + err.Error()}, http.StatusInternalServerError)
@@ -2080,6 +2427,22 @@ func (api *API) renterUploadStreamHandler(w http.ResponseWriter, req *http.Reque
 		WriteError(w, Error{"can't provide erasure code settings when doing a repair"}, http.StatusBadRequest)
 		return
 	}
+	// Parse the compression.
+	compression, err := modules.NewCompressionType(queryForm.Get("compression"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse 'compression' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if repair && compression != modules.CompressionNone {
+		WriteError(w, Error{"can't compress the data when doing a repair"}, http.StatusBadRequest)
+		return
+	}
+	// Parse the checksum type.
+	checksum, err := modules.NewChecksumType(queryForm.Get("checksum"))
+	if err != nil {
+		WriteError(w, Error{"unable to parse 'checksum' parameter: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 
 	// Call the renter to upload the file.
 	siaPath, err := modules.NewSiaPath(ps.ByName("siapath"))
@@ -2087,7 +2450,7 @@ func (api *API) renterUploadStreamHandler(w http.ResponseWriter, req *http.Reque
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
 	}
-	siaPath, err = rebaseInputSiaPath(siaPath)
+	siaPath, err = rebaseInputSiaPath(req, siaPath)
 	if err != nil {
 		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 		return
@@ -2097,9 +2460,11 @@ func (api *API) renterUploadStreamHandler(w http.ResponseWriter, req *http.Reque
 		ErasureCode: ec,
 		Force:       force,
 		Repair:      repair,
+		Compression: compression,
+		Checksum:    checksum,
 
-		// NOTE: can make this an optional param.
-		CipherType: crypto.TypeDefaultRenter,
+		// NOTE: the cipher type is taken from the storage policy of the
+		// directory or the renter's default. Can make this an optional param.
 	}
 	err = api.renter.UploadStreamFromReader(up, req.Body)
 	if err != nil {
@@ -2144,7 +2509,7 @@ func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2158,16 +2523,24 @@ func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	if !root {
-		directories, err = trimSiaDirFolder(directories...)
+		directories, err = trimSiaDirFolder(req, directories...)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
 
+	tags, err := parseUserMetadataTags(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
 	var files []modules.FileInfo
 	var mu sync.Mutex
 	err = api.renter.FileList(siaPath, false, true, func(fi modules.FileInfo) {
+		if !fi.UserMetadata.HasTags(tags) {
+			return
+		}
 		mu.Lock()
 		files = append(files, fi)
 		mu.Unlock()
@@ -2178,16 +2551,23 @@ func (api *API) renterDirHandlerGET(w http.ResponseWriter, req *http.Request, ps
 	}
 
 	if !root {
-		files, err = trimSiaDirFolderOnFiles(files...)
+		files, err = trimSiaDirFolderOnFiles(req, files...)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
 		}
 	}
 
+	policy, err := api.renter.StoragePolicy(siaPath)
+	if err != nil {
+		WriteError(w, Error{"failed to get storage policy: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+
 	WriteJSON(w, RenterDirectory{
-		Directories: directories,
-		Files:       files,
+		Directories:   directories,
+		Files:         files,
+		StoragePolicy: policy,
 	})
 	return
 }
@@ -2225,7 +2605,7 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2257,7 +2637,7 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 			WriteError(w, Error{"failed to parse newsiapath: " + err.Error()}, http.StatusBadRequest)
 			return
 		}
-		newSiaPath, err = rebaseInputSiaPath(newSiaPath)
+		newSiaPath, err = rebaseInputSiaPath(req, newSiaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -2271,11 +2651,160 @@ func (api *API) renterDirHandlerPOST(w http.ResponseWriter, req *http.Request, p
 		return
 	}
 
+	if action == "setversioning" {
+		var policy modules.VersioningPolicy
+		if mv := req.FormValue("maxversions"); mv != "" {
+			policy.MaxVersions, err = strconv.ParseUint(mv, 10, 64)
+			if err != nil {
+				WriteError(w, Error{"failed to parse maxversions: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+		}
+		if ma := req.FormValue("maxage"); ma != "" {
+			policy.MaxAge, err = time.ParseDuration(ma)
+			if err != nil || policy.MaxAge < 0 {
+				WriteError(w, Error{fmt.Sprintf("failed to parse maxage '%v'", ma)}, http.StatusBadRequest)
+				return
+			}
+		}
+		err = api.renter.SetVersioningPolicy(siaPath, policy)
+		if err != nil {
+			WriteError(w, Error{"failed to set versioning policy: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	if action == "setstoragepolicy" {
+		policy, err := parseStoragePolicy(req)
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		err = api.renter.SetStoragePolicy(siaPath, policy)
+		if err != nil {
+			WriteError(w, Error{"failed to set storage policy: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	if action == "setmetadata" {
+		update, err := parseUserMetadata(req.FormValue("metadata"))
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		err = api.renter.SetDirUserMetadata(siaPath, update)
+		if err != nil {
+			WriteError(w, Error{"failed to set user metadata: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
 	// Report that no calls were made
 	WriteError(w, Error{"no calls were made, please check your submission and try again"}, http.StatusInternalServerError)
 	return
 }
 
+// parseUserMetadata parses a json object of user metadata updates.
+func parseUserMetadata(str string) (modules.UserMetadata, error) {
+	var update modules.UserMetadata
+	if err := json.Unmarshal([]byte(str), &update); err != nil {
+		return nil, errors.AddContext(err, "failed to parse metadata")
+	}
+	if len(update) == 0 {
+		return nil, errors.New("metadata can't be empty")
+	}
+	return update, nil
+}
+
+// parseFileQuery parses the filter, order and pagination parameters of a
+// request listing files.
+func parseFileQuery(req *http.Request) (q modules.FileQuery, err error) {
+	q.Tags, err = parseUserMetadataTags(req)
+	if err != nil {
+		return modules.FileQuery{}, err
+	}
+	q.Filter, err = modules.ParseFileFilter(req.FormValue("filter"))
+	if err != nil {
+		return modules.FileQuery{}, err
+	}
+	q.Sort = req.FormValue("sort")
+	if err := modules.ValidateFileSort(q.Sort); err != nil {
+		return modules.FileQuery{}, err
+	}
+	switch order := req.FormValue("order"); order {
+	case "", "asc":
+	case "desc":
+		q.Descending = true
+	default:
+		return modules.FileQuery{}, fmt.Errorf("invalid order '%v', needs to be either 'asc' or 'desc'", order)
+	}
+	if limit := req.FormValue("limit"); limit != "" {
+		q.Limit, err = strconv.Atoi(limit)
+		if err != nil || q.Limit < 0 {
+			return modules.FileQuery{}, fmt.Errorf("invalid limit '%v'", limit)
+		}
+	}
+	q.Cursor = req.FormValue("cursor")
+	return q, nil
+}
+
+// parseUserMetadataTags parses the tags of a request that files are filtered
+// by.
+func parseUserMetadataTags(req *http.Request) (modules.UserMetadata, error) {
+	if err := req.ParseForm(); err != nil {
+		return nil, err
+	}
+	tags, err := modules.NewUserMetadataTags(req.Form["tag"])
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to parse tag")
+	}
+	return tags, nil
+}
+
+// parseStoragePolicy parses the storage policy of a /renter/dir request with
+// the setstoragepolicy action. Omitted parameters are left unset.
+func parseStoragePolicy(req *http.Request) (policy modules.StoragePolicy, err error) {
+	policy.DataPieces, policy.ParityPieces, err = ParseDataAndParityPieces(req.FormValue("datapieces"), req.FormValue("paritypieces"))
+	if err != nil {
+		return modules.StoragePolicy{}, err
+	}
+	if policy.DataPieces > 0 {
+		if _, err := parseErasureCodingParameters(req.FormValue("datapieces"), req.FormValue("paritypieces")); err != nil {
+			return modules.StoragePolicy{}, err
+		}
+	}
+	policy.CipherType = req.FormValue("ciphertype")
+	if rt := req.FormValue("repairthreshold"); rt != "" {
+		policy.RepairThreshold, err = strconv.ParseFloat(rt, 64)
+		if err != nil {
+			return modules.StoragePolicy{}, errors.AddContext(err, "failed to parse repairthreshold")
+		}
+	}
+	if rp := req.FormValue("repairpriority"); rp != "" {
+		policy.RepairPriority, err = strconv.Atoi(rp)
+		if err != nil {
+			return modules.StoragePolicy{}, errors.AddContext(err, "failed to parse repairpriority")
+		}
+	}
+	if ah := req.FormValue("allowedhosts"); ah != "" {
+		for _, str := range strings.Split(ah, ",") {
+			var pk types.SiaPublicKey
+			if err := pk.LoadString(str); err != nil {
+				return modules.StoragePolicy{}, errors.AddContext(err, "failed to parse allowedhosts")
+			}
+			policy.AllowedHosts = append(policy.AllowedHosts, pk)
+		}
+	}
+	return policy, policy.Validate()
+}
+
 // renterContractStatusHandler  handles the API call to check the status of a
 // contract monitored by the renter.
 func (api *API) renterContractStatusHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -2322,7 +2851,7 @@ func (api *API) renterFileHostsHandler(w http.ResponseWriter, req *http.Request,
 	}
 	// Rebase the user's input to the user folder if the user is requesting a user siapath.
 	if !root {
-		siaPath, err = rebaseInputSiaPath(siaPath)
+		siaPath, err = rebaseInputSiaPath(req, siaPath)
 		if err != nil {
 			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
 			return
@@ -35,17 +35,30 @@ func (api *API) buildHTTPRoutes() {
 
 	// Daemon API Calls
 	router.GET("/daemon/alerts", api.daemonAlertsHandlerGET)
+	router.GET("/daemon/alerts/history", api.daemonAlertsHistoryHandlerGET)
+	router.GET("/daemon/alerts/hooks", RequirePassword(api.daemonAlertsHooksHandlerGET, requiredPassword))
+	router.POST("/daemon/alerts/hooks/add", RequirePassword(api.daemonAlertsHooksAddHandlerPOST, requiredPassword))
+	router.POST("/daemon/alerts/hooks/remove", RequirePassword(api.daemonAlertsHooksRemoveHandlerPOST, requiredPassword))
 	router.GET("/daemon/constants", api.daemonConstantsHandler)
+	router.GET("/daemon/logging", api.daemonLoggingHandlerGET)
+	router.POST("/daemon/logging", RequirePassword(api.daemonLoggingHandlerPOST, requiredPassword))
 	router.GET("/daemon/settings", api.daemonSettingsHandlerGET)
 	router.POST("/daemon/settings", api.daemonSettingsHandlerPOST)
 	router.GET("/daemon/stack", api.daemonStackHandlerGET)
+	router.POST("/daemon/reload", RequirePassword(api.daemonReloadHandlerPOST, requiredPassword))
 	router.POST("/daemon/startprofile", api.daemonStartProfileHandlerPOST)
 	router.GET("/daemon/stop", RequirePassword(api.daemonStopHandler, requiredPassword))
 	router.POST("/daemon/stopprofile", api.daemonStopProfileHandlerPOST)
+	router.GET("/daemon/tokens", RequirePassword(api.daemonTokensHandlerGET, requiredPassword))
+	router.POST("/daemon/tokens/create", RequirePassword(api.daemonTokensCreateHandlerPOST, requiredPassword))
+	router.POST("/daemon/tokens/revoke", RequirePassword(api.daemonTokensRevokeHandlerPOST, requiredPassword))
 	router.GET("/daemon/update", api.daemonUpdateHandlerGET)
 	router.POST("/daemon/update", api.daemonUpdateHandlerPOST)
 	router.GET("/daemon/version", api.daemonVersionHandler)
 
+	// Metrics API Calls
+	router.GET("/metrics", RequirePassword(api.metricsHandlerGET, requiredPassword))
+
 	// Consensus API Calls
 	if api.cs != nil {
 		RegisterRoutesConsensus(router, api.cs)
@@ -102,19 +115,31 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/renter/fuse/mount", RequirePassword(api.renterFuseMountHandlerPOST, requiredPassword))
 		router.POST("/renter/fuse/unmount", RequirePassword(api.renterFuseUnmountHandlerPOST, requiredPassword))
 
+		router.POST("/renter/copy/*siapath", RequirePassword(api.renterCopyHandler, requiredPassword))
 		router.POST("/renter/delete/*siapath", RequirePassword(api.renterDeleteHandler, requiredPassword))
 		router.GET("/renter/download/*siapath", RequirePassword(api.renterDownloadHandler, requiredPassword))
 		router.POST("/renter/download/cancel", RequirePassword(api.renterCancelDownloadHandler, requiredPassword))
 		router.GET("/renter/downloadasync/*siapath", RequirePassword(api.renterDownloadAsyncHandler, requiredPassword))
+		router.POST("/renter/reencode/*siapath", RequirePassword(api.renterReencodeHandler, requiredPassword))
 		router.POST("/renter/rename/*siapath", RequirePassword(api.renterRenameHandler, requiredPassword))
 		router.GET("/renter/stream/*siapath", api.renterStreamHandler)
+		router.GET("/renter/sync", api.renterSyncHandlerGET)
+		router.POST("/renter/sync/*siapath", RequirePassword(api.renterSyncHandlerPOST, requiredPassword))
+		router.GET("/renter/tenants", RequirePassword(api.renterTenantsHandlerGET, requiredPassword))
+		router.POST("/renter/tenants/remove", RequirePassword(api.renterTenantsRemoveHandlerPOST, requiredPassword))
+		router.POST("/renter/tenants/reset", RequirePassword(api.renterTenantsResetHandlerPOST, requiredPassword))
+		router.POST("/renter/tenants/set", RequirePassword(api.renterTenantsSetHandlerPOST, requiredPassword))
 		router.POST("/renter/upload/*siapath", RequirePassword(api.renterUploadHandler, requiredPassword))
 		router.GET("/renter/uploadready", api.renterUploadReadyHandler)
 		router.POST("/renter/uploads/pause", RequirePassword(api.renterUploadsPauseHandler, requiredPassword))
 		router.POST("/renter/uploads/resume", RequirePassword(api.renterUploadsResumeHandler, requiredPassword))
 		router.POST("/renter/uploadstream/*siapath", RequirePassword(api.renterUploadStreamHandler, requiredPassword))
 		router.POST("/renter/validatesiapath/*siapath", RequirePassword(api.renterValidateSiaPathHandler, requiredPassword))
+		router.POST("/renter/verify/*siapath", RequirePassword(api.renterVerifyHandler, requiredPassword))
 		router.GET("/renter/workers", api.renterWorkersHandler)
+		router.GET("/renter/traces", api.renterTracesHandlerGET)
+		router.POST("/renter/traces", RequirePassword(api.renterTracesHandlerPOST, requiredPassword))
+		router.GET("/renter/traces/:id", api.renterTraceHandlerGET)
 		router.GET("/renter/hosts/*siapath", api.renterFileHostsHandler)
 
 		// Directory endpoints
@@ -128,6 +153,9 @@ func (api *API) buildHTTPRoutes() {
 		router.GET("/hostdb/hosts/:pubkey", api.hostdbHostsHandler)
 		router.GET("/hostdb/filtermode", api.hostdbFilterModeHandlerGET)
 		router.POST("/hostdb/filtermode", RequirePassword(api.hostdbFilterModeHandlerPOST, requiredPassword))
+		router.GET("/hostdb/scoring", api.hostdbScoringHandlerGET)
+		router.POST("/hostdb/scoring", RequirePassword(api.hostdbScoringHandlerPOST, requiredPassword))
+		router.POST("/hostdb/scoring/preview", RequirePassword(api.hostdbScoringPreviewHandlerPOST, requiredPassword))
 
 		// Renter watchdog endpoints.
 		router.GET("/renter/contractstatus", api.renterContractStatusHandler)
@@ -147,9 +175,9 @@ func (api *API) buildHTTPRoutes() {
 		RegisterRoutesWallet(router, api.wallet, requiredPassword)
 	}
 
-	// Apply UserAgent middleware and return the Router
+	// Apply UserAgent and token middleware and return the Router
 	api.routerMu.Lock()
-	api.router = timeoutHandler(RequireUserAgent(router, requiredUserAgent), httpServerTimeout)
+	api.router = timeoutHandler(RequireUserAgent(api.requireToken(router), requiredUserAgent), httpServerTimeout)
 	api.routerMu.Unlock()
 	return
 }
@@ -200,5 +228,5 @@ func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 
 // isUnrestricted checks if a request may bypass the useragent check.
 func isUnrestricted(req *http.Request) bool {
-	return strings.HasPrefix(req.URL.Path, "/renter/stream/")
+	return strings.HasPrefix(req.URL.Path, "/renter/stream/") || req.URL.Path == "/metrics"
 }
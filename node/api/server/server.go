@@ -4,6 +4,7 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"net/http"
@@ -31,6 +32,7 @@ type Server struct {
 	api               *api.API
 	apiServer         *http.Server
 	listener          net.Listener
+	tlsConfig         *tls.Config
 	node              *node.Node
 	requiredUserAgent string
 	Dir               string
@@ -67,6 +69,9 @@ func (srv *Server) Close() error {
 	if !errors.Contains(srv.serveErr, http.ErrServerClosed) {
 		err = errors.Compose(err, srv.serveErr)
 	}
+	// Persist the last usage of the API tokens and stop the alert monitor.
+	err = errors.Compose(err, srv.api.SaveTokens())
+	err = errors.Compose(err, srv.api.StopAlertMonitor())
 	// Shutdown modules.
 	if srv.node != nil {
 		err = errors.Compose(err, srv.node.Close())
@@ -84,6 +89,15 @@ func (srv *Server) APIAddress() string {
 	return srv.listener.Addr().String()
 }
 
+// TLSFingerprint returns the fingerprint of the API's TLS certificate or an
+// empty string if the API isn't served over TLS.
+func (srv *Server) TLSFingerprint() string {
+	if srv.tlsConfig == nil || len(srv.tlsConfig.Certificates) == 0 {
+		return ""
+	}
+	return api.CertificateFingerprint(srv.tlsConfig.Certificates[0].Certificate[0])
+}
+
 // GatewayAddress returns the underlying node's gateway address
 func (srv *Server) GatewayAddress() modules.NetAddress {
 	return srv.node.Gateway.Address()
@@ -116,6 +130,18 @@ func (srv *Server) RenterSettings() (modules.RenterSettings, error) {
 	return srv.node.Renter.Settings()
 }
 
+// SetConfigFile sets the path of the config file that is applied by
+// ReloadConfig.
+func (srv *Server) SetConfigFile(path string) {
+	srv.api.SetConfigFile(path)
+}
+
+// ReloadConfig applies the config file to the node's modules and returns the
+// changed settings.
+func (srv *Server) ReloadConfig() ([]api.ConfigChange, error) {
+	return srv.api.ReloadConfig()
+}
+
 // ServeErr is a blocking call that will return the result of srv.serve after
 // the server stopped.
 func (srv *Server) ServeErr() <-chan error {
@@ -156,6 +182,12 @@ func (srv *Server) Unlock(password string) error {
 // authentication sends passwords in plaintext and should therefore only be
 // used if the APIaddr is localhost.
 func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, <-chan error) {
+	return NewAsyncWithTLS(APIaddr, requiredUserAgent, requiredPassword, nil, nodeParams, loadStartTime)
+}
+
+// NewAsyncWithTLS creates a new API server like NewAsync which serves the API
+// over TLS if tlsConfig is not nil.
+func NewAsyncWithTLS(APIaddr string, requiredUserAgent string, requiredPassword string, tlsConfig *tls.Config, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, <-chan error) {
 	c := make(chan error, 1)
 	defer close(c)
 
@@ -167,6 +199,9 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 		if err != nil {
 			return nil, err
 		}
+		if tlsConfig != nil {
+			listener = tls.NewListener(listener, tlsConfig)
+		}
 
 		// Load the config file.
 		cfg, err := modules.NewConfig(filepath.Join(nodeParams.Dir, modules.ConfigName))
@@ -174,8 +209,16 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 			return nil, errors.AddContext(err, "failed to load siad config")
 		}
 
-		// Create the api for the server.
+		// Create the api for the server and load its tokens and alerts.
+		tokensPath := filepath.Join(nodeParams.Dir, api.TokensFile)
+		alertsPath := filepath.Join(nodeParams.Dir, api.AlertsFile)
 		api := api.New(cfg, requiredUserAgent, requiredPassword, nil, nil, nil, nil, nil, nil, nil, nil, nil)
+		if err := api.LoadTokens(tokensPath); err != nil {
+			return nil, errors.Compose(err, listener.Close())
+		}
+		if err := api.LoadAlerts(alertsPath); err != nil {
+			return nil, errors.Compose(err, listener.Close())
+		}
 		srv := &Server{
 			api: api,
 			apiServer: &http.Server{
@@ -201,6 +244,7 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 			closeChan:         make(chan struct{}),
 			serveChan:         make(chan struct{}),
 			listener:          listener,
+			tlsConfig:         tlsConfig,
 			requiredUserAgent: requiredUserAgent,
 			Dir:               nodeParams.Dir,
 		}
@@ -237,6 +281,7 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 		// Server wasn't shut down. Add node and replace modules.
 		srv.node = n
 		api.SetModules(n.Accounting, n.ConsensusSet, n.Explorer, n.Gateway, n.Host, n.Miner, n.Renter, n.TransactionPool, n.Wallet)
+		api.StartAlertMonitor()
 		return srv, nil
 	}()
 	if err != nil {
@@ -255,8 +300,14 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 // authentication sends passwords in plaintext and should therefore only be
 // used if the APIaddr is localhost.
 func New(APIaddr string, requiredUserAgent string, requiredPassword string, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, error) {
+	return NewWithTLS(APIaddr, requiredUserAgent, requiredPassword, nil, nodeParams, loadStartTime)
+}
+
+// NewWithTLS creates a new API server like New which serves the API over TLS
+// if tlsConfig is not nil.
+func NewWithTLS(APIaddr string, requiredUserAgent string, requiredPassword string, tlsConfig *tls.Config, nodeParams node.NodeParams, loadStartTime time.Time) (*Server, error) {
 	// Wait for the node to be done loading.
-	srv, errChan := NewAsync(APIaddr, requiredUserAgent, requiredPassword, nodeParams, loadStartTime)
+	srv, errChan := NewAsyncWithTLS(APIaddr, requiredUserAgent, requiredPassword, tlsConfig, nodeParams, loadStartTime)
 	if err := <-errChan; err != nil {
 		// Error occurred during async load. Close all modules.
 		if build.Release == "standard" || build.Release == "testnet" {
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+const (
+	// TLSCertFile is the name of the self-signed certificate of the API.
+	TLSCertFile = "apitls.crt"
+
+	// TLSKeyFile is the name of the key of the self-signed certificate of
+	// the API.
+	TLSKeyFile = "apitls.key"
+
+	// selfSignedCertValidity is the validity of the self-signed certificate.
+	selfSignedCertValidity = 10 * 365 * 24 * time.Hour
+)
+
+// TLSOptions are the options for serving the API over TLS.
+type TLSOptions struct {
+	// CertFile and KeyFile are the paths of the certificate and its key. If
+	// both are empty, a self-signed certificate is generated in the node's
+	// directory on first use.
+	CertFile string
+	KeyFile  string
+
+	// ClientCAFile is the path of the CA certificates that client
+	// certificates are verified against. If set, clients need to present a
+	// valid certificate.
+	ClientCAFile string
+}
+
+// NewTLSConfig creates the TLS config of the API server from the provided
+// options.
+func NewTLSConfig(dir string, opts TLSOptions) (*tls.Config, error) {
+	certFile, keyFile := opts.CertFile, opts.KeyFile
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("both the certificate and the key need to be provided")
+	}
+	if certFile == "" {
+		certFile = filepath.Join(dir, TLSCertFile)
+		keyFile = filepath.Join(dir, TLSKeyFile)
+		_, err := os.Stat(certFile)
+		if os.IsNotExist(err) {
+			err = generateSelfSignedCert(certFile, keyFile)
+		}
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to create self-signed certificate")
+		}
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return nil, errors.AddContext(err, "failed to load TLS certificate")
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}
+	if opts.ClientCAFile != "" {
+		pem, err := os.ReadFile(opts.ClientCAFile)
+		if err != nil {
+			return nil, errors.AddContext(err, "failed to read client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("client CA file doesn't contain any certificates")
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config, nil
+}
+
+// generateSelfSignedCert generates a self-signed certificate for localhost and
+// the hostname of the machine.
+func generateSelfSignedCert(certFile, keyFile string) error {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return err
+	}
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return err
+	}
+	dnsNames := []string{"localhost"}
+	if hostname, err := os.Hostname(); err == nil && hostname != "localhost" {
+		dnsNames = append(dnsNames, hostname)
+	}
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "siad"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(selfSignedCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageKeyEncipherment,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		DNSNames:              dnsNames,
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1), net.IPv6loopback},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return err
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(certFile), 0700); err != nil {
+		return err
+	}
+	err = os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/node"
+	"go.sia.tech/siad/node/api"
+	"go.sia.tech/siad/node/api/client"
+)
+
+// writeTestCert creates a certificate signed by parent, or a self-signed CA
+// certificate if parent is nil, and writes it and its key to dir.
+func writeTestCert(t *testing.T, dir, name string, parent *x509.Certificate, parentKey *ecdsa.PrivateKey) (*x509.Certificate, *ecdsa.PrivateKey) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+		template.KeyUsage |= x509.KeyUsageCertSign
+		parent, parentKey = template, key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert, key
+}
+
+// TestAPITLS tests serving the API over TLS with a self-signed certificate
+// and client certificates.
+func TestAPITLS(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir("server", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	// Create a CA and a client certificate signed by it.
+	ca, caKey := writeTestCert(t, dir, "ca", nil, nil)
+	writeTestCert(t, dir, "client", ca, caKey)
+
+	// The self-signed certificate is generated once.
+	nodeDir := filepath.Join(dir, "node")
+	tlsConfig, err := NewTLSConfig(nodeDir, TLSOptions{ClientCAFile: filepath.Join(dir, "ca.crt")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	fingerprint := api.CertificateFingerprint(tlsConfig.Certificates[0].Certificate[0])
+	tlsConfig2, err := NewTLSConfig(nodeDir, TLSOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if api.CertificateFingerprint(tlsConfig2.Certificates[0].Certificate[0]) != fingerprint {
+		t.Fatal("self-signed certificate was regenerated")
+	}
+	if _, err := NewTLSConfig(nodeDir, TLSOptions{CertFile: filepath.Join(dir, "client.crt")}); err == nil {
+		t.Fatal("certificate without key should be rejected")
+	}
+
+	// Start a server that requires client certificates.
+	srv, err := NewWithTLS("localhost:0", "Sia-Agent", "password", tlsConfig, node.Gateway(nodeDir), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := srv.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	if srv.TLSFingerprint() != fingerprint {
+		t.Fatal("unexpected fingerprint", srv.TLSFingerprint())
+	}
+
+	newClient := func(opts *client.TLSOptions) *client.Client {
+		c := client.New(client.Options{
+			Address:   srv.APIAddress(),
+			Password:  "password",
+			UserAgent: "Sia-Agent",
+		})
+		if opts != nil {
+			c.TLSConfig, err = client.NewTLSConfig(*opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return c
+	}
+
+	// Plain HTTP fails.
+	if _, err := newClient(nil).DaemonVersionGet(); err == nil {
+		t.Fatal("plain HTTP should fail")
+	}
+	// The certificate can't be verified without pinning it.
+	if _, err := newClient(&client.TLSOptions{CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")}).DaemonVersionGet(); err == nil {
+		t.Fatal("self-signed certificate shouldn't be trusted")
+	}
+	// A wrong fingerprint fails.
+	wrong := api.CertificateFingerprint([]byte("wrong"))
+	if _, err := newClient(&client.TLSOptions{Fingerprint: wrong, CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")}).DaemonVersionGet(); err == nil {
+		t.Fatal("wrong fingerprint should fail")
+	}
+	// The client certificate is required.
+	if _, err := newClient(&client.TLSOptions{Fingerprint: fingerprint}).DaemonVersionGet(); err == nil {
+		t.Fatal("missing client certificate should fail")
+	}
+	// The pinned fingerprint and the client certificate work.
+	c := newClient(&client.TLSOptions{Fingerprint: fingerprint, CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")})
+	if _, err := c.DaemonVersionGet(); err != nil {
+		t.Fatal(err)
+	}
+	// The CA of the self-signed certificate works as well.
+	c = newClient(&client.TLSOptions{CAFile: filepath.Join(nodeDir, TLSCertFile), CertFile: filepath.Join(dir, "client.crt"), KeyFile: filepath.Join(dir, "client.key")})
+	if _, err := c.GatewayGet(); err != nil {
+		t.Fatal(err)
+	}
+	// A CA file can't be combined with a pinned fingerprint.
+	if _, err := client.NewTLSConfig(client.TLSOptions{CAFile: filepath.Join(nodeDir, TLSCertFile), Fingerprint: fingerprint}); err == nil {
+		t.Fatal("CA file and fingerprint shouldn't be allowed together")
+	}
+	// A client that wasn't created with New works too.
+	var zero client.Client
+	zero.Options = c.Options
+	if _, err := zero.DaemonVersionGet(); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/modules"
+)
+
+type (
+	// RenterTenantsGET contains the tenants of the renter.
+	RenterTenantsGET struct {
+		Tenants []modules.TenantInfo `json:"tenants"`
+	}
+
+	// tenantContextKey is the key of the tenant of a request within the
+	// request's context.
+	tenantContextKey struct{}
+)
+
+var (
+	// errTenantRoot is returned if a tenant uses the 'root' flag.
+	errTenantRoot = errors.New("tenants can't use the 'root' arg")
+
+	// tenantRoutes are the routes that can be accessed with the API token of
+	// a tenant. A route matches the path itself and all paths below it.
+	tenantRoutes = map[string][]string{
+		http.MethodGet: {
+			"/renter/dir",
+			"/renter/download",
+			"/renter/file",
+			"/renter/files",
+			"/renter/stream",
+			"/renter/tenants",
+		},
+		http.MethodPost: {
+			"/renter/copy",
+			"/renter/delete",
+			"/renter/dir",
+			"/renter/rename",
+			"/renter/uploadstream",
+		},
+	}
+)
+
+// requestTenant returns the tenant of the request or an empty string if the
+// request wasn't made with the API token of a tenant.
+func requestTenant(req *http.Request) string {
+	tenant, _ := req.Context().Value(tenantContextKey{}).(string)
+	return tenant
+}
+
+// tenantAllows returns whether a tenant may access the route of the request.
+// Tenants can only access the files within their namespace, which is why
+// downloads to the disk of the renter aren't allowed.
+func tenantAllows(req *http.Request) bool {
+	for _, route := range tenantRoutes[req.Method] {
+		if req.URL.Path != route && !strings.HasPrefix(req.URL.Path, route+"/") {
+			continue
+		}
+		if route == "/renter/download" {
+			httpResp, err := strconv.ParseBool(req.FormValue("httpresp"))
+			return err == nil && httpResp
+		}
+		return true
+	}
+	return false
+}
+
+// userFolder returns the folder that the siapaths of a request are relative
+// to. That is the root of the request's tenant or the user's home directory.
+func userFolder(req *http.Request) (modules.SiaPath, error) {
+	tenant := requestTenant(req)
+	if tenant == "" {
+		return modules.UserFolder, nil
+	}
+	return modules.Tenant{Name: tenant}.Root()
+}
+
+// parseTenantQuota parses a quota of a tenant if it is set.
+func parseTenantQuota(req *http.Request, name string, quota *uint64) error {
+	str := req.FormValue(name)
+	if str == "" {
+		return nil
+	}
+	q, err := strconv.ParseUint(str, 10, 64)
+	if err != nil {
+		return errors.AddContext(err, "unable to parse "+name)
+	}
+	*quota = q
+	return nil
+}
+
+// renterTenantsHandlerGET handles the API call to /renter/tenants. Tenants
+// only get their own tenant.
+func (api *API) renterTenantsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if tenant := requestTenant(req); tenant != "" {
+		info, err := api.renter.Tenant(tenant)
+		if err != nil {
+			WriteError(w, Error{"unable to get tenant: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteJSON(w, RenterTenantsGET{Tenants: []modules.TenantInfo{info}})
+		return
+	}
+	tenants, err := api.renter.Tenants()
+	if err != nil {
+		WriteError(w, Error{"unable to get tenants: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, RenterTenantsGET{Tenants: tenants})
+}
+
+// renterTenantsSetHandlerPOST handles the API call to /renter/tenants/set.
+// Quotas that aren't set keep the value of an existing tenant.
+func (api *API) renterTenantsSetHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	tenant := modules.Tenant{Name: req.FormValue("name")}
+	if info, err := api.renter.Tenant(tenant.Name); err == nil {
+		tenant = info.Tenant
+	}
+	err := errors.Compose(
+		parseTenantQuota(req, "storagequota", &tenant.StorageQuota),
+		parseTenantQuota(req, "uploadquota", &tenant.UploadQuota),
+		parseTenantQuota(req, "downloadquota", &tenant.DownloadQuota),
+	)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.SetTenant(tenant); err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterTenantsRemoveHandlerPOST handles the API call to
+// /renter/tenants/remove. The API tokens of the tenant are revoked as well.
+func (api *API) renterTenantsRemoveHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if err := api.renter.RemoveTenant(name); err != nil {
+		WriteError(w, Error{"unable to remove tenant: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.staticTokens.RevokeTenant(name); err != nil {
+		WriteError(w, Error{"unable to revoke tokens of tenant: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterTenantsResetHandlerPOST handles the API call to /renter/tenants/reset.
+func (api *API) renterTenantsResetHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.renter.ResetTenantUsage(req.FormValue("name")); err != nil {
+		WriteError(w, Error{"unable to reset usage of tenant: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,22 @@
+package api
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// CertificateFingerprint returns the hex encoded SHA-256 fingerprint of a DER
+// encoded certificate. It's used for pinning the self-signed certificate of
+// the API.
+func CertificateFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeFingerprint converts a fingerprint to the format returned by
+// CertificateFingerprint. Fingerprints are commonly written with colons
+// between the bytes and in uppercase.
+func NormalizeFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(fingerprint), ":", ""))
+}
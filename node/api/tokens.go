@@ -0,0 +1,616 @@
+package api
+
+// tokens.go contains the API token subsystem. Tokens are an alternative to the
+// API password which only grant access to a subset of the API. They are used
+// in place of the API password for HTTP basic auth. When a request carries a
+// valid token, its scopes are checked against the requested route before the
+// request is forwarded to the router with the API password, which means that
+// the routes themselves don't need to be aware of tokens.
+//
+// Tokens are only stored as hashes. The secret of a token is returned once
+// when it is created and can't be recovered afterwards.
+//
+// Tokens of a tenant are additionally restricted to the file routes of the
+// renter. The tenant is attached to the context of the forwarded request and
+// the siapaths of the request are relative to the root of the tenant.
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/crypto"
+	"go.sia.tech/siad/persist"
+	"go.sia.tech/siad/types"
+)
+
+const (
+	// TokensFile is the name of the file the API tokens are persisted to.
+	TokensFile = "apitokens.json"
+
+	// tokenPrefix is the prefix of all token secrets. It allows for telling
+	// tokens and passwords apart.
+	tokenPrefix = "sia_"
+
+	// scopeAll grants access to all routes except for the token management.
+	scopeAll = "all"
+
+	// scopeWalletSend grants access to sending siacoins.
+	scopeWalletSend = "wallet:send"
+
+	// scopeWalletWrite grants access to all wallet routes.
+	scopeWalletWrite = "wallet:write"
+)
+
+var (
+	// lastUsedPersistInterval is the interval at which changes to the last
+	// used time of tokens are persisted. Persisting them on every request
+	// would be too expensive.
+	lastUsedPersistInterval = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: 10 * time.Minute,
+		Testnet:  10 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// tokenGroups are the route groups that can be used in scopes. The group
+	// of a route is the first element of its path.
+	tokenGroups = map[string]struct{}{
+		"consensus": {},
+		"daemon":    {},
+		"explorer":  {},
+		"gateway":   {},
+		"host":      {},
+		"hostdb":    {},
+		"metrics":   {},
+		"miner":     {},
+		"renter":    {},
+		"tpool":     {},
+		"wallet":    {},
+	}
+
+	// privilegedRoutes are the GET routes that require the 'all' scope or the
+	// write scope of their route group, since they reveal secrets, write to
+	// the disk of the node or change its state. Routes that end with a slash
+	// include all of their subroutes.
+	privilegedRoutes = []string{
+		"/daemon/stop",
+		"/miner/start",
+		"/miner/stop",
+		"/renter/download/",
+		"/renter/downloadasync/",
+		"/wallet/address",
+		"/wallet/backup",
+		"/wallet/seeds",
+		"/wallet/verifypassword",
+	}
+
+	// spendRoutes are the POST routes that spend the siacoins or siafunds of
+	// the wallet.
+	spendRoutes = map[string]struct{}{
+		"/wallet/approvals/approve": {},
+		"/wallet/siacoins":          {},
+		"/wallet/siafunds":          {},
+		"/wallet/sign":              {},
+	}
+
+	// tokensMetadata is the header of the tokens file.
+	tokensMetadata = persist.Metadata{
+		Header:  "API Tokens",
+		Version: "1.0.0",
+	}
+
+	// errTokenForbidden is returned if a token doesn't grant access to a
+	// route.
+	errTokenForbidden = errors.New("API token doesn't grant access to this route")
+
+	// errTokenExpired is returned if an expired token is used.
+	errTokenExpired = errors.New("API token expired")
+
+	// errSpendingLimit is returned if a send would exceed the spending limit
+	// of a token.
+	errSpendingLimit = errors.New("send exceeds the spending limit of the API token")
+
+	// errSpendingLimitRequired is returned if a token with the wallet:send
+	// scope is created without a spending limit.
+	errSpendingLimitRequired = errors.New("tokens with the wallet:send scope require a spending limit")
+
+	// errUnmeteredSpend is returned if a token with a spending limit is used
+	// for spending through a route other than /wallet/siacoins.
+	errUnmeteredSpend = errors.New("API tokens with a spending limit can only send siacoins using /wallet/siacoins")
+)
+
+type (
+	// APIToken contains the information about an API token that is exposed
+	// through the API.
+	APIToken struct {
+		Name          string         `json:"name"`
+		Scopes        []string       `json:"scopes"`
+		CreationTime  time.Time      `json:"creationtime"`
+		Expiry        time.Time      `json:"expiry"`
+		LastUsed      time.Time      `json:"lastused"`
+		SpendingLimit types.Currency `json:"spendinglimit"`
+		Spent         types.Currency `json:"spent"`
+		Tenant        string         `json:"tenant,omitempty"`
+	}
+
+	// DaemonTokensGET contains all API tokens.
+	DaemonTokensGET struct {
+		Tokens []APIToken `json:"tokens"`
+	}
+
+	// DaemonTokensCreatePOST contains a newly created API token and its
+	// secret.
+	DaemonTokensCreatePOST struct {
+		APIToken
+		Token string `json:"token"`
+	}
+
+	// persistToken is a token as it is persisted to disk.
+	persistToken struct {
+		APIToken
+		Hash crypto.Hash `json:"hash"`
+	}
+
+	// tokenStore manages the API tokens.
+	tokenStore struct {
+		tokens        map[crypto.Hash]*persistToken
+		path          string
+		lastPersisted time.Time
+		mu            sync.Mutex
+	}
+
+	// statusRecorder is a http.ResponseWriter that records the status code
+	// of the response.
+	statusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+// WriteHeader records the status code before writing it.
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Flush implements http.Flusher for streaming responses.
+func (sr *statusRecorder) Flush() {
+	if f, ok := sr.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// newTokenStore creates a new, empty token store which isn't persisted.
+func newTokenStore() *tokenStore {
+	return &tokenStore{
+		tokens: make(map[crypto.Hash]*persistToken),
+	}
+}
+
+// validateScope checks whether a scope is valid. Valid scopes are 'all',
+// 'wallet:send' and '<group>:read' or '<group>:write' for all route groups.
+func validateScope(scope string) error {
+	if scope == scopeAll || scope == scopeWalletSend {
+		return nil
+	}
+	parts := strings.Split(scope, ":")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid scope '%v'", scope)
+	}
+	if _, exists := tokenGroups[parts[0]]; !exists {
+		return fmt.Errorf("invalid scope '%v': unknown route group '%v'", scope, parts[0])
+	}
+	if parts[1] != "read" && parts[1] != "write" {
+		return fmt.Errorf("invalid scope '%v': access needs to be 'read' or 'write'", scope)
+	}
+	return nil
+}
+
+// routeGroup returns the route group of the given path.
+func routeGroup(path string) string {
+	return strings.SplitN(strings.TrimPrefix(path, "/"), "/", 2)[0]
+}
+
+// isSendRoute returns whether the request sends siacoins.
+func isSendRoute(req *http.Request) bool {
+	return req.Method == http.MethodPost && req.URL.Path == "/wallet/siacoins"
+}
+
+// isSpendRoute returns whether the request spends the funds of the wallet.
+func isSpendRoute(req *http.Request) bool {
+	_, spends := spendRoutes[req.URL.Path]
+	return req.Method == http.MethodPost && spends
+}
+
+// isPrivilegedRoute returns whether the path belongs to a privileged route.
+func isPrivilegedRoute(path string) bool {
+	for _, route := range privilegedRoutes {
+		if path == route || (strings.HasSuffix(route, "/") && strings.HasPrefix(path, route)) {
+			return true
+		}
+	}
+	return false
+}
+
+// spendingLimited returns whether the spending of a token is limited. That is
+// the case if it has a spending limit or if it can only send siacoins through
+// the wallet:send scope.
+func spendingLimited(token APIToken) bool {
+	if !token.SpendingLimit.IsZero() {
+		return true
+	}
+	for _, scope := range token.Scopes {
+		if scope == scopeAll || scope == scopeWalletWrite {
+			return false
+		}
+	}
+	return true
+}
+
+// tokenAllows returns whether a token with the given scopes may access the
+// route of the request.
+func tokenAllows(scopes []string, req *http.Request) bool {
+	group := routeGroup(req.URL.Path)
+	for _, scope := range scopes {
+		switch {
+		case scope == scopeAll:
+			return true
+		case scope == scopeWalletSend && isSendRoute(req):
+			return true
+		case scope == group+":write":
+			return true
+		case scope == group+":read" && (req.Method == http.MethodGet || req.Method == http.MethodHead) && !isPrivilegedRoute(req.URL.Path):
+			return true
+		}
+	}
+	return false
+}
+
+// sendAmount returns the amount of siacoins a request to /wallet/siacoins
+// sends, excluding fees.
+func sendAmount(req *http.Request) (types.Currency, error) {
+	if outputsStr := req.FormValue("outputs"); outputsStr != "" {
+		var outputs []types.SiacoinOutput
+		if err := json.Unmarshal([]byte(outputsStr), &outputs); err != nil {
+			return types.Currency{}, errors.AddContext(err, "could not decode outputs")
+		}
+		var total types.Currency
+		for _, o := range outputs {
+			total = total.Add(o.Value)
+		}
+		return total, nil
+	}
+	amount, ok := scanAmount(req.FormValue("amount"))
+	if !ok {
+		return types.Currency{}, errors.New("could not read amount")
+	}
+	return amount, nil
+}
+
+// load loads the tokens from the file at the given path. If the file doesn't
+// exist, the store starts out empty. Either way, changes are persisted to the
+// file afterwards.
+func (ts *tokenStore) load(path string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	ts.path = path
+	var tokens []persistToken
+	err := persist.LoadJSON(tokensMetadata, &tokens, path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.AddContext(err, "failed to load API tokens")
+	}
+	ts.tokens = make(map[crypto.Hash]*persistToken)
+	for i := range tokens {
+		ts.tokens[tokens[i].Hash] = &tokens[i]
+	}
+	return nil
+}
+
+// save persists the tokens if the store has a path.
+func (ts *tokenStore) save() error {
+	if ts.path == "" {
+		return nil
+	}
+	tokens := make([]persistToken, 0, len(ts.tokens))
+	for _, t := range ts.tokens {
+		tokens = append(tokens, *t)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+	ts.lastPersisted = time.Now()
+	return persist.SaveJSON(tokensMetadata, tokens, ts.path)
+}
+
+// Create creates a new token and returns its secret. Tokens with a tenant can
+// only access the files of the tenant.
+func (ts *tokenStore) Create(name string, scopes []string, expiry time.Time, spendingLimit types.Currency, tenant string) (APIToken, string, error) {
+	if name == "" {
+		return APIToken{}, "", errors.New("token name can't be empty")
+	}
+	if len(scopes) == 0 {
+		return APIToken{}, "", errors.New("token needs at least one scope")
+	}
+	for _, scope := range scopes {
+		if err := validateScope(scope); err != nil {
+			return APIToken{}, "", err
+		}
+		if scope == scopeWalletSend && spendingLimit.IsZero() {
+			return APIToken{}, "", errSpendingLimitRequired
+		}
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for _, t := range ts.tokens {
+		if t.Name == name {
+			return APIToken{}, "", fmt.Errorf("token '%v' already exists", name)
+		}
+	}
+	secret := tokenPrefix + hex.EncodeToString(fastrand.Bytes(32))
+	token := &persistToken{
+		APIToken: APIToken{
+			Name:          name,
+			Scopes:        scopes,
+			CreationTime:  time.Now(),
+			Expiry:        expiry,
+			SpendingLimit: spendingLimit,
+			Tenant:        tenant,
+		},
+		Hash: crypto.HashBytes([]byte(secret)),
+	}
+	ts.tokens[token.Hash] = token
+	if err := ts.save(); err != nil {
+		delete(ts.tokens, token.Hash)
+		return APIToken{}, "", errors.AddContext(err, "failed to persist token")
+	}
+	return token.APIToken, secret, nil
+}
+
+// Revoke removes the token with the given name.
+func (ts *tokenStore) Revoke(name string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	for hash, t := range ts.tokens {
+		if t.Name == name {
+			delete(ts.tokens, hash)
+			return ts.save()
+		}
+	}
+	return fmt.Errorf("token '%v' doesn't exist", name)
+}
+
+// RevokeTenant removes all tokens of a tenant.
+func (ts *tokenStore) RevokeTenant(tenant string) error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	var revoked bool
+	for hash, t := range ts.tokens {
+		if t.Tenant == tenant {
+			delete(ts.tokens, hash)
+			revoked = true
+		}
+	}
+	if !revoked {
+		return nil
+	}
+	return ts.save()
+}
+
+// Tokens returns all tokens sorted by name.
+func (ts *tokenStore) Tokens() []APIToken {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	tokens := make([]APIToken, 0, len(ts.tokens))
+	for _, t := range ts.tokens {
+		tokens = append(tokens, t.APIToken)
+	}
+	sort.Slice(tokens, func(i, j int) bool {
+		return tokens[i].Name < tokens[j].Name
+	})
+	return tokens
+}
+
+// managedAuthorize checks whether the token with the given secret grants
+// access to the route of the request. If the spending of the token is limited,
+// it can only spend siacoins using /wallet/siacoins. The amount is reserved
+// from the token's spending limit and the returned refund function needs to be
+// called if the send fails. The tenant of the token is
+// returned as well. The bool indicates whether the secret belongs to a token
+// at all.
+func (ts *tokenStore) managedAuthorize(secret string, req *http.Request) (refund func(), tenant string, isToken bool, err error) {
+	hash := crypto.HashBytes([]byte(secret))
+
+	// Parse the amount before acquiring the lock.
+	var amount types.Currency
+	var amountErr error
+	if isSendRoute(req) {
+		amount, amountErr = sendAmount(req)
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	token, exists := ts.tokens[hash]
+	if !exists {
+		return nil, "", false, nil
+	}
+	now := time.Now()
+	if !token.Expiry.IsZero() && now.After(token.Expiry) {
+		return nil, "", true, errTokenExpired
+	}
+	if !tokenAllows(token.Scopes, req) || (token.Tenant != "" && !tenantAllows(req)) {
+		return nil, "", true, errTokenForbidden
+	}
+
+	// Track the last use of the token. Only persist it occasionally and on
+	// shutdown.
+	persistNow := token.LastUsed.IsZero() || now.Sub(ts.lastPersisted) > lastUsedPersistInterval
+	token.LastUsed = now
+
+	// Reserve the amount of a send from the spending limit. Other routes that
+	// spend can't be metered and are rejected.
+	refund = func() {}
+	if isSpendRoute(req) && spendingLimited(token.APIToken) {
+		if !isSendRoute(req) {
+			return nil, "", true, errUnmeteredSpend
+		}
+		if amountErr != nil {
+			return nil, "", true, amountErr
+		}
+		if token.Spent.Add(amount).Cmp(token.SpendingLimit) > 0 {
+			return nil, "", true, errSpendingLimit
+		}
+		token.Spent = token.Spent.Add(amount)
+		persistNow = true
+		refund = func() {
+			ts.mu.Lock()
+			defer ts.mu.Unlock()
+			token.Spent = token.Spent.Sub(amount)
+			_ = ts.save()
+		}
+	}
+	if persistNow {
+		if err := ts.save(); err != nil {
+			return nil, "", true, errors.AddContext(err, "failed to persist token usage")
+		}
+	}
+	return refund, token.Tenant, true, nil
+}
+
+// managedSave persists the tokens.
+func (ts *tokenStore) managedSave() error {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+	return ts.save()
+}
+
+// LoadTokens loads the API tokens from the given file and persists all changes
+// to the tokens to it.
+func (api *API) LoadTokens(path string) error {
+	return api.staticTokens.load(path)
+}
+
+// SaveTokens persists the API tokens including their most recent usage.
+func (api *API) SaveTokens() error {
+	return api.staticTokens.managedSave()
+}
+
+// requireToken is middleware that checks the scopes of requests that
+// authenticate with an API token instead of the API password. Authorized
+// requests are forwarded with the API password. Requests with unknown tokens
+// are rejected, even for routes that don't require the API password. Requests
+// without a token are forwarded unchanged.
+func (api *API) requireToken(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, secret, ok := req.BasicAuth()
+		if !ok || api.requiredPassword == "" || secret == api.requiredPassword || !strings.HasPrefix(secret, tokenPrefix) {
+			h.ServeHTTP(w, req)
+			return
+		}
+		// Tokens can't be used to manage tokens or alert hooks, since hooks
+		// can run local commands.
+		if strings.HasPrefix(req.URL.Path, "/daemon/tokens") || strings.HasPrefix(req.URL.Path, "/daemon/alerts/hooks") {
+			WriteError(w, Error{errTokenForbidden.Error()}, http.StatusForbidden)
+			return
+		}
+		refund, tenant, isToken, err := api.staticTokens.managedAuthorize(secret, req)
+		if !isToken {
+			w.Header().Set("WWW-Authenticate", "Basic realm=\"SiaAPI\"")
+			WriteError(w, Error{"API authentication failed."}, http.StatusUnauthorized)
+			return
+		}
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusForbidden)
+			return
+		}
+		authorized := req.Clone(context.WithValue(req.Context(), tenantContextKey{}, tenant))
+		authorized.SetBasicAuth("", api.requiredPassword)
+		sr := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		h.ServeHTTP(sr, authorized)
+		if sr.status >= http.StatusBadRequest {
+			refund()
+		}
+	})
+}
+
+// daemonTokensHandlerGET handles the API call that lists the API tokens.
+func (api *API) daemonTokensHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, DaemonTokensGET{
+		Tokens: api.staticTokens.Tokens(),
+	})
+}
+
+// daemonTokensCreateHandlerPOST handles the API call that creates an API
+// token.
+func (api *API) daemonTokensCreateHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var scopes []string
+	for _, scope := range strings.Split(req.FormValue("scopes"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	var expiry time.Time
+	if e := req.FormValue("expiry"); e != "" {
+		d, err := time.ParseDuration(e)
+		if err != nil || d <= 0 {
+			WriteError(w, Error{fmt.Sprintf("unable to parse expiry '%v'", e)}, http.StatusBadRequest)
+			return
+		}
+		expiry = time.Now().Add(d)
+	}
+	var spendingLimit types.Currency
+	if sl := req.FormValue("spendinglimit"); sl != "" {
+		var ok bool
+		spendingLimit, ok = scanAmount(sl)
+		if !ok {
+			WriteError(w, Error{"unable to parse spendinglimit"}, http.StatusBadRequest)
+			return
+		}
+	}
+	tenant := req.FormValue("tenant")
+	if tenant != "" {
+		if api.renter == nil {
+			WriteError(w, Error{"tokens of tenants require the renter"}, http.StatusBadRequest)
+			return
+		}
+		if _, err := api.renter.Tenant(tenant); err != nil {
+			WriteError(w, Error{"failed to create token: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	token, secret, err := api.staticTokens.Create(req.FormValue("name"), scopes, expiry, spendingLimit, tenant)
+	if err != nil {
+		WriteError(w, Error{"failed to create token: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, DaemonTokensCreatePOST{
+		APIToken: token,
+		Token:    secret,
+	})
+}
+
+// daemonTokensRevokeHandlerPOST handles the API call that revokes an API
+// token.
+func (api *API) daemonTokensRevokeHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.staticTokens.Revoke(req.FormValue("name")); err != nil {
+		WriteError(w, Error{"failed to revoke token: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -0,0 +1,375 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/types"
+)
+
+// TestValidateScope tests validating token scopes.
+func TestValidateScope(t *testing.T) {
+	t.Parallel()
+
+	for _, scope := range []string{"all", "wallet:send", "renter:read", "host:write", "hostdb:read", "metrics:read"} {
+		if err := validateScope(scope); err != nil {
+			t.Error("valid scope was rejected", scope, err)
+		}
+	}
+	for _, scope := range []string{"", "renter", "renter:admin", "unknown:read", "renter:read:write", "wallet:sends"} {
+		if err := validateScope(scope); err == nil {
+			t.Error("invalid scope was accepted", scope)
+		}
+	}
+}
+
+// TestTokenAllows tests checking the scopes of a token against routes.
+func TestTokenAllows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		scopes  []string
+		method  string
+		path    string
+		allowed bool
+	}{
+		{[]string{"renter:read"}, http.MethodGet, "/renter/files", true},
+		{[]string{"renter:read"}, http.MethodPost, "/renter/upload/foo", false},
+		{[]string{"renter:read"}, http.MethodGet, "/wallet", false},
+		{[]string{"renter:write"}, http.MethodPost, "/renter/upload/foo", true},
+		{[]string{"renter:write"}, http.MethodGet, "/renter", true},
+		{[]string{"renter:read"}, http.MethodGet, "/renterx", false},
+		{[]string{"host:read"}, http.MethodGet, "/hostdb", false},
+		{[]string{"wallet:send"}, http.MethodPost, "/wallet/siacoins", true},
+		{[]string{"wallet:send"}, http.MethodPost, "/wallet/siafunds", false},
+		{[]string{"wallet:send"}, http.MethodGet, "/wallet", false},
+		{[]string{"wallet:send", "wallet:read"}, http.MethodGet, "/wallet", true},
+		{[]string{"all"}, http.MethodPost, "/host/announce", true},
+		{[]string{"wallet:read"}, http.MethodGet, "/wallet/addresses", true},
+		{[]string{"wallet:read"}, http.MethodGet, "/wallet/address", false},
+		{[]string{"wallet:read"}, http.MethodGet, "/wallet/seeds", false},
+		{[]string{"wallet:write"}, http.MethodGet, "/wallet/seeds", true},
+		{[]string{"renter:read"}, http.MethodGet, "/renter/downloads", true},
+		{[]string{"renter:read"}, http.MethodGet, "/renter/download/foo", false},
+		{[]string{"renter:write"}, http.MethodGet, "/renter/download/foo", true},
+		{[]string{"daemon:read"}, http.MethodGet, "/daemon/stop", false},
+		{[]string{"all"}, http.MethodGet, "/daemon/stop", true},
+		{[]string{"miner:read"}, http.MethodGet, "/miner", true},
+		{[]string{"miner:read"}, http.MethodGet, "/miner/start", false},
+		{[]string{"miner:read"}, http.MethodGet, "/miner/stop", false},
+		{[]string{"miner:write"}, http.MethodGet, "/miner/start", true},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if tokenAllows(test.scopes, req) != test.allowed {
+			t.Errorf("%v %v with %v: expected allowed to be %v", test.method, test.path, test.scopes, test.allowed)
+		}
+	}
+}
+
+// TestTenantAllows tests checking the routes that tenants can access.
+func TestTenantAllows(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		method  string
+		path    string
+		allowed bool
+	}{
+		{http.MethodGet, "/renter/files", true},
+		{http.MethodGet, "/renter/file/foo", true},
+		{http.MethodGet, "/renter/dir/", true},
+		{http.MethodGet, "/renter/stream/foo", true},
+		{http.MethodGet, "/renter/tenants", true},
+		{http.MethodPost, "/renter/uploadstream/foo", true},
+		{http.MethodPost, "/renter/copy/foo", true},
+		{http.MethodGet, "/renter/download/foo?httpresp=true", true},
+		{http.MethodGet, "/renter/download/foo?destination=/tmp/foo", false},
+		{http.MethodPost, "/renter/upload/foo", false},
+		{http.MethodPost, "/renter/file/foo", false},
+		{http.MethodPost, "/renter/tenants/set", false},
+		{http.MethodPost, "/renter/sync/foo", false},
+		{http.MethodGet, "/renter/filesx", false},
+		{http.MethodGet, "/renter/contracts", false},
+		{http.MethodGet, "/wallet", false},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest(test.method, test.path, nil)
+		if tenantAllows(req) != test.allowed {
+			t.Errorf("%v %v: expected allowed to be %v", test.method, test.path, test.allowed)
+		}
+	}
+}
+
+// TestIsCalledWithRootFlag tests that only tenants can't use the 'root' flag.
+func TestIsCalledWithRootFlag(t *testing.T) {
+	t.Parallel()
+
+	// Requests without authentication can use the flag.
+	req := httptest.NewRequest(http.MethodGet, "/renter/files?root=true", nil)
+	if root, err := isCalledWithRootFlag(req); err != nil || !root {
+		t.Fatal("expected root flag to be set", root, err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/renter/files?root=foo", nil)
+	if _, err := isCalledWithRootFlag(req); err == nil {
+		t.Fatal("expected unparsable flag to be rejected")
+	}
+
+	// Tenants can't.
+	ctx := context.WithValue(req.Context(), tenantContextKey{}, "team")
+	req = httptest.NewRequest(http.MethodGet, "/renter/files?root=true", nil).WithContext(ctx)
+	if _, err := isCalledWithRootFlag(req); !errors.Contains(err, errTenantRoot) {
+		t.Fatal("expected errTenantRoot but got", err)
+	}
+	req = httptest.NewRequest(http.MethodGet, "/renter/files?root=false", nil).WithContext(ctx)
+	if root, err := isCalledWithRootFlag(req); err != nil || root {
+		t.Fatal("expected root flag to be unset", root, err)
+	}
+}
+
+// TestTenantDownloadRoot tests that tenants can't download files outside of
+// their namespace by using the 'root' flag.
+func TestTenantDownloadRoot(t *testing.T) {
+	t.Parallel()
+
+	api := &API{}
+	ctx := context.WithValue(context.Background(), tenantContextKey{}, "team")
+	req := httptest.NewRequest(http.MethodGet, "/renter/download/foo?httpresp=true&root=true", nil).WithContext(ctx)
+	if !tenantAllows(req) {
+		t.Fatal("tenant should be able to access the route")
+	}
+	ps := httprouter.Params{{Key: "siapath", Value: "foo"}}
+	rec := httptest.NewRecorder()
+	api.renterDownloadHandler(rec, req, ps)
+	if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), errTenantRoot.Error()) {
+		t.Fatal("expected errTenantRoot but got", rec.Code, rec.Body.String())
+	}
+}
+
+// TestTokenStore tests creating, persisting and revoking tokens.
+func TestTokenStore(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir("api", t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, TokensFile)
+
+	ts := newTokenStore()
+	if err := ts.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := ts.Create("", []string{"all"}, time.Time{}, types.ZeroCurrency, ""); err == nil {
+		t.Fatal("token without name shouldn't be created")
+	}
+	if _, _, err := ts.Create("foo", nil, time.Time{}, types.ZeroCurrency, ""); err == nil {
+		t.Fatal("token without scopes shouldn't be created")
+	}
+	if _, _, err := ts.Create("foo", []string{"wallet:send"}, time.Time{}, types.ZeroCurrency, ""); err != errSpendingLimitRequired {
+		t.Fatal("expected errSpendingLimitRequired but got", err)
+	}
+	token, secret, err := ts.Create("foo", []string{"renter:read"}, time.Time{}, types.ZeroCurrency, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(secret, tokenPrefix) || token.Name != "foo" {
+		t.Fatal("unexpected token", token, secret)
+	}
+	if _, _, err := ts.Create("foo", []string{"renter:read"}, time.Time{}, types.ZeroCurrency, ""); err == nil {
+		t.Fatal("token names should be unique")
+	}
+
+	// The secret isn't persisted but the token can be used after loading.
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), secret) {
+		t.Fatal("secret was persisted")
+	}
+	ts2 := newTokenStore()
+	if err := ts2.load(path); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, isToken, err := ts2.managedAuthorize(secret, httptest.NewRequest(http.MethodGet, "/renter", nil)); !isToken || err != nil {
+		t.Fatal("loaded token should be usable", isToken, err)
+	}
+
+	// Revoke the token.
+	if err := ts2.Revoke("foo"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ts2.Revoke("foo"); err == nil {
+		t.Fatal("revoking a token twice should fail")
+	}
+	if _, _, isToken, _ := ts2.managedAuthorize(secret, httptest.NewRequest(http.MethodGet, "/renter", nil)); isToken {
+		t.Fatal("revoked token shouldn't be usable")
+	}
+
+	// Tokens of a tenant are restricted to the tenant's routes and revoked
+	// together with the tenant.
+	_, secret, err = ts2.Create("tenant", []string{"renter:write"}, time.Time{}, types.ZeroCurrency, "team")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, _, err := ts2.managedAuthorize(secret, httptest.NewRequest(http.MethodGet, "/renter", nil)); err != errTokenForbidden {
+		t.Fatal("expected errTokenForbidden but got", err)
+	}
+	if _, tenant, isToken, err := ts2.managedAuthorize(secret, httptest.NewRequest(http.MethodGet, "/renter/files", nil)); !isToken || err != nil || tenant != "team" {
+		t.Fatal("token of tenant should be usable", tenant, isToken, err)
+	}
+	if err := ts2.RevokeTenant("team"); err != nil {
+		t.Fatal(err)
+	}
+	if len(ts2.Tokens()) != 0 {
+		t.Fatal("token of tenant wasn't revoked", ts2.Tokens())
+	}
+}
+
+// TestRequireToken tests the token middleware including expiry and spending
+// limits.
+func TestRequireToken(t *testing.T) {
+	t.Parallel()
+
+	const password = "password"
+	api := &API{
+		requiredPassword: password,
+		staticTokens:     newTokenStore(),
+	}
+
+	// The wrapped handler succeeds if the password was set and fails sends
+	// to a special destination.
+	h := api.requireToken(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if _, pw, _ := req.BasicAuth(); pw != password {
+			WriteError(w, Error{"wrong password"}, http.StatusUnauthorized)
+			return
+		}
+		if req.FormValue("destination") == "fail" {
+			WriteError(w, Error{"send failed"}, http.StatusInternalServerError)
+			return
+		}
+		WriteSuccess(w)
+	}))
+	do := func(secret, method, path string, values url.Values) int {
+		req := httptest.NewRequest(method, path, strings.NewReader(values.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.SetBasicAuth("", secret)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	send := func(secret string, amount uint64, dest string) int {
+		values := url.Values{}
+		values.Set("amount", types.NewCurrency64(amount).String())
+		values.Set("destination", dest)
+		return do(secret, http.MethodPost, "/wallet/siacoins", values)
+	}
+
+	// The password is still accepted.
+	if code := do(password, http.MethodPost, "/renter", nil); code != http.StatusNoContent {
+		t.Fatal("password should be accepted", code)
+	}
+
+	// Create a token with a spending limit.
+	_, secret, err := api.staticTokens.Create("send", []string{"wallet:send"}, time.Time{}, types.NewCurrency64(100), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := do(secret, http.MethodPost, "/renter", nil); code != http.StatusForbidden {
+		t.Fatal("token shouldn't grant access to the renter", code)
+	}
+	if code := do(secret, http.MethodGet, "/daemon/tokens", nil); code != http.StatusForbidden {
+		t.Fatal("token shouldn't grant access to the token management", code)
+	}
+	_, all, err := api.staticTokens.Create("all", []string{"all"}, time.Time{}, types.ZeroCurrency, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := do(all, http.MethodPost, "/daemon/alerts/hooks/add", nil); code != http.StatusForbidden {
+		t.Fatal("token shouldn't grant access to the alert hooks", code)
+	}
+	if code := send(secret, 60, "addr"); code != http.StatusNoContent {
+		t.Fatal("send should succeed", code)
+	}
+	// Failed sends don't count towards the limit.
+	if code := send(secret, 40, "fail"); code != http.StatusInternalServerError {
+		t.Fatal("send should fail", code)
+	}
+	if code := send(secret, 41, "addr"); code != http.StatusForbidden {
+		t.Fatal("send should exceed the limit", code)
+	}
+	if code := send(secret, 40, "addr"); code != http.StatusNoContent {
+		t.Fatal("send should succeed", code)
+	}
+	tokens := api.staticTokens.Tokens()
+	if len(tokens) != 2 || tokens[1].Name != "send" || !tokens[1].Spent.Equals64(100) {
+		t.Fatal("unexpected spending", tokens)
+	}
+
+	// Tokens with a spending limit can't spend through routes that can't be
+	// metered, even if their scopes grant access to them.
+	_, wallet, err := api.staticTokens.Create("wallet", []string{"wallet:write"}, time.Time{}, types.NewCurrency64(100), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for route := range spendRoutes {
+		if route == "/wallet/siacoins" {
+			continue
+		}
+		if code := do(wallet, http.MethodPost, route, nil); code != http.StatusForbidden {
+			t.Fatal("limited token shouldn't be able to spend using", route, code)
+		}
+		if code := do(all, http.MethodPost, route, nil); code != http.StatusNoContent {
+			t.Fatal("unlimited token should be able to spend using", route, code)
+		}
+	}
+	if code := send(wallet, 100, "addr"); code != http.StatusNoContent {
+		t.Fatal("send should succeed", code)
+	}
+	if code := send(wallet, 1, "addr"); code != http.StatusForbidden {
+		t.Fatal("send should exceed the limit", code)
+	}
+
+	// Read tokens can't access privileged routes.
+	for _, route := range privilegedRoutes {
+		group := routeGroup(route)
+		_, read, err := api.staticTokens.Create(group+"-read-"+route, []string{group + ":read"}, time.Time{}, types.ZeroCurrency, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		path := route
+		if strings.HasSuffix(path, "/") {
+			path += "foo"
+		}
+		if code := do(read, http.MethodGet, path, nil); code != http.StatusForbidden {
+			t.Fatal("read token shouldn't grant access to", path, code)
+		}
+		if code := do(all, http.MethodGet, path, nil); code != http.StatusNoContent {
+			t.Fatal("token should grant access to", path, code)
+		}
+	}
+
+	// Expired tokens are rejected.
+	_, expired, err := api.staticTokens.Create("expired", []string{"all"}, time.Now().Add(-time.Second), types.ZeroCurrency, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := do(expired, http.MethodGet, "/renter", nil); code != http.StatusForbidden {
+		t.Fatal("expired token should be rejected", code)
+	}
+
+	// Unknown tokens are rejected.
+	if code := do(tokenPrefix+"unknown", http.MethodGet, "/renter", nil); code != http.StatusUnauthorized {
+		t.Fatal("unknown token should be rejected", code)
+	}
+}
@@ -0,0 +1,71 @@
+package api
+
+// tracing.go contains the API for the traces of renter operations. A trace
+// shows where the time of a download or upload went by recording the chunks
+// and worker jobs it fanned out into. The finished spans can also be exported
+// to a file or an OpenTelemetry collector.
+
+import (
+	"net/http"
+
+	"github.com/julienschmidt/httprouter"
+
+	"go.sia.tech/siad/modules"
+)
+
+// RenterTracesGET contains the recent traces of the renter without their
+// spans.
+type RenterTracesGET struct {
+	Traces []modules.RenterTrace `json:"traces"`
+
+	// ExportDestination is the file or OTLP collector URL the finished spans
+	// are exported to. It's empty if the export is disabled.
+	ExportDestination string `json:"exportdestination"`
+}
+
+// renterTracesHandlerGET handles the API call that returns the recent traces.
+func (api *API) renterTracesHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterTracesGET{
+		Traces:            api.renter.Traces(),
+		ExportDestination: api.renter.TraceExportDestination(),
+	})
+}
+
+// renterTracesHandlerPOST handles the API call that sets the export
+// destination of the traces.
+func (api *API) renterTracesHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := req.ParseForm(); err != nil {
+		WriteError(w, Error{"unable to parse form: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if _, ok := req.Form["destination"]; !ok {
+		WriteError(w, Error{"destination needs to be specified, use an empty destination to disable the export"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.SetTraceExportDestination(req.FormValue("destination")); err != nil {
+		WriteError(w, Error{"unable to set export destination: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterTraceHandlerGET handles the API call that returns a single trace
+// including its spans. With format=otlp the trace is returned in the OTLP JSON
+// encoding.
+func (api *API) renterTraceHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	format := req.FormValue("format")
+	if format != "" && format != "json" && format != "otlp" {
+		WriteError(w, Error{"unknown format, use 'json' or 'otlp'"}, http.StatusBadRequest)
+		return
+	}
+	trace, err := api.renter.Trace(ps.ByName("id"))
+	if err != nil {
+		WriteError(w, Error{"unable to get trace: " + err.Error()}, http.StatusNotFound)
+		return
+	}
+	if format == "otlp" {
+		WriteJSON(w, modules.OTLP([]modules.RenterTrace{trace}))
+		return
+	}
+	WriteJSON(w, trace)
+}
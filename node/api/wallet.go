@@ -59,6 +59,20 @@ type (
 	WalletSiacoinsPOST struct {
 		Transactions   []types.Transaction   `json:"transactions"`
 		TransactionIDs []types.TransactionID `json:"transactionids"`
+
+		// PendingSpendID is set instead of the transactions if the spend
+		// needs to be approved first.
+		PendingSpendID string `json:"pendingspendid,omitempty"`
+	}
+
+	// WalletPolicyGET contains the spending policy of the wallet.
+	WalletPolicyGET struct {
+		modules.SpendingPolicy
+	}
+
+	// WalletApprovalsGET contains the spends waiting to be approved.
+	WalletApprovalsGET struct {
+		PendingSpends []modules.PendingSpend `json:"pendingspends"`
 	}
 
 	// WalletSiafundsPOST contains the transaction sent in the POST call to
@@ -246,6 +260,21 @@ func RegisterRoutesWallet(router *httprouter.Router, wallet modules.Wallet, requ
 	router.POST("/wallet/watch", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 		walletWatchHandlerPOST(wallet, w, req, ps)
 	}, requiredPassword))
+	router.GET("/wallet/policy", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletPolicyHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/policy", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletPolicyHandlerPOST(wallet, w, req, ps)
+	}, requiredPassword))
+	router.GET("/wallet/approvals", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletApprovalsHandlerGET(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/approvals/approve", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletApprovalsApproveHandler(wallet, w, req, ps)
+	}, requiredPassword))
+	router.POST("/wallet/approvals/reject", RequirePassword(func(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+		walletApprovalsRejectHandler(wallet, w, req, ps)
+	}, requiredPassword))
 }
 
 // encryptionKeys enumerates the possible encryption keys that can be derived
@@ -597,7 +626,7 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 		}
 		txns, err = wallet.SendSiacoinsMulti(outputs)
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			writeSiacoinsError(w, err)
 			return
 		}
 	} else {
@@ -624,7 +653,7 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 			txns, err = wallet.SendSiacoins(amount, dest)
 		}
 		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			writeSiacoinsError(w, err)
 			return
 		}
 	}
@@ -639,6 +668,22 @@ func walletSiacoinsHandler(wallet modules.Wallet, w http.ResponseWriter, req *ht
 	})
 }
 
+// writeSiacoinsError writes the error of a call to /wallet/siacoins. Spends
+// that need to be approved are accepted with the ID of the pending spend.
+func writeSiacoinsError(w http.ResponseWriter, err error) {
+	if pse, ok := err.(modules.PendingSpendError); ok {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+		w.WriteHeader(http.StatusAccepted)
+		WriteJSON(w, WalletSiacoinsPOST{PendingSpendID: pse.ID})
+		return
+	}
+	code := http.StatusInternalServerError
+	if errors.Contains(err, modules.ErrDestinationNotAllowed) || errors.Contains(err, modules.ErrDailyLimitExceeded) {
+		code = http.StatusForbidden
+	}
+	WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, code)
+}
+
 // walletSiafundsHandler handles API calls to /wallet/siafunds.
 func walletSiafundsHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	amount, ok := scanAmount(req.FormValue("amount"))
@@ -958,3 +1003,139 @@ func walletWatchHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *h
 	}
 	WriteSuccess(w)
 }
+
+// walletPolicyHandlerGET handles GET requests to /wallet/policy.
+func walletPolicyHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	policy, err := wallet.SpendingPolicy()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/policy: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, WalletPolicyGET{policy})
+}
+
+// walletPolicyHandlerPOST handles POST requests to /wallet/policy. Only the
+// fields that are provided are changed.
+func walletPolicyHandlerPOST(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := req.ParseForm(); err != nil {
+		WriteError(w, Error{"unable to parse form: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	policy, err := wallet.SpendingPolicy()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/policy: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	amounts := []struct {
+		param string
+		field *types.Currency
+	}{
+		{"dailylimit", &policy.DailyLimit},
+		{"approvalthreshold", &policy.ApprovalThreshold},
+		{"maxallowancefunds", &policy.MaxAllowanceFunds},
+		{"maxallowanceincrease", &policy.MaxAllowanceIncrease},
+	}
+	for _, a := range amounts {
+		if _, ok := req.Form[a.param]; !ok {
+			continue
+		}
+		amount, ok := scanAmount(req.FormValue(a.param))
+		if !ok {
+			WriteError(w, Error{"could not read '" + a.param + "' from POST call to /wallet/policy"}, http.StatusBadRequest)
+			return
+		}
+		*a.field = amount
+	}
+	if _, ok := req.Form["alloweddestinations"]; ok {
+		policy.AllowedDestinations = nil
+		for _, addrStr := range strings.Split(req.FormValue("alloweddestinations"), ",") {
+			if addrStr = strings.TrimSpace(addrStr); addrStr == "" {
+				continue
+			}
+			addr, err := scanAddress(addrStr)
+			if err != nil {
+				WriteError(w, Error{"could not read 'alloweddestinations' from POST call to /wallet/policy: " + err.Error()}, http.StatusBadRequest)
+				return
+			}
+			policy.AllowedDestinations = append(policy.AllowedDestinations, addr)
+		}
+	}
+
+	keys, _ := encryptionKeys(req.FormValue("encryptionpassword"))
+	err = modules.ErrBadEncryptionKey
+	for _, key := range keys {
+		if err = wallet.SetSpendingPolicy(key, policy); !errors.Contains(err, modules.ErrBadEncryptionKey) {
+			break
+		}
+	}
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/policy: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletApprovalsHandlerGET handles GET requests to /wallet/approvals.
+func walletApprovalsHandlerGET(wallet modules.Wallet, w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	pending, err := wallet.PendingSpends()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/approvals: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteJSON(w, WalletApprovalsGET{PendingSpends: pending})
+}
+
+// walletApprovalsApproveHandler handles POST requests to
+// /wallet/approvals/approve.
+func walletApprovalsApproveHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id := req.FormValue("id")
+	if id == "" {
+		WriteError(w, Error{"id needs to be specified"}, http.StatusBadRequest)
+		return
+	}
+	keys, _ := encryptionKeys(req.FormValue("encryptionpassword"))
+	var txns []types.Transaction
+	err := modules.ErrBadEncryptionKey
+	for _, key := range keys {
+		if txns, err = wallet.ApproveSpend(key, id); !errors.Contains(err, modules.ErrBadEncryptionKey) {
+			break
+		}
+	}
+	if errors.Contains(err, modules.ErrPendingSpendNotFound) {
+		WriteError(w, Error{"error when calling /wallet/approvals/approve: " + err.Error()}, http.StatusNotFound)
+		return
+	} else if errors.Contains(err, modules.ErrBadEncryptionKey) {
+		WriteError(w, Error{"error when calling /wallet/approvals/approve: " + err.Error()}, http.StatusBadRequest)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"error when calling /wallet/approvals/approve: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	var txids []types.TransactionID
+	for _, txn := range txns {
+		txids = append(txids, txn.ID())
+	}
+	WriteJSON(w, WalletSiacoinsPOST{
+		Transactions:   txns,
+		TransactionIDs: txids,
+	})
+}
+
+// walletApprovalsRejectHandler handles POST requests to
+// /wallet/approvals/reject.
+func walletApprovalsRejectHandler(wallet modules.Wallet, w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id := req.FormValue("id")
+	if id == "" {
+		WriteError(w, Error{"id needs to be specified"}, http.StatusBadRequest)
+		return
+	}
+	err := wallet.RejectSpend(id)
+	if errors.Contains(err, modules.ErrPendingSpendNotFound) {
+		WriteError(w, Error{"error when calling /wallet/approvals/reject: " + err.Error()}, http.StatusNotFound)
+		return
+	} else if err != nil {
+		WriteError(w, Error{"error when calling /wallet/approvals/reject: " + err.Error()}, http.StatusInternalServerError)
+		return
+	}
+	WriteSuccess(w)
+}
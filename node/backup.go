@@ -0,0 +1,486 @@
+package node
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/twofish"
+
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/contractmanager"
+)
+
+const (
+	// backupVersion is the version of the node backup format.
+	backupVersion = "1.0"
+
+	// backupEncryption is the encryption of node backups.
+	backupEncryption = "twofish-ctr"
+
+	// backupNodeDir is the directory of the archive that contains the files of
+	// the node's directory.
+	backupNodeDir = "node"
+
+	// backupStorageFoldersDir is the directory of the archive that contains
+	// the metadata of the host's storage folders.
+	backupStorageFoldersDir = "storagefolders"
+
+	// backupLockTimeout is the time to wait for the lock of a database before
+	// considering it in use by a running node.
+	backupLockTimeout = time.Second
+
+	// backupMACSize is the size of the MAC at the beginning of a backup.
+	backupMACSize = sha256.Size
+
+	// nodeLockFile is the file within the node's directory that is locked
+	// while the node is running or a backup of it is created.
+	nodeLockFile = "siad.lock"
+)
+
+var (
+	// ErrNodeRunning is returned when trying to back up or restore a node that
+	// is still running.
+	ErrNodeRunning = errors.New("the node appears to be running, stop siad before creating or restoring a backup")
+
+	// errBackupMAC is returned when the MAC of a backup doesn't match its
+	// contents, which usually means that the password is wrong.
+	errBackupMAC = errors.New("MAC doesn't match, the password is wrong or the backup was modified")
+
+	// errNodeDirLocked is returned when the directory of a node is locked by
+	// another process.
+	errNodeDirLocked = errors.New("the node's directory is locked by another process")
+)
+
+// BackupOptions are the options for creating a node backup.
+type BackupOptions struct {
+	// IncludeConsensus includes the consensus set and the explorer in the
+	// backup. They are excluded by default since they can be rebuilt from the
+	// network and make up most of the node's directory.
+	IncludeConsensus bool
+}
+
+// nodeBackupHeader is the JSON header of a node backup.
+type nodeBackupHeader struct {
+	Version    string `json:"version"`
+	Encryption string `json:"encryption"`
+	IV         []byte `json:"iv"`
+	Salt       []byte `json:"salt"`
+}
+
+// backupKeys derives the encryption key and the MAC key of a backup from the
+// password.
+func backupKeys(password string, salt []byte) (encKey, macKey []byte) {
+	key := argon2.IDKey([]byte(password), salt, 1, 64*1024, 4, 64)
+	return key[:32], key[32:]
+}
+
+// backupCrypto creates the stream cipher and the MAC of a backup from its
+// header and the password.
+func backupCrypto(bh nodeBackupHeader, password string) (cipher.Stream, hash.Hash, error) {
+	if bh.Version != backupVersion {
+		return nil, nil, fmt.Errorf("unknown backup version '%v'", bh.Version)
+	}
+	if bh.Encryption != backupEncryption {
+		return nil, nil, fmt.Errorf("unknown backup encryption '%v'", bh.Encryption)
+	}
+	encKey, macKey := backupKeys(password, bh.Salt)
+	c, err := twofish.NewCipher(encKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cipher.NewCTR(c, bh.IV), hmac.New(sha256.New, macKey), nil
+}
+
+// lockNodeDir acquires the lock of the node's directory, which is held while
+// the node is running or a backup of it is created. The lock is a bolt
+// database since bolt implements file locks on all platforms.
+func lockNodeDir(dir string) (*bolt.DB, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	lock, err := bolt.Open(filepath.Join(dir, nodeLockFile), 0600, &bolt.Options{Timeout: backupLockTimeout})
+	if errors.Contains(err, bolt.ErrTimeout) {
+		return nil, errNodeDirLocked
+	}
+	return lock, err
+}
+
+// checkNodeStopped checks that none of the databases in dir are locked by a
+// running node. This detects nodes that were started by a version of siad
+// that didn't lock the node's directory yet.
+func checkNodeStopped(dir string) error {
+	return filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil || info.IsDir() || filepath.Ext(path) != ".db" || filepath.Base(path) == nodeLockFile {
+			return err
+		}
+		db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: backupLockTimeout, ReadOnly: true})
+		if errors.Contains(err, bolt.ErrTimeout) {
+			return ErrNodeRunning
+		}
+		if err != nil {
+			// Not every .db file is a bolt database.
+			return nil
+		}
+		return db.Close()
+	})
+}
+
+// excludeFromBackup returns whether the file at the provided path relative to
+// the node's directory is excluded from a backup.
+func excludeFromBackup(relPath string, opts BackupOptions) bool {
+	if !opts.IncludeConsensus {
+		top := strings.Split(filepath.ToSlash(relPath), "/")[0]
+		if top == modules.ConsensusDir || top == modules.ExplorerDir {
+			return true
+		}
+	}
+	if relPath == nodeLockFile {
+		return true
+	}
+	return filepath.Ext(relPath) == ".log" || strings.HasSuffix(relPath, "_temp")
+}
+
+// CreateBackup creates an encrypted backup of the stopped node in dir and
+// writes it to dst. The backup contains the state of all modules and the
+// metadata of the host's storage folders, but not the sectors stored in them.
+// The node's directory stays locked while the backup is created, so the node
+// can't be started in the meantime.
+//
+// Backups of a running node aren't supported. The modules don't provide a way
+// to pause their persistence, so the files of a running node could be copied
+// in an inconsistent state.
+func CreateBackup(dir, dst, password string, opts BackupOptions) (err error) {
+	if password == "" {
+		return errors.New("a password is required")
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	absDst, err := filepath.Abs(dst)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(absDst, dir+string(filepath.Separator)) {
+		return errors.New("the backup can't be written to the node's directory")
+	}
+	if _, err := os.Stat(dir); err != nil {
+		return errors.AddContext(err, "failed to access node directory")
+	}
+	lock, err := lockNodeDir(dir)
+	if errors.Contains(err, errNodeDirLocked) {
+		return ErrNodeRunning
+	} else if err != nil {
+		return errors.AddContext(err, "failed to lock node directory")
+	}
+	defer func() {
+		err = errors.Compose(err, lock.Close())
+	}()
+	if err := checkNodeStopped(dir); err != nil {
+		return err
+	}
+	var folders []string
+	cmDir := filepath.Join(dir, modules.HostDir, "contractmanager")
+	if _, err := os.Stat(cmDir); err == nil {
+		folders, err = contractmanager.StorageFolderPaths(cmDir)
+		if err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(absDst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+		if err != nil {
+			err = errors.Compose(err, os.Remove(absDst))
+		}
+	}()
+
+	// Skip the MAC and write the header. The MAC covers the header and the
+	// encrypted contents.
+	bh := nodeBackupHeader{
+		Version:    backupVersion,
+		Encryption: backupEncryption,
+		IV:         fastrand.Bytes(twofish.BlockSize),
+		Salt:       fastrand.Bytes(16),
+	}
+	stream, mac, err := backupCrypto(bh, password)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Seek(backupMACSize, io.SeekStart); err != nil {
+		return err
+	}
+	w := io.MultiWriter(f, mac)
+	if err := json.NewEncoder(w).Encode(bh); err != nil {
+		return err
+	}
+	gzw := gzip.NewWriter(cipher.StreamWriter{S: stream, W: w})
+	tw := tar.NewWriter(gzw)
+	err = filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		relPath, err := filepath.Rel(dir, path)
+		if err != nil || relPath == "." {
+			return err
+		}
+		if excludeFromBackup(relPath, opts) {
+			if info.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !info.IsDir() && !info.Mode().IsRegular() {
+			return nil
+		}
+		return tarFile(tw, path, filepath.ToSlash(filepath.Join(backupNodeDir, relPath)), info)
+	})
+	if err != nil {
+		return errors.Compose(err, tw.Close(), gzw.Close())
+	}
+	// Add the metadata of the storage folders. Folders that are unavailable
+	// are skipped, they can't be used after restoring the backup either.
+	for i, folder := range folders {
+		path := filepath.Join(folder, contractmanager.MetadataFile)
+		info, err := os.Stat(path)
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return errors.Compose(err, tw.Close(), gzw.Close())
+		}
+		name := filepath.ToSlash(filepath.Join(backupStorageFoldersDir, strconv.Itoa(i), contractmanager.MetadataFile))
+		if err := tarFile(tw, path, name, info); err != nil {
+			return errors.Compose(err, tw.Close(), gzw.Close())
+		}
+	}
+	if err := errors.Compose(tw.Close(), gzw.Close()); err != nil {
+		return err
+	}
+	// Write the MAC to the beginning of the file.
+	_, err = f.WriteAt(mac.Sum(nil), 0)
+	return err
+}
+
+// tarFile adds the file or directory at path to the archive.
+func tarFile(tw *tar.Writer, path, name string, info os.FileInfo) (err error) {
+	header, err := tar.FileInfoHeader(info, "")
+	if err != nil {
+		return err
+	}
+	header.Name = name
+	if err := tw.WriteHeader(header); err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, file.Close())
+	}()
+	_, err = io.CopyN(tw, file, header.Size)
+	return err
+}
+
+// RestoreBackup restores the backup at src into dir, which must be empty or
+// not exist. relocations maps the paths of the host's storage folders at the
+// time of the backup to their new paths. The metadata of a storage folder is
+// restored if the folder doesn't contain it yet.
+func RestoreBackup(src, dir, password string, relocations map[string]string) (err error) {
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("'%v' is not empty", dir)
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+	// Read the MAC and the header.
+	expectedMAC := make([]byte, backupMACSize)
+	if _, err := io.ReadFull(f, expectedMAC); err != nil {
+		return errors.AddContext(err, "failed to read MAC")
+	}
+	dec := json.NewDecoder(f)
+	var bh nodeBackupHeader
+	if err := dec.Decode(&bh); err != nil {
+		return errors.AddContext(err, "failed to read header")
+	}
+	// The encoder terminates the header with a newline.
+	bodyOff := int64(backupMACSize) + dec.InputOffset() + 1
+	stream, mac, err := backupCrypto(bh, password)
+	if err != nil {
+		return err
+	}
+	// Verify the MAC of the header and the encrypted contents before
+	// decrypting anything.
+	if _, err := f.Seek(backupMACSize, io.SeekStart); err != nil {
+		return err
+	}
+	if _, err := io.Copy(mac, f); err != nil {
+		return err
+	}
+	if !hmac.Equal(mac.Sum(nil), expectedMAC) {
+		return errBackupMAC
+	}
+	if _, err := f.Seek(bodyOff, io.SeekStart); err != nil {
+		return err
+	}
+	gzr, err := gzip.NewReader(cipher.StreamReader{S: stream, R: f})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, gzr.Close())
+	}()
+
+	// Extract the node's directory into a temporary directory first to not
+	// leave a partially restored node behind.
+	tmpDir := filepath.Join(filepath.Dir(dir), "."+filepath.Base(dir)+"_restore")
+	if err := os.RemoveAll(tmpDir); err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, os.RemoveAll(tmpDir))
+	}()
+	metadata := make(map[int]string)
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return err
+		}
+		name := filepath.FromSlash(header.Name)
+		if filepath.IsAbs(name) || strings.HasPrefix(filepath.Clean(name), "..") {
+			return fmt.Errorf("invalid path '%v' in backup", header.Name)
+		}
+		if err := untarFile(tr, header, filepath.Join(tmpDir, name)); err != nil {
+			return err
+		}
+		parts := strings.Split(header.Name, "/")
+		if parts[0] == backupStorageFoldersDir && len(parts) == 3 {
+			i, err := strconv.Atoi(parts[1])
+			if err != nil {
+				return fmt.Errorf("invalid path '%v' in backup", header.Name)
+			}
+			metadata[i] = filepath.Join(tmpDir, name)
+		}
+	}
+
+	// Relocate the storage folders.
+	nodeDir := filepath.Join(tmpDir, backupNodeDir)
+	cmDir := filepath.Join(nodeDir, modules.HostDir, "contractmanager")
+	var folders []string
+	if _, err := os.Stat(cmDir); err == nil {
+		if len(relocations) > 0 {
+			if err := contractmanager.RelocateStorageFolders(cmDir, relocations); err != nil {
+				return err
+			}
+		}
+		folders, err = contractmanager.StorageFolderPaths(cmDir)
+		if err != nil {
+			return err
+		}
+	} else if len(relocations) > 0 {
+		return errors.New("the backup doesn't contain any storage folders")
+	}
+	for i, folder := range folders {
+		src, exists := metadata[i]
+		if !exists {
+			continue
+		}
+		dst := filepath.Join(folder, contractmanager.MetadataFile)
+		if _, err := os.Stat(dst); !os.IsNotExist(err) {
+			continue
+		}
+		if err := copyFile(src, dst); err != nil {
+			return errors.AddContext(err, "failed to restore storage folder metadata")
+		}
+	}
+
+	// Move the node's directory into place.
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	return os.Rename(nodeDir, dir)
+}
+
+// copyFile copies the file at src to dst, creating the directory of dst if
+// necessary. The storage folders might be on a different filesystem, so the
+// file can't be renamed.
+func copyFile(src, dst string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, in.Close())
+	}()
+	if err := os.MkdirAll(filepath.Dir(dst), 0700); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, out.Sync(), out.Close())
+	}()
+	_, err = io.Copy(out, in)
+	return err
+}
+
+// untarFile extracts a file or directory of the archive to path.
+func untarFile(tr *tar.Reader, header *tar.Header, path string) (err error) {
+	info := header.FileInfo()
+	if info.IsDir() {
+		return os.MkdirAll(path, 0700)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, file.Sync(), file.Close())
+	}()
+	_, err = io.Copy(file, tr)
+	return err
+}
@@ -0,0 +1,245 @@
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"go.sia.tech/siad/build"
+	"go.sia.tech/siad/modules"
+	"go.sia.tech/siad/modules/host/contractmanager"
+)
+
+// TestBackupRestore tests creating a node backup and restoring it with a
+// relocated storage folder.
+func TestBackupRestore(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	testDir := build.TempDir("node", t.Name())
+	dir := filepath.Join(testDir, "node")
+
+	// Create a contract manager with a storage folder and some module files.
+	folder := filepath.Join(testDir, "folder")
+	if err := os.MkdirAll(folder, 0700); err != nil {
+		t.Fatal(err)
+	}
+	cm, err := contractmanager.New(filepath.Join(dir, modules.HostDir, "contractmanager"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.AddStorageFolder(folder, contractmanager.MinimumSectorsPerStorageFolder*modules.SectorSize); err != nil {
+		t.Fatal(err)
+	}
+	if err := cm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string][]byte{
+		filepath.Join(modules.WalletDir, "wallet.db"):       []byte("wallet"),
+		filepath.Join(modules.RenterDir, "renter.json"):     []byte("renter"),
+		filepath.Join(modules.ConsensusDir, "consensus.db"): []byte("consensus"),
+		filepath.Join(modules.HostDir, "host.log"):          []byte("log"),
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// A backup can't be created while the node's directory is locked by a
+	// running node.
+	archive := filepath.Join(testDir, "node.backup")
+	lock, err := lockNodeDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateBackup(dir, archive, "password", BackupOptions{}); !errors.Contains(err, ErrNodeRunning) {
+		t.Fatal("expected ErrNodeRunning, got", err)
+	}
+	if err := lock.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A backup can't be created while a database is in use either.
+	db, err := bolt.Open(filepath.Join(dir, modules.HostDir, "host.db"), 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateBackup(dir, archive, "password", BackupOptions{}); !errors.Contains(err, ErrNodeRunning) {
+		t.Fatal("expected ErrNodeRunning, got", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Fatal("failed backup wasn't removed", err)
+	}
+	if err := CreateBackup(dir, filepath.Join(dir, "node.backup"), "password", BackupOptions{}); err == nil {
+		t.Fatal("backup shouldn't be written to the node's directory")
+	}
+	if err := CreateBackup(dir, archive, "password", BackupOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restoring fails with the wrong password or into a non-empty directory.
+	restored := filepath.Join(testDir, "restored")
+	if err := RestoreBackup(archive, restored, "wrong", nil); !errors.Contains(err, errBackupMAC) {
+		t.Fatal("expected errBackupMAC, got", err)
+	}
+	if err := RestoreBackup(archive, dir, "password", nil); err == nil {
+		t.Fatal("restoring into a non-empty directory should fail")
+	}
+	// Relocating an unknown folder fails.
+	if err := RestoreBackup(archive, restored, "password", map[string]string{"unknown": folder}); err == nil {
+		t.Fatal("relocating an unknown folder should fail")
+	}
+
+	// Restore the backup with a relocated storage folder.
+	newFolder := filepath.Join(testDir, "newfolder")
+	if err := RestoreBackup(archive, restored, "password", map[string]string{folder: newFolder}); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		restoredData, err := os.ReadFile(filepath.Join(restored, name))
+		excluded := filepath.Dir(name) == modules.ConsensusDir || filepath.Ext(name) == ".log"
+		if excluded && !os.IsNotExist(err) {
+			t.Fatal("file should be excluded", name, err)
+		} else if !excluded && (err != nil || !bytes.Equal(restoredData, data)) {
+			t.Fatal("file wasn't restored", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(restored, nodeLockFile)); !os.IsNotExist(err) {
+		t.Fatal("lock of the node's directory shouldn't be restored", err)
+	}
+	if _, err := os.Stat(filepath.Join(testDir, ".restored_restore")); !os.IsNotExist(err) {
+		t.Fatal("temporary directory wasn't removed", err)
+	}
+	paths, err := contractmanager.StorageFolderPaths(filepath.Join(restored, modules.HostDir, "contractmanager"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(paths) != 1 || paths[0] != newFolder {
+		t.Fatal("storage folder wasn't relocated", paths)
+	}
+	oldMetadata, err := os.ReadFile(filepath.Join(folder, contractmanager.MetadataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	newMetadata, err := os.ReadFile(filepath.Join(newFolder, contractmanager.MetadataFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(oldMetadata, newMetadata) {
+		t.Fatal("storage folder metadata wasn't restored")
+	}
+
+	// Move the sectors to the new folder and load the restored contract
+	// manager.
+	if err := os.Rename(filepath.Join(folder, "siahostdata.dat"), filepath.Join(newFolder, "siahostdata.dat")); err != nil {
+		t.Fatal(err)
+	}
+	cm, err = contractmanager.New(filepath.Join(restored, modules.HostDir, "contractmanager"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := cm.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	sfs := cm.StorageFolders()
+	if len(sfs) != 1 || sfs[0].Path != newFolder || sfs[0].FailedReads+sfs[0].FailedWrites != 0 {
+		t.Fatal("unexpected storage folders", sfs)
+	}
+}
+
+// TestBackupWithConsensus tests including the consensus set in a backup.
+func TestBackupWithConsensus(t *testing.T) {
+	t.Parallel()
+	testDir := build.TempDir("node", t.Name())
+	dir := filepath.Join(testDir, "node")
+	path := filepath.Join(dir, modules.ConsensusDir, "consensus.db")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("consensus"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(testDir, "node.backup")
+	if err := CreateBackup(dir, archive, "", BackupOptions{}); err == nil {
+		t.Fatal("a password should be required")
+	}
+	if err := CreateBackup(dir, archive, "password", BackupOptions{IncludeConsensus: true}); err != nil {
+		t.Fatal(err)
+	}
+	restored := filepath.Join(testDir, "restored")
+	if err := RestoreBackup(archive, restored, "password", nil); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(restored, modules.ConsensusDir, "consensus.db"))
+	if err != nil || string(data) != "consensus" {
+		t.Fatal("consensus wasn't restored", err)
+	}
+}
+
+// TestBackupModified tests that modified backups are rejected.
+func TestBackupModified(t *testing.T) {
+	t.Parallel()
+	testDir := build.TempDir("node", t.Name())
+	dir := filepath.Join(testDir, "node")
+	path := filepath.Join(dir, modules.RenterDir, "renter.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("renter"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	archive := filepath.Join(testDir, "node.backup")
+	if err := CreateBackup(dir, archive, "password", BackupOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(archive)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Modifying the encrypted contents or the header invalidates the MAC.
+	headerEnd := backupMACSize + bytes.IndexByte(data[backupMACSize:], '\n') + 1
+	var bh nodeBackupHeader
+	if err := json.Unmarshal(data[backupMACSize:headerEnd], &bh); err != nil {
+		t.Fatal(err)
+	}
+	bh.IV[0] ^= 1
+	header, err := json.Marshal(bh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	modifiedHeader := append(append(append([]byte(nil), data[:backupMACSize]...), header...), '\n')
+	modifiedHeader = append(modifiedHeader, data[headerEnd:]...)
+	modifiedContents := append([]byte(nil), data...)
+	modifiedContents[len(modifiedContents)-1] ^= 1
+	for i, modified := range [][]byte{modifiedContents, modifiedHeader} {
+		modifiedArchive := filepath.Join(testDir, fmt.Sprintf("modified%d.backup", i))
+		if err := os.WriteFile(modifiedArchive, modified, 0600); err != nil {
+			t.Fatal(err)
+		}
+		restored := filepath.Join(testDir, fmt.Sprintf("restored%d", i))
+		if err := RestoreBackup(modifiedArchive, restored, "password", nil); !errors.Contains(err, errBackupMAC) {
+			t.Fatal("expected errBackupMAC, got", err)
+		}
+		if _, err := os.Stat(restored); !os.IsNotExist(err) {
+			t.Fatal("modified backup was restored", err)
+		}
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"path/filepath"
 	"time"
 
+	"gitlab.com/NebulousLabs/bolt"
 	mnemonics "gitlab.com/NebulousLabs/entropy-mnemonics"
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/ratelimit"
@@ -132,6 +133,10 @@ type Node struct {
 	Mux    *siamux.SiaMux
 	muxLog *os.File
 
+	// The lock of the node's directory, which prevents backups of the node
+	// while it is running.
+	lock *bolt.DB
+
 	// The modules of the node. Modules that are not initialized will be nil.
 	Accounting      modules.Accounting
 	ConsensusSet    modules.ConsensusSet
@@ -238,6 +243,9 @@ func (n *Node) Close() (err error) {
 		printlnRelease("Closing siamux...")
 		err = errors.Compose(err, n.Mux.Close(), n.muxLog.Close())
 	}
+	if n.lock != nil {
+		err = errors.Compose(err, n.lock.Close())
+	}
 	return err
 }
 
@@ -278,6 +286,20 @@ func New(params NodeParams, loadStartTime time.Time) (*Node, <-chan error) {
 		return nil, errChan
 	}
 
+	// Lock the node's directory. The lock is released if the node can't be
+	// created, the error is ignored since creating the node failed already.
+	lock, err := lockNodeDir(dir)
+	if err != nil {
+		errChan <- errors.AddContext(err, "unable to lock the node's directory")
+		return nil, errChan
+	}
+	created := false
+	defer func() {
+		if !created {
+			_ = lock.Close()
+		}
+	}()
+
 	// Create the siamux.
 	mux, muxLog, err := modules.NewSiaMux(filepath.Join(dir, modules.SiaMuxDir), dir, params.SiaMuxTCPAddress, params.SiaMuxWSAddress)
 	if err != nil {
@@ -606,9 +628,11 @@ func New(params NodeParams, loadStartTime time.Time) (*Node, <-chan error) {
 		close(errChan)
 	}()
 
+	created = true
 	return &Node{
 		Mux:    mux,
 		muxLog: muxLog,
+		lock:   lock,
 
 		Accounting:      acc,
 		ConsensusSet:    cs,
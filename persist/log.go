@@ -1,15 +1,23 @@
 package persist
 
 import (
+	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"strings"
 
+	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/log"
 	"go.sia.tech/siad/build"
 )
 
-// Logger is a wrapper for log.Logger.
+// Logger is a wrapper for log.Logger. Its output is structured according to
+// the global log settings, see SetLogFormat and SetLogLevel.
 type Logger struct {
 	*log.Logger
+
+	staticWriter *logWriter
 }
 
 var (
@@ -33,25 +41,85 @@ func printCommitHash(logger *log.Logger) {
 }
 
 // NewFileLogger returns a logger that logs to logFilename. The file is opened
-// in append mode, and created if it does not exist.
+// in append mode, and created if it does not exist. The name of the file
+// without the extension is used as the module of the log entries.
 func NewFileLogger(logFilename string) (*Logger, error) {
-	logger, err := log.NewFileLogger(logFilename, options)
+	file, err := os.OpenFile(logFilename, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0660)
 	if err != nil {
 		return nil, err
 	}
-	printCommitHash(logger)
-	return &Logger{logger}, nil
+	logger, err := newLogger(file, moduleFromFilename(logFilename))
+	if err != nil {
+		return nil, errors.Compose(err, file.Close())
+	}
+	return logger, nil
 }
 
 // NewLogger returns a logger that can be closed. Calls should not be made to
 // the logger after 'Close' has been called.
 func NewLogger(w io.Writer) (*Logger, error) {
-	logger, err := log.NewLogger(w, options)
+	var module string
+	if f, ok := w.(*os.File); ok {
+		module = moduleFromFilename(f.Name())
+	}
+	return newLogger(w, module)
+}
+
+// newLogger creates a logger for the provided module that writes to w.
+func newLogger(w io.Writer, module string) (*Logger, error) {
+	lw := newLogWriter(w, module)
+	logger, err := log.NewLogger(lw, options)
 	if err != nil {
 		return nil, err
 	}
 	printCommitHash(logger)
-	return &Logger{logger}, nil
+	return &Logger{Logger: logger, staticWriter: lw}, nil
+}
+
+// moduleFromFilename returns the name of the module that logs to the file.
+func moduleFromFilename(path string) string {
+	return strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+}
+
+// WithFields returns a logger that adds the provided fields to every entry.
+// The fields are passed as alternating keys and values, e.g.
+// WithFields("siapath", siaPath, "hostkey", hostKey). The returned logger
+// shares the output of l and must not be closed.
+func (l *Logger) WithFields(keysAndValues ...interface{}) *Logger {
+	lw := l.staticWriter.withFields(keysAndValues...)
+	// Mute the startup message of the derived logger.
+	lw.muted = true
+	logger, err := log.NewLogger(lw, options)
+	lw.muted = false
+	if err != nil {
+		build.Critical("failed to create logger with fields:", err)
+		return l
+	}
+	return &Logger{Logger: logger, staticWriter: lw}
+}
+
+// Debug is equivalent to Logger.Print if debug logging is enabled for the
+// logger's module. Otherwise it is a no-op.
+func (l *Logger) Debug(v ...interface{}) {
+	if l.staticWriter.enabled(LogLevelDebug) {
+		_ = l.Output(2, debugPrefix+fmt.Sprint(v...))
+	}
+}
+
+// Debugf is equivalent to Logger.Printf if debug logging is enabled for the
+// logger's module. Otherwise it is a no-op.
+func (l *Logger) Debugf(format string, v ...interface{}) {
+	if l.staticWriter.enabled(LogLevelDebug) {
+		_ = l.Output(2, debugPrefix+fmt.Sprintf(format, v...))
+	}
+}
+
+// Debugln is equivalent to Logger.Println if debug logging is enabled for the
+// logger's module. Otherwise it is a no-op.
+func (l *Logger) Debugln(v ...interface{}) {
+	if l.staticWriter.enabled(LogLevelDebug) {
+		_ = l.Output(2, debugPrefix+fmt.Sprintln(v...))
+	}
 }
 
 // buildReleaseType returns the release type for this build, defaulting to
@@ -0,0 +1,176 @@
+package persist
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.sia.tech/siad/build"
+)
+
+// readLogLines reads the lines of a log file.
+func readLogLines(t *testing.T, path string) []string {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var lines []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		lines = append(lines, s.Text())
+	}
+	if err := s.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return lines
+}
+
+// resetLogSettings restores the default log settings.
+func resetLogSettings() {
+	lc := newLogConfig()
+	logSettings.mu.Lock()
+	logSettings.format = lc.format
+	logSettings.stdout = lc.stdout
+	logSettings.defaultLevel = lc.defaultLevel
+	logSettings.levels = lc.levels
+	logSettings.mu.Unlock()
+}
+
+// TestLogLevels tests that log entries are filtered by the level of their
+// module and that the levels can be changed at runtime.
+func TestLogLevels(t *testing.T) {
+	defer resetLogSettings()
+	dir := build.TempDir(persistDir, t.Name())
+	if err := os.MkdirAll(dir, defaultDirPermissions); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "testmodule.log")
+	logger, err := NewFileLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Only warnings and above are logged.
+	if err := SetLogLevel("testmodule", LogLevelWarn); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debugln("debug message")
+	logger.Println("info message")
+	logger.Println("WARN: warn message")
+	logger.Println("ERROR: error message")
+
+	// Removing the level of the module enables debug messages if the
+	// default level allows them.
+	UnsetLogLevel("testmodule")
+	if err := SetLogLevel("", LogLevelDebug); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debugln("second debug message")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var msgs []string
+	for _, line := range readLogLines(t, path) {
+		if strings.Contains(line, "message") {
+			msgs = append(msgs, line)
+		}
+	}
+	expected := []string{"WARN: warn message", "ERROR: error message", "[DEBUG] second debug message"}
+	if len(msgs) != len(expected) {
+		t.Fatalf("unexpected messages: %v", msgs)
+	}
+	for i := range msgs {
+		if !strings.HasSuffix(msgs[i], expected[i]) {
+			t.Fatalf("unexpected message %v: %v", i, msgs[i])
+		}
+	}
+
+	ls := CurrentLogSettings()
+	found := false
+	for _, m := range ls.Modules {
+		found = found || m == "testmodule"
+	}
+	if !found {
+		t.Fatal("module wasn't registered", ls.Modules)
+	}
+	if ls.DefaultLevel != LogLevelDebug {
+		t.Fatal("unexpected default level", ls.DefaultLevel)
+	}
+}
+
+// TestLogJSON tests the JSON log format and loggers with fields.
+func TestLogJSON(t *testing.T) {
+	defer resetLogSettings()
+	dir := build.TempDir(persistDir, t.Name())
+	if err := os.MkdirAll(dir, defaultDirPermissions); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "jsonmodule.log")
+	logger, err := NewFileLogger(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := SetLogFormat("xml"); err != errUnknownLogFormat {
+		t.Fatal("expected errUnknownLogFormat, got", err)
+	}
+	if err := SetLogFormat(LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	logger.WithFields("siapath", "foo/bar", "hostkey", "ed25519:abcd").Println("WARN: upload failed")
+	if err := SetLogFormat(LogFormatText); err != nil {
+		t.Fatal(err)
+	}
+	logger.WithFields("contractid", "1234").Println("renewed contract")
+	if err := logger.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := readLogLines(t, path)
+	var entry map[string]interface{}
+	var text string
+	for _, line := range lines {
+		if strings.HasPrefix(line, "{") {
+			if err := json.Unmarshal([]byte(line), &entry); err != nil {
+				t.Fatal(err)
+			}
+		} else if strings.Contains(line, "renewed contract") {
+			text = line
+		}
+	}
+	if entry == nil {
+		t.Fatal("no JSON entry was logged", lines)
+	}
+	if entry["module"] != "jsonmodule" || entry["level"] != "warn" || entry["msg"] != "WARN: upload failed" ||
+		entry["siapath"] != "foo/bar" || entry["hostkey"] != "ed25519:abcd" || !strings.HasPrefix(entry["caller"].(string), "log_test.go:") {
+		t.Fatal("unexpected entry", entry)
+	}
+	if !strings.HasSuffix(text, "renewed contract contractid=1234") {
+		t.Fatal("unexpected text entry", text)
+	}
+}
+
+// TestParseLogLevel tests parsing and marshaling log levels.
+func TestParseLogLevel(t *testing.T) {
+	for l := LogLevelDebug; l <= LogLevelCritical; l++ {
+		parsed, err := ParseLogLevel(strings.ToUpper(l.String()))
+		if err != nil || parsed != l {
+			t.Fatal("failed to parse", l, err)
+		}
+		b, err := json.Marshal(l)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var unmarshaled LogLevel
+		if err := json.Unmarshal(b, &unmarshaled); err != nil || unmarshaled != l {
+			t.Fatal("failed to unmarshal", string(b), err)
+		}
+	}
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Fatal("unknown level was parsed")
+	}
+}
@@ -0,0 +1,465 @@
+package persist
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"go.sia.tech/siad/build"
+)
+
+// LogLevel is the severity of a log entry.
+type LogLevel int
+
+// The log levels in increasing order of severity. Entries below the level of
+// a module are discarded.
+const (
+	LogLevelDebug LogLevel = iota
+	LogLevelInfo
+	LogLevelWarn
+	LogLevelError
+	LogLevelCritical
+)
+
+const (
+	// LogFormatText is the default log format. Entries are written as lines
+	// of text with the fields appended as key=value pairs.
+	LogFormatText = "text"
+
+	// LogFormatJSON writes every entry as a JSON object on its own line.
+	LogFormatJSON = "json"
+
+	// debugPrefix is the prefix of debug messages.
+	debugPrefix = "[DEBUG] "
+
+	// logTimeLayout is the layout of the timestamps written by the standard
+	// library logger with the flags used by log.NewLogger.
+	logTimeLayout = "2006/01/02 15:04:05.000000"
+)
+
+var (
+	// levelPrefixes maps message prefixes to the level of the entry. Messages
+	// without one of the prefixes are logged at LogLevelInfo.
+	levelPrefixes = []struct {
+		prefix string
+		level  LogLevel
+	}{
+		{debugPrefix, LogLevelDebug},
+		{"CRITICAL:", LogLevelCritical},
+		{"SEVERE:", LogLevelError},
+		{"ERROR:", LogLevelError},
+		{"Error:", LogLevelError},
+		{"WARN:", LogLevelWarn},
+		{"WARNING:", LogLevelWarn},
+	}
+
+	// logSettings are the global log settings shared by all loggers of the
+	// process.
+	logSettings = newLogConfig()
+
+	// errUnknownLogFormat is returned when setting an unknown log format.
+	errUnknownLogFormat = errors.New("unknown log format, use 'text' or 'json'")
+)
+
+type (
+	// LogSettings are the log settings of the process.
+	LogSettings struct {
+		// Format is the format of the log entries, LogFormatText or
+		// LogFormatJSON.
+		Format string `json:"format"`
+
+		// Stdout indicates whether the entries of all modules are also
+		// written to stdout.
+		Stdout bool `json:"stdout"`
+
+		// DefaultLevel is the level of modules without a level.
+		DefaultLevel LogLevel `json:"defaultlevel"`
+
+		// Levels are the levels of individual modules.
+		Levels map[string]LogLevel `json:"levels"`
+
+		// Modules are the modules that have created a logger.
+		Modules []string `json:"modules"`
+	}
+
+	// logConfig contains the global log settings.
+	logConfig struct {
+		format       string
+		stdout       bool
+		defaultLevel LogLevel
+		levels       map[string]LogLevel
+		modules      map[string]struct{}
+
+		// stdoutMu serializes the writes to stdout.
+		stdoutMu sync.Mutex
+		mu       sync.RWMutex
+	}
+
+	// logWriter is the writer of a Logger. It parses the lines written by the
+	// underlying logger, filters them by level and writes them in the
+	// configured format.
+	logWriter struct {
+		staticW      io.Writer
+		staticCloser io.Closer
+		staticModule string
+		staticFields []logField
+
+		// muted discards writes. It is only set while creating a logger to
+		// discard its startup message.
+		muted bool
+
+		closed bool
+		mu     sync.Mutex
+	}
+
+	// logField is a field that is added to the entries of a logger.
+	logField struct {
+		key   string
+		value interface{}
+	}
+)
+
+// newLogConfig creates the default log config. Debug messages are logged by
+// default in debug builds.
+func newLogConfig() *logConfig {
+	lc := &logConfig{
+		format:       LogFormatText,
+		defaultLevel: LogLevelInfo,
+		levels:       make(map[string]LogLevel),
+		modules:      make(map[string]struct{}),
+	}
+	if build.DEBUG {
+		lc.defaultLevel = LogLevelDebug
+	}
+	return lc
+}
+
+// String implements fmt.Stringer.
+func (l LogLevel) String() string {
+	switch l {
+	case LogLevelDebug:
+		return "debug"
+	case LogLevelInfo:
+		return "info"
+	case LogLevelWarn:
+		return "warn"
+	case LogLevelError:
+		return "error"
+	case LogLevelCritical:
+		return "critical"
+	default:
+		return "unknown"
+	}
+}
+
+// ParseLogLevel parses a log level.
+func ParseLogLevel(s string) (LogLevel, error) {
+	for l := LogLevelDebug; l <= LogLevelCritical; l++ {
+		if strings.EqualFold(s, l.String()) {
+			return l, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown log level '%v', use debug, info, warn, error or critical", s)
+}
+
+// MarshalJSON implements json.Marshaler.
+func (l LogLevel) MarshalJSON() ([]byte, error) {
+	return json.Marshal(l.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (l *LogLevel) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	level, err := ParseLogLevel(s)
+	if err != nil {
+		return err
+	}
+	*l = level
+	return nil
+}
+
+// CurrentLogSettings returns the current log settings.
+func CurrentLogSettings() LogSettings {
+	lc := logSettings
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	ls := LogSettings{
+		Format:       lc.format,
+		Stdout:       lc.stdout,
+		DefaultLevel: lc.defaultLevel,
+		Levels:       make(map[string]LogLevel),
+	}
+	for module, level := range lc.levels {
+		ls.Levels[module] = level
+	}
+	for module := range lc.modules {
+		ls.Modules = append(ls.Modules, module)
+	}
+	sort.Strings(ls.Modules)
+	return ls
+}
+
+// SetLogFormat sets the format of all log entries.
+func SetLogFormat(format string) error {
+	if format != LogFormatText && format != LogFormatJSON {
+		return errUnknownLogFormat
+	}
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	logSettings.format = format
+	return nil
+}
+
+// SetLogLevel sets the level of a module. If module is empty, the default
+// level of all modules without a level is set.
+func SetLogLevel(module string, level LogLevel) error {
+	if level < LogLevelDebug || level > LogLevelCritical {
+		return fmt.Errorf("invalid log level %v", int(level))
+	}
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	if module == "" {
+		logSettings.defaultLevel = level
+	} else {
+		logSettings.levels[module] = level
+	}
+	return nil
+}
+
+// UnsetLogLevel removes the level of a module. The module uses the default
+// level afterwards.
+func UnsetLogLevel(module string) {
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	delete(logSettings.levels, module)
+}
+
+// SetLogStdout sets whether the entries of all modules are also written to
+// stdout.
+func SetLogStdout(stdout bool) {
+	logSettings.mu.Lock()
+	defer logSettings.mu.Unlock()
+	logSettings.stdout = stdout
+}
+
+// level returns the level of a module.
+func (lc *logConfig) level(module string) LogLevel {
+	lc.mu.RLock()
+	defer lc.mu.RUnlock()
+	if level, exists := lc.levels[module]; exists {
+		return level
+	}
+	return lc.defaultLevel
+}
+
+// newLogWriter creates a writer for the logger of a module.
+func newLogWriter(w io.Writer, module string) *logWriter {
+	if module != "" {
+		logSettings.mu.Lock()
+		logSettings.modules[module] = struct{}{}
+		logSettings.mu.Unlock()
+	}
+	lw := &logWriter{
+		staticW:      w,
+		staticModule: module,
+	}
+	if c, ok := w.(io.Closer); ok {
+		lw.staticCloser = c
+	}
+	return lw
+}
+
+// withFields returns a writer that shares the output of lw and adds the
+// provided fields to every entry. The returned writer can't be closed.
+func (lw *logWriter) withFields(keysAndValues ...interface{}) *logWriter {
+	fields := append([]logField{}, lw.staticFields...)
+	for i := 0; i < len(keysAndValues); i += 2 {
+		key := fmt.Sprint(keysAndValues[i])
+		var value interface{} = "MISSING"
+		if i+1 < len(keysAndValues) {
+			value = keysAndValues[i+1]
+		}
+		fields = append(fields, logField{key: key, value: value})
+	}
+	return &logWriter{
+		staticW:      lockedWriter{lw},
+		staticModule: lw.staticModule,
+		staticFields: fields,
+	}
+}
+
+// enabled returns whether entries of the provided level are logged.
+func (lw *logWriter) enabled(level LogLevel) bool {
+	return level >= logSettings.level(lw.staticModule)
+}
+
+// Close closes the underlying writer.
+func (lw *logWriter) Close() error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.closed {
+		build.Critical("cannot close the logger; already closed")
+		return nil
+	}
+	lw.closed = true
+	if lw.staticCloser == nil {
+		return nil
+	}
+	var err error
+	if f, ok := lw.staticW.(*os.File); ok {
+		err = f.Sync()
+	}
+	return errors.Compose(err, lw.staticCloser.Close())
+}
+
+// Write implements io.Writer. p is a single line written by the standard
+// library logger.
+func (lw *logWriter) Write(p []byte) (int, error) {
+	if lw.muted {
+		return len(p), nil
+	}
+	e := parseLogLine(p)
+	if e.level < LogLevelCritical && !lw.enabled(e.level) {
+		return len(p), nil
+	}
+	logSettings.mu.RLock()
+	format, stdout := logSettings.format, logSettings.stdout
+	logSettings.mu.RUnlock()
+
+	line := lw.format(e, format, false)
+	if err := lw.write(line); err != nil {
+		return 0, err
+	}
+	if stdout {
+		logSettings.stdoutMu.Lock()
+		_, _ = os.Stdout.Write(lw.format(e, format, true))
+		logSettings.stdoutMu.Unlock()
+	}
+	return len(p), nil
+}
+
+// write writes a formatted line to the underlying writer.
+func (lw *logWriter) write(line []byte) error {
+	lw.mu.Lock()
+	defer lw.mu.Unlock()
+	if lw.closed {
+		build.Critical("cannot write to the logger after it has been closed")
+		return nil
+	}
+	_, err := lw.staticW.Write(line)
+	return err
+}
+
+// lockedWriter writes to the underlying writer of a logWriter. It is used by
+// the writers of loggers with fields to share the output of their parent.
+type lockedWriter struct {
+	lw *logWriter
+}
+
+// Write implements io.Writer.
+func (w lockedWriter) Write(p []byte) (int, error) {
+	return len(p), w.lw.write(p)
+}
+
+// logEntry is a parsed log line.
+type logEntry struct {
+	time   time.Time
+	caller string
+	level  LogLevel
+	msg    string
+	raw    []byte
+}
+
+// parseLogLine parses a line written by the standard library logger with the
+// date, time, microseconds, short file and UTC flags.
+func parseLogLine(p []byte) logEntry {
+	e := logEntry{level: LogLevelInfo, raw: p}
+	line := strings.TrimSuffix(string(p), "\n")
+	if len(line) > len(logTimeLayout) {
+		if t, err := time.Parse(logTimeLayout, line[:len(logTimeLayout)]); err == nil {
+			e.time = t
+			line = line[len(logTimeLayout)+1:]
+			if i := strings.Index(line, ": "); i > 0 && !strings.Contains(line[:i], " ") {
+				e.caller = line[:i]
+				line = line[i+2:]
+			}
+		}
+	}
+	if e.time.IsZero() {
+		e.time = time.Now().UTC()
+	}
+	e.msg = strings.TrimSuffix(line, "\n")
+	for _, lp := range levelPrefixes {
+		if strings.HasPrefix(e.msg, lp.prefix) {
+			e.level = lp.level
+			break
+		}
+	}
+	if e.level == LogLevelDebug {
+		e.msg = strings.TrimPrefix(e.msg, debugPrefix)
+	}
+	return e
+}
+
+// format formats a log entry. If withModule is set, the module is included in
+// text entries, which is necessary when the entries of multiple modules are
+// merged.
+func (lw *logWriter) format(e logEntry, format string, withModule bool) []byte {
+	if format == LogFormatJSON {
+		obj := map[string]interface{}{
+			"time":   e.time.Format(time.RFC3339Nano),
+			"level":  e.level.String(),
+			"caller": e.caller,
+			"msg":    e.msg,
+		}
+		if lw.staticModule != "" {
+			obj["module"] = lw.staticModule
+		}
+		for _, f := range lw.staticFields {
+			// The fixed keys can't be overwritten.
+			if _, exists := obj[f.key]; !exists {
+				obj[f.key] = fieldValue(f.value)
+			}
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			b, _ = json.Marshal(map[string]interface{}{"time": obj["time"], "level": obj["level"], "msg": e.msg, "error": err.Error()})
+		}
+		return append(b, '\n')
+	}
+	if len(lw.staticFields) == 0 && !withModule {
+		return e.raw
+	}
+	var buf bytes.Buffer
+	if withModule && lw.staticModule != "" {
+		buf.WriteString("[" + lw.staticModule + "] ")
+	}
+	buf.Write(bytes.TrimSuffix(e.raw, []byte("\n")))
+	for _, f := range lw.staticFields {
+		fmt.Fprintf(&buf, " %v=%v", f.key, fieldValue(f.value))
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes()
+}
+
+// fieldValue returns the value of a field that is written to the log. Values
+// that implement fmt.Stringer are written as strings.
+func fieldValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case fmt.Stringer:
+		return v.String()
+	case error:
+		return v.Error()
+	}
+	return v
+}
@@ -14,8 +14,10 @@ import (
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/node"
 	"go.sia.tech/siad/node/api/client"
+	"go.sia.tech/siad/persist"
 	"go.sia.tech/siad/profile"
 	"go.sia.tech/siad/siatest"
+	"go.sia.tech/siad/types"
 )
 
 // TestDaemonAPIPassword makes sure that the daemon rejects requests with the
@@ -54,6 +56,85 @@ func TestDaemonAPIPassword(t *testing.T) {
 	}
 }
 
+// TestDaemonTokens tests creating, using and revoking scoped API tokens.
+func TestDaemonTokens(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server
+	testNode, err := siatest.NewCleanNode(node.Gateway(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Invalid scopes are rejected.
+	if _, err := testNode.DaemonTokensCreatePost("invalid", []string{"renter:admin"}, 0, types.ZeroCurrency); err == nil {
+		t.Fatal("token with invalid scope shouldn't be created")
+	}
+
+	// Create a read-only gateway token.
+	dtcp, err := testNode.DaemonTokensCreatePost("monitoring", []string{"gateway:read", "daemon:write"}, 0, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dtcp.Token == "" || dtcp.Name != "monitoring" {
+		t.Fatal("unexpected token", dtcp)
+	}
+	opts := testNode.Client.Options
+	opts.Password = dtcp.Token
+	c := client.New(opts)
+
+	// The token grants access to its scopes.
+	if _, err := c.GatewayGet(); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DaemonGlobalRateLimitPost(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	// But not to other routes or the token management.
+	if err := c.GatewayConnectPost(testNode.GatewayAddress()); err == nil || !strings.Contains(err.Error(), "doesn't grant access") {
+		t.Fatal("token shouldn't grant write access to the gateway", err)
+	}
+	if _, err := c.DaemonTokensGet(); err == nil {
+		t.Fatal("tokens shouldn't be able to manage tokens")
+	}
+
+	// The usage is tracked and the token is persisted.
+	if err := testNode.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	dtg, err := testNode.DaemonTokensGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dtg.Tokens) != 1 || dtg.Tokens[0].Name != "monitoring" || dtg.Tokens[0].LastUsed.IsZero() {
+		t.Fatal("unexpected tokens", dtg.Tokens)
+	}
+	opts.Address = testNode.Server.APIAddress()
+	c = client.New(opts)
+	if _, err := c.GatewayGet(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Revoke the token. It can't be used for protected routes anymore.
+	if err := testNode.DaemonTokensRevokePost("monitoring"); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.DaemonGlobalRateLimitPost(0, 0); err == nil {
+		t.Fatal("revoked token shouldn't be usable")
+	}
+	if dtg, err := testNode.DaemonTokensGet(); err != nil || len(dtg.Tokens) != 0 {
+		t.Fatal("token should be revoked", dtg.Tokens, err)
+	}
+}
+
 // TestDaemonRatelimit makes sure that we can set the daemon's global
 // ratelimits using the API and that they are persisted correctly.
 func TestDaemonRatelimit(t *testing.T) {
@@ -113,6 +194,257 @@ func TestDaemonRatelimit(t *testing.T) {
 	}
 }
 
+// TestDaemonMetrics tests enabling the /metrics endpoint and that it only
+// exports the metrics of the loaded modules.
+func TestDaemonMetrics(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server
+	params := node.Renter(testDir)
+	params.CreateAccounting = true
+	params.CreateMiner = true
+	testNode, err := siatest.NewCleanNode(params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// The endpoint is disabled by default.
+	if _, err := testNode.MetricsGet(); err == nil {
+		t.Fatal("metrics endpoint should be disabled")
+	}
+	dsg, err := testNode.DaemonSettingsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dsg.EnableMetrics {
+		t.Fatal("metrics should be disabled")
+	}
+
+	// Enable it.
+	if err := testNode.DaemonEnableMetricsPost(true); err != nil {
+		t.Fatal(err)
+	}
+	metrics, err := testNode.MetricsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{
+		"siad_accounting_renter_hastings",
+		"siad_build_info",
+		"siad_consensus_height",
+		"siad_gateway_peers",
+		"siad_miner_blocks_mined_total",
+		"siad_renter_memory_available_bytes",
+		"siad_renter_upload_heap_size",
+		"siad_renter_worker_read_job_seconds_count",
+		"siad_tpool_transactions",
+		"siad_wallet_unlocked",
+	} {
+		if !strings.Contains(string(metrics), "\n"+name) {
+			t.Errorf("metric %v is missing", name)
+		}
+	}
+	// The host isn't loaded.
+	if strings.Contains(string(metrics), "siad_host_") {
+		t.Error("host metrics shouldn't be exported")
+	}
+
+	// The setting is persisted.
+	if err := testNode.RestartNode(); err != nil {
+		t.Fatal(err)
+	}
+	dsg, err = testNode.DaemonSettingsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !dsg.EnableMetrics {
+		t.Fatal("metrics should be enabled")
+	}
+}
+
+// TestDaemonReload tests applying the config file using the /daemon/reload
+// endpoint.
+func TestDaemonReload(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server
+	testNode, err := siatest.NewCleanNode(node.Renter(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Reloading fails without a config file.
+	if _, err := testNode.DaemonReloadPost(); err == nil {
+		t.Fatal("reload without config file should fail")
+	}
+
+	// Write a config file. The host section is ignored since the host isn't
+	// loaded.
+	path := filepath.Join(testDir, "siad.json")
+	writeConfig := func(config string) {
+		if err := os.WriteFile(path, []byte(config), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	writeConfig(`{
+		"siad": {"rpc-addr": ":9981"},
+		"gateway": {"blocklist": ["123.123.123.123"]},
+		"renter": {"maxuploadspeed": 1000, "ipviolationcheck": false},
+		"host": {"acceptingcontracts": true}
+	}`)
+	testNode.SetConfigFile(path)
+	drp, err := testNode.DaemonReloadPost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var changed []string
+	for _, c := range drp.Changes {
+		changed = append(changed, c.Setting)
+	}
+	expected := []string{"gateway.blocklist", "renter.ipviolationcheck", "renter.maxuploadspeed"}
+	if strings.Join(changed, ",") != strings.Join(expected, ",") {
+		t.Fatal("unexpected changes", drp.Changes)
+	}
+	rg, err := testNode.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Settings.MaxUploadSpeed != 1000 || rg.Settings.IPViolationCheck {
+		t.Fatal("renter settings weren't applied", rg.Settings)
+	}
+	gbg, err := testNode.GatewayBlocklistGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gbg.Blocklist) != 1 || gbg.Blocklist[0] != "123.123.123.123" {
+		t.Fatal("blocklist wasn't applied", gbg.Blocklist)
+	}
+
+	// Reloading the same file doesn't change anything.
+	drp, err = testNode.DaemonReloadPost()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(drp.Changes) != 0 {
+		t.Fatal("expected no changes", drp.Changes)
+	}
+
+	// Unknown settings are rejected.
+	writeConfig(`{"renter": {"maxuploadspeeed": 2000}}`)
+	if _, err := testNode.DaemonReloadPost(); err == nil {
+		t.Fatal("unknown setting should be rejected")
+	}
+	rg, err = testNode.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Settings.MaxUploadSpeed != 1000 {
+		t.Fatal("renter settings shouldn't change", rg.Settings)
+	}
+}
+
+// TestDaemonLogging tests changing the log settings using the
+// /daemon/logging endpoint.
+func TestDaemonLogging(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server
+	testNode, err := siatest.NewCleanNode(node.Renter(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// The loggers of the node's modules are registered.
+	dlg, err := testNode.DaemonLoggingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, module := range []string{"consensus", "gateway", "renter", "wallet"} {
+		found := false
+		for _, m := range dlg.Modules {
+			found = found || m == module
+		}
+		if !found {
+			t.Errorf("module %v is missing: %v", module, dlg.Modules)
+		}
+	}
+	if dlg.Format != persist.LogFormatText || dlg.Stdout {
+		t.Fatal("unexpected default settings", dlg.LogSettings)
+	}
+	defaultLevel := dlg.DefaultLevel
+
+	// Change the settings.
+	if err := testNode.DaemonLoggingLevelPost("renter", "warn"); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.DaemonLoggingLevelPost("", "error"); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.DaemonLoggingFormatPost(persist.LogFormatJSON); err != nil {
+		t.Fatal(err)
+	}
+	dlg, err = testNode.DaemonLoggingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dlg.Levels["renter"] != persist.LogLevelWarn || dlg.DefaultLevel != persist.LogLevelError || dlg.Format != persist.LogFormatJSON {
+		t.Fatal("settings weren't applied", dlg.LogSettings)
+	}
+
+	// Invalid settings are rejected.
+	if err := testNode.DaemonLoggingLevelPost("renter", "verbose"); err == nil {
+		t.Fatal("invalid level was accepted")
+	}
+	if err := testNode.DaemonLoggingLevelPost("", ""); err == nil {
+		t.Fatal("default level was cleared")
+	}
+	if err := testNode.DaemonLoggingFormatPost("xml"); err == nil {
+		t.Fatal("invalid format was accepted")
+	}
+
+	// Restore the settings.
+	if err := testNode.DaemonLoggingLevelPost("renter", ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.DaemonLoggingLevelPost("", defaultLevel.String()); err != nil {
+		t.Fatal(err)
+	}
+	if err := testNode.DaemonLoggingFormatPost(persist.LogFormatText); err != nil {
+		t.Fatal(err)
+	}
+	dlg, err = testNode.DaemonLoggingGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, exists := dlg.Levels["renter"]; exists || dlg.DefaultLevel != defaultLevel || dlg.Format != persist.LogFormatText {
+		t.Fatal("settings weren't restored", dlg.LogSettings)
+	}
+}
+
 // TestGlobalRatelimitRenter makes sure that if multiple ratelimits are set, the
 // lower one is respected.
 func TestGlobalRatelimitRenter(t *testing.T) {
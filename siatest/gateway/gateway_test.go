@@ -289,7 +289,7 @@ func TestGatewayBandwidth(t *testing.T) {
 			t.Fatal(err)
 		}
 	}()
-	gateway2, err := siatest.NewCleanNode(node.Gateway(testDir + "/gateway1"))
+	gateway2, err := siatest.NewCleanNode(node.Gateway(testDir + "/gateway2"))
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -245,6 +245,20 @@ func (tn *TestNode) KnowsHost(host *TestNode) error {
 	return err
 }
 
+// Copy copies a remoteFile without uploading it again and returns the copy.
+func (tn *TestNode) Copy(rf *RemoteFile, newPath modules.SiaPath) (*RemoteFile, error) {
+	err := tn.RenterCopyPost(rf.SiaPath(), newPath, false)
+	if err != nil {
+		return nil, err
+	}
+	rf.mu.Lock()
+	defer rf.mu.Unlock()
+	return &RemoteFile{
+		siaPath:  newPath,
+		checksum: rf.checksum,
+	}, nil
+}
+
 // Rename renames a remoteFile with the root parameter set to false and returns
 // the new file.
 func (tn *TestNode) Rename(rf *RemoteFile, newPath modules.SiaPath) (*RemoteFile, error) {
@@ -1,6 +1,7 @@
 package renter
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -246,6 +247,7 @@ func TestRenterThree(t *testing.T) {
 		{Name: "TestAllowanceDefaultSet", Test: testAllowanceDefaultSet},
 		{Name: "TestSetFileStuck", Test: testSetFileStuck},
 		{Name: "TestCancelAsyncDownload", Test: testCancelAsyncDownload},
+		{Name: "TestRenterTraces", Test: testRenterTraces},
 		{Name: "TestUploadDownload", Test: testUploadDownload}, // Needs to be last as it impacts hosts
 	}
 
@@ -278,6 +280,14 @@ func TestRenterFour(t *testing.T) {
 		{Name: "TestPauseAndResumeRepairAndUploads", Test: testPauseAndResumeRepairAndUploads},
 		{Name: "TestDownloadServedFromDisk", Test: testDownloadServedFromDisk},
 		{Name: "TestDirMode", Test: testDirMode},
+		{Name: "TestStoragePolicy", Test: testStoragePolicy},
+		{Name: "TestReencodeFile", Test: testReencodeFile},
+		{Name: "TestVerifyFile", Test: testVerifyFile},
+		{Name: "TestUserMetadata", Test: testUserMetadata},
+		{Name: "TestQueryFiles", Test: testQueryFiles},
+		{Name: "TestSyncDir", Test: testSyncDir},
+		{Name: "TestCopyFile", Test: testCopyFile},
+		{Name: "TestTenants", Test: testTenants},
 		{Name: "TestEscapeSiaPath", Test: testEscapeSiaPath}, // Runs last because it uploads many files
 	}
 
@@ -386,6 +396,102 @@ func testReceivedFieldEqualsFileSize(t *testing.T, tg *siatest.TestGroup) {
 	}
 }
 
+// testRenterTraces tests that downloads are traced down to the jobs of the
+// workers and that the traces are exported.
+func testRenterTraces(t *testing.T, tg *siatest.TestGroup) {
+	r := tg.Renters()[0]
+
+	// Export the traces to a file. Relative paths are rejected.
+	if err := r.RenterTracesExportPost("traces.json"); err == nil {
+		t.Fatal("relative export path was accepted")
+	}
+	exportPath := filepath.Join(r.RenterDir(), "traces.json")
+	if err := r.RenterTracesExportPost(exportPath); err != nil {
+		t.Fatal(err)
+	}
+	rtg, err := r.RenterTracesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rtg.ExportDestination != exportPath {
+		t.Fatal("unexpected export destination", rtg.ExportDestination)
+	}
+
+	// Upload a file and download it from the hosts.
+	_, rf, err := r.UploadNewFileBlocking(int(modules.SectorSize), 1, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uid, _, err := r.DownloadToDiskWithDiskFetch(rf, false, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	di, err := r.RenterDownloadInfoGet(uid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if di.TraceID == "" {
+		t.Fatal("download wasn't traced")
+	}
+
+	// The trace of the download contains the chunk and the read sector jobs
+	// of the workers.
+	trace, err := r.RenterTraceGet(di.TraceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !trace.Finished || trace.Name != "download" || trace.Error != "" || !strings.HasSuffix(trace.Attributes["siapath"], rf.SiaPath().String()) {
+		t.Fatal("unexpected trace", trace)
+	}
+	ids := make(map[string]string)
+	for _, span := range trace.Spans {
+		ids[span.Name] = span.ID
+	}
+	var reads int
+	for _, span := range trace.Spans {
+		if span.Name != "worker.readSector" {
+			continue
+		}
+		reads++
+		if span.ParentID != ids["download.chunk"] || span.Attributes["hostkey"] == "" {
+			t.Fatal("unexpected read sector span", span)
+		}
+	}
+	if reads == 0 {
+		t.Fatal("read sector jobs weren't traced", trace.Spans)
+	}
+
+	// The OTLP encoding contains the same spans.
+	otlp, err := r.RenterTraceOTLPGet(di.TraceID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if spans := otlp.ResourceSpans[0].ScopeSpans[0].Spans; len(spans) != len(trace.Spans) || spans[0].TraceID != di.TraceID {
+		t.Fatal("unexpected OTLP spans", spans)
+	}
+	if _, err := r.RenterTraceGet("unknown"); err == nil {
+		t.Fatal("unknown trace was found")
+	}
+
+	// The spans of the download are exported.
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		b, err := ioutil.ReadFile(exportPath)
+		if err != nil {
+			return err
+		}
+		if !strings.Contains(string(b), di.TraceID) {
+			return errors.New("trace wasn't exported yet")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.RenterTracesExportPost(""); err != nil {
+		t.Fatal(err)
+	}
+}
+
 // testClearDownloadHistory makes sure that the download history is
 // properly cleared when called through the API
 func testClearDownloadHistory(t *testing.T, tg *siatest.TestGroup) {
@@ -5769,3 +5875,640 @@ func TestRenterFileHosts(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// testStoragePolicy tests that the storage policy of a directory is inherited
+// by its subdirectories and applied to new uploads.
+func testStoragePolicy(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Set a policy which stores a full copy of the data on every host.
+	dirSP := modules.RandomSiaPath()
+	subDirSP, err := dirSP.Join("sub")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := renter.RenterDirCreatePost(subDirSP); err != nil {
+		t.Fatal(err)
+	}
+	policy := modules.StoragePolicy{
+		DataPieces:     1,
+		ParityPieces:   len(tg.Hosts()) - 1,
+		CipherType:     crypto.TypePlain.String(),
+		RepairPriority: 1,
+	}
+	if err := renter.RenterDirSetStoragePolicyPost(dirSP, policy); err != nil {
+		t.Fatal(err)
+	}
+
+	// The policy is set on the dir and inherited by the subdir.
+	rd, err := renter.RenterDirGet(dirSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rd.StoragePolicy, policy) || !reflect.DeepEqual(rd.Directories[0].StoragePolicy, policy) {
+		t.Fatal("policy wasn't set", rd.StoragePolicy, rd.Directories[0].StoragePolicy)
+	}
+	rd, err = renter.RenterDirGet(subDirSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rd.StoragePolicy, policy) || rd.Directories[0].StoragePolicy.Active() {
+		t.Fatal("policy wasn't inherited", rd.StoragePolicy, rd.Directories[0].StoragePolicy)
+	}
+
+	// Invalid policies are rejected.
+	if err := renter.RenterDirSetStoragePolicyPost(dirSP, modules.StoragePolicy{RepairThreshold: 2}); err == nil {
+		t.Fatal("invalid policy should be rejected")
+	}
+
+	// Upload a file without erasure coding parameters into the subdir. The
+	// file should use the parameters of the policy and reach full health,
+	// which it couldn't with the default parameters.
+	lf, err := renter.FilesDir().NewFile(int(modules.SectorSize) + siatest.Fuzz())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileSP, err := subDirSP.Join(lf.FileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := renter.RenterUploadDefaultPost(lf.Path(), fileSP); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		rf, err := renter.RenterFileGet(fileSP)
+		if err != nil {
+			return err
+		}
+		if rf.File.CipherType != crypto.TypePlain.String() {
+			return fmt.Errorf("expected cipher type %v but got %v", crypto.TypePlain, rf.File.CipherType)
+		}
+		if rf.File.MaxHealth != 0 {
+			return fmt.Errorf("expected full health but got %v", rf.File.MaxHealth)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing the policy restores the defaults.
+	if err := renter.RenterDirSetStoragePolicyPost(dirSP, modules.StoragePolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	rd, err = renter.RenterDirGet(subDirSP)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rd.StoragePolicy.Active() {
+		t.Fatal("policy wasn't removed", rd.StoragePolicy)
+	}
+}
+
+// testReencodeFile tests that re-encoding a file replaces its siafile with one
+// using the new parameters without changing its data.
+func testReencodeFile(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Upload a file.
+	lf, rf, err := renter.UploadNewFileBlocking(int(modules.SectorSize)+siatest.Fuzz(), 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fi, err := renter.File(rf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.CipherType == crypto.TypePlain.String() {
+		t.Fatal("file shouldn't be uploaded in plaintext")
+	}
+
+	// Re-encoding without new parameters is rejected.
+	if err := renter.RenterReencodePost(rf.SiaPath(), 0, 0, ""); err == nil {
+		t.Fatal("re-encode without parameters should fail")
+	}
+	if err := renter.RenterReencodePost(rf.SiaPath(), 0, 0, fi.CipherType); err == nil {
+		t.Fatal("re-encode with unchanged parameters should fail")
+	}
+
+	// Re-encode the file in plaintext.
+	if err := renter.RenterReencodePost(rf.SiaPath(), 0, 0, crypto.TypePlain.String()); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		fi, err := renter.File(rf)
+		if err != nil {
+			return err
+		}
+		if fi.CipherType != crypto.TypePlain.String() {
+			return fmt.Errorf("expected cipher type %v but got %v", crypto.TypePlain, fi.CipherType)
+		}
+		if fi.Filesize != uint64(lf.Size()) {
+			return fmt.Errorf("expected size %v but got %v", lf.Size(), fi.Filesize)
+		}
+		if fi.LocalPath != lf.Path() {
+			return fmt.Errorf("expected local path %v but got %v", lf.Path(), fi.LocalPath)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The data is unchanged and the re-encode folder was cleaned up.
+	_, data, err := renter.DownloadByStreamWithDiskFetch(rf, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := lf.Equal(data); err != nil {
+		t.Fatal(err)
+	}
+	rd, err := renter.RenterDirRootGet(modules.ReencodeFolder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rd.Directories[0].AggregateNumFiles != 0 {
+		t.Fatal("re-encode folder wasn't cleaned up", rd.Directories[0].AggregateNumFiles)
+	}
+}
+
+// testVerifyFile tests that the checksum of an uploaded file is computed and
+// that the data on the network can be verified against it.
+func testVerifyFile(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Upload a file into a folder and wait for its checksum.
+	lf, err := renter.FilesDir().NewFile(int(modules.SectorSize) + siatest.Fuzz())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := modules.NewSiaPath("verify")
+	if err != nil {
+		t.Fatal(err)
+	}
+	siaPath, err := dir.Join(lf.FileName())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rf, err := renter.Upload(lf, siaPath, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := renter.WaitForUploadHealth(rf); err != nil {
+		t.Fatal(err)
+	}
+	data, err := lf.Data()
+	if err != nil {
+		t.Fatal(err)
+	}
+	checksum := crypto.HashBytes(data).String()
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		fi, err := renter.File(rf)
+		if err != nil {
+			return err
+		}
+		if fi.ChecksumType != modules.ChecksumBLAKE2b {
+			return fmt.Errorf("expected checksum type %v but got %v", modules.ChecksumBLAKE2b, fi.ChecksumType)
+		}
+		if fi.Checksum != checksum {
+			return fmt.Errorf("expected checksum %v but got %v", checksum, fi.Checksum)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Verify the file.
+	fv, err := renter.RenterVerifyPost(rf.SiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fv.Verified || fv.ActualChecksum != checksum {
+		t.Fatal("file wasn't verified", fv)
+	}
+	fi, err := renter.File(rf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.VerifyFailed || fi.LastVerified.IsZero() {
+		t.Fatal("verification wasn't recorded", fi.LastVerified, fi.VerifyFailed)
+	}
+
+	// Verify the folder in the background.
+	lastVerified := fi.LastVerified
+	if err := renter.RenterVerifyDirPost(dir); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		fi, err := renter.File(rf)
+		if err != nil {
+			return err
+		}
+		if !fi.LastVerified.After(lastVerified) {
+			return errors.New("file wasn't verified again")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testUserMetadata tests setting the user metadata of files and directories
+// and filtering files by tags.
+func testUserMetadata(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Upload two files.
+	_, rf1, err := renter.UploadNewFileBlocking(100, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, rf2, err := renter.UploadNewFileBlocking(100, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Set the metadata of the files.
+	if err := renter.RenterFileUserMetadataPost(rf1.SiaPath(), modules.UserMetadata{"contenttype": "image/png", "owner": "alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := renter.RenterFileUserMetadataPost(rf2.SiaPath(), modules.UserMetadata{"contenttype": "text/plain"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := renter.RenterFileUserMetadataPost(rf2.SiaPath(), modules.UserMetadata{"a=b": "c"}); err == nil {
+		t.Fatal("invalid metadata should be rejected")
+	}
+	fi, err := renter.File(rf1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.UserMetadata["contenttype"] != "image/png" || fi.UserMetadata["owner"] != "alice" {
+		t.Fatal("wrong user metadata", fi.UserMetadata)
+	}
+
+	// Filter the files by tags.
+	for _, cached := range []bool{false, true} {
+		rf, err := renter.RenterFilesTaggedGet(cached, "contenttype=image/png")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rf.Files) != 1 || !rf.Files[0].SiaPath.Equals(rf1.SiaPath()) {
+			t.Fatal("wrong files for tag", rf.Files)
+		}
+		rf, err = renter.RenterFilesTaggedGet(cached, "contenttype")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rf.Files) < 2 {
+			t.Fatal("expected at least 2 files for tag", len(rf.Files))
+		}
+		rf, err = renter.RenterFilesTaggedGet(cached, "contenttype", "owner=bob")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(rf.Files) != 0 {
+			t.Fatal("expected no files for tags", rf.Files)
+		}
+	}
+
+	// Set the metadata of a directory.
+	rd, err := renter.UploadNewDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := renter.RenterDirSetUserMetadataPost(rd.SiaPath(), modules.UserMetadata{"retention": "archive"}); err != nil {
+		t.Fatal(err)
+	}
+	dir, err := renter.RenterDirGet(rd.SiaPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dir.Directories[0].UserMetadata["retention"] != "archive" {
+		t.Fatal("wrong directory metadata", dir.Directories[0].UserMetadata)
+	}
+}
+
+// testQueryFiles tests filtering, sorting and paging through the files of the
+// renter.
+func testQueryFiles(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Upload files of different sizes and tag them to tell them apart from
+	// the files of other tests.
+	sizes := []int{300, 100, 200}
+	for _, size := range sizes {
+		_, rf, err := renter.UploadNewFileBlocking(size, 1, 1, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := renter.RenterFileUserMetadataPost(rf.SiaPath(), modules.UserMetadata{"test": "query"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	filter, err := modules.ParseFileFilter("size>=150")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Page through the matching files by descending size.
+	for _, cached := range []bool{false, true} {
+		q := modules.FileQuery{
+			Filter:     filter,
+			Tags:       modules.UserMetadata{"test": "query"},
+			Sort:       modules.FileFieldSize,
+			Descending: true,
+			Limit:      1,
+		}
+		var found []uint64
+		for {
+			rf, err := renter.RenterFilesQueryGet(cached, q)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, file := range rf.Files {
+				found = append(found, file.Filesize)
+			}
+			if rf.NextCursor == "" {
+				break
+			}
+			if len(found) > len(sizes) {
+				t.Fatal("too many files", found)
+			}
+			q.Cursor = rf.NextCursor
+		}
+		if fmt.Sprint(found) != fmt.Sprint([]uint64{300, 200}) {
+			t.Fatal("wrong files", found)
+		}
+	}
+
+	// Invalid queries are rejected.
+	if _, err := renter.RenterFilesQueryGet(true, modules.FileQuery{Sort: modules.FileFieldStuck}); err == nil {
+		t.Fatal("expected error for invalid sort field")
+	}
+	if _, err := renter.RenterFilesQueryGet(true, modules.FileQuery{Cursor: "invalid"}); err == nil {
+		t.Fatal("expected error for invalid cursor")
+	}
+}
+
+// testSyncDir tests synchronizing a local directory to the renter.
+func testSyncDir(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Create a local directory with some files.
+	ld, err := renter.FilesDir().CreateDir("sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, size := range []int{100, 200} {
+		if _, err := ld.NewFile(size); err != nil {
+			t.Fatal(err)
+		}
+	}
+	siaPath, err := modules.NewSiaPath("sync")
+	if err != nil {
+		t.Fatal(err)
+	}
+	params := modules.SyncParams{
+		LocalPath: ld.Path(),
+		SiaPath:   siaPath,
+		Delete:    true,
+	}
+
+	// A dry run only plans the uploads.
+	run, err := renter.RenterSyncPost(params, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if run.ID != "" || len(run.Actions) != 2 || run.Actions[0].Type != modules.SyncActionUpload {
+		t.Fatal("wrong dry run", run)
+	}
+	if _, err := renter.RenterDirGet(siaPath); err == nil {
+		t.Fatal("dry run shouldn't upload files")
+	}
+
+	// syncDir runs a sync and waits for it to finish.
+	syncDir := func() modules.SyncRun {
+		run, err := renter.RenterSyncPost(params, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = build.Retry(100, 100*time.Millisecond, func() error {
+			rs, err := renter.RenterSyncGet()
+			if err != nil {
+				return err
+			}
+			for _, r := range rs.Runs {
+				if r.ID == run.ID && r.Completed {
+					run = r
+					return nil
+				}
+			}
+			return errors.New("sync not completed")
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if run.Error != "" {
+			t.Fatal(run.Error, run.Actions)
+		}
+		return run
+	}
+	run = syncDir()
+	if !run.Params.SiaPath.Equals(siaPath) || len(run.Actions) != 2 {
+		t.Fatal("wrong sync run", run)
+	}
+	rd, err := renter.RenterDirGet(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rd.Files) != 2 {
+		t.Fatalf("expected 2 files but got %v", len(rd.Files))
+	}
+
+	// Add a file and remove another one.
+	if _, err := ld.NewFile(300); err != nil {
+		t.Fatal(err)
+	}
+	files, err := ioutil.ReadDir(ld.Path())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(ld.Path(), files[0].Name())); err != nil {
+		t.Fatal(err)
+	}
+	run = syncDir()
+	if len(run.Actions) != 2 {
+		t.Fatal("expected an upload and a delete", run.Actions)
+	}
+	rd, err = renter.RenterDirGet(siaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rd.Files) != 2 {
+		t.Fatalf("expected 2 files but got %v", len(rd.Files))
+	}
+
+	// Nothing changes when syncing again.
+	run, err = renter.RenterSyncPost(params, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(run.Actions) != 0 {
+		t.Fatal("expected no actions", run.Actions)
+	}
+}
+
+// testCopyFile tests copying a file without uploading it again.
+func testCopyFile(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Upload a file and copy it.
+	_, rf, err := renter.UploadNewFileBlocking(int(modules.SectorSize)+100, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copySiaPath, err := modules.NewSiaPath("copies/" + rf.SiaPath().Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cpy, err := renter.Copy(rf, copySiaPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renter.Copy(rf, copySiaPath); err == nil {
+		t.Fatal("copying to an existing file should fail")
+	}
+
+	// The copy doesn't have a local path and its data can be downloaded
+	// from the hosts.
+	fi, err := renter.File(cpy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fi.LocalPath != "" || fi.OnDisk {
+		t.Fatal("copy shouldn't have a local path", fi.LocalPath)
+	}
+	if _, _, err := renter.DownloadByStreamWithDiskFetch(cpy, true); err != nil {
+		t.Fatal(err)
+	}
+
+	// Deleting the original keeps the data of the copy.
+	if err := renter.RenterFileDeletePost(rf.SiaPath()); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := renter.DownloadByStreamWithDiskFetch(cpy, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// testTenants tests the namespaces and quotas of tenants.
+func testTenants(t *testing.T, tg *siatest.TestGroup) {
+	renter := tg.Renters()[0]
+
+	// Create a tenant and a token for it.
+	tenant := modules.Tenant{
+		Name:          "team",
+		StorageQuota:  1000,
+		UploadQuota:   1500,
+		DownloadQuota: 150,
+	}
+	if err := renter.RenterTenantsSetPost(tenant); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renter.DaemonTokensCreateTenantPost("team", "unknown", []string{"renter:write"}, 0, types.ZeroCurrency); err == nil {
+		t.Fatal("token of unknown tenant shouldn't be created")
+	}
+	dtcp, err := renter.DaemonTokensCreateTenantPost("team", tenant.Name, []string{"renter:write"}, 0, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := renter.Client.Options
+	opts.Password = dtcp.Token
+	c := client.New(opts)
+
+	// Upload a file as the tenant. Its siapath is relative to the root of the
+	// tenant.
+	siaPath, err := modules.NewSiaPath("file")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := fastrand.Bytes(600)
+	if err := c.RenterUploadStreamPost(bytes.NewReader(data), siaPath, 1, 1, false); err != nil {
+		t.Fatal(err)
+	}
+	rf, err := c.RenterFilesGet(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rf.Files) != 1 || !rf.Files[0].SiaPath.Equals(siaPath) {
+		t.Fatal("tenant should only see its own file", rf.Files)
+	}
+	tenantRoot, err := tenant.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	absSiaPath, err := tenantRoot.Join(siaPath.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := renter.RenterFileRootGet(absSiaPath); err != nil {
+		t.Fatal(err)
+	}
+
+	// The tenant can't access other files or routes.
+	if _, err := c.RenterFileRootGet(absSiaPath); err == nil {
+		t.Fatal("tenant shouldn't be able to use the root flag")
+	}
+	if _, _, err := c.RenterDownloadHTTPResponseGet(absSiaPath, 0, 100, true, true); err == nil || !strings.Contains(err.Error(), "can't use the 'root' arg") {
+		t.Fatal("tenant shouldn't be able to download with the root flag", err)
+	}
+	if _, err := c.RenterContractsGet(); err == nil {
+		t.Fatal("tenant shouldn't be able to access the contracts")
+	}
+
+	// Uploads and downloads are limited by the quotas.
+	other, err := modules.NewSiaPath("other")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.RenterUploadStreamPost(bytes.NewReader(data), other, 1, 1, false); err == nil || !strings.Contains(err.Error(), "storage quota") {
+		t.Fatal("expected storage quota to be exceeded", err)
+	}
+	_, downloaded, err := c.RenterDownloadHTTPResponseGet(siaPath, 0, 100, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(downloaded, data[:100]) {
+		t.Fatal("downloaded data doesn't match")
+	}
+	if _, _, err := c.RenterDownloadHTTPResponseGet(siaPath, 0, 100, true, false); err == nil || !strings.Contains(err.Error(), "download quota") {
+		t.Fatal("expected download quota to be exceeded", err)
+	}
+
+	// The tenant only gets its own usage. The data of the failed upload that
+	// was read before the quota was exceeded counts towards the uploaded data
+	// but not towards the storage.
+	rtg, err := c.RenterTenantsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rtg.Tenants) != 1 {
+		t.Fatal("unexpected tenants", rtg.Tenants)
+	}
+	if usage := rtg.Tenants[0].Usage; usage.Storage != 600 || usage.Uploaded < 600 || usage.Downloaded != 100 {
+		t.Fatal("unexpected usage", usage)
+	}
+
+	// Removing the tenant revokes its token but keeps its files.
+	if err := renter.RenterTenantsRemovePost(tenant.Name); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.RenterFilesGet(false); err == nil {
+		t.Fatal("token of removed tenant should be revoked")
+	}
+	if _, err := renter.RenterFileRootGet(absSiaPath); err != nil {
+		t.Fatal(err)
+	}
+}
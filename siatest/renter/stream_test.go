@@ -14,6 +14,7 @@ import (
 	"go.sia.tech/siad/crypto"
 	"go.sia.tech/siad/modules"
 	"go.sia.tech/siad/node"
+	"go.sia.tech/siad/node/api"
 	"go.sia.tech/siad/siatest"
 	"go.sia.tech/siad/siatest/dependencies"
 )
@@ -181,6 +182,7 @@ func TestRenterStream(t *testing.T) {
 		{Name: "TestStreamLargeFile", Test: testStreamLargeFile},
 		{Name: "TestStreamRepair", Test: testStreamRepair},
 		{Name: "TestUploadStreaming", Test: testUploadStreaming},
+		{Name: "TestUploadStreamingCompressed", Test: testUploadStreamingCompressed},
 		{Name: "TestUploadStreamingWithBadDeps", Test: testUploadStreamingWithBadDeps},
 	}
 
@@ -344,6 +346,108 @@ func testUploadStreaming(t *testing.T, tg *siatest.TestGroup) {
 	}
 }
 
+// testUploadStreamingCompressed uploads compressible data using the upload
+// streaming API with compression enabled and checks that the file and ranges
+// of it can be downloaded and streamed.
+func testUploadStreamingCompressed(t *testing.T, tg *siatest.TestGroup) {
+	// Create some compressible data to write.
+	pattern := fastrand.Bytes(100)
+	data := bytes.Repeat(pattern, 3*int(modules.SectorSize)/len(pattern)+fastrand.Intn(100))
+	data = append(data, fastrand.Bytes(fastrand.Intn(100)+1)...)
+
+	// Upload the data.
+	siaPath, err := modules.NewSiaPath("/compressed")
+	if err != nil {
+		t.Fatal(err)
+	}
+	r := tg.Renters()[0]
+	err = r.RenterUploadStreamCompressedPost(bytes.NewReader(data), siaPath, 1, uint64(len(tg.Hosts())-1), false, modules.CompressionGzip)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make sure the file reached full redundancy.
+	var rfg api.RenterFile
+	err = build.Retry(100, 600*time.Millisecond, func() error {
+		rfg, err = r.RenterFileGet(siaPath)
+		if err != nil {
+			return err
+		}
+		if rfg.File.Redundancy < float64(len(tg.Hosts())) {
+			return fmt.Errorf("expected redundancy %v but was %v",
+				len(tg.Hosts()), rfg.File.Redundancy)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The file should report the uncompressed size.
+	if rfg.File.Compression != modules.CompressionGzip {
+		t.Fatal("file should be compressed", rfg.File.Compression)
+	}
+	if rfg.File.Filesize != uint64(len(data)) {
+		t.Fatalf("expected file to have size %v but was %v", len(data), rfg.File.Filesize)
+	}
+	if rfg.File.CompressedSize == 0 || rfg.File.CompressedSize >= rfg.File.Filesize {
+		t.Fatalf("expected compressed size to be smaller than %v but was %v", rfg.File.Filesize, rfg.File.CompressedSize)
+	}
+
+	// Download the whole file and a range of it.
+	_, downloadedData, err := r.RenterDownloadHTTPResponseGet(siaPath, 0, uint64(len(data)), true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, downloadedData) {
+		t.Fatal("Downloaded data doesn't match uploaded data")
+	}
+	offset := uint64(fastrand.Intn(len(data) / 2))
+	length := uint64(fastrand.Intn(len(data)/2) + 1)
+	_, downloadedData, err = r.RenterDownloadHTTPResponseGet(siaPath, offset, length, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[offset:offset+length], downloadedData) {
+		t.Fatal("Downloaded range doesn't match uploaded data")
+	}
+
+	// Download a range to disk.
+	dst := filepath.Join(r.DownloadDir().Path(), "compressed")
+	_, err = r.RenterDownloadGet(siaPath, dst, offset, length, false, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	downloadedData, err = ioutil.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[offset:offset+length], downloadedData) {
+		t.Fatal("Downloaded range doesn't match uploaded data")
+	}
+
+	// Stream the whole file and a range of it.
+	streamedData, err := r.RenterStreamGet(siaPath, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, streamedData) {
+		t.Fatal("Streamed data doesn't match uploaded data")
+	}
+	streamedData, err = r.RenterStreamPartialGet(siaPath, offset, offset+length, true, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data[offset:offset+length], streamedData) {
+		t.Fatal("Streamed range doesn't match uploaded data")
+	}
+
+	// Compressed files can't be repaired from a stream.
+	if err := r.RenterUploadStreamRepairPost(bytes.NewReader(data), siaPath); err == nil {
+		t.Fatal("repairing a compressed file from a stream should fail")
+	}
+}
+
 // testUploadStreamingWithBadDeps uploads random data using the upload streaming
 // API, depending on a disrupt to cause a failure. This is a regression test
 // that would have caused a production build panic.
@@ -206,6 +206,37 @@ func newCleanNode(nodeParams node.NodeParams, asyncSync bool) (*TestNode, error)
 	return tn, nil
 }
 
+// LoadNode starts a TestNode from the directory of a node that was created
+// before, e.g. by a previous run of a TestGroup, and unlocks its wallet using
+// the primary seed. The API listens on apiAddr.
+func LoadNode(apiAddr string, nodeParams node.NodeParams, primarySeed string) (*TestNode, error) {
+	s, err := server.New(apiAddr, "Sia-Agent", "password", nodeParams, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	c := client.New(client.Options{
+		Address:   s.APIAddress(),
+		Password:  "password",
+		UserAgent: "Sia-Agent",
+	})
+	tn := &TestNode{
+		Server:      s,
+		Client:      *c,
+		params:      nodeParams,
+		primarySeed: primarySeed,
+	}
+	if err := tn.initRootDirs(); err != nil {
+		return nil, errors.Compose(errors.AddContext(err, "failed to create root directories"), s.Close())
+	}
+	if !nodeParams.CreateWallet && nodeParams.Wallet == nil {
+		return tn, nil
+	}
+	if err := tn.WalletUnlockPost(primarySeed); err != nil {
+		return nil, errors.Compose(errors.AddContext(err, "failed to unlock wallet"), s.Close())
+	}
+	return tn, nil
+}
+
 // IsAlertRegistered returns an error if the given alert is not found
 func (tn *TestNode) IsAlertRegistered(a modules.Alert) error {
 	return build.Retry(10, 100*time.Millisecond, func() error {
@@ -350,6 +381,11 @@ func (tn *TestNode) PrintDebugInfo(t *testing.T, contractInfo, hostInfo, renterI
 	}
 }
 
+// PrimarySeed returns the primary seed of the TestNode's wallet.
+func (tn *TestNode) PrimarySeed() string {
+	return tn.primarySeed
+}
+
 // RestartNode restarts a TestNode
 func (tn *TestNode) RestartNode() error {
 	err := tn.StopNode()
@@ -969,3 +969,133 @@ func TestWalletVerifyPassword(t *testing.T) {
 		t.Error("Password should not be valid")
 	}
 }
+
+// TestWalletSpendingPolicy tests the spending policy endpoints and that the
+// policy applies to sends and to the renter allowance.
+func TestWalletSpendingPolicy(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+
+	// Create a testgroup with a renter.
+	groupParams := siatest.GroupParams{
+		Miners:  1,
+		Renters: 1,
+	}
+	tg, err := siatest.NewGroupFromTemplate(walletTestDir(t.Name()), groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group: ", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	renter := tg.Renters()[0]
+	wsg, err := renter.WalletSeedsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	password := wsg.PrimarySeed
+	uc, err := tg.Miners()[0].WalletAddressGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := uc.Address
+
+	// The policy can't be changed without the wallet password.
+	policy := modules.SpendingPolicy{
+		ApprovalThreshold:   types.SiacoinPrecision.Mul64(10),
+		AllowedDestinations: []types.UnlockHash{dest},
+		MaxAllowanceFunds:   types.SiacoinPrecision.Mul64(100),
+	}
+	if err := renter.WalletPolicyPost(policy, "wrong"); err == nil {
+		t.Fatal("policy was changed with the wrong password")
+	}
+	if err := renter.WalletPolicyPost(policy, password); err != nil {
+		t.Fatal(err)
+	}
+	wpg, err := renter.WalletPolicyGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !wpg.ApprovalThreshold.Equals(policy.ApprovalThreshold) || len(wpg.AllowedDestinations) != 1 || wpg.AllowedDestinations[0] != dest {
+		t.Fatal("unexpected policy", wpg)
+	}
+
+	// Sending to another address fails, sending below the threshold works.
+	if _, err := renter.WalletSiacoinsPost(types.SiacoinPrecision, types.UnlockHash{}, false); err == nil || !strings.Contains(err.Error(), modules.ErrDestinationNotAllowed.Error()) {
+		t.Fatal("expected ErrDestinationNotAllowed, got", err)
+	}
+	if _, err := renter.WalletSignPost(types.Transaction{}, nil); err == nil || !strings.Contains(err.Error(), modules.ErrSigningRestricted.Error()) {
+		t.Fatal("expected ErrSigningRestricted, got", err)
+	}
+	wsp, err := renter.WalletSiacoinsPost(types.SiacoinPrecision, dest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsp.PendingSpendID != "" || len(wsp.TransactionIDs) == 0 {
+		t.Fatal("spend below the threshold wasn't sent", wsp)
+	}
+
+	// Spends above the threshold are accepted as pending spends.
+	wsp, err = renter.WalletSiacoinsPost(types.SiacoinPrecision.Mul64(20), dest, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wsp.PendingSpendID == "" || len(wsp.TransactionIDs) != 0 {
+		t.Fatal("spend above the threshold was sent", wsp)
+	}
+	wag, err := renter.WalletApprovalsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wag.PendingSpends) != 1 || wag.PendingSpends[0].ID != wsp.PendingSpendID {
+		t.Fatal("unexpected pending spends", wag.PendingSpends)
+	}
+	if _, err := renter.WalletApprovalsApprovePost(wsp.PendingSpendID, "wrong"); err == nil {
+		t.Fatal("spend was approved with the wrong password")
+	}
+	wspApproved, err := renter.WalletApprovalsApprovePost(wsp.PendingSpendID, password)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wspApproved.TransactionIDs) == 0 {
+		t.Fatal("approved spend wasn't sent")
+	}
+	if err := renter.WalletApprovalsRejectPost(wsp.PendingSpendID); err == nil {
+		t.Fatal("approved spend could be rejected")
+	}
+
+	// The allowance funds are limited by the policy.
+	a := modules.DefaultAllowance
+	a.Funds = types.SiacoinPrecision.Mul64(101)
+	if err := renter.RenterPostAllowance(a); err == nil || !strings.Contains(err.Error(), "spending policy") {
+		t.Fatal("allowance above the policy maximum was set", err)
+	}
+	a.Funds = types.SiacoinPrecision.Mul64(100)
+	if err := renter.RenterPostAllowance(a); err != nil {
+		t.Fatal(err)
+	}
+
+	// Increases of the allowance funds add up, so multiple smaller increases
+	// can't exceed the maximum increase of the policy either. The allowance
+	// that was set when the group was created counts towards it as well.
+	policy.MaxAllowanceFunds = types.SiacoinPrecision.Mul64(1000)
+	policy.MaxAllowanceIncrease = siatest.DefaultAllowance.Funds.Add(types.SiacoinPrecision.Mul64(100))
+	if err := renter.WalletPolicyPost(policy, password); err != nil {
+		t.Fatal(err)
+	}
+	a.Funds = types.SiacoinPrecision.Mul64(150)
+	if err := renter.RenterPostAllowance(a); err != nil {
+		t.Fatal(err)
+	}
+	a.Funds = types.SiacoinPrecision.Mul64(210)
+	if err := renter.RenterPostAllowance(a); err == nil || !strings.Contains(err.Error(), "spending policy") {
+		t.Fatal("allowance increases above the policy maximum were set", err)
+	}
+	a.Funds = types.SiacoinPrecision.Mul64(200)
+	if err := renter.RenterPostAllowance(a); err != nil {
+		t.Fatal(err)
+	}
+}
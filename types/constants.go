@@ -18,6 +18,9 @@ import (
 var (
 	numGenesisSiacoins Currency
 
+	// currentNetworkName is the name of the network that is in use.
+	currentNetworkName = build.Release
+
 	// ASICHardforkTotalTarget is the initial target after the ASIC hardfork.
 	// The actual target at ASICHardforkHeight is replaced with this value in
 	// order to prevent intolerably slow block times post-fork.
@@ -223,6 +226,15 @@ var (
 		Testing:  BlockHeight(10),
 	}).(BlockHeight)
 
+	// StorageProofSegmentHardforkHeight is the height at which storage proofs
+	// started to verify only the used part of the final segment of a file.
+	StorageProofSegmentHardforkHeight = build.Select(build.Var{
+		Dev:      BlockHeight(0),
+		Testnet:  BlockHeight(2),
+		Standard: BlockHeight(21000),
+		Testing:  BlockHeight(10),
+	}).(BlockHeight)
+
 	// StorageProofHardforkHeight is the height at which the storage proof
 	// hardfork was activated.
 	StorageProofHardforkHeight = build.Select(build.Var{
@@ -812,6 +824,12 @@ func init() {
 		}
 	}
 
+	initGenesis()
+}
+
+// initGenesis creates the genesis block of the current network and computes
+// the values that are derived from it.
+func initGenesis() {
 	// Create the genesis block.
 	GenesisBlock = Block{
 		Timestamp: GenesisTimestamp,
@@ -824,6 +842,7 @@ func init() {
 	}
 
 	// calculate the initial coinbase
+	numGenesisSiacoins = ZeroCurrency
 	for _, tx := range GenesisBlock.Transactions {
 		for _, sco := range tx.SiacoinOutputs {
 			numGenesisSiacoins = numGenesisSiacoins.Add(sco.Value)
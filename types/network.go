@@ -0,0 +1,191 @@
+package types
+
+// network.go allows to replace the network parameters that are selected by the
+// build tags at runtime. This makes it possible to run private networks with
+// their own genesis block, hardforks and difficulty parameters from the same
+// binary that runs the network it was built for.
+
+import (
+	"math/big"
+
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// Network contains the parameters of a network. The parameters that aren't
+// part of a Network are the same for all networks.
+type Network struct {
+	// Name is a human readable name of the network.
+	Name string `json:"name"`
+
+	// The genesis block of the network.
+	GenesisTimestamp         Timestamp       `json:"genesistimestamp"`
+	GenesisSiacoinAllocation []SiacoinOutput `json:"genesissiacoinallocation"`
+	GenesisSiafundAllocation []SiafundOutput `json:"genesissiafundallocation"`
+
+	// The heights at which the hardforks are activated.
+	DevAddrHardforkHeight             BlockHeight `json:"devaddrhardforkheight"`
+	TaxHardforkHeight                 BlockHeight `json:"taxhardforkheight"`
+	StorageProofSegmentHardforkHeight BlockHeight `json:"storageproofsegmenthardforkheight"`
+	StorageProofHardforkHeight        BlockHeight `json:"storageproofhardforkheight"`
+	OakHardforkBlock                  BlockHeight `json:"oakhardforkblock"`
+	OakHardforkFixBlock               BlockHeight `json:"oakhardforkfixblock"`
+	ASICHardforkHeight                BlockHeight `json:"asichardforkheight"`
+	FoundationHardforkHeight          BlockHeight `json:"foundationhardforkheight"`
+
+	// The parameters of the Foundation subsidy.
+	FoundationSubsidyFrequency          BlockHeight `json:"foundationsubsidyfrequency"`
+	InitialFoundationUnlockHash         UnlockHash  `json:"initialfoundationunlockhash"`
+	InitialFoundationFailsafeUnlockHash UnlockHash  `json:"initialfoundationfailsafeunlockhash"`
+
+	// The timing of the blocks.
+	BlockFrequency         BlockHeight `json:"blockfrequency"`
+	MaturityDelay          BlockHeight `json:"maturitydelay"`
+	FutureThreshold        Timestamp   `json:"futurethreshold"`
+	ExtremeFutureThreshold Timestamp   `json:"extremefuturethreshold"`
+	MinimumCoinbase        uint64      `json:"minimumcoinbase"`
+
+	// The target and the parameters of the difficulty adjustment.
+	RootTarget              Target      `json:"roottarget"`
+	TargetWindow            BlockHeight `json:"targetwindow"`
+	MaxTargetAdjustmentUp   *big.Rat    `json:"maxtargetadjustmentup"`
+	MaxTargetAdjustmentDown *big.Rat    `json:"maxtargetadjustmentdown"`
+	OakDecayNum             int64       `json:"oakdecaynum"`
+	OakDecayDenom           int64       `json:"oakdecaydenom"`
+	OakMaxBlockShift        int64       `json:"oakmaxblockshift"`
+	OakMaxRise              *big.Rat    `json:"oakmaxrise"`
+	OakMaxDrop              *big.Rat    `json:"oakmaxdrop"`
+	ASICHardforkTotalTarget Target      `json:"asichardforktotaltarget"`
+	ASICHardforkTotalTime   int64       `json:"asichardforktotaltime"`
+}
+
+// CurrentNetwork returns the parameters of the network that is currently in
+// use. Unless SetNetwork was called, that is the network selected by the build
+// tags.
+func CurrentNetwork() Network {
+	return Network{
+		Name: currentNetworkName,
+
+		GenesisTimestamp:         GenesisTimestamp,
+		GenesisSiacoinAllocation: append([]SiacoinOutput(nil), GenesisSiacoinAllocation...),
+		GenesisSiafundAllocation: append([]SiafundOutput(nil), GenesisSiafundAllocation...),
+
+		DevAddrHardforkHeight:             DevAddrHardforkHeight,
+		TaxHardforkHeight:                 TaxHardforkHeight,
+		StorageProofSegmentHardforkHeight: StorageProofSegmentHardforkHeight,
+		StorageProofHardforkHeight:        StorageProofHardforkHeight,
+		OakHardforkBlock:                  OakHardforkBlock,
+		OakHardforkFixBlock:               OakHardforkFixBlock,
+		ASICHardforkHeight:                ASICHardforkHeight,
+		FoundationHardforkHeight:          FoundationHardforkHeight,
+
+		FoundationSubsidyFrequency:          FoundationSubsidyFrequency,
+		InitialFoundationUnlockHash:         InitialFoundationUnlockHash,
+		InitialFoundationFailsafeUnlockHash: InitialFoundationFailsafeUnlockHash,
+
+		BlockFrequency:         BlockFrequency,
+		MaturityDelay:          MaturityDelay,
+		FutureThreshold:        FutureThreshold,
+		ExtremeFutureThreshold: ExtremeFutureThreshold,
+		MinimumCoinbase:        MinimumCoinbase,
+
+		RootTarget:              RootTarget,
+		TargetWindow:            TargetWindow,
+		MaxTargetAdjustmentUp:   new(big.Rat).Set(MaxTargetAdjustmentUp),
+		MaxTargetAdjustmentDown: new(big.Rat).Set(MaxTargetAdjustmentDown),
+		OakDecayNum:             OakDecayNum,
+		OakDecayDenom:           OakDecayDenom,
+		OakMaxBlockShift:        OakMaxBlockShift,
+		OakMaxRise:              new(big.Rat).Set(OakMaxRise),
+		OakMaxDrop:              new(big.Rat).Set(OakMaxDrop),
+		ASICHardforkTotalTarget: ASICHardforkTotalTarget,
+		ASICHardforkTotalTime:   ASICHardforkTotalTime,
+	}
+}
+
+// Validate checks that the parameters of the network are sane.
+func (n Network) Validate() error {
+	var siafunds Currency
+	for _, sfo := range n.GenesisSiafundAllocation {
+		siafunds = siafunds.Add(sfo.Value)
+	}
+	positiveRat := func(r *big.Rat) bool {
+		return r != nil && r.Sign() > 0
+	}
+	switch {
+	case !siafunds.Equals(SiafundCount):
+		return errors.New("the genesis block has to allocate " + SiafundCount.String() + " siafunds")
+	case n.BlockFrequency == 0:
+		return errors.New("blockfrequency has to be greater than 0")
+	case n.MaturityDelay == 0:
+		return errors.New("maturitydelay has to be greater than 0")
+	case n.FutureThreshold > n.ExtremeFutureThreshold:
+		return errors.New("futurethreshold can't be greater than extremefuturethreshold")
+	case n.MinimumCoinbase > InitialCoinbase:
+		return errors.New("minimumcoinbase can't be greater than the initial coinbase")
+	case n.FoundationSubsidyFrequency == 0:
+		return errors.New("foundationsubsidyfrequency has to be greater than 0")
+	case n.OakHardforkFixBlock < n.OakHardforkBlock:
+		return errors.New("oakhardforkfixblock can't be lower than oakhardforkblock")
+	case n.RootTarget == Target{} || n.ASICHardforkTotalTarget == Target{}:
+		return errors.New("targets can't be 0")
+	case n.TargetWindow == 0:
+		return errors.New("targetwindow has to be greater than 0")
+	case !positiveRat(n.MaxTargetAdjustmentUp) || !positiveRat(n.MaxTargetAdjustmentDown):
+		return errors.New("target adjustments have to be greater than 0")
+	case n.OakDecayNum <= 0 || n.OakDecayDenom <= 0 || n.OakMaxBlockShift <= 0:
+		return errors.New("oak decay and block shift have to be greater than 0")
+	case !positiveRat(n.OakMaxRise) || !positiveRat(n.OakMaxDrop):
+		return errors.New("oak rise and drop have to be greater than 0")
+	case n.ASICHardforkTotalTime <= 0:
+		return errors.New("asichardforktotaltime has to be greater than 0")
+	}
+	return nil
+}
+
+// SetNetwork replaces the parameters of the current network and recomputes
+// the genesis block. It has to be called before any modules are created since
+// they don't expect the parameters to change.
+func SetNetwork(n Network) error {
+	if err := n.Validate(); err != nil {
+		return errors.AddContext(err, "invalid network")
+	}
+	currentNetworkName = n.Name
+
+	GenesisTimestamp = n.GenesisTimestamp
+	GenesisSiacoinAllocation = n.GenesisSiacoinAllocation
+	GenesisSiafundAllocation = n.GenesisSiafundAllocation
+
+	DevAddrHardforkHeight = n.DevAddrHardforkHeight
+	TaxHardforkHeight = n.TaxHardforkHeight
+	StorageProofSegmentHardforkHeight = n.StorageProofSegmentHardforkHeight
+	StorageProofHardforkHeight = n.StorageProofHardforkHeight
+	OakHardforkBlock = n.OakHardforkBlock
+	OakHardforkFixBlock = n.OakHardforkFixBlock
+	ASICHardforkHeight = n.ASICHardforkHeight
+	FoundationHardforkHeight = n.FoundationHardforkHeight
+
+	FoundationSubsidyFrequency = n.FoundationSubsidyFrequency
+	InitialFoundationUnlockHash = n.InitialFoundationUnlockHash
+	InitialFoundationFailsafeUnlockHash = n.InitialFoundationFailsafeUnlockHash
+
+	BlockFrequency = n.BlockFrequency
+	MaturityDelay = n.MaturityDelay
+	FutureThreshold = n.FutureThreshold
+	ExtremeFutureThreshold = n.ExtremeFutureThreshold
+	MinimumCoinbase = n.MinimumCoinbase
+
+	RootTarget = n.RootTarget
+	TargetWindow = n.TargetWindow
+	MaxTargetAdjustmentUp = n.MaxTargetAdjustmentUp
+	MaxTargetAdjustmentDown = n.MaxTargetAdjustmentDown
+	OakDecayNum = n.OakDecayNum
+	OakDecayDenom = n.OakDecayDenom
+	OakMaxBlockShift = n.OakMaxBlockShift
+	OakMaxRise = n.OakMaxRise
+	OakMaxDrop = n.OakMaxDrop
+	ASICHardforkTotalTarget = n.ASICHardforkTotalTarget
+	ASICHardforkTotalTime = n.ASICHardforkTotalTime
+
+	initGenesis()
+	return nil
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"testing"
+)
+
+// TestSetNetwork tests replacing the parameters of the current network. It
+// isn't run in parallel since it modifies the network of all tests.
+func TestSetNetwork(t *testing.T) {
+	orig := CurrentNetwork()
+	origID := GenesisID
+	defer func() {
+		if err := SetNetwork(orig); err != nil {
+			t.Fatal(err)
+		}
+		if GenesisID != origID {
+			t.Fatal("genesis block wasn't restored")
+		}
+	}()
+
+	// Invalid networks are rejected without changing the current network.
+	invalid := CurrentNetwork()
+	invalid.BlockFrequency = 0
+	if err := SetNetwork(invalid); err == nil {
+		t.Fatal("expected network without block frequency to be rejected")
+	}
+	invalid = CurrentNetwork()
+	invalid.GenesisSiafundAllocation = invalid.GenesisSiafundAllocation[1:]
+	if err := SetNetwork(invalid); err == nil {
+		t.Fatal("expected network with missing siafunds to be rejected")
+	}
+	if GenesisID != origID {
+		t.Fatal("invalid network changed the genesis block")
+	}
+
+	// A valid network replaces the parameters and the genesis block.
+	n := CurrentNetwork()
+	n.Name = "private"
+	n.BlockFrequency = 30
+	n.StorageProofSegmentHardforkHeight = n.TaxHardforkHeight + 5
+	n.GenesisSiacoinAllocation = []SiacoinOutput{{
+		Value:      SiacoinPrecision.Mul64(1e6),
+		UnlockHash: UnlockConditions{}.UnlockHash(),
+	}}
+	if err := SetNetwork(n); err != nil {
+		t.Fatal(err)
+	}
+	if BlockFrequency != 30 || StorageProofSegmentHardforkHeight != TaxHardforkHeight+5 || CurrentNetwork().Name != "private" {
+		t.Fatal("network parameters weren't replaced")
+	}
+	if GenesisID == origID || GenesisID != GenesisBlock.ID() {
+		t.Fatal("genesis block wasn't recomputed")
+	}
+	if !numGenesisSiacoins.Equals(SiacoinPrecision.Mul64(1e6)) {
+		t.Fatal("wrong number of genesis siacoins", numGenesisSiacoins)
+	}
+}